@@ -248,8 +248,8 @@ func TestBlockByNumber(t *testing.T) {
 	if err != nil {
 		t.Errorf("could not get recent block: %v", err)
 	}
-	if block.NumberU64() != 0 {
-		t.Errorf("did not get most recent block, instead got block number %v", block.NumberU64())
+	if block.NumberU64(types.QuaiNetworkContext) != 0 {
+		t.Errorf("did not get most recent block, instead got block number %v", block.NumberU64(types.QuaiNetworkContext))
 	}
 
 	// create one block
@@ -259,8 +259,8 @@ func TestBlockByNumber(t *testing.T) {
 	if err != nil {
 		t.Errorf("could not get recent block: %v", err)
 	}
-	if block.NumberU64() != 1 {
-		t.Errorf("did not get most recent block, instead got block number %v", block.NumberU64())
+	if block.NumberU64(types.QuaiNetworkContext) != 1 {
+		t.Errorf("did not get most recent block, instead got block number %v", block.NumberU64(types.QuaiNetworkContext))
 	}
 
 	blockByNumber, err := sim.BlockByNumber(bgCtx, big.NewInt(1))
@@ -1187,7 +1187,7 @@ func TestFork(t *testing.T) {
 		sim.Commit()
 	}
 	// 3.
-	if sim.blockchain.CurrentBlock().NumberU64() != uint64(n) {
+	if sim.blockchain.CurrentBlock().NumberU64(types.QuaiNetworkContext) != uint64(n) {
 		t.Error("wrong chain length")
 	}
 	// 4.
@@ -1197,7 +1197,7 @@ func TestFork(t *testing.T) {
 		sim.Commit()
 	}
 	// 6.
-	if sim.blockchain.CurrentBlock().NumberU64() != uint64(n+1) {
+	if sim.blockchain.CurrentBlock().NumberU64(types.QuaiNetworkContext) != uint64(n+1) {
 		t.Error("wrong chain length")
 	}
 }
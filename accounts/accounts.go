@@ -25,6 +25,7 @@ import (
 	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/params"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -35,6 +36,16 @@ type Account struct {
 	URL     URL            `json:"url"`     // Optional resource locator within a backend
 }
 
+// Location returns the location of the chain owning the account, derived from
+// the address prefix allocation of the given network ontology. It is nil if no
+// ontology is given or the address prefix isn't allocated to any chain.
+func (a Account) Location(ontology *params.Ontology) []byte {
+	if ontology == nil {
+		return nil
+	}
+	return ontology.AddressLocation(a.Address)
+}
+
 const (
 	MimetypeDataWithValidator = "data/validator"
 	MimetypeTypedData         = "data/typed"
@@ -20,7 +20,9 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/common/hexutil"
+	"github.com/spruce-solutions/go-quai/params"
 )
 
 func TestTextHash(t *testing.T) {
@@ -30,3 +32,26 @@ func TestTextHash(t *testing.T) {
 		t.Fatalf("wrong hash: %x", hash)
 	}
 }
+
+func TestAccountLocation(t *testing.T) {
+	tests := []struct {
+		prefix   byte
+		location []byte
+	}{
+		{0x00, []byte{0, 0}},
+		{0x0a, []byte{1, 0}},
+		{0x1d, []byte{1, 1}},
+		{0x6e, []byte{3, 2}},
+		{0x81, []byte{3, 3}},
+		{0x82, nil},
+	}
+	for _, tt := range tests {
+		account := Account{Address: common.Address{tt.prefix}}
+		if have := account.Location(params.MainnetOntology); !bytes.Equal(have, tt.location) {
+			t.Errorf("prefix %#x: location mismatch: have %v, want %v", tt.prefix, have, tt.location)
+		}
+		if have := account.Location(nil); have != nil {
+			t.Errorf("prefix %#x: location without ontology: have %v, want nil", tt.prefix, have)
+		}
+	}
+}
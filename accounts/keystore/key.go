@@ -172,13 +172,17 @@ func newKey(rand io.Reader) (*Key, error) {
 	return newKeyFromECDSA(privateKeyECDSA), nil
 }
 
+// storeNewKey generates a key whose address prefix lies within the inclusive
+// range id, or any key if id isn't a range, and stores it.
 func storeNewKey(ks keyStore, rand io.Reader, auth string, id []int) (*Key, accounts.Account, error) {
 	key, err := newKey(rand)
 	if err != nil {
 		return nil, accounts.Account{}, err
 	}
-
-	for int(key.Address.Bytes()[0]) < id[0] || int(key.Address.Bytes()[0]) > id[1] {
+	if len(id) == 2 && (id[0] > id[1] || id[0] > 255 || id[1] < 0) {
+		return nil, accounts.Account{}, fmt.Errorf("invalid address prefix range %v", id)
+	}
+	for len(id) == 2 && (int(key.Address.Bytes()[0]) < id[0] || int(key.Address.Bytes()[0]) > id[1]) {
 		key, err = newKey(rand)
 		if err != nil {
 			return nil, accounts.Account{}, err
@@ -405,7 +405,9 @@ func (ks *KeyStore) expire(addr common.Address, u *unlocked, timeout time.Durati
 }
 
 // NewAccount generates a new key and stores it into the key directory,
-// encrypting it with the passphrase.
+// encrypting it with the passphrase. The address prefix of the key is ground
+// to lie within the inclusive range id, the prefixes of the chain the account
+// is meant for, unless id is nil.
 func (ks *KeyStore) NewAccount(passphrase string, id []int) (accounts.Account, error) {
 	_, account, err := storeNewKey(ks.storage, crand.Reader, passphrase, id)
 	if err != nil {
@@ -17,6 +17,7 @@
 package keystore
 
 import (
+	"bytes"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -32,6 +33,7 @@ import (
 	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/crypto"
 	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/params"
 )
 
 var testSigData = make([]byte, 32)
@@ -71,6 +73,28 @@ func TestKeyStore(t *testing.T) {
 	}
 }
 
+func TestNewAccountLocation(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	for _, location := range params.MainnetOntology.ZoneLocations() {
+		id, err := params.MainnetOntology.LocationPrefixRange(location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := ks.NewAccount("foo", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(a.Location(params.MainnetOntology), location) {
+			t.Errorf("account %x: location mismatch: have %v, want %v", a.Address, a.Location(params.MainnetOntology), location)
+		}
+	}
+	if _, err := ks.NewAccount("foo", []int{50, 40}); err == nil {
+		t.Errorf("invalid prefix range accepted")
+	}
+}
+
 func TestSign(t *testing.T) {
 	dir, ks := tmpKeyStore(t, true)
 	defer os.RemoveAll(dir)
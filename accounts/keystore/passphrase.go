@@ -97,7 +97,8 @@ func (ks keyStorePassphrase) GetKey(addr common.Address, filename, auth string)
 	return key, nil
 }
 
-// StoreKey generates a key, encrypts with 'auth' and stores in the given directory
+// StoreKey generates a key with an address prefix within idRange, encrypts
+// with 'auth' and stores in the given directory
 func StoreKey(dir, auth string, idRange []int, scryptN, scryptP int) (accounts.Account, error) {
 	_, a, err := storeNewKey(&keyStorePassphrase{dir, scryptN, scryptP, false}, rand.Reader, auth, idRange)
 	return a, err
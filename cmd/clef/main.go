@@ -100,6 +100,10 @@ var (
 		Value: params.MainnetPrimeChainConfig.ChainID.Int64(),
 		Usage: "Chain id to use for signing (1=mainnet, 3=Ropsten, 4=Rinkeby, 5=Goerli)",
 	}
+	locationFlag = cli.StringFlag{
+		Name:  "location",
+		Usage: "Zone to generate new accounts for as <region>,<zone> (default = any address)",
+	}
 	rpcPortFlag = cli.IntFlag{
 		Name:  "http.port",
 		Usage: "HTTP-RPC server listening port",
@@ -200,10 +204,13 @@ The delpw command removes a password for a given address (keyfile).
 			keystoreFlag,
 			utils.LightKDFFlag,
 			acceptFlag,
+			chainIdFlag,
+			locationFlag,
 		},
 		Description: `
 The newaccount command creates a new keystore-backed account. It is a convenience-method
-which can be used in lieu of an external UI.`,
+which can be used in lieu of an external UI. The account is created for the zone given
+by --location.`,
 	}
 
 	gendocCommand = cli.Command{
@@ -253,6 +260,7 @@ func init() {
 		keystoreFlag,
 		configdirFlag,
 		chainIdFlag,
+		locationFlag,
 		utils.LightKDFFlag,
 		utils.NoUSBFlag,
 		utils.SmartCardDaemonPathFlag,
@@ -486,7 +494,8 @@ func newAccount(c *cli.Context) error {
 	log.Info("Starting clef", "keystore", ksLoc, "light-kdf", lightKdf)
 	am := core.StartClefAccountManager(ksLoc, true, lightKdf, "")
 	// This gives is us access to the external API
-	apiImpl := core.NewSignerAPI(am, 0, true, ui, nil, false, pwStorage)
+	apiImpl := core.NewSignerAPI(am, c.GlobalInt64(chainIdFlag.Name), true, ui, nil, false, pwStorage)
+	setAccountLocation(c, apiImpl)
 	// This gives us access to the internal API
 	internalApi := core.NewUIServerAPI(apiImpl)
 	addr, err := internalApi.New(context.Background())
@@ -496,6 +505,21 @@ func newAccount(c *cli.Context) error {
 	return err
 }
 
+// setAccountLocation configures the zone new accounts are generated for.
+func setAccountLocation(c *cli.Context, api *core.SignerAPI) {
+	if !c.GlobalIsSet(locationFlag.Name) {
+		return
+	}
+	var region, zone byte
+	if _, err := fmt.Sscanf(c.GlobalString(locationFlag.Name), "%d,%d", &region, &zone); err != nil {
+		utils.Fatalf("Invalid location %q, want <region>,<zone>", c.GlobalString(locationFlag.Name))
+	}
+	if err := api.SetAccountLocation([]byte{region, zone}); err != nil {
+		utils.Fatalf("Invalid location: %v", err)
+	}
+	log.Info("New accounts are generated for zone", "region", region, "zone", zone)
+}
+
 func initialize(c *cli.Context) error {
 	// Set up the logger to print everything
 	logOutput := os.Stdout
@@ -625,6 +649,7 @@ func signer(c *cli.Context) error {
 		"light-kdf", lightKdf, "advanced", advanced)
 	am := core.StartClefAccountManager(ksLoc, nousb, lightKdf, scpath)
 	apiImpl := core.NewSignerAPI(am, chainId, nousb, ui, db, advanced, pwStorage)
+	setAccountLocation(c, apiImpl)
 
 	// Establish the bidirectional communication, by creating a new UI backend and registering
 	// it with the UI.
@@ -49,7 +49,7 @@ func (c *Chain) Len() int {
 func (c *Chain) TD() *big.Int {
 	sum := big.NewInt(0)
 	for _, block := range c.blocks[:c.Len()] {
-		sum.Add(sum, block.Difficulty())
+		sum.Add(sum, block.Difficulty(types.QuaiNetworkContext))
 	}
 	return sum
 }
@@ -62,7 +62,7 @@ func (c *Chain) TotalDifficultyAt(height int) *big.Int {
 		return sum
 	}
 	for _, block := range c.blocks[:height+1] {
-		sum.Add(sum, block.Difficulty())
+		sum.Add(sum, block.Difficulty(types.QuaiNetworkContext))
 	}
 	return sum
 }
@@ -99,9 +99,9 @@ func (c *Chain) GetHeaders(req GetBlockHeaders) (BlockHeaders, error) {
 
 	// range over blocks to check if our chain has the requested header
 	for _, block := range c.blocks {
-		if block.Hash() == req.Origin.Hash || block.Number().Uint64() == req.Origin.Number {
+		if block.Hash() == req.Origin.Hash || block.Number(types.QuaiNetworkContext).Uint64() == req.Origin.Number {
 			headers[0] = block.Header()
-			blockNumber = block.Number().Uint64()
+			blockNumber = block.Number(types.QuaiNetworkContext).Uint64()
 		}
 	}
 	if headers[0] == nil {
@@ -179,8 +179,8 @@ func blocksFromFile(chainfile string, gblock *types.Block) ([]*types.Block, erro
 		} else if err != nil {
 			return nil, fmt.Errorf("at block index %d: %v", i, err)
 		}
-		if b.NumberU64() != uint64(i+1) {
-			return nil, fmt.Errorf("block at index %d has wrong number %d", i, b.NumberU64())
+		if b.NumberU64(types.QuaiNetworkContext) != uint64(i+1) {
+			return nil, fmt.Errorf("block at index %d has wrong number %d", i, b.NumberU64(types.QuaiNetworkContext))
 		}
 		blocks = append(blocks, &b)
 	}
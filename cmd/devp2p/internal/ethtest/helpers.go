@@ -167,7 +167,7 @@ loop:
 		case *Status:
 			if have, want := msg.Head, chain.blocks[chain.Len()-1].Hash(); have != want {
 				return nil, fmt.Errorf("wrong head block in status, want:  %#x (block %d) have %#x",
-					want, chain.blocks[chain.Len()-1].NumberU64(), have)
+					want, chain.blocks[chain.Len()-1].NumberU64(types.QuaiNetworkContext), have)
 			}
 			if have, want := msg.TD.Cmp(chain.TD()), 0; have != want {
 				return nil, fmt.Errorf("wrong TD in status: have %v want %v", have, want)
@@ -487,7 +487,7 @@ func (s *Suite) oldAnnounce(isQuai66 bool) error {
 	// create old block announcement
 	oldBlockAnnounce := &NewBlock{
 		Block: s.chain.blocks[len(s.chain.blocks)/2],
-		TD:    s.chain.blocks[len(s.chain.blocks)/2].Difficulty(),
+		TD:    s.chain.blocks[len(s.chain.blocks)/2].Difficulty(types.QuaiNetworkContext),
 	}
 	if err := sendConn.Write(oldBlockAnnounce); err != nil {
 		return fmt.Errorf("could not write to connection: %v", err)
@@ -663,7 +663,7 @@ func (s *Suite) hashAnnounce(isQuai66 bool) error {
 		Number uint64      // Number of one particular block being announced
 	}
 	nextBlock := s.fullChain.blocks[s.chain.Len()]
-	announcement := anno{Hash: nextBlock.Hash(), Number: nextBlock.Number().Uint64()}
+	announcement := anno{Hash: nextBlock.Hash(), Number: nextBlock.Number(types.QuaiNetworkContext).Uint64()}
 	newBlockHash := &NewBlockHashes{announcement}
 	if err := sendConn.Write(newBlockHash); err != nil {
 		return fmt.Errorf("failed to write to connection: %v", err)
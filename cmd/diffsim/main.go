@@ -0,0 +1,235 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// diffsim replays the block times of a chain through the blake3 difficulty
+// adjustment algorithms to compare their block time stability.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethclient/quaiclient"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+var (
+	rpcURL     = flag.String("rpc", "", "RPC endpoint of the chain to replay")
+	ctxFlag    = flag.Int("context", params.ZONE, "context of the chain (0 = prime, 1 = region, 2 = zone)")
+	fromFlag   = flag.Uint64("from", 1, "first block to replay")
+	countFlag  = flag.Uint64("count", 1000, "number of blocks to replay")
+	windowFlag = flag.Int("window", 16, "number of blocks averaged to estimate the hashrate")
+	seedFlag   = flag.Int64("seed", 1, "seed of the simulated mining process")
+	algosFlag  = flag.String("algorithms", "", "comma separated algorithms to simulate (default all)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "-rpc <url> [-context <n>] [-from <block>] [-count <n>] [-algorithms <list>]")
+		flag.PrintDefaults()
+		fmt.Fprintln(os.Stderr, `
+Fetches the headers of a chain, estimates the hashrate mining every block
+from its difficulty and block time, and simulates mining the same hashrate
+under each difficulty adjustment algorithm. The block time statistics of the
+simulations are printed next to the historical ones.`)
+	}
+}
+
+// sample is a historical block, reduced to what the simulation needs.
+type sample struct {
+	number     uint64
+	time       uint64
+	difficulty *big.Int
+}
+
+// stats are the block time statistics of a run.
+type stats struct {
+	mean, stddev, max float64
+}
+
+func main() {
+	flag.Parse()
+	if *rpcURL == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *ctxFlag < params.PRIME || *ctxFlag > params.ZONE {
+		die(fmt.Errorf("invalid context %d", *ctxFlag))
+	}
+	names, err := algorithms(*algosFlag)
+	if err != nil {
+		die(err)
+	}
+	samples, err := fetch(*rpcURL, *ctxFlag, *fromFlag, *countFlag)
+	if err != nil {
+		die(err)
+	}
+	if len(samples) < 2 {
+		die(fmt.Errorf("need at least two blocks, have %d", len(samples)))
+	}
+	hashrates := estimateHashrates(samples, *windowFlag)
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(out, "algorithm\tmean\tstddev\tcv\tmax\ttarget\n")
+	target := float64(params.DurationLimits[*ctxFlag].Int64())
+	print := func(name string, s stats) {
+		fmt.Fprintf(out, "%s\t%.2f\t%.2f\t%.3f\t%.0f\t%.0f\n", name, s.mean, s.stddev, s.stddev/s.mean, s.max, target)
+	}
+	print("historical", historical(samples))
+	for _, name := range names {
+		algorithm, _ := blake3.LookupDifficultyAlgorithm(name)
+		print(name, simulate(algorithm, samples[0], hashrates, *ctxFlag, *seedFlag))
+	}
+	out.Flush()
+}
+
+// algorithms parses the requested algorithm names, defaulting to all of them.
+func algorithms(list string) ([]string, error) {
+	if list == "" {
+		return blake3.DifficultyAlgorithmNames(), nil
+	}
+	names := strings.Split(list, ",")
+	for _, name := range names {
+		if _, ok := blake3.LookupDifficultyAlgorithm(name); !ok {
+			return nil, fmt.Errorf("unknown difficulty algorithm %q", name)
+		}
+	}
+	return names, nil
+}
+
+// fetch retrieves the timestamps and difficulties of the blocks to replay.
+func fetch(url string, chainContext int, from, count uint64) ([]sample, error) {
+	client, err := quaiclient.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	samples := make([]sample, 0, count)
+	for number := from; number < from+count; number++ {
+		header, err := client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+		if err != nil {
+			if len(samples) > 0 {
+				break
+			}
+			return nil, fmt.Errorf("failed to retrieve block %d: %v", number, err)
+		}
+		if len(header.Difficulty) <= chainContext || header.Difficulty[chainContext] == nil {
+			return nil, fmt.Errorf("block %d has no difficulty for context %d", number, chainContext)
+		}
+		samples = append(samples, sample{number: number, time: header.Time, difficulty: header.Difficulty[chainContext]})
+	}
+	return samples, nil
+}
+
+// estimateHashrates estimates the hashrate which mined every block after the
+// first one, averaging difficulty over time across the window.
+func estimateHashrates(samples []sample, window int) []float64 {
+	if window < 1 {
+		window = 1
+	}
+	hashrates := make([]float64, len(samples)-1)
+	for i := 1; i < len(samples); i++ {
+		first := i - window
+		if first < 0 {
+			first = 0
+		}
+		work := new(big.Float)
+		for j := first + 1; j <= i; j++ {
+			work.Add(work, new(big.Float).SetInt(samples[j].difficulty))
+		}
+		elapsed := float64(samples[i].time - samples[first].time)
+		if elapsed < 1 {
+			elapsed = 1
+		}
+		rate, _ := work.Float64()
+		hashrates[i-1] = rate / elapsed
+	}
+	return hashrates
+}
+
+// historical computes the block time statistics of the replayed blocks.
+func historical(samples []sample) stats {
+	times := make([]float64, 0, len(samples)-1)
+	for i := 1; i < len(samples); i++ {
+		times = append(times, float64(samples[i].time-samples[i-1].time))
+	}
+	return summarize(times)
+}
+
+// simulate mines a chain with the estimated hashrates under the algorithm,
+// drawing every block time from the exponential distribution of the work
+// required at the parent difficulty.
+func simulate(algorithm blake3.DifficultyAlgorithm, genesis sample, hashrates []float64, context int, seed int64) stats {
+	rng := rand.New(rand.NewSource(seed))
+	parent := &types.Header{
+		Number:     make([]*big.Int, params.ZONE+1),
+		Difficulty: make([]*big.Int, params.ZONE+1),
+		Time:       genesis.time,
+	}
+	parent.Number[context] = new(big.Int).SetUint64(genesis.number)
+	parent.Difficulty[context] = new(big.Int).Set(genesis.difficulty)
+
+	times := make([]float64, 0, len(hashrates))
+	for _, hashrate := range hashrates {
+		difficulty, _ := new(big.Float).SetInt(parent.Difficulty[context]).Float64()
+		solveTime := math.Round(rng.ExpFloat64() * difficulty / hashrate)
+		if solveTime < 1 {
+			solveTime = 1
+		}
+		time := parent.Time + uint64(solveTime)
+		header := &types.Header{
+			Number:     make([]*big.Int, params.ZONE+1),
+			Difficulty: make([]*big.Int, params.ZONE+1),
+			Time:       time,
+		}
+		header.Number[context] = new(big.Int).Add(parent.Number[context], big.NewInt(1))
+		header.Difficulty[context] = algorithm.CalcDifficulty(time, parent, context)
+
+		times = append(times, solveTime)
+		parent = header
+	}
+	return summarize(times)
+}
+
+// summarize computes the statistics of a series of block times.
+func summarize(times []float64) stats {
+	var s stats
+	for _, t := range times {
+		s.mean += t
+		s.max = math.Max(s.max, t)
+	}
+	s.mean /= float64(len(times))
+	for _, t := range times {
+		s.stddev += (t - s.mean) * (t - s.mean)
+	}
+	s.stddev = math.Sqrt(s.stddev / float64(len(times)))
+	return s
+}
+
+func die(args ...interface{}) {
+	fmt.Fprintln(os.Stderr, args...)
+	os.Exit(1)
+}
@@ -33,6 +33,7 @@ import (
 type outputGenerate struct {
 	Address      string
 	AddressEIP55 string
+	Location     string
 }
 
 var commandGenerate = cli.Command{
@@ -44,10 +45,18 @@ Generate a new keyfile.
 
 If you want to encrypt an existing private key, it can be specified by setting
 --privatekey with the location of the file containing the private key.
+
+The address of the key can be bound to a zone by setting --location to the
+zone as <region>,<zone>. A given private key must belong to that zone. Zones
+are those of the main network, or of the test network if --testnet is set, or
+of the network defined by the genesis file given by --genesis.
 `,
 	Flags: []cli.Flag{
 		passphraseFlag,
 		jsonFlag,
+		locationFlag,
+		testnetFlag,
+		genesisFlag,
 		cli.StringFlag{
 			Name:  "privatekey",
 			Usage: "file containing a raw private key to encrypt",
@@ -69,6 +78,28 @@ If you want to encrypt an existing private key, it can be specified by setting
 			utils.Fatalf("Error checking if keyfile exists: %v", err)
 		}
 
+		// Resolve the address prefixes of the requested zone.
+		var (
+			ontology = getOntology(ctx)
+			idRange  []int
+		)
+		if ctx.IsSet(locationFlag.Name) {
+			locations, err := utils.ParseLocations(ctx.String(locationFlag.Name), ontology)
+			if err != nil {
+				utils.Fatalf("%v", err)
+			}
+			if len(locations) != 1 {
+				utils.Fatalf("Only a single key can be generated, use quai account new for one key per zone")
+			}
+			if idRange, err = ontology.LocationPrefixRange(locations[0]); err != nil {
+				utils.Fatalf("%v", err)
+			}
+		}
+		inRange := func(key *ecdsa.PrivateKey) bool {
+			prefix := int(crypto.PubkeyToAddress(key.PublicKey)[0])
+			return idRange == nil || (prefix >= idRange[0] && prefix <= idRange[1])
+		}
+
 		var privateKey *ecdsa.PrivateKey
 		var err error
 		if file := ctx.String("privatekey"); file != "" {
@@ -77,11 +108,16 @@ If you want to encrypt an existing private key, it can be specified by setting
 			if err != nil {
 				utils.Fatalf("Can't load private key: %v", err)
 			}
+			if !inRange(privateKey) {
+				utils.Fatalf("Private key does not belong to zone %s", ctx.String(locationFlag.Name))
+			}
 		} else {
-			// If not loaded, generate random.
-			privateKey, err = crypto.GenerateKey()
-			if err != nil {
-				utils.Fatalf("Failed to generate random private key: %v", err)
+			// If not loaded, generate random keys until one is in the zone.
+			for privateKey == nil || !inRange(privateKey) {
+				privateKey, err = crypto.GenerateKey()
+				if err != nil {
+					utils.Fatalf("Failed to generate random private key: %v", err)
+				}
 			}
 		}
 
@@ -117,11 +153,13 @@ If you want to encrypt an existing private key, it can be specified by setting
 
 		// Output some information.
 		out := outputGenerate{
-			Address: key.Address.Hex(),
+			Address:  key.Address.Hex(),
+			Location: utils.LocationString(ontology.AddressLocation(key.Address)),
 		}
 		if ctx.Bool(jsonFlag.Name) {
 			mustPrintJSON(out)
 		} else {
+			fmt.Println("Location:", out.Location)
 			fmt.Println("Address:", out.Address)
 		}
 		return nil
@@ -29,6 +29,7 @@ import (
 
 type outputInspect struct {
 	Address    string
+	Location   string
 	PublicKey  string
 	PrivateKey string
 }
@@ -45,6 +46,8 @@ make sure to use this feature with great caution!`,
 	Flags: []cli.Flag{
 		passphraseFlag,
 		jsonFlag,
+		testnetFlag,
+		genesisFlag,
 		cli.BoolFlag{
 			Name:  "private",
 			Usage: "include the private key in the output",
@@ -69,7 +72,8 @@ make sure to use this feature with great caution!`,
 		// Output all relevant information we can retrieve.
 		showPrivate := ctx.Bool("private")
 		out := outputInspect{
-			Address: key.Address.Hex(),
+			Address:  key.Address.Hex(),
+			Location: utils.LocationString(getOntology(ctx).AddressLocation(key.Address)),
 			PublicKey: hex.EncodeToString(
 				crypto.FromECDSAPub(&key.PrivateKey.PublicKey)),
 		}
@@ -81,6 +85,7 @@ make sure to use this feature with great caution!`,
 			mustPrintJSON(out)
 		} else {
 			fmt.Println("Address:       ", out.Address)
+			fmt.Println("Location:      ", out.Location)
 			fmt.Println("Public key:    ", out.PublicKey)
 			if showPrivate {
 				fmt.Println("Private key:   ", out.PrivateKey)
@@ -56,6 +56,18 @@ var (
 		Name:  "json",
 		Usage: "output JSON instead of human-readable format",
 	}
+	locationFlag = cli.StringFlag{
+		Name:  "location",
+		Usage: "zone to generate the key for as <region>,<zone>",
+	}
+	testnetFlag = cli.BoolFlag{
+		Name:  "testnet",
+		Usage: "locate keys in the test network ontology",
+	}
+	genesisFlag = cli.StringFlag{
+		Name:  "genesis",
+		Usage: "genesis file whose network ontology locates the keys",
+	}
 )
 
 func main() {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/spruce-solutions/go-quai/cmd/utils"
 	"github.com/spruce-solutions/go-quai/crypto"
+	"github.com/spruce-solutions/go-quai/params"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -46,6 +47,36 @@ func getPassphrase(ctx *cli.Context, confirmation bool) string {
 	return utils.GetPassPhrase("", confirmation)
 }
 
+// getOntology returns the network ontology locating the keys. It is read from
+// the genesis file given by --genesis, or else is the test network ontology if
+// --testnet is set and the main network ontology otherwise.
+func getOntology(ctx *cli.Context) *params.Ontology {
+	if file := ctx.String(genesisFlag.Name); file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("Failed to read genesis file '%s': %v", file, err)
+		}
+		var genesis struct {
+			Config *params.ChainConfig `json:"config"`
+		}
+		if err := json.Unmarshal(content, &genesis); err != nil {
+			utils.Fatalf("Invalid genesis file '%s': %v", file, err)
+		}
+		if genesis.Config == nil {
+			utils.Fatalf("Genesis file '%s' has no chain config", file)
+		}
+		ontology := genesis.Config.NetworkOntology()
+		if ontology == nil {
+			utils.Fatalf("Genesis file '%s' has no network ontology", file)
+		}
+		return ontology
+	}
+	if ctx.Bool(testnetFlag.Name) {
+		return params.TestnetOntology
+	}
+	return params.MainnetOntology
+}
+
 // signHash is a helper function that calculates a hash for the given message
 // that can be safely used to calculate a signature from.
 //
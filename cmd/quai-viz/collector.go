@@ -0,0 +1,242 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethclient/quaiclient"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// source is a chain of the graph along with the client of its node.
+type source struct {
+	client *quaiclient.Client
+	chain  *Chain
+	head   uint64 // Number of the latest block collected
+}
+
+// collector fills a graph with the blocks of every chain of the hierarchy.
+type collector struct {
+	graph   *Graph
+	sources []*source
+	reorgs  uint64 // Number of reorg journal entries to draw
+}
+
+// dial connects to every chain in the config, in the order they are drawn:
+// Prime, then each Region followed by its Zones.
+func dial(ctx context.Context, config *quaiclient.HierarchyConfig, graph *Graph, reorgs uint64) (*collector, error) {
+	c := &collector{graph: graph, reorgs: reorgs}
+	add := func(rawurl string, location []byte) error {
+		if rawurl == "" {
+			return nil
+		}
+		var (
+			client *quaiclient.Client
+			err    error
+		)
+		if config.Secret != nil {
+			client, err = quaiclient.DialWithAuth(ctx, rawurl, config.Secret)
+		} else {
+			client, err = quaiclient.DialContext(ctx, rawurl)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to dial %s: %v", rawurl, err)
+		}
+		chainID, err := client.ChainID(ctx)
+		if err != nil {
+			client.Close()
+			return fmt.Errorf("failed to retrieve chain id of %s: %v", rawurl, err)
+		}
+		chain := graph.AddChain(location, params.LookupChainIDRange(chainID))
+		c.sources = append(c.sources, &source{client: client, chain: chain})
+		return nil
+	}
+	if err := add(config.Prime, []byte{}); err != nil {
+		c.close()
+		return nil, err
+	}
+	for i := 0; i < len(config.Regions) || i < len(config.Zones); i++ {
+		if i < len(config.Regions) {
+			if err := add(config.Regions[i], []byte{byte(i + 1)}); err != nil {
+				c.close()
+				return nil, err
+			}
+		}
+		if i < len(config.Zones) {
+			for j, rawurl := range config.Zones[i] {
+				if err := add(rawurl, []byte{byte(i + 1), byte(j + 1)}); err != nil {
+					c.close()
+					return nil, err
+				}
+			}
+		}
+	}
+	if len(c.sources) == 0 {
+		return nil, fmt.Errorf("no chain endpoints configured")
+	}
+	return c, nil
+}
+
+// close closes the connections to every chain.
+func (c *collector) close() {
+	for _, src := range c.sources {
+		src.client.Close()
+	}
+}
+
+// collectRange adds the blocks numbered from..to of every chain. If both are
+// zero, the last count blocks of each chain are added instead.
+func (c *collector) collectRange(ctx context.Context, from, to, count uint64) error {
+	for _, src := range c.sources {
+		first, last := from, to
+		if from == 0 && to == 0 {
+			head, err := src.client.HeaderByNumber(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve the head of %s: %v", src.chain.Name, err)
+			}
+			last = head.Number[src.chain.Context].Uint64()
+			if last+1 > count {
+				first = last + 1 - count
+			}
+		}
+		for number := first; number <= last; number++ {
+			block, err := src.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+			if err != nil {
+				return fmt.Errorf("failed to retrieve block %d of %s: %v", number, src.chain.Name, err)
+			}
+			c.graph.AddBlock(src.chain, block)
+		}
+		src.head = last
+	}
+	return nil
+}
+
+// collectHead adds the new head of a chain, along with the blocks between it
+// and the previously collected head, and prunes the blocks more than count
+// behind the head.
+func (c *collector) collectHead(ctx context.Context, src *source, header *types.Header, count uint64) error {
+	head := header.Number[src.chain.Context].Uint64()
+	first := src.head + 1
+	if head+1 > count && first < head+1-count {
+		first = head + 1 - count
+	}
+	if first > head {
+		// Same height or lower, a reorg of the tip; refetch the head only
+		first = head
+	}
+	for number := first; number < head; number++ {
+		block, err := src.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return fmt.Errorf("failed to retrieve block %d of %s: %v", number, src.chain.Name, err)
+		}
+		c.graph.AddBlock(src.chain, block)
+	}
+	block, err := src.client.BlockByHash(ctx, header.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to retrieve block %d of %s: %v", head, src.chain.Name, err)
+	}
+	c.graph.AddBlock(src.chain, block)
+	src.head = head
+
+	if head+1 > count {
+		src.chain.Prune(head + 1 - count)
+	}
+	return nil
+}
+
+// collectReorgs marks the blocks dropped by the latest reorgs of every chain.
+// Dropped blocks already pruned from the graph are skipped.
+func (c *collector) collectReorgs(ctx context.Context) error {
+	if c.reorgs == 0 {
+		return nil
+	}
+	for _, src := range c.sources {
+		records, err := src.client.ReorgHistory(ctx, c.reorgs)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve the reorgs of %s: %v", src.chain.Name, err)
+		}
+		lowest := src.chain.Lowest()
+		for _, record := range records {
+			if record.CommonNumber+1 < lowest || len(record.Dropped) <= src.chain.Context {
+				continue
+			}
+			for _, hash := range record.Dropped[src.chain.Context] {
+				if block := src.chain.Block(hash); block != nil {
+					block.Dropped = true
+					continue
+				}
+				block, err := src.client.BlockByHash(ctx, hash)
+				if err != nil {
+					log.Debug("Dropped block unavailable", "chain", src.chain.Name, "hash", hash, "err", err)
+					continue
+				}
+				c.graph.AddDropped(src.chain, block.Header())
+			}
+		}
+	}
+	return nil
+}
+
+// collectETxs looks up the outcome of the ETxs sent by the zone blocks in the
+// graph, asking the node of their destination zone. ETxs already applied are
+// not looked up again.
+func (c *collector) collectETxs(ctx context.Context) {
+	for _, src := range c.sources {
+		if src.chain.Context != params.ZONE {
+			continue
+		}
+		for _, block := range src.chain.Blocks {
+			for _, etx := range block.ETxs {
+				if etx.DestinationBlock != nil {
+					continue
+				}
+				dest := c.source(etx.Destination)
+				if dest == nil {
+					continue
+				}
+				result, err := dest.client.ETxResult(ctx, etx.Hash)
+				if err != nil {
+					continue // Unknown to the destination yet
+				}
+				etx.Stage = result.Stage
+				if result.DestinationBlock != (common.Hash{}) {
+					hash := result.DestinationBlock
+					etx.DestinationBlock = &hash
+				}
+			}
+		}
+	}
+}
+
+// source returns the source of the zone at the given location, if dialed.
+func (c *collector) source(location []byte) *source {
+	if len(location) != params.ZONE {
+		return nil
+	}
+	for _, src := range c.sources {
+		if string(src.chain.Location) == string(location) {
+			return src
+		}
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// Edge kinds drawn between the blocks of the graph.
+const (
+	edgeParent     = "parent"     // From a block to its child in the same chain
+	edgeCoincident = "coincident" // From a dominant block to the same block in a subordinate chain
+	edgeUncle      = "uncle"      // From an uncle to the block including it
+	edgeETx        = "etx"        // From the block sending an ETx to the block applying it
+)
+
+// Graph is the set of blocks collected from the chains of the hierarchy.
+type Graph struct {
+	Chains []*Chain `json:"chains"`
+
+	order func(header *types.Header) (int, error) // Difficulty order of a header
+}
+
+// Chain is a chain of the hierarchy drawn in the graph.
+type Chain struct {
+	Name     string        `json:"name"`
+	Context  int           `json:"context"`
+	Location hexutil.Bytes `json:"location"`
+	Blocks   []*Block      `json:"blocks"`
+
+	index  int                    // Position of the chain in the graph
+	prefix []int                  // Address byte prefix range owned by the chain
+	blocks map[common.Hash]*Block // Blocks of the chain by hash
+}
+
+// Block is a block of a chain, either canonical, an uncle or dropped by a reorg.
+type Block struct {
+	Hash         common.Hash  `json:"hash"`
+	Number       uint64       `json:"number"`
+	ParentHash   common.Hash  `json:"parentHash"`
+	Time         uint64       `json:"timestamp"`
+	Order        int          `json:"order"` // Highest context whose difficulty the block satisfies, -1 if none
+	Transactions int          `json:"transactions"`
+	IncludedBy   *common.Hash `json:"includedBy,omitempty"` // Block including the block as an uncle
+	Dropped      bool         `json:"dropped,omitempty"`    // Rolled back by a reorg
+	ETxs         []*ETx       `json:"etxs,omitempty"`
+}
+
+// ETx is an external transaction sent by a block to another zone.
+type ETx struct {
+	Hash             common.Hash   `json:"hash"`
+	Destination      hexutil.Bytes `json:"destination"`                // Location of the destination zone
+	Stage            string        `json:"stage"`                      // Lifecycle stage reported by the destination zone
+	DestinationBlock *common.Hash  `json:"destinationBlock,omitempty"` // Block applying the ETx, once applied
+}
+
+// Edge is a relation between two blocks of the graph.
+type Edge struct {
+	Kind string   `json:"kind"`
+	From BlockRef `json:"from"`
+	To   BlockRef `json:"to"`
+}
+
+// BlockRef identifies a block of a chain of the graph.
+type BlockRef struct {
+	Chain int         `json:"chain"`
+	Hash  common.Hash `json:"hash"`
+}
+
+// NewGraph creates an empty graph, computing the order of the blocks with the
+// given function.
+func NewGraph(order func(header *types.Header) (int, error)) *Graph {
+	return &Graph{order: order}
+}
+
+// AddChain adds a chain at the given location to the graph. The location is
+// empty for Prime, holds the region for a Region and the region and zone for
+// a Zone.
+func (g *Graph) AddChain(location []byte, prefix []int) *Chain {
+	chain := &Chain{
+		Name:     chainName(location),
+		Context:  len(location),
+		Location: common.CopyBytes(location),
+		Blocks:   []*Block{},
+		index:    len(g.Chains),
+		prefix:   prefix,
+		blocks:   make(map[common.Hash]*Block),
+	}
+	g.Chains = append(g.Chains, chain)
+	return chain
+}
+
+// chainName returns the display name of the chain at a location.
+func chainName(location []byte) string {
+	switch len(location) {
+	case params.PRIME:
+		return "Prime"
+	case params.REGION:
+		return fmt.Sprintf("Region %d", location[0])
+	default:
+		return fmt.Sprintf("Zone %d-%d", location[0], location[1])
+	}
+}
+
+// Block returns the block of the chain with the given hash, if known.
+func (c *Chain) Block(hash common.Hash) *Block {
+	return c.blocks[hash]
+}
+
+// owns reports whether the address belongs to the chain.
+func (c *Chain) owns(addr common.Address) bool {
+	return len(c.prefix) == 2 && int(addr[0]) >= c.prefix[0] && int(addr[0]) <= c.prefix[1]
+}
+
+// AddBlock adds a canonical block of the chain along with its uncles. A known
+// block is returned as is.
+func (g *Graph) AddBlock(chain *Chain, block *types.Block) *Block {
+	added := g.addHeader(chain, block.Header())
+	added.Transactions = len(block.Transactions())
+	if chain.Context == params.ZONE && added.ETxs == nil {
+		added.ETxs = g.etxs(chain, block.Transactions())
+	}
+
+	for _, uncle := range block.Uncles() {
+		hash := block.Hash()
+		g.addHeader(chain, uncle).IncludedBy = &hash
+	}
+	return added
+}
+
+// etxs returns the ETxs sent to other zones by the transactions of a zone.
+func (g *Graph) etxs(chain *Chain, txs types.Transactions) []*ETx {
+	var etxs []*ETx
+	for _, tx := range txs {
+		to := tx.To()
+		if tx.Type() == types.ExternalTxType || to == nil || chain.owns(*to) {
+			continue
+		}
+		etx := &ETx{Hash: tx.Hash(), Stage: types.ETxIncluded.String()}
+		if dest := g.destination(*to); dest != nil {
+			etx.Destination = common.CopyBytes(dest.Location)
+		}
+		etxs = append(etxs, etx)
+	}
+	return etxs
+}
+
+// AddDropped adds a block rolled back by a reorg of the chain.
+func (g *Graph) AddDropped(chain *Chain, header *types.Header) *Block {
+	block := g.addHeader(chain, header)
+	block.Dropped = true
+	return block
+}
+
+// addHeader adds the header to the chain unless it is already known.
+func (g *Graph) addHeader(chain *Chain, header *types.Header) *Block {
+	hash := header.Hash()
+	if block := chain.blocks[hash]; block != nil {
+		return block
+	}
+	order, err := g.order(header)
+	if err != nil {
+		order = -1
+	}
+	block := &Block{
+		Hash:       hash,
+		Number:     header.Number[chain.Context].Uint64(),
+		ParentHash: header.ParentHash[chain.Context],
+		Time:       header.Time,
+		Order:      order,
+	}
+	chain.blocks[hash] = block
+	chain.Blocks = append(chain.Blocks, block)
+	return block
+}
+
+// Prune drops the blocks of the chain numbered below the given number.
+func (c *Chain) Prune(number uint64) {
+	kept := c.Blocks[:0]
+	for _, block := range c.Blocks {
+		if block.Number < number {
+			delete(c.blocks, block.Hash)
+			continue
+		}
+		kept = append(kept, block)
+	}
+	c.Blocks = kept
+}
+
+// Lowest returns the lowest block number of the chain, or 0 if it's empty.
+func (c *Chain) Lowest() uint64 {
+	if len(c.Blocks) == 0 {
+		return 0
+	}
+	lowest := c.Blocks[0].Number
+	for _, block := range c.Blocks[1:] {
+		if block.Number < lowest {
+			lowest = block.Number
+		}
+	}
+	return lowest
+}
+
+// destination returns the zone owning the recipient of an ETx.
+func (g *Graph) destination(addr common.Address) *Chain {
+	for _, chain := range g.Chains {
+		if chain.Context == params.ZONE && chain.owns(addr) {
+			return chain
+		}
+	}
+	return nil
+}
+
+// sorted returns the blocks of the chain ordered by number and hash.
+func (c *Chain) sorted() []*Block {
+	blocks := append([]*Block{}, c.Blocks...)
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Number != blocks[j].Number {
+			return blocks[i].Number < blocks[j].Number
+		}
+		return blocks[i].Hash.Hex() < blocks[j].Hash.Hex()
+	})
+	return blocks
+}
+
+// Edges returns the relations between the blocks of the graph: parent links
+// within a chain, coincident blocks between a chain and its subordinates,
+// uncles and the ETxs applied by another zone.
+func (g *Graph) Edges() []*Edge {
+	var edges []*Edge
+	for _, chain := range g.Chains {
+		for _, block := range chain.sorted() {
+			to := BlockRef{Chain: chain.index, Hash: block.Hash}
+			if chain.blocks[block.ParentHash] != nil {
+				edges = append(edges, &Edge{Kind: edgeParent, From: BlockRef{Chain: chain.index, Hash: block.ParentHash}, To: to})
+			}
+			if block.IncludedBy != nil && chain.blocks[*block.IncludedBy] != nil {
+				edges = append(edges, &Edge{Kind: edgeUncle, From: to, To: BlockRef{Chain: chain.index, Hash: *block.IncludedBy}})
+			}
+			for _, sub := range g.Chains {
+				if sub.Context == chain.Context+1 && sub.blocks[block.Hash] != nil {
+					edges = append(edges, &Edge{Kind: edgeCoincident, From: to, To: BlockRef{Chain: sub.index, Hash: block.Hash}})
+				}
+			}
+			for _, etx := range block.ETxs {
+				if etx.DestinationBlock == nil {
+					continue
+				}
+				for _, dest := range g.Chains {
+					if dest.Context == params.ZONE && string(dest.Location) == string(etx.Destination) && dest.blocks[*etx.DestinationBlock] != nil {
+						edges = append(edges, &Edge{Kind: edgeETx, From: to, To: BlockRef{Chain: dest.index, Hash: *etx.DestinationBlock}})
+					}
+				}
+			}
+		}
+	}
+	return edges
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+)
+
+// testHeader creates a child of the parent in the given context, mined at the
+// given order. A nil parent creates a genesis header.
+func testHeader(parent *types.Header, context int, time uint64, order int) *types.Header {
+	header := types.NewEmptyHeader()
+	for i := range header.Number {
+		header.Number[i] = new(big.Int)
+		header.Difficulty[i] = big.NewInt(int64(order))
+		header.BaseFee[i] = new(big.Int)
+		header.NetworkDifficulty[i] = new(big.Int)
+	}
+	if parent != nil {
+		copy(header.ParentHash, parent.ParentHash)
+		for i := range header.Number {
+			header.Number[i].Set(parent.Number[i])
+		}
+		header.ParentHash[context] = parent.Hash()
+		header.Number[context].Add(parent.Number[context], common.Big1)
+	}
+	header.Time = time
+	return header
+}
+
+// testOrder returns the order stored by testHeader in the difficulties.
+func testOrder(header *types.Header) (int, error) {
+	return int(header.Difficulty[0].Int64()), nil
+}
+
+func TestGraphEdges(t *testing.T) {
+	g := NewGraph(testOrder)
+	region := g.AddChain([]byte{1}, []int{0, 29})
+	zone := g.AddChain([]byte{1, 1}, []int{0, 9})
+	dest := g.AddChain([]byte{1, 2}, []int{10, 19})
+
+	// Region block 1 is coincident with zone block 2, zone block 1 has an uncle
+	genesis := testHeader(nil, 2, 0, 0)
+	z1 := testHeader(genesis, 2, 10, 2)
+	uncle := testHeader(genesis, 2, 11, 2)
+	z2 := testHeader(z1, 2, 20, 1)
+	z2.Number[1] = big.NewInt(1)
+
+	tx := types.NewTransaction(0, common.Address{15}, common.Big1, 21000, common.Big1, nil)
+	local := types.NewTransaction(1, common.Address{5}, common.Big1, 21000, common.Big1, nil)
+
+	g.AddBlock(zone, types.NewBlockWithHeader(genesis))
+	g.AddBlock(zone, types.NewBlockWithHeader(z1))
+	g.AddBlock(zone, types.NewBlockWithHeader(z2).WithBody([]*types.Transaction{tx, local}, []*types.Header{uncle}))
+	g.AddBlock(region, types.NewBlockWithHeader(z2))
+
+	applied := testHeader(nil, 2, 25, 2)
+	g.AddBlock(dest, types.NewBlockWithHeader(applied))
+	etxs := zone.Block(z2.Hash()).ETxs
+	if len(etxs) != 1 || etxs[0].Hash != tx.Hash() || !bytes.Equal(etxs[0].Destination, dest.Location) {
+		t.Fatalf("unexpected etxs: %+v", etxs)
+	}
+	hash := applied.Hash()
+	etxs[0].DestinationBlock = &hash
+
+	dropped := testHeader(z2, 2, 30, 2)
+	dropped.Extra[2] = []byte("dropped")
+	g.AddDropped(zone, dropped)
+
+	kinds := make(map[string]int)
+	for _, edge := range g.Edges() {
+		kinds[edge.Kind]++
+		switch edge.Kind {
+		case edgeCoincident:
+			if edge.From != (BlockRef{region.index, z2.Hash()}) || edge.To != (BlockRef{zone.index, z2.Hash()}) {
+				t.Errorf("unexpected coincident edge %+v", edge)
+			}
+		case edgeUncle:
+			if edge.From != (BlockRef{zone.index, uncle.Hash()}) || edge.To != (BlockRef{zone.index, z2.Hash()}) {
+				t.Errorf("unexpected uncle edge %+v", edge)
+			}
+		case edgeETx:
+			if edge.From != (BlockRef{zone.index, z2.Hash()}) || edge.To != (BlockRef{dest.index, applied.Hash()}) {
+				t.Errorf("unexpected etx edge %+v", edge)
+			}
+		}
+	}
+	// genesis->z1, genesis->uncle, z1->z2, z2->dropped
+	want := map[string]int{edgeParent: 4, edgeCoincident: 1, edgeUncle: 1, edgeETx: 1}
+	for kind, n := range want {
+		if kinds[kind] != n {
+			t.Errorf("%s edges: have %d, want %d", kind, kinds[kind], n)
+		}
+	}
+	if block := zone.Block(z2.Hash()); block.Order != 1 || !coincident(zone, block) {
+		t.Errorf("zone block 2 not coincident: order %d", block.Order)
+	}
+	if !zone.Block(dropped.Hash()).Dropped {
+		t.Error("dropped block not marked")
+	}
+	// Pruning drops the blocks below the number
+	zone.Prune(2)
+	if zone.Block(genesis.Hash()) != nil || zone.Block(z2.Hash()) == nil || zone.Lowest() != 2 {
+		t.Errorf("unexpected blocks after pruning, lowest %d", zone.Lowest())
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := NewGraph(testOrder)
+	prime := g.AddChain([]byte{}, []int{0, 255})
+	region := g.AddChain([]byte{1}, []int{0, 29})
+
+	genesis := testHeader(nil, 1, 0, 0)
+	r1 := testHeader(genesis, 1, 10, 0)
+	r1.Number[0] = big.NewInt(1)
+	g.AddBlock(region, types.NewBlockWithHeader(r1))
+	g.AddBlock(prime, types.NewBlockWithHeader(r1))
+
+	var dot bytes.Buffer
+	if err := writeDOT(&dot, g); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"digraph Quai", `label = "Prime"`, `label = "Region 1"`, "peripheries = 2", "dir = none"} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("dot output misses %q:\n%s", want, dot.String())
+		}
+	}
+	var out bytes.Buffer
+	if err := writeJSON(&out, g); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Chains []*Chain `json:"chains"`
+		Edges  []*Edge  `json:"edges"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Chains) != 2 || len(decoded.Chains[1].Blocks) != 1 || decoded.Chains[1].Blocks[0].Hash != r1.Hash() {
+		t.Errorf("unexpected json chains: %s", out.String())
+	}
+	if len(decoded.Edges) != 1 || decoded.Edges[0].Kind != edgeCoincident {
+		t.Errorf("unexpected json edges: %s", out.String())
+	}
+	var page bytes.Buffer
+	if err := writeHTML(&page, g, 5); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<svg", `content="5"`, "Region 1", `class="block coincident"`, `class="coincident-edge"`} {
+		if !strings.Contains(page.String(), want) {
+			t.Errorf("html output misses %q", want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// quai-viz draws the blocks of every chain of a Quai hierarchy, the coincident
+// blocks between them, uncles, reorgs and the ETxs sent between zones.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethclient/quaiclient"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/node"
+)
+
+var (
+	configFlag   = flag.String("config", "", "JSON file with the endpoints of the hierarchy ({\"Prime\": url, \"Regions\": [url], \"Zones\": [[url]]})")
+	primeFlag    = flag.String("prime", "", "authenticated endpoint of a Prime node to discover the hierarchy from, requires -jwtsecret")
+	secretFlag   = flag.String("jwtsecret", "", "file holding the hex encoded secret of the authenticated endpoints")
+	fromFlag     = flag.Uint64("from", 0, "first block of every chain to draw")
+	toFlag       = flag.Uint64("to", 0, "last block of every chain to draw")
+	countFlag    = flag.Uint64("count", 100, "number of latest blocks of every chain to draw, if -from and -to are not set")
+	reorgsFlag   = flag.Uint64("reorgs", 16, "number of latest reorgs of every chain to draw (0 = none)")
+	followFlag   = flag.Bool("follow", false, "keep following the tip of every chain, redrawing the output")
+	intervalFlag = flag.Duration("interval", 2*time.Second, "minimum time between two redraws when following")
+	formatFlag   = flag.String("format", "dot", "output format (dot, json or html)")
+	outFlag      = flag.String("out", "", "output file (default stdout, required when following)")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "-config <file> | -prime <url> [-jwtsecret <file>] [-from <block> -to <block> | -count <n>] [-follow] [-format dot|json|html] [-out <file>]")
+		flag.PrintDefaults()
+		fmt.Fprintln(os.Stderr, `
+Fetches the blocks of every chain of the hierarchy, either listed in a config
+file or discovered by following the subordinate urls of a Prime node, and
+draws them along with the coincident blocks between chains, uncles, blocks
+dropped by reorgs and the ETxs sent between zones. The DOT output can be
+rendered with Graphviz (dot -Tsvg), the HTML output is a self-contained page.`)
+	}
+}
+
+// renderers are the supported output formats.
+var renderers = map[string]func(w io.Writer, g *Graph, refresh int) error{
+	"dot":  func(w io.Writer, g *Graph, refresh int) error { return writeDOT(w, g) },
+	"json": func(w io.Writer, g *Graph, refresh int) error { return writeJSON(w, g) },
+	"html": writeHTML,
+}
+
+func main() {
+	flag.Parse()
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(false))))
+
+	render, ok := renderers[*formatFlag]
+	if !ok {
+		die(fmt.Errorf("unknown output format %q", *formatFlag))
+	}
+	if (*configFlag == "") == (*primeFlag == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *followFlag && *outFlag == "" {
+		die(fmt.Errorf("an output file is required when following"))
+	}
+	if *fromFlag > *toFlag {
+		die(fmt.Errorf("invalid block range %d-%d", *fromFlag, *toFlag))
+	}
+	ctx := context.Background()
+
+	config, err := hierarchy(ctx)
+	if err != nil {
+		die(err)
+	}
+	engine, err := blake3.New(blake3.Config{}, nil, false)
+	if err != nil {
+		die(err)
+	}
+	graph := NewGraph(engine.GetDifficultyOrder)
+	c, err := dial(ctx, config, graph, *reorgsFlag)
+	if err != nil {
+		die(err)
+	}
+	defer c.close()
+
+	if err := c.collectRange(ctx, *fromFlag, *toFlag, *countFlag); err != nil {
+		die(err)
+	}
+	if err := c.collectReorgs(ctx); err != nil {
+		die(err)
+	}
+	c.collectETxs(ctx)
+
+	if !*followFlag {
+		if err := output(render, graph, 0); err != nil {
+			die(err)
+		}
+		return
+	}
+	if err := follow(ctx, c, render); err != nil {
+		die(err)
+	}
+}
+
+// hierarchy loads the endpoints of the hierarchy from the config file or
+// discovers them from the Prime node.
+func hierarchy(ctx context.Context) (*quaiclient.HierarchyConfig, error) {
+	var secret []byte
+	if *secretFlag != "" {
+		var err error
+		if secret, err = node.ReadAuthSecret(*secretFlag); err != nil {
+			return nil, err
+		}
+	}
+	if *primeFlag != "" {
+		return quaiclient.DiscoverHierarchy(ctx, *primeFlag, secret)
+	}
+	data, err := ioutil.ReadFile(*configFlag)
+	if err != nil {
+		return nil, err
+	}
+	config := new(quaiclient.HierarchyConfig)
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", *configFlag, err)
+	}
+	config.Secret = secret
+	return config, nil
+}
+
+// follow subscribes to the new heads of every chain and redraws the graph at
+// most once per interval while new blocks arrive, keeping the latest count
+// blocks of each chain.
+func follow(ctx context.Context, c *collector, render func(io.Writer, *Graph, int) error) error {
+	type head struct {
+		src    *source
+		header *types.Header
+	}
+	heads := make(chan head, 64)
+	errc := make(chan error, len(c.sources))
+	for _, src := range c.sources {
+		ch := make(chan *types.Header)
+		sub, err := src.client.SubscribeNewHead(ctx, ch)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to the heads of %s: %v", src.chain.Name, err)
+		}
+		defer sub.Unsubscribe()
+
+		go func(src *source) {
+			for {
+				select {
+				case header := <-ch:
+					heads <- head{src, header}
+				case err := <-sub.Err():
+					errc <- fmt.Errorf("subscription to %s failed: %v", src.chain.Name, err)
+					return
+				}
+			}
+		}(src)
+	}
+	refresh := int(*intervalFlag / time.Second)
+	if refresh < 1 {
+		refresh = 1
+	}
+	if err := output(render, c.graph, refresh); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(*intervalFlag)
+	defer ticker.Stop()
+
+	dirty := false
+	for {
+		select {
+		case h := <-heads:
+			if err := c.collectHead(ctx, h.src, h.header, *countFlag); err != nil {
+				log.Warn("Failed to collect head", "err", err)
+				continue
+			}
+			dirty = true
+
+		case <-ticker.C:
+			if !dirty {
+				continue
+			}
+			if err := c.collectReorgs(ctx); err != nil {
+				log.Warn("Failed to collect reorgs", "err", err)
+			}
+			c.collectETxs(ctx)
+			if err := output(render, c.graph, refresh); err != nil {
+				return err
+			}
+			dirty = false
+
+		case err := <-errc:
+			return err
+		}
+	}
+}
+
+// output renders the graph to the output file, or stdout if not set. The file
+// is replaced atomically so viewers never see a partial drawing.
+func output(render func(io.Writer, *Graph, int) error, g *Graph, refresh int) error {
+	if *outFlag == "" {
+		return render(os.Stdout, g, refresh)
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(*outFlag), "."+filepath.Base(*outFlag))
+	if err != nil {
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := render(tmp, g, refresh); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), *outFlag)
+}
+
+func die(args ...interface{}) {
+	fmt.Fprintln(os.Stderr, args...)
+	os.Exit(1)
+}
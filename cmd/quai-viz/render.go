@@ -0,0 +1,270 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+// palette holds the colours of the chains, in the order they are drawn.
+var palette = []string{
+	"red", "green", "lawngreen", "limegreen", "mediumspringgreen",
+	"dodgerblue", "aqua", "blue", "#8a4cee",
+	"orange", "darkorange1", "orangered2", "#c55200",
+}
+
+// colour returns the colour of the chain at the given position.
+func colour(index int) string {
+	return palette[index%len(palette)]
+}
+
+// label returns the short name of a block: its number and hash prefix.
+func label(block *Block) string {
+	return fmt.Sprintf("%d %s", block.Number, block.Hash.Hex()[2:8])
+}
+
+// describe returns the details of a block shown on hover.
+func describe(chain *Chain, block *Block) string {
+	text := fmt.Sprintf("%s #%d\n%s\norder %d, %d txs", chain.Name, block.Number, block.Hash.Hex(), block.Order, block.Transactions)
+	if block.IncludedBy != nil {
+		text += "\nuncle of " + block.IncludedBy.Hex()
+	}
+	if block.Dropped {
+		text += "\ndropped by a reorg"
+	}
+	for _, etx := range block.ETxs {
+		text += fmt.Sprintf("\netx %s to %v: %s", etx.Hash.Hex(), []byte(etx.Destination), etx.Stage)
+	}
+	return text
+}
+
+// coincident reports whether the block is also a block of a dominant chain.
+func coincident(chain *Chain, block *Block) bool {
+	return block.Order >= 0 && block.Order < chain.Context
+}
+
+// offside returns 1 for the uncles and dropped blocks, 0 for canonical ones.
+func offside(block *Block) int {
+	if block.IncludedBy != nil || block.Dropped {
+		return 1
+	}
+	return 0
+}
+
+// nodeID returns the DOT identifier of a block of a chain.
+func nodeID(ref BlockRef) string {
+	return fmt.Sprintf("\"%d_%s\"", ref.Chain, ref.Hash.Hex())
+}
+
+// writeDOT renders the graph in the Graphviz DOT language, one cluster per
+// chain. Coincident blocks are drawn with a double border, uncles and blocks
+// dropped by a reorg dashed.
+func writeDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph Quai {\n\trankdir = LR\n\tnode [shape = box, style = filled, fillcolor = white]\n")
+	for _, chain := range g.Chains {
+		fmt.Fprintf(&b, "\tsubgraph cluster_%d {\n\t\tlabel = %q\n\t\tnode [color = %q]\n", chain.index, chain.Name, colour(chain.index))
+		for _, block := range chain.sorted() {
+			attrs := []string{fmt.Sprintf("label = %q", label(block)), fmt.Sprintf("tooltip = %q", describe(chain, block))}
+			if coincident(chain, block) {
+				attrs = append(attrs, "peripheries = 2")
+			}
+			switch {
+			case block.Dropped:
+				attrs = append(attrs, "style = \"filled,dashed\"", "fillcolor = mistyrose")
+			case block.IncludedBy != nil:
+				attrs = append(attrs, "style = \"filled,dashed\"", "fillcolor = lightgray")
+			}
+			fmt.Fprintf(&b, "\t\t%s [%s]\n", nodeID(BlockRef{Chain: chain.index, Hash: block.Hash}), strings.Join(attrs, ", "))
+		}
+		b.WriteString("\t}\n")
+	}
+	for _, edge := range g.Edges() {
+		var attrs string
+		switch edge.Kind {
+		case edgeCoincident:
+			attrs = " [dir = none, style = dotted, constraint = false]"
+		case edgeUncle:
+			attrs = " [style = dashed, color = gray]"
+		case edgeETx:
+			attrs = " [color = orange, penwidth = 2, constraint = false]"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s%s\n", nodeID(edge.From), nodeID(edge.To), attrs)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeJSON renders the chains and edges of the graph as JSON.
+func writeJSON(w io.Writer, g *Graph) error {
+	chains := make([]*Chain, len(g.Chains))
+	for i, chain := range g.Chains {
+		cpy := *chain
+		cpy.Blocks = chain.sorted()
+		chains[i] = &cpy
+	}
+	edges := g.Edges()
+	if edges == nil {
+		edges = []*Edge{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Chains []*Chain `json:"chains"`
+		Edges  []*Edge  `json:"edges"`
+	}{chains, edges})
+}
+
+// Layout of the HTML view, in pixels.
+const (
+	htmlMargin    = 120 // Width of the chain names on the left
+	htmlColumn    = 90  // Width of a timestamp column
+	htmlLane      = 120 // Height of the lane of a chain
+	htmlRow       = 28  // Vertical offset between the blocks sharing a column
+	htmlBlockW    = 76
+	htmlBlockH    = 22
+	htmlLegendTop = 40
+)
+
+// writeHTML renders the graph as a self-contained HTML page holding an SVG
+// drawing, one horizontal lane per chain. Blocks are laid out in columns by
+// timestamp so coincident blocks line up across the lanes. If refresh is not
+// zero, the page reloads itself every refresh seconds.
+func writeHTML(w io.Writer, g *Graph, refresh int) error {
+	// Assign a column to every distinct timestamp
+	var times []uint64
+	seen := make(map[uint64]bool)
+	for _, chain := range g.Chains {
+		for _, block := range chain.Blocks {
+			if !seen[block.Time] {
+				seen[block.Time] = true
+				times = append(times, block.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	columns := make(map[uint64]int, len(times))
+	for i, time := range times {
+		columns[time] = i
+	}
+	// Place the blocks, stacking the ones sharing a column of a lane with the
+	// canonical blocks on top
+	type point struct{ x, y int }
+	places := make(map[BlockRef]point)
+	for _, chain := range g.Chains {
+		blocks := chain.sorted()
+		sort.SliceStable(blocks, func(i, j int) bool {
+			return offside(blocks[i]) < offside(blocks[j])
+		})
+		rows := make(map[int]int)
+		for _, block := range blocks {
+			column := columns[block.Time]
+			places[BlockRef{Chain: chain.index, Hash: block.Hash}] = point{
+				x: htmlMargin + column*htmlColumn,
+				y: htmlLegendTop + chain.index*htmlLane + rows[column]*htmlRow,
+			}
+			rows[column]++
+		}
+	}
+	width := htmlMargin + len(times)*htmlColumn + htmlColumn
+	height := htmlLegendTop + len(g.Chains)*htmlLane
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	if refresh > 0 {
+		fmt.Fprintf(&b, "<meta http-equiv=\"refresh\" content=\"%d\">\n", refresh)
+	}
+	b.WriteString("<title>Quai hierarchy</title>\n<style>\n" +
+		"body { font-family: sans-serif; margin: 0; }\n" +
+		"text { font-size: 11px; }\n" +
+		".chain { font-weight: bold; font-size: 13px; }\n" +
+		".block rect { fill: white; stroke-width: 2; }\n" +
+		".coincident rect { stroke-width: 4; }\n" +
+		".uncle rect { fill: lightgray; stroke-dasharray: 4 2; }\n" +
+		".dropped rect { fill: mistyrose; stroke-dasharray: 4 2; }\n" +
+		".parent { stroke: black; }\n" +
+		".coincident-edge { stroke: gray; stroke-dasharray: 2 2; }\n" +
+		".uncle-edge { stroke: gray; stroke-dasharray: 4 2; }\n" +
+		".etx { stroke: orange; stroke-width: 2; }\n" +
+		"</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n", width, height)
+	b.WriteString("<defs><marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"6\" markerHeight=\"6\" orient=\"auto\">" +
+		"<path d=\"M 0 0 L 10 5 L 0 10 z\"/></marker></defs>\n")
+
+	// Legend
+	legend := []struct{ class, text string }{
+		{"parent", "parent"}, {"coincident-edge", "coincident"}, {"uncle-edge", "uncle"}, {"etx", "etx"},
+	}
+	for i, item := range legend {
+		x := htmlMargin + i*htmlColumn*2
+		fmt.Fprintf(&b, "<line class=\"%s\" x1=\"%d\" y1=\"16\" x2=\"%d\" y2=\"16\"/><text x=\"%d\" y=\"20\">%s</text>\n", item.class, x, x+30, x+36, item.text)
+	}
+	// Chain lanes
+	for _, chain := range g.Chains {
+		y := htmlLegendTop + chain.index*htmlLane
+		fmt.Fprintf(&b, "<text class=\"chain\" x=\"8\" y=\"%d\" fill=\"%s\">%s</text>\n", y+htmlBlockH/2+4, colour(chain.index), html.EscapeString(chain.Name))
+	}
+	// Edges below the blocks
+	for _, edge := range g.Edges() {
+		from, to := places[edge.From], places[edge.To]
+		class := edge.Kind
+		switch edge.Kind {
+		case edgeCoincident, edgeUncle:
+			class += "-edge"
+		}
+		x1, y1, x2, y2 := from.x+htmlBlockW, from.y+htmlBlockH/2, to.x, to.y+htmlBlockH/2
+		if edge.Kind == edgeCoincident {
+			x1, y1, x2, y2 = from.x+htmlBlockW/2, from.y+htmlBlockH, to.x+htmlBlockW/2, to.y
+		}
+		marker := " marker-end=\"url(#arrow)\""
+		if edge.Kind == edgeCoincident {
+			marker = ""
+		}
+		fmt.Fprintf(&b, "<line class=\"%s\" x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\"%s/>\n", class, x1, y1, x2, y2, marker)
+	}
+	// Blocks
+	for _, chain := range g.Chains {
+		for _, block := range chain.sorted() {
+			place := places[BlockRef{Chain: chain.index, Hash: block.Hash}]
+			classes := []string{"block"}
+			if coincident(chain, block) {
+				classes = append(classes, "coincident")
+			}
+			if block.IncludedBy != nil {
+				classes = append(classes, "uncle")
+			}
+			if block.Dropped {
+				classes = append(classes, "dropped")
+			}
+			fmt.Fprintf(&b, "<g class=\"%s\"><title>%s</title><rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"3\" stroke=\"%s\"/><text x=\"%d\" y=\"%d\">%s</text></g>\n",
+				strings.Join(classes, " "), html.EscapeString(describe(chain, block)),
+				place.x, place.y, htmlBlockW, htmlBlockH, colour(chain.index),
+				place.x+4, place.y+htmlBlockH/2+4, html.EscapeString(label(block)))
+		}
+	}
+	b.WriteString("</svg>\n</body>\n</html>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
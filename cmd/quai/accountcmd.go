@@ -25,6 +25,7 @@ import (
 	"github.com/spruce-solutions/go-quai/cmd/utils"
 	"github.com/spruce-solutions/go-quai/crypto"
 	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -109,12 +110,17 @@ Print a short summary of all accounts`,
 					utils.KeyStoreDirFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					utils.LocationFlag,
 				},
 				Description: `
     geth account new
 
 Creates a new account and prints the address.
 
+The address is generated for the zone given by --location as <region>,<zone>,
+or for the chain of the node if no location is given. With --location all, one
+account is created for every zone, all of them locked with the same password.
+
 The account is saved in encrypted format, you are prompted for a password.
 
 You must remember this password to unlock your account in the future.
@@ -269,11 +275,38 @@ func accountCreate(ctx *cli.Context) error {
 	}
 	utils.SetNodeConfig(ctx, &cfg.Node)
 	keydir, err := cfg.Node.KeyDirConfig()
-	idRange := cfg.Eth.Genesis.Config.ChainIDRange()
-
 	if err != nil {
 		utils.Fatalf("Failed to read configuration: %v", err)
 	}
+	// Grind the keys for the requested zones, or the chain of the node
+	ontology := params.MainnetOntology
+	if ctx.GlobalBool(utils.RopstenFlag.Name) {
+		ontology = params.TestnetOntology
+	}
+	var idRanges [][]int
+	if cfg.Eth.Genesis != nil {
+		if o := cfg.Eth.Genesis.Config.NetworkOntology(); o != nil {
+			ontology = o
+		}
+		idRanges = append(idRanges, cfg.Eth.Genesis.Config.ChainIDRange())
+	}
+	if ctx.IsSet(utils.LocationFlag.Name) {
+		locations, err := utils.ParseLocations(ctx.String(utils.LocationFlag.Name), ontology)
+		if err != nil {
+			utils.Fatalf("%v", err)
+		}
+		idRanges = idRanges[:0]
+		for _, location := range locations {
+			idRange, err := ontology.LocationPrefixRange(location)
+			if err != nil {
+				utils.Fatalf("%v", err)
+			}
+			idRanges = append(idRanges, idRange)
+		}
+	}
+	if len(idRanges) == 0 {
+		idRanges = append(idRanges, nil)
+	}
 	scryptN := keystore.StandardScryptN
 	scryptP := keystore.StandardScryptP
 	if cfg.Node.UseLightweightKDF {
@@ -283,14 +316,17 @@ func accountCreate(ctx *cli.Context) error {
 
 	password := utils.GetPassPhraseWithList("Your new account is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
 
-	account, err := keystore.StoreKey(keydir, password, idRange, scryptN, scryptP)
-
-	if err != nil {
-		utils.Fatalf("Failed to create account: %v", err)
+	for _, idRange := range idRanges {
+		account, err := keystore.StoreKey(keydir, password, idRange, scryptN, scryptP)
+		if err != nil {
+			utils.Fatalf("Failed to create account: %v", err)
+		}
+		fmt.Printf("\nYour new key was generated\n\n")
+		fmt.Printf("Public address of the key:   %s\n", account.Address.Hex())
+		fmt.Printf("Location of the key:         %s\n", utils.LocationString(ontology.AddressLocation(account.Address)))
+		fmt.Printf("Path of the secret key file: %s\n", account.URL.Path)
 	}
-	fmt.Printf("\nYour new key was generated\n\n")
-	fmt.Printf("Public address of the key:   %s\n", account.Address.Hex())
-	fmt.Printf("Path of the secret key file: %s\n\n", account.URL.Path)
+	fmt.Println()
 	fmt.Printf("- You can share your public address with anyone. Others need it to interact with you.\n")
 	fmt.Printf("- You must NEVER share the secret key with anyone! The key controls access to your funds!\n")
 	fmt.Printf("- You must BACKUP your key file! Without the key, it's impossible to access account funds!\n")
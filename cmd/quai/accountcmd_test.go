@@ -79,6 +79,7 @@ Your new key was generated
 `)
 	geth.ExpectRegexp(`
 Public address of the key:   0x[0-9a-fA-F]{40}
+Location of the key:         ([0-9]+,[0-9]+|unknown)
 Path of the secret key file: .*UTC--.+--[0-9a-f]{40}
 
 - You can share your public address with anyone. Others need it to interact with you.
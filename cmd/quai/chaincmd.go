@@ -38,6 +38,7 @@ import (
 	"github.com/spruce-solutions/go-quai/log"
 	"github.com/spruce-solutions/go-quai/metrics"
 	"github.com/spruce-solutions/go-quai/node"
+	"github.com/spruce-solutions/go-quai/rlp"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -49,6 +50,7 @@ var (
 		ArgsUsage: "<genesisPath>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.OntologyForkHeaderFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -56,7 +58,9 @@ The init command initializes a new genesis block and definition for the network.
 This is a destructive action and changes the network in which you will be
 participating.
 
-It expects the genesis file as argument.`,
+It expects the genesis file as argument. Chains created by an ontology fork
+derive their genesis from it and the prime header activating the fork, given
+by --ontology.forkheader.`,
 	}
 	dumpGenesisCommand = cli.Command{
 		Action:    utils.MigrateFlags(dumpGenesis),
@@ -103,7 +107,9 @@ The dumpgenesis command dumps the genesis block configuration in JSON format to
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 The import command imports blocks from an RLP-encoded form. The form can be one file
-with several RLP-encoded blocks, or several files can be used.
+with several RLP-encoded blocks, or several files can be used. Exports bundling blocks
+with their receipts and external blocks are imported without access to the dominant
+and subordinate chains, plain streams of blocks still require them.
 
 If only one file is used, import error will result in failure. If several files are used,
 processing will proceed even if an individual RLP-file import failure occurs.`,
@@ -124,7 +130,8 @@ Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last block to write. In this mode, the file will be appended
 if already existing. If the file ends with .gz, the output will
-be gzipped.`,
+be gzipped. Every block is written together with its receipts
+and the external blocks it references.`,
 	}
 	importPreimagesCommand = cli.Command{
 		Action:    utils.MigrateFlags(importPreimages),
@@ -194,6 +201,23 @@ func initGenesis(ctx *cli.Context) error {
 	if err := json.NewDecoder(file).Decode(genesis); err != nil {
 		utils.Fatalf("invalid genesis file: %v", err)
 	}
+	// Chains created by an ontology fork are anchored to the fork block
+	if genesis.Config != nil && genesis.Config.OntologyForkBlock() != nil {
+		if !ctx.GlobalIsSet(utils.OntologyForkHeaderFlag.Name) {
+			utils.Fatalf("Chain is created by the ontology fork at prime block %v, its header is required", genesis.Config.OntologyForkBlock())
+		}
+		enc, err := hexutil.Decode(ctx.GlobalString(utils.OntologyForkHeaderFlag.Name))
+		if err != nil {
+			utils.Fatalf("Invalid ontology fork header: %v", err)
+		}
+		fork := new(types.Header)
+		if err := rlp.DecodeBytes(enc, fork); err != nil {
+			utils.Fatalf("Invalid ontology fork header: %v", err)
+		}
+		if genesis, err = core.OntologyForkGenesisBlock(genesis, genesis.Config, fork); err != nil {
+			utils.Fatalf("Failed to derive the genesis of the ontology fork: %v", err)
+		}
+	}
 	// Open and initialise both full and light databases
 	stack, _ := makeConfigNode(ctx)
 	defer stack.Close()
@@ -329,8 +353,8 @@ func exportChain(ctx *cli.Context) error {
 		if first < 0 || last < 0 {
 			utils.Fatalf("Export error: block number must be greater than 0\n")
 		}
-		if head := chain.CurrentFastBlock(); uint64(last) > head.NumberU64() {
-			utils.Fatalf("Export error: block number %d larger than head block %d\n", uint64(last), head.NumberU64())
+		if head := chain.CurrentFastBlock(); uint64(last) > head.NumberU64(chain.Config().Context) {
+			utils.Fatalf("Export error: block number %d larger than head block %d\n", uint64(last), head.NumberU64(chain.Config().Context))
 		}
 		err = utils.ExportAppendChain(chain, fp, uint64(first), uint64(last))
 	}
@@ -435,7 +459,7 @@ func parseDumpConfig(ctx *cli.Context, stack *node.Node) (*state.DumpConfig, eth
 	log.Info("State dump configured", "block", header.Number, "hash", header.Hash().Hex(),
 		"skipcode", conf.SkipCode, "skipstorage", conf.SkipStorage,
 		"start", hexutil.Encode(conf.Start), "limit", conf.Max)
-	return conf, db, header.Root[types.QuaiNetworkContext], nil
+	return conf, db, header.Root[utils.MakeChainContext(db)], nil
 }
 
 func dump(ctx *cli.Context) error {
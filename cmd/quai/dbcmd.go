@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/olekukonko/tablewriter"
 	"github.com/spruce-solutions/go-quai/cmd/utils"
 	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/common/hexutil"
@@ -64,6 +65,7 @@ Remove blockchain and state databases`,
 			dbPutCmd,
 			dbGetSlotsCmd,
 			dbDumpFreezerIndex,
+			dbExtBlocksCmd,
 		},
 	}
 	dbInspectCmd = cli.Command{
@@ -173,6 +175,57 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		},
 		Description: "This command displays information about the freezer index.",
 	}
+	dbExtBlocksCmd = cli.Command{
+		Name:      "extblocks",
+		Usage:     "Low level external block table operations",
+		ArgsUsage: "",
+		Subcommands: []cli.Command{
+			dbExtBlocksInspectCmd,
+			dbExtBlocksCountCmd,
+			dbExtBlocksPruneCmd,
+		},
+	}
+	dbExtBlocksInspectCmd = cli.Command{
+		Action:    utils.MigrateFlags(extBlocksInspect),
+		Name:      "inspect",
+		Usage:     "Show a stored external block or the external blocks linked by a block",
+		ArgsUsage: "<hash> [context]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.RegionFlag,
+			utils.ZoneFlag,
+		},
+		Description: `This command looks up the external block with the given hash, in the given
+context or in any context if none is given. If no such external block is stored,
+the hash is taken to be of a local block and the external blocks it links are shown.`,
+	}
+	dbExtBlocksCountCmd = cli.Command{
+		Action: utils.MigrateFlags(extBlocksCount),
+		Name:   "count",
+		Usage:  "Count the stored external blocks per context",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.RegionFlag,
+			utils.ZoneFlag,
+		},
+		Description: "This command iterates the external block table and summarizes it per context.",
+	}
+	dbExtBlocksPruneCmd = cli.Command{
+		Action:    utils.MigrateFlags(extBlocksPrune),
+		Name:      "prune",
+		Usage:     "Delete the external blocks retained under old blocks",
+		ArgsUsage: "[number]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.RegionFlag,
+			utils.ZoneFlag,
+			utils.FinalityDepthFlag,
+			utils.ExternalBlockRetentionFlag,
+		},
+		Description: `This command deletes the external blocks retained under blocks at or below
+the given number. Without a number, the external blocks retained under blocks
+more than the configured retention below the head are deleted.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -205,13 +258,6 @@ func removeDB(ctx *cli.Context) error {
 		} else {
 			log.Info("Full node ancient database missing", "path", path)
 		}
-
-		extPath := filepath.Join(d, "quai", "externalblocks")
-		if common.FileExist(extPath) {
-			confirmAndRemoveDB(extPath, "full external blocks db")
-		} else {
-			log.Info("Full external blocks database missing", "path", extPath)
-		}
 		// Remove the light node database
 		/* path = d.ResolvePath("lightchaindata")
 		if common.FileExist(path) {
@@ -501,3 +547,143 @@ func freezerInspect(ctx *cli.Context) error {
 	}
 	return nil
 }
+
+// extBlocksInspect shows a stored external block, or the external blocks linked
+// by a local block.
+func extBlocksInspect(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		return fmt.Errorf("required arguments: %v", ctx.Command.ArgsUsage)
+	}
+	hash := common.HexToHash(ctx.Args().Get(0))
+	var contexts []uint64
+	for context := 0; context < types.ContextDepth; context++ {
+		contexts = append(contexts, uint64(context))
+	}
+	if ctx.NArg() == 2 {
+		context, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+		if err != nil || context >= uint64(types.ContextDepth) {
+			return fmt.Errorf("invalid context %q", ctx.Args().Get(1))
+		}
+		contexts = []uint64{context}
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	var found bool
+	for _, context := range contexts {
+		if block := rawdb.ReadExternalBlock(db, hash, context); block != nil {
+			printExternalBlock(block, rawdb.ReadExternalBlockIndex(db, hash, context))
+			found = true
+		}
+	}
+	if found {
+		return nil
+	}
+	linked := rawdb.ReadCoincidentExternalBlocks(db, hash)
+	if len(linked) == 0 {
+		return fmt.Errorf("no external block stored for %x", hash)
+	}
+	fmt.Printf("Block %x links %d external blocks\n", hash, len(linked))
+	for _, block := range linked {
+		printExternalBlock(block, rawdb.ReadExternalBlockIndex(db, block.Hash(), block.Context().Uint64()))
+	}
+	return nil
+}
+
+// printExternalBlock prints the summary of an external block and its index.
+func printExternalBlock(block *types.ExternalBlock, index *rawdb.ExternalBlockIndex) {
+	header := block.Header()
+	fmt.Printf("External block %x\n", block.Hash())
+	fmt.Printf("  context:    %d\n", block.Context())
+	fmt.Printf("  numbers:    %v\n", header.Number)
+	fmt.Printf("  location:   %v\n", header.Location)
+	fmt.Printf("  txs:        %d\n", len(block.Transactions()))
+	fmt.Printf("  receipts:   %d\n", len(block.Receipts()))
+	fmt.Printf("  size:       %v\n", block.Size())
+	if index == nil {
+		fmt.Printf("  retained:   unindexed\n")
+		return
+	}
+	fmt.Printf("  retained:   #%d\n", index.Number)
+	for _, coincident := range index.Coincident {
+		fmt.Printf("  linked by:  %x\n", coincident)
+	}
+}
+
+// extBlocksCount summarizes the external block table per context.
+func extBlocksCount(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	var (
+		stats  [][]string
+		blocks int
+		total  common.StorageSize
+	)
+	for _, stat := range rawdb.InspectExternalBlocks(db) {
+		stats = append(stats, []string{
+			strconv.FormatUint(stat.Context, 10),
+			strconv.Itoa(stat.Blocks),
+			strconv.Itoa(stat.Linked),
+			stat.Size.String(),
+			fmt.Sprintf("#%d - #%d", stat.Earliest, stat.Latest),
+		})
+		blocks += stat.Blocks
+		total += stat.Size
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Context", "Blocks", "Linked", "Size", "Retained under"})
+	table.SetFooter([]string{"Total", strconv.Itoa(blocks), "", total.String(), ""})
+	table.AppendBulk(stats)
+	table.Render()
+	return nil
+}
+
+// extBlocksPrune deletes the external blocks retained under blocks at or below
+// a number, by default the configured retention below the head.
+func extBlocksPrune(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("Max 1 argument: %v", ctx.Command.ArgsUsage)
+	}
+	stack, cfg := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	var limit uint64
+	if ctx.NArg() == 1 {
+		number, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q: %v", ctx.Args().Get(0), err)
+		}
+		limit = number
+	} else {
+		retention := cfg.Eth.ExternalBlockRetention
+		if retention == 0 {
+			return fmt.Errorf("external blocks are kept forever, specify a number")
+		}
+		if retention < cfg.Eth.FinalityDepth {
+			retention = cfg.Eth.FinalityDepth
+		}
+		head := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db))
+		if head == nil {
+			return fmt.Errorf("head header not found")
+		}
+		if *head <= retention {
+			log.Info("No external blocks to prune", "head", *head, "retention", retention)
+			return nil
+		}
+		limit = *head - retention
+	}
+	start := time.Now()
+	pruned := rawdb.PruneExternalBlocks(db, limit)
+	log.Info("Pruned external blocks", "count", pruned, "limit", limit, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
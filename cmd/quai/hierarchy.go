@@ -0,0 +1,99 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spruce-solutions/go-quai/cmd/utils"
+	"github.com/spruce-solutions/go-quai/eth/hierarchy"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// runHierarchy starts the full Prime, Region and Zone hierarchy inside this
+// process and blocks until it is interrupted.
+func runHierarchy(ctx *cli.Context) error {
+	// The configured stack is only used to resolve the flags and config file,
+	// every context of the hierarchy gets its own node below its data dir.
+	stack, cfg := makeConfigNode(ctx)
+	stack.Close()
+
+	config := &hierarchy.Config{
+		Node:    cfg.Node,
+		Eth:     cfg.Eth,
+		Ropsten: ctx.GlobalBool(utils.RopstenFlag.Name),
+	}
+	if ctx.GlobalIsSet(utils.HierarchyOntologyFlag.Name) {
+		config.Ontology = &params.Ontology{BaseChainID: params.MainnetOntology.BaseChainID}
+		if config.Ropsten {
+			config.Ontology.BaseChainID = params.TestnetOntology.BaseChainID
+		}
+		shape := ctx.GlobalString(utils.HierarchyOntologyFlag.Name)
+		if _, err := fmt.Sscanf(shape, "%dx%d", &config.Ontology.Regions, &config.Ontology.Zones); err != nil {
+			utils.Fatalf("Invalid hierarchy ontology %q: %v", shape, err)
+		}
+	}
+	if ctx.GlobalIsSet(utils.HierarchyOntologyForksFlag.Name) {
+		for _, spec := range utils.SplitAndTrim(ctx.GlobalString(utils.HierarchyOntologyForksFlag.Name)) {
+			var (
+				fork   = &params.OntologyFork{}
+				number uint64
+			)
+			if _, err := fmt.Sscanf(spec, "%d:%dx%d", &number, &fork.Regions, &fork.Zones); err != nil {
+				utils.Fatalf("Invalid hierarchy ontology fork %q: %v", spec, err)
+			}
+			fork.Block = new(big.Int).SetUint64(number)
+			config.OntologyForks = append(config.OntologyForks, fork)
+		}
+	}
+	h, err := hierarchy.New(config)
+	if err != nil {
+		utils.Fatalf("Failed to create the hierarchy: %v", err)
+	}
+	defer h.Close()
+
+	if err := h.Start(); err != nil {
+		utils.Fatalf("Failed to start the hierarchy: %v", err)
+	}
+	if ctx.GlobalBool(utils.MiningEnabledFlag.Name) {
+		gasprice := utils.GlobalBig(ctx, utils.MinerGasPriceFlag.Name)
+		threads := ctx.GlobalInt(utils.MinerThreadsFlag.Name)
+		// Blocks are mined in the zones and delivered to their dominant chains.
+		for _, instance := range h.Instances() {
+			if instance.Context != params.ZONE {
+				continue
+			}
+			instance.Eth.TxPool().SetGasPrice(gasprice)
+			if err := instance.Eth.StartMining(threads); err != nil {
+				utils.Fatalf("Failed to start mining in %s: %v", instance.Name, err)
+			}
+		}
+	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	<-sigc
+	log.Info("Got interrupt, shutting down hierarchy...")
+	return nil
+}
@@ -83,6 +83,10 @@ var (
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.FinalityDepthFlag,
+		utils.ExternalBlockRetentionFlag,
+		utils.PCRCDepthFlag,
+		utils.PCRCCheckpointFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -117,6 +121,9 @@ var (
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNoVerifyFlag,
+		utils.MinerStratumFlag,
+		utils.MinerStratumV2Flag,
+		utils.MinerStratumDifficultyFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
@@ -159,6 +166,10 @@ var (
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
+		utils.AuthListenFlag,
+		utils.AuthPortFlag,
+		utils.AuthVirtualHostsFlag,
+		utils.JWTSecretFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
@@ -169,6 +180,9 @@ var (
 		utils.ZoneFlag,
 		utils.DomUrl,
 		utils.SubUrls,
+		utils.HierarchyFlag,
+		utils.HierarchyOntologyFlag,
+		utils.HierarchyOntologyForksFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -221,6 +235,10 @@ func init() {
 		dumpConfigCommand,
 		// see dbcmd.go
 		dbCommand,
+		// See managercmd.go
+		managerCommand,
+		// See statscmd.go
+		statsAggregatorCommand,
 		// See cmd/utils/flags_legacy.go
 		utils.ShowDeprecated,
 		// See snapshot.go
@@ -287,6 +305,9 @@ func geth(ctx *cli.Context) error {
 	}
 
 	prepare(ctx)
+	if ctx.GlobalBool(utils.HierarchyFlag.Name) {
+		return runHierarchy(ctx)
+	}
 	stack, backend := makeFullNode(ctx)
 	defer stack.Close()
 
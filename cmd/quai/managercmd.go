@@ -0,0 +1,115 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spruce-solutions/go-quai/cmd/utils"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/ethclient/quaiclient"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/miner/manager"
+	"github.com/spruce-solutions/go-quai/miner/stratum"
+	"github.com/spruce-solutions/go-quai/node"
+	"github.com/spruce-solutions/go-quai/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var managerCommand = cli.Command{
+	Action:    utils.MigrateFlags(runManager),
+	Name:      "manager",
+	Usage:     "Mine a slice of the hierarchy through its Prime, Region and Zone nodes",
+	ArgsUsage: "<prime-url> <region-url> <zone-url>",
+	Flags: []cli.Flag{
+		utils.MinerThreadsFlag,
+		utils.MinerStratumFlag,
+		utils.MinerStratumV2Flag,
+		utils.MinerStratumDifficultyFlag,
+		utils.JWTSecretFlag,
+	},
+	Category: "MINER COMMANDS",
+	Description: `
+The manager command follows the pending blocks of the Prime, Region and Zone
+nodes of a slice over their websocket endpoints and merges them into a single
+header. Mined blocks are delivered through the authenticated API, so the urls
+must point at the authenticated endpoints of the nodes and --authrpc.jwtsecret
+at the secret they share. The header is mined with --miner.threads CPU threads (a negative count
+disables local mining) and served to external miners on the stratum endpoints.
+Every solution is delivered to each context whose difficulty it satisfies.`,
+}
+
+// runManager connects to the nodes of a slice and mines their combined work
+// until it is interrupted.
+func runManager(ctx *cli.Context) error {
+	if ctx.NArg() != params.ZONE+1 {
+		utils.Fatalf("This command requires the urls of the Prime, Region and Zone nodes.")
+	}
+	if !ctx.IsSet(utils.JWTSecretFlag.Name) {
+		utils.Fatalf("The manager requires the secret of the authenticated endpoints (--%s).", utils.JWTSecretFlag.Name)
+	}
+	secret, err := node.ReadAuthSecret(ctx.String(utils.JWTSecretFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read the JWT secret: %v", err)
+	}
+	chains := make([]manager.Chain, 0, ctx.NArg())
+	for _, url := range ctx.Args() {
+		client, err := quaiclient.DialWithAuth(context.Background(), url, secret)
+		if err != nil {
+			utils.Fatalf("Failed to connect to %s: %v", url, err)
+		}
+		defer client.Close()
+		chains = append(chains, client)
+	}
+	threads := ctx.Int(utils.MinerThreadsFlag.Name)
+	engine, err := blake3.New(blake3.Config{MiningThreads: threads}, nil, false)
+	if err != nil {
+		utils.Fatalf("Failed to create the mining engine: %v", err)
+	}
+	m, err := manager.New(chains, engine, threads >= 0)
+	if err != nil {
+		utils.Fatalf("Failed to create the manager: %v", err)
+	}
+	m.Start()
+	defer m.Stop()
+
+	stratumConfig := stratum.DefaultConfig
+	stratumConfig.ListenAddr = ctx.String(utils.MinerStratumFlag.Name)
+	stratumConfig.ListenAddrV2 = ctx.String(utils.MinerStratumV2Flag.Name)
+	if difficulty := ctx.Uint64(utils.MinerStratumDifficultyFlag.Name); difficulty != 0 {
+		stratumConfig.Difficulty = difficulty
+	}
+	if stratumConfig.ListenAddr != "" || stratumConfig.ListenAddrV2 != "" {
+		server := stratum.New(stratumConfig, engine, m)
+		if err := server.Start(); err != nil {
+			utils.Fatalf("Failed to start the stratum server: %v", err)
+		}
+		defer server.Stop()
+	}
+	log.Info("Started hierarchy manager", "threads", threads, "stratum", stratumConfig.ListenAddr, "stratumv2", stratumConfig.ListenAddrV2)
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	<-sigc
+	log.Info("Got interrupt, shutting down manager...")
+	return nil
+}
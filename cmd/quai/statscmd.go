@@ -0,0 +1,78 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spruce-solutions/go-quai/cmd/utils"
+	"github.com/spruce-solutions/go-quai/ethstats"
+	"github.com/spruce-solutions/go-quai/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	statsAggregatorAddrFlag = cli.StringFlag{
+		Name:  "addr",
+		Usage: "Listening address of the stats aggregator",
+		Value: ":3000",
+	}
+	statsAggregatorSecretFlag = cli.StringFlag{
+		Name:  "secret",
+		Usage: "Secret the reporting nodes have to authenticate with",
+	}
+	statsAggregatorCommand = cli.Command{
+		Action: utils.MigrateFlags(runStatsAggregator),
+		Name:   "statsaggregator",
+		Usage:  "Aggregate the stats reported by the nodes of the whole hierarchy",
+		Flags: []cli.Flag{
+			statsAggregatorAddrFlag,
+			statsAggregatorSecretFlag,
+		},
+		Category: "MISCELLANEOUS COMMANDS",
+		Description: `
+The statsaggregator command accepts the reports of nodes started with
+--quaistats nodename:secret@host:port and groups them by the Prime, Region and
+Zone chain they follow. The health of the whole hierarchy is served as JSON on
+the listening address, the nodes report on its /api endpoint.`,
+	}
+)
+
+// runStatsAggregator serves the stats aggregator until it is interrupted.
+func runStatsAggregator(ctx *cli.Context) error {
+	listener, err := net.Listen("tcp", ctx.String(statsAggregatorAddrFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to listen for stats reports: %v", err)
+	}
+	server := &http.Server{Handler: ethstats.NewAggregator(ctx.String(statsAggregatorSecretFlag.Name))}
+	go server.Serve(listener)
+	defer server.Close()
+
+	log.Info("Started stats aggregator", "addr", listener.Addr())
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	<-sigc
+	log.Info("Got interrupt, shutting down stats aggregator...")
+	return nil
+}
@@ -47,6 +47,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.ExitWhenSyncedFlag,
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
+			utils.FinalityDepthFlag,
+			utils.ExternalBlockRetentionFlag,
+			utils.PCRCDepthFlag,
+			utils.PCRCCheckpointFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
@@ -131,6 +135,10 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.WSApiFlag,
 			utils.WSPathPrefixFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.AuthListenFlag,
+			utils.AuthPortFlag,
+			utils.AuthVirtualHostsFlag,
+			utils.JWTSecretFlag,
 			utils.GraphQLEnabledFlag,
 			utils.GraphQLCORSDomainFlag,
 			utils.GraphQLVirtualHostsFlag,
@@ -142,6 +150,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.PreloadJSFlag,
 			utils.DomUrl,
 			utils.SubUrls,
+			utils.HierarchyFlag,
+			utils.HierarchyOntologyFlag,
+			utils.HierarchyOntologyForksFlag,
 		},
 	},
 	{
@@ -173,6 +184,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerExtraDataFlag,
 			utils.MinerRecommitIntervalFlag,
 			utils.MinerNoVerifyFlag,
+			utils.MinerStratumFlag,
+			utils.MinerStratumV2Flag,
+			utils.MinerStratumDifficultyFlag,
 		},
 	},
 	{
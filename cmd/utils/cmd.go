@@ -155,30 +155,40 @@ func ImportChain(chain *core.BlockChain, fn string) error {
 			return err
 		}
 	}
-	stream := rlp.NewStream(reader, 0)
+	exports, err := core.NewExportReader(reader)
+	if err != nil {
+		return err
+	}
+	if err := exports.Verify(chain); err != nil {
+		return err
+	}
+	if exports.Header() == nil {
+		log.Warn("Importing plain blocks without external blocks", "file", fn)
+	}
 
 	// Run actual the import.
+	exported := make([]*core.ExportedBlock, importBatchSize)
 	blocks := make(types.Blocks, importBatchSize)
 	n := 0
 	for batch := 0; ; batch++ {
-		// Load a batch of RLP blocks.
+		// Load a batch of exported blocks.
 		if checkInterrupt() {
 			return fmt.Errorf("interrupted")
 		}
 		i := 0
 		for ; i < importBatchSize; i++ {
-			var b types.Block
-			if err := stream.Decode(&b); err == io.EOF {
+			b, err := exports.Next()
+			if err == io.EOF {
 				break
 			} else if err != nil {
 				return fmt.Errorf("at block %d: %v", n, err)
 			}
 			// don't import first block
-			if b.NumberU64() == 0 {
+			if b.Block.NumberU64(chain.Config().Context) == 0 {
 				i--
 				continue
 			}
-			blocks[i] = &b
+			exported[i], blocks[i] = b, b.Block
 			n++
 		}
 		if i == 0 {
@@ -193,7 +203,7 @@ func ImportChain(chain *core.BlockChain, fn string) error {
 			log.Info("Skipping batch as all blocks present", "batch", batch, "first", blocks[0].Hash(), "last", blocks[i-1].Hash())
 			continue
 		}
-		if _, err := chain.InsertChain(missing); err != nil {
+		if _, err := chain.InsertExportedChain(exported[i-len(missing) : i]); err != nil {
 			return fmt.Errorf("invalid block %d: %v", n, err)
 		}
 	}
@@ -201,17 +211,17 @@ func ImportChain(chain *core.BlockChain, fn string) error {
 }
 
 func missingBlocks(chain *core.BlockChain, blocks []*types.Block) []*types.Block {
-	head := chain.CurrentBlock()
+	head, context := chain.CurrentBlock(), chain.Config().Context
 	for i, block := range blocks {
 		// If we're behind the chain head, only check block, state is available at head
-		if head.NumberU64() > block.NumberU64() {
-			if !chain.HasBlock(block.Hash(), block.NumberU64()) {
+		if head.NumberU64(context) > block.NumberU64(context) {
+			if !chain.HasBlock(block.Hash(), block.NumberU64(context)) {
 				return blocks[i:]
 			}
 			continue
 		}
 		// If we're above the chain head, state availability is a must
-		if !chain.HasBlockAndState(block.Hash(), block.NumberU64()) {
+		if !chain.HasBlockAndState(block.Hash(), block.NumberU64(context)) {
 			return blocks[i:]
 		}
 	}
@@ -44,7 +44,6 @@ import (
 	"github.com/spruce-solutions/go-quai/consensus/clique"
 	"github.com/spruce-solutions/go-quai/core"
 	"github.com/spruce-solutions/go-quai/core/rawdb"
-	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/core/vm"
 	"github.com/spruce-solutions/go-quai/crypto"
 	"github.com/spruce-solutions/go-quai/eth"
@@ -63,6 +62,7 @@ import (
 	"github.com/spruce-solutions/go-quai/metrics/exp"
 	"github.com/spruce-solutions/go-quai/metrics/influxdb"
 	"github.com/spruce-solutions/go-quai/miner"
+	"github.com/spruce-solutions/go-quai/miner/stratum"
 	"github.com/spruce-solutions/go-quai/node"
 	"github.com/spruce-solutions/go-quai/p2p"
 	"github.com/spruce-solutions/go-quai/p2p/enode"
@@ -215,6 +215,23 @@ var (
 		Usage: "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value: ethconfig.Defaults.TxLookupLimit,
 	}
+	FinalityDepthFlag = cli.Uint64Flag{
+		Name:  "forkchoice.finality",
+		Usage: "Number of blocks after which the fork choice never reorgs them (0 = no limit)",
+	}
+	ExternalBlockRetentionFlag = cli.Uint64Flag{
+		Name:  "extblocks.retention",
+		Usage: "Number of blocks below the head external blocks are kept for, at least the finality depth (0 = keep all)",
+		Value: ethconfig.Defaults.ExternalBlockRetention,
+	}
+	PCRCDepthFlag = cli.Uint64Flag{
+		Name:  "pcrc.depth",
+		Usage: "Number of previous prime termini PCRC is re-applied to when importing blocks (0 = single check)",
+	}
+	PCRCCheckpointFlag = cli.StringFlag{
+		Name:  "pcrc.checkpoint",
+		Usage: "Hash of a trusted prime terminus deep PCRC verification stops at",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -424,6 +441,19 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerStratumFlag = cli.StringFlag{
+		Name:  "miner.stratum",
+		Usage: "Stratum v1 server listening address (e.g. 0.0.0.0:3333, disabled if empty)",
+	}
+	MinerStratumV2Flag = cli.StringFlag{
+		Name:  "miner.stratum.v2",
+		Usage: "Stratum v2 server listening address (e.g. 0.0.0.0:3336, disabled if empty)",
+	}
+	MinerStratumDifficultyFlag = cli.Uint64Flag{
+		Name:  "miner.stratum.difficulty",
+		Usage: "Initial share difficulty of stratum connections",
+		Value: stratum.DefaultConfig.Difficulty,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -557,6 +587,26 @@ var (
 		Usage: "HTTP path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
 		Value: "",
 	}
+	AuthListenFlag = cli.StringFlag{
+		Name:  "authrpc.addr",
+		Usage: "Listening address for the authenticated API of the dominant and subordinate chains (empty disables it)",
+		Value: node.DefaultAuthHost,
+	}
+	AuthPortFlag = cli.IntFlag{
+		Name:  "authrpc.port",
+		Usage: "Listening port for the authenticated API of the dominant and subordinate chains",
+		Value: node.DefaultAuthPort,
+	}
+	AuthVirtualHostsFlag = cli.StringFlag{
+		Name:  "authrpc.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept authenticated requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.AuthVirtualHosts, ","),
+	}
+	JWTSecretFlag = cli.StringFlag{
+		Name:  "authrpc.jwtsecret",
+		Usage: "Path to a hex encoded secret shared by the nodes of the hierarchy to authenticate with each other",
+		Value: "",
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -748,14 +798,36 @@ var (
 	}
 	DomUrl = cli.StringFlag{
 		Name:  "dom.url",
-		Usage: "Dominant chain websocket url",
+		Usage: "Dominant chain websocket url, pointing at its authenticated endpoint",
 		Value: ethconfig.Defaults.DomUrl,
 	}
 	SubUrls = cli.StringFlag{
 		Name:  "sub.urls",
-		Usage: "Subordinate chain websocket urls",
+		Usage: "Subordinate chain websocket urls, pointing at their authenticated endpoints",
 		Value: ethconfig.Defaults.DomUrl,
 	}
+	HierarchyFlag = cli.BoolFlag{
+		Name:  "hierarchy",
+		Usage: "Run every Prime, Region and Zone context inside this process",
+	}
+	HierarchyOntologyFlag = cli.StringFlag{
+		Name:  "hierarchy.ontology",
+		Usage: "Shape of the in-process hierarchy as <regions>x<zones> (default = shape of the network)",
+	}
+	HierarchyOntologyForksFlag = cli.StringFlag{
+		Name:  "hierarchy.ontologyforks",
+		Usage: "Comma separated expansions of the in-process hierarchy as <prime block>:<regions>x<zones>, requires --hierarchy.ontology",
+	}
+	OntologyForkHeaderFlag = cli.StringFlag{
+		Name:  "ontology.forkheader",
+		Usage: "RLP of the prime header activating the ontology fork which creates the chain, in hex as returned by debug_getHeaderRlp",
+	}
+
+	// Key generation settings
+	LocationFlag = cli.StringFlag{
+		Name:  "location",
+		Usage: `Zone to generate keys for as <region>,<zone>, or "all" for one key per zone (default = any address)`,
+	}
 )
 
 // MakeDataDir retrieves the currently requested data directory, terminating
@@ -888,6 +960,31 @@ func setNAT(ctx *cli.Context, cfg *p2p.Config) {
 
 // SplitAndTrim splits input separated by a comma
 // and trims excessive white space from the substrings.
+// ParseLocations parses a zone location given as <region>,<zone>, or "all"
+// for the locations of every zone of the ontology.
+func ParseLocations(input string, ontology *params.Ontology) ([][]byte, error) {
+	if strings.TrimSpace(input) == "all" {
+		return ontology.ZoneLocations(), nil
+	}
+	var region, zone int
+	if _, err := fmt.Sscanf(strings.TrimSpace(input), "%d,%d", &region, &zone); err != nil {
+		return nil, fmt.Errorf("invalid location %q, want <region>,<zone>", input)
+	}
+	if region < 1 || region > ontology.Regions || zone < 1 || zone > ontology.Zones {
+		return nil, fmt.Errorf("location %q outside of the %dx%d ontology", input, ontology.Regions, ontology.Zones)
+	}
+	return [][]byte{{byte(region), byte(zone)}}, nil
+}
+
+// LocationString formats a location as <region>,<zone>, or "unknown" for an
+// address outside of every chain.
+func LocationString(location []byte) string {
+	if len(location) != 2 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d,%d", location[0], location[1])
+}
+
 func SplitAndTrim(input string) (ret []string) {
 	l := strings.Split(input, ",")
 	for _, r := range l {
@@ -969,6 +1066,23 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setAuth configures the authenticated RPC endpoint serving the dominant and
+// subordinate chains from the set command line flags.
+func setAuth(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(AuthListenFlag.Name) {
+		cfg.AuthAddr = ctx.GlobalString(AuthListenFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthPortFlag.Name) {
+		cfg.AuthPort = ctx.GlobalInt(AuthPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthVirtualHostsFlag.Name) {
+		cfg.AuthVirtualHosts = SplitAndTrim(ctx.GlobalString(AuthVirtualHostsFlag.Name))
+	}
+	if ctx.GlobalIsSet(JWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.GlobalString(JWTSecretFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -1225,6 +1339,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
+	setAuth(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	setDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
@@ -1386,6 +1501,15 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerNoVerifyFlag.Name) {
 		cfg.Noverify = ctx.GlobalBool(MinerNoVerifyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerStratumFlag.Name) {
+		cfg.Stratum = ctx.GlobalString(MinerStratumFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumV2Flag.Name) {
+		cfg.StratumV2 = ctx.GlobalString(MinerStratumV2Flag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumDifficultyFlag.Name) {
+		cfg.StratumDifficulty = ctx.GlobalUint64(MinerStratumDifficultyFlag.Name)
+	}
 	if ctx.GlobalIsSet(LegacyMinerGasTargetFlag.Name) {
 		log.Warn("The generic --miner.gastarget flag is deprecated and will be removed in the future!")
 	}
@@ -1537,6 +1661,21 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(TxLookupLimitFlag.Name) {
 		cfg.TxLookupLimit = ctx.GlobalUint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(FinalityDepthFlag.Name) {
+		cfg.FinalityDepth = ctx.GlobalUint64(FinalityDepthFlag.Name)
+	}
+	if ctx.GlobalIsSet(ExternalBlockRetentionFlag.Name) {
+		cfg.ExternalBlockRetention = ctx.GlobalUint64(ExternalBlockRetentionFlag.Name)
+	}
+	if ctx.GlobalIsSet(PCRCDepthFlag.Name) {
+		cfg.PCRCDepth = ctx.GlobalUint64(PCRCDepthFlag.Name)
+	}
+	if ctx.GlobalIsSet(PCRCCheckpointFlag.Name) {
+		hash := ctx.GlobalString(PCRCCheckpointFlag.Name)
+		if err := cfg.PCRCCheckpoint.UnmarshalText([]byte(hash)); err != nil {
+			Fatalf("Invalid PCRC checkpoint %q: %v", hash, err)
+		}
+	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheTrieFlag.Name) {
 		cfg.TrieCleanCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheTrieFlag.Name) / 100
 	}
@@ -1599,14 +1738,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 				cfg.NetworkId = params.MainnetRegionChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1].ChainID.Uint64()
 			}
 			cfg.Genesis = core.MainnetRegionGenesisBlock(&params.MainnetRegionChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1])
-			types.QuaiNetworkContext = cfg.Genesis.Config.Context
 			SetDNSDiscoveryDefaults(cfg, params.MainnetRegionGenesisHash)
 		case ctx.GlobalIsSet(RegionFlag.Name) && ctx.GlobalIsSet(ZoneFlag.Name):
 			if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
 				cfg.NetworkId = params.MainnetZoneChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1][ctx.GlobalInt(ZoneFlag.Name)-1].ChainID.Uint64()
 			}
 			cfg.Genesis = core.MainnetZoneGenesisBlock(&params.MainnetZoneChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1][ctx.GlobalInt(ZoneFlag.Name)-1])
-			types.QuaiNetworkContext = cfg.Genesis.Config.Context
 			SetDNSDiscoveryDefaults(cfg, params.MainnetZoneGenesisHash)
 		default:
 			if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
@@ -1622,14 +1759,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 				cfg.NetworkId = params.RopstenRegionChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1].ChainID.Uint64()
 			}
 			cfg.Genesis = core.RopstenRegionGenesisBlock(&params.RopstenRegionChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1])
-			types.QuaiNetworkContext = cfg.Genesis.Config.Context
 			SetDNSDiscoveryDefaults(cfg, params.RopstenRegionGenesisHash)
 		case ctx.GlobalIsSet(RegionFlag.Name) && ctx.GlobalIsSet(ZoneFlag.Name):
 			if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
 				cfg.NetworkId = params.RopstenZoneChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1][ctx.GlobalInt(ZoneFlag.Name)-1].ChainID.Uint64()
 			}
 			cfg.Genesis = core.RopstenZoneGenesisBlock(&params.RopstenZoneChainConfigs[ctx.GlobalInt(RegionFlag.Name)-1][ctx.GlobalInt(ZoneFlag.Name)-1])
-			types.QuaiNetworkContext = cfg.Genesis.Config.Context
 			SetDNSDiscoveryDefaults(cfg, params.RopstenZoneGenesisHash)
 		default:
 			if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
@@ -1847,6 +1982,16 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node, readonly bool) ethdb.
 	return chainDb
 }
 
+// MakeChainContext returns the context of the chain stored in the database,
+// read from the chain config of its genesis.
+func MakeChainContext(db ethdb.Database) int {
+	config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	if config == nil {
+		Fatalf("Could not load chain config")
+	}
+	return config.Context
+}
+
 func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	var genesis *core.Genesis
 	switch {
@@ -1857,7 +2002,6 @@ func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	default:
 		genesis = core.MainnetPrimeGenesisBlock()
 	}
-	types.QuaiNetworkContext = genesis.Config.Context
 	return genesis
 }
 
@@ -1873,8 +2017,9 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	if config.Clique != nil {
 		engine = clique.New(config.Clique, chainDb)
 	} else {
-		engine, _ = blake3.New(blake3.Config{}, nil, false)
+		engine, _ = blake3.New(blake3.Config{Context: config.Context}, nil, false)
 	}
+	rawdb.SetFreezerContext(chainDb, config.Context)
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
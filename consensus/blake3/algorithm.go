@@ -0,0 +1,106 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blake3
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// DifficultyAlgorithm is a difficulty adjustment algorithm. It returns the
+// difficulty that a new block of the given context should have when created
+// at time given its parent.
+type DifficultyAlgorithm interface {
+	CalcDifficulty(time uint64, parent *types.Header, context int) *big.Int
+}
+
+// DifficultyAlgorithmFunc is an adapter to allow the use of ordinary functions
+// as difficulty adjustment algorithms.
+type DifficultyAlgorithmFunc func(time uint64, parent *types.Header, context int) *big.Int
+
+// CalcDifficulty calls f(time, parent, context).
+func (f DifficultyAlgorithmFunc) CalcDifficulty(time uint64, parent *types.Header, context int) *big.Int {
+	return f(time, parent, context)
+}
+
+// difficultyAlgorithms contains every difficulty adjustment algorithm which
+// may be selected for a context in the chain config.
+var difficultyAlgorithms = map[string]DifficultyAlgorithm{
+	params.FrontierDifficulty: DifficultyAlgorithmFunc(calcDifficultyFrontier),
+	params.NoBombDifficulty:   DifficultyAlgorithmFunc(calcDifficultyNoBomb),
+	params.EMADifficulty:      DifficultyAlgorithmFunc(calcDifficultyEMA),
+}
+
+// LookupDifficultyAlgorithm returns the difficulty adjustment algorithm with
+// the given name, if any.
+func LookupDifficultyAlgorithm(name string) (DifficultyAlgorithm, bool) {
+	algorithm, ok := difficultyAlgorithms[name]
+	return algorithm, ok
+}
+
+// DifficultyAlgorithmNames returns the sorted names of every difficulty
+// adjustment algorithm.
+func DifficultyAlgorithmNames() []string {
+	names := make([]string, 0, len(difficultyAlgorithms))
+	for name := range difficultyAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DifficultyAlgorithmFor returns the difficulty adjustment algorithm configured
+// for the context, falling back to the Frontier rules.
+func DifficultyAlgorithmFor(config *params.ChainConfig, context int) DifficultyAlgorithm {
+	if algorithm, ok := difficultyAlgorithms[config.DifficultyAlgorithm(context)]; ok {
+		return algorithm
+	}
+	return difficultyAlgorithms[params.FrontierDifficulty]
+}
+
+// calcDifficultyEMA is a weighted-target exponential moving average difficulty
+// adjustment, approximating ASERT from the parent alone. Every block moves the
+// difficulty by 1/N of its relative block time error, N being the averaging
+// window of the context:
+//
+//	diff = pdiff * N*T / (N*T + (time - ptime) - T)
+//
+// Where T is the target block time of the context.
+func calcDifficultyEMA(time uint64, parent *types.Header, context int) *big.Int {
+	parentDifficulty := parent.Difficulty[context]
+	if parentDifficulty == nil {
+		return params.GenesisDifficulty[context]
+	}
+	target := params.DurationLimits[context]
+	window := new(big.Int).Mul(params.DifficultyEMAWindow[context], target)
+
+	solveTime := new(big.Int).SetUint64(time)
+	solveTime.Sub(solveTime, new(big.Int).SetUint64(parent.Time))
+
+	denominator := new(big.Int).Add(window, solveTime)
+	denominator.Sub(denominator, target)
+
+	diff := new(big.Int).Mul(parentDifficulty, window)
+	diff.Div(diff, denominator)
+	if diff.Cmp(params.MinimumDifficulty[context]) < 0 {
+		diff.Set(params.MinimumDifficulty[context])
+	}
+	return diff
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blake3
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+type algorithmTest struct {
+	parentTime       uint64
+	parentNumber     int64
+	parentDifficulty *big.Int
+	time             uint64
+	want             *big.Int
+}
+
+// parent returns the zone parent header of the test.
+func (test *algorithmTest) parent() *types.Header {
+	return &types.Header{
+		Time:       test.parentTime,
+		Number:     []*big.Int{nil, nil, big.NewInt(test.parentNumber)},
+		Difficulty: []*big.Int{nil, nil, test.parentDifficulty},
+	}
+}
+
+func runAlgorithmTests(t *testing.T, name string, tests []algorithmTest) {
+	algorithm, ok := LookupDifficultyAlgorithm(name)
+	if !ok {
+		t.Fatalf("difficulty algorithm %q not found", name)
+	}
+	for i, test := range tests {
+		if have := algorithm.CalcDifficulty(test.time, test.parent(), params.ZONE); have.Cmp(test.want) != 0 {
+			t.Errorf("%s test %d: difficulty mismatch: have %v, want %v", name, i, have, test.want)
+		}
+	}
+}
+
+func TestCalcDifficultyEMA(t *testing.T) {
+	runAlgorithmTests(t, params.EMADifficulty, []algorithmTest{
+		// Missing parent difficulty
+		{100, 1, nil, 110, params.GenesisDifficulty[params.ZONE]},
+		// On target, 600s window
+		{100, 1, big.NewInt(1000000), 110, big.NewInt(1000000)},
+		// Fast blocks raise the difficulty
+		{100, 1, big.NewInt(1000000), 101, big.NewInt(1015228)},
+		{100, 1, big.NewInt(1000000), 100, big.NewInt(1016949)},
+		// Slow blocks lower it
+		{100, 1, big.NewInt(1000000), 160, big.NewInt(923076)},
+		// Never below the minimum
+		{100, 1, big.NewInt(131072), 1000, params.MinimumDifficulty[params.ZONE]},
+		// No difficulty bomb
+		{100, 400000, big.NewInt(1000000), 110, big.NewInt(1000000)},
+	})
+}
+
+func TestCalcDifficultyNoBomb(t *testing.T) {
+	runAlgorithmTests(t, params.NoBombDifficulty, []algorithmTest{
+		// Missing parent difficulty
+		{100, 1, nil, 105, params.GenesisDifficulty[params.ZONE]},
+		// Steps of 1/2048 of the parent difficulty around the 10s duration limit
+		{100, 1, big.NewInt(2048000), 105, big.NewInt(2049000)},
+		{100, 1, big.NewInt(2048000), 110, big.NewInt(2047000)},
+		// Never below the minimum
+		{100, 1, big.NewInt(131072), 110, params.MinimumDifficulty[params.ZONE]},
+		// No difficulty bomb
+		{100, 400000, big.NewInt(2048000), 105, big.NewInt(2049000)},
+	})
+	// The Frontier rules add the bomb on top of the same adjustment
+	runAlgorithmTests(t, params.FrontierDifficulty, []algorithmTest{
+		{100, 400000, big.NewInt(2048000), 105, big.NewInt(2049004)},
+	})
+}
+
+func TestLookupDifficultyAlgorithm(t *testing.T) {
+	for _, name := range []string{params.FrontierDifficulty, params.NoBombDifficulty, params.EMADifficulty} {
+		if _, ok := LookupDifficultyAlgorithm(name); !ok {
+			t.Errorf("difficulty algorithm %q not found", name)
+		}
+	}
+	if _, ok := LookupDifficultyAlgorithm("bogus"); ok {
+		t.Errorf("unknown difficulty algorithm found")
+	}
+	if have := len(DifficultyAlgorithmNames()); have != 3 {
+		t.Errorf("algorithm name count mismatch: have %d, want 3", have)
+	}
+}
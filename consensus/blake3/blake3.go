@@ -32,6 +32,9 @@ type Config struct {
 
 	// Fake proof of work for testing
 	Fakepow bool
+
+	// Context of the chain whose blocks are sealed and verified
+	Context int `toml:"-"`
 }
 
 // Blake3 a consensus engine based on the Blake3 hash function
@@ -81,6 +84,14 @@ func NewFaker() *Blake3 {
 	}
 }
 
+// NewContextFaker creates a fake blake3 consensus engine like NewFaker, which
+// seals and verifies the blocks of the chain running in the given context.
+func NewContextFaker(context int) *Blake3 {
+	engine := NewFaker()
+	engine.config.Context = context
+	return engine
+}
+
 // NewTester creates a small sized ethash PoW scheme useful only for testing
 // purposes. Params have yet to be implemented.
 func NewTester(notify []string, noverify bool) *Blake3 {
@@ -52,10 +52,8 @@ var (
 	expDiffPeriod = big.NewInt(100000)
 	big1          = big.NewInt(1)
 	big2          = big.NewInt(2)
-	big8          = big.NewInt(8)
 	big9          = big.NewInt(9)
 	big10         = big.NewInt(10)
-	big32         = big.NewInt(32)
 	bigMinus99    = big.NewInt(-99)
 	big2e256      = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0)) // 2^256
 )
@@ -82,17 +80,17 @@ var FrontierDifficultyCalulator = calcDifficultyFrontier
 // Author implements consensus.Engine, returning the header's coinbase as the
 // proof-of-work verified author of the block.
 func (blake3 *Blake3) Author(header *types.Header) (common.Address, error) {
-	return header.Coinbase[types.QuaiNetworkContext], nil
+	return header.Coinbase[blake3.config.Context], nil
 }
 
 // VerifyHeader checks whether a header conforms to the consensus rules of the Blake3 engine.
 func (blake3 *Blake3) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
 	// Short circuit if the header is known, or its parent not
-	number := header.Number[types.QuaiNetworkContext].Uint64()
+	number := header.Number[chain.Config().Context].Uint64()
 	if chain.GetHeader(header.Hash(), number) != nil {
 		return nil
 	}
-	parent := chain.GetHeader(header.ParentHash[types.QuaiNetworkContext], number-1)
+	parent := chain.GetHeader(header.ParentHash[chain.Config().Context], number-1)
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
@@ -169,8 +167,8 @@ func (blake3 *Blake3) VerifyHeaders(chain consensus.ChainHeaderReader, headers [
 func (blake3 *Blake3) verifyHeaderWorker(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool, index int, unixNow int64) error {
 	var parent *types.Header
 	if index == 0 {
-		parent = chain.GetHeader(headers[0].ParentHash[types.QuaiNetworkContext], headers[0].Number[types.QuaiNetworkContext].Uint64()-1)
-	} else if headers[index-1].Hash() == headers[index].ParentHash[types.QuaiNetworkContext] {
+		parent = chain.GetHeader(headers[0].ParentHash[chain.Config().Context], headers[0].Number[chain.Config().Context].Uint64()-1)
+	} else if headers[index-1].Hash() == headers[index].ParentHash[chain.Config().Context] {
 		parent = headers[index-1]
 	}
 	if parent == nil {
@@ -191,7 +189,7 @@ func (blake3 *Blake3) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 	// Gather the set of past uncles and ancestors
 	uncles, ancestors := mapset.NewSet(), make(map[common.Hash]*types.Header)
 
-	number, parent := block.NumberU64()-1, block.ParentHash()
+	number, parent := block.NumberU64(chain.Config().Context)-1, block.ParentHash(chain.Config().Context)
 	for i := 0; i < 7; i++ {
 		ancestorHeader := chain.GetHeader(parent, number)
 		if ancestorHeader == nil {
@@ -199,7 +197,7 @@ func (blake3 *Blake3) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 		}
 		ancestors[parent] = ancestorHeader
 		// If the ancestor doesn't have any uncles, we don't have to iterate them
-		if ancestorHeader.UncleHash[types.QuaiNetworkContext] != types.EmptyUncleHash[types.QuaiNetworkContext] {
+		if ancestorHeader.UncleHash[chain.Config().Context] != types.EmptyUncleHash[chain.Config().Context] {
 			// Need to add those uncles to the banned list too
 			ancestor := chain.GetBlock(parent, number)
 			if ancestor == nil {
@@ -209,7 +207,7 @@ func (blake3 *Blake3) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 				uncles.Add(uncle.Hash())
 			}
 		}
-		parent, number = ancestorHeader.ParentHash[types.QuaiNetworkContext], number-1
+		parent, number = ancestorHeader.ParentHash[chain.Config().Context], number-1
 	}
 	ancestors[block.Hash()] = block.Header()
 	uncles.Add(block.Hash())
@@ -227,10 +225,10 @@ func (blake3 *Blake3) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 		if ancestors[hash] != nil {
 			return errUncleIsAncestor
 		}
-		if ancestors[uncle.ParentHash[types.QuaiNetworkContext]] == nil || uncle.ParentHash[types.QuaiNetworkContext] == block.ParentHash() {
+		if ancestors[uncle.ParentHash[chain.Config().Context]] == nil || uncle.ParentHash[chain.Config().Context] == block.ParentHash(chain.Config().Context) {
 			return errDanglingUncle
 		}
-		if err := blake3.verifyHeader(chain, uncle, ancestors[uncle.ParentHash[types.QuaiNetworkContext]], true, true, time.Now().Unix()); err != nil {
+		if err := blake3.verifyHeader(chain, uncle, ancestors[uncle.ParentHash[chain.Config().Context]], true, true, time.Now().Unix()); err != nil {
 			return err
 		}
 	}
@@ -253,20 +251,20 @@ func (blake3 *Blake3) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 		return errOlderBlockTime
 	}
 	// Verify the block's difficulty based on its timestamp and parent's difficulty
-	expected := blake3.CalcDifficulty(chain, header.Time, parent, types.QuaiNetworkContext)
+	expected := blake3.CalcDifficulty(chain, header.Time, parent, chain.Config().Context)
 	if blake3.config.Fakepow {
-		expected = fakeDifficulties[types.QuaiNetworkContext]
+		expected = fakeDifficulties[chain.Config().Context]
 	}
-	if expected.Cmp(header.Difficulty[types.QuaiNetworkContext]) > 0 {
-		return fmt.Errorf("invalid difficulty: have %v, want %v", header.Difficulty[types.QuaiNetworkContext], expected)
+	if expected.Cmp(header.Difficulty[chain.Config().Context]) > 0 {
+		return fmt.Errorf("invalid difficulty: have %v, want %v", header.Difficulty[chain.Config().Context], expected)
 	}
 	// Verify that the gas limit is <= 2^63-1
 	cap := uint64(0x7fffffffffffffff)
-	if header.GasLimit[types.QuaiNetworkContext] > cap {
+	if header.GasLimit[chain.Config().Context] > cap {
 		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, cap)
 	}
 	// Verify that the gasUsed is <= gasLimit
-	if len(header.GasUsed) > 0 && header.GasUsed[types.QuaiNetworkContext] > header.GasLimit[types.QuaiNetworkContext] {
+	if len(header.GasUsed) > 0 && header.GasUsed[chain.Config().Context] > header.GasLimit[chain.Config().Context] {
 		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
 	}
 	// Verify the block's gas usage and base fee.
@@ -275,7 +273,7 @@ func (blake3 *Blake3) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 		return err
 	}
 	// Verify that the block number is parent's +1
-	if diff := new(big.Int).Sub(header.Number[types.QuaiNetworkContext], parent.Number[types.QuaiNetworkContext]); diff.Cmp(big.NewInt(1)) != 0 {
+	if diff := new(big.Int).Sub(header.Number[chain.Config().Context], parent.Number[chain.Config().Context]); diff.Cmp(big.NewInt(1)) != 0 {
 		return consensus.ErrInvalidNumber
 	}
 	// Verify the engine specific seal securing the block
@@ -285,7 +283,7 @@ func (blake3 *Blake3) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 		}
 	}
 	// Verify that Location is same as config
-	if err := verifyLocation(header.Location, chain.Config().Location); err != nil {
+	if err := verifyLocation(header.Location, chain.Config().Location, chain.Config().Context); err != nil {
 		return err
 	}
 
@@ -309,37 +307,53 @@ func (blake3 *Blake3) CalcDifficulty(chain consensus.ChainHeaderReader, time uin
 
 // CalcDifficulty is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time
-// given the parent block's time and difficulty.
+// given the parent block's time and difficulty, using the algorithm
+// configured for the context.
 func CalcDifficulty(config *params.ChainConfig, time uint64, parent *types.Header, context int) *big.Int {
-	return calcDifficultyFrontier(time, parent, context)
+	return DifficultyAlgorithmFor(config, context).CalcDifficulty(time, parent, context)
 }
 
 // calcDifficultyFrontier is the difficulty adjustment algorithm. It returns the
 // difficulty that a new block should have when created at time given the parent
 // block's time and difficulty. The calculation uses the Frontier rules.
 func calcDifficultyFrontier(time uint64, parent *types.Header, context int) *big.Int {
+	return frontierDifficulty(time, parent, context, true)
+}
+
+// calcDifficultyNoBomb is the Frontier difficulty adjustment algorithm without
+// the exponential difficulty bomb.
+func calcDifficultyNoBomb(time uint64, parent *types.Header, context int) *big.Int {
+	return frontierDifficulty(time, parent, context, false)
+}
+
+// frontierDifficulty steps the parent difficulty up or down by a bounded amount
+// depending on the parent block time, optionally adding the difficulty bomb.
+func frontierDifficulty(time uint64, parent *types.Header, context int, bomb bool) *big.Int {
 	diff := new(big.Int)
 	parentDifficulty := parent.Difficulty[context]
 	if parentDifficulty == nil {
-		return params.GenesisDifficulty[types.QuaiNetworkContext]
+		return params.GenesisDifficulty[context]
 	}
 
-	adjust := new(big.Int).Div(parentDifficulty, params.DifficultyBoundDivisor[types.QuaiNetworkContext])
+	adjust := new(big.Int).Div(parentDifficulty, params.DifficultyBoundDivisor[context])
 	bigTime := new(big.Int)
 	bigParentTime := new(big.Int)
 
 	bigTime.SetUint64(time)
 	bigParentTime.SetUint64(parent.Time)
 
-	duration := params.DurationLimits[types.QuaiNetworkContext]
+	duration := params.DurationLimits[context]
 
 	if bigTime.Sub(bigTime, bigParentTime).Cmp(duration) < 0 {
 		diff.Add(parentDifficulty, adjust)
 	} else {
 		diff.Sub(parentDifficulty, adjust)
 	}
-	if diff.Cmp(params.MinimumDifficulty[types.QuaiNetworkContext]) < 0 {
-		diff.Set(params.MinimumDifficulty[types.QuaiNetworkContext])
+	if diff.Cmp(params.MinimumDifficulty[context]) < 0 {
+		diff.Set(params.MinimumDifficulty[context])
+	}
+	if !bomb {
+		return diff
 	}
 
 	periodCount := new(big.Int).Add(parent.Number[context], big1)
@@ -349,17 +363,17 @@ func calcDifficultyFrontier(time uint64, parent *types.Header, context int) *big
 		expDiff := periodCount.Sub(periodCount, big2)
 		expDiff.Exp(big2, expDiff, nil)
 		diff.Add(diff, expDiff)
-		diff = math.BigMax(diff, params.MinimumDifficulty[types.QuaiNetworkContext])
+		diff = math.BigMax(diff, params.MinimumDifficulty[context])
 	}
 	return diff
 }
 
 // verifySeal checks whether a block satisfies the PoW difficulty requirements,
 func (blake3 *Blake3) verifySeal(header *types.Header) error {
-	difficulty := header.Difficulty[types.QuaiNetworkContext]
+	difficulty := header.Difficulty[blake3.config.Context]
 	// If we are a faker, override the difficulty with the appropriate fake difficulty
 	if blake3.config.Fakepow {
-		difficulty = fakeDifficulties[types.QuaiNetworkContext]
+		difficulty = fakeDifficulties[blake3.config.Context]
 	}
 	// Ensure that we have a valid difficulty for the block
 	if difficulty.Sign() <= 0 {
@@ -649,11 +663,11 @@ func (blake3 *Blake3) GetExternalBlocks(chain consensus.ChainHeaderReader, heade
 // Prepare implements consensus.Engine, initializing the difficulty field of a
 // header to conform to the protocol. The changes are done inline.
 func (blake3 *Blake3) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
-	parent := chain.GetHeader(header.ParentHash[types.QuaiNetworkContext], header.Number[types.QuaiNetworkContext].Uint64()-1)
+	parent := chain.GetHeader(header.ParentHash[chain.Config().Context], header.Number[chain.Config().Context].Uint64()-1)
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
-	header.Difficulty[types.QuaiNetworkContext] = blake3.CalcDifficulty(chain, header.Time, parent, types.QuaiNetworkContext)
+	header.Difficulty[chain.Config().Context] = blake3.CalcDifficulty(chain, header.Time, parent, chain.Config().Context)
 	return nil
 }
 
@@ -683,7 +697,7 @@ func (blake3 *Blake3) GetLinkExternalBlocks(chain consensus.ChainHeaderReader, h
 //     *path - Search among ancestors of this path in the specified slice
 func (blake3 *Blake3) PreviousCoincidentOnPath(chain consensus.ChainHeaderReader, header *types.Header, slice []byte, order, path int, fullSliceEqual bool) (*types.Header, error) {
 
-	if header.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+	if header.Number[chain.Config().Context].Cmp(big.NewInt(0)) == 0 {
 		return chain.GetHeaderByHash(chain.Config().GenesisHashes[0]), nil
 	}
 
@@ -718,7 +732,7 @@ func (blake3 *Blake3) PreviousCoincidentOnPath(chain consensus.ChainHeaderReader
 		if header.Number[path].Cmp(big.NewInt(1)) == 0 {
 			return chain.GetHeaderByHash(chain.Config().GenesisHashes[0]), nil
 		}
-		if path == types.QuaiNetworkContext {
+		if path == chain.Config().Context {
 			// Get previous header on local chain by hash
 			prevHeader := chain.GetHeaderByHash(header.ParentHash[path])
 			if prevHeader == nil {
@@ -803,8 +817,13 @@ func (blake3 *Blake3) TraceBranches(chain consensus.ChainHeaderReader, header *t
 // setting the final state on the header
 func (blake3 *Blake3) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header) {
 	// Accumulate any block and uncle rewards and commit the final state root
-	accumulateRewards(chain.Config(), state, header, uncles)
-	header.Root[types.QuaiNetworkContext] = state.IntermediateRoot(chain.Config().IsEIP158(header.Number[types.QuaiNetworkContext]))
+	config := chain.Config()
+	var parent *types.Header
+	if number := header.Number[config.Context]; number.Sign() > 0 {
+		parent = chain.GetHeader(header.ParentHash[config.Context], number.Uint64()-1)
+	}
+	accumulateRewards(config, state, header, parent, misc.RewardedOrder(config, parent, blake3.GetDifficultyOrder), uncles)
+	header.Root[config.Context] = state.IntermediateRoot(config.IsEIP158(header.Number[config.Context]))
 }
 
 // FinalizeAndAssemble implements consensus.Engine, accumulating the block and
@@ -821,7 +840,14 @@ func (blake3 *Blake3) FinalizeAndAssemble(chain consensus.ChainHeaderReader, hea
 func (blake3 *Blake3) SealHash(header *types.Header) (hash common.Hash) {
 	hasher := blake3hash.New(32, nil)
 	hasher.Reset()
+	hasher.Write(SealData(header))
+	hasher.Sum(hash[:0])
+	return hash
+}
 
+// SealData returns the preimage hashed by SealHash. The encoded nonce is always
+// the trailing 8 bytes, so external miners only need to vary those.
+func SealData(header *types.Header) []byte {
 	enc := []interface{}{
 		header.ParentHash,
 		header.UncleHash,
@@ -842,41 +868,32 @@ func (blake3 *Blake3) SealHash(header *types.Header) (hash common.Hash) {
 		enc = append(enc, header.BaseFee)
 	}
 	enc = append(enc, header.Nonce)
-	rlp.Encode(hasher, enc)
-	hasher.Sum(hash[:0])
-	return hash
+	data, _ := rlp.EncodeToBytes(enc)
+	return data
 }
 
-// AccumulateRewards credits the coinbase of the given block with the mining
-// reward. The total reward consists of the static block reward and rewards for
-// included uncles. The coinbase of each uncle block is also rewarded.
-func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
-	// Skip block reward in catalyst mode
-	if config.IsCatalyst(header.Number[types.QuaiNetworkContext]) {
-		return
-	}
-
-	// Select the correct block reward based on chain progression
-	blockReward := misc.CalculateReward()
-	// Accumulate the rewards for the miner and any included uncles
-	reward := new(big.Int).Set(blockReward)
-	r := new(big.Int)
-	for _, uncle := range uncles {
-		r.Add(uncle.Number[types.QuaiNetworkContext], big8)
-		r.Sub(r, header.Number[types.QuaiNetworkContext])
-		r.Mul(r, blockReward)
-		r.Div(r, big8)
-		state.AddBalance(uncle.Coinbase[types.QuaiNetworkContext], r)
-
-		r.Div(blockReward, big32)
-		reward.Add(reward, r)
-	}
-	state.AddBalance(header.Coinbase[types.QuaiNetworkContext], reward)
+// AccumulateRewards credits the coinbase of the parent of the given block with
+// the reward of the order the parent satisfied. The coinbase of the block is
+// rewarded for the included uncles, the coinbase of each uncle block is also
+// rewarded, and the treasury receives its share of the parent reward.
+func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, parent *types.Header, order int, uncles []*types.Header) {
+	rewards := misc.BlockRewards(config, header, parent, order, uncles)
+	for i, uncle := range uncles {
+		state.AddBalance(uncle.Coinbase[config.Context], rewards.Uncles[i])
+	}
+	if rewards.Treasury.Sign() > 0 {
+		treasury, _ := config.Treasury(config.Context)
+		state.AddBalance(treasury, rewards.Treasury)
+	}
+	if rewards.Parent.Sign() > 0 {
+		state.AddBalance(parent.Coinbase[config.Context], rewards.Parent)
+	}
+	state.AddBalance(header.Coinbase[config.Context], rewards.Miner)
 }
 
 // Verifies that a header location is valid for a specific config.
-func verifyLocation(location []byte, configLocation []byte) error {
-	switch types.QuaiNetworkContext {
+func verifyLocation(location []byte, configLocation []byte, context int) error {
+	switch context {
 	case 0:
 		return nil
 	case 1:
@@ -897,17 +914,18 @@ func verifyLocation(location []byte, configLocation []byte) error {
 }
 
 // Verifies that Location value is valid inside MapContext ontology.
-// Returns MapContext for error handling purposes.
+// Returns MapContext for error handling purposes. The ontology is the one
+// effective at the prime number of the header, so that locations created by an
+// ontology fork are only valid from the fork on.
 func verifyInsideLocation(location []byte, number []*big.Int, config *params.ChainConfig) error {
 	regionLocation := int(location[0])
 	zoneLocation := int(location[1])
 
-	switch {
-	case config.IsFuller(number[0]): // Fuller = [3,3]
-		return checkInsideCurrent(regionLocation, zoneLocation, params.FullerOntology)
-	default:
+	ontology, err := config.CurrentOntology(number)
+	if err != nil {
 		return consensus.ErrInvalidOntology
 	}
+	return checkInsideCurrent(regionLocation, zoneLocation, ontology)
 }
 
 // Verifies that Location is valid inside current MapContext ontology.
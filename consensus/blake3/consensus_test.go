@@ -119,7 +119,7 @@ func TestDifficultyCalculators(t *testing.T) {
 		bombDelay := new(big.Int).SetUint64(rand.Uint64() % 50_000_000)
 		for i, pair := range []struct {
 			bigFn  func(time uint64, parent *types.Header, context int) *big.Int
-			u256Fn func(time uint64, parent *types.Header) *big.Int
+			u256Fn func(time uint64, parent *types.Header, context int) *big.Int
 		}{
 			{FrontierDifficultyCalulator, CalcDifficultyFrontierU256},
 			/*{HomesteadDifficultyCalulator, CalcDifficultyHomesteadU256},
@@ -127,7 +127,7 @@ func TestDifficultyCalculators(t *testing.T) {
 		} {
 			time := header.Time + timeDelta
 			want := pair.bigFn(time, header, 0)
-			have := pair.u256Fn(time, header)
+			have := pair.u256Fn(time, header, 0)
 			if want.BitLen() > 256 {
 				continue
 			}
@@ -158,7 +158,7 @@ func BenchmarkDifficultyCalculator(b *testing.B) {
 	b.Run("u256-frontier", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
-			CalcDifficultyFrontierU256(1000014, h)
+			CalcDifficultyFrontierU256(1000014, h, 0)
 		}
 	})
 	/*b.Run("big-homestead", func(b *testing.B) {
@@ -170,7 +170,7 @@ func BenchmarkDifficultyCalculator(b *testing.B) {
 	b.Run("u256-homestead", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
-			CalcDifficultyHomesteadU256(1000014, h)
+			CalcDifficultyHomesteadU256(1000014, h, 0)
 		}
 	})
 	/*b.Run("big-generic", func(b *testing.B) {
@@ -182,7 +182,7 @@ func BenchmarkDifficultyCalculator(b *testing.B) {
 	b.Run("u256-generic", func(b *testing.B) {
 		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
-			x2(1000014, h)
+			x2(1000014, h, 0)
 		}
 	})
 }
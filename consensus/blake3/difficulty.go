@@ -40,7 +40,7 @@ const (
 // CalcDifficultyFrontierU256 is the difficulty adjustment algorithm. It returns the
 // difficulty that a new block should have when created at time given the parent
 // block's time and difficulty. The calculation uses the Frontier rules.
-func CalcDifficultyFrontierU256(time uint64, parent *types.Header) *big.Int {
+func CalcDifficultyFrontierU256(time uint64, parent *types.Header, context int) *big.Int {
 	/*
 		Algorithm
 		block_diff = pdiff + pdiff / 2048 * (1 if time - ptime < 13 else -1) + int(2^((num // 100000) - 2))
@@ -52,7 +52,7 @@ func CalcDifficultyFrontierU256(time uint64, parent *types.Header) *big.Int {
 		- num = block.number
 	*/
 
-	pDiff, _ := uint256.FromBig(parent.Difficulty[types.QuaiNetworkContext]) // pDiff: pdiff
+	pDiff, _ := uint256.FromBig(parent.Difficulty[context]) // pDiff: pdiff
 	adjust := pDiff.Clone()
 	adjust.Rsh(adjust, difficultyBoundDivisor) // adjust: pDiff / 2048
 
@@ -67,7 +67,7 @@ func CalcDifficultyFrontierU256(time uint64, parent *types.Header) *big.Int {
 	// 'pdiff' now contains:
 	// pdiff + pdiff / 2048 * (1 if time - ptime < 13 else -1)
 
-	if periodCount := (parent.Number[types.QuaiNetworkContext].Uint64() + 1) / expDiffPeriodUint; periodCount > 1 {
+	if periodCount := (parent.Number[context].Uint64() + 1) / expDiffPeriodUint; periodCount > 1 {
 		// diff = diff + 2^(periodCount - 2)
 		expDiff := adjust.SetOne()
 		expDiff.Lsh(expDiff, uint(periodCount-2)) // expdiff: 2 ^ (periodCount -2)
@@ -79,7 +79,7 @@ func CalcDifficultyFrontierU256(time uint64, parent *types.Header) *big.Int {
 // CalcDifficultyHomesteadU256 is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time given the
 // parent block's time and difficulty. The calculation uses the Homestead rules.
-func CalcDifficultyHomesteadU256(time uint64, parent *types.Header) *big.Int {
+func CalcDifficultyHomesteadU256(time uint64, parent *types.Header, context int) *big.Int {
 	/*
 		https://github.com/ethereum/EIPs/blob/master/EIPS/eip-2.md
 		Algorithm:
@@ -95,7 +95,7 @@ func CalcDifficultyHomesteadU256(time uint64, parent *types.Header) *big.Int {
 		- num = block.number
 	*/
 
-	pDiff, _ := uint256.FromBig(parent.Difficulty[types.QuaiNetworkContext]) // pDiff: pdiff
+	pDiff, _ := uint256.FromBig(parent.Difficulty[context]) // pDiff: pdiff
 	adjust := pDiff.Clone()
 	adjust.Rsh(adjust, difficultyBoundDivisor) // adjust: pDiff / 2048
 
@@ -121,7 +121,7 @@ func CalcDifficultyHomesteadU256(time uint64, parent *types.Header) *big.Int {
 	}
 	// for the exponential factor, a.k.a "the bomb"
 	// diff = diff + 2^(periodCount - 2)
-	if periodCount := (1 + parent.Number[types.QuaiNetworkContext].Uint64()) / expDiffPeriodUint; periodCount > 1 {
+	if periodCount := (1 + parent.Number[context].Uint64()) / expDiffPeriodUint; periodCount > 1 {
 		expFactor := adjust.Lsh(adjust.SetOne(), uint(periodCount-2))
 		pDiff.Add(pDiff, expFactor)
 	}
@@ -131,11 +131,11 @@ func CalcDifficultyHomesteadU256(time uint64, parent *types.Header) *big.Int {
 // MakeDifficultyCalculatorU256 creates a difficultyCalculator with the given bomb-delay.
 // the difficulty is calculated with Byzantium rules, which differs from Homestead in
 // how uncles affect the calculation
-func MakeDifficultyCalculatorU256(bombDelay *big.Int) func(time uint64, parent *types.Header) *big.Int {
+func MakeDifficultyCalculatorU256(bombDelay *big.Int) func(time uint64, parent *types.Header, context int) *big.Int {
 	// Note, the calculations below looks at the parent number, which is 1 below
 	// the block number. Thus we remove one from the delay given
 	bombDelayFromParent := bombDelay.Uint64() - 1
-	return func(time uint64, parent *types.Header) *big.Int {
+	return func(time uint64, parent *types.Header, context int) *big.Int {
 		/*
 			https://github.com/ethereum/EIPs/issues/100
 			pDiff = parent.difficulty
@@ -146,7 +146,7 @@ func MakeDifficultyCalculatorU256(bombDelay *big.Int) func(time uint64, parent *
 		*/
 		x := (time - parent.Time) / 9 // (block_timestamp - parent_timestamp) // 9
 		c := uint64(1)                // if parent.unclehash == emptyUncleHashHash
-		if parent.UncleHash[types.QuaiNetworkContext] != types.EmptyUncleHash[types.QuaiNetworkContext] {
+		if parent.UncleHash[context] != types.EmptyUncleHash[context] {
 			c = 2
 		}
 		xNeg := x >= c
@@ -161,11 +161,11 @@ func MakeDifficultyCalculatorU256(bombDelay *big.Int) func(time uint64, parent *
 		}
 		// parent_diff + (parent_diff / 2048 * max((2 if len(parent.uncles) else 1) - ((timestamp - parent.timestamp) // 9), -99))
 		y := new(uint256.Int)
-		y.SetFromBig(parent.Difficulty[types.QuaiNetworkContext]) // y: p_diff
-		pDiff := y.Clone()                                        // pdiff: p_diff
-		z := new(uint256.Int).SetUint64(x)                        //z : +-adj_factor (either pos or negative)
-		y.Rsh(y, difficultyBoundDivisor)                          // y: p__diff / 2048
-		z.Mul(y, z)                                               // z: (p_diff / 2048 ) * (+- adj_factor)
+		y.SetFromBig(parent.Difficulty[context]) // y: p_diff
+		pDiff := y.Clone()                       // pdiff: p_diff
+		z := new(uint256.Int).SetUint64(x)       //z : +-adj_factor (either pos or negative)
+		y.Rsh(y, difficultyBoundDivisor)         // y: p__diff / 2048
+		z.Mul(y, z)                              // z: (p_diff / 2048 ) * (+- adj_factor)
 
 		if xNeg {
 			y.Sub(pDiff, z) // y: parent_diff + parent_diff/2048 * adjustment_factor
@@ -178,7 +178,7 @@ func MakeDifficultyCalculatorU256(bombDelay *big.Int) func(time uint64, parent *
 		}
 		// calculate a fake block number for the ice-age delay
 		// Specification: https://eips.ethereum.org/EIPS/eip-1234
-		var pNum = parent.Number[types.QuaiNetworkContext].Uint64()
+		var pNum = parent.Number[context].Uint64()
 		if pNum >= bombDelayFromParent {
 			if fakeBlockNumber := pNum - bombDelayFromParent; fakeBlockNumber >= 2*expDiffPeriodUint {
 				z.SetOne()
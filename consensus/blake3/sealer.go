@@ -114,7 +114,7 @@ func (blake3 *Blake3) mine(block *types.Block, id int, seed uint64, abort chan s
 	// Extract some data from the header
 	var (
 		header = block.Header()
-		target = new(big.Int).Div(big2e256, header.Difficulty[types.QuaiNetworkContext])
+		target = new(big.Int).Div(big2e256, header.Difficulty[blake3.config.Context])
 	)
 	// Start generating random nonces until we abort or find a good one
 	var (
@@ -125,7 +125,7 @@ func (blake3 *Blake3) mine(block *types.Block, id int, seed uint64, abort chan s
 	logger := blake3.config.Log.New("miner", id)
 	logger.Trace("Started ethash search for new nonces", "seed", seed)
 	if blake3.config.Fakepow {
-		target = new(big.Int).Div(big2e256, fakeDifficulties[types.QuaiNetworkContext])
+		target = new(big.Int).Div(big2e256, fakeDifficulties[blake3.config.Context])
 	}
 search:
 	for {
@@ -432,7 +432,7 @@ func (s *remoteSealer) loop() {
 			// Clear stale pending blocks
 			if s.currentBlock != nil {
 				for hash, block := range s.works {
-					if block.NumberU64()+staleThreshold <= s.currentBlock.NumberU64() {
+					if block.NumberU64(s.blake3.config.Context)+staleThreshold <= s.currentBlock.NumberU64(s.blake3.config.Context) {
 						delete(s.works, hash)
 					}
 				}
@@ -454,8 +454,8 @@ func (s *remoteSealer) loop() {
 func (s *remoteSealer) makeWork(block *types.Block) {
 	hash := s.blake3.SealHash(block.Header())
 	s.currentWork[0] = hash.Hex()
-	s.currentWork[1] = common.BytesToHash(new(big.Int).Div(big2e256, block.Difficulty()).Bytes()).Hex()
-	s.currentWork[2] = hexutil.EncodeBig(block.Number())
+	s.currentWork[1] = common.BytesToHash(new(big.Int).Div(big2e256, block.Difficulty(s.blake3.config.Context)).Bytes()).Hex()
+	s.currentWork[2] = hexutil.EncodeBig(block.Number(s.blake3.config.Context))
 
 	// Trace the seal work fetched by remote sealer.
 	s.currentBlock = block
@@ -515,7 +515,7 @@ func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash,
 	// Make sure the work submitted is present
 	block := s.works[sealhash]
 	if block == nil {
-		s.blake3.config.Log.Warn("Work submitted but none pending", "sealhash", sealhash, "curnumber", s.currentBlock.NumberU64())
+		s.blake3.config.Log.Warn("Work submitted but none pending", "sealhash", sealhash, "curnumber", s.currentBlock.NumberU64(s.blake3.config.Context))
 		return false
 	}
 	// Verify the correctness of submitted result.
@@ -540,10 +540,10 @@ func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash,
 	solution := block.WithSeal(header)
 
 	// The submitted solution is within the scope of acceptance.
-	if solution.NumberU64()+staleThreshold > s.currentBlock.NumberU64() {
+	if solution.NumberU64(s.blake3.config.Context)+staleThreshold > s.currentBlock.NumberU64(s.blake3.config.Context) {
 		select {
 		case s.results <- solution:
-			s.blake3.config.Log.Debug("Work submitted is acceptable", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
+			s.blake3.config.Log.Debug("Work submitted is acceptable", "number", solution.NumberU64(s.blake3.config.Context), "sealhash", sealhash, "hash", solution.Hash())
 			return true
 		default:
 			s.blake3.config.Log.Warn("Sealing result is not read by miner", "mode", "remote", "sealhash", sealhash)
@@ -551,6 +551,6 @@ func (s *remoteSealer) submitWork(nonce types.BlockNonce, mixDigest common.Hash,
 		}
 	}
 	// The submitted block is too old to accept, drop it.
-	s.blake3.config.Log.Warn("Work submitted is too old", "number", solution.NumberU64(), "sealhash", sealhash, "hash", solution.Hash())
+	s.blake3.config.Log.Warn("Work submitted is too old", "number", solution.NumberU64(s.blake3.config.Context), "sealhash", sealhash, "hash", solution.Hash())
 	return false
 }
@@ -0,0 +1,175 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+var (
+	big8  = big.NewInt(8)
+	big32 = big.NewInt(32)
+)
+
+// Rewards is the supply a block issues, split between its miner, the miner of
+// its parent, the miners of the uncles it includes and the treasury.
+type Rewards struct {
+	Miner    *big.Int   // Reward of the coinbase of the block for including uncles
+	Parent   *big.Int   // Reward of the coinbase of the parent for the order it satisfied
+	Uncles   []*big.Int // Reward of the coinbase of every uncle
+	Treasury *big.Int   // Share of the parent reward paid to the treasury
+	Order    int        // Difficulty order of the parent, -1 if it isn't rewarded
+}
+
+// Total returns the supply issued by the block.
+func (r *Rewards) Total() *big.Int {
+	total := new(big.Int).Add(r.Miner, r.Parent)
+	total.Add(total, r.Treasury)
+	for _, reward := range r.Uncles {
+		total.Add(total, reward)
+	}
+	return total
+}
+
+// CalculateReward calculates the reward of a block of the given difficulty
+// order with the given number in the context of the config, after applying
+// the halvings of the emission schedule since its fork. Before the fork, or
+// without a configured reward, a block of each order is paid
+// regions = # of regions
+// zones = # of zones
+// For each prime = Reward/3
+// For each region = Reward/(3*regions*time-factor)
+// For each zone = Reward/(3*regions*zones*time-factor^2)
+func CalculateReward(config *params.ChainConfig, order int, number *big.Int) *big.Int {
+	if !config.IsEmission(number) {
+		return defaultReward(order)
+	}
+	reward := config.EmissionReward(order)
+	if reward == nil {
+		reward = defaultReward(order)
+	}
+	interval := config.HalvingInterval(config.Context)
+	if interval == 0 {
+		return new(big.Int).Set(reward)
+	}
+	elapsed := new(big.Int).Sub(number, config.EmissionBlock)
+	halvings := elapsed.Div(elapsed, new(big.Int).SetUint64(interval))
+	if !halvings.IsUint64() || halvings.Uint64() >= uint64(reward.BitLen()) {
+		return new(big.Int)
+	}
+	return new(big.Int).Rsh(reward, uint(halvings.Uint64()))
+}
+
+// defaultReward returns the block reward of a context without an emission
+// schedule.
+func defaultReward(context int) *big.Int {
+	reward := big.NewInt(5e18)
+
+	timeFactor := big.NewInt(10)
+
+	regions := big.NewInt(3)
+	zones := big.NewInt(3)
+
+	finalReward := new(big.Int)
+
+	if context == 0 {
+		primeReward := big.NewInt(3)
+		primeReward.Div(reward, primeReward)
+		finalReward = primeReward
+	}
+	if context == 1 {
+		regionReward := big.NewInt(3)
+		regionReward.Mul(regionReward, regions)
+		regionReward.Mul(regionReward, timeFactor)
+		regionReward.Div(reward, regionReward)
+		finalReward = regionReward
+	}
+	if context == 2 {
+		zoneReward := big.NewInt(3)
+		zoneReward.Mul(zoneReward, regions)
+		zoneReward.Mul(zoneReward, zones)
+		zoneReward.Mul(zoneReward, timeFactor)
+		zoneReward.Mul(zoneReward, timeFactor)
+		zoneReward.Div(reward, zoneReward)
+		finalReward = zoneReward
+	}
+
+	return finalReward
+}
+
+// RewardedOrder returns the difficulty order of the parent of a block, which
+// the block pays the reward of, or -1 if the parent is the genesis block or
+// satisfies no order.
+func RewardedOrder(config *params.ChainConfig, parent *types.Header, difficultyOrder func(*types.Header) (int, error)) int {
+	if parent == nil || parent.Number[config.Context].Sign() == 0 {
+		return -1
+	}
+	order, err := difficultyOrder(parent)
+	if err != nil {
+		return -1
+	}
+	return order
+}
+
+// BlockRewards calculates the supply issued by a block with the given uncles
+// in the context of the config. The order of a block is only known once it is
+// sealed, so every block pays the reward of the order its parent satisfied,
+// as returned by RewardedOrder, to the coinbase of the parent. The treasury
+// share is taken from that reward, uncle rewards and the uncle inclusion
+// reward are paid in full at the reward of the context.
+func BlockRewards(config *params.ChainConfig, header *types.Header, parent *types.Header, order int, uncles []*types.Header) *Rewards {
+	rewards := &Rewards{
+		Miner:    new(big.Int),
+		Parent:   new(big.Int),
+		Uncles:   make([]*big.Int, len(uncles)),
+		Treasury: new(big.Int),
+		Order:    -1,
+	}
+	for i := range rewards.Uncles {
+		rewards.Uncles[i] = new(big.Int)
+	}
+	// Skip block reward in catalyst mode
+	if config.IsCatalyst(header.Number[config.Context]) {
+		return rewards
+	}
+	// Pay the parent the reward of its order, splitting the treasury share off
+	if order >= 0 && parent != nil {
+		number := parent.Number[config.Context]
+		rewards.Order = order
+		rewards.Parent = CalculateReward(config, order, number)
+		if _, share := config.Treasury(config.Context); share > 0 && config.IsEmission(number) {
+			rewards.Treasury.Mul(rewards.Parent, new(big.Int).SetUint64(share))
+			rewards.Treasury.Div(rewards.Treasury, big.NewInt(params.MaxTreasuryShare))
+			rewards.Parent.Sub(rewards.Parent, rewards.Treasury)
+		}
+	}
+	// Accumulate the rewards for the miner and any included uncles
+	blockReward := CalculateReward(config, config.Context, header.Number[config.Context])
+	for i, uncle := range uncles {
+		r := rewards.Uncles[i]
+		r.Add(uncle.Number[config.Context], big8)
+		r.Sub(r, header.Number[config.Context])
+		r.Mul(r, blockReward)
+		r.Div(r, big8)
+
+		rewards.Miner.Add(rewards.Miner, new(big.Int).Div(blockReward, big32))
+	}
+	return rewards
+}
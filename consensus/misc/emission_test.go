@@ -0,0 +1,96 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+func emissionTestHeader(number int64) *types.Header {
+	header := types.NewEmptyHeader()
+	header.Number[params.ZONE] = big.NewInt(number)
+	return header
+}
+
+// Tests that a block pays the reward of the order its parent satisfied.
+func TestBlockRewards(t *testing.T) {
+	config := &params.ChainConfig{
+		Context:       params.ZONE,
+		EmissionBlock: big.NewInt(0),
+		Emission: &params.Emission{
+			Rewards:       []*big.Int{big.NewInt(3000), big.NewInt(2000), big.NewInt(1000)},
+			Treasuries:    []common.Address{{0x01}, {0x02}, {0x03}},
+			TreasuryShare: 1000,
+		},
+	}
+	var (
+		parent = emissionTestHeader(9)
+		header = emissionTestHeader(10)
+		uncle  = emissionTestHeader(9)
+	)
+	tests := []struct {
+		order                   int
+		parent, treasury, uncle int64
+		miner                   int64
+	}{
+		{params.PRIME, 2700, 300, 875, 31},
+		{params.REGION, 1800, 200, 875, 31},
+		{params.ZONE, 900, 100, 875, 31},
+		{-1, 0, 0, 875, 31},
+	}
+	for i, test := range tests {
+		rewards := BlockRewards(config, header, parent, test.order, []*types.Header{uncle})
+		if rewards.Order != test.order {
+			t.Errorf("test %d: order mismatch: have %d, want %d", i, rewards.Order, test.order)
+		}
+		if rewards.Parent.Int64() != test.parent || rewards.Treasury.Int64() != test.treasury {
+			t.Errorf("test %d: parent reward mismatch: have %v+%v, want %d+%d", i, rewards.Parent, rewards.Treasury, test.parent, test.treasury)
+		}
+		if rewards.Uncles[0].Int64() != test.uncle || rewards.Miner.Int64() != test.miner {
+			t.Errorf("test %d: uncle rewards mismatch: have %v/%v, want %d/%d", i, rewards.Uncles[0], rewards.Miner, test.uncle, test.miner)
+		}
+		if total := test.parent + test.treasury + test.uncle + test.miner; rewards.Total().Int64() != total {
+			t.Errorf("test %d: total mismatch: have %v, want %d", i, rewards.Total(), total)
+		}
+	}
+}
+
+func TestRewardedOrder(t *testing.T) {
+	var (
+		config = &params.ChainConfig{Context: params.ZONE}
+		region = func(*types.Header) (int, error) { return params.REGION, nil }
+		none   = func(*types.Header) (int, error) { return -1, errors.New("no order") }
+	)
+	if order := RewardedOrder(config, emissionTestHeader(1), region); order != params.REGION {
+		t.Errorf("order mismatch: have %d, want %d", order, params.REGION)
+	}
+	if order := RewardedOrder(config, emissionTestHeader(0), region); order != -1 {
+		t.Errorf("genesis rewarded with order %d", order)
+	}
+	if order := RewardedOrder(config, nil, region); order != -1 {
+		t.Errorf("missing parent rewarded with order %d", order)
+	}
+	if order := RewardedOrder(config, emissionTestHeader(1), none); order != -1 {
+		t.Errorf("unsealed parent rewarded with order %d", order)
+	}
+}
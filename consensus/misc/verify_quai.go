@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/common/math"
 	"github.com/spruce-solutions/go-quai/consensus"
 	"github.com/spruce-solutions/go-quai/core/types"
@@ -32,12 +33,12 @@ import (
 // - basefee check
 func VerifyHeaderGasAndFee(config *params.ChainConfig, parent, header *types.Header, chain consensus.ChainHeaderReader) error {
 	// Verify that the gas limit remains within allowed bounds
-	parentGasLimit := parent.GasLimit[types.QuaiNetworkContext]
-	if !config.IsLondon(parent.Number[types.QuaiNetworkContext]) {
-		parentGasLimit = parent.GasLimit[types.QuaiNetworkContext] * params.ElasticityMultiplier
+	parentGasLimit := parent.GasLimit[config.Context]
+	if !config.IsLondon(parent.Number[config.Context]) {
+		parentGasLimit = parent.GasLimit[config.Context] * params.ElasticityMultiplier
 	}
 
-	if err := VerifyGaslimit(parentGasLimit, header.GasLimit[types.QuaiNetworkContext]); err != nil {
+	if err := VerifyGaslimit(parentGasLimit, header.GasLimit[config.Context]); err != nil {
 		return err
 	}
 
@@ -47,7 +48,7 @@ func VerifyHeaderGasAndFee(config *params.ChainConfig, parent, header *types.Hea
 	}
 	// Verify the baseFee is correct based on the parent header.
 	expectedBaseFee := CalcBaseFee(config, parent, chain.GetHeaderByNumber, chain.GetUnclesInChain, chain.GetGasUsedInChain)
-	if header.BaseFee[types.QuaiNetworkContext].Cmp(expectedBaseFee) != 0 {
+	if header.BaseFee[config.Context].Cmp(expectedBaseFee) != 0 {
 		return fmt.Errorf("invalid baseFee: have %s, want %s, parentBaseFee %s, parentGasUsed %d",
 			expectedBaseFee, header.BaseFee, parent.BaseFee, parent.GasUsed)
 	}
@@ -57,20 +58,20 @@ func VerifyHeaderGasAndFee(config *params.ChainConfig, parent, header *types.Hea
 // CalcBaseFee calculates the basefee of the header.
 func CalcBaseFee(config *params.ChainConfig, parent *types.Header, headerByNumber func(number uint64) *types.Header, getUncles func(block *types.Block, length int) []*types.Header, getGasUsed func(block *types.Block, length int) int64) *big.Int {
 	// If the chain is not beyond 1000 blocks, return the initial basefee.
-	if parent.Number[types.QuaiNetworkContext].Int64() < 1000 {
+	if parent.Number[config.Context].Int64() < 1000 {
 		return new(big.Int).SetUint64(params.InitialBaseFee)
 	}
 
 	var (
 		slopeLength        = 500
 		slopeLengthDivisor = big.NewInt(int64(slopeLength))
-		reward             = CalculateReward()
+		reward             = CalculateReward(config, config.Context, new(big.Int).Add(parent.Number[config.Context], common.Big1))
 	)
 
 	// Transform the parent header into a block.
 	parentBlock := types.NewBlockWithHeader(parent)
 
-	header500 := headerByNumber(uint64(parent.Number[types.QuaiNetworkContext].Int64() - 500))
+	header500 := headerByNumber(uint64(parent.Number[config.Context].Int64() - 500))
 	if header500 == nil {
 		return new(big.Int).SetUint64(params.InitialBaseFee)
 	}
@@ -101,48 +102,6 @@ func CalcBaseFee(config *params.ChainConfig, parent *types.Header, headerByNumbe
 	}
 }
 
-// CalculateReward calculates the coinbase rewards depending on the type of the block
-// regions = # of regions
-// zones = # of zones
-// For each prime = Reward/3
-// For each region = Reward/(3*regions*time-factor)
-// For each zone = Reward/(3*regions*zones*time-factor^2)
-func CalculateReward() *big.Int {
-
-	reward := big.NewInt(5e18)
-
-	timeFactor := big.NewInt(10)
-
-	regions := big.NewInt(3)
-	zones := big.NewInt(3)
-
-	finalReward := new(big.Int)
-
-	if types.QuaiNetworkContext == 0 {
-		primeReward := big.NewInt(3)
-		primeReward.Div(reward, primeReward)
-		finalReward = primeReward
-	}
-	if types.QuaiNetworkContext == 1 {
-		regionReward := big.NewInt(3)
-		regionReward.Mul(regionReward, regions)
-		regionReward.Mul(regionReward, timeFactor)
-		regionReward.Div(reward, regionReward)
-		finalReward = regionReward
-	}
-	if types.QuaiNetworkContext == 2 {
-		zoneReward := big.NewInt(3)
-		zoneReward.Mul(zoneReward, regions)
-		zoneReward.Mul(zoneReward, zones)
-		zoneReward.Mul(zoneReward, timeFactor)
-		zoneReward.Mul(zoneReward, timeFactor)
-		zoneReward.Div(reward, zoneReward)
-		finalReward = zoneReward
-	}
-
-	return finalReward
-}
-
 // blockOntology is used to retrieve the MapContext of a given block.
 func BlockOntology(number []*big.Int) ([]int, error) {
 	forkNumber := number[0]
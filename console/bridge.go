@@ -67,6 +67,12 @@ func (b *bridge) NewAccount(call jsre.Call) (goja.Value, error) {
 		confirm  string
 		err      error
 	)
+	// An optional location selects the zone of the new account
+	location := goja.Null()
+	if len(call.Arguments) == 2 {
+		location = call.Argument(1)
+		call.Arguments = call.Arguments[:1]
+	}
 	switch {
 	// No password was specified, prompt the user for it
 	case len(call.Arguments) == 0:
@@ -83,14 +89,14 @@ func (b *bridge) NewAccount(call jsre.Call) (goja.Value, error) {
 	case len(call.Arguments) == 1 && call.Argument(0).ToString() != nil:
 		password = call.Argument(0).ToString().String()
 	default:
-		return nil, fmt.Errorf("expected 0 or 1 string argument")
+		return nil, fmt.Errorf("expected a string password and an optional location")
 	}
 	// Password acquired, execute the call and return
 	newAccount, callable := goja.AssertFunction(getJeth(call.VM).Get("newAccount"))
 	if !callable {
 		return nil, fmt.Errorf("jeth.newAccount is not callable")
 	}
-	ret, err := newAccount(goja.Null(), call.VM.ToValue(password))
+	ret, err := newAccount(goja.Null(), call.VM.ToValue(password), location)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +112,7 @@ func genTxRing(naccounts int) func(int, *BlockGen) {
 	from := 0
 	return func(i int, gen *BlockGen) {
 		block := gen.PrevBlock(i - 1)
-		gas := block.GasLimit()
+		gas := block.GasLimit(types.QuaiNetworkContext)
 		for {
 			gas -= params.TxGas
 			if gas < params.TxGas {
@@ -51,7 +51,7 @@ func NewBlockValidator(config *params.ChainConfig, blockchain *BlockChain, engin
 // validated at this point.
 func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	// Check whether the block's known, and if not, that it's linkable
-	if v.bc.HasBlockAndState(block.Hash(), block.NumberU64()) {
+	if v.bc.HasBlockAndState(block.Hash(), block.NumberU64(v.config.Context)) {
 		return ErrKnownBlock
 	}
 	// Header validity is known at this point, check the uncles and transactions
@@ -59,14 +59,14 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if err := v.engine.VerifyUncles(v.bc, block); err != nil {
 		return err
 	}
-	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash[types.QuaiNetworkContext] {
-		return fmt.Errorf("uncle root hash mismatch: have %x, want %x", hash, header.UncleHash[types.QuaiNetworkContext])
+	if hash := types.CalcUncleHash(block.Uncles()); hash != header.UncleHash[v.config.Context] {
+		return fmt.Errorf("uncle root hash mismatch: have %x, want %x", hash, header.UncleHash[v.config.Context])
 	}
-	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash[types.QuaiNetworkContext] {
+	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash[v.config.Context] {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
-	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
-		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
+	if !v.bc.HasBlockAndState(block.ParentHash(v.config.Context), block.NumberU64(v.config.Context)-1) {
+		if !v.bc.HasBlock(block.ParentHash(v.config.Context), block.NumberU64(v.config.Context)-1) {
 			return consensus.ErrUnknownAncestor
 		}
 		return consensus.ErrPrunedAncestor
@@ -80,23 +80,23 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 // otherwise nil and an error is returned.
 func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error {
 	header := block.Header()
-	if block.GasUsed() != usedGas {
-		return fmt.Errorf("invalid gas used (remote: %d local: %d)", block.GasUsed(), usedGas)
+	if block.GasUsed(v.config.Context) != usedGas {
+		return fmt.Errorf("invalid gas used (remote: %d local: %d)", block.GasUsed(v.config.Context), usedGas)
 	}
 	// Validate the received block's bloom with the one derived from the generated receipts.
 	// For valid blocks this should always validate to true.
 	rbloom := types.CreateBloom(receipts)
-	if rbloom != header.Bloom[types.QuaiNetworkContext] {
+	if rbloom != header.Bloom[v.config.Context] {
 		return fmt.Errorf("invalid bloom (remote: %x  local: %x)", header.Bloom, rbloom)
 	}
 	// Tre receipt Trie's root (R = (Tr [[H1, R1], ... [Hn, Rn]]))
 	receiptSha := types.DeriveSha(receipts, trie.NewStackTrie(nil))
-	if receiptSha != header.ReceiptHash[types.QuaiNetworkContext] {
-		return fmt.Errorf("invalid receipt root hash (remote: %x local: %x)", header.ReceiptHash[types.QuaiNetworkContext], receiptSha)
+	if receiptSha != header.ReceiptHash[v.config.Context] {
+		return fmt.Errorf("invalid receipt root hash (remote: %x local: %x)", header.ReceiptHash[v.config.Context], receiptSha)
 	}
 	// Validate the state root against the received state root and throw
 	// an error if they don't match.
-	if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number[types.QuaiNetworkContext])); header.Root[types.QuaiNetworkContext] != root {
+	if root := statedb.IntermediateRoot(v.config.IsEIP158(header.Number[v.config.Context])); header.Root[v.config.Context] != root {
 		return fmt.Errorf("invalid merkle root (remote: %x local: %x)", header.Root, root)
 	}
 	return nil
@@ -104,13 +104,13 @@ func (v *BlockValidator) ValidateState(block *types.Block, statedb *state.StateD
 
 // CalcGasLimit computes the gas limit of the next block after parent. It aims
 // to keep blocks 95% full.  If we have achieved our max gas limit, we will expand
-// our gas limit to reach our uncle rate.
-func CalcGasLimit(parentGasLimit, gasUsed uint64, uncleCount int) uint64 {
+// our gas limit to reach our uncle rate in the given context.
+func CalcGasLimit(parentGasLimit, gasUsed uint64, uncleCount int, context int) uint64 {
 	delta := parentGasLimit/params.GasLimitBoundDivisor - 1
 	// Add 1000 check for uint64 division comparison to 95%
 	percent := (gasUsed * 1000) / (parentGasLimit * 1000)
 	limit := parentGasLimit
-	aboveRate := uncleCount > params.TargetUncles[types.QuaiNetworkContext]
+	aboveRate := uncleCount > params.TargetUncles[context]
 	// If we're receiving full blocks, we try to increase the block size
 	if percent > uint64(800) {
 		limit = parentGasLimit + delta
@@ -208,23 +208,23 @@ func TestCalcGasLimit(t *testing.T) {
 		{40000000, 40039061, 39960939},
 	} {
 		// Increase
-		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0), tc.max; have != want {
+		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0, params.PRIME), tc.max; have != want {
 			t.Errorf("test %d: have %d want <%d", i, have, want)
 		}
 		// Decrease
-		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0), tc.min; have != want {
+		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0, params.PRIME), tc.min; have != want {
 			t.Errorf("test %d: have %d want >%d", i, have, want)
 		}
 		// Small decrease
-		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0), tc.pGasLimit-1; have != want {
+		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0, params.PRIME), tc.pGasLimit-1; have != want {
 			t.Errorf("test %d: have %d want %d", i, have, want)
 		}
 		// Small increase
-		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0), tc.pGasLimit+1; have != want {
+		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0, params.PRIME), tc.pGasLimit+1; have != want {
 			t.Errorf("test %d: have %d want %d", i, have, want)
 		}
 		// No change
-		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0), tc.pGasLimit; have != want {
+		if have, want := CalcGasLimit(tc.pGasLimit, 0, 0, params.PRIME), tc.pGasLimit; have != want {
 			t.Errorf("test %d: have %d want %d", i, have, want)
 		}
 	}
@@ -24,7 +24,6 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"runtime"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -37,12 +36,12 @@ import (
 	"github.com/spruce-solutions/go-quai/common/prque"
 	"github.com/spruce-solutions/go-quai/consensus"
 	"github.com/spruce-solutions/go-quai/consensus/misc"
+	"github.com/spruce-solutions/go-quai/core/forkchoice"
 	"github.com/spruce-solutions/go-quai/core/rawdb"
 	"github.com/spruce-solutions/go-quai/core/state"
 	"github.com/spruce-solutions/go-quai/core/state/snapshot"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/core/vm"
-	"github.com/spruce-solutions/go-quai/ethclient/quaiclient"
 	"github.com/spruce-solutions/go-quai/ethdb"
 	"github.com/spruce-solutions/go-quai/event"
 	"github.com/spruce-solutions/go-quai/log"
@@ -50,6 +49,7 @@ import (
 	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rlp"
 	"github.com/spruce-solutions/go-quai/trie"
+	"golang.org/x/time/rate"
 )
 
 var (
@@ -81,6 +81,8 @@ var (
 	blockReorgDropMeter     = metrics.NewRegisteredMeter("chain/reorg/drop", nil)
 	blockReorgInvalidatedTx = metrics.NewRegisteredMeter("chain/reorg/invalidTx", nil)
 
+	extBlockPruneMeter = metrics.NewRegisteredMeter("chain/extblocks/pruned", nil)
+
 	blockPrefetchExecuteTimer   = metrics.NewRegisteredTimer("chain/prefetch/executes", nil)
 	blockPrefetchInterruptMeter = metrics.NewRegisteredMeter("chain/prefetch/interrupts", nil)
 
@@ -90,14 +92,15 @@ var (
 )
 
 const (
-	bodyCacheLimit      = 256
-	blockCacheLimit     = 256
-	receiptsCacheLimit  = 32
-	txLookupCacheLimit  = 1024
-	maxFutureBlocks     = 256
-	maxTimeFutureBlocks = 30
-	TriesInMemory       = 128
-	extBlockQueueLimit  = 1024
+	bodyCacheLimit        = 256
+	blockCacheLimit       = 256
+	receiptsCacheLimit    = 32
+	txLookupCacheLimit    = 1024
+	maxFutureBlocks       = 256
+	maxTimeFutureBlocks   = 30
+	TriesInMemory         = 128
+	extBlockQueueLimit    = 1024
+	coincidenceCacheLimit = 1024
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
@@ -140,19 +143,25 @@ type CacheConfig struct {
 
 	SnapshotWait bool // Wait for snapshot construction on startup. TODO(karalabe): This is a dirty hack for testing, nuke it
 
-	ExternalBlockLimit   int    // Memory allowance (MB) to use for caching trie nodes in memory
-	ExternalBlockJournal string // Disk journal for saving clean cache entries.
+	PCRCDepth      uint64      // Number of prime termini PCRC is re-applied back to when inserting blocks, 0 for a single check
+	PCRCCheckpoint common.Hash // Trusted prime terminus deep PCRC verification stops at
+
+	ExternalBlockLimit     int    // Memory allowance (MB) to use for caching external blocks in memory
+	ExternalBlockRetention uint64 // Number of blocks below the head external blocks are kept for, 0 to keep them forever
+
+	LinkSecret []byte // Secret authenticating the links to the dominant and subordinate chains, nil for unauthenticated links
 }
 
 // defaultCacheConfig are the default caching values if none are specified by the
 // user (also used during testing).
 var defaultCacheConfig = &CacheConfig{
-	TrieCleanLimit:     256,
-	TrieDirtyLimit:     256,
-	TrieTimeLimit:      5 * time.Minute,
-	SnapshotLimit:      256,
-	SnapshotWait:       true,
-	ExternalBlockLimit: 256,
+	TrieCleanLimit:         256,
+	TrieDirtyLimit:         256,
+	TrieTimeLimit:          5 * time.Minute,
+	SnapshotLimit:          256,
+	SnapshotWait:           true,
+	ExternalBlockLimit:     256,
+	ExternalBlockRetention: 90000,
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -193,6 +202,12 @@ type BlockChain struct {
 	chainHeadFeed            event.Feed
 	chainUncleFeed           event.Feed
 	missingExternalBlockFeed event.Feed
+	etxStatusFeed            event.Feed
+	etxPool                  *ETxPool // Inbound ETxs waiting to be applied
+	reorgJournalFeed         event.Feed
+	reorgJournalLock         sync.Mutex // Serializes the numbering of reorg journal entries
+	faultProofFeed           event.Feed
+	faultLimiter             *rate.Limiter // Limits the fault proofs stored from peers and RPC
 	logsFeed                 event.Feed
 	blockProcFeed            event.Feed
 	scope                    event.SubscriptionScope
@@ -212,12 +227,15 @@ type BlockChain struct {
 	txLookupCache      *lru.Cache       // Cache for the most recent transaction lookup data.
 	futureBlocks       *lru.Cache       // future blocks are blocks added for later processing
 	externalBlockQueue *lru.Cache       // Queue for external blocks
-	externalBlocks     *fastcache.Cache // blocks that need to be applied externally
+	externalBlocks     *fastcache.Cache // Cache for the most recently used external blocks
+	externalBlockLock  sync.Mutex       // Serializes the indexing of stored external blocks
+	coincidenceCache   *lru.Cache       // Cache for the previous coincident blocks of recent headers
 
 	quit          chan struct{}  // blockchain quit channel
 	wg            sync.WaitGroup // chain processing wait group for shutting down
 	running       int32          // 0 if chain is running, 1 when stopped
 	procInterrupt int32          // interrupt signaler for block processing
+	supplyStart   int32          // 1 while the supply tracking is started in the background
 
 	engine     consensus.Engine
 	validator  Validator // Block and state validator interface
@@ -228,8 +246,10 @@ type BlockChain struct {
 
 	shouldPreserve func(*types.Block) bool // Function used to determine whether should preserve the given block.
 
-	domClient  *quaiclient.Client   // domClient is used to check if a given dominant block in the chain is canonical in dominant chain.
-	subClients []*quaiclient.Client // subClinets is used to check is a coincident block is valid in the subordinate context
+	domLink   DomSubLink   // domLink is used to check if a given dominant block in the chain is canonical in dominant chain.
+	subLinks  []DomSubLink // subLinks is used to check is a coincident block is valid in the subordinate context
+	linkLock  sync.RWMutex // Protects domLink and subLinks, which are set while the chain runs
+	importing bool         // importing is set while an export is inserted, its bundled dominant blocks standing in for the dom (protected by chainmu)
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -239,6 +259,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	if cacheConfig == nil {
 		cacheConfig = defaultCacheConfig
 	}
+	// Chain ID lookups of the network need its ontology if configured in genesis
+	if chainConfig.Ontology != nil || len(chainConfig.OntologyForks) > 0 {
+		if err := params.RegisterOntology(chainConfig.NetworkOntology()); err != nil {
+			return nil, err
+		}
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	receiptsCache, _ := lru.New(receiptsCacheLimit)
@@ -246,13 +272,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	txLookupCache, _ := lru.New(txLookupCacheLimit)
 	futureBlocks, _ := lru.New(maxFutureBlocks)
 	externalBlockQueue, _ := lru.New(extBlockQueueLimit)
+	coincidenceCache, _ := lru.New(coincidenceCacheLimit)
 
-	var externalBlocks *fastcache.Cache
-	if cacheConfig.ExternalBlockJournal == "" {
-		externalBlocks = fastcache.New(cacheConfig.ExternalBlockLimit * 1024 * 1024)
-	} else {
-		externalBlocks = fastcache.LoadFromFileOrNew(cacheConfig.ExternalBlockJournal, cacheConfig.ExternalBlockLimit*1024*1024)
-	}
+	externalBlocks := fastcache.New(cacheConfig.ExternalBlockLimit * 1024 * 1024)
 
 	bc := &BlockChain{
 		chainConfig: chainConfig,
@@ -273,6 +295,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		futureBlocks:       futureBlocks,
 		externalBlocks:     externalBlocks,
 		externalBlockQueue: externalBlockQueue,
+		coincidenceCache:   coincidenceCache,
+		faultLimiter:       rate.NewLimiter(faultProofRate, faultProofBurst),
+		etxPool:            NewETxPool(db),
 		engine:             engine,
 		vmConfig:           vmConfig,
 	}
@@ -282,16 +307,25 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
 
-	// only set the domClient if the chain is not prime
-	if types.QuaiNetworkContext != params.PRIME {
-		bc.domClient = MakeDomClient(domClientUrl)
+	// only set the domLink if the chain is not prime. An empty url leaves the
+	// link unset, so that an in-process dominant can be attached with SetDomLink.
+	if chainConfig.Context != params.PRIME && domClientUrl != "" {
+		bc.domLink = MakeDomLink(domClientUrl, cacheConfig.LinkSecret)
 	}
 
-	bc.subClients = make([]*quaiclient.Client, 3)
-	// only set the subClients if the chain is not region
-	if types.QuaiNetworkContext != params.ZONE {
+	bc.subLinks = make([]DomSubLink, subLinkCount(chainConfig))
+	// only set the subLinks if the chain is not region
+	if chainConfig.Context != params.ZONE && len(subClientUrls) > 0 {
 		go func() {
-			bc.subClients = MakeSubClients(subClientUrls)
+			subLinks := MakeSubLinks(subLinkCount(chainConfig), subClientUrls, cacheConfig.LinkSecret)
+
+			bc.linkLock.Lock()
+			defer bc.linkLock.Unlock()
+			for i, subLink := range subLinks {
+				if subLink != nil {
+					bc.subLinks[i] = subLink
+				}
+			}
 		}()
 	}
 
@@ -326,7 +360,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	}
 	// Make sure the state associated with the block is available
 	head := bc.CurrentBlock()
-	if _, err := state.New(head.Root(), bc.stateCache, bc.snaps); err != nil {
+	if _, err := state.New(head.Root(bc.chainConfig.Context), bc.stateCache, bc.snaps); err != nil {
 		// Head state is missing, before the state recovery, find out the
 		// disk layer point of snapshot(if it's enabled). Make sure the
 		// rewound point is lower than disk layer.
@@ -335,9 +369,9 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			diskRoot = rawdb.ReadSnapshotRoot(bc.db)
 		}
 		if diskRoot != (common.Hash{}) {
-			log.Warn("Head state missing, repairing", "number", head.Number(), "hash", head.Hash(), "snaproot", diskRoot)
+			log.Warn("Head state missing, repairing", "number", head.Number(bc.chainConfig.Context), "hash", head.Hash(), "snaproot", diskRoot)
 
-			snapDisk, err := bc.SetHeadBeyondRoot(head.NumberU64(), diskRoot)
+			snapDisk, err := bc.SetHeadBeyondRoot(head.NumberU64(bc.chainConfig.Context), diskRoot)
 			if err != nil {
 				return nil, err
 			}
@@ -346,8 +380,8 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 				rawdb.WriteSnapshotRecoveryNumber(bc.db, snapDisk)
 			}
 		} else {
-			log.Warn("Head state missing, repairing", "number", head.Number(), "hash", head.Hash())
-			if err := bc.SetHead(head.NumberU64()); err != nil {
+			log.Warn("Head state missing, repairing", "number", head.Number(bc.chainConfig.Context), "hash", head.Hash())
+			if err := bc.SetHead(head.NumberU64(bc.chainConfig.Context)); err != nil {
 				return nil, err
 			}
 		}
@@ -362,22 +396,22 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		// blockchain repair. If the head full block is even lower than the ancient
 		// chain, truncate the ancient store.
 		fullBlock := bc.CurrentBlock()
-		if fullBlock != nil && fullBlock.Hash() != bc.genesisBlock.Hash() && fullBlock.NumberU64() < frozen-1 {
+		if fullBlock != nil && fullBlock.Hash() != bc.genesisBlock.Hash() && fullBlock.NumberU64(bc.chainConfig.Context) < frozen-1 {
 			needRewind = true
-			low = fullBlock.NumberU64()
+			low = fullBlock.NumberU64(bc.chainConfig.Context)
 		}
 		// In fast sync, it may happen that ancient data has been written to the
 		// ancient store, but the LastFastBlock has not been updated, truncate the
 		// extra data here.
 		fastBlock := bc.CurrentFastBlock()
-		if fastBlock != nil && fastBlock.NumberU64() < frozen-1 {
+		if fastBlock != nil && fastBlock.NumberU64(bc.chainConfig.Context) < frozen-1 {
 			needRewind = true
-			if fastBlock.NumberU64() < low || low == 0 {
-				low = fastBlock.NumberU64()
+			if fastBlock.NumberU64(bc.chainConfig.Context) < low || low == 0 {
+				low = fastBlock.NumberU64(bc.chainConfig.Context)
 			}
 		}
 		if needRewind {
-			log.Error("Truncating ancient chain", "from", bc.CurrentHeader().Number[types.QuaiNetworkContext].Uint64(), "to", low)
+			log.Error("Truncating ancient chain", "from", bc.CurrentHeader().Number[chainConfig.Context].Uint64(), "to", low)
 			if err := bc.SetHead(low); err != nil {
 				return nil, err
 			}
@@ -392,11 +426,11 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
 			// get the canonical block corresponding to the offending header's number
-			headerByNumber := bc.GetHeaderByNumber(header.Number[types.QuaiNetworkContext].Uint64())
+			headerByNumber := bc.GetHeaderByNumber(header.Number[chainConfig.Context].Uint64())
 			// make sure the headerByNumber (if present) is in our current canonical chain
 			if headerByNumber != nil && headerByNumber.Hash() == header.Hash() {
-				log.Error("Found bad hash, rewinding chain", "number", header.Number[types.QuaiNetworkContext], "hash", header.ParentHash[types.QuaiNetworkContext])
-				if err := bc.SetHead(header.Number[types.QuaiNetworkContext].Uint64() - 1); err != nil {
+				log.Error("Found bad hash, rewinding chain", "number", header.Number[chainConfig.Context], "hash", header.ParentHash[chainConfig.Context])
+				if err := bc.SetHead(header.Number[chainConfig.Context].Uint64() - 1); err != nil {
 					return nil, err
 				}
 				log.Error("Chain rewind was successful, resuming normal operation")
@@ -412,16 +446,20 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		var recover bool
 
 		head := bc.CurrentBlock()
-		if layer := rawdb.ReadSnapshotRecoveryNumber(bc.db); layer != nil && *layer > head.NumberU64() {
-			log.Warn("Enabling snapshot recovery", "chainhead", head.NumberU64(), "diskbase", *layer)
+		if layer := rawdb.ReadSnapshotRecoveryNumber(bc.db); layer != nil && *layer > head.NumberU64(bc.chainConfig.Context) {
+			log.Warn("Enabling snapshot recovery", "chainhead", head.NumberU64(bc.chainConfig.Context), "diskbase", *layer)
 			recover = true
 		}
-		bc.snaps, _ = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, head.Root(), !bc.cacheConfig.SnapshotWait, true, recover)
+		bc.snaps, _ = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, head.Root(bc.chainConfig.Context), !bc.cacheConfig.SnapshotWait, true, recover)
 	}
 	// Take ownership of this particular state
 	bc.wg.Add(1)
 	go bc.update()
 
+	// Keep the external blocks the next blocks reference ahead of import
+	bc.wg.Add(1)
+	go bc.prefetchExternalBlocks()
+
 	if txLookupLimit != nil {
 		bc.txLookupLimit = *txLookupLimit
 
@@ -483,7 +521,7 @@ func (bc *BlockChain) loadLastState() error {
 	}
 	// Everything seems to be fine, set as the head block
 	bc.currentBlock.Store(currentBlock)
-	headBlockGauge.Update(int64(currentBlock.NumberU64()))
+	headBlockGauge.Update(int64(currentBlock.NumberU64(bc.chainConfig.Context)))
 
 	// Restore the last known head header
 	currentHeader := currentBlock.Header()
@@ -496,56 +534,129 @@ func (bc *BlockChain) loadLastState() error {
 
 	// Restore the last known head fast block
 	bc.currentFastBlock.Store(currentBlock)
-	headFastBlockGauge.Update(int64(currentBlock.NumberU64()))
+	headFastBlockGauge.Update(int64(currentBlock.NumberU64(bc.chainConfig.Context)))
 
 	if head := rawdb.ReadHeadFastBlockHash(bc.db); head != (common.Hash{}) {
 		if block := bc.GetBlockByHash(head); block != nil {
 			bc.currentFastBlock.Store(block)
-			headFastBlockGauge.Update(int64(block.NumberU64()))
+			headFastBlockGauge.Update(int64(block.NumberU64(bc.chainConfig.Context)))
 		}
 	}
 	// Issue a status log for the user
 	currentFastBlock := bc.CurrentFastBlock()
 
-	headerTd := bc.GetTd(currentHeader.Hash(), currentHeader.Number[types.QuaiNetworkContext].Uint64())
-	blockTd := bc.GetTd(currentBlock.Hash(), currentBlock.NumberU64())
-	fastTd := bc.GetTd(currentFastBlock.Hash(), currentFastBlock.NumberU64())
+	headerTd := bc.GetTd(currentHeader.Hash(), currentHeader.Number[bc.chainConfig.Context].Uint64())
+	blockTd := bc.GetTd(currentBlock.Hash(), currentBlock.NumberU64(bc.chainConfig.Context))
+	fastTd := bc.GetTd(currentFastBlock.Hash(), currentFastBlock.NumberU64(bc.chainConfig.Context))
 
 	log.Info("Loaded most recent local header", "number", currentHeader.Number, "hash", currentHeader.Hash(), "td", headerTd, "age", common.PrettyAge(time.Unix(int64(currentHeader.Time), 0)))
-	log.Info("Loaded most recent local full block", "number", currentBlock.Number(), "hash", currentBlock.Hash(), "td", blockTd, "age", common.PrettyAge(time.Unix(int64(currentBlock.Time()), 0)))
-	log.Info("Loaded most recent local fast block", "number", currentFastBlock.Number(), "hash", currentFastBlock.Hash(), "td", fastTd, "age", common.PrettyAge(time.Unix(int64(currentFastBlock.Time()), 0)))
+	log.Info("Loaded most recent local full block", "number", currentBlock.Number(bc.chainConfig.Context), "hash", currentBlock.Hash(), "td", blockTd, "age", common.PrettyAge(time.Unix(int64(currentBlock.Time()), 0)))
+	log.Info("Loaded most recent local fast block", "number", currentFastBlock.Number(bc.chainConfig.Context), "hash", currentFastBlock.Hash(), "td", fastTd, "age", common.PrettyAge(time.Unix(int64(currentFastBlock.Time()), 0)))
 	if pivot := rawdb.ReadLastPivotNumber(bc.db); pivot != nil {
 		log.Info("Loaded last fast-sync pivot marker", "number", *pivot)
 	}
 	return nil
 }
 
-// MakeDomClient creates the quaiclient for the given domurl
-func MakeDomClient(domurl string) *quaiclient.Client {
+// MakeDomLink creates the websocket link for the given domurl, authenticated
+// with the secret if it is not nil.
+func MakeDomLink(domurl string, secret []byte) DomSubLink {
 	if domurl == "" {
 		log.Crit("dom client url is empty")
 	}
-	domClient, err := quaiclient.Dial(domurl)
+	domLink, err := DialDomSubLink(domurl, secret)
 	if err != nil {
 		log.Crit("Error connecting to the dominant go-quai client", "err", err)
 	}
-	return domClient
+	return domLink
+}
+
+// subLinkCount returns the number of subordinate chains of the chain, including
+// the ones created by scheduled ontology forks.
+func subLinkCount(config *params.ChainConfig) int {
+	shape := config.OntologyShape()
+	count := 3
+	switch config.Context {
+	case params.PRIME:
+		count = shape[0]
+	case params.REGION:
+		count = shape[1]
+	}
+	if count < 3 {
+		count = 3
+	}
+	return count
 }
 
-// MakeSubClients creates the quaiclient for the given suburls
-func MakeSubClients(suburls []string) []*quaiclient.Client {
-	subClients := make([]*quaiclient.Client, 3)
+// MakeSubLinks creates count links, dialing the websockets of the given suburls,
+// authenticated with the secret if it is not nil.
+func MakeSubLinks(count int, suburls []string, secret []byte) []DomSubLink {
+	subLinks := make([]DomSubLink, count)
 	for i, suburl := range suburls {
+		if i >= count {
+			log.Warn("Ignoring sub client url beyond the ontology", "index", i, "url", suburl)
+			break
+		}
 		if suburl == "" {
 			log.Warn("sub client url is empty")
 		}
-		subClient, err := quaiclient.Dial(suburl)
+		subLink, err := DialDomSubLink(suburl, secret)
 		if err != nil {
 			log.Crit("Error connecting to the subordinate go-quai client for index", "index", i, " err ", err)
 		}
-		subClients[i] = subClient
+		subLinks[i] = subLink
+	}
+	return subLinks
+}
+
+// SetDomLink sets the link used to reach the dominant chain. It is used to
+// link chains running inside the same process instead of dialing a websocket.
+func (bc *BlockChain) SetDomLink(domLink DomSubLink) {
+	bc.linkLock.Lock()
+	defer bc.linkLock.Unlock()
+
+	bc.domLink = domLink
+}
+
+// SetSubLink sets the link used to reach the subordinate chain at the given
+// index of the local location.
+func (bc *BlockChain) SetSubLink(index int, subLink DomSubLink) error {
+	bc.linkLock.Lock()
+	defer bc.linkLock.Unlock()
+
+	if index < 0 || index >= len(bc.subLinks) {
+		return fmt.Errorf("sub link index %d out of range", index)
 	}
-	return subClients
+	bc.subLinks[index] = subLink
+	return nil
+}
+
+// getDomLink returns the link to the dominant chain, nil if it is not set.
+func (bc *BlockChain) getDomLink() DomSubLink {
+	bc.linkLock.RLock()
+	defer bc.linkLock.RUnlock()
+
+	return bc.domLink
+}
+
+// getSubLink returns the link to the subordinate chain at the given index of
+// the local location, nil if it is not set.
+func (bc *BlockChain) getSubLink(index int) DomSubLink {
+	bc.linkLock.RLock()
+	defer bc.linkLock.RUnlock()
+
+	if index < 0 || index >= len(bc.subLinks) {
+		return nil
+	}
+	return bc.subLinks[index]
+}
+
+// getSubLinks returns a copy of the links to the subordinate chains.
+func (bc *BlockChain) getSubLinks() []DomSubLink {
+	bc.linkLock.RLock()
+	defer bc.linkLock.RUnlock()
+
+	return append([]DomSubLink(nil), bc.subLinks...)
 }
 
 // SetHead rewinds the local chain to a new head. Depending on whether the node
@@ -580,8 +691,8 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 		// Rewind the block chain, ensuring we don't end up with a stateless head
 		// block. Note, depth equality is permitted to allow using SetHead as a
 		// chain reparation mechanism without deleting any data!
-		if currentBlock := bc.CurrentBlock(); currentBlock != nil && header.Number[types.QuaiNetworkContext].Uint64() <= currentBlock.NumberU64() {
-			newHeadBlock := bc.GetBlock(header.Hash(), header.Number[types.QuaiNetworkContext].Uint64())
+		if currentBlock := bc.CurrentBlock(); currentBlock != nil && header.Number[bc.chainConfig.Context].Uint64() <= currentBlock.NumberU64(bc.chainConfig.Context) {
+			newHeadBlock := bc.GetBlock(header.Hash(), header.Number[bc.chainConfig.Context].Uint64())
 			if newHeadBlock == nil {
 				log.Error("Gap in the chain, rewinding to genesis", "number", header.Number, "hash", header.Hash())
 				newHeadBlock = bc.genesisBlock
@@ -593,30 +704,30 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 
 				for {
 					// If a root threshold was requested but not yet crossed, check
-					if root != (common.Hash{}) && !beyondRoot && newHeadBlock.Root() == root {
-						beyondRoot, rootNumber = true, newHeadBlock.NumberU64()
+					if root != (common.Hash{}) && !beyondRoot && newHeadBlock.Root(bc.chainConfig.Context) == root {
+						beyondRoot, rootNumber = true, newHeadBlock.NumberU64(bc.chainConfig.Context)
 					}
-					if _, err := state.New(newHeadBlock.Root(), bc.stateCache, bc.snaps); err != nil {
-						log.Trace("Block state missing, rewinding further", "number", newHeadBlock.NumberU64(), "hash", newHeadBlock.Hash())
-						if pivot == nil || newHeadBlock.NumberU64() > *pivot {
-							parent := bc.GetBlock(newHeadBlock.ParentHash(), newHeadBlock.NumberU64()-1)
+					if _, err := state.New(newHeadBlock.Root(bc.chainConfig.Context), bc.stateCache, bc.snaps); err != nil {
+						log.Trace("Block state missing, rewinding further", "number", newHeadBlock.NumberU64(bc.chainConfig.Context), "hash", newHeadBlock.Hash())
+						if pivot == nil || newHeadBlock.NumberU64(bc.chainConfig.Context) > *pivot {
+							parent := bc.GetBlock(newHeadBlock.ParentHash(bc.chainConfig.Context), newHeadBlock.NumberU64(bc.chainConfig.Context)-1)
 							if parent != nil {
 								newHeadBlock = parent
 								continue
 							}
-							log.Error("Missing block in the middle, aiming genesis", "number", newHeadBlock.NumberU64()-1, "hash", newHeadBlock.ParentHash())
+							log.Error("Missing block in the middle, aiming genesis", "number", newHeadBlock.NumberU64(bc.chainConfig.Context)-1, "hash", newHeadBlock.ParentHash(bc.chainConfig.Context))
 							newHeadBlock = bc.genesisBlock
 						} else {
-							log.Trace("Rewind passed pivot, aiming genesis", "number", newHeadBlock.NumberU64(), "hash", newHeadBlock.Hash(), "pivot", *pivot)
+							log.Trace("Rewind passed pivot, aiming genesis", "number", newHeadBlock.NumberU64(bc.chainConfig.Context), "hash", newHeadBlock.Hash(), "pivot", *pivot)
 							newHeadBlock = bc.genesisBlock
 						}
 					}
-					if beyondRoot || newHeadBlock.NumberU64() == 0 {
-						log.Debug("Rewound to block with state", "number", newHeadBlock.NumberU64(), "hash", newHeadBlock.Hash())
+					if beyondRoot || newHeadBlock.NumberU64(bc.chainConfig.Context) == 0 {
+						log.Debug("Rewound to block with state", "number", newHeadBlock.NumberU64(bc.chainConfig.Context), "hash", newHeadBlock.Hash())
 						break
 					}
-					log.Debug("Skipping block with threshold state", "number", newHeadBlock.NumberU64(), "hash", newHeadBlock.Hash(), "root", newHeadBlock.Root())
-					newHeadBlock = bc.GetBlock(newHeadBlock.ParentHash(), newHeadBlock.NumberU64()-1) // Keep rewinding
+					log.Debug("Skipping block with threshold state", "number", newHeadBlock.NumberU64(bc.chainConfig.Context), "hash", newHeadBlock.Hash(), "root", newHeadBlock.Root(bc.chainConfig.Context))
+					newHeadBlock = bc.GetBlock(newHeadBlock.ParentHash(bc.chainConfig.Context), newHeadBlock.NumberU64(bc.chainConfig.Context)-1) // Keep rewinding
 				}
 			}
 			rawdb.WriteHeadBlockHash(db, newHeadBlock.Hash())
@@ -626,11 +737,11 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 			// last step, however the direction of SetHead is from high
 			// to low, so it's safe the update in-memory markers directly.
 			bc.currentBlock.Store(newHeadBlock)
-			headBlockGauge.Update(int64(newHeadBlock.NumberU64()))
+			headBlockGauge.Update(int64(newHeadBlock.NumberU64(bc.chainConfig.Context)))
 		}
 		// Rewind the fast block in a simpleton way to the target head
-		if currentFastBlock := bc.CurrentFastBlock(); currentFastBlock != nil && header.Number[types.QuaiNetworkContext].Uint64() < currentFastBlock.NumberU64() {
-			newHeadFastBlock := bc.GetBlock(header.Hash(), header.Number[types.QuaiNetworkContext].Uint64())
+		if currentFastBlock := bc.CurrentFastBlock(); currentFastBlock != nil && header.Number[bc.chainConfig.Context].Uint64() < currentFastBlock.NumberU64(bc.chainConfig.Context) {
+			newHeadFastBlock := bc.GetBlock(header.Hash(), header.Number[bc.chainConfig.Context].Uint64())
 			// If either blocks reached nil, reset to the genesis state
 			if newHeadFastBlock == nil {
 				newHeadFastBlock = bc.genesisBlock
@@ -642,9 +753,9 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 			// last step, however the direction of SetHead is from high
 			// to low, so it's safe the update in-memory markers directly.
 			bc.currentFastBlock.Store(newHeadFastBlock)
-			headFastBlockGauge.Update(int64(newHeadFastBlock.NumberU64()))
+			headFastBlockGauge.Update(int64(newHeadFastBlock.NumberU64(bc.chainConfig.Context)))
 		}
-		head := bc.CurrentBlock().NumberU64()
+		head := bc.CurrentBlock().NumberU64(bc.chainConfig.Context)
 
 		// If setHead underflown the freezer threshold and the block processing
 		// intent afterwards is full block importing, delete the chain segment
@@ -674,11 +785,12 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 			rawdb.DeleteBody(db, hash, num)
 			rawdb.DeleteReceipts(db, hash, num)
 		}
+		rawdb.DeleteSupply(db, hash)
 		// Todo(rjl493456442) txlookup, bloombits, etc
 	}
 	// If SetHead was only called as a chain reparation method, try to skip
 	// touching the header chain altogether, unless the freezer is broken
-	if block := bc.CurrentBlock(); block.NumberU64() == head {
+	if block := bc.CurrentBlock(); block.NumberU64(bc.chainConfig.Context) == head {
 		if target, force := updateFn(bc.db, block.Header()); force {
 			bc.hc.SetHead(target, updateFn, delFn)
 		}
@@ -708,27 +820,27 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	if block == nil {
 		return fmt.Errorf("non existent block [%x..]", hash[:4])
 	}
-	if _, err := trie.NewSecure(block.Root(), bc.stateCache.TrieDB()); err != nil {
+	if _, err := trie.NewSecure(block.Root(bc.chainConfig.Context), bc.stateCache.TrieDB()); err != nil {
 		return err
 	}
 	// If all checks out, manually set the head block
 	bc.chainmu.Lock()
 	bc.currentBlock.Store(block)
-	headBlockGauge.Update(int64(block.NumberU64()))
+	headBlockGauge.Update(int64(block.NumberU64(bc.chainConfig.Context)))
 	bc.chainmu.Unlock()
 
 	// Destroy any existing state snapshot and regenerate it in the background,
 	// also resuming the normal maintenance of any previously paused snapshot.
 	if bc.snaps != nil {
-		bc.snaps.Rebuild(block.Root())
+		bc.snaps.Rebuild(block.Root(bc.chainConfig.Context))
 	}
-	log.Info("Committed new head block", "number", block.Number(), "hash", hash)
+	log.Info("Committed new head block", "number", block.Number(bc.chainConfig.Context), "hash", hash)
 	return nil
 }
 
 // GasLimit returns the gas limit of the current HEAD block.
 func (bc *BlockChain) GasLimit() uint64 {
-	return bc.CurrentBlock().GasLimit()
+	return bc.CurrentBlock().GasLimit(bc.chainConfig.Context)
 }
 
 // CurrentBlock retrieves the current head block of the canonical chain. The
@@ -760,7 +872,7 @@ func (bc *BlockChain) Processor() Processor {
 
 // State returns a new mutable state based on the current HEAD block.
 func (bc *BlockChain) State() (*state.StateDB, error) {
-	return bc.StateAt(bc.CurrentBlock().Root())
+	return bc.StateAt(bc.CurrentBlock().Root(bc.chainConfig.Context))
 }
 
 // StateAt returns a new mutable state based on a particular point in time.
@@ -790,8 +902,8 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 
 	// Prepare the genesis block and reinitialise the chain
 	batch := bc.db.NewBatch()
-	rawdb.WriteTd(batch, genesis.Hash(), genesis.NumberU64(), genesis.Header().Difficulty)
-	rawdb.WriteBlock(batch, genesis)
+	rawdb.WriteTd(batch, genesis.Hash(), genesis.NumberU64(bc.chainConfig.Context), genesis.Header().Difficulty)
+	rawdb.WriteBlock(batch, genesis, bc.chainConfig.Context)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to write genesis block", "err", err)
 	}
@@ -800,20 +912,23 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 	// Last update all in-memory chain markers
 	bc.genesisBlock = genesis
 	bc.currentBlock.Store(bc.genesisBlock)
-	headBlockGauge.Update(int64(bc.genesisBlock.NumberU64()))
+	headBlockGauge.Update(int64(bc.genesisBlock.NumberU64(bc.chainConfig.Context)))
 	bc.hc.SetGenesis(bc.genesisBlock.Header())
 	bc.hc.SetCurrentHeader(bc.genesisBlock.Header())
 	bc.currentFastBlock.Store(bc.genesisBlock)
-	headFastBlockGauge.Update(int64(bc.genesisBlock.NumberU64()))
+	headFastBlockGauge.Update(int64(bc.genesisBlock.NumberU64(bc.chainConfig.Context)))
 	return nil
 }
 
 // Export writes the active chain to the given writer.
 func (bc *BlockChain) Export(w io.Writer) error {
-	return bc.ExportN(w, uint64(0), bc.CurrentBlock().NumberU64())
+	return bc.ExportN(w, uint64(0), bc.CurrentBlock().NumberU64(bc.chainConfig.Context))
 }
 
-// ExportN writes a subset of the active chain to the given writer.
+// ExportN writes a subset of the active chain to the given writer. Every block
+// is exported together with its receipts and the external blocks it references,
+// so that the chain can be rebuilt without access to its dominant and
+// subordinate chains.
 func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	bc.chainmu.RLock()
 	defer bc.chainmu.RUnlock()
@@ -823,17 +938,20 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 	}
 	log.Info("Exporting batch of blocks", "count", last-first+1)
 
+	if err := rlp.Encode(w, bc.exportHeader()); err != nil {
+		return err
+	}
 	start, reported := time.Now(), time.Now()
 	for nr := first; nr <= last; nr++ {
-		block := bc.GetBlockByNumber(nr)
-		if block == nil {
-			return fmt.Errorf("export failed on #%d: not found", nr)
+		exported, err := bc.exportBlock(nr)
+		if err != nil {
+			return err
 		}
-		if err := block.EncodeRLP(w); err != nil {
+		if err := rlp.Encode(w, exported); err != nil {
 			return err
 		}
 		if time.Since(reported) >= statsReportLimit {
-			log.Info("Exporting blocks", "exported", block.NumberU64()-first, "elapsed", common.PrettyDuration(time.Since(start)))
+			log.Info("Exporting blocks", "exported", nr-first, "elapsed", common.PrettyDuration(time.Since(start)))
 			reported = time.Now()
 		}
 	}
@@ -848,12 +966,12 @@ func (bc *BlockChain) ExportN(w io.Writer, first uint64, last uint64) error {
 // Note, this function assumes that the `mu` mutex is held!
 func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	// If the block is on a side chain or an unknown one, force other heads onto it too
-	updateHeads := rawdb.ReadCanonicalHash(bc.db, block.NumberU64()) != block.Hash()
+	updateHeads := rawdb.ReadCanonicalHash(bc.db, block.NumberU64(bc.chainConfig.Context)) != block.Hash()
 
 	// Add the block to the canonical chain number scheme and mark as the head
 	batch := bc.db.NewBatch()
-	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
-	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64(bc.chainConfig.Context))
+	rawdb.WriteTxLookupEntriesByBlock(batch, block, bc.chainConfig.Context)
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// If the block is better than our head or is on a different chain, force update heads
@@ -869,10 +987,10 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	if updateHeads {
 		bc.hc.SetCurrentHeader(block.Header())
 		bc.currentFastBlock.Store(block)
-		headFastBlockGauge.Update(int64(block.NumberU64()))
+		headFastBlockGauge.Update(int64(block.NumberU64(bc.chainConfig.Context)))
 	}
 	bc.currentBlock.Store(block)
-	headBlockGauge.Update(int64(block.NumberU64()))
+	headBlockGauge.Update(int64(block.NumberU64(bc.chainConfig.Context)))
 }
 
 // Genesis retrieves the chain's genesis block.
@@ -954,7 +1072,7 @@ func (bc *BlockChain) HasBlockAndState(hash common.Hash, number uint64) bool {
 	if block == nil {
 		return false
 	}
-	return bc.HasState(block.Root())
+	return bc.HasState(block.Root(bc.chainConfig.Context))
 }
 
 // GetBlock retrieves a block from the database by hash and number,
@@ -1022,7 +1140,7 @@ func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*type
 			break
 		}
 		blocks = append(blocks, block)
-		hash = block.ParentHash()
+		hash = block.ParentHash(bc.chainConfig.Context)
 		*number--
 	}
 	return
@@ -1034,7 +1152,7 @@ func (bc *BlockChain) GetUnclesInChain(block *types.Block, length int) []*types.
 	uncles := []*types.Header{}
 	for i := 0; block != nil && i < length; i++ {
 		uncles = append(uncles, block.Uncles()...)
-		block = bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		block = bc.GetBlock(block.ParentHash(bc.chainConfig.Context), block.NumberU64(bc.chainConfig.Context)-1)
 	}
 	return uncles
 }
@@ -1044,8 +1162,8 @@ func (bc *BlockChain) GetUnclesInChain(block *types.Block, length int) []*types.
 func (bc *BlockChain) GetGasUsedInChain(block *types.Block, length int) int64 {
 	gasUsed := 0
 	for i := 0; block != nil && i < length; i++ {
-		gasUsed += int(block.GasUsed())
-		block = bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		gasUsed += int(block.GasUsed(bc.chainConfig.Context))
+		block = bc.GetBlock(block.ParentHash(bc.chainConfig.Context), block.NumberU64(bc.chainConfig.Context)-1)
 	}
 	return int64(gasUsed)
 }
@@ -1096,15 +1214,11 @@ func (bc *BlockChain) Stop() {
 	var snapBase common.Hash
 	if bc.snaps != nil {
 		var err error
-		if snapBase, err = bc.snaps.Journal(bc.CurrentBlock().Root()); err != nil {
+		if snapBase, err = bc.snaps.Journal(bc.CurrentBlock().Root(bc.chainConfig.Context)); err != nil {
 			log.Error("Failed to journal state snapshot", "err", err)
 		}
 	}
 
-	// Save the state of the external block cache to disk
-	log.Info("Writing external blocks state to disk", "dir", bc.cacheConfig.ExternalBlockJournal)
-	bc.externalBlocks.SaveToFileConcurrent(bc.cacheConfig.ExternalBlockJournal, runtime.GOMAXPROCS(0))
-
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -1114,11 +1228,11 @@ func (bc *BlockChain) Stop() {
 		triedb := bc.stateCache.TrieDB()
 
 		for _, offset := range []uint64{0, 1, TriesInMemory - 1} {
-			if number := bc.CurrentBlock().NumberU64(); number > offset {
+			if number := bc.CurrentBlock().NumberU64(bc.chainConfig.Context); number > offset {
 				recent := bc.GetBlockByNumber(number - offset)
 				if recent != nil {
-					log.Info("Writing cached state to disk", "block", recent.Number(), "hash", recent.Hash(), "root", recent.Root())
-					if err := triedb.Commit(recent.Root(), true, nil); err != nil {
+					log.Info("Writing cached state to disk", "block", recent.Number(bc.chainConfig.Context), "hash", recent.Hash(), "root", recent.Root(bc.chainConfig.Context))
+					if err := triedb.Commit(recent.Root(bc.chainConfig.Context), true, nil); err != nil {
 						log.Error("Failed to commit recent state trie", "err", err)
 					}
 				}
@@ -1167,7 +1281,7 @@ func (bc *BlockChain) procFutureBlocks() {
 	}
 	if len(blocks) > 0 {
 		sort.Slice(blocks, func(i, j int) bool {
-			return blocks[i].NumberU64() < blocks[j].NumberU64()
+			return blocks[i].NumberU64(bc.chainConfig.Context) < blocks[j].NumberU64(bc.chainConfig.Context)
 		})
 		// Insert one by one as chain insertion needs contiguous ancestry between blocks
 		for i := range blocks {
@@ -1207,14 +1321,14 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 	// Do a sanity check that the provided chain is actually ordered and linked
 	for i := 0; i < len(blockChain); i++ {
 		if i != 0 {
-			if blockChain[i].NumberU64() != blockChain[i-1].NumberU64()+1 || blockChain[i].ParentHash() != blockChain[i-1].Hash() {
-				log.Error("Non contiguous receipt insert", "number", blockChain[i].Number(), "hash", blockChain[i].Hash(), "parent", blockChain[i].ParentHash(),
-					"prevnumber", blockChain[i-1].Number(), "prevhash", blockChain[i-1].Hash())
-				return 0, fmt.Errorf("non contiguous insert: item %d is #%d [%x..], item %d is #%d [%x..] (parent [%x..])", i-1, blockChain[i-1].NumberU64(),
-					blockChain[i-1].Hash().Bytes()[:4], i, blockChain[i].NumberU64(), blockChain[i].Hash().Bytes()[:4], blockChain[i].ParentHash().Bytes()[:4])
+			if blockChain[i].NumberU64(bc.chainConfig.Context) != blockChain[i-1].NumberU64(bc.chainConfig.Context)+1 || blockChain[i].ParentHash(bc.chainConfig.Context) != blockChain[i-1].Hash() {
+				log.Error("Non contiguous receipt insert", "number", blockChain[i].Number(bc.chainConfig.Context), "hash", blockChain[i].Hash(), "parent", blockChain[i].ParentHash(bc.chainConfig.Context),
+					"prevnumber", blockChain[i-1].Number(bc.chainConfig.Context), "prevhash", blockChain[i-1].Hash())
+				return 0, fmt.Errorf("non contiguous insert: item %d is #%d [%x..], item %d is #%d [%x..] (parent [%x..])", i-1, blockChain[i-1].NumberU64(bc.chainConfig.Context),
+					blockChain[i-1].Hash().Bytes()[:4], i, blockChain[i].NumberU64(bc.chainConfig.Context), blockChain[i].Hash().Bytes()[:4], blockChain[i].ParentHash(bc.chainConfig.Context).Bytes()[:4])
 			}
 		}
-		if blockChain[i].NumberU64() <= ancientLimit {
+		if blockChain[i].NumberU64(bc.chainConfig.Context) <= ancientLimit {
 			ancientBlocks, ancientReceipts = append(ancientBlocks, blockChain[i]), append(ancientReceipts, receiptChain[i])
 		} else {
 			liveBlocks, liveReceipts = append(liveBlocks, blockChain[i]), append(liveReceipts, receiptChain[i])
@@ -1234,7 +1348,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		defer bc.chainmu.Unlock()
 
 		// Rewind may have occurred, skip in that case.
-		if bc.CurrentHeader().Number[types.QuaiNetworkContext].Cmp(head.Number()) >= 0 {
+		if bc.CurrentHeader().Number[bc.chainConfig.Context].Cmp(head.Number(bc.chainConfig.Context)) >= 0 {
 			reorg, err := bc.forker.ReorgNeeded(bc.CurrentFastBlock().Header(), head.Header())
 			if err != nil {
 				log.Warn("Reorg failed", "err", err)
@@ -1244,7 +1358,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 			}
 			rawdb.WriteHeadFastBlockHash(bc.db, head.Hash())
 			bc.currentFastBlock.Store(head)
-			headFastBlockGauge.Update(int64(head.NumberU64()))
+			headFastBlockGauge.Update(int64(head.NumberU64(bc.chainConfig.Context)))
 			return true
 		}
 		return false
@@ -1259,11 +1373,11 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		last := blockChain[len(blockChain)-1]
 
 		// Ensure genesis is in ancients.
-		if first.NumberU64() == 1 {
+		if first.NumberU64(bc.chainConfig.Context) == 1 {
 			if frozen, _ := bc.db.Ancients(); frozen == 0 {
 				b := bc.genesisBlock
-				td := bc.genesisBlock.Difficulty()
-				writeSize, err := rawdb.WriteAncientBlocks(bc.db, []*types.Block{b}, []types.Receipts{nil}, td)
+				td := bc.genesisBlock.Difficulty(bc.chainConfig.Context)
+				writeSize, err := rawdb.WriteAncientBlocks(bc.db, []*types.Block{b}, []types.Receipts{nil}, td, bc.chainConfig.Context)
 				size += writeSize
 				if err != nil {
 					log.Error("Error writing genesis to ancients", "err", err)
@@ -1275,13 +1389,13 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		// Before writing the blocks to the ancients, we need to ensure that
 		// they correspond to the what the headerchain 'expects'.
 		// We only check the last block/header, since it's a contiguous chain.
-		if !bc.HasHeader(last.Hash(), last.NumberU64()) {
-			return 0, fmt.Errorf("containing header #%d [%x..] unknown", last.Number(), last.Hash().Bytes()[:4])
+		if !bc.HasHeader(last.Hash(), last.NumberU64(bc.chainConfig.Context)) {
+			return 0, fmt.Errorf("containing header #%d [%x..] unknown", last.Number(bc.chainConfig.Context), last.Hash().Bytes()[:4])
 		}
 
 		// Write all chain data to ancients.
-		td := bc.GetTd(first.Hash(), first.NumberU64())
-		writeSize, err := rawdb.WriteAncientBlocks(bc.db, blockChain, receiptChain, td[types.QuaiNetworkContext])
+		td := bc.GetTd(first.Hash(), first.NumberU64(bc.chainConfig.Context))
+		writeSize, err := rawdb.WriteAncientBlocks(bc.db, blockChain, receiptChain, td[bc.chainConfig.Context], bc.chainConfig.Context)
 		size += writeSize
 		if err != nil {
 			log.Error("Error importing chain data to ancients", "err", err)
@@ -1301,10 +1415,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		// generated.
 		var batch = bc.db.NewBatch()
 		for _, block := range blockChain {
-			if bc.txLookupLimit == 0 || ancientLimit <= bc.txLookupLimit || block.NumberU64() >= ancientLimit-bc.txLookupLimit {
-				rawdb.WriteTxLookupEntriesByBlock(batch, block)
+			if bc.txLookupLimit == 0 || ancientLimit <= bc.txLookupLimit || block.NumberU64(bc.chainConfig.Context) >= ancientLimit-bc.txLookupLimit {
+				rawdb.WriteTxLookupEntriesByBlock(batch, block, bc.chainConfig.Context)
 			} else if rawdb.ReadTxIndexTail(bc.db) != nil {
-				rawdb.WriteTxLookupEntriesByBlock(batch, block)
+				rawdb.WriteTxLookupEntriesByBlock(batch, block, bc.chainConfig.Context)
 			}
 			stats.processed++
 		}
@@ -1314,7 +1428,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		if err := batch.Write(); err != nil {
 			// The tx index data could not be written.
 			// Roll back the ancient store update.
-			fastBlock := bc.CurrentFastBlock().NumberU64()
+			fastBlock := bc.CurrentFastBlock().NumberU64(bc.chainConfig.Context)
 			if err := bc.db.TruncateAncients(fastBlock + 1); err != nil {
 				log.Error("Can't truncate ancient store after failed insert", "err", err)
 			}
@@ -1327,7 +1441,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		}
 
 		// Update the current fast block because all block data is now present in DB.
-		previousFastBlock := bc.CurrentFastBlock().NumberU64()
+		previousFastBlock := bc.CurrentFastBlock().NumberU64(bc.chainConfig.Context)
 		if !updateHead(blockChain[len(blockChain)-1]) {
 			// We end up here if the header chain has reorg'ed, and the blocks/receipts
 			// don't match the canonical chain.
@@ -1342,14 +1456,14 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		canonHashes := make(map[common.Hash]struct{})
 		for _, block := range blockChain {
 			canonHashes[block.Hash()] = struct{}{}
-			if block.NumberU64() == 0 {
+			if block.NumberU64(bc.chainConfig.Context) == 0 {
 				continue
 			}
-			rawdb.DeleteCanonicalHash(batch, block.NumberU64())
-			rawdb.DeleteBlockWithoutNumber(batch, block.Hash(), block.NumberU64())
+			rawdb.DeleteCanonicalHash(batch, block.NumberU64(bc.chainConfig.Context))
+			rawdb.DeleteBlockWithoutNumber(batch, block.Hash(), block.NumberU64(bc.chainConfig.Context))
 		}
 		// Delete side chain hash-to-number mappings.
-		for _, nh := range rawdb.ReadAllHashesInRange(bc.db, first.NumberU64(), last.NumberU64()) {
+		for _, nh := range rawdb.ReadAllHashesInRange(bc.db, first.NumberU64(bc.chainConfig.Context), last.NumberU64(bc.chainConfig.Context)) {
 			if _, canon := canonHashes[nh.Hash]; !canon {
 				rawdb.DeleteHeader(batch, nh.Hash, nh.Number)
 			}
@@ -1370,12 +1484,12 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 				return 0, errInsertionInterrupted
 			}
 			// Short circuit if the owner header is unknown
-			if !bc.HasHeader(block.Hash(), block.NumberU64()) {
-				return i, fmt.Errorf("containing header #%d [%x..] unknown", block.Number(), block.Hash().Bytes()[:4])
+			if !bc.HasHeader(block.Hash(), block.NumberU64(bc.chainConfig.Context)) {
+				return i, fmt.Errorf("containing header #%d [%x..] unknown", block.Number(bc.chainConfig.Context), block.Hash().Bytes()[:4])
 			}
 			if !skipPresenceCheck {
 				// Ignore if the entire data is already known
-				if bc.HasBlock(block.Hash(), block.NumberU64()) {
+				if bc.HasBlock(block.Hash(), block.NumberU64(bc.chainConfig.Context)) {
 					stats.ignored++
 					continue
 				} else {
@@ -1386,9 +1500,9 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 				}
 			}
 			// Write all the data out into the database
-			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
-			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receiptChain[i])
-			rawdb.WriteTxLookupEntriesByBlock(batch, block) // Always write tx indices for live blocks, we assume they are needed
+			rawdb.WriteBody(batch, block.Hash(), block.NumberU64(bc.chainConfig.Context), block.Body())
+			rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(bc.chainConfig.Context), receiptChain[i])
+			rawdb.WriteTxLookupEntriesByBlock(batch, block, bc.chainConfig.Context) // Always write tx indices for live blocks, we assume they are needed
 
 			// Write everything belongs to the blocks into the database. So that
 			// we can ensure all components of body is completed(body, receipts,
@@ -1425,7 +1539,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		}
 	}
 	// Write the tx index tail (block number from where we index) before write any live blocks
-	if len(liveBlocks) > 0 && liveBlocks[0].NumberU64() == ancientLimit+1 {
+	if len(liveBlocks) > 0 && liveBlocks[0].NumberU64(bc.chainConfig.Context) == ancientLimit+1 {
 		// The tx index tail can only be one of the following two options:
 		// * 0: all ancient blocks have been indexed
 		// * ancient-limit: the indices of blocks before ancient-limit are ignored
@@ -1449,7 +1563,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 	head := blockChain[len(blockChain)-1]
 	context := []interface{}{
 		"count", stats.processed, "elapsed", common.PrettyDuration(time.Since(start)),
-		"number", head.Number(), "hash", head.Hash(), "age", common.PrettyAge(time.Unix(int64(head.Time()), 0)),
+		"number", head.Number(bc.chainConfig.Context), "hash", head.Hash(), "age", common.PrettyAge(time.Unix(int64(head.Time()), 0)),
 		"size", common.StorageSize(size),
 	}
 	if stats.ignored > 0 {
@@ -1482,8 +1596,8 @@ func (bc *BlockChain) writeBlockWithoutState(block *types.Block, td []*big.Int)
 	defer bc.wg.Done()
 
 	batch := bc.db.NewBatch()
-	rawdb.WriteTd(batch, block.Hash(), block.NumberU64(), td)
-	rawdb.WriteBlock(batch, block)
+	rawdb.WriteTd(batch, block.Hash(), block.NumberU64(bc.chainConfig.Context), td)
+	rawdb.WriteBlock(batch, block, bc.chainConfig.Context)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
@@ -1497,7 +1611,7 @@ func (bc *BlockChain) writeKnownBlock(block *types.Block) error {
 	defer bc.wg.Done()
 
 	current := bc.CurrentBlock()
-	if block.ParentHash() != current.Hash() {
+	if block.ParentHash(bc.chainConfig.Context) != current.Hash() {
 		if err := bc.reorg(current, block); err != nil {
 			return err
 		}
@@ -1598,15 +1712,15 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	// Note all the components of block(td, hash->number map, header, body, receipts)
 	// should be written atomically. BlockBatch is used for containing all components.
 	blockBatch := bc.db.NewBatch()
-	rawdb.WriteTd(blockBatch, block.Hash(), block.NumberU64(), externTd)
-	rawdb.WriteBlock(blockBatch, block)
-	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(), receipts)
+	rawdb.WriteTd(blockBatch, block.Hash(), block.NumberU64(bc.chainConfig.Context), externTd)
+	rawdb.WriteBlock(blockBatch, block, bc.chainConfig.Context)
+	rawdb.WriteReceipts(blockBatch, block.Hash(), block.NumberU64(bc.chainConfig.Context), receipts)
 	rawdb.WritePreimages(blockBatch, state.Preimages())
 	if err := blockBatch.Write(); err != nil {
 		log.Crit("Failed to write block into disk", "err", err)
 	}
 	// Commit all cached state changes into underlying memory database.
-	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number()))
+	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number(bc.chainConfig.Context)))
 	if err != nil {
 		return err
 	}
@@ -1620,9 +1734,9 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	} else {
 		// Full but not archive node, do proper garbage collection
 		triedb.Reference(root, common.Hash{}) // metadata reference to keep trie alive
-		bc.triegc.Push(root, -int64(block.NumberU64()))
+		bc.triegc.Push(root, -int64(block.NumberU64(bc.chainConfig.Context)))
 
-		if current := block.NumberU64(); current > TriesInMemory {
+		if current := block.NumberU64(bc.chainConfig.Context); current > TriesInMemory {
 			// If we exceeded our memory allowance, flush matured singleton nodes to disk
 			var (
 				nodes, imgs = triedb.Size()
@@ -1648,7 +1762,7 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 						log.Info("State in memory for too long, committing", "time", bc.gcproc, "allowance", bc.cacheConfig.TrieTimeLimit, "optimum", float64(chosen-lastWrite)/TriesInMemory)
 					}
 					// Flush an entire trie and restart the counters
-					triedb.Commit(header.Root[types.QuaiNetworkContext], true, nil)
+					triedb.Commit(header.Root[bc.chainConfig.Context], true, nil)
 					lastWrite = chosen
 					bc.gcproc = 0
 				}
@@ -1693,7 +1807,7 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 	// Please refer to http://www.cs.cornell.edu/~ie53/publications/btcProcFC.pdf
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
-		if block.ParentHash() != currentBlock.Hash() {
+		if block.ParentHash(bc.chainConfig.Context) != currentBlock.Hash() {
 			if err := bc.reorg(currentBlock, block); err != nil {
 				return NonStatTy, err
 			}
@@ -1746,7 +1860,7 @@ func (bc *BlockChain) addFutureBlock(block *types.Block) error {
 
 // GetBlockStatus returns the status of the block for a given header
 func (bc *BlockChain) GetBlockStatus(header *types.Header) WriteStatus {
-	canonHash := bc.GetCanonicalHash(header.Number[types.QuaiNetworkContext].Uint64())
+	canonHash := bc.GetCanonicalHash(header.Number[bc.chainConfig.Context].Uint64())
 	if (canonHash == common.Hash{}) {
 		return UnknownStatTy
 	}
@@ -1767,24 +1881,101 @@ func (bc *BlockChain) AddExternalBlocks(blocks []*types.ExternalBlock) error {
 	return nil
 }
 
-// addExternalBlock adds the received block to the external block cache.
+// AddExternalBlock stores the received block in the external block table,
+// retained under the current head until a block links it.
 func (bc *BlockChain) AddExternalBlock(block *types.ExternalBlock) error {
 	context := []interface{}{
 		"context", block.Context(), "numbers", block.Header().Number, "hash", block.Hash(), "location", block.Header().Location,
 		"txs", len(block.Transactions()), "uncles", len(block.Uncles()), "receipts", len(block.Receipts()),
 	}
 	log.Debug("Adding external block", context...)
+	bc.writeExternalBlock(block, nil)
+	bc.trackAvailableETxs(block)
+	return nil
+}
+
+// writeExternalBlock stores an external block along with its retention index
+// and caches it. Every block linking the external block is indexed and retains
+// it, otherwise a newly stored external block is retained under the current
+// head.
+func (bc *BlockChain) writeExternalBlock(block *types.ExternalBlock, coincident *types.Header) {
 	data, err := rlp.EncodeToBytes(block)
 	if err != nil {
 		log.Crit("Failed to RLP encode external block", "err", err)
 	}
 	bc.externalBlocks.Set(block.CacheKey(), data)
-	return nil
+
+	bc.externalBlockLock.Lock()
+	defer bc.externalBlockLock.Unlock()
+
+	var (
+		context = block.Context().Uint64()
+		hash    = block.Hash()
+	)
+	if coincident == nil {
+		if rawdb.ReadExternalBlockIndex(bc.db, hash, context) != nil {
+			return
+		}
+		rawdb.WriteExternalBlock(bc.db, block)
+		rawdb.WriteExternalBlockIndex(bc.db, hash, context, bc.CurrentHeader().Number[bc.chainConfig.Context].Uint64(), common.Hash{})
+		return
+	}
+	rawdb.WriteExternalBlock(bc.db, block)
+	rawdb.WriteExternalBlockIndex(bc.db, hash, context, coincident.Number[bc.chainConfig.Context].Uint64(), coincident.Hash())
+}
+
+// pruneExternalBlocks deletes the external blocks retained under blocks more
+// than the retention limit below the given head.
+func (bc *BlockChain) pruneExternalBlocks(head *types.Header) {
+	retention := bc.cacheConfig.ExternalBlockRetention
+	number := head.Number[bc.chainConfig.Context].Uint64()
+	if retention == 0 || number <= retention {
+		return
+	}
+	bc.externalBlockLock.Lock()
+	pruned := rawdb.PruneExternalBlocks(bc.db, number-retention)
+	bc.externalBlockLock.Unlock()
+
+	if pruned > 0 {
+		extBlockPruneMeter.Mark(int64(pruned))
+		log.Debug("Pruned external blocks", "count", pruned, "limit", number-retention)
+	}
+}
+
+// ExternalBlockCacheStats is a snapshot of the external block cache.
+type ExternalBlockCacheStats struct {
+	Entries uint64 // Number of external blocks held in memory
+	Bytes   uint64 // Memory used by the external blocks held
+	Queued  int    // Number of external blocks queued for linking
+	Hits    int64  // Lookups served by the cache or the database
+	Misses  int64  // Lookups fetched from the dominant or subordinate chains
+	Pruned  int64  // External blocks deleted once out of retention
+}
+
+// ExternalBlockCacheStats returns a snapshot of the external block cache.
+func (bc *BlockChain) ExternalBlockCacheStats() ExternalBlockCacheStats {
+	var stats fastcache.Stats
+	bc.externalBlocks.UpdateStats(&stats)
+
+	return ExternalBlockCacheStats{
+		Entries: stats.EntriesCount,
+		Bytes:   stats.BytesSize,
+		Queued:  bc.externalBlockQueue.Len(),
+		Hits:    extBlockHitMeter.Count(),
+		Misses:  extBlockMissMeter.Count(),
+		Pruned:  extBlockPruneMeter.Count(),
+	}
 }
 
 // ReOrgRollBack compares the difficulty of the newchain and oldchain. Rolls back
 // the current header to the position where the reorg took place in a higher context
 func (bc *BlockChain) ReOrgRollBack(header *types.Header, validHeaders []*types.Header, invalidHeaders []*types.Header) error {
+	return bc.rollBack(header, header)
+}
+
+// rollBack rolls the chain back to the parent of header, journaling trigger as
+// the header whose reorg forced the rollback.
+func (bc *BlockChain) rollBack(header *types.Header, trigger *types.Header) error {
 	log.Info("Rolling back header beyond", "hash", header.Hash(), "from", bc.CurrentBlock().Header().Hash())
 	// bc.reorgmu.Lock()
 	// defer bc.reorgmu.Unlock()
@@ -1832,7 +2023,7 @@ func (bc *BlockChain) ReOrgRollBack(header *types.Header, validHeaders []*types.
 
 	if header != nil {
 		// get the commonBlock
-		commonBlock := bc.GetBlockByHash(header.ParentHash[types.QuaiNetworkContext])
+		commonBlock := bc.GetBlockByHash(header.ParentHash[bc.chainConfig.Context])
 
 		// if commonBlock isn't canoncial in our chain, do not reorg
 		// because commonBlock parentHash could potentially be in our chain.
@@ -1842,6 +2033,7 @@ func (bc *BlockChain) ReOrgRollBack(header *types.Header, validHeaders []*types.
 			return nil
 		}
 		// get the current head in this chain
+		var dropped types.Blocks
 		currentBlock := bc.CurrentBlock()
 		for {
 			deletedTxs = append(deletedTxs, currentBlock.Transactions()...)
@@ -1849,15 +2041,19 @@ func (bc *BlockChain) ReOrgRollBack(header *types.Header, validHeaders []*types.
 			if currentBlock.Hash() == commonBlock.Hash() {
 				break
 			}
+			dropped = append(dropped, currentBlock)
 
-			currentBlock = bc.GetBlock(currentBlock.ParentHash(), currentBlock.NumberU64()-1)
+			currentBlock = bc.GetBlock(currentBlock.ParentHash(bc.chainConfig.Context), currentBlock.NumberU64(bc.chainConfig.Context)-1)
 			if currentBlock == nil {
 				return fmt.Errorf("invalid current chain")
 			}
 		}
 
+		// describe the rollback while the dropped blocks are still around
+		journal := bc.prepareReorg(types.ReorgRollback, trigger, commonBlock, dropped, nil)
+
 		// set the head back to the block before the rollback point
-		if err := bc.SetHead(commonBlock.NumberU64()); err != nil {
+		if err := bc.SetHead(commonBlock.NumberU64(bc.chainConfig.Context)); err != nil {
 			return err
 		}
 		// writing the head to the blockchain state
@@ -1875,7 +2071,7 @@ func (bc *BlockChain) ReOrgRollBack(header *types.Header, validHeaders []*types.
 		bc.chainFeed.Send(ChainEvent{Block: commonBlock, Hash: commonBlock.Hash(), Logs: logs})
 		bc.chainHeadFeed.Send(ChainHeadEvent{Block: commonBlock})
 
-		log.Info("Header is now rolled back and the current head is at block with ", "Hash ", bc.CurrentBlock().Hash(), " Number ", bc.CurrentBlock().NumberU64())
+		log.Info("Header is now rolled back and the current head is at block with ", "Hash ", bc.CurrentBlock().Hash(), " Number ", bc.CurrentBlock().NumberU64(bc.chainConfig.Context))
 
 		// Delete useless indexes right now which includes the non-canonical
 		// transaction indexes, canonical chain indexes which above the head.
@@ -1885,7 +2081,7 @@ func (bc *BlockChain) ReOrgRollBack(header *types.Header, validHeaders []*types.
 		}
 
 		// Delete any canonical number assignments above the new head
-		number := bc.CurrentBlock().NumberU64()
+		number := bc.CurrentBlock().NumberU64(bc.chainConfig.Context)
 		for i := number + 1; ; i++ {
 			hash := rawdb.ReadCanonicalHash(bc.db, i)
 			if hash == (common.Hash{}) {
@@ -1901,6 +2097,7 @@ func (bc *BlockChain) ReOrgRollBack(header *types.Header, validHeaders []*types.
 		if len(deletedLogs) > 0 {
 			bc.rmLogsFeed.Send(RemovedLogsEvent{mergeLogs(deletedLogs, true)})
 		}
+		bc.journalReorg(journal)
 	} else {
 		return fmt.Errorf("reorg header was null")
 	}
@@ -1921,24 +2118,10 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	bc.blockProcFeed.Send(true)
 	defer bc.blockProcFeed.Send(false)
 
-	// Remove already known canon-blocks
-	var (
-		block, prev *types.Block
-	)
 	// Do a sanity check that the provided chain is actually ordered and linked
-	for i := 1; i < len(chain); i++ {
-		block = chain[i]
-		prev = chain[i-1]
-		if block.NumberU64() != prev.NumberU64()+1 || block.ParentHash() != prev.Hash() {
-			// Chain broke ancestry, log a message (programming error) and skip insertion
-			log.Error("Non contiguous block insert", "number", block.Number(), "hash", block.Hash(),
-				"parent", block.ParentHash(), "prevnumber", prev.Number(), "prevhash", prev.Hash())
-
-			return 0, fmt.Errorf("non contiguous insert: item %d is #%d [%x..], item %d is #%d [%x..] (parent [%x..])", i-1, prev.NumberU64(),
-				prev.Hash().Bytes()[:4], i, block.NumberU64(), block.Hash().Bytes()[:4], block.ParentHash().Bytes()[:4])
-		}
+	if err := bc.checkContiguous(chain); err != nil {
+		return 0, err
 	}
-
 	// Pre-checks passed, start the full block imports
 	bc.reorgmu.Lock()
 	bc.chainmu.Lock()
@@ -1949,6 +2132,27 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 	return n, err
 }
 
+// checkContiguous does a sanity check that the provided chain is actually
+// ordered and linked.
+func (bc *BlockChain) checkContiguous(chain types.Blocks) error {
+	var (
+		block, prev *types.Block
+	)
+	for i := 1; i < len(chain); i++ {
+		block = chain[i]
+		prev = chain[i-1]
+		if block.NumberU64(bc.chainConfig.Context) != prev.NumberU64(bc.chainConfig.Context)+1 || block.ParentHash(bc.chainConfig.Context) != prev.Hash() {
+			// Chain broke ancestry, log a message (programming error) and skip insertion
+			log.Error("Non contiguous block insert", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash(),
+				"parent", block.ParentHash(bc.chainConfig.Context), "prevnumber", prev.Number(bc.chainConfig.Context), "prevhash", prev.Hash())
+
+			return fmt.Errorf("non contiguous insert: item %d is #%d [%x..], item %d is #%d [%x..] (parent [%x..])", i-1, prev.NumberU64(bc.chainConfig.Context),
+				prev.Hash().Bytes()[:4], i, block.NumberU64(bc.chainConfig.Context), block.Hash().Bytes()[:4], block.ParentHash(bc.chainConfig.Context).Bytes()[:4])
+		}
+	}
+	return nil
+}
+
 // InsertChainWithoutSealVerification works exactly the same
 // except for seal verification, seal verification is omitted
 func (bc *BlockChain) InsertChainWithoutSealVerification(block *types.Block) (int, error) {
@@ -1979,10 +2183,10 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 		return 0, nil
 	}
 	// Start a parallel signature recovery (signer will fluke on fork transition, minimal perf loss)
-	senderCacher.recoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].Number()), chain)
+	senderCacher.recoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].Number(bc.chainConfig.Context)), chain)
 
 	var (
-		stats     = insertStats{startTime: mclock.Now()}
+		stats     = insertStats{startTime: mclock.Now(), context: bc.chainConfig.Context}
 		lastCanon *types.Block
 	)
 	// Fire a single chain head event if we've progressed the chain
@@ -2030,12 +2234,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 				// In eth2 the forker always returns true for reorg decision (blindly trusting
 				// the external consensus engine), but in order to prevent the unnecessary
 				// reorgs when importing known blocks, the special case is handled here.
-				if block.NumberU64() > current.NumberU64() || bc.GetCanonicalHash(block.NumberU64()) != block.Hash() {
+				if block.NumberU64(bc.chainConfig.Context) > current.NumberU64(bc.chainConfig.Context) || bc.GetCanonicalHash(block.NumberU64(bc.chainConfig.Context)) != block.Hash() {
 					fmt.Println("skipblock reorg is true going to break because non-canonical?")
 					break
 				}
 			}
-			log.Debug("Ignoring already known block", "number", block.Number(), "hash", block.Hash())
+			log.Debug("Ignoring already known block", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash())
 			stats.ignored++
 
 			block, err = it.next()
@@ -2049,7 +2253,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 		// `insertChain` while a part of them have higher total difficulty than current
 		// head full block(new pivot point).
 		for block != nil && bc.skipBlock(err, it) {
-			log.Debug("Writing previously known block", "number", block.Number(), "hash", block.Hash())
+			log.Debug("Writing previously known block", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash())
 			if err := bc.writeKnownBlock(block); err != nil {
 				return it.index, err
 			}
@@ -2062,13 +2266,13 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 	switch {
 	// First block is pruned, insert as sidechain and reorg only if TD grows enough
 	case errors.Is(err, consensus.ErrPrunedAncestor):
-		log.Debug("Pruned ancestor, inserting as sidechain", "number", block.Number(), "hash", block.Hash())
+		log.Debug("Pruned ancestor, inserting as sidechain", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash())
 		return bc.insertSideChain(block, it)
 
 	// First block is future, shove it (and all children) to the future queue (unknown ancestor)
 	case errors.Is(err, consensus.ErrFutureBlock) || (errors.Is(err, consensus.ErrUnknownAncestor)):
 		for block != nil && (it.index == 0 || errors.Is(err, consensus.ErrUnknownAncestor)) {
-			log.Debug("Future block, postponing import", "number", block.Number(), "hash", block.Hash())
+			log.Debug("Future block, postponing import", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash())
 			if err := bc.addFutureBlock(block); err != nil {
 				return it.index, err
 			}
@@ -2121,9 +2325,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 			if bc.chainConfig.Clique == nil {
 				logger = log.Warn
 			}
-			logger("Inserted known block", "number", block.Number(), "hash", block.Hash(),
-				"uncles", len(block.Uncles()), "txs", len(block.Transactions()), "gas", block.GasUsed(),
-				"root", block.Root())
+			logger("Inserted known block", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash(),
+				"uncles", len(block.Uncles()), "txs", len(block.Transactions()), "gas", block.GasUsed(bc.chainConfig.Context),
+				"root", block.Root(bc.chainConfig.Context))
 
 			// Special case. Commit the empty receipt slice if we meet the known
 			// block in the middle. It can only happen in the clique chain. Whenever
@@ -2134,10 +2338,10 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 			// state, but if it's this special case here(skip reexecution) we will lose
 			// the empty receipt entry.
 			if len(block.Transactions()) == 0 {
-				rawdb.WriteReceipts(bc.db, block.Hash(), block.NumberU64(), nil)
+				rawdb.WriteReceipts(bc.db, block.Hash(), block.NumberU64(bc.chainConfig.Context), nil)
 			} else {
 				log.Error("Please file an issue, skip known block execution without receipt",
-					"hash", block.Hash(), "number", block.NumberU64())
+					"hash", block.Hash(), "number", block.NumberU64(bc.chainConfig.Context))
 			}
 			if err := bc.writeKnownBlock(block); err != nil {
 				return it.index, err
@@ -2155,14 +2359,14 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 
 		parent := it.previous()
 		if parent == nil {
-			parentBlock := bc.GetBlockByHash(block.ParentHash())
+			parentBlock := bc.GetBlockByHash(block.ParentHash(bc.chainConfig.Context))
 			if parentBlock == nil {
 				return it.index, errors.New("parent is nil")
 			}
 			parent = parentBlock.Header()
 		}
 
-		statedb, err := state.New(parent.Root[types.QuaiNetworkContext], bc.stateCache, bc.snaps)
+		statedb, err := state.New(parent.Root[bc.chainConfig.Context], bc.stateCache, bc.snaps)
 		if err != nil {
 			return it.index, err
 		}
@@ -2175,7 +2379,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 		var followupInterrupt uint32
 		if !bc.cacheConfig.TrieCleanNoPrefetch {
 			if followup, err := it.peek(); followup != nil && err == nil {
-				throwaway, _ := state.New(parent.Root[types.QuaiNetworkContext], bc.stateCache, bc.snaps)
+				throwaway, _ := state.New(parent.Root[bc.chainConfig.Context], bc.stateCache, bc.snaps)
 
 				go func(start time.Time, followup *types.Block, throwaway *state.StateDB, interrupt *uint32) {
 					bc.prefetcher.Prefetch(followup, throwaway, bc.vmConfig, &followupInterrupt)
@@ -2204,7 +2408,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 			return it.index, err
 		}
 
-		if order < types.QuaiNetworkContext {
+		if order < bc.chainConfig.Context {
 			err := bc.CheckDominantBlock(block)
 			if err != nil {
 				return it.index, err
@@ -2218,15 +2422,19 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 
 		log.Info("Running CheckCanonical and PCRC for block", "num", block.Header().Number, "location", block.Header().Location, "hash", block.Header().Hash())
 
-		if order < types.QuaiNetworkContext {
-			status := bc.domClient.GetBlockStatus(context.Background(), block.Header())
+		if order < bc.chainConfig.Context {
+			status := bc.domBlockStatus(block.Header())
 			// If the header is cononical break else keep looking
-			if status != quaiclient.CanonStatTy {
+			if status != CanonStatTy {
 				return it.index, errors.New("cannot append non-canonical dom block in sub")
 			}
 		}
 
-		_, err = bc.PCRC(block.Header(), order)
+		if bc.cacheConfig.PCRCDepth > 0 {
+			_, err = bc.VerifyPCRC(block.Header(), bc.cacheConfig.PCRCDepth)
+		} else {
+			_, err = bc.PCRC(block.Header(), order)
+		}
 		fmt.Println("PCRC", err)
 		if err != nil {
 			return it.index, nil
@@ -2282,6 +2490,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 			bc.chainUncleFeed.Send(block.Header())
 			return it.index, err
 		}
+		bc.recordSupply(block, receipts, externalBlocks)
 
 		// Update the metrics touched during block commit
 		accountCommitTimer.Update(statedb.AccountCommits)   // Account commits are complete, we can mark them
@@ -2293,17 +2502,20 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 
 		if !setHead {
 			// We did not setHead, so we don't have any stats to update
-			log.Info("Inserted block", "number", block.Number(), "hash", block.Hash(), "txs", len(block.Transactions()), "elapsed", common.PrettyDuration(time.Since(start)))
+			log.Info("Inserted block", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash(), "txs", len(block.Transactions()), "elapsed", common.PrettyDuration(time.Since(start)))
 			return it.index, nil
 		}
 
 		switch status {
 		case CanonStatTy:
-			bc.StoreExternalBlocks(linkExtBlocks)
+			bc.StoreExternalBlocks(block.Header(), linkExtBlocks)
+			bc.pruneExternalBlocks(block.Header())
+			bc.trackIncludedETxs(block)
+			bc.trackAppliedETxs(block, receipts, externalBlocks)
 			log.Info("Inserted new block", "number", block.Header().Number, "hash", block.Hash(), "loc", block.Header().Location, "extBlocks", len(externalBlocks),
-				"uncles", len(block.Uncles()), "txs", len(block.Transactions()), "gas", block.GasUsed(),
+				"uncles", len(block.Uncles()), "txs", len(block.Transactions()), "gas", block.GasUsed(bc.chainConfig.Context),
 				"elapsed", common.PrettyDuration(time.Since(start)),
-				"root", block.Root())
+				"root", block.Root(bc.chainConfig.Context))
 
 			lastCanon = block
 
@@ -2311,18 +2523,18 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 			bc.gcproc += proctime
 
 		case SideStatTy:
-			log.Info("Inserted forked block", "number", block.Number(), "hash", block.Hash(),
-				"diff", block.Difficulty(), "elapsed", common.PrettyDuration(time.Since(start)),
-				"txs", len(block.Transactions()), "gas", block.GasUsed(), "uncles", len(block.Uncles()),
-				"root", block.Root())
+			log.Info("Inserted forked block", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash(),
+				"diff", block.Difficulty(bc.chainConfig.Context), "elapsed", common.PrettyDuration(time.Since(start)),
+				"txs", len(block.Transactions()), "gas", block.GasUsed(bc.chainConfig.Context), "uncles", len(block.Uncles()),
+				"root", block.Root(bc.chainConfig.Context))
 
 		default:
 			// This in theory is impossible, but lets be nice to our future selves and leave
 			// a log, instead of trying to track down blocks imports that don't emit logs.
-			log.Info("Inserted block with unknown status", "number", block.Number(), "hash", block.Hash(),
-				"diff", block.Difficulty(), "elapsed", common.PrettyDuration(time.Since(start)),
-				"txs", len(block.Transactions()), "gas", block.GasUsed(), "uncles", len(block.Uncles()),
-				"root", block.Root())
+			log.Info("Inserted block with unknown status", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash(),
+				"diff", block.Difficulty(bc.chainConfig.Context), "elapsed", common.PrettyDuration(time.Since(start)),
+				"txs", len(block.Transactions()), "gas", block.GasUsed(bc.chainConfig.Context), "uncles", len(block.Uncles()),
+				"root", block.Root(bc.chainConfig.Context))
 		}
 		stats.processed++
 		stats.usedGas += usedGas
@@ -2350,7 +2562,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool, setHead
 }
 
 func (bc *BlockChain) DomReorgNeeded(header *types.Header) (bool, error) {
-	terminalHeader, err := bc.PreviousCanonicalCoincidentOnPath(header, header.Location, types.QuaiNetworkContext-1, types.QuaiNetworkContext, true)
+	terminalHeader, err := bc.PreviousCanonicalCoincidentOnPath(header, header.Location, bc.chainConfig.Context-1, bc.chainConfig.Context, true)
 
 	if err != nil {
 		// Send HLCRReorg to dom
@@ -2381,9 +2593,9 @@ func (bc *BlockChain) HLCRReorg(block *types.Block) (bool, error) {
 		return false, errors.New("block provided in hlcrreorg is nil")
 	}
 
-	fmt.Println("HLCRReorg", block.Header().Hash(), " context ", types.QuaiNetworkContext)
+	fmt.Println("HLCRReorg", block.Header().Hash(), " context ", bc.chainConfig.Context)
 
-	fmt.Println("starting reorgrollback, context", types.QuaiNetworkContext)
+	fmt.Println("starting reorgrollback, context", bc.chainConfig.Context)
 
 	order, err := bc.engine.GetDifficultyOrder(block.Header())
 	if err != nil {
@@ -2391,10 +2603,14 @@ func (bc *BlockChain) HLCRReorg(block *types.Block) (bool, error) {
 	}
 
 	var reorgFromDom bool
-	if order < types.QuaiNetworkContext {
-		reorgFromDom, err = bc.domClient.HLCRReorg(context.Background(), block)
+	if order < bc.chainConfig.Context {
+		domLink := bc.getDomLink()
+		if domLink == nil {
+			return false, errors.New("dom client is nil")
+		}
+		reorgFromDom, err = domLink.HLCRReorg(context.Background(), block)
 		if err != nil {
-			fmt.Println("hlcrreorg dom reorg failed, context", types.QuaiNetworkContext)
+			fmt.Println("hlcrreorg dom reorg failed, context", bc.chainConfig.Context)
 			return false, errors.New("unable to reorg the dom")
 		}
 	} else {
@@ -2404,14 +2620,14 @@ func (bc *BlockChain) HLCRReorg(block *types.Block) (bool, error) {
 		if err != nil {
 			return false, err
 		}
-		reorgFromDom = externTd[types.QuaiNetworkContext].Cmp(currentTd[types.QuaiNetworkContext]) >= 0
+		reorgFromDom = externTd[bc.chainConfig.Context].Cmp(currentTd[bc.chainConfig.Context]) >= 0
 	}
 
 	if !reorgFromDom {
 		return false, nil
 	}
 
-	err = bc.ReOrgRollBack(bc.CurrentBlock().Header(), []*types.Header{}, []*types.Header{})
+	err = bc.rollBack(bc.CurrentBlock().Header(), block.Header())
 	if err != nil {
 		return false, err
 	}
@@ -2443,7 +2659,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 	err := consensus.ErrPrunedAncestor
 	for ; block != nil && errors.Is(err, consensus.ErrPrunedAncestor); block, err = it.next() {
 		// Check the canonical state root for that number
-		if number := block.NumberU64(); current.NumberU64() >= number {
+		if number := block.NumberU64(bc.chainConfig.Context); current.NumberU64(bc.chainConfig.Context) >= number {
 			canonical := bc.GetBlockByNumber(number)
 			if canonical != nil && canonical.Hash() == block.Hash() {
 				// Not a sidechain block, this is a re-import of a canon block which has it's state pruned
@@ -2451,10 +2667,10 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 				// Collect the TD of the block. Since we know it's a canon one,
 				// we can get it directly, and not (like further below) use
 				// the parent and then add the block on top
-				externTd = bc.GetTd(block.Hash(), block.NumberU64())
+				externTd = bc.GetTd(block.Hash(), block.NumberU64(bc.chainConfig.Context))
 				continue
 			}
-			if canonical != nil && canonical.Root() == block.Root() {
+			if canonical != nil && canonical.Root(bc.chainConfig.Context) == block.Root(bc.chainConfig.Context) {
 				// This is most likely a shadow-state attack. When a fork is imported into the
 				// database, and it eventually reaches a block height which is not pruned, we
 				// just found that the state already exist! This means that the sidechain block
@@ -2462,7 +2678,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 				//
 				// If left unchecked, we would now proceed importing the blocks, without actually
 				// having verified the state of the previous blocks.
-				log.Warn("Sidechain ghost-state attack detected", "number", block.NumberU64(), "sideroot", block.Root(), "canonroot", canonical.Root())
+				log.Warn("Sidechain ghost-state attack detected", "number", block.NumberU64(bc.chainConfig.Context), "sideroot", block.Root(bc.chainConfig.Context), "canonroot", canonical.Root(bc.chainConfig.Context))
 
 				// If someone legitimately side-mines blocks, they would still be imported as usual. However,
 				// we cannot risk writing unverified blocks to disk when they obviously target the pruning
@@ -2478,15 +2694,15 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 			}
 		}
 
-		if !bc.HasBlock(block.Hash(), block.NumberU64()) {
+		if !bc.HasBlock(block.Hash(), block.NumberU64(bc.chainConfig.Context)) {
 			start := time.Now()
 			if err := bc.writeBlockWithoutState(block, externTd); err != nil {
 				return it.index, err
 			}
-			log.Debug("Injected sidechain block", "number", block.Number(), "hash", block.Hash(),
-				"diff", block.Difficulty(), "elapsed", common.PrettyDuration(time.Since(start)),
-				"txs", len(block.Transactions()), "gas", block.GasUsed(), "uncles", len(block.Uncles()),
-				"root", block.Root())
+			log.Debug("Injected sidechain block", "number", block.Number(bc.chainConfig.Context), "hash", block.Hash(),
+				"diff", block.Difficulty(bc.chainConfig.Context), "elapsed", common.PrettyDuration(time.Since(start)),
+				"txs", len(block.Transactions()), "gas", block.GasUsed(bc.chainConfig.Context), "uncles", len(block.Uncles()),
+				"root", block.Root(bc.chainConfig.Context))
 		}
 		lastBlock = block
 	}
@@ -2502,8 +2718,8 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 		return it.index, err
 	}
 	if !reorg {
-		localTd := bc.GetTd(current.Hash(), current.NumberU64())
-		log.Info("Sidechain written to disk", "start", it.first().NumberU64(), "end", it.previous().Number, "sidetd", externTd, "localtd", localTd)
+		localTd := bc.GetTd(current.Hash(), current.NumberU64(bc.chainConfig.Context))
+		log.Info("Sidechain written to disk", "start", it.first().NumberU64(bc.chainConfig.Context), "end", it.previous().Number, "sidetd", externTd, "localtd", localTd)
 		return it.index, err
 	}
 	// Gather all the sidechain hashes (full blocks may be memory heavy)
@@ -2512,11 +2728,11 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 		numbers []uint64
 	)
 	parent := it.previous()
-	for parent != nil && !bc.HasState(parent.Root[types.QuaiNetworkContext]) {
+	for parent != nil && !bc.HasState(parent.Root[bc.chainConfig.Context]) {
 		hashes = append(hashes, parent.Hash())
-		numbers = append(numbers, parent.Number[types.QuaiNetworkContext].Uint64())
+		numbers = append(numbers, parent.Number[bc.chainConfig.Context].Uint64())
 
-		parent = bc.GetHeader(parent.ParentHash[types.QuaiNetworkContext], parent.Number[types.QuaiNetworkContext].Uint64()-1)
+		parent = bc.GetHeader(parent.ParentHash[bc.chainConfig.Context], parent.Number[bc.chainConfig.Context].Uint64()-1)
 	}
 	if parent == nil {
 		return it.index, errors.New("missing parent")
@@ -2537,7 +2753,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 		// all raised events and logs from notifications since we're too heavy on the
 		// memory here.
 		if len(blocks) >= 2048 || memory > 64*1024*1024 {
-			log.Info("Importing heavy sidechain segment", "blocks", len(blocks), "start", blocks[0].NumberU64(), "end", block.NumberU64())
+			log.Info("Importing heavy sidechain segment", "blocks", len(blocks), "start", blocks[0].NumberU64(bc.chainConfig.Context), "end", block.NumberU64(bc.chainConfig.Context))
 			if _, err := bc.insertChain(blocks, false, true); err != nil {
 				return 0, err
 			}
@@ -2551,7 +2767,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 		}
 	}
 	if len(blocks) > 0 {
-		log.Info("Importing sidechain segment", "start", blocks[0].NumberU64(), "end", blocks[len(blocks)-1].NumberU64())
+		log.Info("Importing sidechain segment", "start", blocks[0].NumberU64(bc.chainConfig.Context), "end", blocks[len(blocks)-1].NumberU64(bc.chainConfig.Context))
 		return bc.insertChain(blocks, false, true)
 	}
 	return 0, nil
@@ -2623,9 +2839,9 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	)
 
 	// Reduce the longer chain to the same number as the shorter one
-	if oldBlock.NumberU64() > newBlock.NumberU64() {
+	if oldBlock.NumberU64(bc.chainConfig.Context) > newBlock.NumberU64(bc.chainConfig.Context) {
 		// Old chain is longer, gather all transactions and logs as deleted ones
-		for ; oldBlock != nil && oldBlock.NumberU64() != newBlock.NumberU64(); oldBlock = bc.GetBlock(oldBlock.ParentHash(), oldBlock.NumberU64()-1) {
+		for ; oldBlock != nil && oldBlock.NumberU64(bc.chainConfig.Context) != newBlock.NumberU64(bc.chainConfig.Context); oldBlock = bc.GetBlock(oldBlock.ParentHash(bc.chainConfig.Context), oldBlock.NumberU64(bc.chainConfig.Context)-1) {
 			oldChain = append(oldChain, oldBlock)
 			deletedTxs = append(deletedTxs, oldBlock.Transactions()...)
 
@@ -2637,7 +2853,7 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		}
 	} else {
 		// New chain is longer, stash all blocks away for subsequent insertion
-		for ; newBlock != nil && newBlock.NumberU64() != oldBlock.NumberU64(); newBlock = bc.GetBlock(newBlock.ParentHash(), newBlock.NumberU64()-1) {
+		for ; newBlock != nil && newBlock.NumberU64(bc.chainConfig.Context) != oldBlock.NumberU64(bc.chainConfig.Context); newBlock = bc.GetBlock(newBlock.ParentHash(bc.chainConfig.Context), newBlock.NumberU64(bc.chainConfig.Context)-1) {
 			newChain = append(newChain, newBlock)
 		}
 	}
@@ -2669,11 +2885,11 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		newChain = append(newChain, newBlock)
 
 		// Step back with both chains
-		oldBlock = bc.GetBlock(oldBlock.ParentHash(), oldBlock.NumberU64()-1)
+		oldBlock = bc.GetBlock(oldBlock.ParentHash(bc.chainConfig.Context), oldBlock.NumberU64(bc.chainConfig.Context)-1)
 		if oldBlock == nil {
 			return fmt.Errorf("invalid old chain")
 		}
-		newBlock = bc.GetBlock(newBlock.ParentHash(), newBlock.NumberU64()-1)
+		newBlock = bc.GetBlock(newBlock.ParentHash(bc.chainConfig.Context), newBlock.NumberU64(bc.chainConfig.Context)-1)
 		if newBlock == nil {
 			return fmt.Errorf("invalid new chain")
 		}
@@ -2686,14 +2902,20 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			msg = "Large chain reorg detected"
 			logFn = log.Warn
 		}
-		logFn(msg, "number", commonBlock.Number(), "hash", commonBlock.Hash(),
+		logFn(msg, "number", commonBlock.Number(bc.chainConfig.Context), "hash", commonBlock.Hash(),
 			"drop", len(oldChain), "dropfrom", oldChain[0].Hash(), "add", len(newChain), "addfrom", newChain[0].Hash())
 		blockReorgAddMeter.Mark(int64(len(newChain)))
 		blockReorgDropMeter.Mark(int64(len(oldChain)))
 		blockReorgMeter.Mark(1)
 	} else {
-		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(), "oldhash", oldBlock.Hash(), "newnum", newBlock.Number(), "newhash", newBlock.Hash())
+		log.Error("Impossible reorg, please file an issue", "oldnum", oldBlock.Number(bc.chainConfig.Context), "oldhash", oldBlock.Hash(), "newnum", newBlock.Number(bc.chainConfig.Context), "newhash", newBlock.Hash())
+	}
+	var trigger *types.Header
+	if len(newChain) > 0 {
+		trigger = newChain[0].Header()
 	}
+	journal := bc.prepareReorg(types.ReorgForkChoice, trigger, commonBlock, oldChain, newChain)
+
 	// Insert the new chain(except the head block(reverse order)),
 	// taking care of the proper incremental order.
 	for i := len(newChain) - 1; i >= 1; i-- {
@@ -2716,7 +2938,7 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		rawdb.DeleteTxLookupEntry(indexesBatch, tx.Hash())
 	}
 	// Delete any canonical number assignments above the new head
-	number := bc.CurrentBlock().NumberU64()
+	number := bc.CurrentBlock().NumberU64(bc.chainConfig.Context)
 	for i := number + 1; ; i++ {
 		hash := rawdb.ReadCanonicalHash(bc.db, i)
 		if hash == (common.Hash{}) {
@@ -2743,7 +2965,9 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		}
 	}
 	// Once the common block is found, the reorg data is sent to the reOrg feed
-	bc.reOrgFeed.Send(ReOrgRollup{ReOrgHeader: commonBlock.Header(), OldChainHeaders: bc.getAllHeaders(oldChain), NewChainHeaders: bc.getAllHeaders(newChain)})
+	bc.reOrgFeed.Send(ReOrgRollup{ReOrgHeader: commonBlock.Header(), OldChainHeaders: bc.getAllHeaders(oldChain), NewChainHeaders: bc.getAllHeaders(newChain),
+		NewSubs: journal.record.NewSubs, NewSubContext: bc.chainConfig.Context + 1})
+	bc.journalReorg(journal)
 
 	return nil
 }
@@ -2767,7 +2991,7 @@ func (bc *BlockChain) skipBlock(err error, it *insertIterator) bool {
 		parentRoot common.Hash
 	)
 	// If we also have the snapshot-state, we can skip the processing.
-	if bc.snaps.Snapshot(header.Root[types.QuaiNetworkContext]) != nil {
+	if bc.snaps.Snapshot(header.Root[bc.chainConfig.Context]) != nil {
 		return true
 	}
 	// In this case, we have the trie-state but not snapshot-state. If the parent
@@ -2775,9 +2999,9 @@ func (bc *BlockChain) skipBlock(err error, it *insertIterator) bool {
 	// in the snapshot layers.
 	// Resolve parent block
 	if parent := it.previous(); parent != nil {
-		parentRoot = parent.Root[types.QuaiNetworkContext]
-	} else if parent = bc.GetHeaderByHash(header.ParentHash[types.QuaiNetworkContext]); parent != nil {
-		parentRoot = parent.Root[types.QuaiNetworkContext]
+		parentRoot = parent.Root[bc.chainConfig.Context]
+	} else if parent = bc.GetHeaderByHash(header.ParentHash[bc.chainConfig.Context]); parent != nil {
+		parentRoot = parent.Root[bc.chainConfig.Context]
 	}
 	if parentRoot == (common.Hash{}) {
 		return false // Theoretically impossible case
@@ -2875,7 +3099,7 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 		case head := <-headCh:
 			if done == nil {
 				done = make(chan struct{})
-				go indexBlocks(rawdb.ReadTxIndexTail(bc.db), head.Block.NumberU64(), done)
+				go indexBlocks(rawdb.ReadTxIndexTail(bc.db), head.Block.NumberU64(bc.chainConfig.Context), done)
 			}
 		case <-done:
 			done = nil
@@ -2891,7 +3115,7 @@ func (bc *BlockChain) maintainTxIndex(ancients uint64) {
 
 // reportBlock logs a bad block error.
 func (bc *BlockChain) reportBlock(block *types.Block, receipts types.Receipts, err error) {
-	rawdb.WriteBadBlock(bc.db, block)
+	rawdb.WriteBadBlock(bc.db, block, bc.chainConfig.Context)
 
 	var receiptString string
 	for i, receipt := range receipts {
@@ -2909,7 +3133,7 @@ Hash: 0x%x
 
 Error: %v
 ##############################
-`, bc.chainConfig, block.Number(), block.Hash(), receiptString, err))
+`, bc.chainConfig, block.Number(bc.chainConfig.Context), block.Hash(), receiptString, err))
 }
 
 // InsertHeaderChain attempts to insert the given header chain in to the local
@@ -2971,7 +3195,7 @@ func (bc *BlockChain) CheckHashInclusion(header *types.Header, parent *types.Hea
 	// If we are in Prime node, check to see if the subordinate Region hash included in the parent block
 	// is the same as the hash we are trying to include in the current block.
 	// Need to run when number is greater than 1 for the edge case of new Regions / Zones being mined in sequentially.
-	if types.QuaiNetworkContext < 1 {
+	if bc.chainConfig.Context < 1 {
 		if header.ParentHash[1] == parent.ParentHash[1] && header.Number[1].Cmp(big.NewInt(1)) > 0 {
 			return fmt.Errorf("error subordinate hash already included in parent")
 		}
@@ -2979,17 +3203,17 @@ func (bc *BlockChain) CheckHashInclusion(header *types.Header, parent *types.Hea
 
 	// If we are in a Prime or Region node, check to see if the subordinate Zone hash included in the parent block
 	// is the same as the hash we are trying to include in the current block.
-	if types.QuaiNetworkContext < 2 {
+	if bc.chainConfig.Context < 2 {
 		if header.ParentHash[2] == parent.ParentHash[2] && header.Number[2].Cmp(big.NewInt(1)) > 0 {
 			return fmt.Errorf("error subordinate hash already included in parent")
 		}
 	}
 
-	if types.QuaiNetworkContext == 2 {
+	if bc.chainConfig.Context == 2 {
 		// Upper level check
 		currentBlock := bc.CurrentBlock()
 
-		if currentBlock.NumberU64() == 0 {
+		if currentBlock.NumberU64(bc.chainConfig.Context) == 0 {
 			return nil
 		}
 
@@ -3037,26 +3261,29 @@ func (bc *BlockChain) GetExternalBlock(hash common.Hash, location []byte, contex
 	}
 
 	if block == nil {
-		block = bc.requestExternalBlock(hash, context)
+		extBlockMissMeter.Mark(1)
+		block = bc.fetchExternalBlock(hash, context)
 		if block == nil {
 			return &types.ExternalBlock{}, errExtBlockNotFound
 		}
+		return block, nil
 	}
+	extBlockHitMeter.Mark(1)
 	return block, nil
 }
 
 // requestExternalBlock sends an external block event to the missingExternalBlockFeed in order to be fulfilled by a manager or client.
 func (bc *BlockChain) requestExternalBlock(hash common.Hash, blockContext uint64) *types.ExternalBlock {
-	if bc.domClient != nil {
-		extBlock := FindExternalBlock(bc.domClient, hash, blockContext)
+	if domLink := bc.getDomLink(); domLink != nil {
+		extBlock := FindExternalBlock(domLink, hash, blockContext)
 		if extBlock != nil {
 			return extBlock
 		}
 	}
 
-	for _, client := range bc.subClients {
-		if client != nil {
-			extBlock := FindExternalBlock(client, hash, blockContext)
+	for _, link := range bc.getSubLinks() {
+		if link != nil {
+			extBlock := FindExternalBlock(link, hash, blockContext)
 			if extBlock != nil {
 				return extBlock
 			}
@@ -3066,25 +3293,14 @@ func (bc *BlockChain) requestExternalBlock(hash common.Hash, blockContext uint64
 	return nil
 }
 
-func FindExternalBlock(client *quaiclient.Client, hash common.Hash, blockContext uint64) *types.ExternalBlock {
-	externalBlock, _ := client.GetExternalBlockByHashAndContext(context.Background(), hash, int(blockContext))
-	// if we find the external block in prime, we stop or else we continue to look at the region
-	if externalBlock != nil {
-		return externalBlock
-	}
-
-	block, _ := client.BlockByHash(context.Background(), hash)
-	var receipts []*types.Receipt
-	if block != nil {
-		receiptBlock, err := client.GetBlockReceipts(context.Background(), hash)
-		if err != nil {
-			return nil
-		}
-		receipts = receiptBlock.Receipts()
-		return types.NewExternalBlockWithHeader(block.Header()).WithBody(block.Transactions(), block.Uncles(), receipts, big.NewInt(int64(blockContext)))
+// FindExternalBlock looks up the external block for the given hash and context
+// through a dominant or subordinate link.
+func FindExternalBlock(link DomSubLink, hash common.Hash, blockContext uint64) *types.ExternalBlock {
+	externalBlock, err := link.GetExternalBlock(context.Background(), hash, int(blockContext))
+	if err != nil {
+		return nil
 	}
-
-	return nil
+	return externalBlock
 }
 
 // GetExternalBlockByHashAndContext checks if the ExternalBlock for the given hash is present in the cache and returns the externalBlock
@@ -3098,6 +3314,11 @@ func (bc *BlockChain) GetExternalBlockByHashAndContext(hash common.Hash, context
 		return extBlockDecoded, nil
 	}
 	extBlock := rawdb.ReadExternalBlock(bc.db, hash, uint64(context))
+	if extBlock != nil {
+		if data, err := rlp.EncodeToBytes(extBlock); err == nil {
+			bc.externalBlocks.Set(key, data)
+		}
+	}
 	return extBlock, nil
 }
 
@@ -3144,7 +3365,7 @@ func (bc *BlockChain) GetSubordinateSet(stopHash common.Hash, location []byte) (
 		return nil, err
 	}
 
-	extBlocks, err := bc.GetExternalBlockTraceSet(stopHash, latest, types.QuaiNetworkContext+1)
+	extBlocks, err := bc.GetExternalBlockTraceSet(stopHash, latest, bc.chainConfig.Context+1)
 	if err != nil {
 		return nil, err
 	}
@@ -3162,29 +3383,28 @@ func (bc *BlockChain) GetSubordinateSet(stopHash common.Hash, location []byte) (
 
 // GetTerminusAtOrder returns the terminus at an order for the path at the node context.
 func (bc *BlockChain) GetTerminusAtOrder(header *types.Header, order int) (common.Hash, error) {
-	terminus, err := bc.Engine().PreviousCoincidentOnPath(bc, header, header.Location, order, types.QuaiNetworkContext, true)
+	terminus, err := bc.Engine().PreviousCoincidentOnPath(bc, header, header.Location, order, bc.chainConfig.Context, true)
 	if err != nil {
 		return common.Hash{}, err
 	}
 	return terminus.Hash(), nil
 }
 
-// StoreExternalBlocks removes the external block from the cached blocks and writes it into the database
-func (bc *BlockChain) StoreExternalBlocks(blocks []*types.ExternalBlock) error {
-
-	for i := 0; i < len(blocks); i++ {
-		context := blocks[i].Context().Uint64()
-		hash := blocks[i].Hash()
-
-		// Lookup block in externalBlocks cache
-		key := types.ExtBlockCacheKey(context, hash)
-		bc.externalBlocks.Del(key)
-
-		rawdb.WriteExternalBlock(bc.db, blocks[i])
+// StoreExternalBlocks writes the external blocks linked by a header into the
+// database, retaining them under the linking header.
+func (bc *BlockChain) StoreExternalBlocks(header *types.Header, blocks []*types.ExternalBlock) error {
+	for _, block := range blocks {
+		bc.writeExternalBlock(block, header)
 	}
 	return nil
 }
 
+// GetCoincidentExternalBlocks retrieves the stored external blocks linked by
+// the block with the given hash.
+func (bc *BlockChain) GetCoincidentExternalBlocks(hash common.Hash) []*types.ExternalBlock {
+	return rawdb.ReadCoincidentExternalBlocks(bc.db, hash)
+}
+
 // GetExternalBlocks retrieves the external blocks for a given header. Will call the necessary
 // TraceBranch functionality.
 func (bc *BlockChain) GetExternalBlocks(header *types.Header) ([]*types.ExternalBlock, error) {
@@ -3278,7 +3498,7 @@ func (bc *BlockChain) QueueAndRetrieveExtBlocks(externalBlocks []*types.External
 			gasUsed += int(receipt.GasUsed)
 		}
 		// If the total gasUsed for external transactions exceeds this blocks gasLimit by 50% break
-		if gasUsed > int(header.GasLimit[types.QuaiNetworkContext]/2) {
+		if gasUsed > int(header.GasLimit[bc.chainConfig.Context]/2) {
 			break
 		}
 	}
@@ -3319,15 +3539,16 @@ func (bc *BlockChain) CheckExtBlockDuplicates(externalBlocks []*types.ExternalBl
 func (bc *BlockChain) checkExtBlockCollision(header *types.Header, externalBlocks []*types.ExternalBlock) error {
 	for _, extBlock := range externalBlocks {
 		equalLocation := bytes.Compare(extBlock.Header().Location, header.Location) == 0
-		greaterContext := int(extBlock.Context().Int64()) < types.QuaiNetworkContext
+		greaterContext := int(extBlock.Context().Int64()) < bc.chainConfig.Context
 
-		subExtBlockNum := extBlock.Header().Number[types.QuaiNetworkContext]
-		subHeaderNum := header.Number[types.QuaiNetworkContext]
+		subExtBlockNum := extBlock.Header().Number[bc.chainConfig.Context]
+		subHeaderNum := header.Number[bc.chainConfig.Context]
 
 		domExtBlockNum := extBlock.Header().Number[extBlock.Context().Int64()]
 		domHeaderNum := header.Number[extBlock.Context().Int64()]
 
 		if equalLocation && greaterContext && subExtBlockNum.Cmp(subHeaderNum) >= 0 && domExtBlockNum.Cmp(domHeaderNum) != 0 {
+			bc.reportFault(bc.collisionProof(header, extBlock))
 			return fmt.Errorf("external block collision detected")
 		}
 	}
@@ -3336,34 +3557,30 @@ func (bc *BlockChain) checkExtBlockCollision(header *types.Header, externalBlock
 
 // HLCR does hierarchical comparison of two difficulty tuples and returns true if second tuple is greater than the first
 func (bc *BlockChain) HLCR(localDifficulties []*big.Int, externDifficulties []*big.Int) bool {
-	if externDifficulties == nil || len(externDifficulties) == 0 || localDifficulties == nil || len(localDifficulties) == 0 {
-		return false
-	}
-	if localDifficulties[0].Cmp(externDifficulties[0]) < 0 {
-		return true
-	} else if localDifficulties[0].Cmp(externDifficulties[0]) > 0 {
-		return false
-	}
-	if localDifficulties[1].Cmp(externDifficulties[1]) < 0 {
-		return true
-	} else if localDifficulties[1].Cmp(externDifficulties[1]) > 0 {
+	if len(externDifficulties) == 0 || len(localDifficulties) == 0 {
 		return false
 	}
-	if localDifficulties[2].Cmp(externDifficulties[2]) < 0 {
-		return true
-	} else if localDifficulties[2].Cmp(externDifficulties[2]) > 0 {
-		return false
-	}
-	return false
+	return forkchoice.Compare(localDifficulties, externDifficulties) < 0
+}
+
+// SetForkChoice replaces the rule choosing the head of the chain among
+// competing forks.
+func (bc *BlockChain) SetForkChoice(rule forkchoice.Rule) {
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	bc.forker.SetRule(rule)
+	bc.hc.SetForkChoice(rule)
 }
 
 // The purpose of the Previous Coincident Reference Check (PCRC) is to establish
 // that we have linked untwisted chains prior to checking HLCR & applying external state transfers.
 // NOTE: note that it only guarantees linked & untwisted back to the prime terminus, assuming the
-// prime termini match. To check deeper than that, you need to iteratively apply PCRC to get that guarantee.
-func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTermini, error) {
+// prime termini match. To check deeper than that, you need to iteratively apply PCRC to get that guarantee,
+// which VerifyPCRC does.
+func (bc *BlockChain) pcrc(header *types.Header, headerOrder int) (types.PCRCTermini, error) {
 
-	if header.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+	if header.Number[bc.chainConfig.Context].Cmp(big.NewInt(0)) == 0 {
 		return types.PCRCTermini{}, nil
 	}
 
@@ -3381,7 +3598,7 @@ func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTer
 	// region   	| X					| x PTP, RTR, PRTP, PRTR		| x PTP, PTR, RTR, PRTP, PRTR
 	// zone			| X					| X								| x PTP, PTR, RTR, PRTP, PRTR
 
-	switch types.QuaiNetworkContext {
+	switch bc.chainConfig.Context {
 	case params.PRIME:
 		fmt.Println("PCRC Running PTP")
 		PTP, err := bc.PreviousValidCoincidentOnPath(header, slice, params.PRIME, params.PRIME, true)
@@ -3396,10 +3613,11 @@ func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTer
 			return types.PCRCTermini{}, err
 		}
 
-		if bc.subClients[slice[0]-1] == nil {
+		subLink := bc.getSubLink(int(slice[0]) - 1)
+		if subLink == nil {
 			return types.PCRCTermini{}, nil
 		}
-		PCRCTermini, err := bc.subClients[slice[0]-1].CheckPCRC(context.Background(), header, headerOrder)
+		PCRCTermini, err := subLink.CheckPCRC(context.Background(), header, headerOrder)
 		if err != nil {
 			return types.PCRCTermini{}, err
 		}
@@ -3414,11 +3632,13 @@ func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTer
 
 		if (PTP.Hash() != PCRCTermini.PTR) && (PCRCTermini.PTR != PCRCTermini.PTZ) && (PCRCTermini.PTZ != PTP.Hash()) {
 			fmt.Println("PTP", PTP.Hash(), "PTR", PCRCTermini.PTR, "PTZ", PCRCTermini.PTZ)
-			return types.PCRCTermini{}, errors.New("there exists a Prime twist (PTP != PTR != PTZ")
+			bc.reportFault(bc.twistProof(header, params.PRIME, false, params.PRIME, params.REGION, params.ZONE))
+			return types.PCRCTermini{}, ErrPrimeTwist
 		}
 		if PRTP.Hash() != PCRCTermini.PRTR {
 			fmt.Println("PRTP", PRTP.Hash(), PCRCTermini.PRTR)
-			return types.PCRCTermini{}, errors.New("there exists a Prime twist (PRTP != PRTR")
+			bc.reportFault(bc.twistProof(header, params.PRIME, true, params.PRIME, params.REGION))
+			return types.PCRCTermini{}, ErrPrimeRegionTwist
 		}
 
 		return PCRCTermini, nil
@@ -3431,11 +3651,12 @@ func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTer
 			return types.PCRCTermini{}, err
 		}
 
-		if bc.subClients[slice[1]-1] == nil {
+		subLink := bc.getSubLink(int(slice[1]) - 1)
+		if subLink == nil {
 			return types.PCRCTermini{}, nil
 		}
 
-		PCRCTermini, err := bc.subClients[slice[1]-1].CheckPCRC(context.Background(), header, headerOrder)
+		PCRCTermini, err := subLink.CheckPCRC(context.Background(), header, headerOrder)
 		if err != nil {
 			return types.PCRCTermini{}, err
 		}
@@ -3446,7 +3667,8 @@ func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTer
 
 		if RTR.Hash() != PCRCTermini.RTZ {
 			fmt.Println("RTR", RTR.Number, RTR.Hash(), "RTZ", PCRCTermini.RTZ)
-			return types.PCRCTermini{}, errors.New("there exists a Region twist (RTR != RTZ)")
+			bc.reportFault(bc.twistProof(header, params.REGION, false, params.REGION, params.ZONE))
+			return types.PCRCTermini{}, ErrRegionTwist
 		}
 		if headerOrder < params.REGION {
 			fmt.Println("PCRC Running PTR")
@@ -3506,28 +3728,28 @@ func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTer
 func (bc *BlockChain) PreviousValidCoincidentOnPath(header *types.Header, slice []byte, order, path int, fullSliceEqual bool) (*types.Header, error) {
 	prevTerminalHeader := header
 	for {
-		if prevTerminalHeader.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+		if prevTerminalHeader.Number[bc.chainConfig.Context].Cmp(big.NewInt(0)) == 0 {
 			return bc.GetHeaderByHash(bc.Config().GenesisHashes[0]), nil
 		}
 
-		terminalHeader, err := bc.Engine().PreviousCoincidentOnPath(bc, prevTerminalHeader, slice, order, path, fullSliceEqual)
+		terminalHeader, err := bc.previousCoincidentOnPath(prevTerminalHeader, slice, order, path, fullSliceEqual)
 		if err != nil {
 			return nil, err
 		}
 
 		fmt.Println("Running PVCOP for header: ", header.Hash(), header.Number, "terminal Header", terminalHeader.Hash(), terminalHeader.Number)
 
-		if terminalHeader.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+		if terminalHeader.Number[bc.chainConfig.Context].Cmp(big.NewInt(0)) == 0 {
 			return bc.GetHeaderByHash(bc.Config().GenesisHashes[0]), nil
 		}
 
 		// If the current header is dominant coincident check the status with the dom node
-		if order < types.QuaiNetworkContext {
-			status := bc.domClient.GetBlockStatus(context.Background(), terminalHeader)
+		if order < bc.chainConfig.Context {
+			status := bc.domBlockStatus(terminalHeader)
 			fmt.Println("terminal Header status", status)
 			// If the header is cononical break else keep looking
 			switch status {
-			case quaiclient.UnknownStatTy:
+			case UnknownStatTy:
 				// do nothing and find latest uncle or canonical in dom
 			default:
 				if prevTerminalHeader.Hash() != header.Hash() {
@@ -3535,7 +3757,7 @@ func (bc *BlockChain) PreviousValidCoincidentOnPath(header *types.Header, slice
 				}
 				return terminalHeader, nil
 			}
-		} else if order == types.QuaiNetworkContext {
+		} else if order == bc.chainConfig.Context {
 			return terminalHeader, err
 		}
 
@@ -3549,7 +3771,7 @@ func (bc *BlockChain) PreviousValidCoincidentOnPath(header *types.Header, slice
 // prime termini match. To check deeper than that, you need to iteratively apply PCRC to get that guarantee.
 func (bc *BlockChain) PCCRC(header *types.Header, headerOrder int) (types.PCRCTermini, error) {
 
-	if header.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+	if header.Number[bc.chainConfig.Context].Cmp(big.NewInt(0)) == 0 {
 		return types.PCRCTermini{}, nil
 	}
 
@@ -3567,7 +3789,7 @@ func (bc *BlockChain) PCCRC(header *types.Header, headerOrder int) (types.PCRCTe
 	// region   	| X					| x PTP, RTR, PRTP, PRTR		| x PTP, PTR, RTR, PRTP, PRTR
 	// zone			| X					| X								| x PTP, PTR, RTR, PRTP, PRTR
 
-	switch types.QuaiNetworkContext {
+	switch bc.chainConfig.Context {
 	case params.PRIME:
 		fmt.Println("PCCRC Running PTP")
 		PTP, err := bc.PreviousCanonicalCoincidentOnPath(header, slice, params.PRIME, params.PRIME, true)
@@ -3582,10 +3804,11 @@ func (bc *BlockChain) PCCRC(header *types.Header, headerOrder int) (types.PCRCTe
 			return types.PCRCTermini{}, err
 		}
 
-		if bc.subClients[slice[0]-1] == nil {
+		subLink := bc.getSubLink(int(slice[0]) - 1)
+		if subLink == nil {
 			return types.PCRCTermini{}, nil
 		}
-		PCRCTermini, err := bc.subClients[slice[0]-1].CheckPCCRC(context.Background(), header, headerOrder)
+		PCRCTermini, err := subLink.CheckPCCRC(context.Background(), header, headerOrder)
 		if err != nil {
 			return types.PCRCTermini{}, err
 		}
@@ -3599,11 +3822,11 @@ func (bc *BlockChain) PCCRC(header *types.Header, headerOrder int) (types.PCRCTe
 
 		if (PTP.Hash() != PCRCTermini.PTR) && (PCRCTermini.PTR != PCRCTermini.PTZ) && (PCRCTermini.PTZ != PTP.Hash()) {
 			fmt.Println("PTP", PTP.Hash(), "PTR", PCRCTermini.PTR, "PTZ", PCRCTermini.PTZ)
-			return types.PCRCTermini{}, errors.New("there exists a Prime twist (PTP != PTR != PTZ")
+			return types.PCRCTermini{}, ErrPrimeTwist
 		}
 		if PRTP.Hash() != PCRCTermini.PRTR {
 			fmt.Println("PRTP", PRTP.Hash(), PCRCTermini.PRTR)
-			return types.PCRCTermini{}, errors.New("there exists a Prime twist (PRTP != PRTR")
+			return types.PCRCTermini{}, ErrPrimeRegionTwist
 		}
 
 		return PCRCTermini, nil
@@ -3617,11 +3840,12 @@ func (bc *BlockChain) PCCRC(header *types.Header, headerOrder int) (types.PCRCTe
 			return types.PCRCTermini{}, err
 		}
 
-		if bc.subClients[slice[1]-1] == nil {
+		subLink := bc.getSubLink(int(slice[1]) - 1)
+		if subLink == nil {
 			return types.PCRCTermini{}, nil
 		}
 
-		PCRCTermini, err := bc.subClients[slice[1]-1].CheckPCCRC(context.Background(), header, headerOrder)
+		PCRCTermini, err := subLink.CheckPCCRC(context.Background(), header, headerOrder)
 		if err != nil {
 			return types.PCRCTermini{}, err
 		}
@@ -3632,7 +3856,7 @@ func (bc *BlockChain) PCCRC(header *types.Header, headerOrder int) (types.PCRCTe
 
 		if RTR.Hash() != PCRCTermini.RTZ {
 			fmt.Println("RTR", RTR.Number, RTR.Hash(), "RTZ", PCRCTermini.RTZ)
-			return types.PCRCTermini{}, errors.New("there exists a Region twist (RTR != RTZ)")
+			return types.PCRCTermini{}, ErrRegionTwist
 		}
 		if headerOrder < params.REGION {
 			fmt.Println("PCCRC Running PTR")
@@ -3693,25 +3917,25 @@ func (bc *BlockChain) PCCRC(header *types.Header, headerOrder int) (types.PCRCTe
 func (bc *BlockChain) PreviousCanonicalCoincidentOnPath(header *types.Header, slice []byte, order, path int, fullSliceEqual bool) (*types.Header, error) {
 	prevTerminalHeader := header
 	for {
-		if prevTerminalHeader.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+		if prevTerminalHeader.Number[bc.chainConfig.Context].Cmp(big.NewInt(0)) == 0 {
 			return bc.GetHeaderByHash(bc.Config().GenesisHashes[0]), nil
 		}
 
-		terminalHeader, err := bc.Engine().PreviousCoincidentOnPath(bc, prevTerminalHeader, slice, order, path, fullSliceEqual)
+		terminalHeader, err := bc.previousCoincidentOnPath(prevTerminalHeader, slice, order, path, fullSliceEqual)
 		if err != nil {
 			return nil, err
 		}
 		fmt.Println("PCCOP Terminal Header Number:", terminalHeader.Number, "Hash:", terminalHeader.Hash(), "Parent Hash", terminalHeader.ParentHash[path])
-		if terminalHeader.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+		if terminalHeader.Number[bc.chainConfig.Context].Cmp(big.NewInt(0)) == 0 {
 			return bc.GetHeaderByHash(bc.Config().GenesisHashes[0]), nil
 		}
 
 		// If the current header is dominant coincident check the status with the dom node
-		if order < types.QuaiNetworkContext {
-			status := bc.domClient.GetBlockStatus(context.Background(), terminalHeader)
+		if order < bc.chainConfig.Context {
+			status := bc.domBlockStatus(terminalHeader)
 
 			switch status {
-			case quaiclient.UnknownStatTy:
+			case UnknownStatTy:
 				// do nothing and find latest uncle or canonical in dom
 				block := bc.GetBlockByHash(terminalHeader.Hash())
 				if block == nil {
@@ -3721,7 +3945,7 @@ func (bc *BlockChain) PreviousCanonicalCoincidentOnPath(header *types.Header, sl
 				if err != nil {
 					return nil, err
 				}
-			case quaiclient.SideStatTy:
+			case SideStatTy:
 				bc.ReOrgRollBack(prevTerminalHeader, []*types.Header{}, []*types.Header{})
 				return prevTerminalHeader, errors.New("PCCOP has found chain is not being built on canonical dom")
 			default:
@@ -3730,7 +3954,7 @@ func (bc *BlockChain) PreviousCanonicalCoincidentOnPath(header *types.Header, sl
 				}
 				return terminalHeader, nil
 			}
-		} else if order == types.QuaiNetworkContext {
+		} else if order == bc.chainConfig.Context {
 			return terminalHeader, err
 		}
 
@@ -3746,15 +3970,64 @@ func (bc *BlockChain) GetDifficultyOrder(header *types.Header) (int, error) {
 	return headerOrder, nil
 }
 
+// InsertMinedBlock imports a block sealed by a miner or delivered by the
+// subordinate chain. A block satisfying the difficulty of a dominant context is
+// delivered to the dominant chain first, which relays it further up to the
+// context of its order, as this chain only accepts it once it is canonical
+// there. The dominant chain receives its own body if it is known as an
+// external block.
+func (bc *BlockChain) InsertMinedBlock(ctx context.Context, block *types.Block) (int, error) {
+	order, err := bc.engine.GetDifficultyOrder(block.Header())
+	if err != nil {
+		return 0, err
+	}
+	if order < bc.chainConfig.Context {
+		domLink := bc.getDomLink()
+		if domLink == nil {
+			return 0, errors.New("dom client is nil")
+		}
+		domBlock := block
+		if extBlock, _ := bc.GetExternalBlockByHashAndContext(block.Hash(), bc.chainConfig.Context-1); extBlock != nil {
+			domBlock = types.NewBlockWithHeader(extBlock.Header()).WithBody(extBlock.Transactions(), extBlock.Uncles())
+		}
+		if err := domLink.SendMinedBlock(ctx, domBlock); err != nil {
+			return 0, fmt.Errorf("failed to deliver block to dominant chain: %v", err)
+		}
+	}
+	return bc.InsertChain(types.Blocks{block})
+}
+
+// domBlockStatus returns the status of the header in the dominant chain. While
+// an export is imported, a dominant block is canonical if the export bundled its
+// dominant body, as the dominant chain may not be reachable.
+func (bc *BlockChain) domBlockStatus(header *types.Header) WriteStatus {
+	if bc.importing {
+		if extBlock, _ := bc.GetExternalBlockByHashAndContext(header.Hash(), bc.chainConfig.Context-1); extBlock != nil {
+			return CanonStatTy
+		}
+		return UnknownStatTy
+	}
+	domLink := bc.getDomLink()
+	if domLink == nil {
+		return UnknownStatTy
+	}
+	return domLink.GetBlockStatus(context.Background(), header)
+}
+
 // CheckDominantBlock sends the block to the dominant chain.
 func (bc *BlockChain) CheckDominantBlock(block *types.Block) error {
-	if bc.domClient == nil {
+	// An imported dominant block was already accepted by the dominant chain
+	if bc.importing {
+		return nil
+	}
+	domLink := bc.getDomLink()
+	if domLink == nil {
 		return errors.New("dom client is nil")
 	}
 
 	status := bc.GetBlockStatus(block.Header())
-	if status == WriteStatus(quaiclient.UnknownStatTy) {
-		extBlock, err := bc.GetExternalBlockByHashAndContext(block.Header().Hash(), types.QuaiNetworkContext-1)
+	if status == UnknownStatTy {
+		extBlock, err := bc.GetExternalBlockByHashAndContext(block.Header().Hash(), bc.chainConfig.Context-1)
 		if err != nil {
 			return err
 		}
@@ -3766,7 +4039,7 @@ func (bc *BlockChain) CheckDominantBlock(block *types.Block) error {
 		block := types.NewBlockWithHeader(extBlock.Header()).WithBody(extBlock.Transactions(), extBlock.Uncles())
 		sealed := block.WithSeal(block.Header())
 		log.Debug("Sending dominant block", "number", block.Header().Number, "hash", block.Hash())
-		go bc.domClient.SendMinedBlock(context.Background(), sealed, true, true)
+		go domLink.SendMinedBlock(context.Background(), sealed)
 	}
 
 	return nil
@@ -3942,10 +4215,11 @@ func (bc *BlockChain) CheckContext(number int) error {
 
 // CheckLocationRange checks to make sure the range of location is valid
 func (bc *BlockChain) CheckLocationRange(location []byte) error {
-	if int(location[0]) < 1 || int(location[0]) > params.FullerOntology[0] {
+	ontology := bc.chainConfig.OntologyShape()
+	if int(location[0]) < 1 || int(location[0]) > ontology[0] {
 		return errors.New("the provided location is outside the allowable region range")
 	}
-	if int(location[1]) < 1 || int(location[1]) > params.FullerOntology[1] {
+	if int(location[1]) < 1 || int(location[1]) > ontology[1] {
 		return errors.New("the provided location is outside the allowable zone range")
 	}
 	return nil
@@ -31,6 +31,7 @@ type insertStats struct {
 	usedGas                    uint64
 	lastIndex                  int
 	startTime                  mclock.AbsTime
+	context                    int
 }
 
 // statsReportLimit is the time limit during import and export after which we
@@ -58,7 +59,7 @@ func (st *insertStats) report(chain []*types.Block, index int, dirty common.Stor
 		context := []interface{}{
 			"blocks", st.processed, "txs", txs, "mgas", float64(st.usedGas) / 1000000,
 			"elapsed", common.PrettyDuration(elapsed), "mgasps", float64(st.usedGas) * 1000 / float64(elapsed),
-			"number", end.Number(), "hash", end.Hash(),
+			"number", end.Number(st.context), "hash", end.Hash(),
 		}
 		if timestamp := time.Unix(int64(end.Time()), 0); time.Since(timestamp) > time.Minute {
 			context = append(context, []interface{}{"age", common.PrettyAge(timestamp)}...)
@@ -74,7 +75,7 @@ func (st *insertStats) report(chain []*types.Block, index int, dirty common.Stor
 		log.Info("Imported new chain segment", context...)
 
 		// Bump the stats reported to the next section
-		*st = insertStats{startTime: now, lastIndex: index + 1}
+		*st = insertStats{startTime: now, lastIndex: index + 1, context: st.context}
 	}
 }
 
@@ -2004,9 +2004,9 @@ func testSetHead(t *testing.T, tt *rewindTest, snapshots bool) {
 		t.Fatalf("Failed to import canonical chain start: %v", err)
 	}
 	if tt.commitBlock > 0 {
-		chain.stateCache.TrieDB().Commit(canonblocks[tt.commitBlock-1].Root(), true, nil)
+		chain.stateCache.TrieDB().Commit(canonblocks[tt.commitBlock-1].Root(types.QuaiNetworkContext), true, nil)
 		if snapshots {
-			if err := chain.snaps.Cap(canonblocks[tt.commitBlock-1].Root(), 0); err != nil {
+			if err := chain.snaps.Cap(canonblocks[tt.commitBlock-1].Root(types.QuaiNetworkContext), 0); err != nil {
 				t.Fatalf("Failed to flatten snapshots: %v", err)
 			}
 		}
@@ -2016,10 +2016,10 @@ func testSetHead(t *testing.T, tt *rewindTest, snapshots bool) {
 	}
 	// Manually dereference anything not committed to not have to work with 128+ tries
 	for _, block := range sideblocks {
-		chain.stateCache.TrieDB().Dereference(block.Root())
+		chain.stateCache.TrieDB().Dereference(block.Root(types.QuaiNetworkContext))
 	}
 	for _, block := range canonblocks {
-		chain.stateCache.TrieDB().Dereference(block.Root())
+		chain.stateCache.TrieDB().Dereference(block.Root(types.QuaiNetworkContext))
 	}
 	// Force run a freeze cycle
 	type freezer interface {
@@ -2044,11 +2044,11 @@ func testSetHead(t *testing.T, tt *rewindTest, snapshots bool) {
 	if head := chain.CurrentHeader(); head.Number[types.QuaiNetworkContext].Uint64() != tt.expHeadHeader {
 		t.Errorf("Head header mismatch: have %d, want %d", head.Number, tt.expHeadHeader)
 	}
-	if head := chain.CurrentFastBlock(); head.NumberU64() != tt.expHeadFastBlock {
-		t.Errorf("Head fast block mismatch: have %d, want %d", head.NumberU64(), tt.expHeadFastBlock)
+	if head := chain.CurrentFastBlock(); head.NumberU64(types.QuaiNetworkContext) != tt.expHeadFastBlock {
+		t.Errorf("Head fast block mismatch: have %d, want %d", head.NumberU64(types.QuaiNetworkContext), tt.expHeadFastBlock)
 	}
-	if head := chain.CurrentBlock(); head.NumberU64() != tt.expHeadBlock {
-		t.Errorf("Head block mismatch: have %d, want %d", head.NumberU64(), tt.expHeadBlock)
+	if head := chain.CurrentBlock(); head.NumberU64(types.QuaiNetworkContext) != tt.expHeadBlock {
+		t.Errorf("Head block mismatch: have %d, want %d", head.NumberU64(types.QuaiNetworkContext), tt.expHeadBlock)
 	}
 	if frozen, err := db.(freezer).Ancients(); err != nil {
 		t.Errorf("Failed to retrieve ancient count: %v\n", err)
@@ -2118,45 +2118,45 @@ func verifyCutoff(t *testing.T, chain *BlockChain, canonical bool, inserted type
 		if i <= head {
 			if header := chain.GetHeader(inserted[i-1].Hash(), uint64(i)); header == nil {
 				if canonical {
-					t.Errorf("Canonical header   #%2d [%x...] missing before cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Canonical header   #%2d [%x...] missing before cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				} else {
-					t.Errorf("Sidechain header   #%2d [%x...] missing before cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Sidechain header   #%2d [%x...] missing before cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				}
 			}
 			if block := chain.GetBlock(inserted[i-1].Hash(), uint64(i)); block == nil {
 				if canonical {
-					t.Errorf("Canonical block    #%2d [%x...] missing before cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Canonical block    #%2d [%x...] missing before cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				} else {
-					t.Errorf("Sidechain block    #%2d [%x...] missing before cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Sidechain block    #%2d [%x...] missing before cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				}
 			}
 			if receipts := chain.GetReceiptsByHash(inserted[i-1].Hash()); receipts == nil {
 				if canonical {
-					t.Errorf("Canonical receipts #%2d [%x...] missing before cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Canonical receipts #%2d [%x...] missing before cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				} else {
-					t.Errorf("Sidechain receipts #%2d [%x...] missing before cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Sidechain receipts #%2d [%x...] missing before cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				}
 			}
 		} else {
 			if header := chain.GetHeader(inserted[i-1].Hash(), uint64(i)); header != nil {
 				if canonical {
-					t.Errorf("Canonical header   #%2d [%x...] present after cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Canonical header   #%2d [%x...] present after cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				} else {
-					t.Errorf("Sidechain header   #%2d [%x...] present after cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Sidechain header   #%2d [%x...] present after cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				}
 			}
 			if block := chain.GetBlock(inserted[i-1].Hash(), uint64(i)); block != nil {
 				if canonical {
-					t.Errorf("Canonical block    #%2d [%x...] present after cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Canonical block    #%2d [%x...] present after cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				} else {
-					t.Errorf("Sidechain block    #%2d [%x...] present after cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Sidechain block    #%2d [%x...] present after cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				}
 			}
 			if receipts := chain.GetReceiptsByHash(inserted[i-1].Hash()); receipts != nil {
 				if canonical {
-					t.Errorf("Canonical receipts #%2d [%x...] present after cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Canonical receipts #%2d [%x...] present after cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				} else {
-					t.Errorf("Sidechain receipts #%2d [%x...] present after cap %d", inserted[i-1].Number(), inserted[i-1].Hash().Bytes()[:3], head)
+					t.Errorf("Sidechain receipts #%2d [%x...] present after cap %d", inserted[i-1].Number(types.QuaiNetworkContext), inserted[i-1].Hash().Bytes()[:3], head)
 				}
 			}
 		}
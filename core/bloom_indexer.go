@@ -42,18 +42,21 @@ type BloomIndexer struct {
 	gen     *bloombits.Generator // generator to rotate the bloom bits crating the bloom index
 	section uint64               // Section is the section number being processed currently
 	head    common.Hash          // Head is the hash of the last header processed
+
+	chainContext int // Context of the chain whose headers are indexed
 }
 
 // NewBloomIndexer returns a chain indexer that generates bloom bits data for the
-// canonical chain for fast logs filtering.
-func NewBloomIndexer(db ethdb.Database, size, confirms uint64) *ChainIndexer {
+// canonical chain of the given context for fast logs filtering.
+func NewBloomIndexer(db ethdb.Database, size, confirms uint64, chainContext int) *ChainIndexer {
 	backend := &BloomIndexer{
-		db:   db,
-		size: size,
+		db:           db,
+		size:         size,
+		chainContext: chainContext,
 	}
 	table := rawdb.NewTable(db, string(rawdb.BloomBitsIndexPrefix))
 
-	return NewChainIndexer(db, table, backend, size, confirms, bloomThrottling, "bloombits")
+	return NewChainIndexer(db, table, backend, size, confirms, bloomThrottling, "bloombits", chainContext)
 }
 
 // Reset implements core.ChainIndexerBackend, starting a new bloombits index
@@ -69,9 +72,9 @@ func (b *BloomIndexer) Reset(ctx context.Context, section uint64, lastSectionHea
 func (b *BloomIndexer) Process(ctx context.Context, header *types.Header) error {
 	bloom := types.Bloom{}
 	if len(header.Bloom) > 0 {
-		bloom = header.Bloom[types.QuaiNetworkContext]
+		bloom = header.Bloom[b.chainContext]
 	}
-	b.gen.AddBloom(uint(header.Number[types.QuaiNetworkContext].Uint64()-b.section*b.size), bloom)
+	b.gen.AddBloom(uint(header.Number[b.chainContext].Uint64()-b.section*b.size), bloom)
 	b.head = header.Hash()
 	return nil
 }
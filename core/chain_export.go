@@ -0,0 +1,279 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/rlp"
+	"github.com/spruce-solutions/go-quai/trie"
+)
+
+const (
+	exportMagic   = "quai-hierarchy-export" // Identifies a hierarchy aware export
+	exportVersion = 1                       // Version of the export format
+)
+
+var (
+	// ErrExportContext is returned when importing an export of another context.
+	ErrExportContext = errors.New("export belongs to another context")
+
+	// ErrExportGenesis is returned when importing an export of another network.
+	ErrExportGenesis = errors.New("export belongs to another genesis")
+)
+
+// ExportHeader precedes the exported blocks of a hierarchy aware export,
+// identifying the chain they were exported from.
+type ExportHeader struct {
+	Magic   string
+	Version uint64
+	Context uint64
+	Genesis common.Hash
+}
+
+// ExportedBlock bundles a block with its receipts and the external blocks it
+// references, which is everything needed to re-validate the block without
+// access to the dominant and subordinate chains.
+type ExportedBlock struct {
+	Block          *types.Block
+	Receipts       []*types.ReceiptForStorage
+	ExternalBlocks []*types.ExternalBlock
+}
+
+// exportHeader returns the header of an export of the chain.
+func (bc *BlockChain) exportHeader() *ExportHeader {
+	return &ExportHeader{
+		Magic:   exportMagic,
+		Version: exportVersion,
+		Context: uint64(bc.chainConfig.Context),
+		Genesis: bc.genesisBlock.Hash(),
+	}
+}
+
+// exportBlock bundles the block with the given number for export.
+func (bc *BlockChain) exportBlock(number uint64) (*ExportedBlock, error) {
+	block := bc.GetBlockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("export failed on #%d: not found", number)
+	}
+	exported := &ExportedBlock{Block: block}
+	for _, receipt := range bc.GetReceiptsByHash(block.Hash()) {
+		exported.Receipts = append(exported.Receipts, (*types.ReceiptForStorage)(receipt))
+	}
+	if number == 0 {
+		return exported, nil
+	}
+	// Bundle the external blocks applied by the state processor as well as the
+	// ones linked when writing the block, each only once.
+	applied, err := bc.engine.GetExternalBlocks(bc, block.Header(), false)
+	if err != nil {
+		return nil, fmt.Errorf("export failed on #%d: %v", number, err)
+	}
+	linked, err := bc.engine.GetLinkExternalBlocks(bc, block.Header(), false)
+	if err != nil {
+		return nil, fmt.Errorf("export failed on #%d: %v", number, err)
+	}
+	extBlocks := append(applied, linked...)
+
+	// Dominant blocks also bundle their body in the dominant chain, which
+	// vouches for them being canonical there when imported offline.
+	order, err := bc.engine.GetDifficultyOrder(block.Header())
+	if err != nil {
+		return nil, fmt.Errorf("export failed on #%d: %v", number, err)
+	}
+	if order < bc.chainConfig.Context {
+		domBlock, err := bc.GetExternalBlockByHashAndContext(block.Hash(), bc.chainConfig.Context-1)
+		if err != nil {
+			return nil, fmt.Errorf("export failed on #%d: %v", number, err)
+		}
+		if domBlock == nil {
+			return nil, fmt.Errorf("export failed on #%d: dominant block %x not found", number, block.Hash())
+		}
+		extBlocks = append(extBlocks, domBlock)
+	}
+	seen := make(map[string]struct{})
+	for _, extBlock := range extBlocks {
+		key := string(extBlock.CacheKey())
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		exported.ExternalBlocks = append(exported.ExternalBlocks, extBlock)
+	}
+	return exported, nil
+}
+
+// InsertExportedChain stores the external blocks bundled with a batch of
+// exported blocks and inserts the blocks into the chain. The bundled receipts
+// are checked against the headers so that a corrupt export is rejected before
+// any of its external blocks are stored, and are stored for the inserted blocks
+// which lack them.
+//
+// The dominant chain is not consulted while inserting: a dominant block counts
+// as canonical there if the export bundled its dominant body.
+func (bc *BlockChain) InsertExportedChain(exported []*ExportedBlock) (int, error) {
+	if len(exported) == 0 {
+		return 0, nil
+	}
+	context := bc.chainConfig.Context
+	blocks := make(types.Blocks, len(exported))
+	receipts := make([]types.Receipts, len(exported))
+	for i, entry := range exported {
+		block := entry.Block
+		if entry.Receipts != nil {
+			receipts[i] = make(types.Receipts, len(entry.Receipts))
+			for j, receipt := range entry.Receipts {
+				receipts[i][j] = (*types.Receipt)(receipt)
+			}
+			if err := receipts[i].DeriveFields(bc.chainConfig, block.Hash(), block.NumberU64(context), block.Transactions()); err != nil {
+				return i, fmt.Errorf("invalid receipts of block #%d: %v", block.NumberU64(context), err)
+			}
+			if hash := types.DeriveSha(receipts[i], trie.NewStackTrie(nil)); hash != block.Header().ReceiptHash[context] {
+				return i, fmt.Errorf("invalid receipts of block #%d: root mismatch (have %x, want %x)", block.NumberU64(context), hash, block.Header().ReceiptHash[context])
+			}
+		}
+		blocks[i] = block
+	}
+	if err := bc.checkContiguous(blocks); err != nil {
+		return 0, err
+	}
+	for _, entry := range exported {
+		for _, extBlock := range entry.ExternalBlocks {
+			bc.writeExternalBlock(extBlock, nil)
+			bc.trackAvailableETxs(extBlock)
+		}
+	}
+	bc.blockProcFeed.Send(true)
+	defer bc.blockProcFeed.Send(false)
+
+	bc.reorgmu.Lock()
+	bc.chainmu.Lock()
+	bc.importing = true
+	n, err := bc.insertChain(blocks, true, true)
+	bc.importing = false
+	bc.chainmu.Unlock()
+	bc.reorgmu.Unlock()
+
+	for i, block := range blocks[:n] {
+		if receipts[i] != nil && bc.HasBlock(block.Hash(), block.NumberU64(context)) && !rawdb.HasReceipts(bc.db, block.Hash(), block.NumberU64(context)) {
+			rawdb.WriteReceipts(bc.db, block.Hash(), block.NumberU64(context), receipts[i])
+		}
+	}
+	return n, err
+}
+
+// ExportReader decodes the blocks of a chain export. Both hierarchy aware
+// exports and plain streams of blocks are accepted, the latter yielding blocks
+// without receipts or external blocks.
+type ExportReader struct {
+	stream *rlp.Stream
+	header *ExportHeader
+	next   *ExportedBlock // Block decoded while detecting the format
+}
+
+// NewExportReader creates a reader of the export in r.
+func NewExportReader(r io.Reader) (*ExportReader, error) {
+	reader := &ExportReader{stream: rlp.NewStream(r, 0)}
+
+	raw, err := reader.stream.Raw()
+	if err == io.EOF {
+		return reader, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if header, ok := decodeExportHeader(raw); ok {
+		reader.header = header
+		return reader, nil
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(raw, block); err != nil {
+		return nil, fmt.Errorf("unknown export format: %v", err)
+	}
+	reader.next = &ExportedBlock{Block: block}
+	return reader, nil
+}
+
+// decodeExportHeader decodes raw as an export header, if it is one.
+func decodeExportHeader(raw []byte) (*ExportHeader, bool) {
+	header := new(ExportHeader)
+	if err := rlp.DecodeBytes(raw, header); err != nil || header.Magic != exportMagic {
+		return nil, false
+	}
+	return header, true
+}
+
+// Header returns the header of a hierarchy aware export, or nil for a plain
+// stream of blocks.
+func (r *ExportReader) Header() *ExportHeader {
+	return r.header
+}
+
+// Verify checks that the export can be imported into the given chain.
+func (r *ExportReader) Verify(bc *BlockChain) error {
+	if r.header == nil {
+		return nil
+	}
+	if r.header.Version != exportVersion {
+		return fmt.Errorf("unsupported export version %d", r.header.Version)
+	}
+	if r.header.Context != uint64(bc.chainConfig.Context) {
+		return fmt.Errorf("%w: have %d, want %d", ErrExportContext, r.header.Context, bc.chainConfig.Context)
+	}
+	if r.header.Genesis != bc.genesisBlock.Hash() {
+		return fmt.Errorf("%w: have %x, want %x", ErrExportGenesis, r.header.Genesis, bc.genesisBlock.Hash())
+	}
+	return nil
+}
+
+// Next decodes the next exported block, returning io.EOF at the end of the
+// export.
+func (r *ExportReader) Next() (*ExportedBlock, error) {
+	if next := r.next; next != nil {
+		r.next = nil
+		return next, nil
+	}
+	for {
+		if r.header == nil {
+			block := new(types.Block)
+			if err := r.stream.Decode(block); err != nil {
+				return nil, err
+			}
+			return &ExportedBlock{Block: block}, nil
+		}
+		raw, err := r.stream.Raw()
+		if err != nil {
+			return nil, err
+		}
+		// Appending to an export repeats its header
+		if header, ok := decodeExportHeader(raw); ok {
+			if header.Context != r.header.Context || header.Genesis != r.header.Genesis {
+				return nil, errors.New("export concatenates different chains")
+			}
+			continue
+		}
+		exported := new(ExportedBlock)
+		if err := rlp.DecodeBytes(raw, exported); err != nil {
+			return nil, err
+		}
+		return exported, nil
+	}
+}
@@ -61,13 +61,13 @@ func (b *BlockGen) SetCoinbase(addr common.Address) {
 		}
 		panic("coinbase can only be set once")
 	}
-	b.header.Coinbase[types.QuaiNetworkContext] = addr
-	b.gasPool = new(GasPool).AddGas(b.header.GasLimit[types.QuaiNetworkContext])
+	b.header.Coinbase[b.config.Context] = addr
+	b.gasPool = new(GasPool).AddGas(b.header.GasLimit[b.config.Context])
 }
 
 // SetExtra sets the extra data field of the generated block.
 func (b *BlockGen) SetExtra(data []byte) {
-	b.header.Extra[types.QuaiNetworkContext] = data
+	b.header.Extra[b.config.Context] = data
 }
 
 // SetNonce sets the nonce field of the generated block.
@@ -79,7 +79,7 @@ func (b *BlockGen) SetNonce(nonce types.BlockNonce) {
 // useful for Clique tests where the difficulty does not depend on time. For the
 // ethash tests, please use OffsetTime, which implicitly recalculates the diff.
 func (b *BlockGen) SetDifficulty(diff *big.Int) {
-	b.header.Difficulty[types.QuaiNetworkContext] = diff
+	b.header.Difficulty[b.config.Context] = diff
 }
 
 // AddTx adds a transaction to the generated block. If no coinbase has
@@ -107,7 +107,7 @@ func (b *BlockGen) AddTxWithChain(bc *BlockChain, tx *types.Transaction) {
 		b.SetCoinbase(common.Address{})
 	}
 	b.statedb.Prepare(tx.Hash(), len(b.txs))
-	receipt, err := ApplyTransaction(b.config, bc, &b.header.Coinbase[types.QuaiNetworkContext], b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed[types.QuaiNetworkContext], vm.Config{})
+	receipt, err := ApplyTransaction(b.config, bc, &b.header.Coinbase[b.config.Context], b.gasPool, b.statedb, b.header, tx, &b.header.GasUsed[b.config.Context], vm.Config{})
 	if err != nil {
 		panic(err)
 	}
@@ -131,12 +131,12 @@ func (b *BlockGen) AddUncheckedTx(tx *types.Transaction) {
 
 // Number returns the block number of the block being generated.
 func (b *BlockGen) Number() *big.Int {
-	return new(big.Int).Set(b.header.Number[types.QuaiNetworkContext])
+	return new(big.Int).Set(b.header.Number[b.config.Context])
 }
 
 // BaseFee returns the EIP-1559 base fee of the block being generated.
 func (b *BlockGen) BaseFee() *big.Int {
-	return new(big.Int).Set(b.header.BaseFee[types.QuaiNetworkContext])
+	return new(big.Int).Set(b.header.BaseFee[b.config.Context])
 }
 
 // AddUncheckedReceipt forcefully adds a receipts to the block without a
@@ -184,7 +184,7 @@ func (b *BlockGen) OffsetTime(seconds int64) {
 		panic("block time out of range")
 	}
 	chainreader := &fakeChainReader{config: b.config}
-	b.header.Difficulty[types.QuaiNetworkContext] = b.engine.CalcDifficulty(chainreader, b.header.Time, b.parent.Header(), types.QuaiNetworkContext)
+	b.header.Difficulty[b.config.Context] = b.engine.CalcDifficulty(chainreader, b.header.Time, b.parent.Header(), b.config.Context)
 }
 
 // GenerateChain creates a chain of n blocks. The first block's
@@ -204,10 +204,13 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		config = params.TestChainConfig
 	}
 	blocks, receipts := make(types.Blocks, n), make([]types.Receipts, n)
-	chainreader := &fakeChainReader{config: config}
+	chainreader := &fakeChainReader{config: config, headers: make(map[common.Hash]*types.Header)}
 	genblock := func(i int, parent *types.Block, statedb *state.StateDB) (*types.Block, types.Receipts) {
 		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine}
 		location := []byte{1, 1}
+		if len(config.Location) > 0 {
+			location = config.Location
+		}
 		b.header = makeHeader(chainreader, parent, statedb, b.engine, location)
 
 		// Execute any user modifications to the block
@@ -219,7 +222,7 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 			block, _ := b.engine.FinalizeAndAssemble(chainreader, b.header, statedb, b.txs, b.uncles, b.receipts)
 
 			// Write state changes to db
-			root, err := statedb.Commit(config.IsEIP158(b.header.Number[types.QuaiNetworkContext]))
+			root, err := statedb.Commit(config.IsEIP158(b.header.Number[config.Context]))
 			if err != nil {
 				panic(fmt.Sprintf("state write error: %v", err))
 			}
@@ -231,10 +234,11 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		return nil, nil
 	}
 	for i := 0; i < n; i++ {
-		statedb, err := state.New(parent.Root(), state.NewDatabase(db), nil)
+		statedb, err := state.New(parent.Root(config.Context), state.NewDatabase(db), nil)
 		if err != nil {
 			panic(err)
 		}
+		chainreader.headers[parent.Hash()] = parent.Header()
 		block, receipt := genblock(i, parent, statedb)
 		blocks[i] = block
 		receipts[i] = receipt
@@ -301,7 +305,7 @@ func GenerateKnot(config *params.ChainConfig, parent *types.Block, engine consen
 
 	locations := [][]byte{[]byte{1, 1}, []byte{1, 2}, []byte{1, 3}, []byte{2, 1}, []byte{2, 2}, []byte{2, 3}, []byte{3, 1}, []byte{3, 2}, []byte{3, 3}}
 	for i := 0; i < len(locations); i++ {
-		statedb, err := state.New(parent.Root(), state.NewDatabase(db), nil)
+		statedb, err := state.New(parent.Root(config.Context), state.NewDatabase(db), nil)
 		if err != nil {
 			panic(err)
 		}
@@ -320,6 +324,8 @@ func GenerateKnot(config *params.ChainConfig, parent *types.Block, engine consen
 }
 
 func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.StateDB, engine consensus.Engine, location []byte) *types.Header {
+	context := chain.Config().Context
+
 	var time uint64
 	if parent.Time() == 0 {
 		time = 10
@@ -352,14 +358,14 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 
 	parentHeader := parent.Header()
 
-	header.ParentHash[types.QuaiNetworkContext] = parent.Hash()
-	header.Coinbase[types.QuaiNetworkContext] = parent.Coinbase()
-	header.Difficulty[types.QuaiNetworkContext] = engine.CalcDifficulty(chain, time, parentHeader, types.QuaiNetworkContext)
+	header.ParentHash[context] = parent.Hash()
+	header.Coinbase[context] = parent.Coinbase(context)
+	header.Difficulty[context] = engine.CalcDifficulty(chain, time, parentHeader, context)
 
-	header.Number[types.QuaiNetworkContext] = new(big.Int).Add(parent.Number(), common.Big1)
-	if len(parent.Header().Location) > 0 && header.Location[0] == parent.Header().Location[0] {
-		header.Number[types.QuaiNetworkContext+1] = new(big.Int).Add(parent.Header().Number[types.QuaiNetworkContext+1], common.Big1)
-		header.ParentHash[types.QuaiNetworkContext+1] = parent.Hash()
+	header.Number[context] = new(big.Int).Add(parent.Number(context), common.Big1)
+	if context+1 < types.ContextDepth && len(parent.Header().Location) > 0 && header.Location[0] == parent.Header().Location[0] {
+		header.Number[context+1] = new(big.Int).Add(parent.Header().Number[context+1], common.Big1)
+		header.ParentHash[context+1] = parent.Hash()
 	}
 
 	return header
@@ -389,7 +395,8 @@ func GenerateNetworkBlocks(graph [3][3][]*types.BlockGenSpec) (map[string]*types
 }
 
 type fakeChainReader struct {
-	config *params.ChainConfig
+	config  *params.ChainConfig
+	headers map[common.Hash]*types.Header // Parents of the generated blocks, for their rewards
 }
 
 // Config returns the chain configuration.
@@ -397,11 +404,16 @@ func (cr *fakeChainReader) Config() *params.ChainConfig {
 	return cr.config
 }
 
-func (cr *fakeChainReader) CurrentHeader() *types.Header                            { return nil }
-func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header           { return nil }
-func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header          { return nil }
-func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header { return nil }
-func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block   { return nil }
+func (cr *fakeChainReader) CurrentHeader() *types.Header                   { return nil }
+func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header  { return nil }
+func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header { return nil }
+func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := cr.headers[hash]; header != nil && header.Number[cr.config.Context].Uint64() == number {
+		return header
+	}
+	return nil
+}
+func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block { return nil }
 func (cr *fakeChainReader) GetExternalBlock(hash common.Hash, location []byte, context uint64) (*types.ExternalBlock, error) {
 	return nil, nil
 }
@@ -432,10 +444,11 @@ func (cr *fakeChainReader) CheckContext(context int) error {
 
 // CheckLocationRange checks to make sure the range of r and z are valid
 func (cr *fakeChainReader) CheckLocationRange(location []byte) error {
-	if int(location[0]) < 1 || int(location[0]) > params.FullerOntology[0] {
+	ontology := cr.config.OntologyShape()
+	if int(location[0]) < 1 || int(location[0]) > ontology[0] {
 		return errors.New("the provided location is outside the allowable region range")
 	}
-	if int(location[1]) < 1 || int(location[1]) > params.FullerOntology[1] {
+	if int(location[1]) < 1 || int(location[1]) > ontology[1] {
 		return errors.New("the provided location is outside the allowable zone range")
 	}
 	return nil
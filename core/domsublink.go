@@ -0,0 +1,226 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethclient/quaiclient"
+	"github.com/spruce-solutions/go-quai/event"
+)
+
+// DomSubLink is the transport a chain uses to talk to its dominant and
+// subordinate chains.
+type DomSubLink interface {
+	// GetExternalBlock retrieves the block with the given hash and context as an
+	// external block, including its body and receipts.
+	GetExternalBlock(ctx context.Context, hash common.Hash, context int) (*types.ExternalBlock, error)
+
+	// GetBlockStatus returns the write status of the header on the linked chain.
+	GetBlockStatus(ctx context.Context, header *types.Header) WriteStatus
+
+	// HLCRReorg asks the linked chain whether the block wins the HLCR fork choice
+	// and rolls the linked chain back if it does.
+	HLCRReorg(ctx context.Context, block *types.Block) (bool, error)
+
+	// CheckPCRC runs PCRC on the linked chain for the header at the given order.
+	CheckPCRC(ctx context.Context, header *types.Header, order int) (types.PCRCTermini, error)
+
+	// CheckPCCRC runs PCCRC on the linked chain for the header at the given order.
+	CheckPCCRC(ctx context.Context, header *types.Header, order int) (types.PCRCTermini, error)
+
+	// SendMinedBlock delivers a mined block to the linked chain.
+	SendMinedBlock(ctx context.Context, block *types.Block) error
+
+	// GetZoneSupplies returns the supply records of the heads of every zone
+	// under the linked chain.
+	GetZoneSupplies(ctx context.Context) ([]*types.Supply, error)
+
+	// SubscribeNewHead subscribes to head changes of the linked chain.
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (event.Subscription, error)
+
+	// Close releases the resources held by the link.
+	Close()
+}
+
+// clientLink is a DomSubLink backed by a quaiclient, typically connected over
+// a websocket.
+type clientLink struct {
+	client *quaiclient.Client
+}
+
+// NewClientLink creates a DomSubLink on top of an established quaiclient.
+func NewClientLink(client *quaiclient.Client) DomSubLink {
+	return &clientLink{client: client}
+}
+
+// DialDomSubLink connects to the go-quai node at the given url. If secret is
+// not nil, the url must be the authenticated endpoint of the node and every
+// request is authenticated with the secret.
+func DialDomSubLink(rawurl string, secret []byte) (DomSubLink, error) {
+	var (
+		client *quaiclient.Client
+		err    error
+	)
+	if secret != nil {
+		client, err = quaiclient.DialWithAuth(context.Background(), rawurl, secret)
+	} else {
+		client, err = quaiclient.Dial(rawurl)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewClientLink(client), nil
+}
+
+func (l *clientLink) GetExternalBlock(ctx context.Context, hash common.Hash, blockContext int) (*types.ExternalBlock, error) {
+	externalBlock, _ := l.client.GetExternalBlockByHashAndContext(ctx, hash, blockContext)
+	if externalBlock != nil {
+		return externalBlock, nil
+	}
+	// The linked node doesn't cache the block as external, it might be one of
+	// its own blocks though.
+	block, err := l.client.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	receiptBlock, err := l.client.GetBlockReceipts(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewExternalBlockWithHeader(block.Header()).WithBody(block.Transactions(), block.Uncles(), receiptBlock.Receipts(), big.NewInt(int64(blockContext))), nil
+}
+
+func (l *clientLink) GetBlockStatus(ctx context.Context, header *types.Header) WriteStatus {
+	return WriteStatus(l.client.GetBlockStatus(ctx, header))
+}
+
+func (l *clientLink) HLCRReorg(ctx context.Context, block *types.Block) (bool, error) {
+	return l.client.HLCRReorg(ctx, block)
+}
+
+func (l *clientLink) CheckPCRC(ctx context.Context, header *types.Header, order int) (types.PCRCTermini, error) {
+	return l.client.CheckPCRC(ctx, header, order)
+}
+
+func (l *clientLink) CheckPCCRC(ctx context.Context, header *types.Header, order int) (types.PCRCTermini, error) {
+	return l.client.CheckPCCRC(ctx, header, order)
+}
+
+func (l *clientLink) SendMinedBlock(ctx context.Context, block *types.Block) error {
+	return l.client.SendMinedBlock(ctx, block, true, true)
+}
+
+func (l *clientLink) GetZoneSupplies(ctx context.Context) ([]*types.Supply, error) {
+	return l.client.GetZoneSupplies(ctx)
+}
+
+func (l *clientLink) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (event.Subscription, error) {
+	return l.client.SubscribeNewHead(ctx, ch)
+}
+
+func (l *clientLink) Close() {
+	l.client.Close()
+}
+
+// memoryLink is a DomSubLink calling directly into a BlockChain running in
+// the same process.
+type memoryLink struct {
+	bc  *BlockChain
+	mux *event.TypeMux // Optional mux to announce delivered blocks on
+}
+
+// NewMemoryLink creates a DomSubLink to a chain in the same process. If mux is
+// not nil, blocks delivered through the link are announced on it the same way
+// the RPC server does.
+func NewMemoryLink(bc *BlockChain, mux *event.TypeMux) DomSubLink {
+	return &memoryLink{bc: bc, mux: mux}
+}
+
+func (l *memoryLink) GetExternalBlock(ctx context.Context, hash common.Hash, blockContext int) (*types.ExternalBlock, error) {
+	externalBlock, err := l.bc.GetExternalBlockByHashAndContext(hash, blockContext)
+	if err != nil {
+		return nil, err
+	}
+	if externalBlock != nil {
+		return externalBlock, nil
+	}
+	block := l.bc.GetBlockByHash(hash)
+	if block == nil {
+		return nil, ErrExternalBlockNotFound
+	}
+	receipts := l.bc.GetReceiptsByHash(hash)
+	return types.NewExternalBlockWithHeader(block.Header()).WithBody(block.Transactions(), block.Uncles(), receipts, big.NewInt(int64(blockContext))), nil
+}
+
+func (l *memoryLink) GetBlockStatus(ctx context.Context, header *types.Header) WriteStatus {
+	return l.bc.GetBlockStatus(header)
+}
+
+func (l *memoryLink) HLCRReorg(ctx context.Context, block *types.Block) (bool, error) {
+	return l.bc.HLCRReorg(block)
+}
+
+func (l *memoryLink) CheckPCRC(ctx context.Context, header *types.Header, order int) (types.PCRCTermini, error) {
+	return l.bc.PCRC(header, order)
+}
+
+func (l *memoryLink) CheckPCCRC(ctx context.Context, header *types.Header, order int) (types.PCRCTermini, error) {
+	return l.bc.PCCRC(header, order)
+}
+
+func (l *memoryLink) SendMinedBlock(ctx context.Context, block *types.Block) error {
+	if _, err := l.bc.InsertMinedBlock(ctx, block); err != nil {
+		return err
+	}
+	if l.mux != nil {
+		l.mux.Post(NewMinedBlockEvent{Block: block})
+	}
+	return nil
+}
+
+func (l *memoryLink) GetZoneSupplies(ctx context.Context) ([]*types.Supply, error) {
+	return l.bc.ZoneSupplies(ctx)
+}
+
+func (l *memoryLink) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (event.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		heads := make(chan ChainHeadEvent, chainHeadChanSize)
+		sub := l.bc.SubscribeChainHeadEvent(heads)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case head := <-heads:
+				select {
+				case ch <- head.Block.Header():
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func (l *memoryLink) Close() {}
@@ -32,6 +32,30 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrExternalBlockNotFound is returned when an external block can't be found
+	// on a dominant or subordinate chain.
+	ErrExternalBlockNotFound = errors.New("external block not found")
+
+	// ErrPrimeTwist is returned by PCRC when the prime termini found along the
+	// prime, region and zone paths all differ.
+	ErrPrimeTwist = errors.New("there exists a Prime twist (PTP != PTR != PTZ")
+
+	// ErrPrimeRegionTwist is returned by PCRC when the prime terminus found on
+	// any region differs from the one found in the region.
+	ErrPrimeRegionTwist = errors.New("there exists a Prime twist (PRTP != PRTR")
+
+	// ErrRegionTwist is returned by PCRC when the region termini found along
+	// the region and zone paths differ.
+	ErrRegionTwist = errors.New("there exists a Region twist (RTR != RTZ)")
+
+	// ErrSupplyUnknown is returned when the supply of a zone block hasn't been
+	// recorded.
+	ErrSupplyUnknown = errors.New("supply not recorded")
+
+	// ErrUnknownETx is returned when a block applies an ETx which neither its
+	// external blocks carry nor the ETx pool holds.
+	ErrUnknownETx = errors.New("unknown external transaction")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
@@ -95,4 +119,8 @@ var (
 
 	// ErrSenderNoEOA is returned if the sender of a transaction is a contract.
 	ErrSenderInoperable = errors.New("sender is in inoperable state")
+
+	// ErrDestinationInoperable is returned if the recipient of a transaction
+	// belongs to a chain not created yet by its ontology fork.
+	ErrDestinationInoperable = errors.New("destination chain does not exist yet")
 )
@@ -0,0 +1,211 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethdb"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/metrics"
+)
+
+var (
+	pendingETxGauge = metrics.NewRegisteredGauge("etxpool/pending", nil)
+	refundETxGauge  = metrics.NewRegisteredGauge("etxpool/refunds", nil)
+	expiredETxMeter = metrics.NewRegisteredMeter("etxpool/expired", nil)
+	failedETxMeter  = metrics.NewRegisteredMeter("etxpool/failed", nil)
+)
+
+// ETxPool keeps the inbound external transactions of a zone until a canonical
+// block applies, expires or fails them, and the refunds owed to the senders of
+// this zone for the ETxs other zones expired or failed, until a canonical block
+// pays them. Both are persisted in the database together with the failed
+// application attempts of the ETxs, so the pool survives restarts.
+type ETxPool struct {
+	db ethdb.Database
+
+	mu      sync.RWMutex
+	pending map[common.Hash]*types.PendingETx
+	refunds map[common.Hash]*types.ETxRefund
+}
+
+// NewETxPool creates a new external transaction pool, loading any pending ETx
+// and refund previously stored in the database.
+func NewETxPool(db ethdb.Database) *ETxPool {
+	pool := &ETxPool{
+		db:      db,
+		pending: make(map[common.Hash]*types.PendingETx),
+		refunds: make(map[common.Hash]*types.ETxRefund),
+	}
+	for _, etx := range rawdb.ReadAllPendingETxs(db) {
+		pool.pending[etx.Tx.Hash()] = etx
+	}
+	for _, refund := range rawdb.ReadAllETxRefunds(db) {
+		pool.refunds[refund.Hash] = refund
+	}
+	if len(pool.pending) > 0 || len(pool.refunds) > 0 {
+		log.Info("Loaded external transaction pool", "pending", len(pool.pending), "refunds", len(pool.refunds))
+	}
+	pool.updateGauges()
+	return pool
+}
+
+// updateGauges refreshes the pool metrics. The caller must hold the lock.
+func (pool *ETxPool) updateGauges() {
+	pendingETxGauge.Update(int64(len(pool.pending)))
+	refundETxGauge.Update(int64(len(pool.refunds)))
+}
+
+// add inserts an ETx into the pool unless it is already known.
+func (pool *ETxPool) add(etx *types.PendingETx) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	hash := etx.Tx.Hash()
+	if _, ok := pool.pending[hash]; ok {
+		return false
+	}
+	pool.pending[hash] = etx
+	rawdb.WritePendingETx(pool.db, etx)
+	pool.updateGauges()
+	return true
+}
+
+// remove drops an ETx resolved by a canonical block from the pool.
+func (pool *ETxPool) remove(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if _, ok := pool.pending[hash]; !ok {
+		return false
+	}
+	delete(pool.pending, hash)
+	rawdb.DeletePendingETx(pool.db, hash)
+	pool.updateGauges()
+	return true
+}
+
+// expire drops an ETx which expired in a canonical block from the pool.
+func (pool *ETxPool) expire(hash common.Hash) {
+	if pool.remove(hash) {
+		log.Info("External transaction expired", "hash", hash)
+		expiredETxMeter.Mark(1)
+	}
+}
+
+// fail drops an ETx whose call failed in a canonical block from the pool.
+func (pool *ETxPool) fail(hash common.Hash) {
+	if pool.remove(hash) {
+		log.Info("External transaction call failed", "hash", hash)
+		failedETxMeter.Mark(1)
+	}
+}
+
+// Get returns the pending ETx with the given hash, or nil if it isn't pending.
+func (pool *ETxPool) Get(hash common.Hash) *types.PendingETx {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.pending[hash]
+}
+
+// RecordAttempt records a failed attempt to apply a pending ETx. Unknown
+// hashes are ignored.
+func (pool *ETxPool) RecordAttempt(hash common.Hash, err error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	etx, ok := pool.pending[hash]
+	if !ok {
+		return
+	}
+	etx.Attempts++
+	if err != nil {
+		etx.LastError = err.Error()
+	}
+	rawdb.WritePendingETx(pool.db, etx)
+	log.Debug("Failed to apply external transaction", "hash", hash, "attempts", etx.Attempts, "err", err)
+}
+
+// addRefund records a refund owed to a sender of this zone unless it is
+// already known.
+func (pool *ETxPool) addRefund(refund *types.ETxRefund) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if _, ok := pool.refunds[refund.Hash]; ok {
+		return false
+	}
+	pool.refunds[refund.Hash] = refund
+	rawdb.WriteETxRefund(pool.db, refund)
+	pool.updateGauges()
+	return true
+}
+
+// ClaimRefund drops a refund once a canonical block paid it to the sender.
+func (pool *ETxPool) ClaimRefund(hash common.Hash) *types.ETxRefund {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	refund, ok := pool.refunds[hash]
+	if !ok {
+		return nil
+	}
+	delete(pool.refunds, hash)
+	rawdb.DeleteETxRefund(pool.db, hash)
+	pool.updateGauges()
+	return refund
+}
+
+// Content returns the pending ETxs ordered by arrival and the refunds owed to
+// the senders of this zone ordered by hash.
+func (pool *ETxPool) Content() ([]*types.PendingETx, []*types.ETxRefund) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	pending := make([]*types.PendingETx, 0, len(pool.pending))
+	for _, etx := range pool.pending {
+		pending = append(pending, etx)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Arrival != pending[j].Arrival {
+			return pending[i].Arrival < pending[j].Arrival
+		}
+		return pending[i].Tx.Hash().Hex() < pending[j].Tx.Hash().Hex()
+	})
+	refunds := make([]*types.ETxRefund, 0, len(pool.refunds))
+	for _, refund := range pool.refunds {
+		refunds = append(refunds, refund)
+	}
+	sort.Slice(refunds, func(i, j int) bool {
+		return refunds[i].Hash.Hex() < refunds[j].Hash.Hex()
+	})
+	return pending, refunds
+}
+
+// Stats returns the number of pending ETxs and owed refunds.
+func (pool *ETxPool) Stats() (int, int) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return len(pool.pending), len(pool.refunds)
+}
@@ -0,0 +1,326 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/state"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/core/vm"
+	"github.com/spruce-solutions/go-quai/crypto"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// etxTestConfig returns the chain config of the mainnet zone at the location.
+func etxTestConfig(t *testing.T, location []byte) *params.ChainConfig {
+	config, err := params.MainnetOntology.ChainConfig(params.MainnetPrimeChainConfig, location)
+	if err != nil {
+		t.Fatalf("failed to derive chain config: %v", err)
+	}
+	return config
+}
+
+// etxTestKey generates a key whose address belongs to the chain of the config.
+func etxTestKey(t *testing.T, config *params.ChainConfig) (*ecdsa.PrivateKey, common.Address) {
+	idRange := config.ChainIDRange()
+	for {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		if int(addr[0]) >= idRange[0] && int(addr[0]) <= idRange[1] {
+			return key, addr
+		}
+	}
+}
+
+// etxTestTx creates an ETx from the origin to the destination chain, signed
+// the way the origin chain recovers its sender.
+func etxTestTx(t *testing.T, origin, destination *params.ChainConfig, key *ecdsa.PrivateKey, nonce uint64, value int64) *types.Transaction {
+	_, to := etxTestKey(t, destination)
+	return etxTestCall(t, origin, destination, key, nonce, to, nil, params.TxGas, value)
+}
+
+// etxTestCall creates an ETx from the origin to the destination chain carrying
+// calldata, signed the way the origin chain recovers its sender.
+func etxTestCall(t *testing.T, origin, destination *params.ChainConfig, key *ecdsa.PrivateKey, nonce uint64, to common.Address, data []byte, gas uint64, value int64) *types.Transaction {
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   destination.ChainID,
+		Nonce:     nonce,
+		To:        &to,
+		Value:     big.NewInt(value),
+		Gas:       gas,
+		GasFeeCap: big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+		Data:      data,
+	})
+	sig, err := crypto.Sign(types.LatestSigner(origin).Hash(tx).Bytes(), key)
+	if err != nil {
+		t.Fatalf("failed to sign etx: %v", err)
+	}
+	signed, err := tx.WithSignature(types.LatestSignerForChainID(destination.ChainID), sig)
+	if err != nil {
+		t.Fatalf("failed to add etx signature: %v", err)
+	}
+	return signed
+}
+
+// etxTestEVM creates an EVM executing the first block of the chain of the config.
+func etxTestEVM(config *params.ChainConfig, statedb *state.StateDB) *vm.EVM {
+	blockContext := vm.BlockContext{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		GasLimit:    10000000,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(0),
+		Difficulty:  big.NewInt(1),
+		BaseFee:     big.NewInt(0),
+	}
+	return vm.NewEVM(blockContext, vm.TxContext{}, statedb, config, vm.Config{})
+}
+
+// etxTestHeader creates a header at the given prime and zone numbers.
+func etxTestHeader(location []byte, prime, zone int64) *types.Header {
+	header := types.NewEmptyHeader()
+	header.Number = []*big.Int{big.NewInt(prime), big.NewInt(prime), big.NewInt(zone)}
+	header.Location = location
+	return header
+}
+
+// Tests that the pending ETxs, their attempts and the refunds are persisted and
+// reloaded by a new pool.
+func TestETxPoolPersistence(t *testing.T) {
+	var (
+		origin      = etxTestConfig(t, []byte{1, 1})
+		destination = etxTestConfig(t, []byte{1, 2})
+		key, sender = etxTestKey(t, origin)
+		db          = rawdb.NewMemoryDatabase()
+		pool        = NewETxPool(db)
+	)
+	first := &types.PendingETx{Tx: etxTestTx(t, origin, destination, key, 0, 1), Sender: sender, OriginLocation: origin.Location, Arrival: 2}
+	second := &types.PendingETx{Tx: etxTestTx(t, origin, destination, key, 1, 2), Sender: sender, OriginLocation: origin.Location, Arrival: 1}
+	if !pool.add(first) || !pool.add(second) {
+		t.Fatalf("failed to add pending etxs")
+	}
+	if pool.add(first) {
+		t.Fatalf("known etx added twice")
+	}
+	pool.RecordAttempt(first.Tx.Hash(), errors.New("attempt failed"))
+	pool.RecordAttempt(common.Hash{1}, errors.New("unknown"))
+
+	refund := &types.ETxRefund{Hash: common.Hash{2}, Sender: sender, Value: big.NewInt(3), DestinationLocation: destination.Location}
+	if !pool.addRefund(refund) || pool.addRefund(refund) {
+		t.Fatalf("refund not added exactly once")
+	}
+
+	pool = NewETxPool(db)
+	if pending, refunds := pool.Stats(); pending != 2 || refunds != 1 {
+		t.Fatalf("reloaded pool size mismatch: have %d/%d, want %d/%d", pending, refunds, 2, 1)
+	}
+	pending, refunds := pool.Content()
+	if pending[0].Tx.Hash() != second.Tx.Hash() || pending[1].Tx.Hash() != first.Tx.Hash() {
+		t.Errorf("pending etxs not ordered by arrival")
+	}
+	if etx := pool.Get(first.Tx.Hash()); etx.Attempts != 1 || etx.LastError != "attempt failed" {
+		t.Errorf("attempt mismatch: have %d %q, want %d %q", etx.Attempts, etx.LastError, 1, "attempt failed")
+	}
+	if refunds[0].Hash != refund.Hash || refunds[0].Value.Cmp(refund.Value) != 0 {
+		t.Errorf("refund mismatch: have %x %v, want %x %v", refunds[0].Hash, refunds[0].Value, refund.Hash, refund.Value)
+	}
+
+	// Resolved ETxs and paid refunds are dropped from the database too
+	pool.expire(first.Tx.Hash())
+	pool.fail(second.Tx.Hash())
+	if pool.ClaimRefund(refund.Hash) == nil {
+		t.Fatalf("owed refund not claimed")
+	}
+	if pool.ClaimRefund(refund.Hash) != nil {
+		t.Fatalf("refund claimed twice")
+	}
+	pool = NewETxPool(db)
+	if pending, refunds := pool.Stats(); pending != 0 || refunds != 0 {
+		t.Fatalf("resolved pool size mismatch: have %d/%d, want %d/%d", pending, refunds, 0, 0)
+	}
+}
+
+// Tests that ETxs expire once the prime chain advanced by their lifetime.
+func TestETxExpired(t *testing.T) {
+	location := []byte{1, 2}
+	externalBlock := types.NewExternalBlockWithHeader(etxTestHeader([]byte{1, 1}, 10, 100))
+
+	tests := []struct {
+		prime   int64
+		expired bool
+	}{
+		{10, false},
+		{10 + int64(params.ETxLifetime) - 1, false},
+		{10 + int64(params.ETxLifetime), true},
+		{10 + int64(params.ETxLifetime) + 1, true},
+	}
+	for i, tt := range tests {
+		if expired := ETxExpired(etxTestHeader(location, tt.prime, 1), externalBlock); expired != tt.expired {
+			t.Errorf("test %d: expiry mismatch: have %v, want %v", i, expired, tt.expired)
+		}
+	}
+}
+
+// Tests that the origin zone refunds the ETxs its senders sent only if their
+// destination zone failed them.
+func TestETxRefunds(t *testing.T) {
+	var (
+		origin      = etxTestConfig(t, []byte{1, 1})
+		destination = etxTestConfig(t, []byte{1, 2})
+		key, sender = etxTestKey(t, origin)
+		header      = etxTestHeader(origin.Location, 1, 1)
+
+		failed  = etxTestTx(t, origin, destination, key, 0, 1000)
+		applied = etxTestTx(t, origin, destination, key, 1, 2000)
+		missing = etxTestTx(t, origin, destination, key, 2, 4000)
+	)
+	receipts := []*types.Receipt{
+		{TxHash: failed.Hash(), Status: types.ReceiptStatusFailed},
+		{TxHash: applied.Hash(), Status: types.ReceiptStatusSuccessful},
+	}
+	txs := []*types.Transaction{failed, applied, missing}
+	externalBlock := types.NewExternalBlockWithHeader(etxTestHeader(destination.Location, 1, 1)).WithBody(txs, nil, receipts, big.NewInt(int64(params.ZONE)))
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	refunds := ApplyETxRefunds(origin, statedb, header, externalBlock)
+	if len(refunds) != 1 {
+		t.Fatalf("refund count mismatch: have %d, want %d", len(refunds), 1)
+	}
+	if refunds[0].Hash != failed.Hash() || refunds[0].Sender != sender || refunds[0].DestinationBlock != externalBlock.Hash() {
+		t.Errorf("refund mismatch: have %x from %x in %x", refunds[0].Hash, refunds[0].Sender, refunds[0].DestinationBlock)
+	}
+	if balance := statedb.GetBalance(sender); balance.Cmp(failed.Value()) != 0 {
+		t.Errorf("refunded balance mismatch: have %v, want %v", balance, failed.Value())
+	}
+
+	// Neither the destination zone itself nor blocks of other contexts refund
+	if refunds := ETxRefunds(destination, header, externalBlock); len(refunds) != 0 {
+		t.Errorf("destination zone refunded %d etxs", len(refunds))
+	}
+	regionBlock := types.NewExternalBlockWithHeader(etxTestHeader(destination.Location, 1, 1)).WithBody(txs, nil, receipts, big.NewInt(int64(params.REGION)))
+	if refunds := ETxRefunds(origin, header, regionBlock); len(refunds) != 0 {
+		t.Errorf("region block refunded %d etxs", len(refunds))
+	}
+}
+
+// Tests that the destination zone executes the calldata of an ETx, and that a
+// failed call reverts the transfer of the value, which the origin zone refunds.
+func TestExternalCall(t *testing.T) {
+	var (
+		origin      = etxTestConfig(t, []byte{1, 1})
+		destination = etxTestConfig(t, []byte{1, 2})
+		key, sender = etxTestKey(t, origin)
+		_, logger   = etxTestKey(t, destination)
+		_, reverter = etxTestKey(t, destination)
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(logger, []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG0), byte(vm.STOP)})
+	statedb.SetCode(reverter, []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT)})
+
+	apply := func(tx *types.Transaction) *types.Receipt {
+		msg, err := tx.AsMessage(types.MakeSigner(destination, big.NewInt(1)), big.NewInt(0))
+		if err != nil || !msg.FromExternal() {
+			t.Fatalf("etx not recovered as external: %v", err)
+		}
+		var used uint64
+		statedb.Prepare(tx.Hash(), 0)
+		receipt, err := applyExternalCall(msg, destination, new(GasPool).AddGas(10000000), statedb, big.NewInt(1), common.Hash{}, tx, &used, etxTestEVM(destination, statedb))
+		if err != nil {
+			t.Fatalf("failed to apply etx: %v", err)
+		}
+		if receipt.GasUsed != used || receipt.GasUsed == 0 || receipt.GasUsed > tx.Gas() {
+			t.Errorf("gas used mismatch: have %d, counted %d, limit %d", receipt.GasUsed, used, tx.Gas())
+		}
+		return receipt
+	}
+	// A successful call transfers the value and keeps its logs
+	call := etxTestCall(t, origin, destination, key, 0, logger, []byte{1}, 100000, 1000)
+	if receipt := apply(call); receipt.Status != types.ReceiptStatusSuccessful || len(receipt.Logs) != 1 {
+		t.Fatalf("call result mismatch: have status %d with %d logs", receipt.Status, len(receipt.Logs))
+	}
+	if balance := statedb.GetBalance(logger); balance.Cmp(call.Value()) != 0 {
+		t.Errorf("callee balance mismatch: have %v, want %v", balance, call.Value())
+	}
+	// A failed call transfers nothing in the destination zone
+	failed := etxTestCall(t, origin, destination, key, 1, reverter, []byte{1}, 100000, 2000)
+	receipt := apply(failed)
+	if receipt.Status != types.ReceiptStatusFailed || len(receipt.Logs) != 0 {
+		t.Fatalf("failed call result mismatch: have status %d with %d logs", receipt.Status, len(receipt.Logs))
+	}
+	if balance := statedb.GetBalance(reverter); balance.Sign() != 0 {
+		t.Errorf("failed callee balance mismatch: have %v, want 0", balance)
+	}
+	if balance := statedb.GetBalance(sender); balance.Sign() != 0 {
+		t.Errorf("sender credited in the destination zone: have %v", balance)
+	}
+	// The origin zone refunds the value of the failed call only
+	txs := []*types.Transaction{call, failed}
+	receipts := []*types.Receipt{{TxHash: call.Hash(), Status: types.ReceiptStatusSuccessful}, receipt}
+	externalBlock := types.NewExternalBlockWithHeader(etxTestHeader(destination.Location, 1, 1)).WithBody(txs, nil, receipts, big.NewInt(int64(params.ZONE)))
+
+	originState, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	ApplyETxRefunds(origin, originState, etxTestHeader(origin.Location, 1, 1), externalBlock)
+	if balance := originState.GetBalance(sender); balance.Cmp(failed.Value()) != 0 {
+		t.Errorf("refund mismatch: have %v, want %v", balance, failed.Value())
+	}
+}
+
+// Tests that the origin zone charges the full gas limit of an ETx carrying
+// calldata, the budget of its call in the destination zone, but refunds unused
+// gas of plain ETxs and local calls.
+func TestExternalCallGasBudget(t *testing.T) {
+	var (
+		origin      = etxTestConfig(t, []byte{1, 1})
+		destination = etxTestConfig(t, []byte{1, 2})
+		_, sender   = etxTestKey(t, origin)
+		_, local    = etxTestKey(t, origin)
+		_, remote   = etxTestKey(t, destination)
+	)
+	tests := []struct {
+		to   common.Address
+		data []byte
+		used uint64
+	}{
+		{remote, []byte{1, 2, 3}, 100000},
+		{remote, nil, params.TxGas},
+		{local, []byte{1, 2, 3}, params.TxGas + 3*params.TxDataNonZeroGasEIP2028},
+	}
+	for i, tt := range tests {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetBalance(sender, big.NewInt(1000000000))
+
+		msg := types.NewMessage(sender, &tt.to, 0, big.NewInt(1), 100000, big.NewInt(1), big.NewInt(1), big.NewInt(1), tt.data, nil, false)
+		result, err := ApplyMessage(etxTestEVM(origin, statedb), msg, new(GasPool).AddGas(10000000))
+		if err != nil {
+			t.Fatalf("test %d: failed to apply message: %v", i, err)
+		}
+		if result.UsedGas != tt.used {
+			t.Errorf("test %d: gas used mismatch: have %d, want %d", i, result.UsedGas, tt.used)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// GetETxStatus returns the lifecycle status of an external transaction as seen
+// by this chain, or nil if the ETx is unknown.
+func (bc *BlockChain) GetETxStatus(hash common.Hash) *types.ETxStatus {
+	return rawdb.ReadETxStatus(bc.db, hash)
+}
+
+// SubscribeETxStatusEvent registers a subscription of ETxStatusEvent.
+func (bc *BlockChain) SubscribeETxStatusEvent(ch chan<- ETxStatusEvent) event.Subscription {
+	return bc.scope.Track(bc.etxStatusFeed.Subscribe(ch))
+}
+
+// updateETxStatus stores the status if it advances the ETx lifecycle and
+// announces it to the subscribers.
+func (bc *BlockChain) updateETxStatus(status *types.ETxStatus) {
+	prev := rawdb.ReadETxStatus(bc.db, status.Hash)
+	if !status.Advances(prev) {
+		return
+	}
+	status.Inherit(prev)
+	rawdb.WriteETxStatus(bc.db, status)
+	log.Debug("Updated external transaction status", "hash", status.Hash, "stage", status.Stage)
+	bc.etxStatusFeed.Send(ETxStatusEvent{Status: status})
+}
+
+// isOutboundETx reports whether a transaction included locally is destined to
+// an address outside of this chain.
+func (bc *BlockChain) isOutboundETx(tx *types.Transaction) bool {
+	return isExternalAddress(bc.chainConfig, tx.To())
+}
+
+// isExternalAddress reports whether the address belongs to another chain than
+// the configured one.
+func isExternalAddress(config *params.ChainConfig, addr *common.Address) bool {
+	if addr == nil {
+		return false
+	}
+	idRange := config.ChainIDRange()
+	if len(idRange) != 2 {
+		return false
+	}
+	prefix := int(addr.Bytes()[0])
+	return prefix < idRange[0] || prefix > idRange[1]
+}
+
+// isInboundETx reports whether a transaction of an external block is an ETx
+// to be applied on this chain.
+func (bc *BlockChain) isInboundETx(tx *types.Transaction, header *types.Header) bool {
+	_, ok := bc.inboundETxSender(tx, header)
+	return ok
+}
+
+// inboundETxSender returns the sender of a transaction of an external block if
+// it is an ETx to be applied on this chain.
+func (bc *BlockChain) inboundETxSender(tx *types.Transaction, header *types.Header) (common.Address, bool) {
+	context := bc.chainConfig.Context
+	msg, err := tx.AsMessage(types.MakeSigner(bc.chainConfig, header.Number[context]), header.BaseFee[context])
+	if err != nil {
+		return common.Address{}, false
+	}
+	if !msg.FromExternal() || !bc.chainConfig.CheckETxChainID(tx.ChainId(), header.Number[params.PRIME]) {
+		return common.Address{}, false
+	}
+	return msg.From(), true
+}
+
+// ETxSource returns the external block carrying an ETx to this chain which the
+// block with the given header may apply. The ETx is either carried by one of the
+// external blocks of the header or pending in the ETx pool since an earlier block
+// left it out.
+func (bc *BlockChain) ETxSource(tx *types.Transaction, header *types.Header, externalBlocks []*types.ExternalBlock) (*types.ExternalBlock, error) {
+	if !bc.isInboundETx(tx, header) {
+		return nil, ErrUnknownETx
+	}
+	for _, externalBlock := range externalBlocks {
+		for _, etx := range externalBlock.Transactions() {
+			if etx.Hash() == tx.Hash() {
+				return externalBlock, nil
+			}
+		}
+	}
+	etx := bc.etxPool.Get(tx.Hash())
+	if etx == nil {
+		return nil, ErrUnknownETx
+	}
+	return bc.GetExternalBlock(etx.ExternalBlock, etx.OriginLocation, uint64(params.ZONE))
+}
+
+// trackIncludedETxs records the ETxs leaving this chain in a canonical block.
+func (bc *BlockChain) trackIncludedETxs(block *types.Block) {
+	order, err := bc.engine.GetDifficultyOrder(block.Header())
+	if err != nil {
+		return
+	}
+	for _, tx := range block.Transactions() {
+		if !bc.isOutboundETx(tx) {
+			continue
+		}
+		status := &types.ETxStatus{
+			Hash:           tx.Hash(),
+			Stage:          types.ETxIncluded,
+			OriginBlock:    block.Hash(),
+			OriginLocation: block.Header().Location,
+		}
+		// A block coincident with a dominant block is referenced by it as soon
+		// as it is mined.
+		if order < params.ZONE {
+			status.Stage = types.ETxReferenced
+			status.ReferenceContext = uint64(order)
+		}
+		bc.updateETxStatus(status)
+	}
+}
+
+// trackAvailableETxs records the ETxs to this chain carried by an external block
+// and queues them in the ETx pool until they are applied, as well as the outcome
+// of the ETxs sent from this chain which the external block applied.
+func (bc *BlockChain) trackAvailableETxs(externalBlock *types.ExternalBlock) {
+	if externalBlock.Context().Int64() != int64(params.ZONE) {
+		return
+	}
+	order, err := bc.engine.GetDifficultyOrder(externalBlock.Header())
+	if err != nil {
+		order = params.ZONE
+	}
+	header := bc.CurrentBlock().Header()
+	for _, tx := range externalBlock.Transactions() {
+		sender, ok := bc.inboundETxSender(tx, header)
+		if !ok {
+			continue
+		}
+		if status := bc.GetETxStatus(tx.Hash()); status != nil && status.Stage == types.ETxApplied {
+			continue
+		}
+		bc.etxPool.add(&types.PendingETx{
+			Tx:             tx,
+			Sender:         sender,
+			ExternalBlock:  externalBlock.Hash(),
+			OriginLocation: externalBlock.Header().Location,
+			Arrival:        header.Number[bc.chainConfig.Context].Uint64(),
+		})
+		bc.updateETxStatus(&types.ETxStatus{
+			Hash:             tx.Hash(),
+			Stage:            types.ETxAvailable,
+			OriginBlock:      externalBlock.Hash(),
+			OriginLocation:   externalBlock.Header().Location,
+			ReferenceContext: uint64(order),
+		})
+	}
+	for _, refund := range ETxRefunds(bc.chainConfig, header, externalBlock) {
+		if status := bc.GetETxStatus(refund.Hash); status != nil && status.Stage == types.ETxRefunded {
+			continue
+		}
+		bc.etxPool.addRefund(refund)
+		bc.trackRefundedETx(refund, types.ETxFailed)
+	}
+	bc.trackDestinationETxs(externalBlock)
+}
+
+// trackDestinationETxs records the ETxs sent from this chain which were applied
+// by a block of their destination zone, so that the origin zone knows about the
+// whole lifecycle of its ETxs. The failed ones are recorded with their refunds.
+func (bc *BlockChain) trackDestinationETxs(externalBlock *types.ExternalBlock) {
+	if bytes.Equal(externalBlock.Header().Location, bc.chainConfig.Location) {
+		return
+	}
+	for _, tx := range externalBlock.Transactions() {
+		status := bc.GetETxStatus(tx.Hash())
+		if status == nil || !bytes.Equal(status.OriginLocation, bc.chainConfig.Location) {
+			continue
+		}
+		receipt := externalBlock.ReceiptForTransaction(tx)
+		if receipt.TxHash != tx.Hash() || receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		bc.updateETxStatus(&types.ETxStatus{
+			Hash:             tx.Hash(),
+			Stage:            types.ETxApplied,
+			DestinationBlock: externalBlock.Hash(),
+		})
+	}
+}
+
+// trackRefundedETx records an ETx sent from this chain, which failed in the
+// destination block of the refund, as failed until its refund is paid.
+func (bc *BlockChain) trackRefundedETx(refund *types.ETxRefund, stage types.ETxStage) {
+	status := bc.GetETxStatus(refund.Hash)
+	if status == nil {
+		status = &types.ETxStatus{Hash: refund.Hash, OriginLocation: bc.chainConfig.Location}
+	}
+	status.Stage = stage
+	status.DestinationBlock = refund.DestinationBlock
+	status.Error = ""
+	if stage == types.ETxFailed {
+		status.Error = "failed in destination"
+	}
+	bc.updateETxStatus(status)
+}
+
+// trackAppliedETxs records the outcome of the ETxs applied by a canonical block
+// and of the refunds it paid for the ETxs sent from this chain. The ETxs of its
+// external blocks which the block left out stay pending for a retry.
+func (bc *BlockChain) trackAppliedETxs(block *types.Block, receipts types.Receipts, externalBlocks []*types.ExternalBlock) {
+	header := block.Header()
+	applied := make(map[common.Hash]*types.Receipt)
+	for _, receipt := range receipts {
+		applied[receipt.TxHash] = receipt
+	}
+	for _, externalBlock := range externalBlocks {
+		for _, tx := range externalBlock.Transactions() {
+			if _, ok := applied[tx.Hash()]; ok {
+				continue
+			}
+			sender, ok := bc.inboundETxSender(tx, header)
+			if !ok {
+				continue
+			}
+			// Every node queues the ETx here, whether or not it received the
+			// external block before the block referencing it.
+			bc.etxPool.add(&types.PendingETx{
+				Tx:             tx,
+				Sender:         sender,
+				ExternalBlock:  externalBlock.Hash(),
+				OriginLocation: externalBlock.Header().Location,
+				Arrival:        header.Number[bc.chainConfig.Context].Uint64(),
+			})
+			status := &types.ETxStatus{
+				Hash:             tx.Hash(),
+				Stage:            types.ETxFailed,
+				OriginBlock:      externalBlock.Hash(),
+				OriginLocation:   externalBlock.Header().Location,
+				DestinationBlock: block.Hash(),
+				Error:            "not applied",
+			}
+			bc.etxPool.RecordAttempt(tx.Hash(), errors.New(status.Error))
+			bc.updateETxStatus(status)
+		}
+		for _, refund := range ETxRefunds(bc.chainConfig, header, externalBlock) {
+			bc.etxPool.ClaimRefund(refund.Hash)
+			bc.trackRefundedETx(refund, types.ETxRefunded)
+		}
+	}
+	for _, tx := range block.Transactions() {
+		receipt, ok := applied[tx.Hash()]
+		if !ok {
+			continue
+		}
+		externalBlock, err := bc.ETxSource(tx, header, externalBlocks)
+		if err != nil {
+			continue
+		}
+		status := &types.ETxStatus{
+			Hash:             tx.Hash(),
+			Stage:            types.ETxApplied,
+			OriginBlock:      externalBlock.Hash(),
+			OriginLocation:   externalBlock.Header().Location,
+			DestinationBlock: block.Hash(),
+		}
+		switch {
+		case receipt.Status == types.ReceiptStatusFailed && ETxExpired(header, externalBlock):
+			status.Stage = types.ETxFailed
+			status.Error = "expired"
+			bc.etxPool.expire(tx.Hash())
+		case receipt.Status == types.ReceiptStatusFailed:
+			// The call was executed and consumed the ETx, retrying it
+			// would fail the same way.
+			status.Stage = types.ETxFailed
+			status.Error = "execution failed"
+			bc.etxPool.fail(tx.Hash())
+		default:
+			bc.etxPool.remove(tx.Hash())
+		}
+		bc.updateETxStatus(status)
+	}
+}
+
+// ETxPool returns the pool of inbound ETxs waiting to be applied.
+func (bc *BlockChain) ETxPool() *ETxPool {
+	return bc.etxPool
+}
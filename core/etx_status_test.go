@@ -0,0 +1,105 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/VictoriaMetrics/fastcache"
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// Tests that the origin zone learns the outcome of its ETxs from the blocks of
+// their destination zone, and that an applied ETx is final.
+func TestETxStatusDestination(t *testing.T) {
+	var (
+		origin      = etxTestConfig(t, []byte{1, 1})
+		destination = etxTestConfig(t, []byte{1, 2})
+		key, _      = etxTestKey(t, origin)
+		bc          = &BlockChain{db: rawdb.NewMemoryDatabase(), chainConfig: origin}
+
+		applied = etxTestTx(t, origin, destination, key, 0, 1000)
+		failed  = etxTestTx(t, origin, destination, key, 1, 2000)
+	)
+	for _, tx := range []*types.Transaction{applied, failed} {
+		rawdb.WriteETxStatus(bc.db, &types.ETxStatus{
+			Hash:             tx.Hash(),
+			Stage:            types.ETxReferenced,
+			OriginBlock:      common.Hash{1},
+			OriginLocation:   origin.Location,
+			ReferenceContext: uint64(params.REGION),
+		})
+	}
+	receipts := []*types.Receipt{
+		{TxHash: applied.Hash(), Status: types.ReceiptStatusSuccessful},
+		{TxHash: failed.Hash(), Status: types.ReceiptStatusFailed},
+	}
+	externalBlock := types.NewExternalBlockWithHeader(etxTestHeader(destination.Location, 1, 1)).WithBody([]*types.Transaction{applied, failed}, nil, receipts, big.NewInt(int64(params.ZONE)))
+	bc.trackDestinationETxs(externalBlock)
+
+	status := bc.GetETxStatus(applied.Hash())
+	if status.Stage != types.ETxApplied || status.DestinationBlock != externalBlock.Hash() {
+		t.Fatalf("applied status mismatch: have %v in %x, want %v in %x", status.Stage, status.DestinationBlock, types.ETxApplied, externalBlock.Hash())
+	}
+	if status.OriginBlock != (common.Hash{1}) || status.ReferenceContext != uint64(params.REGION) {
+		t.Errorf("origin of applied status lost: have %x referenced in %d", status.OriginBlock, status.ReferenceContext)
+	}
+	// Failed ETxs are left to their refunds
+	if status := bc.GetETxStatus(failed.Hash()); status.Stage != types.ETxReferenced {
+		t.Errorf("failed status mismatch: have %v, want %v", status.Stage, types.ETxReferenced)
+	}
+	bc.trackRefundedETx(&types.ETxRefund{Hash: applied.Hash(), DestinationBlock: common.Hash{2}}, types.ETxFailed)
+	if status := bc.GetETxStatus(applied.Hash()); status.Stage != types.ETxApplied {
+		t.Errorf("applied etx overwritten: have %v, want %v", status.Stage, types.ETxApplied)
+	}
+}
+
+// Tests that a block may only apply the ETxs carried by its external blocks or
+// pending in the ETx pool.
+func TestETxSource(t *testing.T) {
+	var (
+		origin      = etxTestConfig(t, []byte{1, 1})
+		destination = etxTestConfig(t, []byte{1, 2})
+		key, sender = etxTestKey(t, origin)
+		db          = rawdb.NewMemoryDatabase()
+		bc          = &BlockChain{db: db, chainConfig: destination, etxPool: NewETxPool(db), externalBlocks: fastcache.New(1024 * 1024)}
+		header      = etxTestHeader(destination.Location, 1, 1)
+
+		carried = etxTestTx(t, origin, destination, key, 0, 1000)
+		pending = etxTestTx(t, origin, destination, key, 1, 2000)
+		unknown = etxTestTx(t, origin, destination, key, 2, 4000)
+	)
+	current := types.NewExternalBlockWithHeader(etxTestHeader(origin.Location, 1, 2)).WithBody([]*types.Transaction{carried}, nil, nil, big.NewInt(int64(params.ZONE)))
+	earlier := types.NewExternalBlockWithHeader(etxTestHeader(origin.Location, 1, 1)).WithBody([]*types.Transaction{pending}, nil, nil, big.NewInt(int64(params.ZONE)))
+	rawdb.WriteExternalBlock(db, earlier)
+	bc.etxPool.add(&types.PendingETx{Tx: pending, Sender: sender, ExternalBlock: earlier.Hash(), OriginLocation: origin.Location})
+
+	externalBlocks := []*types.ExternalBlock{current}
+	if source, err := bc.ETxSource(carried, header, externalBlocks); err != nil || source.Hash() != current.Hash() {
+		t.Errorf("carried etx source mismatch: have %v, want %x", err, current.Hash())
+	}
+	if source, err := bc.ETxSource(pending, header, externalBlocks); err != nil || source.Hash() != earlier.Hash() {
+		t.Errorf("pending etx source mismatch: have %v, want %x", err, earlier.Hash())
+	}
+	if _, err := bc.ETxSource(unknown, header, externalBlocks); err != ErrUnknownETx {
+		t.Errorf("unknown etx error mismatch: have %v, want %v", err, ErrUnknownETx)
+	}
+}
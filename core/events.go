@@ -54,3 +54,12 @@ type MissingExternalBlock struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ETxStatusEvent is posted when an external transaction advances in its lifecycle.
+type ETxStatusEvent struct{ Status *types.ETxStatus }
+
+// ReorgJournalEvent is posted when a rollup of the chain is journaled.
+type ReorgJournalEvent struct{ Record *types.ReorgRecord }
+
+// FaultProofEvent is posted when a new fault proof is stored.
+type FaultProofEvent struct{ Proof *types.FaultProof }
@@ -35,8 +35,9 @@ type ChainContext interface {
 	GetHeader(common.Hash, uint64) *types.Header
 }
 
-// NewEVMBlockContext creates a new context for use in the EVM.
-func NewEVMBlockContext(header *types.Header, chain ChainContext, author *common.Address) vm.BlockContext {
+// NewEVMBlockContext creates a new context for use in the EVM, reading the
+// fields of the header in the given context.
+func NewEVMBlockContext(header *types.Header, chain ChainContext, author *common.Address, context int) vm.BlockContext {
 	var (
 		beneficiary common.Address
 		baseFee     *big.Int
@@ -49,18 +50,18 @@ func NewEVMBlockContext(header *types.Header, chain ChainContext, author *common
 		beneficiary = *author
 	}
 	if header.BaseFee != nil {
-		baseFee = new(big.Int).Set(header.BaseFee[types.QuaiNetworkContext])
+		baseFee = new(big.Int).Set(header.BaseFee[context])
 	}
 	return vm.BlockContext{
 		CanTransfer: CanTransfer,
 		Transfer:    Transfer,
-		GetHash:     GetHashFn(header, chain),
+		GetHash:     GetHashFn(header, chain, context),
 		Coinbase:    beneficiary,
-		BlockNumber: new(big.Int).Set(header.Number[types.QuaiNetworkContext]),
+		BlockNumber: new(big.Int).Set(header.Number[context]),
 		Time:        new(big.Int).SetUint64(header.Time),
-		Difficulty:  new(big.Int).Set(header.Difficulty[types.QuaiNetworkContext]),
+		Difficulty:  new(big.Int).Set(header.Difficulty[context]),
 		BaseFee:     baseFee,
-		GasLimit:    header.GasLimit[types.QuaiNetworkContext],
+		GasLimit:    header.GasLimit[context],
 	}
 }
 
@@ -72,8 +73,9 @@ func NewEVMTxContext(msg Message) vm.TxContext {
 	}
 }
 
-// GetHashFn returns a GetHashFunc which retrieves header hashes by number
-func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash {
+// GetHashFn returns a GetHashFunc which retrieves header hashes by number in
+// the given context
+func GetHashFn(ref *types.Header, chain ChainContext, context int) func(n uint64) common.Hash {
 	// Cache will initially contain [refHash.parent],
 	// Then fill up with [refHash.p, refHash.pp, refHash.ppp, ...]
 	var cache []common.Hash
@@ -81,23 +83,23 @@ func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash
 	return func(n uint64) common.Hash {
 		// If there's no hash cache yet, make one
 		if len(cache) == 0 {
-			cache = append(cache, ref.ParentHash[types.QuaiNetworkContext])
+			cache = append(cache, ref.ParentHash[context])
 		}
-		if idx := ref.Number[types.QuaiNetworkContext].Uint64() - n - 1; idx < uint64(len(cache)) {
+		if idx := ref.Number[context].Uint64() - n - 1; idx < uint64(len(cache)) {
 			return cache[idx]
 		}
 		// No luck in the cache, but we can start iterating from the last element we already know
 		lastKnownHash := cache[len(cache)-1]
-		lastKnownNumber := ref.Number[types.QuaiNetworkContext].Uint64() - uint64(len(cache))
+		lastKnownNumber := ref.Number[context].Uint64() - uint64(len(cache))
 
 		for {
 			header := chain.GetHeader(lastKnownHash, lastKnownNumber)
 			if header == nil {
 				break
 			}
-			cache = append(cache, header.ParentHash[types.QuaiNetworkContext])
-			lastKnownHash = header.ParentHash[types.QuaiNetworkContext]
-			lastKnownNumber = header.Number[types.QuaiNetworkContext].Uint64() - 1
+			cache = append(cache, header.ParentHash[context])
+			lastKnownHash = header.ParentHash[context]
+			lastKnownNumber = header.Number[context].Uint64() - 1
 			if n == lastKnownNumber {
 				return lastKnownHash
 			}
@@ -0,0 +1,265 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/metrics"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+const (
+	// prefetchHeadChanSize is the size of the channel queueing the new heads
+	// of linked chains to prefetch for. Heads arriving on a full queue are
+	// dropped, the next head covers for them.
+	prefetchHeadChanSize = 64
+
+	// prefetchDepth is the maximum number of external blocks fetched walking
+	// back from the head of a linked chain.
+	prefetchDepth = 64
+
+	// prefetchSeenLimit is the number of recent linked heads remembered so
+	// that a head announced on several links is only prefetched for once.
+	prefetchSeenLimit = 256
+
+	// prefetchRelinkInterval is the interval at which links set after the
+	// chain started, or whose subscription failed, are subscribed to.
+	prefetchRelinkInterval = 5 * time.Second
+)
+
+var (
+	extBlockHitMeter        = metrics.NewRegisteredMeter("chain/extblocks/hits", nil)
+	extBlockMissMeter       = metrics.NewRegisteredMeter("chain/extblocks/misses", nil)
+	extBlockFetchTimer      = metrics.NewRegisteredTimer("chain/extblocks/fetch", nil)
+	extBlockPrefetchMeter   = metrics.NewRegisteredMeter("chain/extblocks/prefetch/fetched", nil)
+	extBlockPrefetchDropped = metrics.NewRegisteredMeter("chain/extblocks/prefetch/dropped", nil)
+)
+
+// linkedHead is a new head announced by a dominant or subordinate chain.
+type linkedHead struct {
+	header  *types.Header
+	context int // Context of the chain announcing the head
+}
+
+// prefetchChain is the chain reader the prefetcher traces branches with. It
+// fetches missing external blocks without accounting them as import misses.
+type prefetchChain struct {
+	*BlockChain
+}
+
+// GetExternalBlock retrieves an external block from the local store, fetching
+// it from the linked chains if missing.
+func (c prefetchChain) GetExternalBlock(hash common.Hash, location []byte, context uint64) (*types.ExternalBlock, error) {
+	if block, _ := c.GetExternalBlockByHashAndContext(hash, int(context)); block != nil {
+		return block, nil
+	}
+	if block := c.fetchExternalBlock(hash, context); block != nil {
+		extBlockPrefetchMeter.Mark(1)
+		return block, nil
+	}
+	return nil, errExtBlockNotFound
+}
+
+// fetchExternalBlock requests an external block from the linked chains and
+// stores it if found.
+func (bc *BlockChain) fetchExternalBlock(hash common.Hash, context uint64) *types.ExternalBlock {
+	start := time.Now()
+	block := bc.requestExternalBlock(hash, context)
+	if block == nil {
+		return nil
+	}
+	extBlockFetchTimer.UpdateSince(start)
+	bc.writeExternalBlock(block, nil)
+	return block
+}
+
+// hasExternalBlock checks whether an external block is cached or stored.
+func (bc *BlockChain) hasExternalBlock(hash common.Hash, context uint64) bool {
+	if bc.externalBlocks.Has(types.ExtBlockCacheKey(context, hash)) {
+		return true
+	}
+	return rawdb.HasExternalBlock(bc.db, hash, context)
+}
+
+// prefetchExternalBlocks follows the heads of the dominant and subordinate
+// chains and stores the external blocks the next local blocks are going to
+// reference, so that importing them doesn't wait on the linked chains.
+func (bc *BlockChain) prefetchExternalBlocks() {
+	defer bc.wg.Done()
+
+	var (
+		heads   = make(chan linkedHead, prefetchHeadChanSize)
+		failed  = make(chan DomSubLink)
+		subs    = make(map[DomSubLink]event.Subscription)
+		seen, _ = lru.New(prefetchSeenLimit)
+	)
+	relink := func() {
+		links := map[DomSubLink]int{}
+		if domLink := bc.getDomLink(); domLink != nil {
+			links[domLink] = bc.chainConfig.Context - 1
+		}
+		for _, link := range bc.getSubLinks() {
+			if link != nil {
+				links[link] = bc.chainConfig.Context + 1
+			}
+		}
+		// Stop following the links replaced since the last relink
+		for link, sub := range subs {
+			if _, ok := links[link]; !ok {
+				sub.Unsubscribe()
+				delete(subs, link)
+			}
+		}
+		for link, context := range links {
+			if _, ok := subs[link]; ok {
+				continue
+			}
+			sub, err := bc.followLink(link, context, heads, failed)
+			if err != nil {
+				log.Debug("Failed to follow linked chain", "context", context, "err", err)
+				continue
+			}
+			subs[link] = sub
+		}
+	}
+	relink()
+
+	ticker := time.NewTicker(prefetchRelinkInterval)
+	defer ticker.Stop()
+	defer func() {
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+	}()
+	for {
+		select {
+		case head := <-heads:
+			hash := head.header.Hash()
+			if seen.Contains(hash) {
+				continue
+			}
+			seen.Add(hash, struct{}{})
+			bc.prefetchForHead(head)
+
+		case link := <-failed:
+			delete(subs, link)
+
+		case <-ticker.C:
+			relink()
+
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// followLink subscribes to the new heads of a linked chain, forwarding them to
+// the heads channel. The link is reported on failed if the subscription fails.
+func (bc *BlockChain) followLink(link DomSubLink, linkContext int, heads chan<- linkedHead, failed chan<- DomSubLink) (event.Subscription, error) {
+	ch := make(chan *types.Header, prefetchHeadChanSize)
+	sub, err := link.SubscribeNewHead(context.Background(), ch)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			select {
+			case header := <-ch:
+				select {
+				case heads <- linkedHead{header: header, context: linkContext}:
+				default:
+					extBlockPrefetchDropped.Mark(1)
+				}
+			case err := <-sub.Err():
+				if err != nil {
+					log.Debug("Linked chain subscription failed", "context", linkContext, "err", err)
+				}
+				select {
+				case failed <- link:
+				case <-bc.quit:
+				}
+				return
+			case <-bc.quit:
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// prefetchForHead stores the external blocks a new head of a linked chain
+// makes the next local blocks reference. A head coincident with the local
+// chain will be imported itself, so the branches it links are traced. Any
+// other head is an external block, which is fetched along with its ancestors
+// up to the first one already known.
+func (bc *BlockChain) prefetchForHead(head linkedHead) {
+	var (
+		header   = head.header
+		context  = bc.chainConfig.Context
+		location = bc.chainConfig.Location
+	)
+	order, err := bc.engine.GetDifficultyOrder(header)
+	if err != nil {
+		return
+	}
+	if order <= context && bc.inSlice(header) {
+		if context == params.ZONE && order == params.ZONE {
+			return
+		}
+		if _, err := bc.engine.TraceBranches(prefetchChain{bc}, header, order, context, location); err != nil {
+			log.Debug("Failed to prefetch linked external blocks", "hash", header.Hash(), "err", err)
+		}
+		return
+	}
+	hash := header.Hash()
+	for i := 0; i < prefetchDepth; i++ {
+		if bc.hasExternalBlock(hash, uint64(head.context)) || bc.GetHeaderByHash(hash) != nil {
+			return
+		}
+		block := bc.fetchExternalBlock(hash, uint64(head.context))
+		if block == nil {
+			log.Debug("Failed to prefetch external block", "hash", hash, "context", head.context)
+			return
+		}
+		extBlockPrefetchMeter.Mark(1)
+
+		extHeader := block.Header()
+		if extHeader.Number[head.context].Uint64() <= 1 {
+			return
+		}
+		hash = extHeader.ParentHash[head.context]
+	}
+}
+
+// inSlice checks whether a header was mined in the slice of the local chain.
+func (bc *BlockChain) inSlice(header *types.Header) bool {
+	location := bc.chainConfig.Location
+	for i := 0; i < bc.chainConfig.Context && i < len(location); i++ {
+		if i >= len(header.Location) || header.Location[i] != location[i] {
+			return false
+		}
+	}
+	return true
+}
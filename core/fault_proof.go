@@ -0,0 +1,357 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/metrics"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+var (
+	// ErrKnownFaultProof is returned when adding a fault proof already stored.
+	ErrKnownFaultProof = errors.New("fault proof already known")
+
+	// ErrInvalidFaultProof is returned when a fault proof doesn't prove a fault.
+	ErrInvalidFaultProof = errors.New("invalid fault proof")
+
+	// ErrFaultProofLimit is returned when adding fault proofs faster than they
+	// are stored.
+	ErrFaultProofLimit = errors.New("fault proof rate limit exceeded")
+
+	faultProofMeter = metrics.NewRegisteredMeter("chain/faults", nil)
+)
+
+const (
+	// faultProofRate is the number of fault proofs per second stored from
+	// peers and RPC, and faultProofBurst the number stored at once.
+	faultProofRate  = 1
+	faultProofBurst = 16
+)
+
+// VerifyFaultProof checks that a fault proof of the chain proves its offender
+// invalid. The offender must extend a known block of the chain with the
+// difficulty the chain demands of it, and the headers of the paths traced in
+// other contexts must declare at least the minimum difficulty of those, so
+// that cheaply sealed headers can't forge evidence. Proofs of offenders whose
+// parent isn't known yet fail with consensus.ErrUnknownAncestor.
+func VerifyFaultProof(chain consensus.ChainHeaderReader, engine consensus.Engine, proof *types.FaultProof) error {
+	offender := proof.Offender
+	if offender == nil || !hasContext(offender, int(proof.Context)) || offender.Number[proof.Context].Sign() == 0 {
+		return fmt.Errorf("%w: missing offender", ErrInvalidFaultProof)
+	}
+	if context := chain.Config().Context; proof.Context != uint64(context) {
+		return fmt.Errorf("%w: offender of context %d, not %d", ErrInvalidFaultProof, proof.Context, context)
+	}
+	if err := engine.VerifyHeader(chain, offender, true); err != nil {
+		if errors.Is(err, consensus.ErrUnknownAncestor) || errors.Is(err, consensus.ErrFutureBlock) {
+			return err
+		}
+		return fmt.Errorf("%w: offender: %v", ErrInvalidFaultProof, err)
+	}
+	if _, err := engine.GetDifficultyOrder(offender); err != nil {
+		return fmt.Errorf("%w: offender: %v", ErrInvalidFaultProof, err)
+	}
+	if len(proof.Termini) != len(proof.Paths) || len(proof.PathContext) != len(proof.Paths) {
+		return fmt.Errorf("%w: %d termini and %d contexts for %d paths", ErrInvalidFaultProof, len(proof.Termini), len(proof.PathContext), len(proof.Paths))
+	}
+	for i, path := range proof.Paths {
+		if proof.PathContext[i] == proof.Context {
+			continue // linked to the verified offender
+		}
+		for j, header := range path {
+			if header != nil && !hasMinimumDifficulty(header) {
+				return fmt.Errorf("%w: path %d header %d below minimum difficulty", ErrInvalidFaultProof, i, j)
+			}
+		}
+	}
+	switch proof.Kind {
+	case types.FaultTwist:
+		return verifyTwist(engine, proof)
+	case types.FaultCollision:
+		return verifyCollision(engine, proof)
+	default:
+		return fmt.Errorf("%w: unknown kind %d", ErrInvalidFaultProof, proof.Kind)
+	}
+}
+
+// verifyTwist checks that every path of a twist proof reaches the terminus it
+// claims, and that the termini all differ.
+func verifyTwist(engine consensus.Engine, proof *types.FaultProof) error {
+	if len(proof.Paths) < 2 {
+		return fmt.Errorf("%w: twist needs two paths", ErrInvalidFaultProof)
+	}
+	for i, path := range proof.Paths {
+		context := proof.PathContext[i]
+		if context < proof.Order || context >= uint64(types.ContextDepth) {
+			return fmt.Errorf("%w: path %d traces context %d for order %d", ErrInvalidFaultProof, i, context, proof.Order)
+		}
+		terminus, err := traceFaultPath(engine, proof, int(context), path)
+		if err != nil {
+			return fmt.Errorf("%w: path %d: %v", ErrInvalidFaultProof, i, err)
+		}
+		if terminus != proof.Termini[i] {
+			return fmt.Errorf("%w: path %d reaches %x, not %x", ErrInvalidFaultProof, i, terminus, proof.Termini[i])
+		}
+		for j := 0; j < i; j++ {
+			if proof.Termini[j] == terminus {
+				return fmt.Errorf("%w: paths %d and %d reach the same terminus", ErrInvalidFaultProof, j, i)
+			}
+		}
+	}
+	return nil
+}
+
+// traceFaultPath retraces the search for the previous coincident block of the
+// proof's order from the offender along a context, as PCRC does, returning
+// the terminus the path proves.
+func traceFaultPath(engine consensus.Engine, proof *types.FaultProof, context int, path []*types.Header) (common.Hash, error) {
+	current := proof.Offender
+	for i := 0; ; i++ {
+		if !hasContext(current, context) {
+			return common.Hash{}, fmt.Errorf("header %d lacks context %d", i, context)
+		}
+		// The first block of a context references the genesis as terminus
+		if current.Number[context].Cmp(common.Big1) == 0 {
+			if i != len(path) {
+				return common.Hash{}, errors.New("path continues past the genesis")
+			}
+			return current.ParentHash[context], nil
+		}
+		if i == len(path) {
+			return common.Hash{}, errors.New("path ends before the terminus")
+		}
+		next := path[i]
+		if next == nil || next.Hash() != current.ParentHash[context] {
+			return common.Hash{}, fmt.Errorf("header %d is not the parent of its predecessor", i)
+		}
+		order, err := engine.GetDifficultyOrder(next)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("header %d: %v", i, err)
+		}
+		current = next
+		if order <= int(proof.Order) && sameSlice(current.Location, proof.Offender.Location, proof.MatchRegion) {
+			if i != len(path)-1 {
+				return common.Hash{}, errors.New("path continues past the terminus")
+			}
+			return current.Hash(), nil
+		}
+	}
+}
+
+// verifyCollision checks that the colliding header of a collision proof
+// references a different dominant block for the offender's location at or
+// after its height.
+func verifyCollision(engine consensus.Engine, proof *types.FaultProof) error {
+	if len(proof.Paths) != 1 || len(proof.Paths[0]) != 1 || proof.Paths[0][0] == nil {
+		return fmt.Errorf("%w: collision needs a single colliding header", ErrInvalidFaultProof)
+	}
+	offender, colliding := proof.Offender, proof.Paths[0][0]
+	if proof.Termini[0] != colliding.Hash() || proof.PathContext[0] != proof.Order {
+		return fmt.Errorf("%w: colliding header mismatch", ErrInvalidFaultProof)
+	}
+	if _, err := engine.GetDifficultyOrder(colliding); err != nil {
+		return fmt.Errorf("%w: colliding header: %v", ErrInvalidFaultProof, err)
+	}
+	if !hasContext(colliding, int(proof.Context)) || !hasContext(offender, int(proof.Context)) {
+		return fmt.Errorf("%w: colliding header lacks context %d", ErrInvalidFaultProof, proof.Context)
+	}
+	if !collides(offender, colliding, int(proof.Context), int(proof.Order)) {
+		return fmt.Errorf("%w: headers don't collide", ErrInvalidFaultProof)
+	}
+	return nil
+}
+
+// collides reports whether an external block of a dominant context collides
+// with a header of the given context, referencing a different dominant block
+// for the same location at or after the header's height.
+func collides(header, extHeader *types.Header, context, extContext int) bool {
+	return bytes.Equal(extHeader.Location, header.Location) && extContext < context &&
+		extHeader.Number[context].Cmp(header.Number[context]) >= 0 &&
+		extHeader.Number[extContext].Cmp(header.Number[extContext]) != 0
+}
+
+// hasContext reports whether the header carries a number and parent for the
+// context.
+func hasContext(header *types.Header, context int) bool {
+	return context >= 0 && context < len(header.Number) && context < len(header.ParentHash) && header.Number[context] != nil
+}
+
+// hasMinimumDifficulty reports whether the header declares at least the
+// minimum difficulty of every context.
+func hasMinimumDifficulty(header *types.Header) bool {
+	if len(header.Difficulty) < len(params.MinimumDifficulty) {
+		return false
+	}
+	for context, minimum := range params.MinimumDifficulty {
+		if header.Difficulty[context] == nil || header.Difficulty[context].Cmp(minimum) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sameSlice reports whether two locations are the same, or only in the same
+// region if matchRegion is set.
+func sameSlice(a, b []byte, matchRegion bool) bool {
+	if matchRegion {
+		return len(a) > 0 && len(b) > 0 && a[0] == b[0]
+	}
+	return bytes.Equal(a, b)
+}
+
+// twistProof builds the proof that tracing the previous coincident block of
+// the given order from the header along the given contexts reaches different
+// termini. It returns nil if the local chain and external blocks don't hold
+// the paths or the termini they prove don't all differ.
+func (bc *BlockChain) twistProof(header *types.Header, order int, matchRegion bool, contexts ...int) *types.FaultProof {
+	proof := &types.FaultProof{
+		Kind:        types.FaultTwist,
+		Context:     uint64(bc.chainConfig.Context),
+		Order:       uint64(order),
+		MatchRegion: matchRegion,
+		Offender:    types.CopyHeader(header),
+	}
+	for _, context := range contexts {
+		path, terminus, err := bc.traceLocalPath(header, order, context, matchRegion)
+		if err != nil {
+			log.Debug("Failed to trace twisted path", "hash", header.Hash(), "context", context, "err", err)
+			return nil
+		}
+		proof.Paths = append(proof.Paths, path)
+		proof.Termini = append(proof.Termini, terminus)
+		proof.PathContext = append(proof.PathContext, uint64(context))
+	}
+	if err := VerifyFaultProof(bc, bc.engine, proof); err != nil {
+		log.Debug("Twisted paths don't prove a fault", "hash", header.Hash(), "err", err)
+		return nil
+	}
+	return proof
+}
+
+// traceLocalPath collects the headers proving the previous coincident block of
+// an order along a context, reading the chain itself or the external blocks
+// of other contexts.
+func (bc *BlockChain) traceLocalPath(header *types.Header, order, context int, matchRegion bool) ([]*types.Header, common.Hash, error) {
+	var path []*types.Header
+	current := header
+	for {
+		if current.Number[context].Cmp(common.Big1) <= 0 {
+			return path, current.ParentHash[context], nil
+		}
+		var parent *types.Header
+		if context == bc.chainConfig.Context {
+			parent = bc.GetHeaderByHash(current.ParentHash[context])
+		} else if extBlock, err := bc.GetExternalBlock(current.ParentHash[context], current.Location, uint64(context)); err == nil && extBlock != nil {
+			parent = extBlock.Header()
+		}
+		if parent == nil {
+			return nil, common.Hash{}, fmt.Errorf("missing parent %x", current.ParentHash[context])
+		}
+		path = append(path, parent)
+		current = parent
+
+		parentOrder, err := bc.engine.GetDifficultyOrder(current)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		if parentOrder <= order && sameSlice(current.Location, header.Location, matchRegion) {
+			return path, current.Hash(), nil
+		}
+	}
+}
+
+// collisionProof builds the proof that an external block collides with the
+// header.
+func (bc *BlockChain) collisionProof(header *types.Header, extBlock *types.ExternalBlock) *types.FaultProof {
+	extHeader := extBlock.Header()
+	return &types.FaultProof{
+		Kind:        types.FaultCollision,
+		Context:     uint64(bc.chainConfig.Context),
+		Order:       extBlock.Context().Uint64(),
+		Offender:    types.CopyHeader(header),
+		Termini:     []common.Hash{extHeader.Hash()},
+		PathContext: []uint64{extBlock.Context().Uint64()},
+		Paths:       [][]*types.Header{{extHeader}},
+	}
+}
+
+// reportFault stores a fault proof found locally, to be gossiped to peers.
+func (bc *BlockChain) reportFault(proof *types.FaultProof) {
+	if proof == nil {
+		return
+	}
+	if err := bc.addFaultProof(proof, true); err != nil && !errors.Is(err, ErrKnownFaultProof) {
+		log.Warn("Discarded local fault proof", "offender", proof.Offender.Hash(), "err", err)
+	}
+}
+
+// AddFaultProof verifies a fault proof received from a peer or over RPC and
+// stores it if it is new, announcing it to the subscribers. Proofs are stored
+// at a limited rate, failing with ErrFaultProofLimit beyond it.
+func (bc *BlockChain) AddFaultProof(proof *types.FaultProof) error {
+	return bc.addFaultProof(proof, false)
+}
+
+// addFaultProof verifies and stores a fault proof, rate limiting those not
+// found locally.
+func (bc *BlockChain) addFaultProof(proof *types.FaultProof, local bool) error {
+	hash := proof.Hash()
+	if rawdb.HasFaultProof(bc.db, hash) {
+		return ErrKnownFaultProof
+	}
+	if err := VerifyFaultProof(bc, bc.engine, proof); err != nil {
+		return err
+	}
+	if !local && !bc.faultLimiter.Allow() {
+		return ErrFaultProofLimit
+	}
+	rawdb.WriteFaultProof(bc.db, proof)
+	faultProofMeter.Mark(1)
+
+	var miner common.Address
+	if int(proof.Context) < len(proof.Offender.Coinbase) {
+		miner = proof.Offender.Coinbase[proof.Context]
+	}
+	log.Warn("Proved block faulty", "kind", proof.Kind, "offender", proof.Offender.Hash(), "number", proof.Offender.Number, "location", proof.Offender.Location, "miner", miner, "proof", hash)
+	bc.faultProofFeed.Send(FaultProofEvent{Proof: proof})
+	return nil
+}
+
+// FaultProofs returns every stored fault proof.
+func (bc *BlockChain) FaultProofs() []*types.FaultProof {
+	return rawdb.ReadAllFaultProofs(bc.db)
+}
+
+// HasFaultProof checks whether the fault proof with the given hash is stored.
+func (bc *BlockChain) HasFaultProof(hash common.Hash) bool {
+	return rawdb.HasFaultProof(bc.db, hash)
+}
+
+// SubscribeFaultProofEvent registers a subscription of FaultProofEvent.
+func (bc *BlockChain) SubscribeFaultProofEvent(ch chan<- FaultProofEvent) event.Subscription {
+	return bc.scope.Track(bc.faultProofFeed.Subscribe(ch))
+}
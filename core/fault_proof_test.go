@@ -0,0 +1,112 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// faultTestEngine verifies offenders with a fixed outcome and gives every
+// header the region order.
+type faultTestEngine struct {
+	consensus.Engine
+	verifyErr error
+}
+
+func (e *faultTestEngine) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
+	return e.verifyErr
+}
+
+func (e *faultTestEngine) GetDifficultyOrder(header *types.Header) (int, error) {
+	return params.REGION, nil
+}
+
+// faultTestChain is a chain reader of the given context.
+type faultTestChain struct {
+	consensus.ChainHeaderReader
+	config *params.ChainConfig
+}
+
+func (c *faultTestChain) Config() *params.ChainConfig { return c.config }
+
+// faultTestProof creates a twist proof of a zone block whose region path holds
+// a single header of the given difficulty, reaching another terminus than its
+// zone path.
+func faultTestProof(difficulty *big.Int) *types.FaultProof {
+	terminus := types.NewEmptyHeader()
+	terminus.Number = []*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(1)}
+	terminus.Difficulty = []*big.Int{difficulty, difficulty, difficulty}
+
+	offender := types.NewEmptyHeader()
+	offender.Number = []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(1)}
+	offender.ParentHash[params.REGION] = terminus.Hash()
+	offender.ParentHash[params.ZONE] = common.Hash{0x01}
+
+	return &types.FaultProof{
+		Kind:        types.FaultTwist,
+		Context:     uint64(params.ZONE),
+		Order:       uint64(params.REGION),
+		Offender:    offender,
+		Termini:     []common.Hash{terminus.Hash(), {0x01}},
+		PathContext: []uint64{uint64(params.REGION), uint64(params.ZONE)},
+		Paths:       [][]*types.Header{{terminus}, {}},
+	}
+}
+
+// Tests that fault proofs are only accepted for offenders extending the known
+// chain as it demands, with paths of other contexts at the minimum difficulty.
+func TestVerifyFaultProof(t *testing.T) {
+	var (
+		zone     = &faultTestChain{config: &params.ChainConfig{Context: params.ZONE}}
+		region   = &faultTestChain{config: &params.ChainConfig{Context: params.REGION}}
+		minimum  = new(big.Int).Set(params.MinimumDifficulty[params.PRIME])
+		tooLow   = big.NewInt(1)
+		errDiff  = errors.New("invalid difficulty")
+		verified = &faultTestEngine{}
+	)
+	tests := []struct {
+		chain   consensus.ChainHeaderReader
+		engine  *faultTestEngine
+		proof   *types.FaultProof
+		want    error
+		invalid bool
+	}{
+		{zone, verified, faultTestProof(minimum), nil, false},
+		{zone, verified, faultTestProof(tooLow), ErrInvalidFaultProof, true},
+		{region, verified, faultTestProof(minimum), ErrInvalidFaultProof, true},
+		{zone, &faultTestEngine{verifyErr: consensus.ErrUnknownAncestor}, faultTestProof(minimum), consensus.ErrUnknownAncestor, false},
+		{zone, &faultTestEngine{verifyErr: errDiff}, faultTestProof(minimum), ErrInvalidFaultProof, true},
+	}
+	for i, tt := range tests {
+		err := VerifyFaultProof(tt.chain, tt.engine, tt.proof)
+		if tt.want == nil && err != nil {
+			t.Errorf("test %d: valid proof rejected: %v", i, err)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+		if invalid := errors.Is(err, ErrInvalidFaultProof); invalid != tt.invalid {
+			t.Errorf("test %d: invalidity mismatch: have %v, want %v", i, invalid, tt.invalid)
+		}
+	}
+}
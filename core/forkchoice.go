@@ -17,14 +17,12 @@
 package core
 
 import (
-	crand "crypto/rand"
 	"errors"
 	"fmt"
 	"math/big"
-	mrand "math/rand"
 
 	"github.com/spruce-solutions/go-quai/common"
-	"github.com/spruce-solutions/go-quai/common/math"
+	"github.com/spruce-solutions/go-quai/core/forkchoice"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/log"
 	"github.com/spruce-solutions/go-quai/params"
@@ -46,6 +44,12 @@ type ChainReader interface {
 	// GetBlockByHash retrieves a block from the database by hash, caching it if found.
 	GetBlockByHash(hash common.Hash) *types.Block
 
+	// GetHeader retrieves a block header from the database by hash and number.
+	GetHeader(hash common.Hash, number uint64) *types.Header
+
+	// GetCanonicalHash returns the canonical hash for a given block number.
+	GetCanonicalHash(number uint64) common.Hash
+
 	// HLCR does hierarchical comparison of two difficulty tuples and returns true if second tuple is greater than the first
 	HLCR(localDifficulties []*big.Int, externDifficulties []*big.Int) bool
 
@@ -59,14 +63,12 @@ type ChainReader interface {
 	GetDifficultyOrder(header *types.Header) (int, error)
 }
 
-// ForkChoice is the fork chooser based on the highest total difficulty of the
-// chain(the fork choice used in the eth1) and the external fork choice (the fork
-// choice used in the eth2). This main goal of this ForkChoice is not only for
-// offering fork choice during the eth1/2 merge phase, but also keep the compatibility
-// for all other proof-of-work networks.
+// ForkChoice chooses the head of the chain between the local head and an
+// external header by the hierarchical total difficulties of both, applying a
+// configurable fork choice rule.
 type ForkChoice struct {
 	chain ChainReader
-	rand  *mrand.Rand
+	rule  forkchoice.Rule
 
 	// preserve is a helper function used in td fork choice.
 	// Miners will prefer to choose the local mined block if the
@@ -75,31 +77,32 @@ type ForkChoice struct {
 	preserve func(header *types.Header) bool
 }
 
+// NewForkChoice creates a fork chooser applying the HLCR rule.
 func NewForkChoice(chainReader ChainReader, preserve func(header *types.Header) bool) *ForkChoice {
-	// Seed a fast but crypto originating random generator
-	seed, err := crand.Int(crand.Reader, big.NewInt(math.MaxInt64))
-	if err != nil {
-		log.Crit("Failed to initialize random seed", "err", err)
-	}
 	return &ForkChoice{
 		chain:    chainReader,
-		rand:     mrand.New(mrand.NewSource(seed.Int64())),
+		rule:     new(forkchoice.HLCR),
 		preserve: preserve,
 	}
 }
 
+// SetRule replaces the fork choice rule.
+func (f *ForkChoice) SetRule(rule forkchoice.Rule) {
+	f.rule = rule
+}
+
 // ReorgNeeded returns whether the reorg should be applied
 // based on the given external header and local canonical chain.
-// In the td mode, the new head is chosen if the corresponding
-// total difficulty is higher. In the extern mode, the trusted
-// header is always selected as the head.
+// The fork choice rule decides between both by their total
+// difficulties, unless they tie and only one was mined locally.
 func (f *ForkChoice) ReorgNeeded(current *types.Header, header *types.Header) (bool, error) {
 
 	if current == nil || header == nil {
 		return false, errors.New("reorg beeing calculated on nil header")
 	}
 
-	localTd := f.chain.GetTd(current.Hash(), current.Number[types.QuaiNetworkContext].Uint64())
+	context := f.chain.Config().Context
+	localTd := f.chain.GetTd(current.Hash(), current.Number[context].Uint64())
 
 	fmt.Println("calctd from forker")
 	externTd, err := f.chain.CalcTd(header)
@@ -111,23 +114,16 @@ func (f *ForkChoice) ReorgNeeded(current *types.Header, header *types.Header) (b
 		return false, errors.New("missing td")
 	}
 
-	// If the total difficulty is higher than our known, add it to the canonical chain
-	// Second clause in the if statement reduces the vulnerability to selfish mining.
-	// Please refer to http://www.cs.cornell.edu/~ie53/publications/btcProcFC.pdf
-	reorg := f.chain.HLCR(localTd, externTd)
-	equalTd := externTd[0].Cmp(localTd[0]) == 0 && externTd[1].Cmp(localTd[1]) == 0 && externTd[2].Cmp(localTd[2]) == 0
-	if !reorg && equalTd {
-		number, headNumber := header.Number[types.QuaiNetworkContext].Uint64(), current.Number[types.QuaiNetworkContext].Uint64()
-		if number < headNumber {
-			reorg = true
-		} else if number == headNumber {
-			var currentPreserve, externPreserve bool
-			if f.preserve != nil {
-				currentPreserve, externPreserve = f.preserve(current), f.preserve(header)
-			}
-			reorg = !currentPreserve && (externPreserve || f.rand.Float64() < 0.5)
+	local := &forkchoice.Head{Hash: current.Hash(), Number: current.Number[context].Uint64(), Td: localTd}
+	extern := &forkchoice.Head{Hash: header.Hash(), Number: header.Number[context].Uint64(), Td: externTd}
+
+	// Miners keep their own block over an equally heavy one of the same height
+	if f.preserve != nil && local.Number == extern.Number && forkchoice.Compare(localTd, externTd) == 0 {
+		if currentPreserve, externPreserve := f.preserve(current), f.preserve(header); currentPreserve != externPreserve {
+			return externPreserve, nil
 		}
 	}
+	reorg := f.rule.Prefer(local, extern, f.commonAncestor(current, header))
 
 	// if reorg && types.QuaiNetworkContext != params.PRIME {
 	// 	domReorg, err := f.chain.DomReorgNeeded(header)
@@ -141,6 +137,34 @@ func (f *ForkChoice) ReorgNeeded(current *types.Header, header *types.Header) (b
 	return reorg, nil
 }
 
+// commonAncestor returns the number of the last block the chain of the header
+// shares with the canonical chain, whose head is current. The header's chain is
+// walked back only until it joins the canonical chain, or until it is deeper
+// than the finality depth of the rule, in which case the number reached is
+// returned as the bound the ancestor is beyond. It is zero if the ancestry of
+// the header is unknown.
+func (f *ForkChoice) commonAncestor(current, header *types.Header) uint64 {
+	var (
+		context = f.chain.Config().Context
+		head    = current.Number[context].Uint64()
+		depth   uint64
+	)
+	if rule, ok := f.rule.(*forkchoice.HLCR); ok {
+		depth = rule.FinalityDepth
+	}
+	for header != nil {
+		number := header.Number[context].Uint64()
+		if number <= head && f.chain.GetCanonicalHash(number) == header.Hash() {
+			return number
+		}
+		if number == 0 || (depth > 0 && number+depth < head) {
+			return number
+		}
+		header = f.chain.GetHeader(header.ParentHash[context], number-1)
+	}
+	return 0
+}
+
 func (f *ForkChoice) UntwistAndTrim(header *types.Header) error {
 	headerOrder, err := f.chain.GetDifficultyOrder(header)
 	if err != nil {
@@ -0,0 +1,86 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package forkchoice implements the rules choosing the head of a chain in the
+// Quai hierarchy among competing forks.
+package forkchoice
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/spruce-solutions/go-quai/common"
+)
+
+// Head is a candidate head of a chain.
+type Head struct {
+	Hash   common.Hash
+	Number uint64     // Number of the head in the context of the chain
+	Td     []*big.Int // Total difficulty of the head per context, prime first
+}
+
+// Rule decides which of two competing heads a chain follows. Rules must be
+// deterministic, so that every node presented the same heads chooses the same.
+type Rule interface {
+	// Prefer reports whether the extern head should replace the local head of
+	// the chain. Ancestor is the number of the last block both heads share.
+	Prefer(local, extern *Head, ancestor uint64) bool
+}
+
+// Compare does the hierarchical comparison of two total difficulty tuples,
+// returning -1, 0 or +1 if a is lighter, as heavy or heavier than b. The
+// difficulty of a dominant context outweighs any difficulty of its
+// subordinates, missing tuples are lighter than any other.
+func Compare(a, b []*big.Int) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return -1
+	case len(b) == 0:
+		return 1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := a[i].Cmp(b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// HLCR is the heaviest local chain rule, the default fork choice of the Quai
+// hierarchy. The head with the hierarchically heavier total difficulty wins.
+// Ties go to the head with fewer blocks, then to the lower hash.
+type HLCR struct {
+	// FinalityDepth is the number of blocks of the local chain after which
+	// they are final. Forks dropping more blocks are never chosen, however
+	// heavy they are. Zero disables the limit.
+	FinalityDepth uint64
+}
+
+// Prefer implements Rule.
+func (r *HLCR) Prefer(local, extern *Head, ancestor uint64) bool {
+	if r.FinalityDepth > 0 && local.Number > ancestor && local.Number-ancestor > r.FinalityDepth {
+		return false
+	}
+	if c := Compare(local.Td, extern.Td); c != 0 {
+		return c < 0
+	}
+	if local.Number != extern.Number {
+		return extern.Number < local.Number
+	}
+	return bytes.Compare(extern.Hash[:], local.Hash[:]) < 0
+}
@@ -0,0 +1,231 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkchoice
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+)
+
+// dagBlock is a block of a synthetic hierarchy. A block of a given order is
+// coincident with its context and every subordinate one, adding its work to
+// the total difficulty of each of them.
+type dagBlock struct {
+	id     byte // Identifier and first byte of the hash, 0 is genesis
+	parent byte
+	order  int
+	work   int64
+}
+
+type dagNode struct {
+	hash   common.Hash
+	parent *dagNode
+	order  int
+	td     []*big.Int
+}
+
+// ancestors returns the blocks of the chain of a context from the node down to
+// genesis, which are the ones coincident with the context.
+func (n *dagNode) ancestors(context int) []*dagNode {
+	var chain []*dagNode
+	for ; n != nil; n = n.parent {
+		if n.order <= context {
+			chain = append(chain, n)
+		}
+	}
+	return chain
+}
+
+// dag feeds the blocks of a synthetic hierarchy to a fork choice rule, keeping
+// the head chosen by every context.
+type dag struct {
+	rule  Rule
+	nodes map[byte]*dagNode
+	heads []*dagNode
+}
+
+func newDag(rule Rule) *dag {
+	genesis := &dagNode{td: []*big.Int{new(big.Int), new(big.Int), new(big.Int)}}
+	return &dag{
+		rule:  rule,
+		nodes: map[byte]*dagNode{0: genesis},
+		heads: []*dagNode{genesis, genesis, genesis},
+	}
+}
+
+func (d *dag) head(node *dagNode, context int) *Head {
+	return &Head{Hash: node.hash, Number: uint64(len(node.ancestors(context)) - 1), Td: node.td}
+}
+
+// insert adds a block and lets every context it is coincident with choose
+// between its head and the block.
+func (d *dag) insert(b dagBlock) {
+	parent := d.nodes[b.parent]
+	node := &dagNode{hash: common.Hash{b.id}, parent: parent, order: b.order}
+	for context, td := range parent.td {
+		node.td = append(node.td, new(big.Int).Set(td))
+		if b.order <= context {
+			node.td[context].Add(node.td[context], big.NewInt(b.work))
+		}
+	}
+	d.nodes[b.id] = node
+
+	for context := b.order; context < len(d.heads); context++ {
+		local := d.heads[context]
+		ancestor := uint64(0)
+		shared := make(map[*dagNode]bool)
+		for _, n := range local.ancestors(context) {
+			shared[n] = true
+		}
+		for _, n := range node.ancestors(context) {
+			if shared[n] {
+				ancestor = uint64(len(n.ancestors(context)) - 1)
+				break
+			}
+		}
+		if d.rule.Prefer(d.head(local, context), d.head(node, context), ancestor) {
+			d.heads[context] = node
+		}
+	}
+}
+
+func TestHierarchicalForkChoice(t *testing.T) {
+	const (
+		prime  = 0
+		region = 1
+		zone   = 2
+	)
+	tests := []struct {
+		name   string
+		rule   Rule
+		blocks []dagBlock
+		heads  [3]byte // Expected heads of prime, region and zone
+	}{
+		{
+			name:   "linear",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{1, 0, zone, 1}, {2, 1, region, 1}, {3, 2, zone, 1}, {4, 3, prime, 1}, {5, 4, zone, 1}},
+			heads:  [3]byte{4, 4, 5},
+		},
+		{
+			name:   "heavier zone fork",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{1, 0, zone, 5}, {2, 1, zone, 5}, {3, 0, zone, 11}},
+			heads:  [3]byte{0, 0, 3},
+		},
+		{
+			name:   "lighter fork ignored",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{1, 0, zone, 5}, {2, 1, zone, 5}, {3, 0, zone, 9}},
+			heads:  [3]byte{0, 0, 2},
+		},
+		{
+			name:   "dominant difficulty outweighs",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{1, 0, zone, 100}, {2, 1, zone, 100}, {3, 0, region, 1}},
+			heads:  [3]byte{0, 3, 3},
+		},
+		{
+			name:   "prime fork reorgs every context",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{1, 0, region, 50}, {2, 1, zone, 50}, {3, 0, prime, 1}, {4, 2, region, 50}},
+			heads:  [3]byte{3, 3, 3},
+		},
+		{
+			name:   "tie broken by hash",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{7, 0, zone, 5}, {3, 0, zone, 5}},
+			heads:  [3]byte{0, 0, 3},
+		},
+		{
+			name:   "tie broken by hash regardless of arrival",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{3, 0, zone, 5}, {7, 0, zone, 5}},
+			heads:  [3]byte{0, 0, 3},
+		},
+		{
+			name:   "tie broken by fewer blocks",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{1, 0, zone, 5}, {2, 1, zone, 5}, {9, 0, zone, 10}},
+			heads:  [3]byte{0, 0, 9},
+		},
+		{
+			name:   "tie in region broken by hash",
+			rule:   new(HLCR),
+			blocks: []dagBlock{{8, 0, region, 5}, {9, 8, zone, 1}, {6, 0, region, 5}},
+			heads:  [3]byte{0, 6, 9},
+		},
+		{
+			name:   "finality depth rejects deep fork",
+			rule:   &HLCR{FinalityDepth: 2},
+			blocks: []dagBlock{{1, 0, zone, 1}, {2, 1, zone, 1}, {3, 2, zone, 1}, {4, 0, zone, 10}},
+			heads:  [3]byte{0, 0, 3},
+		},
+		{
+			name:   "finality depth allows shallow fork",
+			rule:   &HLCR{FinalityDepth: 2},
+			blocks: []dagBlock{{1, 0, zone, 1}, {2, 1, zone, 1}, {3, 2, zone, 1}, {4, 1, zone, 10}},
+			heads:  [3]byte{0, 0, 4},
+		},
+		{
+			name:   "finality depth counts blocks per context",
+			rule:   &HLCR{FinalityDepth: 1},
+			blocks: []dagBlock{{1, 0, region, 1}, {2, 1, zone, 1}, {3, 2, zone, 1}, {4, 0, region, 10}},
+			heads:  [3]byte{0, 4, 3},
+		},
+	}
+	for _, tt := range tests {
+		d := newDag(tt.rule)
+		for _, b := range tt.blocks {
+			d.insert(b)
+		}
+		for context, want := range tt.heads {
+			if have := d.heads[context].hash; have != (common.Hash{want}) {
+				t.Errorf("%s: context %d head mismatch: have %x, want %x", tt.name, context, have[0], want)
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tuple := func(tds ...int64) []*big.Int {
+		var td []*big.Int
+		for _, n := range tds {
+			td = append(td, big.NewInt(n))
+		}
+		return td
+	}
+	tests := []struct {
+		a, b []*big.Int
+		want int
+	}{
+		{tuple(1, 2, 3), tuple(1, 2, 3), 0},
+		{tuple(1, 2, 3), tuple(1, 2, 4), -1},
+		{tuple(1, 3, 0), tuple(1, 2, 100), 1},
+		{tuple(2, 0, 0), tuple(1, 100, 100), 1},
+		{nil, tuple(0, 0, 0), -1},
+		{tuple(0, 0, 0), nil, 1},
+		{nil, nil, 0},
+	}
+	for i, tt := range tests {
+		if have := Compare(tt.a, tt.b); have != tt.want {
+			t.Errorf("test %d: comparison mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/forkchoice"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// forkChoiceTestChain is a chain reader serving the headers of a canonical
+// chain and its forks, counting the headers the fork choice looks up.
+type forkChoiceTestChain struct {
+	ChainReader
+
+	config    *params.ChainConfig
+	headers   map[common.Hash]*types.Header
+	canonical []common.Hash
+	lookups   int
+}
+
+func (c *forkChoiceTestChain) Config() *params.ChainConfig { return c.config }
+
+func (c *forkChoiceTestChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	c.lookups++
+	return c.headers[hash]
+}
+
+func (c *forkChoiceTestChain) GetCanonicalHash(number uint64) common.Hash {
+	if number < uint64(len(c.canonical)) {
+		return c.canonical[number]
+	}
+	return common.Hash{}
+}
+
+// extend appends n headers to the parent in the context of the chain, tagging
+// them with the fork they belong to, and returns the last one.
+func (c *forkChoiceTestChain) extend(parent *types.Header, n int, fork byte) *types.Header {
+	context := c.config.Context
+	for i := 0; i < n; i++ {
+		header := types.NewEmptyHeader()
+		for j := range header.Number {
+			header.Number[j] = new(big.Int)
+		}
+		if parent != nil {
+			header.ParentHash[context] = parent.Hash()
+			header.Number[context].Add(parent.Number[context], common.Big1)
+		}
+		header.Extra[context] = []byte{fork}
+		c.headers[header.Hash()] = header
+		parent = header
+	}
+	return parent
+}
+
+func newForkChoiceTestChain(t *testing.T, length int) (*forkChoiceTestChain, *types.Header) {
+	config, err := params.MainnetOntology.ChainConfig(params.MainnetPrimeChainConfig, []byte{1, 1})
+	if err != nil {
+		t.Fatalf("failed to derive chain config: %v", err)
+	}
+	chain := &forkChoiceTestChain{config: config, headers: make(map[common.Hash]*types.Header)}
+	head := chain.extend(nil, length, 0)
+	chain.canonical = make([]common.Hash, length)
+	for header := head; header != nil; header = chain.headers[header.ParentHash[config.Context]] {
+		chain.canonical[header.Number[config.Context].Uint64()] = header.Hash()
+	}
+	return chain, head
+}
+
+// Tests that the common ancestor of a fork is found where the fork leaves the
+// canonical chain, walking back only the blocks of the fork.
+func TestForkChoiceCommonAncestor(t *testing.T) {
+	chain, head := newForkChoiceTestChain(t, 100)
+	forker := NewForkChoice(chain, nil)
+	context := chain.config.Context
+
+	for _, tt := range []struct {
+		ancestor uint64
+		blocks   int
+	}{
+		{99, 1}, {90, 5}, {90, 20}, {0, 150},
+	} {
+		fork := chain.extend(chain.headers[chain.canonical[tt.ancestor]], tt.blocks, 1+byte(tt.blocks))
+		chain.lookups = 0
+		if have := forker.commonAncestor(head, fork); have != tt.ancestor {
+			t.Errorf("fork of %d blocks from %d: ancestor mismatch: have %d, want %d", tt.blocks, tt.ancestor, have, tt.ancestor)
+		}
+		if chain.lookups != tt.blocks {
+			t.Errorf("fork of %d blocks from %d: looked up %d headers, want %d", tt.blocks, tt.ancestor, chain.lookups, tt.blocks)
+		}
+	}
+	// The canonical head is its own ancestor
+	if have := forker.commonAncestor(head, head); have != head.Number[context].Uint64() {
+		t.Errorf("head ancestor mismatch: have %d, want %d", have, head.Number[context].Uint64())
+	}
+	// Unknown ancestry has no ancestor
+	orphan := types.NewEmptyHeader()
+	for j := range orphan.Number {
+		orphan.Number[j] = new(big.Int)
+	}
+	orphan.Number[context] = big.NewInt(50)
+	orphan.ParentHash[context] = common.Hash{0xff}
+	if have := forker.commonAncestor(head, orphan); have != 0 {
+		t.Errorf("orphan ancestor mismatch: have %d, want 0", have)
+	}
+}
+
+// Tests that forks are walked back no deeper than the finality depth, and are
+// never chosen if they leave the canonical chain beyond it.
+func TestForkChoiceFinalityBound(t *testing.T) {
+	chain, head := newForkChoiceTestChain(t, 1000)
+	forker := NewForkChoice(chain, nil)
+	forker.SetRule(&forkchoice.HLCR{FinalityDepth: 10})
+
+	fork := chain.extend(chain.headers[chain.canonical[500]], 600, 1)
+	chain.lookups = 0
+	ancestor := forker.commonAncestor(head, fork)
+	if ancestor < 500 || head.Number[chain.config.Context].Uint64()-ancestor <= 10 {
+		t.Errorf("ancestor bound %d not beyond the finality depth", ancestor)
+	}
+	if chain.lookups > 1100-(999-10)+1 {
+		t.Errorf("looked up %d headers beyond the finality depth", chain.lookups)
+	}
+	local := &forkchoice.Head{Hash: head.Hash(), Number: 999, Td: []*big.Int{big.NewInt(1)}}
+	extern := &forkchoice.Head{Hash: fork.Hash(), Number: 1100, Td: []*big.Int{big.NewInt(2)}}
+	if forker.rule.Prefer(local, extern, ancestor) {
+		t.Errorf("fork beyond the finality depth chosen")
+	}
+}
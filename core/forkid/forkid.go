@@ -89,7 +89,7 @@ func NewIDWithChain(chain Blockchain) ID {
 	return NewID(
 		chain.Config(),
 		chain.Genesis().Hash(),
-		chain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64(),
+		chain.CurrentHeader().Number[chain.Config().Context].Uint64(),
 	)
 }
 
@@ -100,7 +100,7 @@ func NewFilter(chain Blockchain) Filter {
 		chain.Config(),
 		chain.Genesis().Hash(),
 		func() uint64 {
-			return chain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
+			return chain.CurrentHeader().Number[chain.Config().Context].Uint64()
 		},
 	)
 }
@@ -182,7 +182,8 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, genesis *Genesis, override
 	// We have the genesis block in database(perhaps in ancient database)
 	// but the corresponding state is missing.
 	header := rawdb.ReadHeader(db, stored, 0)
-	if _, err := state.New(header.Root[types.QuaiNetworkContext], state.NewDatabaseWithConfig(db, nil), nil); err != nil {
+	// The genesis state is shared by every context of the header.
+	if _, err := state.New(header.Root[params.PRIME], state.NewDatabaseWithConfig(db, nil), nil); err != nil {
 		if genesis == nil {
 			genesis = MainnetPrimeGenesisBlock()
 		}
@@ -304,23 +305,23 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 // The block is committed as the canonical head block.
 func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	block := g.ToBlock(db)
-	if block.Number().Sign() != 0 {
-		return nil, errors.New("can't commit genesis block with number > 0")
-	}
 	config := g.Config
 	if config == nil {
 		config = params.AllEthashProtocolChanges
 	}
+	if block.Number(config.Context).Sign() != 0 {
+		return nil, errors.New("can't commit genesis block with number > 0")
+	}
 	if err := config.CheckConfigForkOrder(); err != nil {
 		return nil, err
 	}
-	if config.Clique != nil && len(block.Extra()) == 0 {
+	if config.Clique != nil && len(block.Extra(config.Context)) == 0 {
 		return nil, errors.New("can't start clique chain without signers")
 	}
-	rawdb.WriteTd(db, block.Hash(), block.NumberU64(), g.Difficulty)
-	rawdb.WriteBlock(db, block)
-	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
-	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	rawdb.WriteTd(db, block.Hash(), block.NumberU64(config.Context), g.Difficulty)
+	rawdb.WriteBlock(db, block, config.Context)
+	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(config.Context), nil)
+	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64(config.Context))
 	rawdb.WriteHeadBlockHash(db, block.Hash())
 	rawdb.WriteHeadFastBlockHash(db, block.Hash())
 	rawdb.WriteHeadHeaderHash(db, block.Hash())
@@ -461,6 +462,55 @@ func DefaultRopstenGenesisBlock() *Genesis {
 	}
 }
 
+// OntologyForkGenesisBlock returns the genesis of a chain created by an
+// ontology fork, derived from the genesis template of its network. The genesis
+// is anchored to the prime block activating the fork, which becomes its parent
+// in every dominant context, and only keeps the allocations inside the address
+// prefixes of the new chain. The genesis hash of the new chain is recorded in
+// its returned config.
+func OntologyForkGenesisBlock(template *Genesis, config *params.ChainConfig, fork *types.Header) (*Genesis, error) {
+	number := fork.Number[params.PRIME]
+	if config.OntologyAt(number) == nil {
+		return nil, errors.New("chain has no ontology")
+	}
+	inside := func(o *params.Ontology) bool {
+		id, err := o.ChainID(config.Location)
+		return err == nil && id.Cmp(config.ChainID) == 0
+	}
+	if !inside(config.OntologyAt(number)) {
+		return nil, fmt.Errorf("location %v not created by an ontology fork at prime block %v", config.Location, number)
+	}
+	if number.Sign() > 0 && inside(config.OntologyAt(new(big.Int).Sub(number, common.Big1))) {
+		return nil, fmt.Errorf("location %v exists before prime block %v", config.Location, number)
+	}
+	genesis := *template
+	cfg := *config
+	genesis.Config = &cfg
+	genesis.Knot = nil
+	genesis.ParentHash = make([]common.Hash, types.ContextDepth)
+	genesis.Number = make([]*big.Int, types.ContextDepth)
+	for ctx := 0; ctx < types.ContextDepth; ctx++ {
+		genesis.Number[ctx] = new(big.Int)
+		if ctx < config.Context {
+			genesis.ParentHash[ctx] = fork.Hash()
+			genesis.Number[ctx] = new(big.Int).Set(fork.Number[ctx])
+		}
+	}
+	prefixes := config.ChainIDRange()
+	genesis.Alloc = make(GenesisAlloc)
+	for addr, account := range template.Alloc {
+		if prefix := int(addr[0]); prefix >= prefixes[0] && prefix <= prefixes[1] {
+			genesis.Alloc[addr] = account
+		}
+	}
+	cfg.GenesisHashes = append([]common.Hash{}, config.GenesisHashes...)
+	for len(cfg.GenesisHashes) < types.ContextDepth {
+		cfg.GenesisHashes = append(cfg.GenesisHashes, common.Hash{})
+	}
+	cfg.GenesisHashes[config.Context] = genesis.ToBlock(nil).Hash()
+	return &genesis, nil
+}
+
 // DeveloperGenesisBlock returns the 'geth --dev' genesis block.
 func DeveloperGenesisBlock(period uint64, faucet common.Address) *Genesis {
 	// Override the default period to the user requested one
@@ -30,6 +30,7 @@ import (
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/consensus"
+	"github.com/spruce-solutions/go-quai/core/forkchoice"
 	"github.com/spruce-solutions/go-quai/core/rawdb"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/ethdb"
@@ -71,8 +72,9 @@ type HeaderChain struct {
 
 	procInterrupt func() bool
 
-	rand   *mrand.Rand
-	engine consensus.Engine
+	rand       *mrand.Rand
+	engine     consensus.Engine
+	forkChoice forkchoice.Rule // Rule choosing the head among competing forks
 }
 
 // NewHeaderChain creates a new HeaderChain structure. ProcInterrupt points
@@ -97,6 +99,7 @@ func NewHeaderChain(chainDb ethdb.Database, config *params.ChainConfig, engine c
 		procInterrupt: procInterrupt,
 		rand:          mrand.New(mrand.NewSource(seed.Int64())),
 		engine:        engine,
+		forkChoice:    new(forkchoice.HLCR),
 	}
 
 	hc.genesisHeader = hc.GetHeaderByNumber(0)
@@ -111,7 +114,7 @@ func NewHeaderChain(chainDb ethdb.Database, config *params.ChainConfig, engine c
 		}
 	}
 	hc.currentHeaderHash = hc.CurrentHeader().Hash()
-	headHeaderGauge.Update(hc.CurrentHeader().Number[types.QuaiNetworkContext].Int64())
+	headHeaderGauge.Update(hc.CurrentHeader().Number[hc.config.Context].Int64())
 
 	return hc, nil
 }
@@ -151,13 +154,13 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 	if len(headers) == 0 {
 		return &headerWriteResult{}, nil
 	}
-	ptd := hc.GetTd(headers[0].ParentHash[types.QuaiNetworkContext], headers[0].Number[types.QuaiNetworkContext].Uint64()-1)
+	ptd := hc.GetTd(headers[0].ParentHash[hc.config.Context], headers[0].Number[hc.config.Context].Uint64()-1)
 	if ptd == nil {
 		return &headerWriteResult{}, consensus.ErrUnknownAncestor
 	}
 	var (
-		lastNumber = headers[0].Number[types.QuaiNetworkContext].Uint64() - 1 // Last successfully imported number
-		lastHash   = headers[0].ParentHash[types.QuaiNetworkContext]          // Last imported header hash
+		lastNumber = headers[0].Number[hc.config.Context].Uint64() - 1 // Last successfully imported number
+		lastHash   = headers[0].ParentHash[hc.config.Context]          // Last imported header hash
 
 		newTd = ptd // Total difficulty of inserted chain
 
@@ -174,7 +177,7 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 		// know that it's a contiguous chain, where
 		// headers[i].Hash() == headers[i+1].ParentHash
 		if i < len(headers)-1 {
-			hash = headers[i+1].ParentHash[types.QuaiNetworkContext]
+			hash = headers[i+1].ParentHash[hc.config.Context]
 		} else {
 			hash = header.Hash()
 		}
@@ -184,7 +187,7 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 			return &headerWriteResult{}, errors.New("error calculating the total td for the header")
 		}
 
-		number := header.Number[types.QuaiNetworkContext].Uint64()
+		number := header.Number[hc.config.Context].Uint64()
 
 		// If the parent was not present, store it
 		// If the header is already known, skip it, otherwise store
@@ -194,7 +197,7 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 			rawdb.WriteTd(batch, hash, number, newTd)
 			hc.tdCache.Add(hash, newTd)
 
-			rawdb.WriteHeader(batch, header)
+			rawdb.WriteHeader(batch, header, hc.config.Context)
 			inserted = append(inserted, numberHash{number, hash})
 			hc.headerCache.Add(hash, header)
 			hc.numberCache.Add(hash, number)
@@ -218,27 +221,25 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 	batch.Reset()
 
 	var (
-		head    = hc.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
+		current = hc.CurrentHeader()
+		head    = current.Number[hc.config.Context].Uint64()
 		localTd = hc.GetTd(hc.currentHeaderHash, head)
 		status  = SideStatTy
 	)
-	// If the total difficulty is higher than our known, add it to the canonical chain
-	// Second clause in the if statement reduces the vulnerability to selfish mining.
-	// Please refer to http://www.cs.cornell.edu/~ie53/publications/btcProcFC.pdf
-	// TODO: need to change the HLCR logic to return something else if the difficulties are equal (replace the first if statement)
-	reorg := hc.HLCR(localTd, newTd)
-	equalTd := newTd[0].Cmp(localTd[0]) == 0 && newTd[1].Cmp(localTd[1]) == 0 && newTd[2].Cmp(localTd[2]) == 0
-	if !reorg && equalTd {
-		if lastNumber < head {
-			reorg = true
-		} else if lastNumber == head {
-			reorg = mrand.Float64() < 0.5
-		}
-	}
+	// Let the fork choice rule decide whether the last header becomes the head
+	var ancestor uint64
+	if shared := rawdb.FindCommonAncestor(hc.chainDb, current, lastHeader, hc.config.Context); shared != nil {
+		ancestor = shared.Number[hc.config.Context].Uint64()
+	}
+	reorg := hc.forkChoice.Prefer(
+		&forkchoice.Head{Hash: hc.currentHeaderHash, Number: head, Td: localTd},
+		&forkchoice.Head{Hash: lastHash, Number: lastNumber, Td: newTd},
+		ancestor,
+	)
 	// If the parent of the (first) block is already the canon header,
 	// we don't have to go backwards to delete canon blocks, but
 	// simply pile them onto the existing chain
-	chainAlreadyCanon := headers[0].ParentHash[types.QuaiNetworkContext] == hc.currentHeaderHash
+	chainAlreadyCanon := headers[0].ParentHash[hc.config.Context] == hc.currentHeaderHash
 	if reorg {
 		// If the header can be added into canonical chain, adjust the
 		// header chain markers(canonical indexes and head header flag).
@@ -257,14 +258,14 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 			// Overwrite any stale canonical number assignments, going
 			// backwards from the first header in this import
 			var (
-				headHash   = headers[0].ParentHash[types.QuaiNetworkContext]          // inserted[0].parent?
-				headNumber = headers[0].Number[types.QuaiNetworkContext].Uint64() - 1 // inserted[0].num-1 ?
+				headHash   = headers[0].ParentHash[hc.config.Context]          // inserted[0].parent?
+				headNumber = headers[0].Number[hc.config.Context].Uint64() - 1 // inserted[0].num-1 ?
 				headHeader = hc.GetHeader(headHash, headNumber)
 			)
 			for rawdb.ReadCanonicalHash(hc.chainDb, headNumber) != headHash {
 				rawdb.WriteCanonicalHash(markerBatch, headHash, headNumber)
-				headHash = headHeader.ParentHash[types.QuaiNetworkContext]
-				headNumber = headHeader.Number[types.QuaiNetworkContext].Uint64() - 1
+				headHash = headHeader.ParentHash[hc.config.Context]
+				headNumber = headHeader.Number[hc.config.Context].Uint64() - 1
 				headHeader = hc.GetHeader(headHash, headNumber)
 			}
 			// If some of the older headers were already known, but obtained canon-status
@@ -273,7 +274,7 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 			// were not already known
 			for i := 0; i < firstInserted; i++ {
 				hash := headers[i].Hash()
-				num := headers[i].Number[types.QuaiNetworkContext].Uint64()
+				num := headers[i].Number[hc.config.Context].Uint64()
 				rawdb.WriteCanonicalHash(markerBatch, hash, num)
 				rawdb.WriteHeadHeaderHash(markerBatch, hash)
 			}
@@ -290,7 +291,7 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 		// Last step update all in-memory head header markers
 		hc.currentHeaderHash = lastHash
 		hc.currentHeader.Store(types.CopyHeader(lastHeader))
-		headHeaderGauge.Update(lastHeader.Number[types.QuaiNetworkContext].Int64())
+		headHeaderGauge.Update(lastHeader.Number[hc.config.Context].Int64())
 
 		// Chain status is canonical since this insert was a reorg.
 		// Note that all inserts which have higher TD than existing are 'reorg'.
@@ -312,7 +313,7 @@ func (hc *HeaderChain) writeHeaders(headers []*types.Header) (result *headerWrit
 func (hc *HeaderChain) ValidateHeaderChain(chain []*types.Header, checkFreq int) (int, error) {
 	// Do a sanity check that the provided chain is actually ordered and linked
 	for i := 1; i < len(chain); i++ {
-		if chain[i].Number[types.QuaiNetworkContext].Uint64() != chain[i-1].Number[types.QuaiNetworkContext].Uint64()+1 {
+		if chain[i].Number[hc.config.Context].Uint64() != chain[i-1].Number[hc.config.Context].Uint64()+1 {
 			hash := chain[i].Hash()
 			parentHash := chain[i-1].Hash()
 			// Chain broke ancestry, log a message (programming error) and skip insertion
@@ -323,7 +324,7 @@ func (hc *HeaderChain) ValidateHeaderChain(chain []*types.Header, checkFreq int)
 				parentHash.Bytes()[:4], i, chain[i].Number, hash.Bytes()[:4], chain[i].ParentHash[:4])
 		}
 		// If the header is a banned one, straight out abort
-		if BadHashes[chain[i].ParentHash[types.QuaiNetworkContext]] {
+		if BadHashes[chain[i].ParentHash[hc.config.Context]] {
 			return i - 1, ErrBannedHash
 		}
 		// If it's the last header in the cunk, we need to check it too
@@ -414,12 +415,12 @@ func (hc *HeaderChain) GetBlockHashesFromHash(hash common.Hash, max uint64) []co
 	// Iterate the headers until enough is collected or the genesis reached
 	chain := make([]common.Hash, 0, max)
 	for i := uint64(0); i < max; i++ {
-		next := header.ParentHash[types.QuaiNetworkContext]
-		if header = hc.GetHeader(next, header.Number[types.QuaiNetworkContext].Uint64()-1); header == nil {
+		next := header.ParentHash[hc.config.Context]
+		if header = hc.GetHeader(next, header.Number[hc.config.Context].Uint64()-1); header == nil {
 			break
 		}
 		chain = append(chain, next)
-		if header.Number[types.QuaiNetworkContext].Sign() == 0 {
+		if header.Number[hc.config.Context].Sign() == 0 {
 			break
 		}
 	}
@@ -438,7 +439,7 @@ func (hc *HeaderChain) GetAncestor(hash common.Hash, number, ancestor uint64, ma
 	if ancestor == 1 {
 		// in this case it is cheaper to just read the header
 		if header := hc.GetHeader(hash, number); header != nil {
-			return header.ParentHash[types.QuaiNetworkContext], number - 1
+			return header.ParentHash[hc.config.Context], number - 1
 		}
 		return common.Hash{}, 0
 	}
@@ -459,7 +460,7 @@ func (hc *HeaderChain) GetAncestor(hash common.Hash, number, ancestor uint64, ma
 		if header == nil {
 			return common.Hash{}, 0
 		}
-		hash = header.ParentHash[types.QuaiNetworkContext]
+		hash = header.ParentHash[hc.config.Context]
 		number--
 	}
 	return hash, number
@@ -475,7 +476,7 @@ func (hc *HeaderChain) GetAncestorByLocation(hash common.Hash, location []byte)
 	}
 
 	for !bytes.Equal(header.Location, location) {
-		hash = header.ParentHash[types.QuaiNetworkContext]
+		hash = header.ParentHash[hc.config.Context]
 
 		header := hc.GetHeaderByHash(hash)
 		if header != nil {
@@ -596,7 +597,7 @@ func (hc *HeaderChain) CurrentHeader() *types.Header {
 func (hc *HeaderChain) SetCurrentHeader(head *types.Header) {
 	hc.currentHeader.Store(head)
 	hc.currentHeaderHash = head.Hash()
-	headHeaderGauge.Update(head.Number[types.QuaiNetworkContext].Int64())
+	headHeaderGauge.Update(head.Number[hc.config.Context].Int64())
 }
 
 type (
@@ -619,11 +620,11 @@ func (hc *HeaderChain) SetHead(head uint64, updateFn UpdateHeadBlocksCallback, d
 		batch      = hc.chainDb.NewBatch()
 		origin     = true
 	)
-	for hdr := hc.CurrentHeader(); hdr != nil && hdr.Number[types.QuaiNetworkContext].Uint64() > head; hdr = hc.CurrentHeader() {
-		num := hdr.Number[types.QuaiNetworkContext].Uint64()
+	for hdr := hc.CurrentHeader(); hdr != nil && hdr.Number[hc.config.Context].Uint64() > head; hdr = hc.CurrentHeader() {
+		num := hdr.Number[hc.config.Context].Uint64()
 
 		// Rewind block chain to new head.
-		parent := hc.GetHeader(hdr.ParentHash[types.QuaiNetworkContext], num-1)
+		parent := hc.GetHeader(hdr.ParentHash[hc.config.Context], num-1)
 		if parent == nil {
 			parent = hc.genesisHeader
 		}
@@ -651,7 +652,7 @@ func (hc *HeaderChain) SetHead(head uint64, updateFn UpdateHeadBlocksCallback, d
 		}
 		hc.currentHeader.Store(parent)
 		hc.currentHeaderHash = parentHash
-		headHeaderGauge.Update(parent.Number[types.QuaiNetworkContext].Int64())
+		headHeaderGauge.Update(parent.Number[hc.config.Context].Int64())
 
 		// If this is the first iteration, wipe any leftover data upwards too so
 		// we don't end up with dangling daps in the database
@@ -695,22 +696,13 @@ func (hc *HeaderChain) SetHead(head uint64, updateFn UpdateHeadBlocksCallback, d
 // HLCR does hierarchical comparison of two difficulty tuples and returns true if second tuple is greater than the first
 func (hc *HeaderChain) HLCR(localDifficulties []*big.Int, externDifficulties []*big.Int) bool {
 	log.Info("HLCR", "localDiff", localDifficulties, "externDiff", externDifficulties)
-	if localDifficulties[0].Cmp(externDifficulties[0]) < 0 {
-		return true
-	} else if localDifficulties[0].Cmp(externDifficulties[0]) > 0 {
-		return false
-	}
-	if localDifficulties[1].Cmp(externDifficulties[1]) < 0 {
-		return true
-	} else if localDifficulties[1].Cmp(externDifficulties[1]) > 0 {
-		return false
-	}
-	if localDifficulties[2].Cmp(externDifficulties[2]) < 0 {
-		return true
-	} else if localDifficulties[2].Cmp(externDifficulties[2]) > 0 {
-		return false
-	}
-	return false
+	return forkchoice.Compare(localDifficulties, externDifficulties) < 0
+}
+
+// SetForkChoice replaces the rule choosing the head of the header chain among
+// competing forks.
+func (hc *HeaderChain) SetForkChoice(rule forkchoice.Rule) {
+	hc.forkChoice = rule
 }
 
 // CalcTd calculates the TD of the given header using PCRC and CalcHLCRNetDifficulty.
@@ -795,7 +787,7 @@ func (hc *HeaderChain) NdToTd(header *types.Header, nD []*big.Int) ([]*big.Int,
 // prime termini match. To check deeper than that, you need to iteratively apply PCRC to get that guarantee.
 func (hc *HeaderChain) PCRC(header *types.Header) (common.Hash, error) {
 
-	if header.Number[types.QuaiNetworkContext].Cmp(big.NewInt(0)) == 0 {
+	if header.Number[hc.config.Context].Cmp(big.NewInt(0)) == 0 {
 		return hc.config.GenesisHashes[0], nil
 	}
 
@@ -933,10 +925,11 @@ func (hc *HeaderChain) CheckContext(context int) error {
 
 // CheckLocationRange checks to make sure the range of r and z are valid
 func (hc *HeaderChain) CheckLocationRange(location []byte) error {
-	if int(location[0]) < 1 || int(location[0]) > params.FullerOntology[0] {
+	ontology := hc.config.OntologyShape()
+	if int(location[0]) < 1 || int(location[0]) > ontology[0] {
 		return errors.New("the provided location is outside the allowable region range")
 	}
-	if int(location[1]) < 1 || int(location[1]) > params.FullerOntology[1] {
+	if int(location[1]) < 1 || int(location[1]) > ontology[1] {
 		return errors.New("the provided location is outside the allowable zone range")
 	}
 	return nil
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rlp"
 )
 
@@ -32,7 +33,7 @@ func ReadKnot(chainfile string) []*types.Block {
 		} else if err != nil {
 			return nil
 		}
-		if b.NumberU64() != uint64(i+1) {
+		if b.NumberU64(params.PRIME) != uint64(i+1) {
 			return nil
 		}
 		blocks = append(blocks, &b)
@@ -0,0 +1,141 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// twistErrors are the errors PCRC reports twisted chains with. Subordinate
+// chains report them over RPC, losing all but their message.
+var twistErrors = []error{ErrPrimeTwist, ErrPrimeRegionTwist, ErrRegionTwist}
+
+// isTwist reports whether a PCRC error is caused by twisted chains rather than
+// by missing data.
+func isTwist(err error) bool {
+	for _, twist := range twistErrors {
+		if errors.Is(err, twist) || err.Error() == twist.Error() {
+			return true
+		}
+	}
+	return false
+}
+
+// coincidenceKey identifies the search for the previous coincident block of a
+// header.
+type coincidenceKey struct {
+	hash           common.Hash
+	slice          string
+	order, path    int
+	fullSliceEqual bool
+}
+
+// PCRCReport is the outcome of a deep PCRC verification.
+type PCRCReport struct {
+	Hash     common.Hash    `json:"hash"`              // Header the verification started from
+	Checked  hexutil.Uint64 `json:"checked"`           // Number of headers whose PCRC passed
+	Reached  common.Hash    `json:"reached"`           // Last header whose PCRC passed
+	Complete bool           `json:"complete"`          // Whether the walk reached the genesis or the checkpoint
+	Twisted  *common.Hash   `json:"twisted,omitempty"` // First header whose PCRC found twisted chains
+	Error    string         `json:"error,omitempty"`   // Twist found at the twisted header
+}
+
+// PCRC runs the Previous Coincident Reference Check of the header as a block of
+// the given order. The termini depend on the blocks the dominant chains hold
+// canonical, which their reorgs change, so only the searches for the previous
+// coincident blocks are cached, and every check asks the dominant and
+// subordinate chains anew.
+func (bc *BlockChain) PCRC(header *types.Header, headerOrder int) (types.PCRCTermini, error) {
+	return bc.pcrc(header, headerOrder)
+}
+
+// previousCoincidentOnPath returns the previous block of the order coincident
+// with the header along the path in the slice, as found by the engine. The
+// search only follows the ancestry of the header, so its result never changes
+// and is cached and indexed, saving repeated checks the walk back through the
+// headers.
+func (bc *BlockChain) previousCoincidentOnPath(header *types.Header, slice []byte, order, path int, fullSliceEqual bool) (*types.Header, error) {
+	key := coincidenceKey{header.Hash(), string(slice), order, path, fullSliceEqual}
+	if cached, ok := bc.coincidenceCache.Get(key); ok {
+		if coincident := bc.GetHeaderByHash(cached.(common.Hash)); coincident != nil {
+			return coincident, nil
+		}
+	}
+	if hash := rawdb.ReadPreviousCoincident(bc.db, key.hash, slice, order, path, fullSliceEqual); hash != (common.Hash{}) {
+		if coincident := bc.GetHeaderByHash(hash); coincident != nil {
+			bc.coincidenceCache.Add(key, hash)
+			return coincident, nil
+		}
+	}
+	coincident, err := bc.engine.PreviousCoincidentOnPath(bc, header, slice, order, path, fullSliceEqual)
+	if err != nil || coincident == nil {
+		return coincident, err
+	}
+	rawdb.WritePreviousCoincident(bc.db, key.hash, slice, order, path, fullSliceEqual, coincident.Hash())
+	bc.coincidenceCache.Add(key, coincident.Hash())
+	return coincident, nil
+}
+
+// VerifyPCRC guarantees linked, untwisted chains deeper than a single PCRC by
+// re-applying it to the previous prime termini of the header, up to depth of
+// them or until reaching the genesis or the trusted checkpoint if depth is 0.
+// The report tells how far the chains were verified and which header's PCRC
+// found them twisted, which is also returned as the error.
+func (bc *BlockChain) VerifyPCRC(header *types.Header, depth uint64) (*PCRCReport, error) {
+	context := bc.chainConfig.Context
+	report := &PCRCReport{Hash: header.Hash()}
+	for termini := uint64(0); ; termini++ {
+		// The genesis has no difficulty order and trivially passes PCRC
+		if header.Number[context].Sign() != 0 {
+			order, err := bc.engine.GetDifficultyOrder(header)
+			if err != nil {
+				return report, err
+			}
+			if _, err := bc.PCRC(header, order); err != nil {
+				if isTwist(err) {
+					hash := header.Hash()
+					report.Twisted, report.Error = &hash, err.Error()
+				}
+				return report, err
+			}
+		}
+		report.Checked++
+		report.Reached = header.Hash()
+
+		if header.Number[context].Sign() == 0 || header.Hash() == bc.cacheConfig.PCRCCheckpoint {
+			report.Complete = true
+			return report, nil
+		}
+		if depth > 0 && termini == depth {
+			return report, nil
+		}
+		prev, err := bc.previousCoincidentOnPath(header, header.Location, params.PRIME, context, true)
+		if err != nil {
+			return report, err
+		}
+		if prev.Hash() == header.Hash() {
+			return report, errors.New("prime terminus is its own predecessor")
+		}
+		header = prev
+	}
+}
@@ -377,11 +377,11 @@ func ReadExternalHeader(db ethdb.Reader, hash common.Hash, context uint64) *type
 }
 
 // WriteHeader stores a block header into the database and also stores the hash-
-// to-number mapping.
-func WriteHeader(db ethdb.KeyValueWriter, header *types.Header) {
+// to-number mapping, keyed by the header's number in the given context.
+func WriteHeader(db ethdb.KeyValueWriter, header *types.Header, context int) {
 	var (
 		hash   = header.Hash()
-		number = header.Number[types.QuaiNetworkContext].Uint64()
+		number = header.Number[context].Uint64()
 	)
 	// Write the hash -> number mapping
 	WriteHeaderNumber(db, hash, number)
@@ -845,35 +845,13 @@ func ReadBlock(db ethdb.Reader, hash common.Hash, number uint64) *types.Block {
 }
 
 // WriteBlock serializes a block into the database, header and body separately.
-func WriteBlock(db ethdb.KeyValueWriter, block *types.Block) {
-	WriteBody(db, block.Hash(), block.NumberU64(), block.Body())
-	WriteHeader(db, block.Header())
-}
-
-// ReadExternalBlock retrieves an entire external block corresponding to the hash, assembling it
-// back from the stored header and body. If either the header or body could not
-// be retrieved nil is returned.
-func ReadExternalBlock(db ethdb.Reader, hash common.Hash, context uint64) *types.ExternalBlock {
-	header := ReadExternalHeader(db, hash, context)
-	if header == nil {
-		return nil
-	}
-	body := ReadExternalBody(db, hash, context)
-	if body == nil {
-		return nil
-	}
-	return types.NewExternalBlockWithHeader(header).WithBody(body.Transactions, body.Uncles, body.Receipts, body.Context)
-}
-
-// WriteBlock serializes a block into the database, header and body separately.
-func WriteExternalBlock(db ethdb.KeyValueWriter, block *types.ExternalBlock) {
-	context := block.Context().Uint64()
-	WriteExternalBody(db, block.Hash(), block.Header().Number[context].Uint64(), context, block.Body())
-	WriteExternalHeader(db, block.Header(), context)
+func WriteBlock(db ethdb.KeyValueWriter, block *types.Block, context int) {
+	WriteBody(db, block.Hash(), block.NumberU64(context), block.Body())
+	WriteHeader(db, block.Header(), context)
 }
 
 // WriteAncientBlock writes entire block data into ancient store and returns the total written size.
-func WriteAncientBlocks(db ethdb.AncientWriter, blocks []*types.Block, receipts []types.Receipts, td *big.Int) (int64, error) {
+func WriteAncientBlocks(db ethdb.AncientWriter, blocks []*types.Block, receipts []types.Receipts, td *big.Int, context int) (int64, error) {
 	var (
 		tdSum      = new(big.Int).Set(td)
 		stReceipts []*types.ReceiptForStorage
@@ -887,9 +865,9 @@ func WriteAncientBlocks(db ethdb.AncientWriter, blocks []*types.Block, receipts
 			}
 			header := block.Header()
 			if i > 0 {
-				tdSum.Add(tdSum, header.Difficulty[types.QuaiNetworkContext])
+				tdSum.Add(tdSum, header.Difficulty[context])
 			}
-			if err := writeAncientBlock(op, block, header, stReceipts, tdSum); err != nil {
+			if err := writeAncientBlock(op, block, header, stReceipts, tdSum, context); err != nil {
 				return err
 			}
 		}
@@ -897,8 +875,8 @@ func WriteAncientBlocks(db ethdb.AncientWriter, blocks []*types.Block, receipts
 	})
 }
 
-func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *types.Header, receipts []*types.ReceiptForStorage, td *big.Int) error {
-	num := block.NumberU64()
+func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *types.Header, receipts []*types.ReceiptForStorage, td *big.Int, context int) error {
+	num := block.NumberU64(context)
 	if err := op.AppendRaw(freezerHashTable, num, block.Hash().Bytes()); err != nil {
 		return fmt.Errorf("can't add block %d hash: %v", num, err)
 	}
@@ -941,16 +919,10 @@ type badBlock struct {
 	Body   *types.Body
 }
 
-// badBlockList implements the sort interface to allow sorting a list of
-// bad blocks by their number in the reverse order.
+// badBlockList is a list of bad blocks, kept sorted by their number in the
+// reverse order.
 type badBlockList []*badBlock
 
-func (s badBlockList) Len() int { return len(s) }
-func (s badBlockList) Less(i, j int) bool {
-	return s[i].Header.Number[types.QuaiNetworkContext].Uint64() < s[j].Header.Number[types.QuaiNetworkContext].Uint64()
-}
-func (s badBlockList) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
-
 // ReadBadBlock retrieves the bad block with the corresponding block hash.
 func ReadBadBlock(db ethdb.Reader, hash common.Hash) *types.Block {
 	blob, err := db.Get(badBlockKey)
@@ -988,8 +960,9 @@ func ReadAllBadBlocks(db ethdb.Reader) []*types.Block {
 }
 
 // WriteBadBlock serializes the bad block into the database. If the cumulated
-// bad blocks exceeds the limitation, the oldest will be dropped.
-func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
+// bad blocks exceeds the limitation, the oldest will be dropped. Blocks are
+// ordered by their number in the given context.
+func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block, context int) {
 	blob, err := db.Get(badBlockKey)
 	if err != nil {
 		log.Warn("Failed to load old bad blocks", "error", err)
@@ -1001,8 +974,8 @@ func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
 		}
 	}
 	for _, b := range badBlocks {
-		if b.Header.Number[types.QuaiNetworkContext].Uint64() == block.NumberU64() && b.Header.Hash() == block.Hash() {
-			log.Info("Skip duplicated bad block", "number", block.NumberU64(), "hash", block.Hash())
+		if b.Header.Number[context].Uint64() == block.NumberU64(context) && b.Header.Hash() == block.Hash() {
+			log.Info("Skip duplicated bad block", "number", block.NumberU64(context), "hash", block.Hash())
 			return
 		}
 	}
@@ -1010,7 +983,9 @@ func WriteBadBlock(db ethdb.KeyValueStore, block *types.Block) {
 		Header: block.Header(),
 		Body:   block.Body(),
 	})
-	sort.Sort(sort.Reverse(badBlocks))
+	sort.SliceStable(badBlocks, func(i, j int) bool {
+		return badBlocks[i].Header.Number[context].Uint64() > badBlocks[j].Header.Number[context].Uint64()
+	})
 	if len(badBlocks) > badBlockToKeep {
 		badBlocks = badBlocks[:badBlockToKeep]
 	}
@@ -1030,26 +1005,27 @@ func DeleteBadBlocks(db ethdb.KeyValueWriter) {
 	}
 }
 
-// FindCommonAncestor returns the last common ancestor of two block headers
-func FindCommonAncestor(db ethdb.Reader, a, b *types.Header) *types.Header {
-	for bn := b.Number[types.QuaiNetworkContext].Uint64(); a.Number[types.QuaiNetworkContext].Uint64() > bn; {
-		a = ReadHeader(db, a.ParentHash[types.QuaiNetworkContext], a.Number[types.QuaiNetworkContext].Uint64()-1)
+// FindCommonAncestor returns the last common ancestor of two block headers,
+// walking their parents in the given context.
+func FindCommonAncestor(db ethdb.Reader, a, b *types.Header, context int) *types.Header {
+	for bn := b.Number[context].Uint64(); a.Number[context].Uint64() > bn; {
+		a = ReadHeader(db, a.ParentHash[context], a.Number[context].Uint64()-1)
 		if a == nil {
 			return nil
 		}
 	}
-	for an := a.Number[types.QuaiNetworkContext].Uint64(); an < b.Number[types.QuaiNetworkContext].Uint64(); {
-		b = ReadHeader(db, b.ParentHash[types.QuaiNetworkContext], b.Number[types.QuaiNetworkContext].Uint64()-1)
+	for an := a.Number[context].Uint64(); an < b.Number[context].Uint64(); {
+		b = ReadHeader(db, b.ParentHash[context], b.Number[context].Uint64()-1)
 		if b == nil {
 			return nil
 		}
 	}
 	for a.Hash() != b.Hash() {
-		a = ReadHeader(db, a.ParentHash[types.QuaiNetworkContext], a.Number[types.QuaiNetworkContext].Uint64()-1)
+		a = ReadHeader(db, a.ParentHash[context], a.Number[context].Uint64()-1)
 		if a == nil {
 			return nil
 		}
-		b = ReadHeader(db, b.ParentHash[types.QuaiNetworkContext], b.Number[types.QuaiNetworkContext].Uint64()-1)
+		b = ReadHeader(db, b.ParentHash[context], b.Number[context].Uint64()-1)
 		if b == nil {
 			return nil
 		}
@@ -46,7 +46,7 @@ func TestHeaderStorage(t *testing.T) {
 		t.Fatalf("Non existent header returned: %v", entry)
 	}
 	// Write and verify the header in the database
-	WriteHeader(db, header)
+	WriteHeader(db, header, types.QuaiNetworkContext)
 	if entry := ReadHeader(db, header.Hash(), header.Number[types.QuaiNetworkContext].Uint64()); entry == nil {
 		t.Fatalf("Stored header not found")
 	} else if entry.Hash() != header.Hash() {
@@ -119,41 +119,41 @@ func TestBlockStorage(t *testing.T) {
 		TxHash:      types.EmptyRootHash,
 		ReceiptHash: types.EmptyRootHash,
 	})
-	if entry := ReadBlock(db, block.Hash(), block.NumberU64()); entry != nil {
+	if entry := ReadBlock(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Non existent block returned: %v", entry)
 	}
-	if entry := ReadHeader(db, block.Hash(), block.NumberU64()); entry != nil {
+	if entry := ReadHeader(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Non existent header returned: %v", entry)
 	}
-	if entry := ReadBody(db, block.Hash(), block.NumberU64()); entry != nil {
+	if entry := ReadBody(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Non existent body returned: %v", entry)
 	}
 	// Write and verify the block in the database
-	WriteBlock(db, block)
-	if entry := ReadBlock(db, block.Hash(), block.NumberU64()); entry == nil {
+	WriteBlock(db, block, types.QuaiNetworkContext)
+	if entry := ReadBlock(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry == nil {
 		t.Fatalf("Stored block not found")
 	} else if entry.Hash() != block.Hash() {
 		t.Fatalf("Retrieved block mismatch: have %v, want %v", entry, block)
 	}
-	if entry := ReadHeader(db, block.Hash(), block.NumberU64()); entry == nil {
+	if entry := ReadHeader(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry == nil {
 		t.Fatalf("Stored header not found")
 	} else if entry.Hash() != block.Header().Hash() {
 		t.Fatalf("Retrieved header mismatch: have %v, want %v", entry, block.Header())
 	}
-	if entry := ReadBody(db, block.Hash(), block.NumberU64()); entry == nil {
+	if entry := ReadBody(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry == nil {
 		t.Fatalf("Stored body not found")
 	} else if types.DeriveSha(types.Transactions(entry.Transactions), newHasher()) != types.DeriveSha(block.Transactions(), newHasher()) || types.CalcUncleHash(entry.Uncles) != types.CalcUncleHash(block.Uncles()) {
 		t.Fatalf("Retrieved body mismatch: have %v, want %v", entry, block.Body())
 	}
 	// Delete the block and verify the execution
-	DeleteBlock(db, block.Hash(), block.NumberU64())
-	if entry := ReadBlock(db, block.Hash(), block.NumberU64()); entry != nil {
+	DeleteBlock(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
+	if entry := ReadBlock(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Deleted block returned: %v", entry)
 	}
-	if entry := ReadHeader(db, block.Hash(), block.NumberU64()); entry != nil {
+	if entry := ReadHeader(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Deleted header returned: %v", entry)
 	}
-	if entry := ReadBody(db, block.Hash(), block.NumberU64()); entry != nil {
+	if entry := ReadBody(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Deleted body returned: %v", entry)
 	}
 }
@@ -169,24 +169,24 @@ func TestPartialBlockStorage(t *testing.T) {
 		ReceiptHash: types.EmptyRootHash,
 	})
 	// Store a header and check that it's not recognized as a block
-	WriteHeader(db, block.Header())
-	if entry := ReadBlock(db, block.Hash(), block.NumberU64()); entry != nil {
+	WriteHeader(db, block.Header(), types.QuaiNetworkContext)
+	if entry := ReadBlock(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Non existent block returned: %v", entry)
 	}
-	DeleteHeader(db, block.Hash(), block.NumberU64())
+	DeleteHeader(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 
 	// Store a body and check that it's not recognized as a block
-	WriteBody(db, block.Hash(), block.NumberU64(), block.Body())
-	if entry := ReadBlock(db, block.Hash(), block.NumberU64()); entry != nil {
+	WriteBody(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext), block.Body())
+	if entry := ReadBlock(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry != nil {
 		t.Fatalf("Non existent block returned: %v", entry)
 	}
-	DeleteBody(db, block.Hash(), block.NumberU64())
+	DeleteBody(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 
 	// Store a header and a body separately and check reassembly
-	WriteHeader(db, block.Header())
-	WriteBody(db, block.Hash(), block.NumberU64(), block.Body())
+	WriteHeader(db, block.Header(), types.QuaiNetworkContext)
+	WriteBody(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext), block.Body())
 
-	if entry := ReadBlock(db, block.Hash(), block.NumberU64()); entry == nil {
+	if entry := ReadBlock(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext)); entry == nil {
 		t.Fatalf("Stored block not found")
 	} else if entry.Hash() != block.Hash() {
 		t.Fatalf("Retrieved block mismatch: have %v, want %v", entry, block)
@@ -209,7 +209,7 @@ func TestBadBlockStorage(t *testing.T) {
 		t.Fatalf("Non existent block returned: %v", entry)
 	}
 	// Write and verify the block in the database
-	WriteBadBlock(db, block)
+	WriteBadBlock(db, block, types.QuaiNetworkContext)
 	if entry := ReadBadBlock(db, block.Hash()); entry == nil {
 		t.Fatalf("Stored block not found")
 	} else if entry.Hash() != block.Hash() {
@@ -223,10 +223,10 @@ func TestBadBlockStorage(t *testing.T) {
 		TxHash:      types.EmptyRootHash,
 		ReceiptHash: types.EmptyRootHash,
 	})
-	WriteBadBlock(db, blockTwo)
+	WriteBadBlock(db, blockTwo, types.QuaiNetworkContext)
 
 	// Write the block one again, should be filtered out.
-	WriteBadBlock(db, block)
+	WriteBadBlock(db, block, types.QuaiNetworkContext)
 	badBlocks := ReadAllBadBlocks(db)
 	if len(badBlocks) != 2 {
 		t.Fatalf("Failed to load all bad blocks")
@@ -242,15 +242,15 @@ func TestBadBlockStorage(t *testing.T) {
 			TxHash:      types.EmptyRootHash,
 			ReceiptHash: types.EmptyRootHash,
 		})
-		WriteBadBlock(db, block)
+		WriteBadBlock(db, block, types.QuaiNetworkContext)
 	}
 	badBlocks = ReadAllBadBlocks(db)
 	if len(badBlocks) != badBlockToKeep {
 		t.Fatalf("The number of persised bad blocks in incorrect %d", len(badBlocks))
 	}
 	for i := 0; i < len(badBlocks)-1; i++ {
-		if badBlocks[i].NumberU64() < badBlocks[i+1].NumberU64() {
-			t.Fatalf("The bad blocks are not sorted #[%d](%d) < #[%d](%d)", i, i+1, badBlocks[i].NumberU64(), badBlocks[i+1].NumberU64())
+		if badBlocks[i].NumberU64(types.QuaiNetworkContext) < badBlocks[i+1].NumberU64(types.QuaiNetworkContext) {
+			t.Fatalf("The bad blocks are not sorted #[%d](%d) < #[%d](%d)", i, i+1, badBlocks[i].NumberU64(types.QuaiNetworkContext), badBlocks[i+1].NumberU64(types.QuaiNetworkContext))
 		}
 	}
 
@@ -458,7 +458,7 @@ func TestAncientStorage(t *testing.T) {
 		ReceiptHash: types.EmptyRootHash,
 	})
 	// Ensure nothing non-existent will be read
-	hash, number := block.Hash(), block.NumberU64()
+	hash, number := block.Hash(), block.NumberU64(types.QuaiNetworkContext)
 	if blob := ReadHeaderRLP(db, hash, number); len(blob) > 0 {
 		t.Fatalf("non existent header returned")
 	}
@@ -473,7 +473,7 @@ func TestAncientStorage(t *testing.T) {
 	}
 
 	// Write and verify the header in the database
-	WriteAncientBlocks(db, []*types.Block{block}, []types.Receipts{nil}, big.NewInt(100))
+	WriteAncientBlocks(db, []*types.Block{block}, []types.Receipts{nil}, big.NewInt(100), types.QuaiNetworkContext)
 
 	if blob := ReadHeaderRLP(db, hash, number); len(blob) == 0 {
 		t.Fatalf("no header returned")
@@ -550,7 +550,7 @@ func TestHashesInRange(t *testing.T) {
 	total := 0
 	for i := 0; i < 15; i++ {
 		for ii := 0; ii < i; ii++ {
-			WriteHeader(db, mkHeader(i, ii))
+			WriteHeader(db, mkHeader(i, ii), types.QuaiNetworkContext)
 			total++
 		}
 	}
@@ -612,7 +612,7 @@ func BenchmarkWriteAncientBlocks(b *testing.B) {
 
 		blocks := allBlocks[i : i+length]
 		receipts := batchReceipts[:length]
-		writeSize, err := WriteAncientBlocks(db, blocks, receipts, td)
+		writeSize, err := WriteAncientBlocks(db, blocks, receipts, td, types.QuaiNetworkContext)
 		if err != nil {
 			b.Fatal(err)
 		}
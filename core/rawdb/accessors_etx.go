@@ -0,0 +1,127 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethdb"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+// ReadETxStatus retrieves the lifecycle status of an external transaction.
+func ReadETxStatus(db ethdb.KeyValueReader, hash common.Hash) *types.ETxStatus {
+	data, _ := db.Get(etxStatusKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	status := new(types.ETxStatus)
+	if err := rlp.DecodeBytes(data, status); err != nil {
+		log.Error("Invalid external transaction status RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return status
+}
+
+// WriteETxStatus stores the lifecycle status of an external transaction.
+func WriteETxStatus(db ethdb.KeyValueWriter, status *types.ETxStatus) {
+	data, err := rlp.EncodeToBytes(status)
+	if err != nil {
+		log.Crit("Failed to RLP encode external transaction status", "err", err)
+	}
+	if err := db.Put(etxStatusKey(status.Hash), data); err != nil {
+		log.Crit("Failed to store external transaction status", "err", err)
+	}
+}
+
+// WritePendingETx stores an inbound external transaction waiting to be applied.
+func WritePendingETx(db ethdb.KeyValueWriter, etx *types.PendingETx) {
+	data, err := rlp.EncodeToBytes(etx)
+	if err != nil {
+		log.Crit("Failed to RLP encode pending external transaction", "err", err)
+	}
+	if err := db.Put(pendingETxKey(etx.Tx.Hash()), data); err != nil {
+		log.Crit("Failed to store pending external transaction", "err", err)
+	}
+}
+
+// DeletePendingETx removes an inbound external transaction from the pending set.
+func DeletePendingETx(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(pendingETxKey(hash)); err != nil {
+		log.Crit("Failed to delete pending external transaction", "err", err)
+	}
+}
+
+// ReadAllPendingETxs retrieves every inbound external transaction waiting to
+// be applied.
+func ReadAllPendingETxs(db ethdb.Iteratee) []*types.PendingETx {
+	it := db.NewIterator(pendingETxPrefix, nil)
+	defer it.Release()
+
+	var etxs []*types.PendingETx
+	for it.Next() {
+		if len(it.Key()) != len(pendingETxPrefix)+common.HashLength {
+			continue
+		}
+		etx := new(types.PendingETx)
+		if err := rlp.DecodeBytes(it.Value(), etx); err != nil {
+			log.Error("Invalid pending external transaction RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		etxs = append(etxs, etx)
+	}
+	return etxs
+}
+
+// WriteETxRefund stores the refund owed for an expired external transaction.
+func WriteETxRefund(db ethdb.KeyValueWriter, refund *types.ETxRefund) {
+	data, err := rlp.EncodeToBytes(refund)
+	if err != nil {
+		log.Crit("Failed to RLP encode external transaction refund", "err", err)
+	}
+	if err := db.Put(etxRefundKey(refund.Hash), data); err != nil {
+		log.Crit("Failed to store external transaction refund", "err", err)
+	}
+}
+
+// DeleteETxRefund removes the refund owed for an expired external transaction.
+func DeleteETxRefund(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(etxRefundKey(hash)); err != nil {
+		log.Crit("Failed to delete external transaction refund", "err", err)
+	}
+}
+
+// ReadAllETxRefunds retrieves every refund owed for expired external transactions.
+func ReadAllETxRefunds(db ethdb.Iteratee) []*types.ETxRefund {
+	it := db.NewIterator(etxRefundPrefix, nil)
+	defer it.Release()
+
+	var refunds []*types.ETxRefund
+	for it.Next() {
+		if len(it.Key()) != len(etxRefundPrefix)+common.HashLength {
+			continue
+		}
+		refund := new(types.ETxRefund)
+		if err := rlp.DecodeBytes(it.Value(), refund); err != nil {
+			log.Error("Invalid external transaction refund RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds
+}
@@ -0,0 +1,278 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethdb"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+// ExternalBlockIndex is the retention metadata stored alongside an external
+// block. An external block is retained until the highest local block it is
+// indexed under is pruned, which is the local head when the external block
+// arrived or the highest of the local blocks linking it, whichever is higher.
+// The same external block may be linked by blocks on several forks, each of
+// which is indexed.
+type ExternalBlockIndex struct {
+	Number     uint64        // Number of the local block retaining the external block
+	Coincident []common.Hash // Local blocks linking the external block, empty if unlinked
+}
+
+// ExternalBlockStats summarizes the external blocks stored for one context.
+type ExternalBlockStats struct {
+	Context  uint64
+	Blocks   int
+	Linked   int
+	Size     common.StorageSize
+	Earliest uint64 // Lowest local number an external block is retained under
+	Latest   uint64 // Highest local number an external block is retained under
+}
+
+// ReadExternalBlockRLP retrieves an external block in its raw RLP database encoding.
+func ReadExternalBlockRLP(db ethdb.KeyValueReader, hash common.Hash, context uint64) rlp.RawValue {
+	data, _ := db.Get(extBlockKey(context, hash))
+	return data
+}
+
+// HasExternalBlock verifies the existence of an external block corresponding
+// to the hash and context.
+func HasExternalBlock(db ethdb.Reader, hash common.Hash, context uint64) bool {
+	if has, err := db.Has(extBlockKey(context, hash)); has && err == nil {
+		return true
+	}
+	return ReadExternalHeaderRLP(db, hash, context) != nil && ReadExternalBodyRLP(db, hash, context) != nil
+}
+
+// ReadExternalBlock retrieves the external block corresponding to the hash and
+// context. External blocks stored before the external block table existed are
+// assembled back from their separately stored header and body.
+func ReadExternalBlock(db ethdb.Reader, hash common.Hash, context uint64) *types.ExternalBlock {
+	if data := ReadExternalBlockRLP(db, hash, context); len(data) > 0 {
+		block := new(types.ExternalBlock)
+		if err := rlp.DecodeBytes(data, block); err != nil {
+			log.Error("Invalid external block RLP", "hash", hash, "context", context, "err", err)
+			return nil
+		}
+		return block
+	}
+	header := ReadExternalHeader(db, hash, context)
+	if header == nil {
+		return nil
+	}
+	body := ReadExternalBody(db, hash, context)
+	if body == nil {
+		return nil
+	}
+	return types.NewExternalBlockWithHeader(header).WithBody(body.Transactions, body.Uncles, body.Receipts, body.Context)
+}
+
+// WriteExternalBlock stores an external block into the external block table.
+func WriteExternalBlock(db ethdb.KeyValueWriter, block *types.ExternalBlock) {
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		log.Crit("Failed to RLP encode external block", "err", err)
+	}
+	if err := db.Put(extBlockKey(block.Context().Uint64(), block.Hash()), data); err != nil {
+		log.Crit("Failed to store external block", "err", err)
+	}
+}
+
+// DeleteExternalBlock removes an external block along with its indexes, as
+// well as any copy of it stored in the legacy header and body layout.
+func DeleteExternalBlock(db ethdb.KeyValueStore, hash common.Hash, context uint64) {
+	if index := ReadExternalBlockIndex(db, hash, context); index != nil {
+		deleteExternalBlockIndex(db, hash, context, index)
+	}
+	for _, key := range [][]byte{extBlockIndexKey(context, hash), extBlockKey(context, hash), extHeaderKey(context, hash), extBlockBodyKey(context, hash)} {
+		if err := db.Delete(key); err != nil {
+			log.Crit("Failed to delete external block", "err", err)
+		}
+	}
+}
+
+// ReadExternalBlockIndex retrieves the retention index of an external block.
+func ReadExternalBlockIndex(db ethdb.KeyValueReader, hash common.Hash, context uint64) *ExternalBlockIndex {
+	data, _ := db.Get(extBlockIndexKey(context, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	index := new(ExternalBlockIndex)
+	if err := rlp.DecodeBytes(data, index); err != nil {
+		log.Error("Invalid external block index RLP", "hash", hash, "context", context, "err", err)
+		return nil
+	}
+	return index
+}
+
+// WriteExternalBlockIndex indexes an external block under a local block. The
+// block is retained under the highest number it was indexed under, and linked
+// by every non-zero coincident block it was indexed with.
+func WriteExternalBlockIndex(db ethdb.KeyValueStore, hash common.Hash, context uint64, number uint64, coincident common.Hash) {
+	index := ReadExternalBlockIndex(db, hash, context)
+	if index == nil {
+		index = &ExternalBlockIndex{Number: number}
+	} else if number > index.Number {
+		if err := db.Delete(extBlockRetentionKey(index.Number, context, hash)); err != nil {
+			log.Crit("Failed to delete external block retention", "err", err)
+		}
+		index.Number = number
+	}
+	if coincident != (common.Hash{}) {
+		linked := false
+		for _, have := range index.Coincident {
+			if have == coincident {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			index.Coincident = append(index.Coincident, coincident)
+		}
+		if err := db.Put(extBlockCoincidentKey(coincident, context, hash), nil); err != nil {
+			log.Crit("Failed to store external block coincidence", "err", err)
+		}
+	}
+	data, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		log.Crit("Failed to RLP encode external block index", "err", err)
+	}
+	if err := db.Put(extBlockIndexKey(context, hash), data); err != nil {
+		log.Crit("Failed to store external block index", "err", err)
+	}
+	if err := db.Put(extBlockRetentionKey(index.Number, context, hash), nil); err != nil {
+		log.Crit("Failed to store external block retention", "err", err)
+	}
+}
+
+// deleteExternalBlockIndex removes the retention and coincidence entries of an
+// external block index.
+func deleteExternalBlockIndex(db ethdb.KeyValueWriter, hash common.Hash, context uint64, index *ExternalBlockIndex) {
+	if err := db.Delete(extBlockRetentionKey(index.Number, context, hash)); err != nil {
+		log.Crit("Failed to delete external block retention", "err", err)
+	}
+	for _, coincident := range index.Coincident {
+		if err := db.Delete(extBlockCoincidentKey(coincident, context, hash)); err != nil {
+			log.Crit("Failed to delete external block coincidence", "err", err)
+		}
+	}
+}
+
+// ReadCoincidentExternalBlocks retrieves the external blocks linked by the
+// local block with the given hash.
+func ReadCoincidentExternalBlocks(db ethdb.Database, coincident common.Hash) []*types.ExternalBlock {
+	prefix := append(extBlockCoincidentPrefix, coincident.Bytes()...)
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var blocks []*types.ExternalBlock
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8+common.HashLength {
+			continue
+		}
+		context := binary.BigEndian.Uint64(key[len(prefix) : len(prefix)+8])
+		hash := common.BytesToHash(key[len(prefix)+8:])
+		if block := ReadExternalBlock(db, hash, context); block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// PruneExternalBlocks deletes the external blocks retained under a local block
+// number at or below limit and returns the number of blocks deleted.
+func PruneExternalBlocks(db ethdb.Database, limit uint64) int {
+	type entry struct {
+		context uint64
+		hash    common.Hash
+	}
+	var (
+		entries []entry
+		it      = db.NewIterator(extBlockRetentionPrefix, nil)
+		length  = len(extBlockRetentionPrefix) + 16 + common.HashLength
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != length {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[1:9]) > limit {
+			break
+		}
+		entries = append(entries, entry{binary.BigEndian.Uint64(key[9:17]), common.BytesToHash(key[17:])})
+	}
+	it.Release()
+
+	for _, entry := range entries {
+		DeleteExternalBlock(db, entry.hash, entry.context)
+	}
+	return len(entries)
+}
+
+// InspectExternalBlocks iterates the external block table and summarizes the
+// stored external blocks per context.
+func InspectExternalBlocks(db ethdb.Iteratee) []*ExternalBlockStats {
+	it := db.NewIterator(extBlockPrefix, nil)
+	defer it.Release()
+
+	var (
+		stats  = make(map[uint64]*ExternalBlockStats)
+		length = len(extBlockPrefix) + 8 + common.HashLength
+	)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != length && len(key) != length+len(extBlockIndexSuffix) {
+			continue
+		}
+		context := binary.BigEndian.Uint64(key[1:9])
+		stat, ok := stats[context]
+		if !ok {
+			stat = &ExternalBlockStats{Context: context}
+			stats[context] = stat
+		}
+		stat.Size += common.StorageSize(len(key) + len(it.Value()))
+		if len(key) == length {
+			stat.Blocks++
+			continue
+		}
+		index := new(ExternalBlockIndex)
+		if err := rlp.DecodeBytes(it.Value(), index); err != nil {
+			continue
+		}
+		if len(index.Coincident) > 0 {
+			stat.Linked++
+		}
+		if stat.Earliest == 0 || index.Number < stat.Earliest {
+			stat.Earliest = index.Number
+		}
+		if index.Number > stat.Latest {
+			stat.Latest = index.Number
+		}
+	}
+	result := make([]*ExternalBlockStats, 0, len(stats))
+	for context := uint64(0); context < uint64(types.ContextDepth); context++ {
+		if stat, ok := stats[context]; ok {
+			result = append(result, stat)
+		}
+	}
+	return result
+}
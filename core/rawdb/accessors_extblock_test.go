@@ -0,0 +1,119 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+)
+
+// newTestExternalBlock creates an external block of a context, tagged with a
+// seed to make it unique.
+func newTestExternalBlock(context uint64, seed byte) *types.ExternalBlock {
+	header := types.NewEmptyHeader()
+	for i := range header.Number {
+		header.Number[i] = big.NewInt(int64(seed))
+	}
+	header.Extra[context] = []byte{seed}
+	return types.NewExternalBlockWithHeader(header).WithBody(nil, nil, nil, new(big.Int).SetUint64(context))
+}
+
+// Tests that an external block linked by blocks on several forks is indexed
+// under every one of them, and retained under the highest.
+func TestExternalBlockIndex(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	block := newTestExternalBlock(0, 1)
+	hash, context := block.Hash(), block.Context().Uint64()
+	WriteExternalBlock(db, block)
+
+	// An unlinked external block is retained under the head it arrived at
+	WriteExternalBlockIndex(db, hash, context, 10, common.Hash{})
+	if index := ReadExternalBlockIndex(db, hash, context); index == nil || index.Number != 10 || len(index.Coincident) != 0 {
+		t.Fatalf("unlinked index mismatch: have %+v", index)
+	}
+	// Blocks on two forks link it, each must find it
+	forkA, forkB := common.Hash{0xa}, common.Hash{0xb}
+	WriteExternalBlockIndex(db, hash, context, 12, forkA)
+	WriteExternalBlockIndex(db, hash, context, 11, forkB)
+	WriteExternalBlockIndex(db, hash, context, 12, forkA)
+
+	index := ReadExternalBlockIndex(db, hash, context)
+	if index == nil || index.Number != 12 {
+		t.Fatalf("linked index mismatch: have %+v, want retention 12", index)
+	}
+	if len(index.Coincident) != 2 || index.Coincident[0] != forkA || index.Coincident[1] != forkB {
+		t.Fatalf("coincident mismatch: have %x, want [%x %x]", index.Coincident, forkA, forkB)
+	}
+	for _, coincident := range []common.Hash{forkA, forkB} {
+		if blocks := ReadCoincidentExternalBlocks(db, coincident); len(blocks) != 1 || blocks[0].Hash() != hash {
+			t.Errorf("block %x links %d external blocks, want 1", coincident, len(blocks))
+		}
+	}
+	// The retention moved to the highest linking block only
+	if pruned := PruneExternalBlocks(db, 11); pruned != 0 {
+		t.Fatalf("pruned %d external blocks below their retention", pruned)
+	}
+	if stats := InspectExternalBlocks(db); len(stats) != 1 || stats[0].Blocks != 1 || stats[0].Linked != 1 || stats[0].Latest != 12 {
+		t.Fatalf("stats mismatch: have %+v", stats)
+	}
+}
+
+// Tests that pruning deletes the external blocks retained at or below the
+// limit along with every index entry, keeping the ones retained above it.
+func TestPruneExternalBlocks(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var blocks []*types.ExternalBlock
+	for i := 0; i < 6; i++ {
+		block := newTestExternalBlock(uint64(i%types.ContextDepth), byte(i))
+		WriteExternalBlock(db, block)
+		WriteExternalBlockIndex(db, block.Hash(), block.Context().Uint64(), uint64(i), common.Hash{byte(i)})
+		WriteExternalBlockIndex(db, block.Hash(), block.Context().Uint64(), uint64(i), common.Hash{0xff})
+		blocks = append(blocks, block)
+	}
+	if pruned := PruneExternalBlocks(db, 2); pruned != 3 {
+		t.Fatalf("pruned external blocks mismatch: have %d, want 3", pruned)
+	}
+	for i, block := range blocks {
+		hash, context := block.Hash(), block.Context().Uint64()
+		if i <= 2 {
+			if HasExternalBlock(db, hash, context) || ReadExternalBlockIndex(db, hash, context) != nil {
+				t.Errorf("external block %d not pruned", i)
+			}
+			if linked := ReadCoincidentExternalBlocks(db, common.Hash{byte(i)}); len(linked) != 0 {
+				t.Errorf("external block %d still linked", i)
+			}
+			if has, _ := db.Has(extBlockRetentionKey(uint64(i), context, hash)); has {
+				t.Errorf("external block %d retention not pruned", i)
+			}
+			continue
+		}
+		if ReadExternalBlock(db, hash, context) == nil || ReadExternalBlockIndex(db, hash, context) == nil {
+			t.Errorf("external block %d pruned above the limit", i)
+		}
+	}
+	if linked := ReadCoincidentExternalBlocks(db, common.Hash{0xff}); len(linked) != 3 {
+		t.Errorf("shared block links %d external blocks, want 3", len(linked))
+	}
+	if pruned := PruneExternalBlocks(db, 2); pruned != 0 {
+		t.Errorf("pruned %d external blocks twice", pruned)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethdb"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+// HasFaultProof checks whether the fault proof with the given hash is stored.
+func HasFaultProof(db ethdb.KeyValueReader, hash common.Hash) bool {
+	has, _ := db.Has(faultProofKey(hash))
+	return has
+}
+
+// ReadFaultProof retrieves the fault proof with the given hash.
+func ReadFaultProof(db ethdb.KeyValueReader, hash common.Hash) *types.FaultProof {
+	data, _ := db.Get(faultProofKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	proof := new(types.FaultProof)
+	if err := rlp.DecodeBytes(data, proof); err != nil {
+		log.Error("Invalid fault proof RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return proof
+}
+
+// WriteFaultProof stores a fault proof.
+func WriteFaultProof(db ethdb.KeyValueWriter, proof *types.FaultProof) {
+	data, err := rlp.EncodeToBytes(proof)
+	if err != nil {
+		log.Crit("Failed to RLP encode fault proof", "err", err)
+	}
+	if err := db.Put(faultProofKey(proof.Hash()), data); err != nil {
+		log.Crit("Failed to store fault proof", "err", err)
+	}
+}
+
+// ReadAllFaultProofs retrieves every stored fault proof.
+func ReadAllFaultProofs(db ethdb.Iteratee) []*types.FaultProof {
+	it := db.NewIterator(faultProofPrefix, nil)
+	defer it.Release()
+
+	var proofs []*types.FaultProof
+	for it.Next() {
+		if len(it.Key()) != len(faultProofPrefix)+common.HashLength {
+			continue
+		}
+		proof := new(types.FaultProof)
+		if err := rlp.DecodeBytes(it.Value(), proof); err != nil {
+			log.Error("Invalid fault proof RLP", "key", it.Key(), "err", err)
+			continue
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs
+}
@@ -63,7 +63,7 @@ func TestLookupStorage(t *testing.T) {
 		{
 			"DatabaseV6",
 			func(db ethdb.Writer, block *types.Block) {
-				WriteTxLookupEntriesByBlock(db, block)
+				WriteTxLookupEntriesByBlock(db, block, types.QuaiNetworkContext)
 			},
 		},
 		{
@@ -80,7 +80,7 @@ func TestLookupStorage(t *testing.T) {
 				for index, tx := range block.Transactions() {
 					entry := LegacyTxLookupEntry{
 						BlockHash:  block.Hash(),
-						BlockIndex: block.NumberU64(),
+						BlockIndex: block.NumberU64(types.QuaiNetworkContext),
 						Index:      uint64(index),
 					}
 					data, _ := rlp.EncodeToBytes(entry)
@@ -108,16 +108,16 @@ func TestLookupStorage(t *testing.T) {
 				}
 			}
 			// Insert all the transactions into the database, and verify contents
-			WriteCanonicalHash(db, block.Hash(), block.NumberU64())
-			WriteBlock(db, block)
+			WriteCanonicalHash(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
+			WriteBlock(db, block, types.QuaiNetworkContext)
 			tc.writeTxLookupEntriesByBlock(db, block)
 
 			for i, tx := range txs {
 				if txn, hash, number, index := ReadTransaction(db, tx.Hash()); txn == nil {
 					t.Fatalf("tx #%d [%x]: transaction not found", i, tx.Hash())
 				} else {
-					if hash != block.Hash() || number != block.NumberU64() || index != uint64(i) {
-						t.Fatalf("tx #%d [%x]: positional metadata mismatch: have %x/%d/%d, want %x/%v/%v", i, tx.Hash(), hash, number, index, block.Hash(), block.NumberU64(), i)
+					if hash != block.Hash() || number != block.NumberU64(types.QuaiNetworkContext) || index != uint64(i) {
+						t.Fatalf("tx #%d [%x]: positional metadata mismatch: have %x/%d/%d, want %x/%v/%v", i, tx.Hash(), hash, number, index, block.Hash(), block.NumberU64(types.QuaiNetworkContext), i)
 					}
 					if tx.Hash() != txn.Hash() {
 						t.Fatalf("tx #%d [%x]: transaction mismatch: have %v, want %v", i, tx.Hash(), txn, tx)
@@ -0,0 +1,49 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/ethdb"
+	"github.com/spruce-solutions/go-quai/log"
+)
+
+// ReadPreviousCoincident retrieves the hash of the previous block of the given
+// order coincident with the header with the given hash, found searching its
+// ancestors along the path in the slice.
+func ReadPreviousCoincident(db ethdb.KeyValueReader, hash common.Hash, slice []byte, order, path int, fullSliceEqual bool) common.Hash {
+	data, _ := db.Get(previousCoincidentKey(hash, slice, order, path, fullSliceEqual))
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WritePreviousCoincident stores the hash of the previous coincident block found
+// for a header.
+func WritePreviousCoincident(db ethdb.KeyValueWriter, hash common.Hash, slice []byte, order, path int, fullSliceEqual bool, coincident common.Hash) {
+	if err := db.Put(previousCoincidentKey(hash, slice, order, path, fullSliceEqual), coincident.Bytes()); err != nil {
+		log.Crit("Failed to store previous coincident block", "err", err)
+	}
+}
+
+// DeletePreviousCoincident removes the previous coincident block of a header.
+func DeletePreviousCoincident(db ethdb.KeyValueWriter, hash common.Hash, slice []byte, order, path int, fullSliceEqual bool) {
+	if err := db.Delete(previousCoincidentKey(hash, slice, order, path, fullSliceEqual)); err != nil {
+		log.Crit("Failed to delete previous coincident block", "err", err)
+	}
+}
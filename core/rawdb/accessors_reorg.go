@@ -0,0 +1,72 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethdb"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+// ReadReorgJournalHead retrieves the number of the latest reorg journal entry,
+// or 0 if nothing was journaled yet.
+func ReadReorgJournalHead(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(reorgJournalHeadKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// ReadReorgRecord retrieves the reorg journal entry with the given number.
+func ReadReorgRecord(db ethdb.KeyValueReader, number uint64) *types.ReorgRecord {
+	data, _ := db.Get(reorgJournalKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	record := new(types.ReorgRecord)
+	if err := rlp.DecodeBytes(data, record); err != nil {
+		log.Error("Invalid reorg journal entry RLP", "number", number, "err", err)
+		return nil
+	}
+	return record
+}
+
+// WriteReorgRecord stores a reorg journal entry and makes it the head of the
+// journal.
+func WriteReorgRecord(db ethdb.KeyValueWriter, record *types.ReorgRecord) {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		log.Crit("Failed to RLP encode reorg journal entry", "err", err)
+	}
+	if err := db.Put(reorgJournalKey(record.Number), data); err != nil {
+		log.Crit("Failed to store reorg journal entry", "err", err)
+	}
+	if err := db.Put(reorgJournalHeadKey, encodeBlockNumber(record.Number)); err != nil {
+		log.Crit("Failed to store reorg journal head", "err", err)
+	}
+}
+
+// DeleteReorgRecord removes the reorg journal entry with the given number.
+func DeleteReorgRecord(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(reorgJournalKey(number)); err != nil {
+		log.Crit("Failed to delete reorg journal entry", "err", err)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethdb"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+// ReadSupply retrieves the supply record of the block with the given hash.
+func ReadSupply(db ethdb.KeyValueReader, hash common.Hash) *types.Supply {
+	data, _ := db.Get(supplyKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	supply := new(types.Supply)
+	if err := rlp.DecodeBytes(data, supply); err != nil {
+		log.Error("Invalid supply record RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return supply
+}
+
+// WriteSupply stores the supply record of a block.
+func WriteSupply(db ethdb.KeyValueWriter, supply *types.Supply) {
+	data, err := rlp.EncodeToBytes(supply)
+	if err != nil {
+		log.Crit("Failed to RLP encode supply record", "err", err)
+	}
+	if err := db.Put(supplyKey(supply.Hash), data); err != nil {
+		log.Crit("Failed to store supply record", "err", err)
+	}
+}
+
+// DeleteSupply removes the supply record of the block with the given hash.
+func DeleteSupply(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(supplyKey(hash)); err != nil {
+		log.Crit("Failed to delete supply record", "err", err)
+	}
+}
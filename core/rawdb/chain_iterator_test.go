@@ -35,8 +35,8 @@ func TestChainIterator(t *testing.T) {
 	var txs []*types.Transaction
 	to := common.BytesToAddress([]byte{0x11})
 	block = types.NewBlock(&types.Header{Number: []*big.Int{big.NewInt(int64(0)), big.NewInt(int64(0)), big.NewInt(int64(0))}}, nil, nil, nil, newHasher()) // Empty genesis block
-	WriteBlock(chainDb, block)
-	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	WriteBlock(chainDb, block, types.QuaiNetworkContext)
+	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 	for i := uint64(1); i <= 10; i++ {
 		var tx *types.Transaction
 		if i%2 == 0 {
@@ -61,8 +61,8 @@ func TestChainIterator(t *testing.T) {
 		}
 		txs = append(txs, tx)
 		block = types.NewBlock(&types.Header{Number: []*big.Int{big.NewInt(int64(i)), big.NewInt(int64(i)), big.NewInt(int64(i))}}, []*types.Transaction{tx}, nil, nil, newHasher())
-		WriteBlock(chainDb, block)
-		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+		WriteBlock(chainDb, block, types.QuaiNetworkContext)
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 	}
 
 	var cases = []struct {
@@ -112,8 +112,8 @@ func TestIndexTransactions(t *testing.T) {
 
 	// Write empty genesis block
 	block = types.NewBlock(&types.Header{Number: []*big.Int{big.NewInt(int64(0)), big.NewInt(int64(0)), big.NewInt(int64(0))}}, nil, nil, nil, newHasher())
-	WriteBlock(chainDb, block)
-	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	WriteBlock(chainDb, block, types.QuaiNetworkContext)
+	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 
 	for i := uint64(1); i <= 10; i++ {
 		var tx *types.Transaction
@@ -139,8 +139,8 @@ func TestIndexTransactions(t *testing.T) {
 		}
 		txs = append(txs, tx)
 		block = types.NewBlock(&types.Header{Number: []*big.Int{big.NewInt(int64(i)), big.NewInt(int64(i)), big.NewInt(int64(i))}}, []*types.Transaction{tx}, nil, nil, newHasher())
-		WriteBlock(chainDb, block)
-		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+		WriteBlock(chainDb, block, types.QuaiNetworkContext)
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 	}
 	// verify checks whether the tx indices in the range [from, to)
 	// is expected.
@@ -29,6 +29,7 @@ import (
 	"github.com/spruce-solutions/go-quai/ethdb/leveldb"
 	"github.com/spruce-solutions/go-quai/ethdb/memorydb"
 	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/olekukonko/tablewriter"
 )
 
@@ -74,6 +75,17 @@ func (frdb *freezerdb) Freeze(threshold uint64) error {
 	return nil
 }
 
+// SetFreezerContext configures the freezer of the database, if it has one, to
+// freeze the blocks of the chain running in the given context.
+func SetFreezerContext(db ethdb.Database, context int) {
+	frdb, ok := db.(*freezerdb)
+	if !ok {
+		return
+	}
+	atomic.StoreUint64(&frdb.AncientStore.(*freezer).threshold, params.FullImmutabilityThreshold[context])
+	atomic.StoreUint64(&frdb.AncientStore.(*freezer).context, uint64(context))
+}
+
 // nofreezedb is a database wrapper that disables freezer data retrievals.
 type nofreezedb struct {
 	ethdb.KeyValueStore
@@ -306,6 +318,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		preimages       stat
 		bloomBits       stat
 		cliqueSnaps     stat
+		extBlocks       stat
+		extBlockIndexes stat
 
 		// Ancient store statistics
 		ancientHeadersSize  common.StorageSize
@@ -345,6 +359,14 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			numHashPairings.Add(size)
 		case bytes.HasPrefix(key, headerNumberPrefix) && len(key) == (len(headerNumberPrefix)+common.HashLength):
 			hashNumPairings.Add(size)
+		case bytes.HasPrefix(key, extBlockPrefix) && len(key) == (len(extBlockPrefix)+8+common.HashLength):
+			extBlocks.Add(size)
+		case bytes.HasPrefix(key, extBlockPrefix) && len(key) == (len(extBlockPrefix)+8+common.HashLength+len(extBlockIndexSuffix)):
+			extBlockIndexes.Add(size)
+		case bytes.HasPrefix(key, extBlockCoincidentPrefix) && len(key) == (len(extBlockCoincidentPrefix)+8+2*common.HashLength):
+			extBlockIndexes.Add(size)
+		case bytes.HasPrefix(key, extBlockRetentionPrefix) && len(key) == (len(extBlockRetentionPrefix)+16+common.HashLength):
+			extBlockIndexes.Add(size)
 		case len(key) == common.HashLength:
 			tries.Add(size)
 		case bytes.HasPrefix(key, CodePrefix) && len(key) == len(CodePrefix)+common.HashLength:
@@ -419,6 +441,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
 		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
+		{"Key-Value store", "External blocks", extBlocks.Size(), extBlocks.Count()},
+		{"Key-Value store", "External block index", extBlockIndexes.Size(), extBlockIndexes.Count()},
 		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Trie nodes", tries.Size(), tries.Count()},
@@ -28,7 +28,6 @@ import (
 
 	"github.com/prometheus/tsdb/fileutil"
 	"github.com/spruce-solutions/go-quai/common"
-	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/ethdb"
 	"github.com/spruce-solutions/go-quai/log"
 	"github.com/spruce-solutions/go-quai/metrics"
@@ -80,6 +79,7 @@ type freezer struct {
 	// so take advantage of that (https://golang.org/pkg/sync/atomic/#pkg-note-BUG).
 	frozen    uint64 // Number of blocks already frozen
 	threshold uint64 // Number of recent blocks not to freeze (params.FullImmutabilityThreshold apart from tests)
+	context   uint64 // Context of the chain whose blocks are frozen
 
 	// This lock synchronizes writers and the truncate operation.
 	writeLock  sync.Mutex
@@ -124,7 +124,7 @@ func newFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 	// Open all the supported data tables
 	freezer := &freezer{
 		readonly:     readonly,
-		threshold:    params.FullImmutabilityThreshold[types.QuaiNetworkContext],
+		threshold:    params.FullImmutabilityThreshold[params.PRIME],
 		tables:       make(map[string]*freezerTable),
 		instanceLock: lock,
 		trigger:      make(chan chan struct{}),
@@ -454,7 +454,7 @@ func (f *freezer) freeze(db ethdb.KeyValueStore) {
 						log.Error("Missing dangling header", "number", tip, "hash", children[i])
 						continue
 					}
-					if _, ok := drop[child.ParentHash[types.QuaiNetworkContext]]; !ok {
+					if _, ok := drop[child.ParentHash[atomic.LoadUint64(&f.context)]]; !ok {
 						children = append(children[:i], children[i+1:]...)
 						i--
 						continue
@@ -72,6 +72,9 @@ var (
 	// badBlockKey tracks the list of bad blocks seen by local
 	badBlockKey = []byte("InvalidBlock")
 
+	// reorgJournalHeadKey tracks the number of the latest reorg journal entry.
+	reorgJournalHeadKey = []byte("ReorgJournalHead")
+
 	// uncleanShutdownKey tracks the list of local crashes
 	uncleanShutdownKey = []byte("unclean-shutdown") // config prefix for the db
 
@@ -84,6 +87,18 @@ var (
 	blockBodyPrefix     = []byte("b") // blockBodyPrefix + num (uint64 big endian) + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 	extBlockBodyPrefix  = []byte("e") // extBlockBodyPrefix + num (uint64 big endian) + hash -> block body
+	etxStatusPrefix     = []byte("x") // etxStatusPrefix + hash -> external transaction lifecycle status
+	pendingETxPrefix    = []byte("X") // pendingETxPrefix + hash -> pending external transaction
+	etxRefundPrefix     = []byte("F") // etxRefundPrefix + hash -> expired external transaction refund
+	reorgJournalPrefix  = []byte("J") // reorgJournalPrefix + num (uint64 big endian) -> reorg journal entry
+	coincidentPrefix    = []byte("T") // coincidentPrefix + hash + order + path + full slice flag + slice -> previous coincident block hash
+	faultProofPrefix    = []byte("V") // faultProofPrefix + hash -> fault proof of an invalid block
+	supplyPrefix        = []byte("S") // supplyPrefix + hash -> supply record of a zone block
+
+	extBlockPrefix           = []byte("E") // extBlockPrefix + context (uint64 big endian) + hash -> external block
+	extBlockIndexSuffix      = []byte("m") // extBlockPrefix + context (uint64 big endian) + hash + extBlockIndexSuffix -> retention index
+	extBlockCoincidentPrefix = []byte("K") // extBlockCoincidentPrefix + coincident hash + context (uint64 big endian) + hash -> nil
+	extBlockRetentionPrefix  = []byte("W") // extBlockRetentionPrefix + num (uint64 big endian) + context (uint64 big endian) + hash -> nil
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
@@ -193,6 +208,65 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// etxStatusKey = etxStatusPrefix + hash
+func etxStatusKey(hash common.Hash) []byte {
+	return append(etxStatusPrefix, hash.Bytes()...)
+}
+
+// pendingETxKey = pendingETxPrefix + hash
+func pendingETxKey(hash common.Hash) []byte {
+	return append(pendingETxPrefix, hash.Bytes()...)
+}
+
+// etxRefundKey = etxRefundPrefix + hash
+func etxRefundKey(hash common.Hash) []byte {
+	return append(etxRefundPrefix, hash.Bytes()...)
+}
+
+// reorgJournalKey = reorgJournalPrefix + num (uint64 big endian)
+func reorgJournalKey(number uint64) []byte {
+	return append(reorgJournalPrefix, encodeBlockNumber(number)...)
+}
+
+// faultProofKey = faultProofPrefix + hash
+func faultProofKey(hash common.Hash) []byte {
+	return append(faultProofPrefix, hash.Bytes()...)
+}
+
+// supplyKey = supplyPrefix + hash
+func supplyKey(hash common.Hash) []byte {
+	return append(supplyPrefix, hash.Bytes()...)
+}
+
+// extBlockKey = extBlockPrefix + context (uint64 big endian) + hash
+func extBlockKey(context uint64, hash common.Hash) []byte {
+	return append(append(extBlockPrefix, encodeBlockNumber(context)...), hash.Bytes()...)
+}
+
+// extBlockIndexKey = extBlockPrefix + context (uint64 big endian) + hash + extBlockIndexSuffix
+func extBlockIndexKey(context uint64, hash common.Hash) []byte {
+	return append(extBlockKey(context, hash), extBlockIndexSuffix...)
+}
+
+// extBlockCoincidentKey = extBlockCoincidentPrefix + coincident hash + context (uint64 big endian) + hash
+func extBlockCoincidentKey(coincident common.Hash, context uint64, hash common.Hash) []byte {
+	return append(append(append(extBlockCoincidentPrefix, coincident.Bytes()...), encodeBlockNumber(context)...), hash.Bytes()...)
+}
+
+// extBlockRetentionKey = extBlockRetentionPrefix + num (uint64 big endian) + context (uint64 big endian) + hash
+func extBlockRetentionKey(number uint64, context uint64, hash common.Hash) []byte {
+	return append(append(append(extBlockRetentionPrefix, encodeBlockNumber(number)...), encodeBlockNumber(context)...), hash.Bytes()...)
+}
+
+// previousCoincidentKey = coincidentPrefix + hash + order + path + full slice flag + slice
+func previousCoincidentKey(hash common.Hash, slice []byte, order, path int, fullSliceEqual bool) []byte {
+	key := append(append(coincidentPrefix, hash.Bytes()...), byte(order), byte(path), 0)
+	if fullSliceEqual {
+		key[len(key)-1] = 1
+	}
+	return append(key, slice...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
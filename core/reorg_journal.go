@@ -0,0 +1,232 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// reorgJournalLimit is the number of most recent rollups kept in the journal.
+const reorgJournalLimit = 1024
+
+// pendingReorg is a rollup about to be journaled, together with the ETxs its
+// dropped blocks applied and the refunds they paid, which the added blocks
+// didn't pay again.
+type pendingReorg struct {
+	record   *types.ReorgRecord
+	reverted []*types.PendingETx
+	unpaid   []*types.ETxRefund
+}
+
+// prepareReorg describes the rollup of the chain from the dropped to the added
+// blocks onto the common block. It must be called while the dropped blocks are
+// still stored, as their ETxs are looked up.
+func (bc *BlockChain) prepareReorg(kind types.ReorgKind, trigger *types.Header, commonBlock *types.Block, dropped, added types.Blocks) *pendingReorg {
+	context := bc.chainConfig.Context
+	record := &types.ReorgRecord{
+		Time:           uint64(time.Now().Unix()),
+		Kind:           kind,
+		Context:        uint64(context),
+		TriggerContext: uint64(context),
+		CommonAncestor: commonBlock.Hash(),
+		CommonNumber:   commonBlock.NumberU64(context),
+		Dropped:        make([][]common.Hash, types.ContextDepth),
+		Added:          make([][]common.Hash, types.ContextDepth),
+	}
+	if trigger != nil {
+		record.Trigger = trigger.Hash()
+		if order, err := bc.engine.GetDifficultyOrder(trigger); err == nil {
+			record.TriggerContext = uint64(order)
+		}
+	}
+	bc.addCoincidentHashes(record.Dropped, dropped)
+	bc.addCoincidentHashes(record.Added, added)
+	if context < params.ZONE {
+		for _, block := range added {
+			record.NewSubs = append(record.NewSubs, block.Hash())
+		}
+	}
+	included := make(map[common.Hash]struct{})
+	for _, block := range added {
+		for _, tx := range block.Transactions() {
+			included[tx.Hash()] = struct{}{}
+		}
+	}
+	for _, block := range dropped {
+		for _, tx := range block.Transactions() {
+			if _, ok := included[tx.Hash()]; !ok {
+				record.RemovedTxs = append(record.RemovedTxs, tx.Hash())
+			}
+		}
+	}
+	paid := make(map[common.Hash]struct{})
+	for _, refund := range bc.paidRefunds(added) {
+		paid[refund.Hash] = struct{}{}
+	}
+	pending := &pendingReorg{record: record, reverted: bc.appliedETxs(dropped)}
+	for _, refund := range bc.paidRefunds(dropped) {
+		if _, ok := paid[refund.Hash]; !ok {
+			pending.unpaid = append(pending.unpaid, refund)
+		}
+	}
+	for _, etx := range pending.reverted {
+		record.RevertedETxs = append(record.RevertedETxs, etx.Tx.Hash())
+	}
+	return pending
+}
+
+// addCoincidentHashes adds the hashes of the blocks to the lists of every
+// context they are coincident with.
+func (bc *BlockChain) addCoincidentHashes(lists [][]common.Hash, blocks types.Blocks) {
+	for _, block := range blocks {
+		order, err := bc.engine.GetDifficultyOrder(block.Header())
+		if err != nil {
+			order = bc.chainConfig.Context
+		}
+		for context := order; context < types.ContextDepth; context++ {
+			lists[context] = append(lists[context], block.Hash())
+		}
+	}
+}
+
+// appliedETxs returns the inbound ETxs applied by the given blocks.
+func (bc *BlockChain) appliedETxs(blocks types.Blocks) []*types.PendingETx {
+	var etxs []*types.PendingETx
+	for _, block := range blocks {
+		externalBlocks, err := bc.engine.GetExternalBlocks(bc, block.Header(), false)
+		if err != nil {
+			log.Warn("Failed to retrieve external blocks of dropped block", "hash", block.Hash(), "err", err)
+			continue
+		}
+		for _, externalBlock := range externalBlocks {
+			for _, tx := range externalBlock.Transactions() {
+				sender, ok := bc.inboundETxSender(tx, block.Header())
+				if !ok {
+					continue
+				}
+				status := bc.GetETxStatus(tx.Hash())
+				if status == nil || status.DestinationBlock != block.Hash() {
+					continue
+				}
+				etxs = append(etxs, &types.PendingETx{
+					Tx:             tx,
+					Sender:         sender,
+					ExternalBlock:  externalBlock.Hash(),
+					OriginLocation: externalBlock.Header().Location,
+				})
+			}
+		}
+	}
+	return etxs
+}
+
+// paidRefunds returns the refunds paid by the given blocks to the senders of
+// ETxs which failed in other zones.
+func (bc *BlockChain) paidRefunds(blocks types.Blocks) []*types.ETxRefund {
+	var refunds []*types.ETxRefund
+	for _, block := range blocks {
+		externalBlocks, err := bc.engine.GetExternalBlocks(bc, block.Header(), false)
+		if err != nil {
+			log.Warn("Failed to retrieve external blocks of rolled up block", "hash", block.Hash(), "err", err)
+			continue
+		}
+		for _, externalBlock := range externalBlocks {
+			refunds = append(refunds, ETxRefunds(bc.chainConfig, block.Header(), externalBlock)...)
+		}
+	}
+	return refunds
+}
+
+// journalReorg stores a prepared rollup in the reorg journal once the chain
+// rolled up, returns the ETxs applied by the dropped blocks to the ETx pool,
+// owes the refunds they paid again and announces the rollup to the subscribers.
+func (bc *BlockChain) journalReorg(pending *pendingReorg) {
+	arrival := bc.CurrentBlock().Header().Number[bc.chainConfig.Context].Uint64()
+	for _, etx := range pending.reverted {
+		etx.Arrival = arrival
+		bc.etxPool.add(etx)
+
+		status := &types.ETxStatus{
+			Hash:           etx.Tx.Hash(),
+			Stage:          types.ETxAvailable,
+			OriginBlock:    etx.ExternalBlock,
+			OriginLocation: etx.OriginLocation,
+		}
+		if prev := bc.GetETxStatus(etx.Tx.Hash()); prev != nil {
+			status.ReferenceContext = prev.ReferenceContext
+		}
+		// The ETx moves back in its lifecycle, which updateETxStatus refuses
+		rawdb.WriteETxStatus(bc.db, status)
+		bc.etxStatusFeed.Send(ETxStatusEvent{Status: status})
+	}
+	for _, refund := range pending.unpaid {
+		bc.etxPool.addRefund(refund)
+
+		status := &types.ETxStatus{
+			Hash:             refund.Hash,
+			Stage:            types.ETxFailed,
+			OriginLocation:   bc.chainConfig.Location,
+			DestinationBlock: refund.DestinationBlock,
+			Error:            "failed in destination",
+		}
+		if prev := bc.GetETxStatus(refund.Hash); prev != nil {
+			status.OriginBlock, status.ReferenceContext = prev.OriginBlock, prev.ReferenceContext
+		}
+		rawdb.WriteETxStatus(bc.db, status)
+		bc.etxStatusFeed.Send(ETxStatusEvent{Status: status})
+	}
+	record := pending.record
+
+	bc.reorgJournalLock.Lock()
+	record.Number = rawdb.ReadReorgJournalHead(bc.db) + 1
+	rawdb.WriteReorgRecord(bc.db, record)
+	if record.Number > reorgJournalLimit {
+		rawdb.DeleteReorgRecord(bc.db, record.Number-reorgJournalLimit)
+	}
+	bc.reorgJournalLock.Unlock()
+
+	log.Info("Journaled chain rollup", "number", record.Number, "kind", record.Kind, "trigger", record.TriggerContext,
+		"ancestor", record.CommonAncestor, "drop", len(record.Dropped[record.Context]), "add", len(record.Added[record.Context]),
+		"txs", len(record.RemovedTxs), "etxs", len(record.RevertedETxs))
+	bc.reorgJournalFeed.Send(ReorgJournalEvent{Record: record})
+}
+
+// GetReorgHistory returns up to count of the most recent rollups of the chain,
+// newest first.
+func (bc *BlockChain) GetReorgHistory(count uint64) []*types.ReorgRecord {
+	var records []*types.ReorgRecord
+	for number := rawdb.ReadReorgJournalHead(bc.db); number > 0 && uint64(len(records)) < count; number-- {
+		record := rawdb.ReadReorgRecord(bc.db, number)
+		if record == nil {
+			break
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// SubscribeReorgJournalEvent registers a subscription of ReorgJournalEvent.
+func (bc *BlockChain) SubscribeReorgJournalEvent(ch chan<- ReorgJournalEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgJournalFeed.Subscribe(ch))
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -65,12 +66,12 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		usedGas     = new(uint64)
 		header      = block.Header()
 		blockHash   = block.Hash()
-		blockNumber = block.Number()
+		blockNumber = block.Number(p.config.Context)
 		allLogs     []*types.Log
-		gp          = new(GasPool).AddGas(block.GasLimit())
+		gp          = new(GasPool).AddGas(block.GasLimit(p.config.Context))
 	)
 
-	blockContext := NewEVMBlockContext(header, p.bc, nil)
+	blockContext := NewEVMBlockContext(header, p.bc, nil, p.config.Context)
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, p.config, cfg)
 
 	// Gather external blocks and apply transactions, need to trace own local external block cache based on cache to validate.
@@ -80,7 +81,6 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		return nil, nil, uint64(0), nil, err
 	}
 
-	etxs := 0
 	for _, externalBlock := range externalBlocks {
 		externalBlock.Receipts().DeriveFields(p.config, externalBlock.Hash(), externalBlock.Header().Number[externalBlock.Context().Int64()].Uint64(), externalBlock.Transactions())
 
@@ -89,45 +89,43 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 			fmt.Println("Bad external block: Transaction hash not equal to txs", externalBlock.Header().TxHash[externalBlock.Context().Int64()], hashedTxList)
 			return nil, nil, uint64(0), nil, fmt.Errorf("bad external block: transaction hash not equal to txs %v, %v", externalBlock.Header().TxHash[externalBlock.Context().Int64()], hashedTxList)
 		}
-
-		for _, tx := range externalBlock.Transactions() {
-			msg, err := tx.AsMessage(types.MakeSigner(p.config, header.Number[types.QuaiNetworkContext]), header.BaseFee[types.QuaiNetworkContext])
-			// Quick check to make sure we're adding an external transaction, currently saves us from not passing merkel path in external block
-			if err != nil {
-				return nil, nil, 0, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
-			}
-
-			if !msg.FromExternal() || !params.CheckETxChainID(p.config.ChainID, tx.ChainId()) {
-				continue
-			}
-			fmt.Println("Applying etx", tx.Hash().Hex(), msg.From(), msg.To(), msg.Value())
-			statedb.Prepare(tx.Hash(), i)
-			receipt, err := applyExternalTransaction(msg, p.config, p.bc, nil, gp, statedb, blockNumber, blockHash, externalBlock, tx, usedGas, vmenv)
-			if err != nil {
-				log.Warn("Could not apply etx", "i", i, "hash", tx.Hash().Hex(), "err", err)
-				return nil, nil, uint64(0), nil, err
-			}
-			receipts = append(receipts, receipt)
-			allLogs = append(allLogs, receipt.Logs...)
-			etxs += 1
-			i++
-		}
+		ApplyETxRefunds(p.config, statedb, header, externalBlock)
 	}
 
-	// Iterate over and process the individual transactions.
+	// Iterate over and process the individual transactions. The ETxs of the
+	// block come from its external blocks or from the ETx pool, and the miner
+	// leaves out the ones which fail to apply until a later block retries them.
+	etxs := make(map[common.Hash]bool)
 	for _, tx := range block.Transactions() {
-		msg, err := tx.AsMessage(types.MakeSigner(p.config, header.Number[types.QuaiNetworkContext]), header.BaseFee[types.QuaiNetworkContext])
+		msg, err := tx.AsMessage(types.MakeSigner(p.config, header.Number[p.config.Context]), header.BaseFee[p.config.Context])
 		if err != nil {
 			return nil, nil, 0, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
-		// All ETxs applied to state must be generated from our cache.
-		if msg.FromExternal() {
-			continue
-		}
 		statedb.Prepare(tx.Hash(), i)
-		receipt, err := applyTransaction(msg, p.config, p.bc, nil, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
-		if err != nil {
-			return nil, nil, 0, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+
+		var receipt *types.Receipt
+		if msg.FromExternal() {
+			if etxs[tx.Hash()] {
+				return nil, nil, 0, nil, fmt.Errorf("could not apply etx %d [%v]: %w", i, tx.Hash().Hex(), ErrUnknownETx)
+			}
+			etxs[tx.Hash()] = true
+
+			externalBlock, err := p.bc.ETxSource(tx, header, externalBlocks)
+			if err != nil {
+				return nil, nil, 0, nil, fmt.Errorf("could not apply etx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			receipt, err = applyExternalTransaction(msg, p.config, p.bc, nil, gp, statedb, blockNumber, blockHash, externalBlock, ETxExpired(header, externalBlock), tx, usedGas, vmenv)
+			if err != nil {
+				return nil, nil, 0, nil, fmt.Errorf("could not apply etx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+		} else {
+			if err := verifyDestination(p.config, header, tx.To()); err != nil {
+				return nil, nil, 0, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			receipt, err = applyTransaction(msg, p.config, p.bc, nil, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+			if err != nil {
+				return nil, nil, 0, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
 		}
 		receipts = append(receipts, receipt)
 		allLogs = append(allLogs, receipt.Logs...)
@@ -212,17 +210,39 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 		return nil, errors.New("tx is nil")
 	}
 
-	msg, err := tx.AsMessage(types.MakeSigner(config, header.Number[types.QuaiNetworkContext]), header.BaseFee[types.QuaiNetworkContext])
+	msg, err := tx.AsMessage(types.MakeSigner(config, header.Number[config.Context]), header.BaseFee[config.Context])
 	if err != nil {
 		return nil, err
 	}
+	if err := verifyDestination(config, header, tx.To()); err != nil {
+		return nil, err
+	}
 	// Create a new context to be used in the EVM environment
-	blockContext := NewEVMBlockContext(header, bc, author)
+	blockContext := NewEVMBlockContext(header, bc, author, config.Context)
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, config, cfg)
-	return applyTransaction(msg, config, bc, author, gp, statedb, header.Number[types.QuaiNetworkContext], header.Hash(), tx, usedGas, vmenv)
+	return applyTransaction(msg, config, bc, author, gp, statedb, header.Number[config.Context], header.Hash(), tx, usedGas, vmenv)
+}
+
+// verifyDestination checks that the chain owning the recipient exists at the
+// prime number of the header. Ontology forks allocate the address prefixes of
+// the chains they create ahead of time, value sent there earlier would be lost.
+func verifyDestination(config *params.ChainConfig, header *types.Header, to *common.Address) error {
+	network := config.NetworkOntology()
+	if to == nil || network == nil {
+		return nil
+	}
+	location := network.AddressLocation(*to)
+	if location == nil {
+		return nil
+	}
+	chainID, err := network.ChainID(location)
+	if err != nil || !config.ValidChainID(chainID, header.Number[params.PRIME]) {
+		return ErrDestinationInoperable
+	}
+	return nil
 }
 
-func applyExternalTransaction(msg types.Message, config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, externalBlock *types.ExternalBlock, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
+func applyExternalTransaction(msg types.Message, config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, externalBlock *types.ExternalBlock, expired bool, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
 	// Create a new context to be used in the EVM environment.
 	txContext := NewEVMTxContext(msg)
 	evm.Reset(txContext, statedb)
@@ -236,6 +256,17 @@ func applyExternalTransaction(msg types.Message, config *params.ChainConfig, bc
 		return nil, errors.New("not an external transaction")
 	}
 
+	// Expired ETxs are not applied, their failed receipt has the origin zone
+	// refund the value to the sender.
+	if expired {
+		return expireExternalTransaction(config, statedb, blockNumber, blockHash, tx, usedGas), nil
+	}
+
+	// ETxs carrying calldata execute a call instead of a plain transfer.
+	if len(msg.Data()) > 0 {
+		return applyExternalCall(msg, config, gp, statedb, blockNumber, blockHash, tx, usedGas, evm)
+	}
+
 	// Apply the transaction to the current state (included in the env).
 	statedb.AddBalance(msg.From(), msg.Value())
 	statedb.AddBalance(*msg.To(), msg.Value())
@@ -250,14 +281,147 @@ func applyExternalTransaction(msg types.Message, config *params.ChainConfig, bc
 	return receipt, nil
 }
 
+// applyExternalCall executes the calldata of an ETx as a call from its sender
+// to its recipient in the destination zone. The gas budget of the call was paid
+// in the origin zone, so the call runs with the gas limit of the ETx less its
+// intrinsic gas without charging the sender again, and unused gas is not
+// refunded. If the call fails, all of its state changes including the transfer
+// of the value are reverted and the value is refunded by the origin zone.
+func applyExternalCall(msg types.Message, config *params.ChainConfig, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
+	rules := config.Rules(blockNumber)
+	intrinsic, err := IntrinsicGas(msg.Data(), msg.AccessList(), false, rules.IsHomestead, rules.IsIstanbul)
+	if err != nil {
+		return nil, err
+	}
+	if err := gp.SubGas(msg.Gas()); err != nil {
+		return nil, err
+	}
+	var (
+		snap     = statedb.Snapshot()
+		leftOver uint64
+		vmerr    error = ErrIntrinsicGas
+	)
+	if msg.Gas() >= intrinsic {
+		if rules.IsBerlin {
+			statedb.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(rules), msg.AccessList())
+		}
+		// The value left the origin zone with the ETx, credit it to the
+		// sender so the call can transfer it.
+		statedb.AddBalance(msg.From(), msg.Value())
+		_, leftOver, vmerr = evm.Call(vm.AccountRef(msg.From()), *msg.To(), msg.Data(), msg.Gas()-intrinsic, msg.Value())
+	}
+	if vmerr != nil {
+		statedb.RevertToSnapshot(snap)
+	}
+	gp.AddGas(leftOver)
+	gasUsed := msg.Gas() - leftOver
+
+	// Update the state with pending changes.
+	var root []byte
+	if config.IsByzantium(blockNumber) {
+		statedb.Finalise(true)
+	} else {
+		root = statedb.IntermediateRoot(config.IsEIP158(blockNumber)).Bytes()
+	}
+	*usedGas += gasUsed
+
+	// Create the receipt of the call in the destination zone.
+	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: *usedGas}
+	if vmerr != nil {
+		log.Debug("External transaction call failed", "hash", tx.Hash(), "err", vmerr)
+		receipt.Status = types.ReceiptStatusFailed
+	} else {
+		receipt.Status = types.ReceiptStatusSuccessful
+	}
+	receipt.TxHash = tx.Hash()
+	receipt.GasUsed = gasUsed
+	receipt.Logs = statedb.GetLogs(tx.Hash(), blockHash)
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	receipt.BlockHash = blockHash
+	receipt.BlockNumber = blockNumber
+	receipt.TransactionIndex = uint(statedb.TxIndex())
+	return receipt, nil
+}
+
+// expireExternalTransaction creates the failed receipt of an ETx which expired
+// before the destination zone applied it. The ETx uses no gas and changes no
+// state in the destination zone.
+func expireExternalTransaction(config *params.ChainConfig, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64) *types.Receipt {
+	var root []byte
+	if !config.IsByzantium(blockNumber) {
+		root = statedb.IntermediateRoot(config.IsEIP158(blockNumber)).Bytes()
+	}
+	receipt := &types.Receipt{Type: tx.Type(), PostState: root, Status: types.ReceiptStatusFailed, CumulativeGasUsed: *usedGas}
+	receipt.TxHash = tx.Hash()
+	receipt.Logs = statedb.GetLogs(tx.Hash(), blockHash)
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	receipt.BlockHash = blockHash
+	receipt.BlockNumber = blockNumber
+	receipt.TransactionIndex = uint(statedb.TxIndex())
+	return receipt
+}
+
+// ETxExpired reports whether the ETxs carried by the external block expired
+// before the block with the given header could apply them, which is the case
+// once the prime chain advanced by params.ETxLifetime blocks since they were
+// sent.
+func ETxExpired(header *types.Header, externalBlock *types.ExternalBlock) bool {
+	sent := externalBlock.Header().Number[params.PRIME].Uint64()
+	return header.Number[params.PRIME].Uint64() >= sent+params.ETxLifetime
+}
+
+// ETxRefunds returns the refunds owed for the ETxs sent from this chain which
+// the zone of the external block expired or failed to apply, as told by their
+// failed receipts in that zone.
+func ETxRefunds(config *params.ChainConfig, header *types.Header, externalBlock *types.ExternalBlock) []*types.ETxRefund {
+	if externalBlock.Context().Int64() != int64(params.ZONE) || bytes.Equal(externalBlock.Header().Location, config.Location) {
+		return nil
+	}
+	signer := types.MakeSigner(config, header.Number[config.Context])
+
+	var refunds []*types.ETxRefund
+	for _, tx := range externalBlock.Transactions() {
+		if tx.ChainId().Cmp(config.ChainID) == 0 {
+			continue
+		}
+		receipt := externalBlock.ReceiptForTransaction(tx)
+		if receipt.TxHash != tx.Hash() || receipt.Status != types.ReceiptStatusFailed {
+			continue
+		}
+		sender, err := types.Sender(signer, tx)
+		if err != nil || isExternalAddress(config, &sender) {
+			continue
+		}
+		refunds = append(refunds, &types.ETxRefund{
+			Hash:                tx.Hash(),
+			Sender:              sender,
+			Value:               tx.Value(),
+			DestinationBlock:    externalBlock.Hash(),
+			DestinationLocation: externalBlock.Header().Location,
+		})
+	}
+	return refunds
+}
+
+// ApplyETxRefunds credits the value of the ETxs sent from this chain which
+// failed in the zone of the external block back to their senders, and returns
+// the refunds paid.
+func ApplyETxRefunds(config *params.ChainConfig, statedb *state.StateDB, header *types.Header, externalBlock *types.ExternalBlock) []*types.ETxRefund {
+	refunds := ETxRefunds(config, header, externalBlock)
+	for _, refund := range refunds {
+		statedb.AddBalance(refund.Sender, refund.Value)
+	}
+	return refunds
+}
+
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
 func ApplyExternalTransaction(config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, externalBlock *types.ExternalBlock, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, error) {
-	s := types.MakeSigner(config, header.Number[types.QuaiNetworkContext])
+	s := types.MakeSigner(config, header.Number[config.Context])
 
-	msg, err := tx.AsMessage(s, header.BaseFee[types.QuaiNetworkContext])
+	msg, err := tx.AsMessage(s, header.BaseFee[config.Context])
 	if err != nil {
 		return nil, err
 	}
@@ -269,7 +433,7 @@ func ApplyExternalTransaction(config *params.ChainConfig, bc ChainContext, autho
 	}
 
 	// Create a new context to be used in the EVM environment
-	blockContext := NewEVMBlockContext(header, bc, author)
+	blockContext := NewEVMBlockContext(header, bc, author, config.Context)
 	vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, config, cfg)
-	return applyExternalTransaction(msg, config, bc, author, gp, statedb, header.Number[types.QuaiNetworkContext], header.Hash(), externalBlock, tx, usedGas, vmenv)
+	return applyExternalTransaction(msg, config, bc, author, gp, statedb, header.Number[config.Context], header.Hash(), externalBlock, ETxExpired(header, externalBlock), tx, usedGas, vmenv)
 }
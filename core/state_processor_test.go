@@ -297,7 +297,7 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 		UncleHash:  types.EmptyUncleHash,
 		Number:     []*big.Int{new(big.Int).SetUint64(0), new(big.Int).SetUint64(0), new(big.Int).SetUint64(0)},
 		ParentHash: []common.Hash{hash, hash, hash},
-		GasLimit:   []uint64{parent.GasLimit(), parent.GasLimit(), parent.GasLimit()},
+		GasLimit:   []uint64{parent.GasLimit(types.QuaiNetworkContext), parent.GasLimit(types.QuaiNetworkContext), parent.GasLimit(types.QuaiNetworkContext)},
 		Difficulty: []*big.Int{new(big.Int).SetUint64(0), new(big.Int).SetUint64(0), new(big.Int).SetUint64(0)},
 		//MixDigest:  hash,
 		Coinbase: []common.Address{common.Address{}, common.Address{}, common.Address{}},
@@ -306,14 +306,14 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	}
 
 	header.ParentHash[types.QuaiNetworkContext] = parent.Hash()
-	header.Coinbase[types.QuaiNetworkContext] = parent.Coinbase()
+	header.Coinbase[types.QuaiNetworkContext] = parent.Coinbase(types.QuaiNetworkContext)
 	parentDiff := &types.Header{Time: parent.Time()}
-	parentDiff.Number[types.QuaiNetworkContext] = parent.Number()
-	parentDiff.Difficulty[types.QuaiNetworkContext] = parent.Difficulty()
-	parentDiff.UncleHash[types.QuaiNetworkContext] = parent.UncleHash()
-	header.Difficulty[types.QuaiNetworkContext] = engine.CalcDifficulty(&fakeChainReader{config}, parent.Time()+10, parentDiff, 0)
-	header.GasLimit[types.QuaiNetworkContext] = parent.GasLimit()
-	header.Number[types.QuaiNetworkContext] = new(big.Int).Add(parent.Number(), common.Big1)
+	parentDiff.Number[types.QuaiNetworkContext] = parent.Number(types.QuaiNetworkContext)
+	parentDiff.Difficulty[types.QuaiNetworkContext] = parent.Difficulty(types.QuaiNetworkContext)
+	parentDiff.UncleHash[types.QuaiNetworkContext] = parent.UncleHash(types.QuaiNetworkContext)
+	header.Difficulty[types.QuaiNetworkContext] = engine.CalcDifficulty(&fakeChainReader{config: config}, parent.Time()+10, parentDiff, 0)
+	header.GasLimit[types.QuaiNetworkContext] = parent.GasLimit(types.QuaiNetworkContext)
+	header.Number[types.QuaiNetworkContext] = new(big.Int).Add(parent.Number(types.QuaiNetworkContext), common.Big1)
 
 	header.BaseFee[types.QuaiNetworkContext] = misc.CalcBaseFee(config, parent.Header(), chain.GetHeaderByNumber, chain.GetUnclesInChain, chain.GetGasUsedInChain)
 
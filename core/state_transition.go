@@ -322,6 +322,11 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
+	// An ETx carrying calldata executes its call in the destination zone, the
+	// gas left over is the budget of that call and is not refunded.
+	if vmerr == nil && len(st.data) > 0 && isExternalAddress(st.evm.ChainConfig(), msg.To()) {
+		st.gas = 0
+	}
 
 	if !london {
 		// Before EIP-3529: refunds were capped to gasUsed / 2
@@ -0,0 +1,348 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus/misc"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/metrics"
+	"github.com/spruce-solutions/go-quai/params"
+	"github.com/spruce-solutions/go-quai/rlp"
+	"github.com/spruce-solutions/go-quai/trie"
+)
+
+var (
+	supplyIssuedGauge = metrics.NewRegisteredGauge("chain/supply/issued", nil)
+	supplyBurnedGauge = metrics.NewRegisteredGauge("chain/supply/burned", nil)
+)
+
+// NetworkSupply is the supply of the whole network, aggregated from the
+// supply records of the current heads of every zone.
+type NetworkSupply struct {
+	Zones       []*types.Supply // Supply records of the zone heads
+	Base        *big.Int        // Balance held by the zones when tracking started
+	Issued      *big.Int        // Rewards issued by all zones
+	Burned      *big.Int        // Base fees burned by all zones
+	Circulating *big.Int        // Supply held by the zones
+	InFlight    *big.Int        // Value of the ETxs sent but neither applied nor refunded yet
+	Mismatches  []string        // Inconsistencies found reconciling the zones
+}
+
+// GetSupply returns the supply record of the zone block with the given hash.
+func (bc *BlockChain) GetSupply(hash common.Hash) *types.Supply {
+	return rawdb.ReadSupply(bc.db, hash)
+}
+
+// recordSupply records the change of the supply of the zone by an inserted
+// block: the rewards it issues, the base fees it burns and the value of the
+// ETxs leaving and entering the zone. Tracking starts from the genesis state,
+// or if that isn't available, from the head state in the background.
+func (bc *BlockChain) recordSupply(block *types.Block, receipts types.Receipts, externalBlocks []*types.ExternalBlock) bool {
+	if bc.chainConfig.Context != params.ZONE {
+		return false
+	}
+	if rawdb.ReadSupply(bc.db, block.Hash()) != nil {
+		return true
+	}
+	parent := rawdb.ReadSupply(bc.db, block.ParentHash(params.ZONE))
+	if parent == nil && block.NumberU64(params.ZONE) == 1 {
+		parent = bc.baseSupply(bc.genesisBlock.Header())
+	}
+	if parent == nil {
+		bc.startSupply()
+		return false
+	}
+	delta := bc.supplyDelta(block, receipts, externalBlocks)
+	total := parent.Total.Copy()
+	total.Add(delta)
+
+	rawdb.WriteSupply(bc.db, &types.Supply{
+		Hash:     block.Hash(),
+		Number:   block.NumberU64(params.ZONE),
+		Location: block.Header().Location,
+		Base:     parent.Base,
+		Block:    delta,
+		Total:    total,
+	})
+	supplyIssuedGauge.Update(delta.Issued.Int64())
+	supplyBurnedGauge.Update(delta.Burned.Int64())
+	return true
+}
+
+// baseSupply starts the supply tracking at a block, recording the balance held
+// by its state. The balance is exact for the genesis block only, later states
+// also hold the value of the ETxs credited to recipients outside of the zone.
+// Nothing is recorded if the state of the block isn't available.
+func (bc *BlockChain) baseSupply(header *types.Header) *types.Supply {
+	hash := header.Hash()
+	if supply := rawdb.ReadSupply(bc.db, hash); supply != nil {
+		return supply
+	}
+	root := header.Root[params.ZONE]
+	if !bc.HasState(root) {
+		log.Debug("Skipping supply tracking without state", "number", header.Number[params.ZONE], "hash", hash)
+		return nil
+	}
+	balance, err := bc.stateBalance(root)
+	if err != nil {
+		log.Warn("Failed to start supply tracking", "number", header.Number[params.ZONE], "hash", hash, "err", err)
+		return nil
+	}
+	if header.Number[params.ZONE].Sign() > 0 {
+		log.Warn("Starting supply tracking after genesis, balances of external ETx recipients are counted", "number", header.Number[params.ZONE], "hash", hash)
+	}
+	supply := &types.Supply{
+		Hash:     hash,
+		Number:   header.Number[params.ZONE].Uint64(),
+		Location: header.Location,
+		Base:     balance,
+		Block:    types.NewSupplyDelta(),
+		Total:    types.NewSupplyDelta(),
+	}
+	rawdb.WriteSupply(bc.db, supply)
+	return supply
+}
+
+// startSupply starts the supply tracking from the current head in the
+// background, unless it is being started already. Once the head is recorded,
+// the canonical blocks inserted meanwhile are recorded on top of it.
+func (bc *BlockChain) startSupply() {
+	if !atomic.CompareAndSwapInt32(&bc.supplyStart, 0, 1) {
+		return
+	}
+	bc.wg.Add(1)
+	go func() {
+		defer bc.wg.Done()
+		defer atomic.StoreInt32(&bc.supplyStart, 0)
+
+		head := bc.CurrentBlock()
+		if bc.baseSupply(head.Header()) == nil {
+			return
+		}
+		for number := head.NumberU64(params.ZONE) + 1; ; number++ {
+			select {
+			case <-bc.quit:
+				return
+			default:
+			}
+			block := bc.GetBlockByNumber(number)
+			if block == nil {
+				return
+			}
+			externalBlocks, err := bc.engine.GetExternalBlocks(bc, block.Header(), true)
+			if err != nil {
+				log.Debug("Failed to catch up supply tracking", "number", number, "err", err)
+				return
+			}
+			if !bc.recordSupply(block, bc.GetReceiptsByHash(block.Hash()), externalBlocks) {
+				return
+			}
+		}
+	}()
+}
+
+// stateBalance sums the balances of every account of the state with the given
+// root. The walk is aborted if the chain is stopped.
+func (bc *BlockChain) stateBalance(root common.Hash) (*big.Int, error) {
+	tr, err := bc.stateCache.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	balance := new(big.Int)
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		select {
+		case <-bc.quit:
+			return nil, errInsertionInterrupted
+		default:
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &account); err != nil {
+			return nil, err
+		}
+		balance.Add(balance, account.Balance)
+	}
+	return balance, it.Err
+}
+
+// supplyDelta calculates the change of the supply of the zone by a block.
+func (bc *BlockChain) supplyDelta(block *types.Block, receipts types.Receipts, externalBlocks []*types.ExternalBlock) *types.SupplyDelta {
+	var (
+		header   = block.Header()
+		number   = header.Number[params.ZONE]
+		baseFee  = header.BaseFee[params.ZONE]
+		signer   = types.MakeSigner(bc.chainConfig, number)
+		ontology = bc.chainConfig.OntologyAt(header.Number[params.PRIME])
+		delta    = types.NewSupplyDelta()
+	)
+	parent := bc.GetHeader(header.ParentHash[params.ZONE], number.Uint64()-1)
+	order := misc.RewardedOrder(bc.chainConfig, parent, bc.engine.GetDifficultyOrder)
+	delta.Issued = misc.BlockRewards(bc.chainConfig, header, parent, order, block.Uncles()).Total()
+
+	applied := make(map[common.Hash]*types.Receipt, len(receipts))
+	for _, receipt := range receipts {
+		applied[receipt.TxHash] = receipt
+	}
+	// Local transactions burn the base fee of the gas they use, those sent to
+	// another chain take their value out of the zone. ETxs applied by the block
+	// and refunds of the ETxs failing in their destination bring their value
+	// into the zone.
+	for _, externalBlock := range externalBlocks {
+		for _, refund := range ETxRefunds(bc.chainConfig, header, externalBlock) {
+			delta.AddRefund(refund.DestinationLocation, refund.Value)
+		}
+	}
+	for _, tx := range block.Transactions() {
+		receipt := applied[tx.Hash()]
+		if receipt == nil {
+			continue
+		}
+		msg, err := tx.AsMessage(signer, baseFee)
+		if err != nil {
+			continue
+		}
+		if msg.FromExternal() {
+			if receipt.Status == types.ReceiptStatusSuccessful && tx.Value().Sign() > 0 {
+				delta.AddInflow(addressLocation(ontology, msg.From()), tx.Value())
+			}
+			continue
+		}
+		if bc.chainConfig.IsLondon(number) && baseFee != nil {
+			delta.Burned.Add(delta.Burned, new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), baseFee))
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful && bc.isOutboundETx(tx) && tx.Value().Sign() > 0 {
+			delta.AddOutflow(addressLocation(ontology, *tx.To()), tx.Value())
+		}
+	}
+	return delta
+}
+
+// addressLocation returns the location of the zone owning the address in the
+// ontology, or nil if the ontology is unknown.
+func addressLocation(ontology *params.Ontology, addr common.Address) []byte {
+	if ontology == nil {
+		return nil
+	}
+	return ontology.AddressLocation(addr)
+}
+
+// ZoneSupplies returns the supply records of the current heads of every zone
+// under this chain, collected from the subordinate chains. Zones that can't be
+// reached are left out.
+func (bc *BlockChain) ZoneSupplies(ctx context.Context) ([]*types.Supply, error) {
+	if bc.chainConfig.Context == params.ZONE {
+		supply := rawdb.ReadSupply(bc.db, bc.CurrentBlock().Hash())
+		if supply == nil {
+			return nil, ErrSupplyUnknown
+		}
+		return []*types.Supply{supply}, nil
+	}
+	var supplies []*types.Supply
+	for i, link := range bc.getSubLinks() {
+		if link == nil {
+			continue
+		}
+		zones, err := link.GetZoneSupplies(ctx)
+		if err != nil {
+			log.Debug("Failed to retrieve subordinate supply", "index", i, "err", err)
+			continue
+		}
+		supplies = append(supplies, zones...)
+	}
+	return supplies, nil
+}
+
+// NetworkSupply aggregates the supply of every zone under this chain and
+// reconciles the ETx flows between them. On a prime node this is the supply of
+// the whole network.
+func (bc *BlockChain) NetworkSupply(ctx context.Context) (*NetworkSupply, error) {
+	zones, err := bc.ZoneSupplies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ReconcileSupply(zones, bc.chainConfig.OntologyAt(bc.CurrentHeader().Number[params.PRIME])), nil
+}
+
+// ReconcileSupply aggregates the supply records of zone heads into the supply
+// of the network and checks them against each other and the ontology. A zone
+// credited by another, or refunded by it, with more value than it sent it, a
+// zone reporting more than once and a zone of the ontology not reporting are
+// flagged as mismatches.
+func ReconcileSupply(zones []*types.Supply, ontology *params.Ontology) *NetworkSupply {
+	supply := &NetworkSupply{
+		Zones:       zones,
+		Base:        new(big.Int),
+		Issued:      new(big.Int),
+		Burned:      new(big.Int),
+		Circulating: new(big.Int),
+		InFlight:    new(big.Int),
+		Mismatches:  []string{},
+	}
+	reported := make(map[string]*types.Supply)
+	for _, zone := range zones {
+		if _, ok := reported[string(zone.Location)]; ok {
+			supply.Mismatches = append(supply.Mismatches, fmt.Sprintf("zone %v reported more than once", zone.Location))
+			continue
+		}
+		reported[string(zone.Location)] = zone
+
+		supply.Base.Add(supply.Base, zone.Base)
+		supply.Issued.Add(supply.Issued, zone.Total.Issued)
+		supply.Burned.Add(supply.Burned, zone.Total.Burned)
+		supply.Circulating.Add(supply.Circulating, zone.Circulating())
+		supply.InFlight.Add(supply.InFlight, zone.Total.ETxOut)
+		supply.InFlight.Sub(supply.InFlight, zone.Total.ETxIn)
+	}
+	if ontology != nil {
+		for _, location := range ontology.ZoneLocations() {
+			if _, ok := reported[string(location)]; !ok {
+				supply.Mismatches = append(supply.Mismatches, fmt.Sprintf("zone %v not reported", location))
+			}
+		}
+	}
+	// Every inflow must have left its origin zone first, and the value sent
+	// is either applied by its destination or refunded to its origin.
+	for _, zone := range zones {
+		for _, inflow := range zone.Total.Inflows {
+			origin, ok := reported[string(inflow.Location)]
+			if !ok {
+				continue
+			}
+			sent, refunded := origin.Total.Outflow(zone.Location), origin.Total.Refund(zone.Location)
+			if received := new(big.Int).Add(inflow.Value, refunded); sent.Cmp(received) < 0 {
+				supply.Mismatches = append(supply.Mismatches, fmt.Sprintf("zone %v received %v from zone %v, which sent %v and was refunded %v", zone.Location, inflow.Value, inflow.Location, sent, refunded))
+			}
+		}
+		for _, refund := range zone.Total.Refunds {
+			if sent := zone.Total.Outflow(refund.Location); sent.Cmp(refund.Value) < 0 {
+				supply.Mismatches = append(supply.Mismatches, fmt.Sprintf("zone %v was refunded %v by zone %v, but sent it %v", zone.Location, refund.Value, refund.Location, sent))
+			}
+		}
+	}
+	if supply.InFlight.Sign() < 0 {
+		supply.Mismatches = append(supply.Mismatches, fmt.Sprintf("zones received %v more than they sent", new(big.Int).Neg(supply.InFlight)))
+	}
+	return supply
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/core/types"
+)
+
+// Tests that the refunds of failed ETxs net out the value they took out of
+// their origin zone.
+func TestReconcileSupplyRefunds(t *testing.T) {
+	var (
+		origin      = []byte{1, 1}
+		destination = []byte{1, 2}
+	)
+	zones := func(sent, applied, refunded int64) []*types.Supply {
+		out, in := types.NewSupplyDelta(), types.NewSupplyDelta()
+		out.AddOutflow(destination, big.NewInt(sent))
+		if refunded > 0 {
+			out.AddRefund(destination, big.NewInt(refunded))
+		}
+		if applied > 0 {
+			in.AddInflow(origin, big.NewInt(applied))
+		}
+		return []*types.Supply{
+			{Location: origin, Base: big.NewInt(1000), Block: types.NewSupplyDelta(), Total: out},
+			{Location: destination, Base: big.NewInt(1000), Block: types.NewSupplyDelta(), Total: in},
+		}
+	}
+	tests := []struct {
+		sent, applied, refunded int64
+		inFlight                int64
+		mismatch                bool
+	}{
+		{100, 0, 0, 100, false},
+		{100, 100, 0, 0, false},
+		{100, 0, 100, 0, false},
+		{100, 60, 40, 0, false},
+		{100, 60, 20, 20, false},
+		{100, 60, 50, -10, true},
+		{100, 0, 150, -50, true},
+	}
+	for i, test := range tests {
+		supply := ReconcileSupply(zones(test.sent, test.applied, test.refunded), nil)
+		if supply.InFlight.Int64() != test.inFlight {
+			t.Errorf("test %d: in flight mismatch: have %v, want %d", i, supply.InFlight, test.inFlight)
+		}
+		if circulating := supply.Circulating.Int64(); circulating+supply.InFlight.Int64() != 2000 {
+			t.Errorf("test %d: supply not conserved: %d circulating, %v in flight", i, circulating, supply.InFlight)
+		}
+		if have := len(supply.Mismatches) > 0; have != test.mismatch {
+			t.Errorf("test %d: reconciliation mismatch: have %v, want mismatch %v", i, supply.Mismatches, test.mismatch)
+		}
+	}
+}
@@ -652,7 +652,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if int(from.Bytes()[0]) < idRange[0] || int(from.Bytes()[0]) > idRange[1] {
 		return ErrSenderInoperable
 	}
-
+	if err := verifyDestination(pool.chainconfig, pool.chain.CurrentBlock().Header(), tx.To()); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1189,7 +1191,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	// because of another transaction (e.g. higher gas price).
 	if reset != nil {
 		pool.demoteUnexecutables()
-		if reset.newHead != nil && pool.chainconfig.IsLondon(new(big.Int).Add(reset.newHead.Number[types.QuaiNetworkContext], big.NewInt(1))) {
+		if reset.newHead != nil && pool.chainconfig.IsLondon(new(big.Int).Add(reset.newHead.Number[pool.chainconfig.Context], big.NewInt(1))) {
 			pendingBaseFee := misc.CalcBaseFee(pool.chainconfig, reset.newHead, pool.chain.GetHeaderByNumber, pool.chain.GetUnclesInChain, pool.chain.GetGasUsedInChain)
 			pool.priced.SetBaseFee(pendingBaseFee)
 		}
@@ -1230,10 +1232,10 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// If we're reorging an old state, reinject all dropped transactions
 	var reinject types.Transactions
 
-	if oldHead != nil && oldHead.Hash() != newHead.ParentHash[types.QuaiNetworkContext] {
+	if oldHead != nil && oldHead.Hash() != newHead.ParentHash[pool.chainconfig.Context] {
 		// If the reorg is too deep, avoid doing it (will happen during fast sync)
-		oldNum := oldHead.Number[types.QuaiNetworkContext].Uint64()
-		newNum := newHead.Number[types.QuaiNetworkContext].Uint64()
+		oldNum := oldHead.Number[pool.chainconfig.Context].Uint64()
+		newNum := newHead.Number[pool.chainconfig.Context].Uint64()
 
 		if depth := uint64(math.Abs(float64(oldNum) - float64(newNum))); depth > 64 {
 			log.Debug("Skipping deep transaction reorg", "depth", depth)
@@ -1241,8 +1243,8 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 			// Reorg seems shallow enough to pull in all transactions into memory
 			var discarded, included types.Transactions
 			var (
-				rem = pool.chain.GetBlock(oldHead.Hash(), oldHead.Number[types.QuaiNetworkContext].Uint64())
-				add = pool.chain.GetBlock(newHead.Hash(), newHead.Number[types.QuaiNetworkContext].Uint64())
+				rem = pool.chain.GetBlock(oldHead.Hash(), oldHead.Number[pool.chainconfig.Context].Uint64())
+				add = pool.chain.GetBlock(newHead.Hash(), newHead.Number[pool.chainconfig.Context].Uint64())
 			)
 			if rem == nil {
 				// This can happen if a setHead is performed, where we simply discard the old
@@ -1263,9 +1265,9 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 				log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
 				return
 			} else {
-				for rem.NumberU64() > add.NumberU64() {
+				for rem.NumberU64(pool.chainconfig.Context) > add.NumberU64(pool.chainconfig.Context) {
 					discarded = append(discarded, rem.Transactions()...)
-					if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
+					if rem = pool.chain.GetBlock(rem.ParentHash(pool.chainconfig.Context), rem.NumberU64(pool.chainconfig.Context)-1); rem == nil {
 						log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
 						return
 					}
@@ -1278,9 +1280,9 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 						return
 					}
 				}
-				for add.NumberU64() > rem.NumberU64() {
+				for add.NumberU64(pool.chainconfig.Context) > rem.NumberU64(pool.chainconfig.Context) {
 					included = append(included, add.Transactions()...)
-					if add = pool.chain.GetBlock(add.ParentHash(), add.NumberU64()-1); add == nil {
+					if add = pool.chain.GetBlock(add.ParentHash(pool.chainconfig.Context), add.NumberU64(pool.chainconfig.Context)-1); add == nil {
 						log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
 						return
 					}
@@ -1295,12 +1297,12 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 				}
 				for rem.Hash() != add.Hash() {
 					discarded = append(discarded, rem.Transactions()...)
-					if rem = pool.chain.GetBlock(rem.ParentHash(), rem.NumberU64()-1); rem == nil {
+					if rem = pool.chain.GetBlock(rem.ParentHash(pool.chainconfig.Context), rem.NumberU64(pool.chainconfig.Context)-1); rem == nil {
 						log.Error("Unrooted old chain seen by tx pool", "block", oldHead.Number, "hash", oldHead.Hash())
 						return
 					}
 					included = append(included, add.Transactions()...)
-					if add = pool.chain.GetBlock(add.ParentHash(), add.NumberU64()-1); add == nil {
+					if add = pool.chain.GetBlock(add.ParentHash(pool.chainconfig.Context), add.NumberU64(pool.chainconfig.Context)-1); add == nil {
 						log.Error("Unrooted new chain seen by tx pool", "block", newHead.Number, "hash", newHead.Hash())
 						return
 					}
@@ -1313,14 +1315,14 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	if newHead == nil {
 		newHead = pool.chain.CurrentBlock().Header() // Special case during testing
 	}
-	statedb, err := pool.chain.StateAt(newHead.Root[types.QuaiNetworkContext])
+	statedb, err := pool.chain.StateAt(newHead.Root[pool.chainconfig.Context])
 	if err != nil {
 		log.Error("Failed to reset txpool state", "err", err)
 		return
 	}
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
-	pool.currentMaxGas = newHead.GasLimit[types.QuaiNetworkContext]
+	pool.currentMaxGas = newHead.GasLimit[pool.chainconfig.Context]
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -1328,7 +1330,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.addTxsLocked(reinject, false)
 
 	// Update all fork indicator by next pending block number.
-	next := new(big.Int).Add(newHead.Number[types.QuaiNetworkContext], big.NewInt(1))
+	next := new(big.Int).Add(newHead.Number[pool.chainconfig.Context], big.NewInt(1))
 	pool.istanbul = pool.chainconfig.IsIstanbul(next)
 	pool.eip2718 = pool.chainconfig.IsBerlin(next)
 	pool.eip1559 = true
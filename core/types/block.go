@@ -172,14 +172,15 @@ func (h *Header) SanityCheck() error {
 }
 
 // EmptyBody returns true if there is no additional 'body' to complete the header
-// that is: no transactions and no uncles.
-func (h *Header) EmptyBody() bool {
-	return h.TxHash[QuaiNetworkContext] == EmptyRootHash[QuaiNetworkContext] && h.UncleHash[QuaiNetworkContext] == EmptyUncleHash[QuaiNetworkContext]
+// in the given context, that is: no transactions and no uncles.
+func (h *Header) EmptyBody(context int) bool {
+	return h.TxHash[context] == EmptyRootHash[context] && h.UncleHash[context] == EmptyUncleHash[context]
 }
 
-// EmptyReceipts returns true if there are no receipts for this header/block.
-func (h *Header) EmptyReceipts() bool {
-	return h.ReceiptHash[QuaiNetworkContext] == EmptyRootHash[QuaiNetworkContext]
+// EmptyReceipts returns true if there are no receipts for this header/block in
+// the given context.
+func (h *Header) EmptyReceipts(context int) bool {
+	return h.ReceiptHash[context] == EmptyRootHash[context]
 }
 
 // IsEqualHashSlice compares each hash in a headers slice of hashes
@@ -542,57 +543,33 @@ func (b *Block) Transaction(hash common.Hash) *Transaction {
 	return nil
 }
 
-func (b *Block) Number(params ...int) *big.Int {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) Number(context int) *big.Int {
 	// Temp check
 	if len(b.header.Number) < context {
 		return nil
 	}
 	return b.header.Number[context]
 }
-func (b *Block) GasLimit(params ...int) uint64 {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) GasLimit(context int) uint64 {
 	return b.header.GasLimit[context]
 }
-func (b *Block) GasUsed(params ...int) uint64 {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) GasUsed(context int) uint64 {
 	return b.header.GasUsed[context]
 }
-func (b *Block) Difficulty(params ...int) *big.Int {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) Difficulty(context int) *big.Int {
 	if b.header.Difficulty[context] == nil {
 		return nil
 	}
 	return new(big.Int).Set(b.header.Difficulty[context])
 }
-func (b *Block) NetworkDifficulty(params ...int) *big.Int {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) NetworkDifficulty(context int) *big.Int {
 	if b.header.NetworkDifficulty[context] == nil {
 		return nil
 	}
 	return new(big.Int).Set(b.header.NetworkDifficulty[context])
 }
 func (b *Block) Time() uint64 { return b.header.Time }
-func (b *Block) NumberU64(params ...int) uint64 {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) NumberU64(context int) uint64 {
 	if b.header.Number[context] == nil {
 		return 0
 	}
@@ -601,68 +578,34 @@ func (b *Block) NumberU64(params ...int) uint64 {
 func (b *Block) Nonce(params ...int) uint64 {
 	return binary.BigEndian.Uint64(b.header.Nonce[:])
 }
-func (b *Block) Bloom(params ...int) Bloom {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) Bloom(context int) Bloom {
 	return b.header.Bloom[context]
 }
-func (b *Block) Coinbase(params ...int) common.Address {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) Coinbase(context int) common.Address {
 	return b.header.Coinbase[context]
 }
-func (b *Block) Root(params ...int) common.Hash {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) Root(context int) common.Hash {
 	return b.header.Root[context]
 }
-func (b *Block) ParentHash(params ...int) common.Hash {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
-
+func (b *Block) ParentHash(context int) common.Hash {
 	return b.header.ParentHash[context]
 }
-func (b *Block) TxHash(params ...int) common.Hash {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) TxHash(context int) common.Hash {
 	return b.header.TxHash[context]
 }
-func (b *Block) ReceiptHash(params ...int) common.Hash {
-	return b.header.ReceiptHash[QuaiNetworkContext]
+func (b *Block) ReceiptHash(context int) common.Hash {
+	return b.header.ReceiptHash[context]
 }
-func (b *Block) UncleHash(params ...int) common.Hash {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) UncleHash(context int) common.Hash {
 	return b.header.UncleHash[context]
 }
-func (b *Block) Extra(params ...int) []byte {
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
+func (b *Block) Extra(context int) []byte {
 	return common.CopyBytes(b.header.Extra[context])
 }
-func (b *Block) BaseFee(params ...int) *big.Int {
+func (b *Block) BaseFee(context int) *big.Int {
 	if b.header.BaseFee == nil {
 		return nil
 	}
-	context := QuaiNetworkContext
-	if len(params) > 0 {
-		context = params[0]
-	}
-
 	return new(big.Int).Set(b.header.BaseFee[context])
 }
 
@@ -0,0 +1,162 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+)
+
+// ETxStage is the lifecycle stage of an external transaction.
+type ETxStage uint8
+
+const (
+	// ETxUnknown is the stage of an ETx that hasn't been seen.
+	ETxUnknown ETxStage = iota
+	// ETxIncluded is the stage of an ETx included in a block of its origin zone.
+	ETxIncluded
+	// ETxReferenced is the stage of an ETx whose origin block is coincident with
+	// a region or prime block.
+	ETxReferenced
+	// ETxAvailable is the stage of an ETx whose origin block is available as an
+	// external block in the destination zone.
+	ETxAvailable
+	// ETxApplied is the stage of an ETx applied in the destination zone.
+	ETxApplied
+	// ETxFailed is the stage of an ETx that failed to apply in the destination zone.
+	ETxFailed
+	// ETxRefunded is the stage of a failed ETx whose value was refunded to the
+	// sender in the origin zone.
+	ETxRefunded
+)
+
+// String implements the stringer interface.
+func (s ETxStage) String() string {
+	switch s {
+	case ETxIncluded:
+		return "included"
+	case ETxReferenced:
+		return "referenced"
+	case ETxAvailable:
+		return "available"
+	case ETxApplied:
+		return "applied"
+	case ETxFailed:
+		return "failed"
+	case ETxRefunded:
+		return "refunded"
+	default:
+		return "unknown"
+	}
+}
+
+// ETxStatus is the lifecycle status of an external transaction as seen by a
+// single chain.
+type ETxStatus struct {
+	Hash             common.Hash // Hash of the external transaction
+	Stage            ETxStage
+	OriginBlock      common.Hash // Hash of the origin zone block including the ETx
+	OriginLocation   []byte      // Location of the origin zone
+	ReferenceContext uint64      // Context of the dominant block coincident with the origin block
+	DestinationBlock common.Hash // Hash of the destination zone block applying the ETx
+	Error            string      // Reason the ETx failed to apply, if any
+}
+
+// Advances reports whether the status moves the ETx to a later stage than the
+// given one. A failed ETx can always advance, as its application may be retried,
+// while an applied ETx is final.
+func (s *ETxStatus) Advances(prev *ETxStatus) bool {
+	switch {
+	case prev == nil:
+		return true
+	case prev.Stage == ETxApplied:
+		return false
+	case prev.Stage == ETxFailed:
+		return true
+	}
+	return s.Stage > prev.Stage
+}
+
+// Inherit fills in what the earlier stages of the ETx learned and the status
+// doesn't know about, so that it describes the lifecycle from the origin block
+// to the destination block.
+func (s *ETxStatus) Inherit(prev *ETxStatus) {
+	if prev == nil {
+		return
+	}
+	if s.OriginBlock == (common.Hash{}) {
+		s.OriginBlock = prev.OriginBlock
+	}
+	if len(s.OriginLocation) == 0 {
+		s.OriginLocation = prev.OriginLocation
+	}
+	// The reference to the origin block is only known up to its availability
+	// in the destination zone.
+	if s.Stage > ETxAvailable {
+		s.ReferenceContext = prev.ReferenceContext
+	}
+	if s.DestinationBlock == (common.Hash{}) {
+		s.DestinationBlock = prev.DestinationBlock
+	}
+}
+
+// MarshalJSON marshals the status in its RPC representation.
+func (s *ETxStatus) MarshalJSON() ([]byte, error) {
+	type ETxStatus struct {
+		Hash             common.Hash    `json:"hash"`
+		Stage            string         `json:"stage"`
+		OriginBlock      common.Hash    `json:"originBlock"`
+		OriginLocation   hexutil.Bytes  `json:"originLocation"`
+		ReferenceContext hexutil.Uint64 `json:"referenceContext"`
+		DestinationBlock common.Hash    `json:"destinationBlock"`
+		Error            string         `json:"error,omitempty"`
+	}
+	return json.Marshal(&ETxStatus{
+		Hash:             s.Hash,
+		Stage:            s.Stage.String(),
+		OriginBlock:      s.OriginBlock,
+		OriginLocation:   s.OriginLocation,
+		ReferenceContext: hexutil.Uint64(s.ReferenceContext),
+		DestinationBlock: s.DestinationBlock,
+		Error:            s.Error,
+	})
+}
+
+// PendingETx is an inbound external transaction waiting to be applied in the
+// destination zone.
+type PendingETx struct {
+	Tx             *Transaction
+	Sender         common.Address // Sender of the ETx in its origin zone
+	ExternalBlock  common.Hash    // Hash of the origin zone block including the ETx
+	OriginLocation []byte         // Location of the origin zone
+	Arrival        uint64         // Local block number at which the ETx became available
+	Attempts       uint64         // Number of failed application attempts
+	LastError      string         // Reason of the last failed application attempt
+}
+
+// ETxRefund is an external transaction sent from a zone which expired or
+// failed in its destination zone, whose value is owed back to the sender.
+type ETxRefund struct {
+	Hash                common.Hash
+	Sender              common.Address
+	Value               *big.Int
+	DestinationBlock    common.Hash // Hash of the destination zone block failing the ETx
+	DestinationLocation []byte      // Location of the destination zone
+}
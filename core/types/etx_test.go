@@ -0,0 +1,143 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/crypto"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+func TestETxStatusAdvances(t *testing.T) {
+	tests := []struct {
+		prev *ETxStatus
+		next ETxStage
+		want bool
+	}{
+		{nil, ETxIncluded, true},
+		{&ETxStatus{Stage: ETxIncluded}, ETxReferenced, true},
+		{&ETxStatus{Stage: ETxAvailable}, ETxReferenced, false},
+		{&ETxStatus{Stage: ETxApplied}, ETxApplied, false},
+		{&ETxStatus{Stage: ETxApplied}, ETxFailed, false},
+		{&ETxStatus{Stage: ETxApplied}, ETxRefunded, false},
+		{&ETxStatus{Stage: ETxFailed}, ETxApplied, true},
+		{&ETxStatus{Stage: ETxFailed}, ETxFailed, true},
+	}
+	for i, tt := range tests {
+		status := &ETxStatus{Stage: tt.next}
+		if have := status.Advances(tt.prev); have != tt.want {
+			t.Errorf("test %d: advance mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestETxStatusInherit(t *testing.T) {
+	available := &ETxStatus{
+		Hash:             common.HexToHash("0x01"),
+		Stage:            ETxAvailable,
+		OriginBlock:      common.HexToHash("0x02"),
+		OriginLocation:   []byte{1, 2},
+		ReferenceContext: 1,
+	}
+	applied := &ETxStatus{
+		Hash:             common.HexToHash("0x01"),
+		Stage:            ETxApplied,
+		DestinationBlock: common.HexToHash("0x03"),
+	}
+	applied.Inherit(available)
+
+	want := &ETxStatus{
+		Hash:             common.HexToHash("0x01"),
+		Stage:            ETxApplied,
+		OriginBlock:      common.HexToHash("0x02"),
+		OriginLocation:   []byte{1, 2},
+		ReferenceContext: 1,
+		DestinationBlock: common.HexToHash("0x03"),
+	}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("status mismatch: have %+v, want %+v", applied, want)
+	}
+}
+
+func TestETxStatusEncoding(t *testing.T) {
+	status := &ETxStatus{
+		Hash:             common.HexToHash("0x01"),
+		Stage:            ETxFailed,
+		OriginBlock:      common.HexToHash("0x02"),
+		OriginLocation:   []byte{1, 2},
+		ReferenceContext: 1,
+		DestinationBlock: common.HexToHash("0x03"),
+		Error:            "execution failed",
+	}
+	data, err := rlp.EncodeToBytes(status)
+	if err != nil {
+		t.Fatalf("failed to encode status: %v", err)
+	}
+	decoded := new(ETxStatus)
+	if err := rlp.DecodeBytes(data, decoded); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if !reflect.DeepEqual(status, decoded) {
+		t.Errorf("status mismatch: have %+v, want %+v", decoded, status)
+	}
+}
+
+func TestETxAsMessage(t *testing.T) {
+	// Zone 1-1 of the main network owns the address prefixes 20 to 29
+	signer := LatestSignerForChainID(big.NewInt(9101))
+	key, _ := crypto.GenerateKey()
+	for from := crypto.PubkeyToAddress(key.PublicKey); from[0] >= 20 && from[0] <= 29; from = crypto.PubkeyToAddress(key.PublicKey) {
+		key, _ = crypto.GenerateKey()
+	}
+	internal, external := common.Address{20}, common.Address{40}
+
+	tests := []struct {
+		to   *common.Address
+		data []byte
+		want bool
+	}{
+		{&internal, nil, true},                 // Value transfer
+		{&internal, []byte{0xde, 0xad}, true},  // Contract call
+		{nil, []byte{0xde, 0xad}, false},       // Contract creation
+		{&external, []byte{0xde, 0xad}, false}, // Call to another chain
+	}
+	for i, tt := range tests {
+		tx, err := SignNewTx(key, signer, &DynamicFeeTx{
+			ChainID:   big.NewInt(9101),
+			To:        tt.to,
+			Gas:       100000,
+			GasFeeCap: big.NewInt(1),
+			GasTipCap: big.NewInt(1),
+			Value:     big.NewInt(1),
+			Data:      tt.data,
+		})
+		if err != nil {
+			t.Fatalf("test %d: failed to sign transaction: %v", i, err)
+		}
+		msg, err := tx.AsMessage(signer, nil)
+		if err != nil {
+			t.Fatalf("test %d: failed to convert transaction: %v", i, err)
+		}
+		if msg.FromExternal() != tt.want {
+			t.Errorf("test %d: external mismatch: have %v, want %v", i, msg.FromExternal(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+)
+
+// FaultKind tells what a fault proof proves a block guilty of.
+type FaultKind uint8
+
+const (
+	// FaultTwist proves that the chains a block links to are twisted: tracing
+	// the previous coincident block of an order back from the block along
+	// different paths reaches different termini.
+	FaultTwist FaultKind = iota
+	// FaultCollision proves that a block links to an external block of the
+	// same location which references a different dominant block for a
+	// subordinate block the linking block already succeeds.
+	FaultCollision
+)
+
+// String implements the stringer interface.
+func (k FaultKind) String() string {
+	switch k {
+	case FaultTwist:
+		return "twist"
+	case FaultCollision:
+		return "collision"
+	default:
+		return "unknown"
+	}
+}
+
+// FaultProof is the self-contained evidence that a block is invalid, which any
+// node can verify from the headers it carries alone.
+//
+// A twist proof carries a path of headers for every context traced, each
+// header the parent of the previous one in that context, starting from the
+// parent of the offender and ending at the terminus of the path. The termini
+// must all differ. A collision proof carries the colliding external block
+// header as its only path.
+type FaultProof struct {
+	Kind        FaultKind
+	Context     uint64        // Context of the chain that found the fault
+	Order       uint64        // Order of the termini, or context of the colliding block
+	MatchRegion bool          // Whether termini need only be in the offender's region
+	Offender    *Header       // Header of the invalid block
+	Termini     []common.Hash // Conflicting terminus per path
+	PathContext []uint64      // Context traced by each path
+	Paths       [][]*Header   // Headers proving each terminus
+}
+
+// Hash returns the hash identifying the proof.
+func (p *FaultProof) Hash() common.Hash {
+	return rlpHash(p)
+}
+
+// MarshalJSON marshals the proof in its RPC representation.
+func (p *FaultProof) MarshalJSON() ([]byte, error) {
+	type FaultProof struct {
+		Hash        common.Hash      `json:"hash"`
+		Kind        string           `json:"kind"`
+		Context     hexutil.Uint64   `json:"context"`
+		Order       hexutil.Uint64   `json:"order"`
+		MatchRegion bool             `json:"matchRegion"`
+		Offender    *Header          `json:"offender"`
+		Miner       common.Address   `json:"miner"`
+		Termini     []common.Hash    `json:"termini"`
+		PathContext []hexutil.Uint64 `json:"pathContext"`
+		Paths       [][]common.Hash  `json:"paths"`
+	}
+	enc := &FaultProof{
+		Hash:        p.Hash(),
+		Kind:        p.Kind.String(),
+		Context:     hexutil.Uint64(p.Context),
+		Order:       hexutil.Uint64(p.Order),
+		MatchRegion: p.MatchRegion,
+		Offender:    p.Offender,
+		Termini:     p.Termini,
+		Paths:       make([][]common.Hash, len(p.Paths)),
+	}
+	if p.Offender != nil && int(p.Context) < len(p.Offender.Coinbase) {
+		enc.Miner = p.Offender.Coinbase[p.Context]
+	}
+	for _, context := range p.PathContext {
+		enc.PathContext = append(enc.PathContext, hexutil.Uint64(context))
+	}
+	for i, path := range p.Paths {
+		for _, header := range path {
+			enc.Paths[i] = append(enc.Paths[i], header.Hash())
+		}
+	}
+	return json.Marshal(enc)
+}
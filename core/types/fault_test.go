@@ -0,0 +1,70 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+func TestFaultProofEncoding(t *testing.T) {
+	offender := NewEmptyHeader()
+	offender.Number = []*big.Int{big.NewInt(4), big.NewInt(7), big.NewInt(9)}
+	offender.Coinbase[2] = common.HexToAddress("0xdead")
+
+	parent := NewEmptyHeader()
+	parent.Number = []*big.Int{big.NewInt(3), big.NewInt(6), big.NewInt(8)}
+
+	proof := &FaultProof{
+		Kind:        FaultTwist,
+		Context:     2,
+		Order:       1,
+		Offender:    offender,
+		Termini:     []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")},
+		PathContext: []uint64{1, 2},
+		Paths:       [][]*Header{{parent}, {parent}},
+	}
+	enc, err := rlp.EncodeToBytes(proof)
+	if err != nil {
+		t.Fatalf("failed to encode proof: %v", err)
+	}
+	dec := new(FaultProof)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatalf("failed to decode proof: %v", err)
+	}
+	if dec.Hash() != proof.Hash() {
+		t.Errorf("hash mismatch: have %x, want %x", dec.Hash(), proof.Hash())
+	}
+	if dec.Offender.Hash() != offender.Hash() || len(dec.Paths) != 2 || dec.Paths[1][0].Hash() != parent.Hash() {
+		t.Errorf("proof mismatch: have %+v, want %+v", dec, proof)
+	}
+	blob, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("failed to marshal proof: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		t.Fatalf("failed to unmarshal proof: %v", err)
+	}
+	if fields["kind"] != "twist" || fields["order"] != "0x1" || fields["miner"] != "0x000000000000000000000000000000000000dead" {
+		t.Errorf("unexpected RPC fields: %s", blob)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+)
+
+// ReorgKind tells what caused a chain to roll up to another fork.
+type ReorgKind uint8
+
+const (
+	// ReorgForkChoice is a reorg of the chain to a heavier fork of its own.
+	ReorgForkChoice ReorgKind = iota
+	// ReorgRollback is a rollback of the chain forced by a reorg of a
+	// dominant chain.
+	ReorgRollback
+)
+
+// String implements the stringer interface.
+func (k ReorgKind) String() string {
+	switch k {
+	case ReorgForkChoice:
+		return "forkchoice"
+	case ReorgRollback:
+		return "rollback"
+	default:
+		return "unknown"
+	}
+}
+
+// ReorgRecord is an entry of the reorg journal, describing a single rollup of
+// a chain. Dropped and added blocks are listed once for every context they are
+// coincident with, indexed by context.
+type ReorgRecord struct {
+	Number         uint64          // Sequence number of the entry in the journal
+	Time           uint64          // Unix time of the rollup
+	Kind           ReorgKind       // What caused the rollup
+	Context        uint64          // Context of the chain that rolled up
+	TriggerContext uint64          // Context whose reorg triggered the rollup
+	Trigger        common.Hash     // Hash of the header that triggered the rollup
+	CommonAncestor common.Hash     // Hash of the last block kept
+	CommonNumber   uint64          // Number of the last block kept
+	Dropped        [][]common.Hash // Hashes of the dropped blocks per context
+	Added          [][]common.Hash // Hashes of the added blocks per context
+	NewSubs        []common.Hash   // Hashes of the added blocks the subordinate chains roll up to
+	RemovedTxs     []common.Hash   // Transactions of dropped blocks not included by the added ones
+	RevertedETxs   []common.Hash   // ETxs applied by dropped blocks, pending again
+}
+
+// rpcReorgRecord is the RPC representation of a reorg record.
+type rpcReorgRecord struct {
+	Number         hexutil.Uint64  `json:"number"`
+	Time           hexutil.Uint64  `json:"time"`
+	Kind           string          `json:"kind"`
+	Context        hexutil.Uint64  `json:"context"`
+	TriggerContext hexutil.Uint64  `json:"triggerContext"`
+	Trigger        common.Hash     `json:"trigger"`
+	CommonAncestor common.Hash     `json:"commonAncestor"`
+	CommonNumber   hexutil.Uint64  `json:"commonNumber"`
+	Dropped        [][]common.Hash `json:"dropped"`
+	Added          [][]common.Hash `json:"added"`
+	NewSubs        []common.Hash   `json:"newSubs"`
+	RemovedTxs     []common.Hash   `json:"removedTxs"`
+	RevertedETxs   []common.Hash   `json:"revertedETxs"`
+}
+
+// MarshalJSON marshals the record in its RPC representation.
+func (r *ReorgRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&rpcReorgRecord{
+		Number:         hexutil.Uint64(r.Number),
+		Time:           hexutil.Uint64(r.Time),
+		Kind:           r.Kind.String(),
+		Context:        hexutil.Uint64(r.Context),
+		TriggerContext: hexutil.Uint64(r.TriggerContext),
+		Trigger:        r.Trigger,
+		CommonAncestor: r.CommonAncestor,
+		CommonNumber:   hexutil.Uint64(r.CommonNumber),
+		Dropped:        r.Dropped,
+		Added:          r.Added,
+		NewSubs:        r.NewSubs,
+		RemovedTxs:     r.RemovedTxs,
+		RevertedETxs:   r.RevertedETxs,
+	})
+}
+
+// UnmarshalJSON unmarshals the record from its RPC representation.
+func (r *ReorgRecord) UnmarshalJSON(input []byte) error {
+	var dec rpcReorgRecord
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	switch dec.Kind {
+	case ReorgForkChoice.String():
+		r.Kind = ReorgForkChoice
+	case ReorgRollback.String():
+		r.Kind = ReorgRollback
+	default:
+		return fmt.Errorf("unknown reorg kind %q", dec.Kind)
+	}
+	r.Number = uint64(dec.Number)
+	r.Time = uint64(dec.Time)
+	r.Context = uint64(dec.Context)
+	r.TriggerContext = uint64(dec.TriggerContext)
+	r.Trigger = dec.Trigger
+	r.CommonAncestor = dec.CommonAncestor
+	r.CommonNumber = uint64(dec.CommonNumber)
+	r.Dropped = dec.Dropped
+	r.Added = dec.Added
+	r.NewSubs = dec.NewSubs
+	r.RemovedTxs = dec.RemovedTxs
+	r.RevertedETxs = dec.RevertedETxs
+	return nil
+}
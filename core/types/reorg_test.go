@@ -0,0 +1,72 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+func TestReorgRecordEncoding(t *testing.T) {
+	record := &ReorgRecord{
+		Number:         3,
+		Time:           1600000000,
+		Kind:           ReorgRollback,
+		Context:        2,
+		TriggerContext: 1,
+		Trigger:        common.HexToHash("0x01"),
+		CommonAncestor: common.HexToHash("0x02"),
+		CommonNumber:   10,
+		Dropped:        [][]common.Hash{{}, {common.HexToHash("0x03")}, {common.HexToHash("0x03"), common.HexToHash("0x04")}},
+		Added:          [][]common.Hash{{}, {}, {}},
+		RemovedTxs:     []common.Hash{common.HexToHash("0x05")},
+		RevertedETxs:   []common.Hash{common.HexToHash("0x06")},
+	}
+	enc, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		t.Fatalf("failed to encode record: %v", err)
+	}
+	dec := new(ReorgRecord)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatalf("failed to decode record: %v", err)
+	}
+	if !reflect.DeepEqual(dec.Dropped, record.Dropped) || dec.Kind != record.Kind || dec.Trigger != record.Trigger || !reflect.DeepEqual(dec.RevertedETxs, record.RevertedETxs) {
+		t.Errorf("record mismatch: have %+v, want %+v", dec, record)
+	}
+	blob, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("failed to marshal record: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		t.Fatalf("failed to unmarshal record: %v", err)
+	}
+	if fields["kind"] != "rollback" || fields["triggerContext"] != "0x1" || fields["commonNumber"] != "0xa" {
+		t.Errorf("unexpected RPC fields: %s", blob)
+	}
+	rpcDec := new(ReorgRecord)
+	if err := json.Unmarshal(blob, rpcDec); err != nil {
+		t.Fatalf("failed to unmarshal RPC record: %v", err)
+	}
+	if !reflect.DeepEqual(rpcDec, record) {
+		t.Errorf("RPC record mismatch: have %+v, want %+v", rpcDec, record)
+	}
+}
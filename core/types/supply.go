@@ -0,0 +1,276 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+)
+
+// SupplyFlow is the value moved by ETxs between a zone and another chain.
+type SupplyFlow struct {
+	Location []byte   // Location of the other chain
+	Value    *big.Int // Value moved
+}
+
+// SupplyDelta is a change of the supply held by a zone.
+type SupplyDelta struct {
+	Issued   *big.Int      // Block, uncle and treasury rewards
+	Burned   *big.Int      // Base fees burned by transactions
+	ETxOut   *big.Int      // Value of the ETxs leaving the zone
+	ETxIn    *big.Int      // Value of the ETxs applied by the zone or refunded to it
+	Outflows []*SupplyFlow // Value of the ETxs leaving the zone by destination
+	Inflows  []*SupplyFlow // Value of the ETxs applied by the zone by origin
+	Refunds  []*SupplyFlow // Value of the ETxs refunded to the zone by destination
+}
+
+// NewSupplyDelta creates an empty supply change.
+func NewSupplyDelta() *SupplyDelta {
+	return &SupplyDelta{
+		Issued: new(big.Int),
+		Burned: new(big.Int),
+		ETxOut: new(big.Int),
+		ETxIn:  new(big.Int),
+	}
+}
+
+// Copy returns a deep copy of the supply change.
+func (d *SupplyDelta) Copy() *SupplyDelta {
+	cpy := NewSupplyDelta()
+	cpy.Add(d)
+	return cpy
+}
+
+// Add accumulates another supply change into d.
+func (d *SupplyDelta) Add(other *SupplyDelta) {
+	d.Issued.Add(d.Issued, other.Issued)
+	d.Burned.Add(d.Burned, other.Burned)
+	d.ETxOut.Add(d.ETxOut, other.ETxOut)
+	d.ETxIn.Add(d.ETxIn, other.ETxIn)
+	for _, flow := range other.Outflows {
+		d.Outflows = addSupplyFlow(d.Outflows, flow.Location, flow.Value)
+	}
+	for _, flow := range other.Inflows {
+		d.Inflows = addSupplyFlow(d.Inflows, flow.Location, flow.Value)
+	}
+	for _, flow := range other.Refunds {
+		d.Refunds = addSupplyFlow(d.Refunds, flow.Location, flow.Value)
+	}
+}
+
+// AddOutflow records value leaving the zone to the chain at the location.
+func (d *SupplyDelta) AddOutflow(location []byte, value *big.Int) {
+	d.ETxOut.Add(d.ETxOut, value)
+	d.Outflows = addSupplyFlow(d.Outflows, location, value)
+}
+
+// AddInflow records value entering the zone from the chain at the location.
+func (d *SupplyDelta) AddInflow(location []byte, value *big.Int) {
+	d.ETxIn.Add(d.ETxIn, value)
+	d.Inflows = addSupplyFlow(d.Inflows, location, value)
+}
+
+// AddRefund records value sent from the zone returning to it after its ETxs
+// failed in the chain at the location.
+func (d *SupplyDelta) AddRefund(location []byte, value *big.Int) {
+	d.ETxIn.Add(d.ETxIn, value)
+	d.Refunds = addSupplyFlow(d.Refunds, location, value)
+}
+
+// Outflow returns the value that left the zone to the chain at the location.
+func (d *SupplyDelta) Outflow(location []byte) *big.Int {
+	return supplyFlow(d.Outflows, location)
+}
+
+// Inflow returns the value that entered the zone from the chain at the
+// location.
+func (d *SupplyDelta) Inflow(location []byte) *big.Int {
+	return supplyFlow(d.Inflows, location)
+}
+
+// Refund returns the value refunded to the zone for the ETxs failing in the
+// chain at the location.
+func (d *SupplyDelta) Refund(location []byte) *big.Int {
+	return supplyFlow(d.Refunds, location)
+}
+
+// Net returns the change of the supply held by the zone.
+func (d *SupplyDelta) Net() *big.Int {
+	net := new(big.Int).Sub(d.Issued, d.Burned)
+	net.Sub(net, d.ETxOut)
+	return net.Add(net, d.ETxIn)
+}
+
+// addSupplyFlow adds value to the flow of the location, keeping the flows
+// sorted by location so their encoding is deterministic.
+func addSupplyFlow(flows []*SupplyFlow, location []byte, value *big.Int) []*SupplyFlow {
+	i := sort.Search(len(flows), func(i int) bool { return bytes.Compare(flows[i].Location, location) >= 0 })
+	if i < len(flows) && bytes.Equal(flows[i].Location, location) {
+		flows[i].Value = new(big.Int).Add(flows[i].Value, value)
+		return flows
+	}
+	flow := &SupplyFlow{Location: common.CopyBytes(location), Value: new(big.Int).Set(value)}
+	flows = append(flows, nil)
+	copy(flows[i+1:], flows[i:])
+	flows[i] = flow
+	return flows
+}
+
+// supplyFlow returns the value of the flow of the location.
+func supplyFlow(flows []*SupplyFlow, location []byte) *big.Int {
+	for _, flow := range flows {
+		if bytes.Equal(flow.Location, location) {
+			return new(big.Int).Set(flow.Value)
+		}
+	}
+	return new(big.Int)
+}
+
+// Supply is the supply record of a zone block, holding the change of the
+// supply by the block and the changes accumulated since tracking started.
+type Supply struct {
+	Hash     common.Hash  // Hash of the block
+	Number   uint64       // Number of the block in the zone
+	Location []byte       // Location of the zone
+	Base     *big.Int     // Balance held by the zone when tracking started
+	Block    *SupplyDelta // Change of the supply by the block
+	Total    *SupplyDelta // Changes of the supply since tracking started, including the block
+}
+
+// Circulating returns the supply held by the zone after the block.
+func (s *Supply) Circulating() *big.Int {
+	return new(big.Int).Add(s.Base, s.Total.Net())
+}
+
+type supplyFlowJSON struct {
+	Location hexutil.Bytes `json:"location"`
+	Value    *hexutil.Big  `json:"value"`
+}
+
+type supplyDeltaJSON struct {
+	Issued   *hexutil.Big     `json:"issued"`
+	Burned   *hexutil.Big     `json:"burned"`
+	ETxOut   *hexutil.Big     `json:"etxOut"`
+	ETxIn    *hexutil.Big     `json:"etxIn"`
+	Outflows []supplyFlowJSON `json:"outflows"`
+	Inflows  []supplyFlowJSON `json:"inflows"`
+	Refunds  []supplyFlowJSON `json:"refunds"`
+}
+
+func encodeSupplyFlows(flows []*SupplyFlow) []supplyFlowJSON {
+	enc := make([]supplyFlowJSON, len(flows))
+	for i, flow := range flows {
+		enc[i] = supplyFlowJSON{Location: flow.Location, Value: (*hexutil.Big)(flow.Value)}
+	}
+	return enc
+}
+
+func decodeSupplyFlows(enc []supplyFlowJSON) []*SupplyFlow {
+	var flows []*SupplyFlow
+	for _, flow := range enc {
+		value := new(big.Int)
+		if flow.Value != nil {
+			value = flow.Value.ToInt()
+		}
+		flows = addSupplyFlow(flows, flow.Location, value)
+	}
+	return flows
+}
+
+func bigOrZero(b *hexutil.Big) *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+	return b.ToInt()
+}
+
+// MarshalJSON marshals the supply change in its RPC representation.
+func (d *SupplyDelta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&supplyDeltaJSON{
+		Issued:   (*hexutil.Big)(d.Issued),
+		Burned:   (*hexutil.Big)(d.Burned),
+		ETxOut:   (*hexutil.Big)(d.ETxOut),
+		ETxIn:    (*hexutil.Big)(d.ETxIn),
+		Outflows: encodeSupplyFlows(d.Outflows),
+		Inflows:  encodeSupplyFlows(d.Inflows),
+		Refunds:  encodeSupplyFlows(d.Refunds),
+	})
+}
+
+// UnmarshalJSON unmarshals a supply change from its RPC representation.
+func (d *SupplyDelta) UnmarshalJSON(input []byte) error {
+	var dec supplyDeltaJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	d.Issued = bigOrZero(dec.Issued)
+	d.Burned = bigOrZero(dec.Burned)
+	d.ETxOut = bigOrZero(dec.ETxOut)
+	d.ETxIn = bigOrZero(dec.ETxIn)
+	d.Outflows = decodeSupplyFlows(dec.Outflows)
+	d.Inflows = decodeSupplyFlows(dec.Inflows)
+	d.Refunds = decodeSupplyFlows(dec.Refunds)
+	return nil
+}
+
+type supplyJSON struct {
+	Hash        common.Hash    `json:"hash"`
+	Number      hexutil.Uint64 `json:"number"`
+	Location    hexutil.Bytes  `json:"location"`
+	Base        *hexutil.Big   `json:"base"`
+	Circulating *hexutil.Big   `json:"circulating"`
+	Block       *SupplyDelta   `json:"block"`
+	Total       *SupplyDelta   `json:"total"`
+}
+
+// MarshalJSON marshals the supply record in its RPC representation.
+func (s *Supply) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&supplyJSON{
+		Hash:        s.Hash,
+		Number:      hexutil.Uint64(s.Number),
+		Location:    s.Location,
+		Base:        (*hexutil.Big)(s.Base),
+		Circulating: (*hexutil.Big)(s.Circulating()),
+		Block:       s.Block,
+		Total:       s.Total,
+	})
+}
+
+// UnmarshalJSON unmarshals a supply record from its RPC representation.
+func (s *Supply) UnmarshalJSON(input []byte) error {
+	var dec supplyJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	s.Hash = dec.Hash
+	s.Number = uint64(dec.Number)
+	s.Location = dec.Location
+	s.Base = bigOrZero(dec.Base)
+	s.Block, s.Total = dec.Block, dec.Total
+	if s.Block == nil {
+		s.Block = NewSupplyDelta()
+	}
+	if s.Total == nil {
+		s.Total = NewSupplyDelta()
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/rlp"
+)
+
+func TestSupplyDeltaFlows(t *testing.T) {
+	delta := NewSupplyDelta()
+	delta.Issued.SetUint64(100)
+	delta.Burned.SetUint64(10)
+	delta.AddOutflow([]byte{2, 1}, big.NewInt(5))
+	delta.AddOutflow([]byte{1, 2}, big.NewInt(3))
+	delta.AddOutflow([]byte{2, 1}, big.NewInt(4))
+	delta.AddInflow([]byte{3, 3}, big.NewInt(7))
+	delta.AddRefund([]byte{1, 2}, big.NewInt(2))
+
+	if have := delta.Outflow([]byte{2, 1}); have.Cmp(big.NewInt(9)) != 0 {
+		t.Errorf("outflow mismatch: have %v, want 9", have)
+	}
+	if have := delta.Outflow([]byte{3, 3}); have.Sign() != 0 {
+		t.Errorf("unexpected outflow: %v", have)
+	}
+	if len(delta.Outflows) != 2 || !reflect.DeepEqual(delta.Outflows[0].Location, []byte{1, 2}) {
+		t.Errorf("outflows not sorted by location: %v", delta.Outflows)
+	}
+	if have := delta.Refund([]byte{1, 2}); have.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("refund mismatch: have %v, want 2", have)
+	}
+	if have := delta.Inflow([]byte{1, 2}); have.Sign() != 0 {
+		t.Errorf("refund counted as applied inflow: %v", have)
+	}
+	// 100 issued - 10 burned - 12 sent + 7 received + 2 refunded
+	if have := delta.Net(); have.Cmp(big.NewInt(87)) != 0 {
+		t.Errorf("net change mismatch: have %v, want 87", have)
+	}
+	total := delta.Copy()
+	total.Add(delta)
+	if have := total.Net(); have.Cmp(big.NewInt(174)) != 0 {
+		t.Errorf("accumulated net change mismatch: have %v, want 174", have)
+	}
+	if have := delta.Net(); have.Cmp(big.NewInt(87)) != 0 {
+		t.Errorf("copy shares state with the original: net change %v, want 87", have)
+	}
+}
+
+func TestSupplyEncoding(t *testing.T) {
+	block := NewSupplyDelta()
+	block.Issued.SetUint64(1000)
+	block.AddOutflow([]byte{1, 1}, big.NewInt(20))
+	total := block.Copy()
+	total.AddInflow([]byte{2, 3}, big.NewInt(30))
+	total.AddRefund([]byte{1, 1}, big.NewInt(5))
+
+	supply := &Supply{
+		Hash:     common.HexToHash("0x01"),
+		Number:   12,
+		Location: []byte{1, 2},
+		Base:     big.NewInt(500),
+		Block:    block,
+		Total:    total,
+	}
+	if have := supply.Circulating(); have.Cmp(big.NewInt(1515)) != 0 {
+		t.Errorf("circulating supply mismatch: have %v, want 1515", have)
+	}
+	enc, err := rlp.EncodeToBytes(supply)
+	if err != nil {
+		t.Fatalf("failed to encode supply: %v", err)
+	}
+	dec := new(Supply)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatalf("failed to decode supply: %v", err)
+	}
+	if have, _ := rlp.EncodeToBytes(dec); !bytes.Equal(have, enc) {
+		t.Errorf("RLP round trip mismatch:\nhave %x\nwant %x", have, enc)
+	}
+	blob, err := json.Marshal(supply)
+	if err != nil {
+		t.Fatalf("failed to marshal supply: %v", err)
+	}
+	dec = new(Supply)
+	if err := json.Unmarshal(blob, dec); err != nil {
+		t.Fatalf("failed to unmarshal supply: %v", err)
+	}
+	if have, _ := json.Marshal(dec); !bytes.Equal(have, blob) {
+		t.Errorf("JSON round trip mismatch:\nhave %s\nwant %s", have, blob)
+	}
+}
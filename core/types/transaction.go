@@ -617,12 +617,16 @@ func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 	var err error
 	msg.from, err = Sender(s, tx)
 	idRange := params.LookupChainIDRange(s.ChainID())
+	if idRange == nil {
+		return msg, ErrInvalidChain
+	}
 
 	// check if the from address is not a common.Address and the doesn't match the id range
 	sendingFromExternal := (int(msg.from[0]) < idRange[0] || int(msg.from[0]) > idRange[1]) && msg.from != common.Address{}
 
-	// checking if the transaction is deploying a contract
-	if tx.To() != nil && len(tx.Data()) == 0 {
+	// Contract creations cannot cross chains, but calls carrying calldata can
+	// and are executed in the destination zone.
+	if tx.To() != nil {
 		sendingToInternal := int(tx.To()[0]) >= idRange[0] && int(tx.To()[0]) <= idRange[1]
 		if sendingFromExternal && sendingToInternal {
 			msg.fromExternal = true
@@ -30,9 +30,6 @@ import (
 var ErrInvalidChainId = errors.New("invalid chain id for signer")
 var ErrInvalidChain = errors.New("the chain has an invalid chain id")
 
-var mainnetValidChainIdMuls = map[uint64]*big.Int{9000: big.NewInt(18000), 9100: big.NewInt(18200), 9101: big.NewInt(18202), 9102: big.NewInt(18204), 9103: big.NewInt(18206), 9200: big.NewInt(18400), 9201: big.NewInt(18402), 9202: big.NewInt(18404), 9203: big.NewInt(18406), 9300: big.NewInt(18600), 9301: big.NewInt(18602), 9302: big.NewInt(18604), 9303: big.NewInt(18606)}
-var testnetValidChainIdMuls = map[uint64]*big.Int{12000: big.NewInt(24000), 12100: big.NewInt(24200), 12101: big.NewInt(24202), 12102: big.NewInt(24204), 12103: big.NewInt(24206), 12200: big.NewInt(24400), 12201: big.NewInt(24402), 12202: big.NewInt(24404), 12203: big.NewInt(24406), 12300: big.NewInt(24600), 12301: big.NewInt(24602), 12302: big.NewInt(24604), 12303: big.NewInt(24606)}
-
 // sigCache is used to cache the derived sender and contains
 // the signer used to derive it.
 type sigCache struct {
@@ -265,10 +262,7 @@ func (s eip2930Signer) Sender(tx *Transaction) (common.Address, error) {
 			return HomesteadSigner{}.Sender(tx)
 		}
 		// check if the chain has different chainId from the allowed list
-		if s.chainIdMul == nil {
-			return common.Address{}, ErrInvalidChain
-		}
-		chainIdMul, isFound := s.chainIdMul[tx.ChainId().Uint64()]
+		chainIdMul, isFound := s.chainIdMul(tx.ChainId())
 		if !isFound {
 			return common.Address{}, ErrInvalidChain
 		}
@@ -341,33 +335,28 @@ func (s eip2930Signer) Hash(tx *Transaction) common.Hash {
 // EIP155Signer implements Signer using the EIP-155 rules. This accepts transactions which
 // are replay-protected as well as unprotected homestead transactions.
 type EIP155Signer struct {
-	chainId    *big.Int
-	chainIdMul map[uint64]*big.Int
+	chainId *big.Int
 }
 
 func NewEIP155Signer(chainId *big.Int) EIP155Signer {
 	if chainId == nil {
 		chainId = new(big.Int)
 	}
-	_, isMainnet := mainnetValidChainIdMuls[chainId.Uint64()]
-	if isMainnet {
-		return EIP155Signer{
-			chainId:    chainId,
-			chainIdMul: mainnetValidChainIdMuls,
-		}
-	}
-	_, isTestnet := testnetValidChainIdMuls[chainId.Uint64()]
-	if isTestnet {
-		return EIP155Signer{
-			chainId:    chainId,
-			chainIdMul: testnetValidChainIdMuls,
-		}
-	}
 	return EIP155Signer{
 		chainId: chainId,
 	}
 }
 
+// chainIdMul returns the chain ID multiplier encoded into the V value of a
+// legacy transaction for the given chain, which has to be the chain of the
+// signer or another chain of its network.
+func (s EIP155Signer) chainIdMul(chainId *big.Int) (*big.Int, bool) {
+	if chainId.Cmp(s.chainId) != 0 && !params.ValidChainID(chainId, s.chainId) {
+		return nil, false
+	}
+	return new(big.Int).Mul(chainId, big.NewInt(2)), true
+}
+
 func (s EIP155Signer) ChainID() *big.Int {
 	return s.chainId
 }
@@ -391,10 +380,7 @@ func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
 	}
 	V, R, S := tx.RawSignatureValues()
 	// check if the chainId of the current chain is valid
-	if s.chainIdMul == nil {
-		return common.Address{}, ErrInvalidChain
-	}
-	chainIdMul, isFound := s.chainIdMul[tx.ChainId().Uint64()]
+	chainIdMul, isFound := s.chainIdMul(tx.ChainId())
 	if !isFound {
 		return common.Address{}, ErrInvalidChain
 	}
@@ -412,11 +398,9 @@ func (s EIP155Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big
 	R, S, V = decodeSignature(sig)
 	if s.chainId.Sign() != 0 {
 		V = big.NewInt(int64(sig[64] + 35))
-		// check if the chainId of the current chain is valid
-		if s.chainIdMul == nil {
-			return nil, nil, nil, ErrInvalidChain
-		}
-		chainIdMul, isFound := s.chainIdMul[tx.ChainId().Uint64()]
+		// An unsigned legacy transaction carries no chain ID, it is signed
+		// for the chain of the signer
+		chainIdMul, isFound := s.chainIdMul(s.chainId)
 		if !isFound {
 			return nil, nil, nil, ErrInvalidChain
 		}
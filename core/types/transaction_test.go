@@ -259,6 +259,29 @@ func TestRecipientNormal(t *testing.T) {
 	}
 }
 
+// Tests that a transaction of a chain outside of every registered ontology
+// can't be turned into a message.
+func TestAsMessageUnknownChain(t *testing.T) {
+	key, _ := defaultTestKey()
+	tests := []struct {
+		chainID *big.Int
+		err     error
+	}{
+		{big.NewInt(9101), nil},
+		{big.NewInt(1337), ErrInvalidChain},
+	}
+	for _, test := range tests {
+		signer := LatestSignerForChainID(test.chainID)
+		tx, err := SignNewTx(key, signer, &LegacyTx{To: &testAddr, Gas: 21000, GasPrice: big.NewInt(1)})
+		if err != nil {
+			t.Fatalf("chain %v: failed to sign: %v", test.chainID, err)
+		}
+		if _, err := tx.AsMessage(signer, nil); err != test.err {
+			t.Errorf("chain %v: error mismatch: have %v, want %v", test.chainID, err, test.err)
+		}
+	}
+}
+
 func TestTransactionPriceNonceSortLegacy(t *testing.T) {
 	testTransactionPriceNonceSort(t, nil)
 }
@@ -233,6 +233,11 @@ func (d *dummyChain) Engine() consensus.Engine {
 	return nil
 }
 
+// Config retrieves the chain's configuration.
+func (d *dummyChain) Config() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
 // GetHeader returns the hash corresponding to their hash.
 func (d *dummyChain) GetHeader(h common.Hash, n uint64) *types.Header {
 	d.counter++
@@ -293,7 +298,7 @@ func TestBlockhash(t *testing.T) {
 	input := common.Hex2Bytes("f8a8fd6d")
 	chain := &dummyChain{}
 	ret, _, err := Execute(data, input, &Config{
-		GetHashFn:   core.GetHashFn(header, chain),
+		GetHashFn:   core.GetHashFn(header, chain, types.QuaiNetworkContext),
 		BlockNumber: new(big.Int).Set(header.Number[types.QuaiNetworkContext]),
 	})
 	if err != nil {
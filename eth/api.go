@@ -36,6 +36,7 @@ import (
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/internal/ethapi"
 	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rlp"
 	"github.com/spruce-solutions/go-quai/rpc"
 	"github.com/spruce-solutions/go-quai/trie"
@@ -67,6 +68,27 @@ func (api *PublicEthereumAPI) Hashrate() hexutil.Uint64 {
 	return hexutil.Uint64(api.e.Miner().Hashrate())
 }
 
+// PrivateHierarchyAPI provides the links of the node to the other chains of the
+// hierarchy. It is only served on the authenticated endpoint, as the urls it
+// returns are those of the authenticated endpoints of the subordinate nodes.
+type PrivateHierarchyAPI struct {
+	e *Ethereum
+}
+
+// NewPrivateHierarchyAPI creates a new API exposing the links of the node.
+func NewPrivateHierarchyAPI(e *Ethereum) *PrivateHierarchyAPI {
+	return &PrivateHierarchyAPI{e}
+}
+
+// SubordinateUrls returns the urls of the subordinate chains the node is linked
+// to, indexed by region or zone. Zones have no subordinate chains.
+func (api *PrivateHierarchyAPI) SubordinateUrls() []string {
+	if api.e.blockchain.Config().Context == params.ZONE {
+		return []string{}
+	}
+	return api.e.config.SubUrls
+}
+
 // PublicMinerAPI provides an API to control the miner.
 // It offers only methods that operate on data that pose no security risk when it is publicly accessible.
 type PublicMinerAPI struct {
@@ -166,7 +188,7 @@ func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64
 		return false, errors.New("last cannot be specified without first")
 	}
 	if first != nil && last == nil {
-		head := api.eth.BlockChain().CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
+		head := api.eth.BlockChain().CurrentHeader().Number[api.eth.blockchain.Config().Context].Uint64()
 		last = &head
 	}
 	if _, err := os.Stat(file); err == nil {
@@ -200,7 +222,7 @@ func (api *PrivateAdminAPI) ExportChain(file string, first *uint64, last *uint64
 
 func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	for _, b := range bs {
-		if !chain.HasBlock(b.Hash(), b.NumberU64()) {
+		if !chain.HasBlock(b.Hash(), b.NumberU64(chain.Config().Context)) {
 			return false
 		}
 	}
@@ -224,35 +246,43 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 		}
 	}
 
-	// Run actual the import in pre-configured batches
-	stream := rlp.NewStream(reader, 0)
+	exports, err := core.NewExportReader(reader)
+	if err != nil {
+		return false, err
+	}
+	if err := exports.Verify(api.eth.BlockChain()); err != nil {
+		return false, err
+	}
 
-	blocks, index := make([]*types.Block, 0, 2500), 0
+	// Run actual the import in pre-configured batches
+	exported, index := make([]*core.ExportedBlock, 0, 2500), 0
+	blocks := make([]*types.Block, 0, cap(exported))
 	for batch := 0; ; batch++ {
 		// Load a batch of blocks from the input file
-		for len(blocks) < cap(blocks) {
-			block := new(types.Block)
-			if err := stream.Decode(block); err == io.EOF {
+		for len(exported) < cap(exported) {
+			entry, err := exports.Next()
+			if err == io.EOF {
 				break
 			} else if err != nil {
 				return false, fmt.Errorf("block %d: failed to parse: %v", index, err)
 			}
-			blocks = append(blocks, block)
+			exported = append(exported, entry)
+			blocks = append(blocks, entry.Block)
 			index++
 		}
-		if len(blocks) == 0 {
+		if len(exported) == 0 {
 			break
 		}
 
 		if hasAllBlocks(api.eth.BlockChain(), blocks) {
-			blocks = blocks[:0]
+			exported, blocks = exported[:0], blocks[:0]
 			continue
 		}
 		// Import the batch and reset the buffer
-		if _, err := api.eth.BlockChain().InsertChain(blocks); err != nil {
+		if _, err := api.eth.BlockChain().InsertExportedChain(exported); err != nil {
 			return false, fmt.Errorf("batch %d: failed to insert: %v", batch, err)
 		}
-		blocks = blocks[:0]
+		exported, blocks = exported[:0], blocks[:0]
 	}
 	return true, nil
 }
@@ -291,7 +321,7 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 	if block == nil {
 		return state.Dump{}, fmt.Errorf("block #%d not found", blockNr)
 	}
-	stateDb, err := api.eth.BlockChain().StateAt(block.Root())
+	stateDb, err := api.eth.BlockChain().StateAt(block.Root(api.eth.blockchain.Config().Context))
 	if err != nil {
 		return state.Dump{}, err
 	}
@@ -318,6 +348,21 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// VerifyPCRC re-applies PCRC to the block with the given hash and to depth of
+// its previous prime termini, or down to the genesis or the checkpoint if depth
+// is 0, reporting the first twisted link found.
+func (api *PrivateDebugAPI) VerifyPCRC(ctx context.Context, hash common.Hash, depth hexutil.Uint64) (*core.PCRCReport, error) {
+	header := api.eth.blockchain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, fmt.Errorf("header %#x not found", hash)
+	}
+	report, err := api.eth.blockchain.VerifyPCRC(header, uint64(depth))
+	if err != nil && report.Twisted == nil {
+		return nil, err
+	}
+	return report, nil
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
 	Hash  common.Hash            `json:"hash"`
@@ -343,7 +388,7 @@ func (api *PrivateDebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs,
 		} else {
 			blockRlp = fmt.Sprintf("0x%x", rlpBytes)
 		}
-		if blockJSON, err = ethapi.RPCMarshalBlock(block, true, true); err != nil {
+		if blockJSON, err = ethapi.RPCMarshalBlock(block, true, true, api.eth.blockchain.Config().Context); err != nil {
 			blockJSON = map[string]interface{}{"error": err.Error()}
 		}
 		results = append(results, &BadBlockArgs{
@@ -379,7 +424,7 @@ func (api *PublicDebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, sta
 			if block == nil {
 				return state.IteratorDump{}, fmt.Errorf("block #%d not found", number)
 			}
-			stateDb, err = api.eth.BlockChain().StateAt(block.Root())
+			stateDb, err = api.eth.BlockChain().StateAt(block.Root(api.eth.blockchain.Config().Context))
 			if err != nil {
 				return state.IteratorDump{}, err
 			}
@@ -389,7 +434,7 @@ func (api *PublicDebugAPI) AccountRange(blockNrOrHash rpc.BlockNumberOrHash, sta
 		if block == nil {
 			return state.IteratorDump{}, fmt.Errorf("block %s not found", hash.Hex())
 		}
-		stateDb, err = api.eth.BlockChain().StateAt(block.Root())
+		stateDb, err = api.eth.BlockChain().StateAt(block.Root(api.eth.blockchain.Config().Context))
 		if err != nil {
 			return state.IteratorDump{}, err
 		}
@@ -479,9 +524,9 @@ func (api *PrivateDebugAPI) GetModifiedAccountsByNumber(startNum uint64, endNum
 
 	if endNum == nil {
 		endBlock = startBlock
-		startBlock = api.eth.blockchain.GetBlockByHash(startBlock.ParentHash())
+		startBlock = api.eth.blockchain.GetBlockByHash(startBlock.ParentHash(api.eth.blockchain.Config().Context))
 		if startBlock == nil {
-			return nil, fmt.Errorf("block %x has no parent", endBlock.Number())
+			return nil, fmt.Errorf("block %x has no parent", endBlock.Number(api.eth.blockchain.Config().Context))
 		}
 	} else {
 		endBlock = api.eth.blockchain.GetBlockByNumber(*endNum)
@@ -506,9 +551,9 @@ func (api *PrivateDebugAPI) GetModifiedAccountsByHash(startHash common.Hash, end
 
 	if endHash == nil {
 		endBlock = startBlock
-		startBlock = api.eth.blockchain.GetBlockByHash(startBlock.ParentHash())
+		startBlock = api.eth.blockchain.GetBlockByHash(startBlock.ParentHash(api.eth.blockchain.Config().Context))
 		if startBlock == nil {
-			return nil, fmt.Errorf("block %x has no parent", endBlock.Number())
+			return nil, fmt.Errorf("block %x has no parent", endBlock.Number(api.eth.blockchain.Config().Context))
 		}
 	} else {
 		endBlock = api.eth.blockchain.GetBlockByHash(*endHash)
@@ -520,16 +565,17 @@ func (api *PrivateDebugAPI) GetModifiedAccountsByHash(startHash common.Hash, end
 }
 
 func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Block) ([]common.Address, error) {
-	if startBlock.Number().Uint64() >= endBlock.Number().Uint64() {
-		return nil, fmt.Errorf("start block height (%d) must be less than end block height (%d)", startBlock.Number().Uint64(), endBlock.Number().Uint64())
+	context := api.eth.blockchain.Config().Context
+	if startBlock.NumberU64(context) >= endBlock.NumberU64(context) {
+		return nil, fmt.Errorf("start block height (%d) must be less than end block height (%d)", startBlock.NumberU64(context), endBlock.NumberU64(context))
 	}
 	triedb := api.eth.BlockChain().StateCache().TrieDB()
 
-	oldTrie, err := trie.NewSecure(startBlock.Root(), triedb)
+	oldTrie, err := trie.NewSecure(startBlock.Root(context), triedb)
 	if err != nil {
 		return nil, err
 	}
-	newTrie, err := trie.NewSecure(endBlock.Root(), triedb)
+	newTrie, err := trie.NewSecure(endBlock.Root(context), triedb)
 	if err != nil {
 		return nil, err
 	}
@@ -566,7 +612,7 @@ func (api *PrivateDebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64
 			if block == nil {
 				return 0, fmt.Errorf("current block missing")
 			}
-			return block.NumberU64(), nil
+			return block.NumberU64(api.eth.blockchain.Config().Context), nil
 		}
 		return uint64(num.Int64()), nil
 	}
@@ -601,7 +647,7 @@ func (api *PrivateDebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64
 		if h == nil {
 			return 0, fmt.Errorf("missing header %d", i)
 		}
-		if ok, _ := api.eth.ChainDb().Has(h.Root[types.QuaiNetworkContext][:]); ok {
+		if ok, _ := api.eth.ChainDb().Has(h.Root[api.eth.blockchain.Config().Context][:]); ok {
 			return uint64(i), nil
 		}
 	}
@@ -83,7 +83,7 @@ func (b *EthAPIBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash
 		if header == nil {
 			return nil, errors.New("header for hash not found")
 		}
-		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number[types.QuaiNetworkContext].Uint64()) != hash {
+		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number[b.eth.blockchain.Config().Context].Uint64()) != hash {
 			return nil, errors.New("hash is not currently canonical")
 		}
 		return header, nil
@@ -117,7 +117,7 @@ func (b *EthAPIBackend) PendingBlock(ctx context.Context) (*types.Block, error)
 }
 
 func (b *EthAPIBackend) InsertBlock(ctx context.Context, block *types.Block) (int, error) {
-	return b.eth.blockchain.InsertChain([]*types.Block{block})
+	return b.eth.blockchain.InsertMinedBlock(ctx, block)
 }
 
 func (b *EthAPIBackend) AddExternalBlock(block *types.ExternalBlock) error {
@@ -132,6 +132,14 @@ func (b *EthAPIBackend) GetExternalBlockByHashAndContext(hash common.Hash, conte
 	return b.eth.blockchain.GetExternalBlockByHashAndContext(hash, context)
 }
 
+func (b *EthAPIBackend) GetCoincidentExternalBlocks(hash common.Hash) []*types.ExternalBlock {
+	return b.eth.blockchain.GetCoincidentExternalBlocks(hash)
+}
+
+func (b *EthAPIBackend) ExternalBlockCacheStats() core.ExternalBlockCacheStats {
+	return b.eth.blockchain.ExternalBlockCacheStats()
+}
+
 func (b *EthAPIBackend) GetSubordinateSet(stopHash common.Hash, location []byte) ([]common.Hash, error) {
 	return b.eth.blockchain.GetSubordinateSet(stopHash, location)
 }
@@ -156,6 +164,34 @@ func (b *EthAPIBackend) PCRC(header *types.Header, order int) (types.PCRCTermini
 	return b.eth.blockchain.PCRC(header, order)
 }
 
+func (b *EthAPIBackend) GetETxStatus(hash common.Hash) *types.ETxStatus {
+	return b.eth.blockchain.GetETxStatus(hash)
+}
+
+func (b *EthAPIBackend) GetReorgHistory(count uint64) []*types.ReorgRecord {
+	return b.eth.blockchain.GetReorgHistory(count)
+}
+
+func (b *EthAPIBackend) FaultProofs() []*types.FaultProof {
+	return b.eth.blockchain.FaultProofs()
+}
+
+func (b *EthAPIBackend) AddFaultProof(proof *types.FaultProof) error {
+	return b.eth.blockchain.AddFaultProof(proof)
+}
+
+func (b *EthAPIBackend) GetSupply(hash common.Hash) *types.Supply {
+	return b.eth.blockchain.GetSupply(hash)
+}
+
+func (b *EthAPIBackend) ZoneSupplies(ctx context.Context) ([]*types.Supply, error) {
+	return b.eth.blockchain.ZoneSupplies(ctx)
+}
+
+func (b *EthAPIBackend) NetworkSupply(ctx context.Context) (*core.NetworkSupply, error) {
+	return b.eth.blockchain.NetworkSupply(ctx)
+}
+
 func (b *EthAPIBackend) PCCRC(header *types.Header, order int) (types.PCRCTermini, error) {
 	return b.eth.blockchain.PCCRC(header, order)
 }
@@ -177,10 +213,10 @@ func (b *EthAPIBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash r
 		if header == nil {
 			return nil, errors.New("header for hash not found")
 		}
-		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number[types.QuaiNetworkContext].Uint64()) != hash {
+		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number[b.eth.blockchain.Config().Context].Uint64()) != hash {
 			return nil, errors.New("hash is not currently canonical")
 		}
-		block := b.eth.blockchain.GetBlock(hash, header.Number[types.QuaiNetworkContext].Uint64())
+		block := b.eth.blockchain.GetBlock(hash, header.Number[b.eth.blockchain.Config().Context].Uint64())
 		if block == nil {
 			return nil, errors.New("header found, but block body is missing")
 		}
@@ -207,7 +243,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.B
 	if header == nil {
 		return nil, nil, errors.New("header not found")
 	}
-	stateDb, err := b.eth.BlockChain().StateAt(header.Root[types.QuaiNetworkContext])
+	stateDb, err := b.eth.BlockChain().StateAt(header.Root[b.eth.blockchain.Config().Context])
 	return stateDb, header, err
 }
 
@@ -223,10 +259,10 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 		if header == nil {
 			return nil, nil, errors.New("header for hash not found")
 		}
-		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number[types.QuaiNetworkContext].Uint64()) != hash {
+		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number[b.eth.blockchain.Config().Context].Uint64()) != hash {
 			return nil, nil, errors.New("hash is not currently canonical")
 		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root[types.QuaiNetworkContext])
+		stateDb, err := b.eth.BlockChain().StateAt(header.Root[b.eth.blockchain.Config().Context])
 		return stateDb, header, err
 	}
 	return nil, nil, errors.New("invalid arguments; neither block nor hash specified")
@@ -259,7 +295,7 @@ func (b *EthAPIBackend) GetEVM(ctx context.Context, msg core.Message, state *sta
 		vmConfig = b.eth.blockchain.GetVMConfig()
 	}
 	txContext := core.NewEVMTxContext(msg)
-	context := core.NewEVMBlockContext(header, b.eth.BlockChain(), nil)
+	context := core.NewEVMBlockContext(header, b.eth.BlockChain(), nil, b.eth.blockchain.Config().Context)
 	return vm.NewEVM(context, txContext, state, b.eth.blockchain.Config(), *vmConfig), vmError, nil
 }
 
@@ -283,6 +319,14 @@ func (b *EthAPIBackend) SubscribeMissingExternalBlockEvent(ch chan<- core.Missin
 	return b.eth.BlockChain().SubscribeMissingExternalBlockEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeETxStatusEvent(ch chan<- core.ETxStatusEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeETxStatusEvent(ch)
+}
+
+func (b *EthAPIBackend) SubscribeReorgJournalEvent(ch chan<- core.ReorgJournalEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeReorgJournalEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.eth.BlockChain().SubscribeChainEvent(ch)
 }
@@ -344,6 +388,10 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactio
 	return b.eth.TxPool().ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) ETxPoolContent() ([]*types.PendingETx, []*types.ETxRefund) {
+	return b.eth.BlockChain().ETxPool().Content()
+}
+
 func (b *EthAPIBackend) TxPool() *core.TxPool {
 	return b.eth.TxPool()
 }
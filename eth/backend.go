@@ -34,6 +34,7 @@ import (
 	"github.com/spruce-solutions/go-quai/consensus/clique"
 	"github.com/spruce-solutions/go-quai/core"
 	"github.com/spruce-solutions/go-quai/core/bloombits"
+	"github.com/spruce-solutions/go-quai/core/forkchoice"
 	"github.com/spruce-solutions/go-quai/core/rawdb"
 	"github.com/spruce-solutions/go-quai/core/state/pruner"
 	"github.com/spruce-solutions/go-quai/core/types"
@@ -138,7 +139,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 
 	knotSet := make([]*types.Block, 0)
-	switch types.QuaiNetworkContext {
+	switch chainConfig.Context {
 	case params.PRIME:
 		knotSet = config.Genesis.Knot
 	case params.REGION:
@@ -161,10 +162,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 
 	for _, block := range knotSet {
 		if block != nil {
-			rawdb.WriteTd(chainDb, block.Hash(), block.NumberU64(), config.Genesis.Difficulty)
-			rawdb.WriteBlock(chainDb, block)
-			rawdb.WriteReceipts(chainDb, block.Hash(), block.NumberU64(), nil)
-			rawdb.WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+			rawdb.WriteTd(chainDb, block.Hash(), block.NumberU64(chainConfig.Context), config.Genesis.Difficulty)
+			rawdb.WriteBlock(chainDb, block, chainConfig.Context)
+			rawdb.WriteReceipts(chainDb, block.Hash(), block.NumberU64(chainConfig.Context), nil)
+			rawdb.WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64(chainConfig.Context))
 			rawdb.WriteHeadBlockHash(chainDb, block.Hash())
 			rawdb.WriteHeadFastBlockHash(chainDb, block.Hash())
 			rawdb.WriteHeadHeaderHash(chainDb, block.Hash())
@@ -172,6 +173,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 
 	log.Info("Initialised chain configuration", "config", chainConfig)
+	rawdb.SetFreezerContext(chainDb, chainConfig.Context)
 
 	if err := pruner.RecoverPruning(stack.ResolvePath(""), chainDb, stack.ResolvePath(config.TrieCleanCacheJournal)); err != nil {
 		log.Error("Failed to recover state", "error", err)
@@ -187,7 +189,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		gasPrice:          config.Miner.GasPrice,
 		etherbase:         config.Miner.Etherbase,
 		bloomRequests:     make(chan chan *bloombits.Retrieval),
-		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
+		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms, chainConfig.Context),
 		p2pServer:         stack.Server(),
 	}
 
@@ -208,22 +210,31 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			rawdb.WriteDatabaseVersion(chainDb, core.BlockChainVersion)
 		}
 	}
+	// External blocks linked by blocks that may still be reorged are needed to
+	// reprocess them, so never prune them before the fork choice finalizes.
+	if config.ExternalBlockRetention != 0 && config.ExternalBlockRetention < config.FinalityDepth {
+		log.Warn("Raising external block retention to the finality depth", "provided", config.ExternalBlockRetention, "updated", config.FinalityDepth)
+		config.ExternalBlockRetention = config.FinalityDepth
+	}
 	var (
 		vmConfig = vm.Config{
 			EnablePreimageRecording: config.EnablePreimageRecording,
 		}
 		cacheConfig = &core.CacheConfig{
-			TrieCleanLimit:       config.TrieCleanCache,
-			TrieCleanJournal:     stack.ResolvePath(config.TrieCleanCacheJournal),
-			TrieCleanRejournal:   config.TrieCleanCacheRejournal,
-			TrieCleanNoPrefetch:  config.NoPrefetch,
-			TrieDirtyLimit:       config.TrieDirtyCache,
-			TrieDirtyDisabled:    config.NoPruning,
-			TrieTimeLimit:        config.TrieTimeout,
-			SnapshotLimit:        config.SnapshotCache,
-			Preimages:            config.Preimages,
-			ExternalBlockLimit:   config.ExternalBlockCache,
-			ExternalBlockJournal: stack.ResolvePath(config.ExternalBlocksCacheJournal),
+			TrieCleanLimit:         config.TrieCleanCache,
+			TrieCleanJournal:       stack.ResolvePath(config.TrieCleanCacheJournal),
+			TrieCleanRejournal:     config.TrieCleanCacheRejournal,
+			TrieCleanNoPrefetch:    config.NoPrefetch,
+			TrieDirtyLimit:         config.TrieDirtyCache,
+			TrieDirtyDisabled:      config.NoPruning,
+			TrieTimeLimit:          config.TrieTimeout,
+			SnapshotLimit:          config.SnapshotCache,
+			Preimages:              config.Preimages,
+			ExternalBlockLimit:     config.ExternalBlockCache,
+			ExternalBlockRetention: config.ExternalBlockRetention,
+			PCRCDepth:              config.PCRCDepth,
+			PCRCCheckpoint:         config.PCRCCheckpoint,
+			LinkSecret:             stack.AuthSecret(),
 		}
 	)
 
@@ -231,6 +242,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	if config.FinalityDepth > 0 {
+		eth.blockchain.SetForkChoice(&forkchoice.HLCR{FinalityDepth: config.FinalityDepth})
+	}
 
 	// Rewind the chain in case of an incompatible config upgrade.
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
@@ -296,6 +310,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	stack.RegisterAPIs(eth.APIs())
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
+	if server := newStratumServer(eth, config); server != nil {
+		stack.RegisterLifecycle(server)
+	}
 	// Check for unclean shutdown
 	if uncleanShutdowns, discards, err := rawdb.PushUncleanShutdownMarker(chainDb); err != nil {
 		log.Error("Could not update unclean-shutdown-marker list", "error", err)
@@ -388,6 +405,11 @@ func (s *Ethereum) APIs() []rpc.API {
 			Namespace: "admin",
 			Version:   "1.0",
 			Service:   NewPrivateAdminAPI(s),
+		}, {
+			Namespace:     "domsub",
+			Version:       "1.0",
+			Service:       NewPrivateHierarchyAPI(s),
+			Authenticated: true,
 		}, {
 			Namespace: "debug",
 			Version:   "1.0",
@@ -92,7 +92,7 @@ func (env *blockExecutionEnv) commitTransaction(tx *types.Transaction, coinbase
 }
 
 func (api *consensusAPI) makeEnv(parent *types.Block, header *types.Header) (*blockExecutionEnv, error) {
-	state, err := api.eth.BlockChain().StateAt(parent.Root())
+	state, err := api.eth.BlockChain().StateAt(parent.Root(types.QuaiNetworkContext))
 	if err != nil {
 		return nil, err
 	}
@@ -137,11 +137,11 @@ func (api *consensusAPI) AssembleBlock(params assembleBlockParams) (*executableD
 	if err != nil {
 		return nil, err
 	}
-	num := parent.Number()
+	num := parent.Number(types.QuaiNetworkContext)
 
 	header := types.NewEmptyHeader()
 	header.Time = params.Timestamp
-	header.GasLimit[types.QuaiNetworkContext] = parent.GasLimit()
+	header.GasLimit[types.QuaiNetworkContext] = parent.GasLimit(types.QuaiNetworkContext)
 	header.ParentHash[types.QuaiNetworkContext] = parent.Hash()
 	header.Number[types.QuaiNetworkContext] = num.Add(num, common.Big1)
 	header.Coinbase[types.QuaiNetworkContext] = coinbase
@@ -217,15 +217,15 @@ func (api *consensusAPI) AssembleBlock(params assembleBlockParams) (*executableD
 	}
 	return &executableData{
 		BlockHash:    block.Hash(),
-		ParentHash:   block.ParentHash(),
-		Miner:        block.Coinbase(),
-		StateRoot:    block.Root(),
-		Number:       block.NumberU64(),
-		GasLimit:     block.GasLimit(),
-		GasUsed:      block.GasUsed(),
+		ParentHash:   block.ParentHash(types.QuaiNetworkContext),
+		Miner:        block.Coinbase(types.QuaiNetworkContext),
+		StateRoot:    block.Root(types.QuaiNetworkContext),
+		Number:       block.NumberU64(types.QuaiNetworkContext),
+		GasLimit:     block.GasLimit(types.QuaiNetworkContext),
+		GasUsed:      block.GasUsed(types.QuaiNetworkContext),
 		Timestamp:    block.Time(),
-		ReceiptRoot:  block.ReceiptHash(),
-		LogsBloom:    block.Bloom().Bytes(),
+		ReceiptRoot:  block.ReceiptHash(types.QuaiNetworkContext),
+		LogsBloom:    block.Bloom(types.QuaiNetworkContext).Bytes(),
 		Transactions: encodeTransactions(block.Transactions()),
 	}, nil
 }
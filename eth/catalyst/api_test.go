@@ -113,13 +113,13 @@ func TestEth2AssembleBlock(t *testing.T) {
 
 	api := newConsensusAPI(ethservice)
 	signer := types.NewEIP155Signer(ethservice.BlockChain().Config().ChainID)
-	tx, err := types.SignTx(types.NewTransaction(0, blocks[8].Coinbase(), big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, testKey)
+	tx, err := types.SignTx(types.NewTransaction(0, blocks[8].Coinbase(types.QuaiNetworkContext), big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), signer, testKey)
 	if err != nil {
 		t.Fatalf("error signing transaction, err=%v", err)
 	}
 	ethservice.TxPool().AddLocal(tx)
 	blockParams := assembleBlockParams{
-		ParentHash: blocks[8].ParentHash(),
+		ParentHash: blocks[8].ParentHash(types.QuaiNetworkContext),
 		Timestamp:  blocks[8].Time(),
 	}
 	execData, err := api.AssembleBlock(blockParams)
@@ -143,7 +143,7 @@ func TestEth2AssembleBlockWithAnotherBlocksTxs(t *testing.T) {
 	// Put the 10th block's tx in the pool and produce a new block
 	api.addBlockTxs(blocks[9])
 	blockParams := assembleBlockParams{
-		ParentHash: blocks[9].ParentHash(),
+		ParentHash: blocks[9].ParentHash(types.QuaiNetworkContext),
 		Timestamp:  blocks[9].Time(),
 	}
 	execData, err := api.AssembleBlock(blockParams)
@@ -165,13 +165,13 @@ func TestEth2NewBlock(t *testing.T) {
 	for i := 5; i < 10; i++ {
 		p := executableData{
 			ParentHash:   ethservice.BlockChain().CurrentBlock().Hash(),
-			Miner:        blocks[i].Coinbase(),
-			StateRoot:    blocks[i].Root(),
-			GasLimit:     blocks[i].GasLimit(),
-			GasUsed:      blocks[i].GasUsed(),
+			Miner:        blocks[i].Coinbase(types.QuaiNetworkContext),
+			StateRoot:    blocks[i].Root(types.QuaiNetworkContext),
+			GasLimit:     blocks[i].GasLimit(types.QuaiNetworkContext),
+			GasUsed:      blocks[i].GasUsed(types.QuaiNetworkContext),
 			Transactions: encodeTransactions(blocks[i].Transactions()),
-			ReceiptRoot:  blocks[i].ReceiptHash(),
-			LogsBloom:    blocks[i].Bloom().Bytes(),
+			ReceiptRoot:  blocks[i].ReceiptHash(types.QuaiNetworkContext),
+			LogsBloom:    blocks[i].Bloom(types.QuaiNetworkContext).Bytes(),
 			BlockHash:    blocks[i].Hash(),
 			Timestamp:    blocks[i].Time(),
 			Number:       uint64(i),
@@ -185,20 +185,20 @@ func TestEth2NewBlock(t *testing.T) {
 	exp := ethservice.BlockChain().CurrentBlock().Hash()
 
 	// Introduce the fork point.
-	lastBlockNum := blocks[4].Number()
+	lastBlockNum := blocks[4].Number(types.QuaiNetworkContext)
 	lastBlock := blocks[4]
 	for i := 0; i < 4; i++ {
 		lastBlockNum.Add(lastBlockNum, big.NewInt(1))
 		p := executableData{
 			ParentHash:   lastBlock.Hash(),
-			Miner:        forkedBlocks[i].Coinbase(),
-			StateRoot:    forkedBlocks[i].Root(),
+			Miner:        forkedBlocks[i].Coinbase(types.QuaiNetworkContext),
+			StateRoot:    forkedBlocks[i].Root(types.QuaiNetworkContext),
 			Number:       lastBlockNum.Uint64(),
-			GasLimit:     forkedBlocks[i].GasLimit(),
-			GasUsed:      forkedBlocks[i].GasUsed(),
+			GasLimit:     forkedBlocks[i].GasLimit(types.QuaiNetworkContext),
+			GasUsed:      forkedBlocks[i].GasUsed(types.QuaiNetworkContext),
 			Transactions: encodeTransactions(blocks[i].Transactions()),
-			ReceiptRoot:  forkedBlocks[i].ReceiptHash(),
-			LogsBloom:    forkedBlocks[i].Bloom().Bytes(),
+			ReceiptRoot:  forkedBlocks[i].ReceiptHash(types.QuaiNetworkContext),
+			LogsBloom:    forkedBlocks[i].Bloom(types.QuaiNetworkContext).Bytes(),
 			BlockHash:    forkedBlocks[i].Hash(),
 			Timestamp:    forkedBlocks[i].Time(),
 		}
@@ -19,7 +19,6 @@ package eth
 import (
 	"github.com/spruce-solutions/go-quai/core"
 	"github.com/spruce-solutions/go-quai/core/forkid"
-	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/p2p/enode"
 	"github.com/spruce-solutions/go-quai/rlp"
 )
@@ -60,5 +59,5 @@ func (eth *Ethereum) startEthEntryUpdate(ln *enode.LocalNode) {
 
 func (eth *Ethereum) currentEthEntry() *ethEntry {
 	return &ethEntry{ForkID: forkid.NewID(eth.blockchain.Config(), eth.blockchain.Genesis().Hash(),
-		eth.blockchain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64())}
+		eth.blockchain.CurrentHeader().Number[eth.blockchain.Config().Context].Uint64())}
 }
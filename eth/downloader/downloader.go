@@ -48,11 +48,11 @@ var (
 	MaxExtBlockFetch = 1028 // Amount of external blocks to allow fetching per request
 	MaxStateFetch    = 384  // Amount of node state values to allow fetching per request
 
-	maxQueuedHeaders            = 32 * 1024                                                   // [eth/62] Maximum number of headers to queue for import (DOS protection)
-	maxHeadersProcess           = 2048                                                        // Number of header download results to import at once into the chain
-	maxResultsProcess           = 2048                                                        // Number of content download results to import at once into the chain
-	fullMaxForkAncestry  uint64 = params.FullImmutabilityThreshold[types.QuaiNetworkContext]  // Maximum chain reorganisation (locally redeclared so tests can reduce it)
-	lightMaxForkAncestry uint64 = params.LightImmutabilityThreshold[types.QuaiNetworkContext] // Maximum chain reorganisation (locally redeclared so tests can reduce it)
+	maxQueuedHeaders     = 32 * 1024                         // [eth/62] Maximum number of headers to queue for import (DOS protection)
+	maxHeadersProcess    = 2048                              // Number of header download results to import at once into the chain
+	maxResultsProcess    = 2048                              // Number of content download results to import at once into the chain
+	fullMaxForkAncestry  = params.FullImmutabilityThreshold  // Maximum chain reorganisation per context (locally redeclared so tests can reduce it)
+	lightMaxForkAncestry = params.LightImmutabilityThreshold // Maximum chain reorganisation per context (locally redeclared so tests can reduce it)
 
 	reorgProtThreshold   = 48 // Threshold number of recent blocks to disable mini reorg protection
 	reorgProtHeaderDelay = 2  // Number of headers to delay delivering to cover mini reorgs
@@ -90,6 +90,7 @@ type Downloader struct {
 	mode uint32         // Synchronisation mode defining the strategy used (per sync cycle), use d.getMode() to get the SyncMode
 	mux  *event.TypeMux // Event multiplexer to announce sync operation events
 
+	context    int      // Context of the chain being synchronised
 	checkpoint uint64   // Checkpoint block number to enforce head against (e.g. fast sync)
 	genesis    uint64   // Genesis block number to limit sync to (e.g. light client CHT)
 	queue      *queue   // Scheduler for selecting the hashes to download
@@ -213,8 +214,9 @@ type BlockChain interface {
 	HLCR(localDifficulties []*big.Int, externDifficulties []*big.Int) bool
 }
 
-// New creates a new downloader to fetch hashes and blocks from remote peers.
-func New(checkpoint uint64, stateDb ethdb.Database, stateBloom *trie.SyncBloom, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn) *Downloader {
+// New creates a new downloader to fetch hashes and blocks from remote peers for
+// the chain running in the given context.
+func New(checkpoint uint64, stateDb ethdb.Database, stateBloom *trie.SyncBloom, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn, context int) *Downloader {
 	if lightchain == nil {
 		lightchain = chain
 	}
@@ -222,8 +224,9 @@ func New(checkpoint uint64, stateDb ethdb.Database, stateBloom *trie.SyncBloom,
 		stateDB:        stateDb,
 		stateBloom:     stateBloom,
 		mux:            mux,
+		context:        context,
 		checkpoint:     checkpoint,
-		queue:          newQueue(blockCacheMaxItems, blockCacheInitialItems),
+		queue:          newQueue(blockCacheMaxItems, blockCacheInitialItems, context),
 		peers:          newPeerSet(),
 		blockchain:     chain,
 		lightchain:     lightchain,
@@ -265,11 +268,11 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 	mode := d.getMode()
 	switch {
 	case d.blockchain != nil && mode == FullSync:
-		current = d.blockchain.CurrentBlock().NumberU64()
+		current = d.blockchain.CurrentBlock().NumberU64(d.context)
 	case d.blockchain != nil && mode == FastSync:
-		current = d.blockchain.CurrentFastBlock().NumberU64()
+		current = d.blockchain.CurrentFastBlock().NumberU64(d.context)
 	case d.lightchain != nil:
-		current = d.lightchain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
+		current = d.lightchain.CurrentHeader().Number[d.context].Uint64()
 	default:
 		log.Error("Unknown downloader chain/mode combo", "light", d.lightchain != nil, "full", d.blockchain != nil, "mode", mode)
 	}
@@ -483,7 +486,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td []*big
 		// nil panics on an access.
 		pivot = d.blockchain.CurrentBlock().Header()
 	}
-	height := latest.Number[types.QuaiNetworkContext].Uint64()
+	height := latest.Number[d.context].Uint64()
 
 	origin, err := d.findAncestor(p, latest)
 	if err != nil {
@@ -501,7 +504,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td []*big
 		if height <= uint64(fsMinFullBlocks) {
 			origin = 0
 		} else {
-			pivotNumber := pivot.Number[types.QuaiNetworkContext].Uint64()
+			pivotNumber := pivot.Number[d.context].Uint64()
 			if pivotNumber <= origin {
 				origin = pivotNumber - 1
 			}
@@ -511,7 +514,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td []*big
 		}
 	}
 	d.committed = 1
-	if mode == FastSync && pivot.Number[types.QuaiNetworkContext].Uint64() != 0 {
+	if mode == FastSync && pivot.Number[d.context].Uint64() != 0 {
 		d.committed = 0
 	}
 	if mode == FastSync {
@@ -529,10 +532,10 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td []*big
 		// The peer would start to feed us valid blocks until head, resulting in all of
 		// the blocks might be written into the ancient store. A following mini-reorg
 		// could cause issues.
-		if d.checkpoint != 0 && d.checkpoint > fullMaxForkAncestry+1 {
+		if d.checkpoint != 0 && d.checkpoint > fullMaxForkAncestry[d.context]+1 {
 			d.ancientLimit = d.checkpoint
-		} else if height > fullMaxForkAncestry+1 {
-			d.ancientLimit = height - fullMaxForkAncestry - 1
+		} else if height > fullMaxForkAncestry[d.context]+1 {
+			d.ancientLimit = height - fullMaxForkAncestry[d.context] - 1
 		} else {
 			d.ancientLimit = 0
 		}
@@ -684,11 +687,11 @@ func (d *Downloader) fetchHead(p *peerConnection) (head *types.Header, pivot *ty
 			// and request. If only 1 header was returned, make sure there's no pivot
 			// or there was not one requested.
 			head := headers[0]
-			if (mode == FastSync || mode == LightSync) && head.Number[types.QuaiNetworkContext].Uint64() < d.checkpoint {
+			if (mode == FastSync || mode == LightSync) && head.Number[d.context].Uint64() < d.checkpoint {
 				return nil, nil, fmt.Errorf("%w: remote head %d below checkpoint %d", errUnsyncedPeer, head.Number, d.checkpoint)
 			}
 			if len(headers) == 1 {
-				if mode == FastSync && head.Number[types.QuaiNetworkContext].Uint64() > uint64(fsMinFullBlocks) {
+				if mode == FastSync && head.Number[d.context].Uint64() > uint64(fsMinFullBlocks) {
 					return nil, nil, fmt.Errorf("%w: no pivot included along head header", errBadPeer)
 				}
 				p.log.Debug("Remote head identified, no pivot", "number", head.Number, "hash", head.Hash())
@@ -697,8 +700,8 @@ func (d *Downloader) fetchHead(p *peerConnection) (head *types.Header, pivot *ty
 			// At this point we have 2 headers in total and the first is the
 			// validated head of the chain. Check the pivot number and return,
 			pivot := headers[1]
-			if pivot.Number[types.QuaiNetworkContext].Uint64() != head.Number[types.QuaiNetworkContext].Uint64()-uint64(fsMinFullBlocks) {
-				return nil, nil, fmt.Errorf("%w: remote pivot %d != requested %d", errInvalidChain, pivot.Number, head.Number[types.QuaiNetworkContext].Uint64()-uint64(fsMinFullBlocks))
+			if pivot.Number[d.context].Uint64() != head.Number[d.context].Uint64()-uint64(fsMinFullBlocks) {
+				return nil, nil, fmt.Errorf("%w: remote pivot %d != requested %d", errInvalidChain, pivot.Number, head.Number[d.context].Uint64()-uint64(fsMinFullBlocks))
 			}
 			return head, pivot, nil
 
@@ -776,23 +779,23 @@ func (d *Downloader) findAncestor(p *peerConnection, remoteHeader *types.Header)
 	var (
 		floor        = int64(-1)
 		localHeight  uint64
-		remoteHeight = remoteHeader.Number[types.QuaiNetworkContext].Uint64()
+		remoteHeight = remoteHeader.Number[d.context].Uint64()
 	)
 	mode := d.getMode()
 	switch mode {
 	case FullSync:
-		localHeight = d.blockchain.CurrentBlock().NumberU64()
+		localHeight = d.blockchain.CurrentBlock().NumberU64(d.context)
 	case FastSync:
-		localHeight = d.blockchain.CurrentFastBlock().NumberU64()
+		localHeight = d.blockchain.CurrentFastBlock().NumberU64(d.context)
 	default:
-		localHeight = d.lightchain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
+		localHeight = d.lightchain.CurrentHeader().Number[d.context].Uint64()
 	}
 	p.log.Debug("Looking for common ancestor", "local", localHeight, "remote", remoteHeight)
 
 	// Recap floor value for binary search
-	maxForkAncestry := fullMaxForkAncestry
+	maxForkAncestry := fullMaxForkAncestry[d.context]
 	if d.getMode() == LightSync {
-		maxForkAncestry = lightMaxForkAncestry
+		maxForkAncestry = lightMaxForkAncestry[d.context]
 	}
 	if localHeight >= maxForkAncestry {
 		// We're above the max reorg threshold, find the earliest fork point
@@ -805,11 +808,11 @@ func (d *Downloader) findAncestor(p *peerConnection, remoteHeader *types.Header)
 		if d.genesis == 0 {
 			header := d.lightchain.CurrentHeader()
 			for header != nil {
-				d.genesis = header.Number[types.QuaiNetworkContext].Uint64()
+				d.genesis = header.Number[d.context].Uint64()
 				if floor >= int64(d.genesis)-1 {
 					break
 				}
-				header = d.lightchain.GetHeaderByHash(header.ParentHash[types.QuaiNetworkContext])
+				header = d.lightchain.GetHeaderByHash(header.ParentHash[d.context])
 			}
 		}
 		// We already know the "genesis" block number, cap floor to that
@@ -869,7 +872,7 @@ func (d *Downloader) findAncestorSpanSearch(p *peerConnection, mode SyncMode, re
 			// Make sure the peer's reply conforms to the request
 			for i, header := range headers {
 				expectNumber := from + int64(i)*int64(skip+1)
-				if number := header.Number[types.QuaiNetworkContext].Int64(); number != expectNumber {
+				if number := header.Number[d.context].Int64(); number != expectNumber {
 					p.log.Warn("Head headers broke chain ordering", "index", i, "requested", expectNumber, "received", number)
 					return 0, fmt.Errorf("%w: %v", errInvalidChain, errors.New("head headers broke chain ordering"))
 				}
@@ -878,12 +881,12 @@ func (d *Downloader) findAncestorSpanSearch(p *peerConnection, mode SyncMode, re
 			finished = true
 			for i := len(headers) - 1; i >= 0; i-- {
 				// Skip any headers that underflow/overflow our requested set
-				if headers[i].Number[types.QuaiNetworkContext].Int64() < from || headers[i].Number[types.QuaiNetworkContext].Uint64() > max {
+				if headers[i].Number[d.context].Int64() < from || headers[i].Number[d.context].Uint64() > max {
 					continue
 				}
 				// Otherwise check if we already know the header or not
 				h := headers[i].Hash()
-				n := headers[i].Number[types.QuaiNetworkContext].Uint64()
+				n := headers[i].Number[d.context].Uint64()
 
 				var known bool
 				switch mode {
@@ -963,7 +966,7 @@ func (d *Downloader) findAncestorBinarySearch(p *peerConnection, mode SyncMode,
 
 				// Modify the search interval based on the response
 				h := headers[0].Hash()
-				n := headers[0].Number[types.QuaiNetworkContext].Uint64()
+				n := headers[0].Number[d.context].Uint64()
 
 				var known bool
 				switch mode {
@@ -983,7 +986,7 @@ func (d *Downloader) findAncestorBinarySearch(p *peerConnection, mode SyncMode,
 				if header == nil {
 					break
 				}
-				if header.Number[types.QuaiNetworkContext].Uint64() != check {
+				if header.Number[d.context].Uint64() != check {
 					p.log.Warn("Received non requested header", "number", header.Number, "hash", header.Hash(), "request", check)
 					return 0, fmt.Errorf("%w: non-requested header (%d)", errBadPeer, header.Number)
 				}
@@ -1053,7 +1056,7 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 		timeout.Reset(ttl)
 
 		d.pivotLock.RLock()
-		pivot := d.pivotHeader.Number[types.QuaiNetworkContext].Uint64()
+		pivot := d.pivotHeader.Number[d.context].Uint64()
 		d.pivotLock.RUnlock()
 
 		p.log.Trace("Fetching next pivot header", "number", pivot+uint64(fsMinFullBlocks))
@@ -1083,7 +1086,7 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 
 			d.pivotLock.RLock()
 			if d.pivotHeader != nil {
-				pivot = d.pivotHeader.Number[types.QuaiNetworkContext].Uint64()
+				pivot = d.pivotHeader.Number[d.context].Uint64()
 			}
 			d.pivotLock.RUnlock()
 
@@ -1092,16 +1095,16 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 					// Retrieve the headers and do some sanity checks, just in case
 					headers := packet.(*headerPack).headers
 
-					if have, want := headers[0].Number[types.QuaiNetworkContext].Uint64(), pivot+uint64(fsMinFullBlocks); have != want {
+					if have, want := headers[0].Number[d.context].Uint64(), pivot+uint64(fsMinFullBlocks); have != want {
 						log.Warn("Peer sent invalid next pivot", "have", have, "want", want)
 						return fmt.Errorf("%w: next pivot number %d != requested %d", errInvalidChain, have, want)
 					}
-					if have, want := headers[1].Number[types.QuaiNetworkContext].Uint64(), pivot+2*uint64(fsMinFullBlocks)-8; have != want {
+					if have, want := headers[1].Number[d.context].Uint64(), pivot+2*uint64(fsMinFullBlocks)-8; have != want {
 						log.Warn("Peer sent invalid pivot confirmer", "have", have, "want", want)
 						return fmt.Errorf("%w: next pivot confirmer number %d != requested %d", errInvalidChain, have, want)
 					}
 					log.Warn("Pivot seemingly stale, moving", "old", pivot, "new", headers[0].Number)
-					pivot = headers[0].Number[types.QuaiNetworkContext].Uint64()
+					pivot = headers[0].Number[d.context].Uint64()
 
 					d.pivotLock.Lock()
 					d.pivotHeader = headers[0]
@@ -1163,10 +1166,10 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 					// Retrieve the current head we're at
 					var head uint64
 					if mode == LightSync {
-						head = d.lightchain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
+						head = d.lightchain.CurrentHeader().Number[d.context].Uint64()
 					} else {
-						head = d.blockchain.CurrentFastBlock().NumberU64()
-						if full := d.blockchain.CurrentBlock().NumberU64(); head < full {
+						head = d.blockchain.CurrentFastBlock().NumberU64(d.context)
+						if full := d.blockchain.CurrentBlock().NumberU64(d.context); head < full {
 							head = full
 						}
 					}
@@ -1177,7 +1180,7 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 						head = ancestor
 					}
 					// If the head is way older than this batch, delay the last few headers
-					if head+uint64(reorgProtThreshold) < headers[n-1].Number[types.QuaiNetworkContext].Uint64() {
+					if head+uint64(reorgProtThreshold) < headers[n-1].Number[d.context].Uint64() {
 						delay := reorgProtHeaderDelay
 						if delay > n {
 							delay = n
@@ -1566,8 +1569,8 @@ func (d *Downloader) processHeaders(origin uint64, td []*big.Int) error {
 		if rollback > 0 {
 			lastHeader, lastFastBlock, lastBlock := d.lightchain.CurrentHeader().Number, common.Big0, common.Big0
 			if mode != LightSync {
-				lastFastBlock = d.blockchain.CurrentFastBlock().Number()
-				lastBlock = d.blockchain.CurrentBlock().Number()
+				lastFastBlock = d.blockchain.CurrentFastBlock().Number(d.context)
+				lastBlock = d.blockchain.CurrentBlock().Number(d.context)
 			}
 			if err := d.lightchain.SetHead(rollback - 1); err != nil { // -1 to target the parent of the first uncertain block
 				// We're already unwinding the stack, only print the error to make it more visible
@@ -1575,8 +1578,8 @@ func (d *Downloader) processHeaders(origin uint64, td []*big.Int) error {
 			}
 			curFastBlock, curBlock := common.Big0, common.Big0
 			if mode != LightSync {
-				curFastBlock = d.blockchain.CurrentFastBlock().Number()
-				curBlock = d.blockchain.CurrentBlock().Number()
+				curFastBlock = d.blockchain.CurrentFastBlock().Number(d.context)
+				curBlock = d.blockchain.CurrentBlock().Number(d.context)
 			}
 			log.Warn("Rolled back chain segment",
 				"header", fmt.Sprintf("%d->%d", lastHeader, d.lightchain.CurrentHeader().Number),
@@ -1617,7 +1620,7 @@ func (d *Downloader) processHeaders(origin uint64, td []*big.Int) error {
 				// R: Nothing to give
 				if mode != LightSync {
 					head := d.blockchain.CurrentBlock()
-					if !gotHeaders && d.blockchain.HLCR(d.blockchain.GetTd(head.Hash(), head.NumberU64()), td) {
+					if !gotHeaders && d.blockchain.HLCR(d.blockchain.GetTd(head.Hash(), head.NumberU64(d.context)), td) {
 						return errStallingPeer
 					}
 				}
@@ -1630,7 +1633,7 @@ func (d *Downloader) processHeaders(origin uint64, td []*big.Int) error {
 				// peer gave us something useful, we're already happy/progressed (above check).
 				if mode == FastSync || mode == LightSync {
 					head := d.lightchain.CurrentHeader()
-					if d.blockchain.HLCR(d.lightchain.GetTd(head.Hash(), head.Number[types.QuaiNetworkContext].Uint64()), td) {
+					if d.blockchain.HLCR(d.lightchain.GetTd(head.Hash(), head.Number[d.context].Uint64()), td) {
 						return errStallingPeer
 					}
 				}
@@ -1662,12 +1665,12 @@ func (d *Downloader) processHeaders(origin uint64, td []*big.Int) error {
 
 					d.pivotLock.RLock()
 					if d.pivotHeader != nil {
-						pivot = d.pivotHeader.Number[types.QuaiNetworkContext].Uint64()
+						pivot = d.pivotHeader.Number[d.context].Uint64()
 					}
 					d.pivotLock.RUnlock()
 
 					frequency := fsHeaderCheckFrequency
-					if chunk[len(chunk)-1].Number[types.QuaiNetworkContext].Uint64()+uint64(fsHeaderForceVerify) > pivot {
+					if chunk[len(chunk)-1].Number[d.context].Uint64()+uint64(fsHeaderForceVerify) > pivot {
 						frequency = 1
 					}
 					if n, err := d.lightchain.InsertHeaderChain(chunk, frequency); err != nil {
@@ -1675,14 +1678,14 @@ func (d *Downloader) processHeaders(origin uint64, td []*big.Int) error {
 
 						// If some headers were inserted, track them as uncertain
 						if (mode == FastSync || frequency > 1) && n > 0 && rollback == 0 {
-							rollback = chunk[0].Number[types.QuaiNetworkContext].Uint64()
+							rollback = chunk[0].Number[d.context].Uint64()
 						}
 						log.Warn("Invalid header encountered", "number", chunk[n].Number, "hash", chunk[n].Hash(), "parent", chunk[n].ParentHash, "err", err)
 						return fmt.Errorf("%w: %v", errInvalidChain, err)
 					}
 					// All verifications passed, track all headers within the alloted limits
 					if mode == FastSync {
-						head := chunk[len(chunk)-1].Number[types.QuaiNetworkContext].Uint64()
+						head := chunk[len(chunk)-1].Number[d.context].Uint64()
 						if head-rollback > uint64(fsHeaderSafetyNet) {
 							rollback = head - uint64(fsHeaderSafetyNet)
 						} else {
@@ -1796,7 +1799,7 @@ func (d *Downloader) processFastSyncContent() error {
 	// Start syncing state of the reported head block. This should get us most of
 	// the state of the pivot block.
 	d.pivotLock.RLock()
-	sync := d.syncState(d.pivotHeader.Root[types.QuaiNetworkContext])
+	sync := d.syncState(d.pivotHeader.Root[d.context])
 	d.pivotLock.RUnlock()
 
 	defer func() {
@@ -1846,9 +1849,9 @@ func (d *Downloader) processFastSyncContent() error {
 		d.pivotLock.RUnlock()
 
 		if oldPivot == nil {
-			if pivot.Root[types.QuaiNetworkContext] != sync.root {
+			if pivot.Root[d.context] != sync.root {
 				sync.Cancel()
-				sync = d.syncState(pivot.Root[types.QuaiNetworkContext])
+				sync = d.syncState(pivot.Root[d.context])
 
 				go closeOnErr(sync)
 			}
@@ -1865,8 +1868,8 @@ func (d *Downloader) processFastSyncContent() error {
 			// Note, we have `reorgProtHeaderDelay` number of blocks withheld, Those
 			// need to be taken into account, otherwise we're detecting the pivot move
 			// late and will drop peers due to unavailable state!!!
-			if height := latest.Number[types.QuaiNetworkContext].Uint64(); height >= pivot.Number[types.QuaiNetworkContext].Uint64()+2*uint64(fsMinFullBlocks)-uint64(reorgProtHeaderDelay) {
-				log.Warn("Pivot became stale, moving", "old", pivot.Number[types.QuaiNetworkContext].Uint64(), "new", height-uint64(fsMinFullBlocks)+uint64(reorgProtHeaderDelay))
+			if height := latest.Number[d.context].Uint64(); height >= pivot.Number[d.context].Uint64()+2*uint64(fsMinFullBlocks)-uint64(reorgProtHeaderDelay) {
+				log.Warn("Pivot became stale, moving", "old", pivot.Number[d.context].Uint64(), "new", height-uint64(fsMinFullBlocks)+uint64(reorgProtHeaderDelay))
 				pivot = results[len(results)-1-fsMinFullBlocks+reorgProtHeaderDelay].Header // must exist as lower old pivot is uncommitted
 
 				d.pivotLock.Lock()
@@ -1875,10 +1878,10 @@ func (d *Downloader) processFastSyncContent() error {
 
 				// Write out the pivot into the database so a rollback beyond it will
 				// reenable fast sync
-				rawdb.WriteLastPivotNumber(d.stateDB, pivot.Number[types.QuaiNetworkContext].Uint64())
+				rawdb.WriteLastPivotNumber(d.stateDB, pivot.Number[d.context].Uint64())
 			}
 		}
-		P, beforeP, afterP := splitAroundPivot(pivot.Number[types.QuaiNetworkContext].Uint64(), results)
+		P, beforeP, afterP := splitAroundPivot(pivot.Number[d.context].Uint64(), results, d.context)
 		if err := d.commitFastSyncData(beforeP, sync); err != nil {
 			return err
 		}
@@ -1886,7 +1889,7 @@ func (d *Downloader) processFastSyncContent() error {
 			// If new pivot block found, cancel old state retrieval and restart
 			if oldPivot != P {
 				sync.Cancel()
-				sync = d.syncState(P.Header.Root[types.QuaiNetworkContext])
+				sync = d.syncState(P.Header.Root[d.context])
 
 				go closeOnErr(sync)
 				oldPivot = P
@@ -1914,17 +1917,17 @@ func (d *Downloader) processFastSyncContent() error {
 	}
 }
 
-func splitAroundPivot(pivot uint64, results []*fetchResult) (p *fetchResult, before, after []*fetchResult) {
+func splitAroundPivot(pivot uint64, results []*fetchResult, context int) (p *fetchResult, before, after []*fetchResult) {
 	if len(results) == 0 {
 		return nil, nil, nil
 	}
-	if lastNum := results[len(results)-1].Header.Number[types.QuaiNetworkContext].Uint64(); lastNum < pivot {
+	if lastNum := results[len(results)-1].Header.Number[context].Uint64(); lastNum < pivot {
 		// the pivot is somewhere in the future
 		return nil, results, nil
 	}
 	// This can also be optimized, but only happens very seldom
 	for _, result := range results {
-		num := result.Header.Number[types.QuaiNetworkContext].Uint64()
+		num := result.Header.Number[context].Uint64()
 		switch {
 		case num < pivot:
 			before = append(before, result)
@@ -1972,7 +1975,7 @@ func (d *Downloader) commitFastSyncData(results []*fetchResult, stateSync *state
 
 func (d *Downloader) commitPivotBlock(result *fetchResult) error {
 	block := types.NewBlockWithHeader(result.Header).WithBody(result.Transactions, result.Uncles)
-	log.Debug("Committing fast sync pivot as new head", "number", block.Number(), "hash", block.Hash())
+	log.Debug("Committing fast sync pivot as new head", "number", block.Number(d.context), "hash", block.Hash())
 
 	// Commit the pivot block as the new head, will require full sync from here on
 	if _, err := d.blockchain.InsertReceiptChain([]*types.Block{block}, []types.Receipts{result.Receipts}, d.ancientLimit); err != nil {
@@ -458,7 +458,7 @@ func (ps *peerSet) HeaderIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.BlockHeadersMsg, time.Second)
 	}
-	return ps.idlePeers(eth.QUAI66, eth.QUAI66, idle, throughput)
+	return ps.idlePeers(eth.QUAI66, eth.QUAI67, idle, throughput)
 }
 
 // BodyIdlePeers retrieves a flat list of all the currently body-idle peers within
@@ -470,7 +470,7 @@ func (ps *peerSet) BodyIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.BlockBodiesMsg, time.Second)
 	}
-	return ps.idlePeers(eth.QUAI66, eth.QUAI66, idle, throughput)
+	return ps.idlePeers(eth.QUAI66, eth.QUAI67, idle, throughput)
 }
 
 // ReceiptIdlePeers retrieves a flat list of all the currently receipt-idle peers
@@ -482,7 +482,7 @@ func (ps *peerSet) ReceiptIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.ReceiptsMsg, time.Second)
 	}
-	return ps.idlePeers(eth.QUAI66, eth.QUAI66, idle, throughput)
+	return ps.idlePeers(eth.QUAI66, eth.QUAI67, idle, throughput)
 }
 
 // ExtBlockIdlePeers retrieves a flat list of all the currently external block idle peers
@@ -494,7 +494,7 @@ func (ps *peerSet) ExtBlockIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.ExtBlocksMsg, time.Second)
 	}
-	return ps.idlePeers(eth.QUAI66, eth.QUAI66, idle, throughput)
+	return ps.idlePeers(eth.QUAI66, eth.QUAI67, idle, throughput)
 }
 
 // NodeDataIdlePeers retrieves a flat list of all the currently node-data-idle
@@ -506,7 +506,7 @@ func (ps *peerSet) NodeDataIdlePeers() ([]*peerConnection, int) {
 	throughput := func(p *peerConnection) int {
 		return p.rates.Capacity(eth.NodeDataMsg, time.Second)
 	}
-	return ps.idlePeers(eth.QUAI66, eth.QUAI66, idle, throughput)
+	return ps.idlePeers(eth.QUAI66, eth.QUAI67, idle, throughput)
 }
 
 // idlePeers retrieves a flat list of all currently idle peers satisfying the
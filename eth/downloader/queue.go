@@ -72,14 +72,14 @@ type fetchResult struct {
 	ExternalBlocks []*types.ExternalBlock
 }
 
-func newFetchResult(header *types.Header, fastSync bool) *fetchResult {
+func newFetchResult(header *types.Header, fastSync bool, context int) *fetchResult {
 	item := &fetchResult{
 		Header: header,
 	}
-	if !header.EmptyBody() {
+	if !header.EmptyBody(context) {
 		item.pending |= (1 << bodyType)
 	}
-	if fastSync && !header.EmptyReceipts() {
+	if fastSync && !header.EmptyReceipts(context) {
 		item.pending |= (1 << receiptType)
 	}
 	item.pending |= (1 << externalBlockType)
@@ -121,7 +121,8 @@ func (f *fetchResult) Done(kind uint) bool {
 
 // queue represents hashes that are either need fetching or are being fetched
 type queue struct {
-	mode SyncMode // Synchronisation mode to decide on the block parts to schedule for fetching
+	mode    SyncMode // Synchronisation mode to decide on the block parts to schedule for fetching
+	context int      // Context of the chain whose blocks are scheduled
 
 	// Headers are "special", they download in batches, supported by a skeleton chain
 	headerHead      common.Hash                    // Hash of the last queued header to verify order
@@ -157,10 +158,12 @@ type queue struct {
 	lastStatLog time.Time
 }
 
-// newQueue creates a new download queue for scheduling block retrieval.
-func newQueue(blockCacheLimit int, thresholdInitialSize int) *queue {
+// newQueue creates a new download queue for scheduling block retrieval of the
+// chain running in the given context.
+func newQueue(blockCacheLimit int, thresholdInitialSize int, context int) *queue {
 	lock := new(sync.RWMutex)
 	q := &queue{
+		context:                context,
 		headerContCh:           make(chan bool),
 		blockTaskQueue:         prque.New(nil),
 		receiptTaskQueue:       prque.New(nil),
@@ -195,7 +198,7 @@ func (q *queue) Reset(blockCacheLimit int, thresholdInitialSize int) {
 	q.externalBlockTaskQueue.Reset()
 	q.externalBlockPendPool = make(map[string]*fetchRequest)
 
-	q.resultCache = newResultStore(blockCacheLimit)
+	q.resultCache = newResultStore(blockCacheLimit, q.context)
 	q.resultCache.SetThrottleThreshold(uint64(thresholdInitialSize))
 }
 
@@ -341,11 +344,11 @@ func (q *queue) Schedule(headers []*types.Header, from uint64) []*types.Header {
 			log.Warn("Header nil", "expected", from)
 			break
 		}
-		if header.Number == nil || header.Number[types.QuaiNetworkContext].Uint64() != from {
+		if header.Number == nil || header.Number[q.context].Uint64() != from {
 			log.Warn("Header broke chain ordering", "number", header.Number, "hash", hash, "expected", from)
 			break
 		}
-		if q.headerHead != (common.Hash{}) && q.headerHead != header.ParentHash[types.QuaiNetworkContext] {
+		if q.headerHead != (common.Hash{}) && q.headerHead != header.ParentHash[q.context] {
 			log.Warn("Header broke chain ancestry", "number", header.Number, "hash", hash)
 			break
 		}
@@ -356,15 +359,15 @@ func (q *queue) Schedule(headers []*types.Header, from uint64) []*types.Header {
 			log.Warn("Header already scheduled for block fetch", "number", header.Number, "hash", hash)
 		} else {
 			q.blockTaskPool[hash] = header
-			q.blockTaskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+			q.blockTaskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 		}
 		// Queue for receipt retrieval
-		if q.mode == FastSync && !header.EmptyReceipts() {
+		if q.mode == FastSync && !header.EmptyReceipts(q.context) {
 			if _, ok := q.receiptTaskPool[hash]; ok {
 				log.Warn("Header already scheduled for receipt fetch", "number", header.Number, "hash", hash)
 			} else {
 				q.receiptTaskPool[hash] = header
-				q.receiptTaskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+				q.receiptTaskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 			}
 		}
 		// Queue for external block retrieval
@@ -372,7 +375,7 @@ func (q *queue) Schedule(headers []*types.Header, from uint64) []*types.Header {
 			log.Warn("Header already scheduled for external block fetch", "number", header.Number, "hash", hash)
 		} else {
 			q.externalBlockTaskPool[hash] = header
-			q.externalBlockTaskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+			q.externalBlockTaskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 		}
 		inserts = append(inserts, header)
 		q.headerHead = hash
@@ -570,7 +573,7 @@ func (q *queue) reserveHeaders(p *peerConnection, count int, taskPool map[common
 			progress = true
 			delete(taskPool, header.Hash())
 			proc = proc - 1
-			log.Error("Fetch reservation already delivered", "number", header.Number[types.QuaiNetworkContext].Uint64())
+			log.Error("Fetch reservation already delivered", "number", header.Number[q.context].Uint64())
 			continue
 		}
 		if throttle {
@@ -606,7 +609,7 @@ func (q *queue) reserveHeaders(p *peerConnection, count int, taskPool map[common
 	}
 	// Merge all the skipped headers back
 	for _, header := range skip {
-		taskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+		taskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 	}
 	if q.resultCache.HasCompletedItems() {
 		// Wake Results, resultCache was modified
@@ -662,7 +665,7 @@ func (q *queue) cancel(request *fetchRequest, taskQueue *prque.Prque, pendPool m
 		taskQueue.Push(request.From, -int64(request.From))
 	}
 	for _, header := range request.Headers {
-		taskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+		taskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 	}
 	delete(pendPool, request.Peer.id)
 }
@@ -676,19 +679,19 @@ func (q *queue) Revoke(peerID string) {
 
 	if request, ok := q.blockPendPool[peerID]; ok {
 		for _, header := range request.Headers {
-			q.blockTaskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+			q.blockTaskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 		}
 		delete(q.blockPendPool, peerID)
 	}
 	if request, ok := q.receiptPendPool[peerID]; ok {
 		for _, header := range request.Headers {
-			q.receiptTaskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+			q.receiptTaskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 		}
 		delete(q.receiptPendPool, peerID)
 	}
 	if request, ok := q.externalBlockPendPool[peerID]; ok {
 		for _, header := range request.Headers {
-			q.externalBlockTaskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+			q.externalBlockTaskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 		}
 		delete(q.externalBlockPendPool, peerID)
 	}
@@ -749,7 +752,7 @@ func (q *queue) expire(timeout time.Duration, pendPool map[string]*fetchRequest,
 				taskQueue.Push(request.From, -int64(request.From))
 			}
 			for _, header := range request.Headers {
-				taskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+				taskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 			}
 			// Add the peer to the expiry report along the number of failed requests
 			expiries[id] = len(request.Headers)
@@ -792,7 +795,7 @@ func (q *queue) DeliverHeaders(id string, headers []*types.Header, headerProcCh
 
 	accepted := len(headers) == MaxHeaderFetch
 	if accepted {
-		if headers[0].Number[types.QuaiNetworkContext].Uint64() != request.From {
+		if headers[0].Number[q.context].Uint64() != request.From {
 			logger.Trace("First header broke chain ordering", "number", headers[0].Number, "hash", headers[0].Hash(), "expected", request.From)
 			accepted = false
 		} else if headers[len(headers)-1].Hash() != target {
@@ -804,12 +807,12 @@ func (q *queue) DeliverHeaders(id string, headers []*types.Header, headerProcCh
 		parentHash := headers[0].Hash()
 		for i, header := range headers[1:] {
 			hash := header.Hash()
-			if want := request.From + 1 + uint64(i); header.Number[types.QuaiNetworkContext].Uint64() != want {
+			if want := request.From + 1 + uint64(i); header.Number[q.context].Uint64() != want {
 				logger.Warn("Header broke chain ordering", "number", header.Number, "hash", hash, "expected", want)
 				accepted = false
 				break
 			}
-			if parentHash != header.ParentHash[types.QuaiNetworkContext] {
+			if parentHash != header.ParentHash[q.context] {
 				logger.Warn("Header broke chain ancestry", "number", header.Number, "hash", hash)
 				accepted = false
 				break
@@ -867,10 +870,10 @@ func (q *queue) DeliverBodies(id string, txLists [][]*types.Transaction, uncleLi
 	defer q.lock.Unlock()
 	trieHasher := trie.NewStackTrie(nil)
 	validate := func(index int, header *types.Header) error {
-		if types.DeriveSha(types.Transactions(txLists[index]), trieHasher) != header.TxHash[types.QuaiNetworkContext] {
+		if types.DeriveSha(types.Transactions(txLists[index]), trieHasher) != header.TxHash[q.context] {
 			return errInvalidBody
 		}
-		if types.CalcUncleHash(uncleLists[index]) != header.UncleHash[types.QuaiNetworkContext] {
+		if types.CalcUncleHash(uncleLists[index]) != header.UncleHash[q.context] {
 			return errInvalidBody
 		}
 		return nil
@@ -893,7 +896,7 @@ func (q *queue) DeliverReceipts(id string, receiptList [][]*types.Receipt) (int,
 	defer q.lock.Unlock()
 	trieHasher := trie.NewStackTrie(nil)
 	validate := func(index int, header *types.Header) error {
-		if types.DeriveSha(types.Receipts(receiptList[index]), trieHasher) != header.ReceiptHash[types.QuaiNetworkContext] {
+		if types.DeriveSha(types.Receipts(receiptList[index]), trieHasher) != header.ReceiptHash[q.context] {
 			return errInvalidReceipt
 		}
 		return nil
@@ -967,13 +970,13 @@ func (q *queue) deliver(id string, taskPool map[common.Hash]*types.Header,
 	}
 
 	for _, header := range request.Headers[:i] {
-		if res, stale, err := q.resultCache.GetDeliverySlot(header.Number[types.QuaiNetworkContext].Uint64()); err == nil {
+		if res, stale, err := q.resultCache.GetDeliverySlot(header.Number[q.context].Uint64()); err == nil {
 			reconstruct(accepted, res)
 		} else {
 			// else: betweeen here and above, some other peer filled this result,
 			// or it was indeed a no-op. This should not happen, but if it does it's
 			// not something to panic about
-			log.Error("Delivery stale", "stale", stale, "number", header.Number[types.QuaiNetworkContext].Uint64(), "err", err)
+			log.Error("Delivery stale", "stale", stale, "number", header.Number[q.context].Uint64(), "err", err)
 			failure = errStaleDelivery
 		}
 		// Clean up a successful fetch
@@ -982,7 +985,7 @@ func (q *queue) deliver(id string, taskPool map[common.Hash]*types.Header,
 	}
 	// Return all failed or missing fetches to the queue
 	for _, header := range request.Headers[accepted:] {
-		taskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
+		taskQueue.Push(header, -int64(header.Number[q.context].Uint64()))
 	}
 	// Wake up Results
 	if accepted > 0 {
@@ -100,7 +100,7 @@ func TestBasics(t *testing.T) {
 	numOfBlocks := len(emptyChain.blocks)
 	numOfReceipts := len(emptyChain.blocks) / 2
 
-	q := newQueue(10, 10)
+	q := newQueue(10, 10, types.QuaiNetworkContext)
 	if !q.Idle() {
 		t.Errorf("new queue should be idle")
 	}
@@ -195,7 +195,7 @@ func TestBasics(t *testing.T) {
 func TestEmptyBlocks(t *testing.T) {
 	numOfBlocks := len(emptyChain.blocks)
 
-	q := newQueue(10, 10)
+	q := newQueue(10, 10, types.QuaiNetworkContext)
 
 	q.Prepare(1, FastSync)
 	// Schedule a batch of headers
@@ -270,7 +270,7 @@ func XTestDelivery(t *testing.T) {
 		log.Root().SetHandler(log.StdoutHandler)
 
 	}
-	q := newQueue(10, 10)
+	q := newQueue(10, 10, types.QuaiNetworkContext)
 	var wg sync.WaitGroup
 	q.Prepare(1, FastSync)
 	wg.Add(1)
@@ -29,6 +29,7 @@ import (
 type resultStore struct {
 	items        []*fetchResult // Downloaded but not yet delivered fetch results
 	resultOffset uint64         // Offset of the first cached fetch result in the block chain
+	context      int            // Context in which the results are numbered
 
 	// Internal index of first non-completed entry, updated atomically when needed.
 	// If all items are complete, this will equal length(items), so
@@ -45,9 +46,10 @@ type resultStore struct {
 	lock sync.RWMutex
 }
 
-func newResultStore(size int) *resultStore {
+func newResultStore(size int, context int) *resultStore {
 	return &resultStore{
 		resultOffset:      0,
+		context:           context,
 		items:             make([]*fetchResult, size),
 		throttleThreshold: uint64(size),
 	}
@@ -80,12 +82,12 @@ func (r *resultStore) AddFetch(header *types.Header, fastSync bool) (stale, thro
 	defer r.lock.Unlock()
 
 	var index int
-	item, index, stale, throttled, err = r.getFetchResult(header.Number[types.QuaiNetworkContext].Uint64())
+	item, index, stale, throttled, err = r.getFetchResult(header.Number[r.context].Uint64())
 	if err != nil || stale || throttled {
 		return stale, throttled, item, err
 	}
 	if item == nil {
-		item = newFetchResult(header, fastSync)
+		item = newFetchResult(header, fastSync, r.context)
 		r.items[index] = item
 	}
 	return stale, throttled, item, err
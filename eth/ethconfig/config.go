@@ -57,20 +57,20 @@ var LightClientGPO = gasprice.Config{
 
 // Defaults contains default settings for use on the Quai Network Prime main net.
 var Defaults = Config{
-	SyncMode:                   downloader.SnapSync,
-	Blake3:                     blake3.Config{},
-	NetworkId:                  9000,
-	TxLookupLimit:              2350000,
-	LightPeers:                 100,
-	UltraLightFraction:         75,
-	DatabaseCache:              512,
-	TrieCleanCache:             154,
-	TrieCleanCacheJournal:      "triecache",
-	TrieCleanCacheRejournal:    60 * time.Minute,
-	TrieDirtyCache:             256,
-	TrieTimeout:                60 * time.Minute,
-	ExternalBlockCache:         256,
-	ExternalBlocksCacheJournal: "externalblocks",
+	SyncMode:                downloader.SnapSync,
+	Blake3:                  blake3.Config{},
+	NetworkId:               9000,
+	TxLookupLimit:           2350000,
+	LightPeers:              100,
+	UltraLightFraction:      75,
+	DatabaseCache:           512,
+	TrieCleanCache:          154,
+	TrieCleanCacheJournal:   "triecache",
+	TrieCleanCacheRejournal: 60 * time.Minute,
+	TrieDirtyCache:          256,
+	TrieTimeout:             60 * time.Minute,
+	ExternalBlockCache:      256,
+	ExternalBlockRetention:  90000,
 
 	SnapshotCache: 102,
 	Miner: miner.Config{
@@ -84,8 +84,8 @@ var Defaults = Config{
 	RPCTxFeeCap: 1, // 1 ether
 	Region:      0,
 	Zone:        0,
-	DomUrl:      "ws://127.0.0.1:8546",
-	SubUrls:     []string{"ws://127.0.0.1:8546", "ws://127.0.0.1:8546", "ws://127.0.0.1:8546"},
+	DomUrl:      "ws://127.0.0.1:8551",
+	SubUrls:     []string{"ws://127.0.0.1:8551", "ws://127.0.0.1:8551", "ws://127.0.0.1:8551"},
 }
 
 func init() {
@@ -113,6 +113,12 @@ type Config struct {
 
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
+	FinalityDepth uint64 `toml:",omitempty"` // Number of blocks after which the fork choice never reorgs them, 0 for none
+
+	// Deep PCRC verification options
+	PCRCDepth      uint64      `toml:",omitempty"` // Number of prime termini PCRC is re-applied back to, 0 for a single check
+	PCRCCheckpoint common.Hash `toml:",omitempty"` // Trusted prime terminus deep PCRC verification stops at
+
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
 
@@ -144,9 +150,9 @@ type Config struct {
 	SnapshotCache           int
 	Preimages               bool
 
-	// External Block cache options
-	ExternalBlockCache         int
-	ExternalBlocksCacheJournal string `toml:",omitempty"` // Disk journal directory for external blocks to survive node restarts
+	// External block store options
+	ExternalBlockCache     int
+	ExternalBlockRetention uint64 `toml:",omitempty"` // Number of blocks below the head external blocks are kept for, 0 to keep them forever
 
 	// Mining options
 	Miner miner.Config
@@ -202,8 +208,12 @@ func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, co
 		return clique.New(chainConfig.Clique, db)
 	}
 	// Otherwise assume proof-of-work
+	if config.Fakepow {
+		return blake3.NewContextFaker(chainConfig.Context)
+	}
 	engine, err := blake3.New(blake3.Config{
 		NotifyFull: config.NotifyFull,
+		Context:    chainConfig.Context,
 	}, notify, noverify)
 	if nil != err {
 		log.Fatal(err)
@@ -26,6 +26,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		NoPruning               bool
 		NoPrefetch              bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
+		FinalityDepth           uint64                 `toml:",omitempty"`
+		PCRCDepth               uint64                 `toml:",omitempty"`
+		PCRCCheckpoint          common.Hash            `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
 		LightServ               int                    `toml:",omitempty"`
 		LightIngress            int                    `toml:",omitempty"`
@@ -48,6 +51,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		TrieTimeout             time.Duration
 		SnapshotCache           int
 		Preimages               bool
+		ExternalBlockRetention  uint64 `toml:",omitempty"`
 		Miner                   miner.Config
 		Blake3                  blake3.Config
 		TxPool                  core.TxPoolConfig
@@ -69,6 +73,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
+	enc.FinalityDepth = c.FinalityDepth
+	enc.PCRCDepth = c.PCRCDepth
+	enc.PCRCCheckpoint = c.PCRCCheckpoint
 	enc.Whitelist = c.Whitelist
 	enc.LightServ = c.LightServ
 	enc.LightIngress = c.LightIngress
@@ -91,6 +98,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.TrieTimeout = c.TrieTimeout
 	enc.SnapshotCache = c.SnapshotCache
 	enc.Preimages = c.Preimages
+	enc.ExternalBlockRetention = c.ExternalBlockRetention
 	enc.Miner = c.Miner
 	enc.Blake3 = c.Blake3
 	enc.TxPool = c.TxPool
@@ -116,6 +124,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		NoPruning               *bool
 		NoPrefetch              *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
+		FinalityDepth           *uint64                `toml:",omitempty"`
+		PCRCDepth               *uint64                `toml:",omitempty"`
+		PCRCCheckpoint          *common.Hash           `toml:",omitempty"`
 		Whitelist               map[uint64]common.Hash `toml:"-"`
 		LightServ               *int                   `toml:",omitempty"`
 		LightIngress            *int                   `toml:",omitempty"`
@@ -138,6 +149,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		TrieTimeout             *time.Duration
 		SnapshotCache           *int
 		Preimages               *bool
+		ExternalBlockRetention  *uint64 `toml:",omitempty"`
 		Miner                   *miner.Config
 		Blake3                  *blake3.Config
 		TxPool                  *core.TxPoolConfig
@@ -178,6 +190,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TxLookupLimit != nil {
 		c.TxLookupLimit = *dec.TxLookupLimit
 	}
+	if dec.FinalityDepth != nil {
+		c.FinalityDepth = *dec.FinalityDepth
+	}
+	if dec.PCRCDepth != nil {
+		c.PCRCDepth = *dec.PCRCDepth
+	}
+	if dec.PCRCCheckpoint != nil {
+		c.PCRCCheckpoint = *dec.PCRCCheckpoint
+	}
 	if dec.Whitelist != nil {
 		c.Whitelist = dec.Whitelist
 	}
@@ -244,6 +265,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.Preimages != nil {
 		c.Preimages = *dec.Preimages
 	}
+	if dec.ExternalBlockRetention != nil {
+		c.ExternalBlockRetention = *dec.ExternalBlockRetention
+	}
 	if dec.Miner != nil {
 		c.Miner = *dec.Miner
 	}
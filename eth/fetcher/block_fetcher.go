@@ -157,12 +157,12 @@ type blockOrHeaderInject struct {
 	extBlocks []*types.ExternalBlock // UIsed for normal mode fetcher which imports
 }
 
-// number returns the block number of the injected object.
-func (inject *blockOrHeaderInject) number() uint64 {
+// number returns the block number of the injected object in the given context.
+func (inject *blockOrHeaderInject) number(context int) uint64 {
 	if inject.header != nil {
-		return inject.header.Number[types.QuaiNetworkContext].Uint64()
+		return inject.header.Number[context].Uint64()
 	}
-	return inject.block.NumberU64()
+	return inject.block.NumberU64(context)
 }
 
 // number returns the block hash of the injected object.
@@ -176,7 +176,8 @@ func (inject *blockOrHeaderInject) hash() common.Hash {
 // BlockFetcher is responsible for accumulating block announcements from various peers
 // and scheduling them for retrieval.
 type BlockFetcher struct {
-	light bool // The indicator whether it's a light fetcher or normal one.
+	light   bool // The indicator whether it's a light fetcher or normal one.
+	context int  // Context of the chain the fetched blocks are imported into
 
 	// Various event channels
 	notify chan *blockAnnounce
@@ -222,9 +223,10 @@ type BlockFetcher struct {
 }
 
 // NewBlockFetcher creates a block fetcher to retrieve blocks based on hash announcements.
-func NewBlockFetcher(light bool, getHeader HeaderRetrievalFn, getBlock blockRetrievalFn, verifyHeader headerVerifierFn, broadcastBlock blockBroadcasterFn, chainHeight chainHeightFn, insertHeaders headersInsertFn, insertChain chainInsertFn, dropPeer peerDropFn, getExtBlocks extBlockRetrievalFn, addExtBlocks addExtBlockFn) *BlockFetcher {
+func NewBlockFetcher(light bool, getHeader HeaderRetrievalFn, getBlock blockRetrievalFn, verifyHeader headerVerifierFn, broadcastBlock blockBroadcasterFn, chainHeight chainHeightFn, insertHeaders headersInsertFn, insertChain chainInsertFn, dropPeer peerDropFn, getExtBlocks extBlockRetrievalFn, addExtBlocks addExtBlockFn, context int) *BlockFetcher {
 	return &BlockFetcher{
 		light:          light,
+		context:        context,
 		notify:         make(chan *blockAnnounce),
 		inject:         make(chan *blockOrHeaderInject),
 		headerFilter:   make(chan chan *headerFilterTask),
@@ -414,7 +416,7 @@ func (f *BlockFetcher) loop() {
 				f.queueChangeHook(hash, false)
 			}
 			// If too high up the chain or phase, continue later
-			number := op.number()
+			number := op.number(f.context)
 			if number > height+1 {
 				f.queue.Push(op, -int64(number))
 				if f.queueChangeHook != nil {
@@ -599,7 +601,7 @@ func (f *BlockFetcher) loop() {
 				// Filter fetcher-requested headers from other synchronisation algorithms
 				if announce := f.fetching[hash]; announce != nil && announce.origin == task.peer && f.fetched[hash] == nil && f.completing[hash] == nil && f.queued[hash] == nil {
 					// If the delivered header does not match the promised number, drop the announcer
-					if header.Number[types.QuaiNetworkContext].Uint64() != announce.number {
+					if header.Number[f.context].Uint64() != announce.number {
 						log.Trace("Invalid block number fetched", "peer", announce.origin, "hash", header.Hash(), "announced", announce.number, "provided", header.Number)
 						f.dropPeer(announce.origin)
 						f.forgetHash(hash)
@@ -621,7 +623,7 @@ func (f *BlockFetcher) loop() {
 						announce.time = task.time
 
 						// If the block is empty (header only), short circuit into the final import queue
-						if header.TxHash[types.QuaiNetworkContext] == types.EmptyRootHash[types.QuaiNetworkContext] && header.UncleHash[types.QuaiNetworkContext] == types.EmptyUncleHash[types.QuaiNetworkContext] {
+						if header.TxHash[f.context] == types.EmptyRootHash[f.context] && header.UncleHash[f.context] == types.EmptyUncleHash[f.context] {
 							log.Trace("Block empty, skipping body retrieval", "peer", announce.origin, "number", header.Number, "hash", header.Hash())
 
 							block := types.NewBlockWithHeader(header)
@@ -704,13 +706,13 @@ func (f *BlockFetcher) loop() {
 						if uncleHash == (common.Hash{}) {
 							uncleHash = types.CalcUncleHash(task.uncles[i])
 						}
-						if uncleHash != announce.header.UncleHash[types.QuaiNetworkContext] {
+						if uncleHash != announce.header.UncleHash[f.context] {
 							continue
 						}
 						if txnHash == (common.Hash{}) {
 							txnHash = types.DeriveSha(types.Transactions(task.transactions[i]), trie.NewStackTrie(nil))
 						}
-						if txnHash != announce.header.TxHash[types.QuaiNetworkContext] {
+						if txnHash != announce.header.TxHash[f.context] {
 							continue
 						}
 						// Mark the body matched, reassemble if still unknown
@@ -841,9 +843,9 @@ func (f *BlockFetcher) enqueue(peer string, header *types.Header, block *types.B
 		number uint64
 	)
 	if header != nil {
-		hash, number = header.Hash(), header.Number[types.QuaiNetworkContext].Uint64()
+		hash, number = header.Hash(), header.Number[f.context].Uint64()
 	} else {
-		hash, number = block.Hash(), block.NumberU64()
+		hash, number = block.Hash(), block.NumberU64(f.context)
 	}
 	// Ensure the peer isn't DOSing us
 	count := f.queues[peer] + 1
@@ -888,7 +890,7 @@ func (f *BlockFetcher) importHeaders(peer string, header *types.Header) {
 	go func() {
 		defer func() { f.done <- hash }()
 		// If the parent's unknown, abort insertion
-		parent := f.getHeader(header.ParentHash[types.QuaiNetworkContext])
+		parent := f.getHeader(header.ParentHash[f.context])
 		if parent == nil {
 			log.Debug("Unknown parent of propagated header", "peer", peer, "number", header.Number, "hash", hash, "parent", header.ParentHash)
 			return
@@ -918,14 +920,14 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block, extBlocks [
 	hash := block.Hash()
 
 	// Run the import on a new thread
-	log.Info("Importing propagated block", "peer", peer, "number", block.Number(), "hash", hash, "extBlocks", extBlocks)
+	log.Info("Importing propagated block", "peer", peer, "number", block.Number(f.context), "hash", hash, "extBlocks", extBlocks)
 	go func() {
 		defer func() { f.done <- hash }()
 
 		// If the parent's unknown, abort insertion
-		parent := f.getBlock(block.ParentHash())
+		parent := f.getBlock(block.ParentHash(f.context))
 		if parent == nil {
-			log.Debug("Unknown parent of propagated block", "peer", peer, "number", block.Number(), "hash", hash, "parent", block.ParentHash())
+			log.Debug("Unknown parent of propagated block", "peer", peer, "number", block.Number(f.context), "hash", hash, "parent", block.ParentHash(f.context))
 			return
 		}
 		// Quickly validate the header and propagate the block if it passes
@@ -943,7 +945,7 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block, extBlocks [
 
 		default:
 			// Something went very wrong, drop the peer
-			log.Debug("Propagated block verification failed", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
+			log.Debug("Propagated block verification failed", "peer", peer, "number", block.Number(f.context), "hash", hash, "err", err)
 			f.dropPeer(peer)
 			return
 		}
@@ -951,7 +953,7 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block, extBlocks [
 		// Run the actual import and log any issues
 		log.Info("importBlocks: Inserting block into chain", "hash", block.Hash(), "extBlocks", len(extBlocks))
 		if _, err := f.insertChain(types.Blocks{block}, extBlocks); err != nil {
-			log.Debug("Propagated block import failed", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
+			log.Debug("Propagated block import failed", "peer", peer, "number", block.Number(f.context), "hash", hash, "err", err)
 			return
 		}
 		// If import succeeded, broadcast the block
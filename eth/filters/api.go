@@ -361,6 +361,68 @@ func (api *PublicFilterAPI) MissingExtBlock(ctx context.Context) (*rpc.Subscript
 	return rpcSub, nil
 }
 
+// ETxStatus sends a notification each time an external transaction advances in
+// its lifecycle.
+func (api *PublicFilterAPI) ETxStatus(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		etxStatus := make(chan core.ETxStatusEvent)
+		etxStatusSub := api.backend.SubscribeETxStatusEvent(etxStatus)
+
+		for {
+			select {
+			case ev := <-etxStatus:
+				notifier.Notify(rpcSub.ID, ev.Status)
+			case <-rpcSub.Err():
+				etxStatusSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				etxStatusSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// ReorgJournal sends a notification each time the chain rolls up to another
+// fork, describing the rollup as journaled.
+func (api *PublicFilterAPI) ReorgJournal(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		journal := make(chan core.ReorgJournalEvent)
+		journalSub := api.backend.SubscribeReorgJournalEvent(journal)
+
+		for {
+			select {
+			case ev := <-journal:
+				notifier.Notify(rpcSub.ID, ev.Record)
+			case <-rpcSub.Err():
+				journalSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				journalSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
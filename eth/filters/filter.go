@@ -27,11 +27,13 @@ import (
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/ethdb"
 	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rpc"
 )
 
 type Backend interface {
 	ChainDb() ethdb.Database
+	ChainConfig() *params.ChainConfig
 	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
 	HeaderByHash(ctx context.Context, blockHash common.Hash) (*types.Header, error)
 	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
@@ -45,6 +47,8 @@ type Backend interface {
 	SubscribePendingBlockEvent(ch chan<- *types.Header) event.Subscription
 	SubscribeReOrgEvent(ch chan<- core.ReOrgRollup) event.Subscription
 	SubscribeMissingExternalBlockEvent(ch chan<- core.MissingExternalBlock) event.Subscription
+	SubscribeETxStatusEvent(ch chan<- core.ETxStatusEvent) event.Subscription
+	SubscribeReorgJournalEvent(ch chan<- core.ReorgJournalEvent) event.Subscription
 	SubscribeChainUncleEvent(ch chan<- *types.Header) event.Subscription
 
 	BloomStatus() (uint64, uint64)
@@ -137,7 +141,7 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if header == nil {
 		return nil, nil
 	}
-	head := header.Number[types.QuaiNetworkContext].Uint64()
+	head := header.Number[f.backend.ChainConfig().Context].Uint64()
 
 	if f.begin == -1 {
 		f.begin = int64(head)
@@ -235,7 +239,7 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header *types.Header) (logs []*types.Log, err error) {
-	if bloomFilter(header.Bloom[types.QuaiNetworkContext], f.addresses, f.topics) {
+	if bloomFilter(header.Bloom[f.backend.ChainConfig().Context], f.addresses, f.topics) {
 		found, err := f.checkMatches(ctx, header)
 		if err != nil {
 			return logs, err
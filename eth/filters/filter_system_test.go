@@ -59,6 +59,10 @@ func (b *testBackend) ChainDb() ethdb.Database {
 	return b.db
 }
 
+func (b *testBackend) ChainConfig() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
 func (b *testBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
 	var (
 		hash common.Hash
@@ -100,6 +104,14 @@ func (b *testBackend) SubscribeReOrgEvent(ch chan<- core.ReOrgRollup) event.Subs
 func (b *testBackend) SubscribeMissingExternalBlockEvent(ch chan<- core.MissingExternalBlock) event.Subscription {
 	return nil
 }
+func (b *testBackend) SubscribeETxStatusEvent(ch chan<- core.ETxStatusEvent) event.Subscription {
+	return nil
+}
+
+func (b *testBackend) SubscribeReorgJournalEvent(ch chan<- core.ReorgJournalEvent) event.Subscription {
+	return nil
+}
+
 func (b *testBackend) SubscribeChainUncleEvent(ch chan<- *types.Header) event.Subscription {
 	return nil
 }
@@ -79,9 +79,9 @@ func BenchmarkFilters(b *testing.B) {
 	})
 	for i, block := range chain {
 		rawdb.WriteBlock(db, block)
-		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 		rawdb.WriteHeadBlockHash(db, block.Hash())
-		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext), receipts[i])
 	}
 	b.ResetTimer()
 
@@ -163,9 +163,9 @@ func TestFilters(t *testing.T) {
 	})
 	for i, block := range chain {
 		rawdb.WriteBlock(db, block)
-		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext))
 		rawdb.WriteHeadBlockHash(db, block.Hash())
-		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(types.QuaiNetworkContext), receipts[i])
 	}
 
 	filter := NewRangeFilter(backend, 0, -1, []common.Address{addr}, [][]common.Hash{{hash1, hash2, hash3, hash4}})
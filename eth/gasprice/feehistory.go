@@ -84,7 +84,7 @@ func (s sortGasAndReward) Less(i, j int) bool {
 // fills in the rest of the fields.
 func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 	chainconfig := oracle.backend.ChainConfig()
-	if bf.results.baseFee = bf.header.BaseFee[types.QuaiNetworkContext]; bf.results.baseFee == nil {
+	if bf.results.baseFee = bf.header.BaseFee[chainconfig.Context]; bf.results.baseFee == nil {
 		bf.results.baseFee = new(big.Int)
 	}
 	if chainconfig.IsLondon(big.NewInt(int64(bf.blockNumber + 1))) {
@@ -92,7 +92,7 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 	} else {
 		bf.results.nextBaseFee = new(big.Int)
 	}
-	bf.results.gasUsedRatio = float64(bf.header.GasUsed[types.QuaiNetworkContext]) / float64(bf.header.GasLimit[types.QuaiNetworkContext])
+	bf.results.gasUsedRatio = float64(bf.header.GasUsed[chainconfig.Context]) / float64(bf.header.GasLimit[chainconfig.Context])
 	if len(percentiles) == 0 {
 		// rewards were not requested, return null
 		return
@@ -113,7 +113,7 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 
 	sorter := make(sortGasAndReward, len(bf.block.Transactions()))
 	for i, tx := range bf.block.Transactions() {
-		reward, _ := tx.EffectiveGasTip(bf.block.BaseFee())
+		reward, _ := tx.EffectiveGasTip(bf.block.BaseFee(chainconfig.Context))
 		sorter[i] = txGasAndReward{gasUsed: bf.receipts[i].GasUsed, reward: reward}
 	}
 	sort.Sort(sorter)
@@ -122,7 +122,7 @@ func (oracle *Oracle) processBlock(bf *blockFees, percentiles []float64) {
 	sumGasUsed := sorter[0].gasUsed
 
 	for i, p := range percentiles {
-		thresholdGasUsed := uint64(float64(bf.block.GasUsed()) * p / 100)
+		thresholdGasUsed := uint64(float64(bf.block.GasUsed(chainconfig.Context)) * p / 100)
 		for sumGasUsed < thresholdGasUsed && txIndex < len(bf.block.Transactions())-1 {
 			txIndex++
 			sumGasUsed += sorter[txIndex].gasUsed
@@ -145,7 +145,7 @@ func (oracle *Oracle) resolveBlockRange(ctx context.Context, lastBlock rpc.Block
 	// query either pending block or head header and set headBlock
 	if lastBlock == rpc.PendingBlockNumber {
 		if pendingBlock, pendingReceipts = oracle.backend.PendingBlockAndReceipts(); pendingBlock != nil {
-			lastBlock = rpc.BlockNumber(pendingBlock.NumberU64())
+			lastBlock = rpc.BlockNumber(pendingBlock.NumberU64(oracle.backend.ChainConfig().Context))
 			headBlock = lastBlock - 1
 		} else {
 			// pending block not supported by backend, process until latest block
@@ -159,7 +159,7 @@ func (oracle *Oracle) resolveBlockRange(ctx context.Context, lastBlock rpc.Block
 	if pendingBlock == nil {
 		// if pending block is not fetched then we retrieve the head header to get the head block number
 		if latestHeader, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber); err == nil {
-			headBlock = rpc.BlockNumber(latestHeader.Number[types.QuaiNetworkContext].Uint64())
+			headBlock = rpc.BlockNumber(latestHeader.Number[oracle.backend.ChainConfig().Context].Uint64())
 		} else {
 			return nil, nil, 0, 0, err
 		}
@@ -238,7 +238,7 @@ func (oracle *Oracle) FeeHistory(ctx context.Context, blocks int, unresolvedLast
 				}
 
 				fees := &blockFees{blockNumber: blockNumber}
-				if pendingBlock != nil && blockNumber >= pendingBlock.NumberU64() {
+				if pendingBlock != nil && blockNumber >= pendingBlock.NumberU64(oracle.backend.ChainConfig().Context) {
 					fees.block, fees.receipts = pendingBlock, pendingReceipts
 					fees.header = fees.block.Header()
 					oracle.processBlock(fees, rewardPercentiles)
@@ -112,7 +112,7 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 	go func() {
 		var lastHead common.Hash
 		for ev := range headEvent {
-			if ev.Block.ParentHash() != lastHead {
+			if ev.Block.ParentHash(backend.ChainConfig().Context) != lastHead {
 				cache.Purge()
 			}
 			lastHead = ev.Block.Hash()
@@ -161,7 +161,7 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	}
 	var (
 		sent, exp int
-		number    = head.Number[types.QuaiNetworkContext].Uint64()
+		number    = head.Number[oracle.backend.ChainConfig().Context].Uint64()
 		result    = make(chan results, oracle.checkBlocks)
 		quit      = make(chan struct{})
 		results   []*big.Int
@@ -258,17 +258,17 @@ func (oracle *Oracle) getBlockValues(ctx context.Context, signer types.Signer, b
 	// Sort the transaction by effective tip in ascending sort.
 	txs := make([]*types.Transaction, len(block.Transactions()))
 	copy(txs, block.Transactions())
-	sorter := newSorter(txs, block.BaseFee())
+	sorter := newSorter(txs, block.BaseFee(oracle.backend.ChainConfig().Context))
 	sort.Sort(sorter)
 
 	var prices []*big.Int
 	for _, tx := range sorter.txs {
-		tip, _ := tx.EffectiveGasTip(block.BaseFee())
+		tip, _ := tx.EffectiveGasTip(block.BaseFee(oracle.backend.ChainConfig().Context))
 		if ignoreUnder != nil && tip.Cmp(ignoreUnder) == -1 {
 			continue
 		}
 		sender, err := types.Sender(signer, tx)
-		if err == nil && sender != block.Coinbase() {
+		if err == nil && sender != block.Coinbase(oracle.backend.ChainConfig().Context) {
 			prices = append(prices, tip)
 			if len(prices) >= limit {
 				break
@@ -44,6 +44,9 @@ const (
 	// txChanSize is the size of channel listening to NewTxsEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+
+	// faultChanSize is the size of channel listening to FaultProofEvent.
+	faultChanSize = 16
 )
 
 var (
@@ -113,6 +116,8 @@ type handler struct {
 	txsCh         chan core.NewTxsEvent
 	txsSub        event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
+	faultCh       chan core.FaultProofEvent
+	faultSub      event.Subscription
 
 	whitelist map[uint64]common.Hash
 
@@ -151,12 +156,12 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		//   time. But we don't have any recent state for full sync.
 		// In these cases however it's safe to reenable fast sync.
 		fullBlock, fastBlock := h.chain.CurrentBlock(), h.chain.CurrentFastBlock()
-		if fullBlock.NumberU64() == 0 && fastBlock.NumberU64() > 0 {
+		if fullBlock.NumberU64(h.chain.Config().Context) == 0 && fastBlock.NumberU64(h.chain.Config().Context) > 0 {
 			h.fastSync = uint32(1)
 			log.Warn("Switch sync mode from full sync to fast sync")
 		}
 	} else {
-		if h.chain.CurrentBlock().NumberU64() > 0 {
+		if h.chain.CurrentBlock().NumberU64(h.chain.Config().Context) > 0 {
 			// Print warning log if database is not empty to run fast sync.
 			log.Warn("Switch sync mode from fast sync to full sync")
 		} else {
@@ -182,14 +187,14 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	if atomic.LoadUint32(&h.fastSync) == 1 && atomic.LoadUint32(&h.snapSync) == 0 {
 		h.stateBloom = trie.NewSyncBloom(config.BloomCache, config.Database)
 	}
-	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.stateBloom, h.eventMux, h.chain, nil, h.removePeer)
+	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.stateBloom, h.eventMux, h.chain, nil, h.removePeer, h.chain.Config().Context)
 
 	// Construct the fetcher (short sync)
 	validator := func(header *types.Header) error {
 		return h.chain.Engine().VerifyHeader(h.chain, header, true)
 	}
 	heighter := func() uint64 {
-		return h.chain.CurrentBlock().NumberU64()
+		return h.chain.CurrentBlock().NumberU64(h.chain.Config().Context)
 	}
 	inserter := func(blocks types.Blocks, extBlocks []*types.ExternalBlock) (int, error) {
 		// If sync hasn't reached the checkpoint yet, deny importing weird blocks.
@@ -204,14 +209,14 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		// out a way yet where nodes can decide unilaterally whether the network is new
 		// or not. This should be fixed if we figure out a solution.
 		if atomic.LoadUint32(&h.fastSync) == 1 {
-			log.Warn("Fast syncing, discarded propagated block", "number", blocks[0].Number(), "hash", blocks[0].Hash())
+			log.Warn("Fast syncing, discarded propagated block", "number", blocks[0].Number(h.chain.Config().Context), "hash", blocks[0].Hash())
 			return 0, nil
 		}
 
-		log.Info("Adding external blocks from peer", "len", len(extBlocks), "number", blocks[0].Number())
+		log.Info("Adding external blocks from peer", "len", len(extBlocks), "number", blocks[0].Number(h.chain.Config().Context))
 		err := h.chain.AddExternalBlocks(extBlocks)
 		if err != nil {
-			log.Warn("Error importing external blocks", "number", blocks[0].Number(), "hash", blocks[0].Hash())
+			log.Warn("Error importing external blocks", "number", blocks[0].Number(h.chain.Config().Context), "hash", blocks[0].Hash())
 		}
 
 		n, err := h.chain.InsertChain(blocks)
@@ -220,7 +225,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return n, err
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer, h.chain.GetLinkExternalBlocks, h.chain.AddExternalBlocks)
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer, h.chain.GetLinkExternalBlocks, h.chain.AddExternalBlocks, h.chain.Config().Context)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -256,10 +261,10 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		genesis = h.chain.Genesis()
 		head    = h.chain.CurrentHeader()
 		hash    = head.Hash()
-		number  = head.Number[types.QuaiNetworkContext].Uint64()
+		number  = head.Number[h.chain.Config().Context].Uint64()
 		td      = h.chain.GetTd(hash, number)
 	)
-	forkID := forkid.NewID(h.chain.Config(), h.chain.Genesis().Hash(), h.chain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64())
+	forkID := forkid.NewID(h.chain.Config(), h.chain.Genesis().Hash(), h.chain.CurrentHeader().Number[h.chain.Config().Context].Uint64())
 	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
 		peer.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
@@ -408,6 +413,12 @@ func (h *handler) Start(maxPeers int) {
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go h.minedBroadcastLoop()
 
+	// broadcast fault proofs
+	h.wg.Add(1)
+	h.faultCh = make(chan core.FaultProofEvent, faultChanSize)
+	h.faultSub = h.chain.SubscribeFaultProofEvent(h.faultCh)
+	go h.faultBroadcastLoop()
+
 	// start sync handlers
 	h.wg.Add(1)
 	go h.chainSync.loop()
@@ -416,6 +427,7 @@ func (h *handler) Start(maxPeers int) {
 func (h *handler) Stop() {
 	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	h.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	h.faultSub.Unsubscribe()      // quits faultBroadcastLoop
 
 	// Quit chainSync and txsync64.
 	// After this is done, no new peers will be accepted.
@@ -442,7 +454,7 @@ func (h *handler) BroadcastBlock(block *types.Block, extBlocks []*types.External
 	if propagate {
 		// Calculate the TD of the block (it's not imported yet, so block.Td is not valid)
 		var td []*big.Int
-		if parent := h.chain.GetBlock(block.ParentHash(), block.NumberU64()-1); parent != nil {
+		if parent := h.chain.GetBlock(block.ParentHash(h.chain.Config().Context), block.NumberU64(h.chain.Config().Context)-1); parent != nil {
 			order, err := h.chain.Engine().GetDifficultyOrder(block.Header())
 			if err != nil {
 				log.Error("Error calculating block order in BroadcastBlock, err: ", err)
@@ -453,9 +465,9 @@ func (h *handler) BroadcastBlock(block *types.Block, extBlocks []*types.External
 				return
 			}
 			var tempTD = big.NewInt(0)
-			parentPrimeTd := h.chain.GetTd(block.Header().ParentHash[types.QuaiNetworkContext], block.Header().Number[types.QuaiNetworkContext].Uint64()-1)[0]
-			parentRegionTd := h.chain.GetTd(block.Header().ParentHash[types.QuaiNetworkContext], block.Header().Number[types.QuaiNetworkContext].Uint64()-1)[1]
-			parentZoneTd := h.chain.GetTd(block.Header().ParentHash[types.QuaiNetworkContext], block.Header().Number[types.QuaiNetworkContext].Uint64()-1)[2]
+			parentPrimeTd := h.chain.GetTd(block.Header().ParentHash[h.chain.Config().Context], block.Header().Number[h.chain.Config().Context].Uint64()-1)[0]
+			parentRegionTd := h.chain.GetTd(block.Header().ParentHash[h.chain.Config().Context], block.Header().Number[h.chain.Config().Context].Uint64()-1)[1]
+			parentZoneTd := h.chain.GetTd(block.Header().ParentHash[h.chain.Config().Context], block.Header().Number[h.chain.Config().Context].Uint64()-1)[2]
 			switch order {
 			case params.PRIME:
 				tempTD = new(big.Int).Add(block.Header().Difficulty[0], parentPrimeTd)
@@ -468,7 +480,7 @@ func (h *handler) BroadcastBlock(block *types.Block, extBlocks []*types.External
 				td = []*big.Int{parentPrimeTd, parentRegionTd, tempTD}
 			}
 		} else {
-			log.Error("Propagating dangling block", "number", block.Number(), "hash", hash)
+			log.Error("Propagating dangling block", "number", block.Number(h.chain.Config().Context), "hash", hash)
 			return
 		}
 		// Send the block to a subset of our peers if less than 10
@@ -482,9 +494,9 @@ func (h *handler) BroadcastBlock(block *types.Block, extBlocks []*types.External
 		return
 	}
 	// Otherwise if the block is indeed in out own chain, announce it
-	if h.chain.HasBlock(hash, block.NumberU64()) {
+	if h.chain.HasBlock(hash, block.NumberU64(h.chain.Config().Context)) {
 		for _, peer := range peers {
-			peer.AsyncSendNewBlockHash(block)
+			peer.AsyncSendNewBlockHash(block, h.chain.Config().Context)
 		}
 		log.Trace("Announced block", "hash", hash, "recipients", len(peers), "duration", common.PrettyDuration(time.Since(block.ReceivedAt)))
 	}
@@ -555,6 +567,32 @@ func (h *handler) minedBroadcastLoop() {
 	}
 }
 
+// BroadcastFaultProof propagates a fault proof to all peers not yet known to
+// have it.
+func (h *handler) BroadcastFaultProof(proof *types.FaultProof) {
+	hash := proof.Hash()
+	peers := h.peers.peersWithoutFaultProof(hash)
+	for _, peer := range peers {
+		if err := peer.SendFaultProofs([]*types.FaultProof{proof}); err != nil {
+			log.Debug("Failed to send fault proof", "peer", peer.ID(), "hash", hash, "err", err)
+		}
+	}
+	log.Debug("Fault proof broadcast", "hash", hash, "kind", proof.Kind, "recipients", len(peers))
+}
+
+// faultBroadcastLoop propagates newly recorded fault proofs to connected peers.
+func (h *handler) faultBroadcastLoop() {
+	defer h.wg.Done()
+	for {
+		select {
+		case event := <-h.faultCh:
+			h.BroadcastFaultProof(event.Proof)
+		case <-h.faultSub.Err():
+			return
+		}
+	}
+}
+
 // txBroadcastLoop announces new transactions to connected peers.
 func (h *handler) txBroadcastLoop() {
 	defer h.wg.Done()
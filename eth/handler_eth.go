@@ -87,6 +87,9 @@ func (h *ethHandler) Handle(peer *eth.Peer, packet eth.Packet) error {
 	case *eth.ExtBlocksPacket:
 		return h.handleExtBlocks(peer, *packet)
 
+	case *eth.FaultProofsPacket:
+		return h.handleFaultProofs(peer, *packet)
+
 	case *eth.NewBlockHashesPacket:
 		hashes, numbers := packet.Unpack()
 		return h.handleBlockAnnounces(peer, hashes, numbers)
@@ -133,7 +136,7 @@ func (h *ethHandler) handleHeaders(peer *eth.Peer, headers []*types.Header) erro
 	filter := len(headers) == 1
 	if filter {
 		// If it's a potential sync progress check, validate the content and advertised chain weight
-		if p.syncDrop != nil && headers[0].Number[types.QuaiNetworkContext].Uint64() == h.checkpointNumber {
+		if p.syncDrop != nil && headers[0].Number[h.chain.Config().Context].Uint64() == h.checkpointNumber {
 			// Disable the sync drop timer
 			p.syncDrop.Stop()
 			p.syncDrop = nil
@@ -145,12 +148,12 @@ func (h *ethHandler) handleHeaders(peer *eth.Peer, headers []*types.Header) erro
 			return nil
 		}
 		// Otherwise if it's a whitelisted block, validate against the set
-		if want, ok := h.whitelist[headers[0].Number[types.QuaiNetworkContext].Uint64()]; ok {
+		if want, ok := h.whitelist[headers[0].Number[h.chain.Config().Context].Uint64()]; ok {
 			if hash := headers[0].Hash(); want != hash {
-				peer.Log().Info("Whitelist mismatch, dropping peer", "number", headers[0].Number[types.QuaiNetworkContext].Uint64(), "hash", hash, "want", want)
+				peer.Log().Info("Whitelist mismatch, dropping peer", "number", headers[0].Number[h.chain.Config().Context].Uint64(), "hash", hash, "want", want)
 				return errors.New("whitelist block mismatch")
 			}
-			peer.Log().Debug("Whitelist block verified", "number", headers[0].Number[types.QuaiNetworkContext].Uint64(), "hash", want)
+			peer.Log().Debug("Whitelist block verified", "number", headers[0].Number[h.chain.Config().Context].Uint64(), "hash", want)
 		}
 		// Irrelevant of the fork checks, send the header to the fetcher just in case
 		headers = h.blockFetcher.FilterHeaders(peer.ID(), headers, time.Now())
@@ -196,6 +199,24 @@ func (h *ethHandler) handleExtBlocks(peer *eth.Peer, extBlocks [][]*types.Extern
 	return nil
 }
 
+// handleFaultProofs is invoked from a peer's message handler when it transmits a
+// batch of fault proofs for the local node to verify and store. Proofs that fail
+// verification drop the peer.
+func (h *ethHandler) handleFaultProofs(peer *eth.Peer, proofs []*types.FaultProof) error {
+	for _, proof := range proofs {
+		if err := h.chain.AddFaultProof(proof); err != nil {
+			if errors.Is(err, core.ErrKnownFaultProof) {
+				continue
+			}
+			if errors.Is(err, core.ErrInvalidFaultProof) {
+				return err
+			}
+			log.Debug("Failed to add fault proof", "peer", peer.ID(), "hash", proof.Hash(), "err", err)
+		}
+	}
+	return nil
+}
+
 // handleBlockAnnounces is invoked from a peer's message handler when it transmits a
 // batch of block announcements for the local node to process.
 func (h *ethHandler) handleBlockAnnounces(peer *eth.Peer, hashes []common.Hash, numbers []uint64) error {
@@ -230,7 +251,7 @@ func (h *ethHandler) handleBlockBroadcast(peer *eth.Peer, block *types.Block, td
 	// Assuming the block is importable by the peer, but possibly not yet done so,
 	// calculate the head hash and TD that the peer truly must have.
 	var (
-		trueHead = block.ParentHash()
+		trueHead = block.ParentHash(h.chain.Config().Context)
 		trueTD   = make([]*big.Int, 3)
 		tempTD   = big.NewInt(0)
 	)
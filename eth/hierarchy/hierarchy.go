@@ -0,0 +1,405 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package hierarchy runs the full Prime, Region and Zone hierarchy inside a
+// single process. Every context gets its own node.Node and eth.Ethereum
+// instance, the hierarchy level is taken from each instance's chain config and
+// the dominant/subordinate links call directly into the linked chains instead
+// of going through websockets.
+package hierarchy
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/eth"
+	"github.com/spruce-solutions/go-quai/eth/ethconfig"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/node"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// Config contains the templates used to build every instance of the hierarchy.
+type Config struct {
+	Node    node.Config      // Node configuration template, DataDir is used as the root of all instances
+	Eth     ethconfig.Config // Protocol configuration template, Genesis and links are set per instance
+	Ropsten bool             // Whether to use the ropsten chain configs instead of mainnet
+
+	// Ontology, if set, replaces the shape of the network. The configs of all
+	// contexts are then derived from the prime chain config.
+	Ontology *params.Ontology
+
+	// OntologyForks, if set, schedules expansions of the ontology. The chains
+	// they create are started once the prime chain reaches their fork block.
+	OntologyForks []*params.OntologyFork
+}
+
+// Instance is a single context of the hierarchy.
+type Instance struct {
+	Name     string
+	Context  int
+	Location []byte
+	Stack    *node.Node
+	Eth      *eth.Ethereum
+}
+
+// Hierarchy is a Prime chain together with all of its Regions and Zones.
+type Hierarchy struct {
+	Prime   *Instance
+	Regions []*Instance
+	Zones   [][]*Instance
+
+	config        *Config
+	regionGenesis func(*params.ChainConfig) *core.Genesis
+	zoneGenesis   func(*params.ChainConfig) *core.Genesis
+
+	lock sync.RWMutex // Protects Regions and Zones growing on ontology forks
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates, but does not start, every instance of the hierarchy.
+func New(config *Config) (*Hierarchy, error) {
+	var (
+		primeConfig   = params.MainnetPrimeChainConfig
+		primeGenesis  = core.MainnetPrimeGenesisBlock()
+		regionConfigs = params.MainnetRegionChainConfigs
+		zoneConfigs   = params.MainnetZoneChainConfigs
+		regionGenesis = core.MainnetRegionGenesisBlock
+		zoneGenesis   = core.MainnetZoneGenesisBlock
+	)
+	if config.Ropsten {
+		primeConfig = params.RopstenPrimeChainConfig
+		primeGenesis = core.RopstenPrimeGenesisBlock()
+		regionConfigs = params.RopstenRegionChainConfigs
+		zoneConfigs = params.RopstenZoneChainConfigs
+		regionGenesis = core.RopstenRegionGenesisBlock
+		zoneGenesis = core.RopstenZoneGenesisBlock
+	}
+	if config.Ontology != nil {
+		template := *primeConfig
+		template.OntologyForks = config.OntologyForks
+
+		var err error
+		if regionConfigs, zoneConfigs, err = ontologyConfigs(config.Ontology, &template); err != nil {
+			return nil, err
+		}
+		if primeConfig, err = config.Ontology.ChainConfig(&template, []byte{0, 0}); err != nil {
+			return nil, err
+		}
+		primeGenesis.Config = primeConfig
+	} else if len(config.OntologyForks) > 0 {
+		return nil, errors.New("ontology forks need an ontology")
+	}
+	h := &Hierarchy{
+		Regions:       make([]*Instance, len(regionConfigs)),
+		Zones:         make([][]*Instance, len(zoneConfigs)),
+		config:        config,
+		regionGenesis: regionGenesis,
+		zoneGenesis:   zoneGenesis,
+		quit:          make(chan struct{}),
+	}
+	var err error
+	if h.Prime, err = newInstance(config, 0, "prime", primeConfig, primeGenesis); err != nil {
+		return nil, err
+	}
+	for i := range regionConfigs {
+		name := fmt.Sprintf("region-%d", i+1)
+		if h.Regions[i], err = newInstance(config, 1+i, name, &regionConfigs[i], regionGenesis(&regionConfigs[i])); err != nil {
+			h.Close()
+			return nil, err
+		}
+	}
+	for i := range zoneConfigs {
+		h.Zones[i] = make([]*Instance, len(zoneConfigs[i]))
+		for j := range zoneConfigs[i] {
+			name := fmt.Sprintf("zone-%d-%d", i+1, j+1)
+			if h.Zones[i][j], err = newInstance(config, 1+len(regionConfigs)+i*len(zoneConfigs[i])+j, name, &zoneConfigs[i][j], zoneGenesis(&zoneConfigs[i][j])); err != nil {
+				h.Close()
+				return nil, err
+			}
+		}
+	}
+	return h, nil
+}
+
+// ontologyConfigs derives the region and zone chain configs of an ontology from
+// the prime chain config.
+func ontologyConfigs(ontology *params.Ontology, template *params.ChainConfig) ([]params.ChainConfig, [][]params.ChainConfig, error) {
+	if err := ontology.Validate(); err != nil {
+		return nil, nil, err
+	}
+	regions := make([]params.ChainConfig, ontology.Regions)
+	zones := make([][]params.ChainConfig, ontology.Regions)
+	for r := range regions {
+		config, err := ontology.ChainConfig(template, []byte{byte(r + 1), 0})
+		if err != nil {
+			return nil, nil, err
+		}
+		regions[r] = *config
+
+		zones[r] = make([]params.ChainConfig, ontology.Zones)
+		for z := range zones[r] {
+			config, err := ontology.ChainConfig(template, []byte{byte(r + 1), byte(z + 1)})
+			if err != nil {
+				return nil, nil, err
+			}
+			zones[r][z] = *config
+		}
+	}
+	return regions, zones, nil
+}
+
+// newInstance creates the node and protocol stack of a single context. The index
+// is used to offset the RPC ports of the template so instances don't collide.
+func newInstance(config *Config, index int, name string, chainConfig *params.ChainConfig, genesis *core.Genesis) (*Instance, error) {
+	nodeConfig := config.Node
+	nodeConfig.Name = name
+	if nodeConfig.DataDir != "" {
+		nodeConfig.DataDir = filepath.Join(config.Node.DataDir, name)
+	}
+	// Instances are linked in-process, they only need networking if it was
+	// explicitly requested in the template.
+	nodeConfig.P2P.ListenAddr = ""
+	nodeConfig.P2P.NoDiscovery = true
+	// The authenticated endpoint only serves out-of-process dominant and
+	// subordinate chains.
+	nodeConfig.AuthAddr = ""
+	if nodeConfig.HTTPHost != "" {
+		nodeConfig.HTTPPort += index
+	}
+	if nodeConfig.WSHost != "" {
+		nodeConfig.WSPort += index
+	}
+
+	stack, err := node.New(&nodeConfig)
+	if err != nil {
+		return nil, err
+	}
+	ethConfig := config.Eth
+	ethConfig.Genesis = genesis
+	ethConfig.NetworkId = chainConfig.ChainID.Uint64()
+	ethConfig.DomUrl = ""
+	ethConfig.SubUrls = nil
+
+	backend, err := eth.New(stack, &ethConfig)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	return &Instance{
+		Name:     name,
+		Context:  chainConfig.Context,
+		Location: chainConfig.Location,
+		Stack:    stack,
+		Eth:      backend,
+	}, nil
+}
+
+// Instances returns every instance of the hierarchy, dominant chains first.
+func (h *Hierarchy) Instances() []*Instance {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.instances()
+}
+
+func (h *Hierarchy) instances() []*Instance {
+	var instances []*Instance
+	if h.Prime != nil {
+		instances = append(instances, h.Prime)
+	}
+	for _, region := range h.Regions {
+		if region != nil {
+			instances = append(instances, region)
+		}
+	}
+	for _, zones := range h.Zones {
+		for _, zone := range zones {
+			if zone != nil {
+				instances = append(instances, zone)
+			}
+		}
+	}
+	return instances
+}
+
+// Start starts every instance and links each chain to its dominant and
+// subordinate chains in memory.
+func (h *Hierarchy) Start() error {
+	for _, instance := range h.Instances() {
+		if err := instance.Stack.Start(); err != nil {
+			return fmt.Errorf("failed to start %s: %v", instance.Name, err)
+		}
+	}
+	for i, region := range h.Regions {
+		if err := h.link(h.Prime, region, i); err != nil {
+			return err
+		}
+		for j, zone := range h.Zones[i] {
+			if err := h.link(region, zone, j); err != nil {
+				return err
+			}
+		}
+	}
+	if len(h.Prime.Eth.BlockChain().Config().OntologyForks) > 0 {
+		h.wg.Add(1)
+		go h.forkLoop()
+	}
+	log.Info("Started in-process hierarchy", "regions", len(h.Regions), "instances", len(h.Instances()))
+	return nil
+}
+
+// forkLoop expands the hierarchy whenever the prime chain reaches the block of
+// a scheduled ontology fork.
+func (h *Hierarchy) forkLoop() {
+	defer h.wg.Done()
+
+	var (
+		chain  = h.Prime.Eth.BlockChain()
+		forks  = chain.Config().OntologyForks
+		heads  = make(chan core.ChainHeadEvent, 16)
+		sub    = chain.SubscribeChainHeadEvent(heads)
+		active = 0
+	)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case head := <-heads:
+			number := head.Block.Number(params.PRIME)
+			for ; active < len(forks) && number.Cmp(forks[active].Block) >= 0; active++ {
+				fork := chain.GetHeaderByNumber(forks[active].Block.Uint64())
+				if fork == nil {
+					log.Error("Ontology fork block missing", "number", forks[active].Block)
+					break
+				}
+				if err := h.Expand(fork); err != nil {
+					log.Error("Failed to expand the hierarchy", "number", forks[active].Block, "err", err)
+				}
+			}
+		case <-sub.Err():
+			return
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+// Expand creates, starts and links the chains created by the ontology fork the
+// given prime block activates. Their genesis is anchored to the fork block.
+func (h *Hierarchy) Expand(fork *types.Header) error {
+	var (
+		config = h.Prime.Eth.BlockChain().Config()
+		number = fork.Number[params.PRIME]
+		after  = config.OntologyAt(number)
+	)
+	if after == nil || number.Sign() == 0 || config.OntologyAt(new(big.Int).Sub(number, common.Big1)) == after {
+		return fmt.Errorf("no ontology fork at prime block %v", number)
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for r := 0; r < after.Regions; r++ {
+		if r == len(h.Regions) {
+			region, err := h.forkInstance(h.Prime, []byte{byte(r + 1), 0}, fork)
+			if err != nil {
+				return err
+			}
+			h.Regions = append(h.Regions, region)
+			h.Zones = append(h.Zones, nil)
+			if err := h.link(h.Prime, region, r); err != nil {
+				return err
+			}
+		}
+		region := h.Regions[r]
+		for z := len(h.Zones[r]); z < after.Zones; z++ {
+			zone, err := h.forkInstance(region, []byte{byte(r + 1), byte(z + 1)}, fork)
+			if err != nil {
+				return err
+			}
+			h.Zones[r] = append(h.Zones[r], zone)
+			if err := h.link(region, zone, z); err != nil {
+				return err
+			}
+		}
+	}
+	log.Info("Expanded in-process hierarchy", "number", number, "regions", after.Regions, "zones", after.Zones)
+	return nil
+}
+
+// forkInstance creates and starts the instance of a chain created by the
+// ontology fork at the given prime block, deriving its config from its dominant.
+func (h *Hierarchy) forkInstance(dom *Instance, location []byte, fork *types.Header) (*Instance, error) {
+	chainConfig, err := dom.Eth.BlockChain().Config().ForkedChainConfig(location)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("region-%d", location[0])
+	template := h.regionGenesis(chainConfig)
+	if chainConfig.Context == params.ZONE {
+		name = fmt.Sprintf("zone-%d-%d", location[0], location[1])
+		template = h.zoneGenesis(chainConfig)
+	}
+	genesis, err := core.OntologyForkGenesisBlock(template, chainConfig, fork)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := newInstance(h.config, len(h.instances()), name, genesis.Config, genesis)
+	if err != nil {
+		return nil, err
+	}
+	if err := instance.Stack.Start(); err != nil {
+		instance.Stack.Close()
+		return nil, fmt.Errorf("failed to start %s: %v", name, err)
+	}
+	return instance, nil
+}
+
+// link attaches dom as the dominant of sub, and sub as the subordinate of dom
+// at the given index.
+func (h *Hierarchy) link(dom, sub *Instance, index int) error {
+	sub.Eth.BlockChain().SetDomLink(core.NewMemoryLink(dom.Eth.BlockChain(), dom.Eth.EventMux()))
+	return dom.Eth.BlockChain().SetSubLink(index, core.NewMemoryLink(sub.Eth.BlockChain(), sub.Eth.EventMux()))
+}
+
+// Close stops every instance of the hierarchy, subordinate chains first.
+func (h *Hierarchy) Close() error {
+	select {
+	case <-h.quit:
+	default:
+		close(h.quit)
+	}
+	h.wg.Wait()
+
+	var errs []error
+	instances := h.Instances()
+	for i := len(instances) - 1; i >= 0; i-- {
+		if err := instances[i].Stack.Close(); err != nil && err != node.ErrNodeStopped {
+			errs = append(errs, fmt.Errorf("%s: %v", instances[i].Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close hierarchy: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,489 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hierarchy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/eth/ethconfig"
+	"github.com/spruce-solutions/go-quai/miner/manager"
+	"github.com/spruce-solutions/go-quai/node"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// newTestHierarchy starts a single prime, region and zone chain in memory,
+// sealing with fake difficulties, and schedules the given ontology forks.
+func newTestHierarchy(t *testing.T, forks ...*params.OntologyFork) *Hierarchy {
+	config := &Config{
+		Node:          node.Config{Name: "quai-test"},
+		Eth:           ethconfig.Defaults,
+		Ontology:      &params.Ontology{Regions: 1, Zones: 1, BaseChainID: params.MainnetOntology.BaseChainID},
+		OntologyForks: forks,
+	}
+	config.Eth.Blake3.Fakepow = true
+	config.Eth.SnapshotCache = 0
+	config.Eth.Miner.Etherbase = common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+	h, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create hierarchy: %v", err)
+	}
+	// The genesis hashes of the derived configs are those of the template,
+	// point them at the genesis the instances actually run on.
+	for _, instance := range h.Instances() {
+		chain := instance.Eth.BlockChain()
+		genesis := chain.Genesis().Hash()
+		chain.Config().GenesisHashes = []common.Hash{genesis, genesis, genesis}
+	}
+	if err := h.Start(); err != nil {
+		h.Close()
+		t.Fatalf("failed to start hierarchy: %v", err)
+	}
+	return h
+}
+
+// pendingWork starts the miner of every instance of the slice without local
+// sealing threads and returns the first sealing block each of them assembles.
+func pendingWork(t *testing.T, instances []*Instance) []*types.Block {
+	blocks := make([]*types.Block, len(instances))
+	for i, instance := range instances {
+		headers := make(chan *types.Header, 1)
+		sub := instance.Eth.Miner().SubscribePendingBlock(headers)
+		if err := instance.Eth.StartMining(0); err != nil {
+			t.Fatalf("%s: failed to start miner: %v", instance.Name, err)
+		}
+		select {
+		case header := <-headers:
+			blocks[i] = instance.Eth.Miner().PendingWork(instance.Eth.Engine().SealHash(header))
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: no pending work", instance.Name)
+		}
+		sub.Unsubscribe()
+		instance.Eth.StopMining()
+
+		if blocks[i] == nil {
+			t.Fatalf("%s: pending work already dropped", instance.Name)
+		}
+	}
+	return blocks
+}
+
+// seal searches the nonce of the header until its difficulty order is the
+// given one, so the block is imported in every context from order down.
+func seal(t *testing.T, engine *blake3.Blake3, header *types.Header, order int) {
+	for nonce := uint64(0); ; nonce++ {
+		binary.BigEndian.PutUint64(header.Nonce[:], nonce)
+		if have, err := engine.GetDifficultyOrder(header); err == nil && have == order {
+			return
+		}
+		if nonce == 1<<26 {
+			t.Fatalf("failed to seal block of order %d", order)
+		}
+	}
+}
+
+// Tests that every chain of an in-process hierarchy imports a block in its
+// own context, instead of the context of the process.
+func TestHierarchyImport(t *testing.T) {
+	h := newTestHierarchy(t)
+	defer h.Close()
+
+	instances := []*Instance{h.Prime, h.Regions[0], h.Zones[0][0]}
+	for i, instance := range instances {
+		if instance.Context != i {
+			t.Fatalf("%s: context mismatch: have %d, want %d", instance.Name, instance.Context, i)
+		}
+	}
+	blocks := pendingWork(t, instances)
+
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	header := manager.CombineHeaders(headers)
+	seal(t, blake3.NewContextFaker(params.ZONE), header, params.PRIME)
+
+	// Instances don't gossip blocks, hand the dominant chains the external
+	// blocks they would otherwise receive from the network.
+	for i, instance := range instances {
+		for j := i + 1; j < len(instances); j++ {
+			external := types.NewExternalBlockWithHeader(header).WithBody(blocks[j].Transactions(), blocks[j].Uncles(), nil, big.NewInt(int64(j)))
+			if err := instance.Eth.BlockChain().AddExternalBlock(external); err != nil {
+				t.Fatalf("%s: failed to add external block: %v", instance.Name, err)
+			}
+		}
+	}
+	for i, instance := range instances {
+		block := types.NewBlockWithHeader(header).WithBody(blocks[i].Transactions(), blocks[i].Uncles())
+		chain := instance.Eth.BlockChain()
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("%s: failed to import block: %v", instance.Name, err)
+		}
+		if head := chain.CurrentBlock(); head.Hash() != block.Hash() {
+			t.Fatalf("%s: head mismatch: have %x, want %x", instance.Name, head.Hash(), block.Hash())
+		}
+		if number := chain.CurrentBlock().NumberU64(instance.Context); number != 1 {
+			t.Errorf("%s: head number mismatch: have %d, want %d", instance.Name, number, 1)
+		}
+	}
+}
+
+// Tests that a block mined in a zone with a region order is delivered to the
+// region before the zone imports it, carrying the body of the region.
+func TestMinedBlockRelay(t *testing.T) {
+	h := newTestHierarchy(t)
+	defer h.Close()
+
+	prime, region, zone := h.Prime, h.Regions[0], h.Zones[0][0]
+	blocks := pendingWork(t, []*Instance{prime, region, zone})
+	header := manager.CombineHeaders([]*types.Header{blocks[0].Header(), blocks[1].Header(), blocks[2].Header()})
+	seal(t, blake3.NewContextFaker(params.ZONE), header, params.REGION)
+
+	// The region processes the zone block as an external block, the zone knows
+	// the region body from its external block
+	zoneExternal := types.NewExternalBlockWithHeader(header).WithBody(blocks[2].Transactions(), blocks[2].Uncles(), nil, big.NewInt(int64(params.ZONE)))
+	if err := region.Eth.BlockChain().AddExternalBlock(zoneExternal); err != nil {
+		t.Fatalf("failed to add zone external block: %v", err)
+	}
+	regionExternal := types.NewExternalBlockWithHeader(header).WithBody(blocks[1].Transactions(), blocks[1].Uncles(), nil, big.NewInt(int64(params.REGION)))
+	if err := zone.Eth.BlockChain().AddExternalBlock(regionExternal); err != nil {
+		t.Fatalf("failed to add region external block: %v", err)
+	}
+	block := types.NewBlockWithHeader(header).WithBody(blocks[2].Transactions(), blocks[2].Uncles())
+	if _, err := zone.Eth.BlockChain().InsertMinedBlock(context.Background(), block); err != nil {
+		t.Fatalf("failed to insert mined block: %v", err)
+	}
+	for _, instance := range []*Instance{region, zone} {
+		if head := instance.Eth.BlockChain().CurrentBlock(); head.Hash() != block.Hash() {
+			t.Errorf("%s: head mismatch: have %x, want %x", instance.Name, head.Hash(), block.Hash())
+		}
+	}
+	if head := prime.Eth.BlockChain().CurrentBlock(); head.Hash() == block.Hash() {
+		t.Errorf("%s: region block imported", prime.Name)
+	}
+}
+
+// Tests that a region follows the heads of its zone through links attached
+// while it runs, and prefetches the zone blocks as external blocks before any
+// region block references them.
+func TestExternalBlockPrefetch(t *testing.T) {
+	h := newTestHierarchy(t)
+	defer h.Close()
+
+	prime, region, zone := h.Prime, h.Regions[0], h.Zones[0][0]
+	blocks := pendingWork(t, []*Instance{prime, region, zone})
+	header := manager.CombineHeaders([]*types.Header{blocks[0].Header(), blocks[1].Header(), blocks[2].Header()})
+	seal(t, blake3.NewContextFaker(params.ZONE), header, params.ZONE)
+
+	// Replace the links concurrently while the prefetcher runs, it follows the
+	// last ones from its next relink on
+	errc := make(chan error, 1)
+	go func() {
+		for i := 0; i < 10; i++ {
+			if err := h.link(region, zone, 0); err != nil {
+				errc <- err
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		errc <- nil
+	}()
+	if err := <-errc; err != nil {
+		t.Fatalf("failed to relink zone: %v", err)
+	}
+	time.Sleep(6 * time.Second)
+
+	block := types.NewBlockWithHeader(header).WithBody(blocks[2].Transactions(), blocks[2].Uncles())
+	if _, err := zone.Eth.BlockChain().InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to import zone block: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); !rawdb.HasExternalBlock(region.Eth.ChainDb(), block.Hash(), uint64(params.ZONE)); {
+		if time.Now().After(deadline) {
+			t.Fatalf("zone block not prefetched by the region")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	extBlock, err := region.Eth.BlockChain().GetExternalBlockByHashAndContext(block.Hash(), params.ZONE)
+	if err != nil || extBlock == nil {
+		t.Fatalf("prefetched zone block missing: %v", err)
+	}
+	if extBlock.Header().Hash() != block.Hash() || len(extBlock.Transactions()) != len(block.Transactions()) {
+		t.Errorf("prefetched zone block mismatch: have %x, want %x", extBlock.Hash(), block.Hash())
+	}
+}
+
+// Tests that a zone tracks its supply from the genesis state, and that a zone
+// missing the supply of the parent of a block starts tracking it from its head
+// in the background instead of during the import.
+func TestSupplyTracking(t *testing.T) {
+	h := newTestHierarchy(t)
+	defer h.Close()
+
+	prime, region, zone := h.Prime, h.Regions[0], h.Zones[0][0]
+	chain, db := zone.Eth.BlockChain(), zone.Eth.ChainDb()
+	mine := func() *types.Block {
+		blocks := pendingWork(t, []*Instance{prime, region, zone})
+		header := manager.CombineHeaders([]*types.Header{blocks[0].Header(), blocks[1].Header(), blocks[2].Header()})
+		seal(t, blake3.NewContextFaker(params.ZONE), header, params.ZONE)
+
+		block := types.NewBlockWithHeader(header).WithBody(blocks[2].Transactions(), blocks[2].Uncles())
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("failed to import zone block: %v", err)
+		}
+		return block
+	}
+	first := mine()
+	genesis := rawdb.ReadSupply(db, chain.Genesis().Hash())
+	if genesis == nil || genesis.Number != 0 {
+		t.Fatalf("genesis supply mismatch: have %+v", genesis)
+	}
+	supply := rawdb.ReadSupply(db, first.Hash())
+	if supply == nil || supply.Number != 1 || supply.Base.Cmp(genesis.Base) != 0 {
+		t.Fatalf("block supply mismatch: have %+v, want base %v", supply, genesis.Base)
+	}
+	// The genesis block isn't rewarded, the reward of a block is paid by its child
+	if supply.Block.Issued.Sign() != 0 {
+		t.Errorf("block supply issued %v for the genesis block", supply.Block.Issued)
+	}
+	second := mine()
+	if supply = rawdb.ReadSupply(db, second.Hash()); supply == nil || supply.Block.Issued.Sign() <= 0 {
+		t.Fatalf("block supply issued nothing: have %+v", supply)
+	}
+	// Lose the supply records and restart tracking from the head
+	rawdb.DeleteSupply(db, genesis.Hash)
+	rawdb.DeleteSupply(db, first.Hash())
+	rawdb.DeleteSupply(db, second.Hash())
+
+	third := mine()
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+		if supply = rawdb.ReadSupply(db, third.Hash()); supply != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("supply tracking not restarted from the head")
+		}
+	}
+	if supply.Number != 3 || supply.Total.Issued.Sign() != 0 {
+		t.Errorf("restarted supply mismatch: have number %d, issued %v", supply.Number, supply.Total.Issued)
+	}
+	if supply.Base.Cmp(genesis.Base) <= 0 {
+		t.Errorf("restarted supply base %v doesn't hold the rewards issued since genesis %v", supply.Base, genesis.Base)
+	}
+	if rawdb.ReadSupply(db, first.Hash()) != nil || rawdb.ReadSupply(db, second.Hash()) != nil {
+		t.Errorf("supply recorded below the restarted head")
+	}
+}
+
+// Tests that a zone chain holding a region block is exported together with the
+// external blocks it references and imported into a zone without a dominant
+// chain.
+func TestExportImportOffline(t *testing.T) {
+	h := newTestHierarchy(t)
+	defer h.Close()
+
+	prime, region, zone := h.Prime, h.Regions[0], h.Zones[0][0]
+	blocks := pendingWork(t, []*Instance{prime, region, zone})
+	header := manager.CombineHeaders([]*types.Header{blocks[0].Header(), blocks[1].Header(), blocks[2].Header()})
+	seal(t, blake3.NewContextFaker(params.ZONE), header, params.REGION)
+
+	zoneExternal := types.NewExternalBlockWithHeader(header).WithBody(blocks[2].Transactions(), blocks[2].Uncles(), nil, big.NewInt(int64(params.ZONE)))
+	if err := region.Eth.BlockChain().AddExternalBlock(zoneExternal); err != nil {
+		t.Fatalf("failed to add zone external block: %v", err)
+	}
+	regionExternal := types.NewExternalBlockWithHeader(header).WithBody(blocks[1].Transactions(), blocks[1].Uncles(), nil, big.NewInt(int64(params.REGION)))
+	if err := zone.Eth.BlockChain().AddExternalBlock(regionExternal); err != nil {
+		t.Fatalf("failed to add region external block: %v", err)
+	}
+	block := types.NewBlockWithHeader(header).WithBody(blocks[2].Transactions(), blocks[2].Uncles())
+	if _, err := zone.Eth.BlockChain().InsertMinedBlock(context.Background(), block); err != nil {
+		t.Fatalf("failed to insert mined block: %v", err)
+	}
+	var export bytes.Buffer
+	if err := zone.Eth.BlockChain().ExportN(&export, 0, 1); err != nil {
+		t.Fatalf("failed to export chain: %v", err)
+	}
+
+	// Import the export into a zone whose dominant chain is unreachable
+	offline := newTestHierarchy(t)
+	defer offline.Close()
+
+	chain := offline.Zones[0][0].Eth.BlockChain()
+	chain.SetDomLink(nil)
+
+	reader, err := core.NewExportReader(&export)
+	if err != nil {
+		t.Fatalf("failed to open export: %v", err)
+	}
+	if err := reader.Verify(chain); err != nil {
+		t.Fatalf("failed to verify export: %v", err)
+	}
+	var exported []*core.ExportedBlock
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read export: %v", err)
+		}
+		if entry.Block.NumberU64(chain.Config().Context) != 0 {
+			exported = append(exported, entry)
+		}
+	}
+	if len(exported) != 1 || len(exported[0].ExternalBlocks) == 0 {
+		t.Fatalf("export content mismatch: have %d blocks", len(exported))
+	}
+	if _, err := chain.InsertExportedChain(exported); err != nil {
+		t.Fatalf("failed to import export: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != block.Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", head.Hash(), block.Hash())
+	}
+	if !rawdb.HasReceipts(offline.Zones[0][0].Eth.ChainDb(), block.Hash(), block.NumberU64(params.ZONE)) {
+		t.Errorf("receipts of imported block missing")
+	}
+	if extBlock, _ := chain.GetExternalBlockByHashAndContext(block.Hash(), params.REGION); extBlock == nil {
+		t.Errorf("bundled region block missing")
+	}
+}
+
+// Tests that the zone created by an ontology fork is started once the prime
+// chain reaches the fork block, with its genesis anchored to that block.
+func TestOntologyForkExpansion(t *testing.T) {
+	h := newTestHierarchy(t, &params.OntologyFork{Block: big.NewInt(1), Regions: 1, Zones: 2})
+	defer h.Close()
+
+	instances := []*Instance{h.Prime, h.Regions[0], h.Zones[0][0]}
+	blocks := pendingWork(t, instances)
+	header := manager.CombineHeaders([]*types.Header{blocks[0].Header(), blocks[1].Header(), blocks[2].Header()})
+	seal(t, blake3.NewContextFaker(params.ZONE), header, params.PRIME)
+
+	for i, instance := range instances {
+		for j := i + 1; j < len(instances); j++ {
+			external := types.NewExternalBlockWithHeader(header).WithBody(blocks[j].Transactions(), blocks[j].Uncles(), nil, big.NewInt(int64(j)))
+			if err := instance.Eth.BlockChain().AddExternalBlock(external); err != nil {
+				t.Fatalf("%s: failed to add external block: %v", instance.Name, err)
+			}
+		}
+	}
+	block := types.NewBlockWithHeader(header).WithBody(blocks[0].Transactions(), blocks[0].Uncles())
+	if _, err := h.Prime.Eth.BlockChain().InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to import fork block: %v", err)
+	}
+	var all []*Instance
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if all = h.Instances(); len(all) == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("instance count mismatch: have %d, want %d", len(all), 4)
+		}
+	}
+	zone := all[3]
+	if zone.Name != "zone-1-2" || zone.Context != params.ZONE {
+		t.Fatalf("forked instance mismatch: have %s in context %d", zone.Name, zone.Context)
+	}
+	config := zone.Eth.BlockChain().Config()
+	if want := params.MainnetOntology.BaseChainID + 102; config.ChainID.Uint64() != want {
+		t.Errorf("chain ID mismatch: have %v, want %d", config.ChainID, want)
+	}
+	genesis := zone.Eth.BlockChain().Genesis()
+	for _, ctx := range []int{params.PRIME, params.REGION} {
+		if parent := genesis.ParentHash(ctx); parent != block.Hash() {
+			t.Errorf("genesis parent mismatch in context %d: have %x, want %x", ctx, parent, block.Hash())
+		}
+	}
+	if config.GenesisHashes[params.ZONE] != genesis.Hash() {
+		t.Errorf("genesis hash mismatch: have %x, want %x", config.GenesisHashes[params.ZONE], genesis.Hash())
+	}
+	// Only the chains of the initial ontology exist before the fork
+	if config.ValidChainID(config.ChainID, common.Big0) || !config.ValidChainID(config.ChainID, common.Big1) {
+		t.Errorf("forked chain valid before its fork")
+	}
+}
+
+// Tests that debug_verifyPCRC verifies the chains of a zone block down to the
+// genesis, and that the searches for previous coincident blocks it runs are
+// indexed by the hash of the header they start from.
+func TestVerifyPCRC(t *testing.T) {
+	h := newTestHierarchy(t)
+	defer h.Close()
+
+	instances := []*Instance{h.Prime, h.Regions[0], h.Zones[0][0]}
+	blocks := pendingWork(t, instances)
+	header := manager.CombineHeaders([]*types.Header{blocks[0].Header(), blocks[1].Header(), blocks[2].Header()})
+	seal(t, blake3.NewContextFaker(params.ZONE), header, params.PRIME)
+
+	for i, instance := range instances {
+		for j := i + 1; j < len(instances); j++ {
+			external := types.NewExternalBlockWithHeader(header).WithBody(blocks[j].Transactions(), blocks[j].Uncles(), nil, big.NewInt(int64(j)))
+			if err := instance.Eth.BlockChain().AddExternalBlock(external); err != nil {
+				t.Fatalf("%s: failed to add external block: %v", instance.Name, err)
+			}
+		}
+	}
+	for i, instance := range instances {
+		block := types.NewBlockWithHeader(header).WithBody(blocks[i].Transactions(), blocks[i].Uncles())
+		if _, err := instance.Eth.BlockChain().InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("%s: failed to import block: %v", instance.Name, err)
+		}
+	}
+	zone := h.Zones[0][0]
+	client, err := zone.Stack.Attach()
+	if err != nil {
+		t.Fatalf("failed to attach to zone: %v", err)
+	}
+	defer client.Close()
+
+	var report core.PCRCReport
+	if err := client.Call(&report, "debug_verifyPCRC", header.Hash(), hexutil.Uint64(0)); err != nil {
+		t.Fatalf("failed to verify PCRC: %v", err)
+	}
+	genesis := zone.Eth.BlockChain().Genesis().Hash()
+	if report.Hash != header.Hash() || !report.Complete || report.Twisted != nil {
+		t.Fatalf("report mismatch: have %+v", report)
+	}
+	if report.Checked != 2 || report.Reached != genesis {
+		t.Errorf("verified chain mismatch: checked %d down to %x, want 2 down to %x", report.Checked, report.Reached, genesis)
+	}
+	if hash := rawdb.ReadPreviousCoincident(zone.Eth.ChainDb(), header.Hash(), header.Location, params.PRIME, params.ZONE, true); hash != genesis {
+		t.Errorf("indexed prime terminus mismatch: have %x, want %x", hash, genesis)
+	}
+	// The indexed searches are served again, verifying the same way
+	var again core.PCRCReport
+	if err := client.Call(&again, "debug_verifyPCRC", header.Hash(), hexutil.Uint64(0)); err != nil {
+		t.Fatalf("failed to verify PCRC again: %v", err)
+	}
+	if again != report {
+		t.Errorf("repeated report mismatch: have %+v, want %+v", again, report)
+	}
+	// Unknown headers aren't verified
+	if err := client.Call(&report, "debug_verifyPCRC", common.Hash{0x01}, hexutil.Uint64(0)); err == nil {
+		t.Errorf("unknown header verified")
+	}
+}
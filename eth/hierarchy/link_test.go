@@ -0,0 +1,112 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hierarchy
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/ethclient/quaiclient"
+	"github.com/spruce-solutions/go-quai/miner/manager"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// Tests that the in-memory link serves the chain it wraps.
+func TestMemoryLink(t *testing.T) {
+	testLink(t, func(instance *Instance) core.DomSubLink {
+		return core.NewMemoryLink(instance.Eth.BlockChain(), instance.Eth.EventMux())
+	})
+}
+
+// Tests that the client link serves the chain of the node it is connected to.
+func TestClientLink(t *testing.T) {
+	testLink(t, func(instance *Instance) core.DomSubLink {
+		client, err := instance.Stack.Attach()
+		if err != nil {
+			t.Fatalf("%s: failed to attach: %v", instance.Name, err)
+		}
+		return core.NewClientLink(quaiclient.NewClient(client))
+	})
+}
+
+func testLink(t *testing.T, newLink func(*Instance) core.DomSubLink) {
+	h := newTestHierarchy(t)
+	defer h.Close()
+
+	zone := h.Zones[0][0]
+	chain := zone.Eth.BlockChain()
+	link := newLink(zone)
+	defer link.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The genesis block is canonical and served as an external block
+	genesis := chain.Genesis()
+	if status := link.GetBlockStatus(ctx, genesis.Header()); status != core.CanonStatTy {
+		t.Fatalf("genesis status mismatch: have %v, want %v", status, core.CanonStatTy)
+	}
+	external, err := link.GetExternalBlock(ctx, genesis.Hash(), params.ZONE)
+	if err != nil {
+		t.Fatalf("failed to retrieve genesis as external block: %v", err)
+	}
+	if external.Hash() != genesis.Hash() {
+		t.Fatalf("external block hash mismatch: have %x, want %x", external.Hash(), genesis.Hash())
+	}
+	if external.Context().Cmp(big.NewInt(int64(params.ZONE))) != 0 {
+		t.Fatalf("external block context mismatch: have %v, want %d", external.Context(), params.ZONE)
+	}
+	if _, err := link.GetExternalBlock(ctx, common.Hash{1}, params.ZONE); err == nil {
+		t.Fatalf("unknown block retrieved as external block")
+	}
+
+	// A block mined through the link is imported and announced as the new head
+	heads := make(chan *types.Header, 1)
+	sub, err := link.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		t.Fatalf("failed to subscribe to new heads: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	work := pendingWork(t, []*Instance{h.Prime, h.Regions[0], zone})
+	header := manager.CombineHeaders([]*types.Header{work[0].Header(), work[1].Header(), work[2].Header()})
+	seal(t, blake3.NewContextFaker(params.ZONE), header, params.ZONE)
+	block := types.NewBlockWithHeader(header).WithBody(work[2].Transactions(), work[2].Uncles())
+	if status := link.GetBlockStatus(ctx, header); status != core.UnknownStatTy {
+		t.Fatalf("pending block status mismatch: have %v, want %v", status, core.UnknownStatTy)
+	}
+	if err := link.SendMinedBlock(ctx, block); err != nil {
+		t.Fatalf("failed to send mined block: %v", err)
+	}
+	select {
+	case head := <-heads:
+		if head.Hash() != block.Hash() {
+			t.Fatalf("new head mismatch: have %x, want %x", head.Hash(), block.Hash())
+		}
+	case <-ctx.Done():
+		t.Fatalf("new head not announced")
+	}
+	if status := link.GetBlockStatus(ctx, header); status != core.CanonStatTy {
+		t.Fatalf("mined block status mismatch: have %v, want %v", status, core.CanonStatTy)
+	}
+}
@@ -37,6 +37,13 @@ type blockPropagation struct {
 	extBlocks []*types.ExternalBlock
 }
 
+// blockAnnouncement is a block hash announcement, carrying the number of the
+// block in the context of the announcing chain.
+type blockAnnouncement struct {
+	hash   common.Hash
+	number uint64
+}
+
 // broadcastBlocks is a write loop that multiplexes blocks and block accouncements
 // to the remote peer. The goal is to have an async writer that does not lock up
 // node internals and at the same time rate limits queued data.
@@ -48,13 +55,13 @@ func (p *Peer) broadcastBlocks() {
 				return
 			}
 
-			p.Log().Info("Propagated block", "number", prop.block.Number(), "hash", prop.block.Hash(), "td", prop.td)
+			p.Log().Info("Propagated block", "number", prop.block.Header().Number, "hash", prop.block.Hash(), "td", prop.td)
 
-		case block := <-p.queuedBlockAnns:
-			if err := p.SendNewBlockHashes([]common.Hash{block.Hash()}, []uint64{block.NumberU64()}); err != nil {
+		case ann := <-p.queuedBlockAnns:
+			if err := p.SendNewBlockHashes([]common.Hash{ann.hash}, []uint64{ann.number}); err != nil {
 				return
 			}
-			p.Log().Info("Announced block", "number", block.Number(), "hash", block.Hash())
+			p.Log().Info("Announced block", "number", ann.number, "hash", ann.hash)
 
 		case <-p.term:
 			return
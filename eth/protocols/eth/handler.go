@@ -146,7 +146,7 @@ func nodeInfo(chain *core.BlockChain, network uint64) *NodeInfo {
 	head := chain.CurrentBlock()
 	return &NodeInfo{
 		Network:    network,
-		Difficulty: chain.GetTd(head.Hash(), head.NumberU64())[types.QuaiNetworkContext],
+		Difficulty: chain.GetTd(head.Hash(), head.NumberU64(chain.Config().Context))[chain.Config().Context],
 		Genesis:    chain.Genesis().Hash(),
 		Config:     chain.Config(),
 		Head:       head.Hash(),
@@ -190,6 +190,17 @@ var quai66 = map[uint64]msgHandler{
 	PooledTransactionsMsg:         handlePooledTransactions66,
 }
 
+// quai67 extends quai66 with the propagation of fault proofs.
+var quai67 = map[uint64]msgHandler{
+	FaultProofsMsg: handleFaultProofs,
+}
+
+func init() {
+	for code, handler := range quai66 {
+		quai67[code] = handler
+	}
+}
+
 // handleMessage is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
 func handleMessage(backend Backend, peer *Peer) error {
@@ -204,9 +215,9 @@ func handleMessage(backend Backend, peer *Peer) error {
 	defer msg.Discard()
 
 	var handlers = quai66
-	//if peer.Version() >= ETH67 { // Left in as a sample when new protocol is added
-	//	handlers = eth67
-	//}
+	if peer.Version() >= QUAI67 {
+		handlers = quai67
+	}
 
 	// Track the amount of time it takes to serve the request and run the handler
 	if metrics.Enabled {
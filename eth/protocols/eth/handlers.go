@@ -30,7 +30,7 @@ import (
 // IsCoincident returns true on a coincident block with a given hash
 func IsCoincident(backend Backend, hash common.Hash) bool {
 	order, _ := backend.Chain().Engine().GetDifficultyOrder(backend.Chain().GetHeaderByHash(hash))
-	return order < types.QuaiNetworkContext
+	return order < backend.Chain().Config().Context
 }
 
 // handleGetBlockHeaders66 is the eth/66 version of handleGetBlockHeaders
@@ -66,7 +66,7 @@ func answerGetBlockHeadersQuery(backend Backend, query *GetBlockHeadersPacket, p
 				first = false
 				origin = backend.Chain().GetHeaderByHash(query.Origin.Hash)
 				if origin != nil {
-					query.Origin.Number = origin.Number[types.QuaiNetworkContext].Uint64()
+					query.Origin.Number = origin.Number[backend.Chain().Config().Context].Uint64()
 				}
 			} else {
 				origin = backend.Chain().GetHeader(query.Origin.Hash, query.Origin.Number)
@@ -99,7 +99,7 @@ func answerGetBlockHeadersQuery(backend Backend, query *GetBlockHeadersPacket, p
 		case hashMode && !query.Reverse:
 			// Hash based traversal towards the leaf block
 			var (
-				current = origin.Number[types.QuaiNetworkContext].Uint64()
+				current = origin.Number[backend.Chain().Config().Context].Uint64()
 				next    = current + query.Skip + 1
 			)
 			if next <= current {
@@ -252,7 +252,7 @@ func answerGetReceiptsQuery(backend Backend, query GetReceiptsPacket, peer *Peer
 		// Retrieve the requested block's receipts
 		results := backend.Chain().GetReceiptsByHash(hash)
 		if results == nil {
-			if header := backend.Chain().GetHeaderByHash(hash); header == nil || header.ReceiptHash[types.QuaiNetworkContext] != types.EmptyRootHash[types.QuaiNetworkContext] {
+			if header := backend.Chain().GetHeaderByHash(hash); header == nil || header.ReceiptHash[backend.Chain().Config().Context] != types.EmptyRootHash[backend.Chain().Config().Context] {
 				continue
 			}
 		}
@@ -329,12 +329,13 @@ func handleNewBlock(backend Backend, msg Decoder, peer *Peer) error {
 	if err := ann.sanityCheck(); err != nil {
 		return err
 	}
-	if hash := types.CalcUncleHash(ann.Block.Uncles()); hash != ann.Block.UncleHash() {
-		log.Warn("Propagated block has invalid uncles", "have", hash, "exp", ann.Block.UncleHash())
+	context := backend.Chain().Config().Context
+	if hash := types.CalcUncleHash(ann.Block.Uncles()); hash != ann.Block.UncleHash(context) {
+		log.Warn("Propagated block has invalid uncles", "have", hash, "exp", ann.Block.UncleHash(context))
 		return nil // TODO(karalabe): return error eventually, but wait a few releases
 	}
-	if hash := types.DeriveSha(ann.Block.Transactions(), trie.NewStackTrie(nil)); hash != ann.Block.TxHash() {
-		log.Warn("Propagated block has invalid body", "have", hash, "exp", ann.Block.TxHash())
+	if hash := types.DeriveSha(ann.Block.Transactions(), trie.NewStackTrie(nil)); hash != ann.Block.TxHash(context) {
+		log.Warn("Propagated block has invalid body", "have", hash, "exp", ann.Block.TxHash(context))
 		return nil // TODO(karalabe): return error eventually, but wait a few releases
 	}
 	ann.Block.ReceivedAt = msg.Time()
@@ -484,6 +485,21 @@ func handleTransactions(backend Backend, msg Decoder, peer *Peer) error {
 	return backend.Handle(peer, &txs)
 }
 
+func handleFaultProofs(backend Backend, msg Decoder, peer *Peer) error {
+	// Fault proofs arrived, mark them known before verifying and storing them
+	var proofs FaultProofsPacket
+	if err := msg.Decode(&proofs); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	for i, proof := range proofs {
+		if proof == nil {
+			return fmt.Errorf("%w: fault proof %d is nil", errDecode, i)
+		}
+		peer.markFaultProof(proof.Hash())
+	}
+	return backend.Handle(peer, &proofs)
+}
+
 func handlePooledTransactions66(backend Backend, msg Decoder, peer *Peer) error {
 	// Transactions arrived, make sure we have a valid and fresh chain to handle them
 	if !backend.AcceptTxs() {
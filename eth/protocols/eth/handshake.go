@@ -23,7 +23,6 @@ import (
 
 	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/core/forkid"
-	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/p2p"
 )
 
@@ -70,8 +69,10 @@ func (p *Peer) Handshake(network uint64, td []*big.Int, head common.Hash, genesi
 
 	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
 	// larger, it will still fit within 100 bits
-	if tdlen := p.td[types.QuaiNetworkContext].BitLen(); tdlen > 100 {
-		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+	for _, td := range p.td {
+		if tdlen := td.BitLen(); tdlen > 100 {
+			return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+		}
 	}
 	return nil
 }
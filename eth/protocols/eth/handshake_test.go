@@ -40,7 +40,7 @@ func testHandshake(t *testing.T, protocol uint) {
 	var (
 		genesis = backend.chain.Genesis()
 		head    = backend.chain.CurrentBlock()
-		td      = backend.chain.GetTd(head.Hash(), head.NumberU64())
+		td      = backend.chain.GetTd(head.Hash(), head.NumberU64(types.QuaiNetworkContext))
 		forkID  = forkid.NewID(backend.chain.Config(), backend.chain.Genesis().Hash(), backend.chain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64())
 	)
 	tests := []struct {
@@ -38,6 +38,10 @@ const (
 	// before starting to randomly evict them.
 	maxKnownBlocks = 1024
 
+	// maxKnownFaultProofs is the maximum fault proof hashes to keep in the known
+	// list before starting to randomly evict them.
+	maxKnownFaultProofs = 1024
+
 	// maxQueuedTxs is the maximum number of transactions to queue up before dropping
 	// older broadcasts.
 	maxQueuedTxs = 4096
@@ -76,15 +80,17 @@ type Peer struct {
 	head common.Hash // Latest advertised head block hash
 	td   []*big.Int  // Latest advertised head block total difficulty
 
-	knownBlocks     *knownCache            // Set of block hashes known to be known by this peer
-	queuedBlocks    chan *blockPropagation // Queue of blocks to broadcast to the peer
-	queuedBlockAnns chan *types.Block      // Queue of blocks to announce to the peer
+	knownBlocks     *knownCache             // Set of block hashes known to be known by this peer
+	queuedBlocks    chan *blockPropagation  // Queue of blocks to broadcast to the peer
+	queuedBlockAnns chan *blockAnnouncement // Queue of blocks to announce to the peer
 
 	txpool      TxPool             // Transaction pool used by the broadcasters for liveness checks
 	knownTxs    *knownCache        // Set of transaction hashes known to be known by this peer
 	txBroadcast chan []common.Hash // Channel used to queue transaction propagation requests
 	txAnnounce  chan []common.Hash // Channel used to queue transaction announcement requests
 
+	knownFaultProofs *knownCache // Set of fault proof hashes known to be known by this peer
+
 	term chan struct{} // Termination channel to stop the broadcasters
 	lock sync.RWMutex  // Mutex protecting the internal fields
 }
@@ -93,18 +99,19 @@ type Peer struct {
 // version.
 func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Peer {
 	peer := &Peer{
-		id:              p.ID().String(),
-		Peer:            p,
-		rw:              rw,
-		version:         version,
-		knownTxs:        newKnownCache(maxKnownTxs),
-		knownBlocks:     newKnownCache(maxKnownBlocks),
-		queuedBlocks:    make(chan *blockPropagation, maxQueuedBlocks),
-		queuedBlockAnns: make(chan *types.Block, maxQueuedBlockAnns),
-		txBroadcast:     make(chan []common.Hash),
-		txAnnounce:      make(chan []common.Hash),
-		txpool:          txpool,
-		term:            make(chan struct{}),
+		id:               p.ID().String(),
+		Peer:             p,
+		rw:               rw,
+		version:          version,
+		knownTxs:         newKnownCache(maxKnownTxs),
+		knownBlocks:      newKnownCache(maxKnownBlocks),
+		knownFaultProofs: newKnownCache(maxKnownFaultProofs),
+		queuedBlocks:     make(chan *blockPropagation, maxQueuedBlocks),
+		queuedBlockAnns:  make(chan *blockAnnouncement, maxQueuedBlockAnns),
+		txBroadcast:      make(chan []common.Hash),
+		txAnnounce:       make(chan []common.Hash),
+		txpool:           txpool,
+		term:             make(chan struct{}),
 	}
 	// Start up all the broadcasters
 	go peer.broadcastBlocks()
@@ -160,6 +167,11 @@ func (p *Peer) KnownTransaction(hash common.Hash) bool {
 	return p.knownTxs.Contains(hash)
 }
 
+// KnownFaultProof returns whether peer is known to already have a fault proof.
+func (p *Peer) KnownFaultProof(hash common.Hash) bool {
+	return p.knownFaultProofs.Contains(hash)
+}
+
 // markBlock marks a block as known for the peer, ensuring that the block will
 // never be propagated to this particular peer.
 func (p *Peer) markBlock(hash common.Hash) {
@@ -174,6 +186,25 @@ func (p *Peer) markTransaction(hash common.Hash) {
 	p.knownTxs.Add(hash)
 }
 
+// markFaultProof marks a fault proof as known for the peer, ensuring that it
+// will never be propagated to this particular peer.
+func (p *Peer) markFaultProof(hash common.Hash) {
+	p.knownFaultProofs.Add(hash)
+}
+
+// SendFaultProofs sends fault proofs to the peer and includes the hashes in
+// its fault proof hash set for future reference. Peers speaking a protocol
+// version before quai/67 don't accept them.
+func (p *Peer) SendFaultProofs(proofs []*types.FaultProof) error {
+	if p.Version() < QUAI67 {
+		return errFaultProofsUnsupported
+	}
+	for _, proof := range proofs {
+		p.knownFaultProofs.Add(proof.Hash())
+	}
+	return p2p.Send(p.rw, FaultProofsMsg, proofs)
+}
+
 // SendTransactions sends transactions to the peer and includes the hashes
 // in its transaction hash set for future reference.
 //
@@ -256,15 +287,15 @@ func (p *Peer) SendNewBlockHashes(hashes []common.Hash, numbers []uint64) error
 }
 
 // AsyncSendNewBlockHash queues the availability of a block for propagation to a
-// remote peer. If the peer's broadcast queue is full, the event is silently
-// dropped.
-func (p *Peer) AsyncSendNewBlockHash(block *types.Block) {
+// remote peer, announcing it with its number in the local chain's context. If
+// the peer's broadcast queue is full, the event is silently dropped.
+func (p *Peer) AsyncSendNewBlockHash(block *types.Block, context int) {
 	select {
-	case p.queuedBlockAnns <- block:
+	case p.queuedBlockAnns <- &blockAnnouncement{hash: block.Hash(), number: block.NumberU64(context)}:
 		// Mark all the block hash as known, but ensure we don't overflow our limits
 		p.knownBlocks.Add(block.Hash())
 	default:
-		p.Log().Debug("Dropping block announcement", "number", block.NumberU64(), "hash", block.Hash())
+		p.Log().Debug("Dropping block announcement", "number", block.NumberU64(context), "hash", block.Hash())
 	}
 }
 
@@ -306,7 +337,7 @@ func (p *Peer) AsyncSendNewBlock(block *types.Block, td []*big.Int, extBlocks []
 		// Mark all the block hash as known, but ensure we don't overflow our limits
 		p.knownBlocks.Add(block.Hash())
 	default:
-		p.Log().Debug("Dropping block propagation", "number", block.NumberU64(), "hash", block.Hash())
+		p.Log().Debug("Dropping block propagation", "number", block.Header().Number, "hash", block.Hash())
 	}
 }
 
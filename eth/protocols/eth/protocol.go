@@ -31,6 +31,7 @@ import (
 // Constants to match up protocol versions and messages
 const (
 	QUAI66 = 66
+	QUAI67 = 67
 )
 
 // ProtocolName is the official short name of the `eth` protocol used during
@@ -39,11 +40,11 @@ const ProtocolName = "quai"
 
 // ProtocolVersions are the supported versions of the `eth` protocol (first
 // is primary).
-var ProtocolVersions = []uint{QUAI66}
+var ProtocolVersions = []uint{QUAI67, QUAI66}
 
 // protocolLengths are the number of implemented message corresponding to
 // different protocol versions.
-var protocolLengths = map[uint]uint64{QUAI66: 19}
+var protocolLengths = map[uint]uint64{QUAI67: 20, QUAI66: 19}
 
 // maxMessageSize is the maximum cap on the size of a protocol message.
 const maxMessageSize = 10 * 1024 * 1024
@@ -65,6 +66,9 @@ const (
 	GetExtBlocksMsg    = 0x11
 	ExtBlocksMsg       = 0x12
 
+	// Protocol messages added in quai/67
+	FaultProofsMsg = 0x13
+
 	// Protocol messages overloaded in eth/65
 	NewPooledTransactionHashesMsg = 0x08
 	GetPooledTransactionsMsg      = 0x09
@@ -80,6 +84,7 @@ var (
 	errNetworkIDMismatch       = errors.New("network ID mismatch")
 	errGenesisMismatch         = errors.New("genesis mismatch")
 	errForkIDRejected          = errors.New("fork ID rejected")
+	errFaultProofsUnsupported  = errors.New("fault proofs unsupported by protocol version")
 )
 
 // Packet represents a p2p message in the `eth` protocol.
@@ -194,8 +199,10 @@ func (request *NewBlockPacket) sanityCheck() error {
 	}
 	//TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
 	// larger, it will still fit within 100 bits
-	if tdlen := request.TD[types.QuaiNetworkContext].BitLen(); tdlen > 100 {
-		return fmt.Errorf("too large block TD: bitlen %d", tdlen)
+	for _, td := range request.TD {
+		if tdlen := td.BitLen(); tdlen > 100 {
+			return fmt.Errorf("too large block TD: bitlen %d", tdlen)
+		}
 	}
 
 	// TODO #86
@@ -322,6 +329,9 @@ type ExtBlocksRLPPacket66 struct {
 	ExtBlocksRLPPacket
 }
 
+// FaultProofsPacket is the network packet for fault proof propagation.
+type FaultProofsPacket []*types.FaultProof
+
 // NewPooledTransactionHashesPacket represents a transaction announcement packet.
 type NewPooledTransactionHashesPacket []common.Hash
 
@@ -394,6 +404,9 @@ func (*GetExtBlocksPacket) Kind() byte   { return GetExtBlocksMsg }
 func (*ExtBlocksPacket) Name() string { return "ExtBlocks" }
 func (*ExtBlocksPacket) Kind() byte   { return ExtBlocksMsg }
 
+func (*FaultProofsPacket) Name() string { return "FaultProofs" }
+func (*FaultProofsPacket) Kind() byte   { return FaultProofsMsg }
+
 func (*NewPooledTransactionHashesPacket) Name() string { return "NewPooledTransactionHashes" }
 func (*NewPooledTransactionHashesPacket) Kind() byte   { return NewPooledTransactionHashesMsg }
 
@@ -50,11 +50,12 @@ func (eth *Ethereum) StateAtBlock(block *types.Block, reexec uint64, base *state
 		current  *types.Block
 		database state.Database
 		report   = true
-		origin   = block.NumberU64()
+		context  = eth.blockchain.Config().Context
+		origin   = block.NumberU64(context)
 	)
 	// Check the live database first if we have the state fully available, use that.
 	if checkLive {
-		statedb, err = eth.blockchain.StateAt(block.Root())
+		statedb, err = eth.blockchain.StateAt(block.Root(context))
 		if err == nil {
 			return statedb, nil
 		}
@@ -64,14 +65,14 @@ func (eth *Ethereum) StateAtBlock(block *types.Block, reexec uint64, base *state
 			// Create an ephemeral trie.Database for isolating the live one. Otherwise
 			// the internal junks created by tracing will be persisted into the disk.
 			database = state.NewDatabaseWithConfig(eth.chainDb, &trie.Config{Cache: 16})
-			if statedb, err = state.New(block.Root(), database, nil); err == nil {
-				log.Info("Found disk backend for state trie", "root", block.Root(), "number", block.Number())
+			if statedb, err = state.New(block.Root(context), database, nil); err == nil {
+				log.Info("Found disk backend for state trie", "root", block.Root(context), "number", block.Number(context))
 				return statedb, nil
 			}
 		}
 		// The optional base statedb is given, mark the start point as parent block
 		statedb, database, report = base, base.Database(), false
-		current = eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		current = eth.blockchain.GetBlock(block.ParentHash(context), block.NumberU64(context)-1)
 	} else {
 		// Otherwise try to reexec blocks until we find a state or reach our limit
 		current = block
@@ -84,23 +85,23 @@ func (eth *Ethereum) StateAtBlock(block *types.Block, reexec uint64, base *state
 		// we would rewind past a persisted block (specific corner case is chain
 		// tracing from the genesis).
 		if !checkLive {
-			statedb, err = state.New(current.Root(), database, nil)
+			statedb, err = state.New(current.Root(context), database, nil)
 			if err == nil {
 				return statedb, nil
 			}
 		}
 		// Database does not have the state for the given block, try to regenerate
 		for i := uint64(0); i < reexec; i++ {
-			if current.NumberU64() == 0 {
+			if current.NumberU64(context) == 0 {
 				return nil, errors.New("genesis state is missing")
 			}
-			parent := eth.blockchain.GetBlock(current.ParentHash(), current.NumberU64()-1)
+			parent := eth.blockchain.GetBlock(current.ParentHash(context), current.NumberU64(context)-1)
 			if parent == nil {
-				return nil, fmt.Errorf("missing block %v %d", current.ParentHash(), current.NumberU64()-1)
+				return nil, fmt.Errorf("missing block %v %d", current.ParentHash(context), current.NumberU64(context)-1)
 			}
 			current = parent
 
-			statedb, err = state.New(current.Root(), database, nil)
+			statedb, err = state.New(current.Root(context), database, nil)
 			if err == nil {
 				break
 			}
@@ -120,30 +121,30 @@ func (eth *Ethereum) StateAtBlock(block *types.Block, reexec uint64, base *state
 		logged time.Time
 		parent common.Hash
 	)
-	for current.NumberU64() < origin {
+	for current.NumberU64(context) < origin {
 		// Print progress logs if long enough time elapsed
 		if time.Since(logged) > 8*time.Second && report {
-			log.Info("Regenerating historical state", "block", current.NumberU64()+1, "target", origin, "remaining", origin-current.NumberU64()-1, "elapsed", time.Since(start))
+			log.Info("Regenerating historical state", "block", current.NumberU64(context)+1, "target", origin, "remaining", origin-current.NumberU64(context)-1, "elapsed", time.Since(start))
 			logged = time.Now()
 		}
 		// Retrieve the next block to regenerate and process it
-		next := current.NumberU64() + 1
+		next := current.NumberU64(context) + 1
 		if current = eth.blockchain.GetBlockByNumber(next); current == nil {
 			return nil, fmt.Errorf("block #%d not found", next)
 		}
 		_, _, _, _, err := eth.blockchain.Processor().Process(current, statedb, vm.Config{})
 		if err != nil {
-			return nil, fmt.Errorf("processing block %d failed: %v", current.NumberU64(), err)
+			return nil, fmt.Errorf("processing block %d failed: %v", current.NumberU64(context), err)
 		}
 		// Finalize the state so any modifications are written to the trie
-		root, err := statedb.Commit(eth.blockchain.Config().IsEIP158(current.Number()))
+		root, err := statedb.Commit(eth.blockchain.Config().IsEIP158(current.Number(context)))
 		if err != nil {
 			return nil, fmt.Errorf("stateAtBlock commit failed, number %d root %v: %w",
-				current.NumberU64(), current.Root().Hex(), err)
+				current.NumberU64(context), current.Root(context).Hex(), err)
 		}
 		statedb, err = state.New(root, database, nil)
 		if err != nil {
-			return nil, fmt.Errorf("state reset after block %d failed: %v", current.NumberU64(), err)
+			return nil, fmt.Errorf("state reset after block %d failed: %v", current.NumberU64(context), err)
 		}
 		database.TrieDB().Reference(root, common.Hash{})
 		if parent != (common.Hash{}) {
@@ -153,21 +154,23 @@ func (eth *Ethereum) StateAtBlock(block *types.Block, reexec uint64, base *state
 	}
 	if report {
 		nodes, imgs := database.TrieDB().Size()
-		log.Info("Historical state regenerated", "block", current.NumberU64(), "elapsed", time.Since(start), "nodes", nodes, "preimages", imgs)
+		log.Info("Historical state regenerated", "block", current.NumberU64(context), "elapsed", time.Since(start), "nodes", nodes, "preimages", imgs)
 	}
 	return statedb, nil
 }
 
 // stateAtTransaction returns the execution environment of a certain transaction.
 func (eth *Ethereum) stateAtTransaction(block *types.Block, txIndex int, reexec uint64) (core.Message, vm.BlockContext, *state.StateDB, error) {
+	context := eth.blockchain.Config().Context
+
 	// Short circuit if it's genesis block.
-	if block.NumberU64() == 0 {
+	if block.NumberU64(context) == 0 {
 		return nil, vm.BlockContext{}, nil, errors.New("no transaction in genesis")
 	}
 	// Create the parent state database
-	parent := eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	parent := eth.blockchain.GetBlock(block.ParentHash(context), block.NumberU64(context)-1)
 	if parent == nil {
-		return nil, vm.BlockContext{}, nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+		return nil, vm.BlockContext{}, nil, fmt.Errorf("parent %#x not found", block.ParentHash(context))
 	}
 	// Lookup the statedb of parent block from the live database,
 	// otherwise regenerate it on the flight.
@@ -179,24 +182,24 @@ func (eth *Ethereum) stateAtTransaction(block *types.Block, txIndex int, reexec
 		return nil, vm.BlockContext{}, statedb, nil
 	}
 	// Recompute transactions up to the target index.
-	signer := types.MakeSigner(eth.blockchain.Config(), block.Number())
+	signer := types.MakeSigner(eth.blockchain.Config(), block.Number(context))
 	for idx, tx := range block.Transactions() {
 		// Assemble the transaction call message and return if the requested offset
-		msg, _ := tx.AsMessage(signer, block.BaseFee())
+		msg, _ := tx.AsMessage(signer, block.BaseFee(context))
 		txContext := core.NewEVMTxContext(msg)
-		context := core.NewEVMBlockContext(block.Header(), eth.blockchain, nil)
+		blockContext := core.NewEVMBlockContext(block.Header(), eth.blockchain, nil, context)
 		if idx == txIndex {
-			return msg, context, statedb, nil
+			return msg, blockContext, statedb, nil
 		}
 		// Not yet the searched for transaction, execute on top of the current state
-		vmenv := vm.NewEVM(context, txContext, statedb, eth.blockchain.Config(), vm.Config{})
+		vmenv := vm.NewEVM(blockContext, txContext, statedb, eth.blockchain.Config(), vm.Config{})
 		statedb.Prepare(tx.Hash(), idx)
 		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
 			return nil, vm.BlockContext{}, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
 		}
 		// Ensure any modifications are committed to the state
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
+		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number(context)))
 	}
 	return nil, vm.BlockContext{}, nil, fmt.Errorf("transaction index %d out of range for block %#x", txIndex, block.Hash())
 }
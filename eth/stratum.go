@@ -0,0 +1,76 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/eth/ethconfig"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/miner/stratum"
+)
+
+// stratumSubmitTimeout bounds the delivery of a block found by a stratum miner
+// to the dominant chains.
+const stratumSubmitTimeout = 10 * time.Second
+
+// stratumBackend serves the work of the local miner to the stratum server.
+type stratumBackend struct {
+	eth *Ethereum
+}
+
+func (b *stratumBackend) SubscribePendingHeader(ch chan<- *types.Header) event.Subscription {
+	return b.eth.miner.SubscribePendingBlock(ch)
+}
+
+func (b *stratumBackend) PendingBlock(sealHash common.Hash) *types.Block {
+	return b.eth.miner.PendingWork(sealHash)
+}
+
+// SubmitBlock inserts a block sealed by a stratum miner. A block of a dominant
+// order is delivered to every dominant chain from its order down to this one,
+// which relay it through their dominant links.
+func (b *stratumBackend) SubmitBlock(block *types.Block, order int) error {
+	log.Info("Stratum miner sealed block", "number", block.Number(b.eth.blockchain.Config().Context), "hash", block.Hash(), "order", order)
+	ctx, cancel := context.WithTimeout(context.Background(), stratumSubmitTimeout)
+	defer cancel()
+	if _, err := b.eth.blockchain.InsertMinedBlock(ctx, block); err != nil {
+		return err
+	}
+	b.eth.eventMux.Post(core.NewMinedBlockEvent{Block: block})
+	return nil
+}
+
+// newStratumServer creates the stratum server configured for the miner, or nil
+// if neither protocol is enabled.
+func newStratumServer(eth *Ethereum, config *ethconfig.Config) *stratum.Server {
+	if config.Miner.Stratum == "" && config.Miner.StratumV2 == "" {
+		return nil
+	}
+	stratumConfig := stratum.DefaultConfig
+	stratumConfig.ListenAddr = config.Miner.Stratum
+	stratumConfig.ListenAddrV2 = config.Miner.StratumV2
+	if config.Miner.StratumDifficulty != 0 {
+		stratumConfig.Difficulty = config.Miner.StratumDifficulty
+	}
+	return stratum.New(stratumConfig, eth.engine, &stratumBackend{eth})
+}
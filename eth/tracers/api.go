@@ -90,6 +90,10 @@ func (context *chainContext) Engine() consensus.Engine {
 	return context.api.backend.Engine()
 }
 
+func (context *chainContext) Config() *params.ChainConfig {
+	return context.api.backend.ChainConfig()
+}
+
 func (context *chainContext) GetHeader(hash common.Hash, number uint64) *types.Header {
 	header, err := context.api.backend.HeaderByNumber(context.ctx, rpc.BlockNumber(number))
 	if err != nil {
@@ -219,7 +223,7 @@ func (api *API) TraceChain(ctx context.Context, start, end rpc.BlockNumber, conf
 	if err != nil {
 		return nil, err
 	}
-	if from.Number().Cmp(to.Number()) >= 0 {
+	if from.Number(api.backend.ChainConfig().Context).Cmp(to.Number(api.backend.ChainConfig().Context)) >= 0 {
 		return nil, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", end, start)
 	}
 	return api.traceChain(ctx, from, to, config)
@@ -242,7 +246,7 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	blocks := int(end.NumberU64() - start.NumberU64())
+	blocks := int(end.NumberU64(api.backend.ChainConfig().Context) - start.NumberU64(api.backend.ChainConfig().Context))
 	threads := runtime.NumCPU()
 	if threads > blocks {
 		threads = blocks
@@ -260,11 +264,11 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 
 			// Fetch and execute the next block trace tasks
 			for task := range tasks {
-				signer := types.MakeSigner(api.backend.ChainConfig(), task.block.Number())
-				blockCtx := core.NewEVMBlockContext(task.block.Header(), api.chainContext(localctx), nil)
+				signer := types.MakeSigner(api.backend.ChainConfig(), task.block.Number(api.backend.ChainConfig().Context))
+				blockCtx := core.NewEVMBlockContext(task.block.Header(), api.chainContext(localctx), nil, api.backend.ChainConfig().Context)
 				// Trace all the transactions contained within
 				for i, tx := range task.block.Transactions() {
-					msg, _ := tx.AsMessage(signer, task.block.BaseFee())
+					msg, _ := tx.AsMessage(signer, task.block.BaseFee(api.backend.ChainConfig().Context))
 					txctx := &Context{
 						BlockHash: task.block.Hash(),
 						TxIndex:   i,
@@ -273,11 +277,11 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 					res, err := api.traceTx(localctx, msg, txctx, blockCtx, task.statedb, config)
 					if err != nil {
 						task.results[i] = &txTraceResult{Error: err.Error()}
-						log.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(), "err", err)
+						log.Warn("Tracing failed", "hash", tx.Hash(), "block", task.block.NumberU64(api.backend.ChainConfig().Context), "err", err)
 						break
 					}
 					// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-					task.statedb.Finalise(api.backend.ChainConfig().IsEIP158(task.block.Number()))
+					task.statedb.Finalise(api.backend.ChainConfig().IsEIP158(task.block.Number(api.backend.ChainConfig().Context)))
 					task.results[i] = &txTraceResult{Result: res}
 				}
 				// Stream the result back to the user or abort on teardown
@@ -312,16 +316,16 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 
 			switch {
 			case failed != nil:
-				log.Warn("Chain tracing failed", "start", start.NumberU64(), "end", end.NumberU64(), "transactions", traced, "elapsed", time.Since(begin), "err", failed)
-			case number < end.NumberU64():
-				log.Warn("Chain tracing aborted", "start", start.NumberU64(), "end", end.NumberU64(), "abort", number, "transactions", traced, "elapsed", time.Since(begin))
+				log.Warn("Chain tracing failed", "start", start.NumberU64(api.backend.ChainConfig().Context), "end", end.NumberU64(api.backend.ChainConfig().Context), "transactions", traced, "elapsed", time.Since(begin), "err", failed)
+			case number < end.NumberU64(api.backend.ChainConfig().Context):
+				log.Warn("Chain tracing aborted", "start", start.NumberU64(api.backend.ChainConfig().Context), "end", end.NumberU64(api.backend.ChainConfig().Context), "abort", number, "transactions", traced, "elapsed", time.Since(begin))
 			default:
-				log.Info("Chain tracing finished", "start", start.NumberU64(), "end", end.NumberU64(), "transactions", traced, "elapsed", time.Since(begin))
+				log.Info("Chain tracing finished", "start", start.NumberU64(api.backend.ChainConfig().Context), "end", end.NumberU64(api.backend.ChainConfig().Context), "transactions", traced, "elapsed", time.Since(begin))
 			}
 			close(results)
 		}()
 		// Feed all the blocks both into the tracer, as well as fast process concurrently
-		for number = start.NumberU64(); number < end.NumberU64(); number++ {
+		for number = start.NumberU64(api.backend.ChainConfig().Context); number < end.NumberU64(api.backend.ChainConfig().Context); number++ {
 			// Stop tracing if interruption was requested
 			select {
 			case <-notifier.Closed():
@@ -339,7 +343,7 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 			// Print progress logs if long enough time elapsed
 			if time.Since(logged) > 8*time.Second {
 				logged = time.Now()
-				log.Info("Tracing chain segment", "start", start.NumberU64(), "end", end.NumberU64(), "current", number, "transactions", traced, "elapsed", time.Since(begin))
+				log.Info("Tracing chain segment", "start", start.NumberU64(api.backend.ChainConfig().Context), "end", end.NumberU64(api.backend.ChainConfig().Context), "current", number, "transactions", traced, "elapsed", time.Since(begin))
 			}
 			// Retrieve the parent state to trace on top
 			block, err := api.blockByNumber(localctx, rpc.BlockNumber(number))
@@ -356,14 +360,14 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 			}
 			if statedb.Database().TrieDB() != nil {
 				// Hold the reference for tracer, will be released at the final stage
-				statedb.Database().TrieDB().Reference(block.Root(), common.Hash{})
+				statedb.Database().TrieDB().Reference(block.Root(api.backend.ChainConfig().Context), common.Hash{})
 
 				// Release the parent state because it's already held by the tracer
 				if parent != (common.Hash{}) {
 					statedb.Database().TrieDB().Dereference(parent)
 				}
 			}
-			parent = block.Root()
+			parent = block.Root(api.backend.ChainConfig().Context)
 
 			next, err := api.blockByNumber(localctx, rpc.BlockNumber(number+1))
 			if err != nil {
@@ -373,7 +377,7 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 			// Send the block over to the concurrent tracers (if not in the fast-forward phase)
 			txs := next.Transactions()
 			select {
-			case tasks <- &blockTraceTask{statedb: statedb.Copy(), block: next, rootref: block.Root(), results: make([]*txTraceResult, len(txs))}:
+			case tasks <- &blockTraceTask{statedb: statedb.Copy(), block: next, rootref: block.Root(api.backend.ChainConfig().Context), results: make([]*txTraceResult, len(txs))}:
 			case <-notifier.Closed():
 				return
 			}
@@ -385,12 +389,12 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 	go func() {
 		var (
 			done = make(map[uint64]*blockTraceResult)
-			next = start.NumberU64() + 1
+			next = start.NumberU64(api.backend.ChainConfig().Context) + 1
 		)
 		for res := range results {
 			// Queue up next received result
 			result := &blockTraceResult{
-				Block:  hexutil.Uint64(res.block.NumberU64()),
+				Block:  hexutil.Uint64(res.block.NumberU64(api.backend.ChainConfig().Context)),
 				Hash:   res.block.Hash(),
 				Traces: res.results,
 			}
@@ -401,7 +405,7 @@ func (api *API) traceChain(ctx context.Context, start, end *types.Block, config
 			derefsMu.Unlock()
 			// Stream completed traces to the user, aborting on the first error
 			for result, ok := done[next]; ok; result, ok = done[next] {
-				if len(result.Traces) > 0 || next == end.NumberU64() {
+				if len(result.Traces) > 0 || next == end.NumberU64(api.backend.ChainConfig().Context) {
 					notifier.Notify(sub.ID, result)
 				}
 				delete(done, next)
@@ -485,10 +489,10 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 	if block == nil {
 		return nil, fmt.Errorf("block %#x not found", hash)
 	}
-	if block.NumberU64() == 0 {
+	if block.NumberU64(api.backend.ChainConfig().Context) == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
-	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64(api.backend.ChainConfig().Context)-1), block.ParentHash(api.backend.ChainConfig().Context))
 	if err != nil {
 		return nil, err
 	}
@@ -502,14 +506,14 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 	}
 	var (
 		roots              []common.Hash
-		signer             = types.MakeSigner(api.backend.ChainConfig(), block.Number())
+		signer             = types.MakeSigner(api.backend.ChainConfig(), block.Number(api.backend.ChainConfig().Context))
 		chainConfig        = api.backend.ChainConfig()
-		vmctx              = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
-		deleteEmptyObjects = chainConfig.IsEIP158(block.Number())
+		vmctx              = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil, api.backend.ChainConfig().Context)
+		deleteEmptyObjects = chainConfig.IsEIP158(block.Number(api.backend.ChainConfig().Context))
 	)
 	for i, tx := range block.Transactions() {
 		var (
-			msg, _    = tx.AsMessage(signer, block.BaseFee())
+			msg, _    = tx.AsMessage(signer, block.BaseFee(api.backend.ChainConfig().Context))
 			txContext = core.NewEVMTxContext(msg)
 			vmenv     = vm.NewEVM(vmctx, txContext, statedb, chainConfig, vm.Config{})
 		)
@@ -546,10 +550,10 @@ func (api *API) StandardTraceBadBlockToFile(ctx context.Context, hash common.Has
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requestd tracer.
 func (api *API) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
-	if block.NumberU64() == 0 {
+	if block.NumberU64(api.backend.ChainConfig().Context) == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
-	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64(api.backend.ChainConfig().Context)-1), block.ParentHash(api.backend.ChainConfig().Context))
 	if err != nil {
 		return nil, err
 	}
@@ -563,7 +567,7 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	}
 	// Execute all the transaction contained within the block concurrently
 	var (
-		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number())
+		signer  = types.MakeSigner(api.backend.ChainConfig(), block.Number(api.backend.ChainConfig().Context))
 		txs     = block.Transactions()
 		results = make([]*txTraceResult, len(txs))
 
@@ -574,7 +578,7 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if threads > len(txs) {
 		threads = len(txs)
 	}
-	blockCtx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+	blockCtx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil, api.backend.ChainConfig().Context)
 	blockHash := block.Hash()
 	for th := 0; th < threads; th++ {
 		pend.Add(1)
@@ -582,7 +586,7 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 			defer pend.Done()
 			// Fetch and execute the next transaction trace tasks
 			for task := range jobs {
-				msg, _ := txs[task.index].AsMessage(signer, block.BaseFee())
+				msg, _ := txs[task.index].AsMessage(signer, block.BaseFee(api.backend.ChainConfig().Context))
 				txctx := &Context{
 					BlockHash: blockHash,
 					TxIndex:   task.index,
@@ -604,7 +608,7 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
 
 		// Generate the next state snapshot fast without tracing
-		msg, _ := tx.AsMessage(signer, block.BaseFee())
+		msg, _ := tx.AsMessage(signer, block.BaseFee(api.backend.ChainConfig().Context))
 		statedb.Prepare(tx.Hash(), i)
 		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{})
 		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
@@ -613,7 +617,7 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 		}
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
+		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number(api.backend.ChainConfig().Context)))
 	}
 	close(jobs)
 	pend.Wait()
@@ -635,10 +639,10 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 			return nil, fmt.Errorf("transaction %#x not found in block", config.TxHash)
 		}
 	}
-	if block.NumberU64() == 0 {
+	if block.NumberU64(api.backend.ChainConfig().Context) == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
-	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64(api.backend.ChainConfig().Context)-1), block.ParentHash(api.backend.ChainConfig().Context))
 	if err != nil {
 		return nil, err
 	}
@@ -664,9 +668,9 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 	// Execute transaction, either tracing all or just the requested one
 	var (
 		dumps       []string
-		signer      = types.MakeSigner(api.backend.ChainConfig(), block.Number())
+		signer      = types.MakeSigner(api.backend.ChainConfig(), block.Number(api.backend.ChainConfig().Context))
 		chainConfig = api.backend.ChainConfig()
-		vmctx       = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		vmctx       = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil, api.backend.ChainConfig().Context)
 		canon       = true
 	)
 	// Check if there are any overrides: the caller may wish to enable a future
@@ -689,7 +693,7 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 	for i, tx := range block.Transactions() {
 		// Prepare the trasaction for un-traced execution
 		var (
-			msg, _    = tx.AsMessage(signer, block.BaseFee())
+			msg, _    = tx.AsMessage(signer, block.BaseFee(api.backend.ChainConfig().Context))
 			txContext = core.NewEVMTxContext(msg)
 			vmConf    vm.Config
 			dump      *os.File
@@ -733,7 +737,7 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 		}
 		// Finalize the state so any modifications are written to the trie
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
-		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
+		statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number(api.backend.ChainConfig().Context)))
 
 		// If we've traced the transaction we were looking for, abort
 		if tx.Hash() == txHash {
@@ -821,11 +825,11 @@ func (api *API) TraceCall(ctx context.Context, args ethapi.TransactionArgs, bloc
 		}
 	}
 	// Execute the trace
-	msg, err := args.ToMessage(api.backend.RPCGasCap(), block.BaseFee())
+	msg, err := args.ToMessage(api.backend.RPCGasCap(), block.BaseFee(api.backend.ChainConfig().Context))
 	if err != nil {
 		return nil, err
 	}
-	vmctx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+	vmctx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil, api.backend.ChainConfig().Context)
 
 	var traceConfig *TraceConfig
 	if config != nil {
@@ -30,6 +30,7 @@ import (
 	"github.com/spruce-solutions/go-quai/core"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/internal/ethapi"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rpc"
 )
 
@@ -644,13 +645,14 @@ func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) er
 	return ec.c.CallContext(ctx, nil, "quai_sendRawTransaction", hexutil.Encode(data))
 }
 
-// SendMinedBlock sends a mined block back to the node
+// SendMinedBlock sends a mined block back to the node. The transactions of a
+// mined block are numbered in the zone that carries them.
 func (ec *Client) SendMinedBlock(ctx context.Context, block *types.Block, inclTx bool, fullTx bool) error {
-	data, err := ethapi.RPCMarshalBlock(block, inclTx, fullTx)
+	data, err := ethapi.RPCMarshalBlock(block, inclTx, fullTx, params.ZONE)
 	if err != nil {
 		return err
 	}
-	return ec.c.CallContext(ctx, nil, "quai_sendMinedBlock", data)
+	return ec.c.CallContext(ctx, nil, "domsub_sendMinedBlock", data)
 }
 
 // SendExternalBlock sends an external block back to the node to add to it's external block list
@@ -659,7 +661,7 @@ func (ec *Client) SendExternalBlock(ctx context.Context, block *types.Block, rec
 	if err != nil {
 		return err
 	}
-	return ec.c.CallContext(ctx, nil, "quai_sendExternalBlock", data)
+	return ec.c.CallContext(ctx, nil, "domsub_sendExternalBlock", data)
 }
 
 // GetExternalBlockByHashAndContext searches the cache for external block
@@ -688,7 +690,7 @@ func (ec *Client) SendReOrgData(ctx context.Context, header *types.Header, newHe
 	if err != nil {
 		return err
 	}
-	return ec.c.CallContext(ctx, nil, "quai_sendReOrgData", data)
+	return ec.c.CallContext(ctx, nil, "domsub_sendReOrgData", data)
 }
 
 func toBlockNumArg(number *big.Int) string {
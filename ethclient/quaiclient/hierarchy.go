@@ -0,0 +1,324 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quaiclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	quai "github.com/spruce-solutions/go-quai"
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+var (
+	// ErrUnknownLocation is returned when no endpoint is configured for a location.
+	ErrUnknownLocation = errors.New("no endpoint configured for location")
+
+	// ErrUnknownAddress is returned when no configured chain owns an address.
+	ErrUnknownAddress = errors.New("no endpoint owns address")
+
+	// ErrMissingSecret is returned when discovering a hierarchy without the
+	// secret of the authenticated endpoints.
+	ErrMissingSecret = errors.New("secret of the authenticated endpoints required")
+)
+
+// HierarchyConfig contains the endpoints of every chain in the hierarchy.
+// Regions are indexed by region and Zones by region and zone, both starting
+// from the first region and zone. Chains without an endpoint are skipped.
+type HierarchyConfig struct {
+	Prime   string
+	Regions []string
+	Zones   [][]string
+
+	// Secret is the secret shared by the authenticated endpoints of the nodes.
+	// If set, every endpoint is dialed with a JWT signed with it.
+	Secret []byte `json:"-"`
+}
+
+// HierarchyHeader is a header received from one of the chains of the hierarchy.
+type HierarchyHeader struct {
+	Header   *types.Header
+	Location []byte // Location of the chain the header was received from
+	Context  int    // Context of the chain the header was received from
+}
+
+// hierarchyChain is a single chain of the hierarchy.
+type hierarchyChain struct {
+	client   *Client
+	location []byte
+	context  int
+	prefix   []int // Address byte prefix range owned by the chain
+}
+
+// HierarchyClient talks to every Prime, Region and Zone chain of the hierarchy
+// and routes each request to the chain it belongs to.
+type HierarchyClient struct {
+	chains []*hierarchyChain
+}
+
+// DialHierarchy connects to every endpoint in the config and retrieves their
+// chain IDs to learn which addresses each chain owns.
+func DialHierarchy(ctx context.Context, config *HierarchyConfig) (*HierarchyClient, error) {
+	hc := new(HierarchyClient)
+	add := func(rawurl string, location []byte) error {
+		if rawurl == "" {
+			return nil
+		}
+		var (
+			client *Client
+			err    error
+		)
+		if config.Secret != nil {
+			client, err = DialWithAuth(ctx, rawurl, config.Secret)
+		} else {
+			client, err = DialContext(ctx, rawurl)
+		}
+		if err != nil {
+			return err
+		}
+		if err := hc.AddChain(ctx, client, location); err != nil {
+			client.Close()
+			return err
+		}
+		return nil
+	}
+	if err := add(config.Prime, []byte{}); err != nil {
+		hc.Close()
+		return nil, err
+	}
+	for i, rawurl := range config.Regions {
+		if err := add(rawurl, []byte{byte(i + 1)}); err != nil {
+			hc.Close()
+			return nil, err
+		}
+	}
+	for i, zones := range config.Zones {
+		for j, rawurl := range zones {
+			if err := add(rawurl, []byte{byte(i + 1), byte(j + 1)}); err != nil {
+				hc.Close()
+				return nil, err
+			}
+		}
+	}
+	return hc, nil
+}
+
+// DiscoverHierarchy builds the config of the hierarchy under the Prime node at
+// the given url by following the subordinate urls of the Prime and Region nodes.
+// The subordinate urls are only served on the authenticated endpoints of the
+// nodes, so the Prime url must point at the authenticated endpoint of the Prime
+// node and the secret the nodes share is needed to reach all of them.
+func DiscoverHierarchy(ctx context.Context, primeURL string, secret []byte) (*HierarchyConfig, error) {
+	if secret == nil {
+		return nil, ErrMissingSecret
+	}
+	subordinates := func(rawurl string) ([]string, error) {
+		client, err := DialWithAuth(ctx, rawurl, secret)
+		if err != nil {
+			return nil, err
+		}
+		defer client.Close()
+		return client.SubordinateUrls(ctx)
+	}
+	regions, err := subordinates(primeURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the regions of %s: %v", primeURL, err)
+	}
+	config := &HierarchyConfig{
+		Prime:   primeURL,
+		Regions: regions,
+		Zones:   make([][]string, len(regions)),
+		Secret:  secret,
+	}
+	for i, region := range regions {
+		if region == "" {
+			continue
+		}
+		zones, err := subordinates(region)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the zones of region %d: %v", i+1, err)
+		}
+		config.Zones[i] = zones
+	}
+	return config, nil
+}
+
+// NewHierarchyClient creates an empty client, chains are added with AddChain.
+func NewHierarchyClient() *HierarchyClient {
+	return new(HierarchyClient)
+}
+
+// AddChain adds an established client for the chain at the given location.
+// The location is empty for Prime, holds the region for a Region and the
+// region and zone for a Zone.
+func (hc *HierarchyClient) AddChain(ctx context.Context, client *Client, location []byte) error {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve chain id for location %v: %v", location, err)
+	}
+	hc.chains = append(hc.chains, &hierarchyChain{
+		client:   client,
+		location: common.CopyBytes(location),
+		context:  len(location),
+		prefix:   params.LookupChainIDRange(chainID),
+	})
+	return nil
+}
+
+// Close closes the connections to every chain.
+func (hc *HierarchyClient) Close() {
+	for _, chain := range hc.chains {
+		chain.client.Close()
+	}
+	hc.chains = nil
+}
+
+// Client returns the client of the chain at the given location. The location
+// is empty for Prime, holds the region for a Region and the region and zone
+// for a Zone.
+func (hc *HierarchyClient) Client(location []byte) (*Client, error) {
+	for _, chain := range hc.chains {
+		if bytes.Equal(chain.location, location) {
+			return chain.client, nil
+		}
+	}
+	return nil, ErrUnknownLocation
+}
+
+// ClientForAddress returns the client of the chain owning the address byte
+// prefix of addr.
+func (hc *HierarchyClient) ClientForAddress(addr common.Address) (*Client, error) {
+	for _, chain := range hc.chains {
+		if len(chain.prefix) == 2 && int(addr[0]) >= chain.prefix[0] && int(addr[0]) <= chain.prefix[1] {
+			return chain.client, nil
+		}
+	}
+	return nil, ErrUnknownAddress
+}
+
+// BalanceAt returns the wei balance of the given account, retrieved from the
+// chain owning the account. The block number can be nil, in which case the
+// balance is taken from the latest known block.
+func (hc *HierarchyClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	client, err := hc.ClientForAddress(account)
+	if err != nil {
+		return nil, err
+	}
+	return client.BalanceAt(ctx, account, blockNumber)
+}
+
+// NonceAt returns the account nonce of the given account, retrieved from the
+// chain owning the account.
+func (hc *HierarchyClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	client, err := hc.ClientForAddress(account)
+	if err != nil {
+		return 0, err
+	}
+	return client.NonceAt(ctx, account, blockNumber)
+}
+
+// SendTransaction injects a signed transaction into the pending pool of the
+// chain owning the sender.
+func (hc *HierarchyClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	chainID := tx.ChainId()
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return err
+	}
+	client, err := hc.ClientForAddress(from)
+	if err != nil {
+		return err
+	}
+	return client.SendTransaction(ctx, tx)
+}
+
+// ETxResult returns the outcome of an external transaction, retrieved from
+// the chain owning its recipient.
+func (hc *HierarchyClient) ETxResult(ctx context.Context, tx *types.Transaction) (*ETxResult, error) {
+	if tx.To() == nil {
+		return nil, ErrUnknownAddress
+	}
+	client, err := hc.ClientForAddress(*tx.To())
+	if err != nil {
+		return nil, err
+	}
+	return client.ETxResult(ctx, tx.Hash())
+}
+
+// SubscribeNewHeads subscribes to the heads of every chain of the hierarchy,
+// delivering them on a single channel tagged with their origin.
+func (hc *HierarchyClient) SubscribeNewHeads(ctx context.Context, ch chan<- HierarchyHeader) (quai.Subscription, error) {
+	var (
+		subs    []quai.Subscription
+		headers []chan *types.Header
+	)
+	for _, chain := range hc.chains {
+		heads := make(chan *types.Header)
+		sub, err := chain.client.SubscribeNewHead(ctx, heads)
+		if err != nil {
+			for _, sub := range subs {
+				sub.Unsubscribe()
+			}
+			return nil, err
+		}
+		subs = append(subs, sub)
+		headers = append(headers, heads)
+	}
+	chains := hc.chains
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer func() {
+			for _, sub := range subs {
+				sub.Unsubscribe()
+			}
+		}()
+		errc := make(chan error, len(subs))
+		done := make(chan struct{})
+		defer close(done)
+
+		for i := range subs {
+			go func(chain *hierarchyChain, sub quai.Subscription, heads chan *types.Header) {
+				for {
+					select {
+					case head := <-heads:
+						select {
+						case ch <- HierarchyHeader{Header: head, Location: chain.location, Context: chain.context}:
+						case <-done:
+							return
+						}
+					case err := <-sub.Err():
+						errc <- err
+						return
+					case <-done:
+						return
+					}
+				}
+			}(chains[i], subs[i], headers[i])
+		}
+		select {
+		case err := <-errc:
+			return err
+		case <-quit:
+			return nil
+		}
+	}), nil
+}
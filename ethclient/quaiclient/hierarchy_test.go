@@ -0,0 +1,179 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quaiclient
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+	"github.com/spruce-solutions/go-quai/rpc"
+)
+
+// testChainService serves the chain id and a balance identifying the chain.
+type testChainService struct {
+	chainID int64
+}
+
+func (s *testChainService) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(s.chainID))
+}
+
+func (s *testChainService) GetBalance(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(s.chainID))
+}
+
+func newTestChainClient(t *testing.T, chainID int64) *Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName("quai", &testChainService{chainID: chainID}); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	return NewClient(rpc.DialInProc(server))
+}
+
+func TestHierarchyClientRouting(t *testing.T) {
+	hc := NewHierarchyClient()
+	defer hc.Close()
+
+	chains := []struct {
+		chainID  int64
+		location []byte
+	}{
+		{9000, []byte{}},
+		{9100, []byte{1}},
+		{9101, []byte{1, 1}},
+		{9303, []byte{3, 3}},
+	}
+	for _, chain := range chains {
+		if err := hc.AddChain(context.Background(), newTestChainClient(t, chain.chainID), chain.location); err != nil {
+			t.Fatalf("failed to add chain %d: %v", chain.chainID, err)
+		}
+	}
+	tests := []struct {
+		prefix  byte
+		chainID int64
+		err     error
+	}{
+		{0, 9000, nil},
+		{9, 9000, nil},
+		{15, 9100, nil},
+		{20, 9101, nil},
+		{129, 9303, nil},
+		{50, 0, ErrUnknownAddress},
+		{200, 0, ErrUnknownAddress},
+	}
+	for i, tt := range tests {
+		balance, err := hc.BalanceAt(context.Background(), common.Address{tt.prefix}, nil)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && balance.Int64() != tt.chainID {
+			t.Errorf("test %d: routed to wrong chain: have %d, want %d", i, balance.Int64(), tt.chainID)
+		}
+	}
+	client, err := hc.Client([]byte{1, 1})
+	if err != nil {
+		t.Fatalf("failed to find zone client: %v", err)
+	}
+	if id, _ := client.ChainID(context.Background()); id.Int64() != 9101 {
+		t.Errorf("location routed to wrong chain: have %d, want %d", id.Int64(), 9101)
+	}
+	if _, err := hc.Client([]byte{2}); err != ErrUnknownLocation {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrUnknownLocation)
+	}
+}
+
+// Tests that a client which fails to join the hierarchy doesn't leak its
+// connection.
+func TestDialHierarchyClosesFailedClient(t *testing.T) {
+	// The server doesn't serve the chain id, so adding its chain fails
+	server := rpc.NewServer()
+	defer server.Stop()
+
+	var conns int32
+	handler := server.WebsocketHandler([]string{"*"})
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&conns, 1)
+		defer atomic.AddInt32(&conns, -1)
+		handler.ServeHTTP(w, r)
+	}))
+	defer httpsrv.Close()
+
+	config := &HierarchyConfig{Prime: "ws://" + httpsrv.Listener.Addr().String()}
+	if _, err := DialHierarchy(context.Background(), config); err == nil {
+		t.Fatalf("hierarchy dialed without chain id")
+	}
+	for deadline := time.Now().Add(5 * time.Second); atomic.LoadInt32(&conns) != 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("connection of failed client left open")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// testHierarchyService serves the subordinate urls of a node.
+type testHierarchyService struct {
+	urls []string
+}
+
+func (s *testHierarchyService) SubordinateUrls() []string {
+	return s.urls
+}
+
+func newTestHierarchyServer(t *testing.T, urls []string) *httptest.Server {
+	server := rpc.NewServer()
+	if err := server.RegisterName("domsub", &testHierarchyService{urls: urls}); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(func() {
+		httpsrv.Close()
+		server.Stop()
+	})
+	return httpsrv
+}
+
+// Tests that the hierarchy is discovered by following the subordinate urls of
+// the authenticated endpoints, which can't be reached without the secret.
+func TestDiscoverHierarchy(t *testing.T) {
+	var (
+		zone   = "ws://" + newTestHierarchyServer(t, []string{}).Listener.Addr().String()
+		region = "ws://" + newTestHierarchyServer(t, []string{zone}).Listener.Addr().String()
+		prime  = "ws://" + newTestHierarchyServer(t, []string{region, ""}).Listener.Addr().String()
+		secret = make([]byte, 32)
+	)
+	if _, err := DiscoverHierarchy(context.Background(), prime, nil); err != ErrMissingSecret {
+		t.Fatalf("error mismatch: have %v, want %v", err, ErrMissingSecret)
+	}
+	config, err := DiscoverHierarchy(context.Background(), prime, secret)
+	if err != nil {
+		t.Fatalf("failed to discover hierarchy: %v", err)
+	}
+	if config.Prime != prime || len(config.Regions) != 2 || config.Regions[0] != region || config.Regions[1] != "" {
+		t.Errorf("regions mismatch: have %v, want [%s ]", config.Regions, region)
+	}
+	if len(config.Zones) != 2 || len(config.Zones[0]) != 1 || config.Zones[0][0] != zone || len(config.Zones[1]) != 0 {
+		t.Errorf("zones mismatch: have %v, want [[%s] []]", config.Zones, zone)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/spruce-solutions/go-quai/common/math"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rpc"
 )
 
@@ -83,13 +84,30 @@ func Dial(rawurl string) (*Client, error) {
 }
 
 func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	return dial(ctx, func(ctx context.Context) (*rpc.Client, error) {
+		return rpc.DialContext(ctx, rawurl)
+	})
+}
+
+// DialWithAuth connects a client to the authenticated endpoint at the given URL,
+// signing the tokens of every request with the shared secret. The endpoint
+// serves the methods the dominant and subordinate chains drive the chain with.
+func DialWithAuth(ctx context.Context, rawurl string, secret []byte) (*Client, error) {
+	return dial(ctx, func(ctx context.Context) (*rpc.Client, error) {
+		return rpc.DialWithAuth(ctx, rawurl, rpc.NewJWTAuth(secret))
+	})
+}
+
+// dial connects a client with the given dial function, retrying until the node
+// is reachable.
+func dial(ctx context.Context, dialFn func(context.Context) (*rpc.Client, error)) (*Client, error) {
 	connectStatus := false
 	attempts := 0
 
 	var c *rpc.Client
 	var err error
 	for !connectStatus {
-		c, err = rpc.DialContext(ctx, rawurl)
+		c, err = dialFn(ctx)
 		if err == nil {
 			break
 		}
@@ -152,11 +170,11 @@ func (ec *Client) GetBlockStatus(ctx context.Context, header *types.Header) Writ
 
 func (ec *Client) HLCRReorg(ctx context.Context, block *types.Block) (bool, error) {
 	var domReorgNeeded bool
-	data, err := RPCMarshalBlock(block, true, true)
+	data, err := RPCMarshalBlock(block, true, true, params.ZONE)
 	if err != nil {
 		return false, err
 	}
-	if err := ec.c.CallContext(ctx, &domReorgNeeded, "quai_hLCRReorg", data); err != nil {
+	if err := ec.c.CallContext(ctx, &domReorgNeeded, "domsub_hLCRReorg", data); err != nil {
 		return false, err
 	}
 	return domReorgNeeded, nil
@@ -168,6 +186,17 @@ func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header)
 	return ec.c.EthSubscribe(ctx, ch, "newHeads")
 }
 
+// SubscribePendingBlock subscribes to the headers of the blocks the miner of
+// the node starts working on.
+func (ec *Client) SubscribePendingBlock(ctx context.Context, ch chan<- *types.Header) (quai.Subscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "pendingBlock")
+}
+
+// PendingBlock returns the block the miner of the node is working on.
+func (ec *Client) PendingBlock(ctx context.Context) (*types.Block, error) {
+	return ec.getBlock(ctx, "quai_pendingBlock")
+}
+
 // BlockByHash returns the given full block.
 //
 // Note that loading full blocks requires two requests. Use HeaderByHash
@@ -176,6 +205,12 @@ func (ec *Client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Blo
 	return ec.getBlock(ctx, "quai_getBlockByHash", hash, true)
 }
 
+// BlockByNumber returns a block from the current canonical chain. If number is
+// nil, the latest known block is returned.
+func (ec *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return ec.getBlock(ctx, "quai_getBlockByNumber", toBlockNumArg(number), true)
+}
+
 // GetBlockReceipts returns the receipts of a block by block hash.
 func (ec *Client) GetBlockReceipts(ctx context.Context, blockHash common.Hash) (*types.ReceiptBlock, error) {
 	return ec.getBlockWithReceipts(ctx, "quai_getBlockWithReceiptsByHash", blockHash)
@@ -192,13 +227,75 @@ func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.H
 	return head, err
 }
 
-// SendMinedBlock sends a mined block back to the node
+// ChainID retrieves the current chain ID for transaction replay protection.
+func (ec *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	err := ec.c.CallContext(ctx, &result, "quai_chainId")
+	if err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), err
+}
+
+// BalanceAt returns the wei balance of the given account.
+// The block number can be nil, in which case the balance is taken from the latest known block.
+func (ec *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var result hexutil.Big
+	err := ec.c.CallContext(ctx, &result, "quai_getBalance", account, toBlockNumArg(blockNumber))
+	return (*big.Int)(&result), err
+}
+
+// NonceAt returns the account nonce of the given account.
+// The block number can be nil, in which case the nonce is taken from the latest known block.
+func (ec *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	var result hexutil.Uint64
+	err := ec.c.CallContext(ctx, &result, "quai_getTransactionCount", account, toBlockNumArg(blockNumber))
+	return uint64(result), err
+}
+
+// SendTransaction injects a signed transaction into the pending pool for execution.
+func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return ec.c.CallContext(ctx, nil, "quai_sendRawTransaction", hexutil.Encode(data))
+}
+
+// ETxResult is the outcome of an external transaction as seen by its
+// destination zone.
+type ETxResult struct {
+	Hash             common.Hash    `json:"hash"`
+	Stage            string         `json:"stage"`
+	DestinationBlock common.Hash    `json:"destinationBlock"`
+	Error            string         `json:"error,omitempty"`
+	Receipt          *types.Receipt `json:"receipt,omitempty"`
+	Refund           *hexutil.Big   `json:"refund,omitempty"`
+}
+
+// ETxResult returns the outcome of the external transaction with the given
+// hash, which must be asked to the node of its destination zone. The receipt
+// holds the status and logs of the call of an ETx carrying calldata. If the
+// ETx is unknown, quai.NotFound is returned.
+func (ec *Client) ETxResult(ctx context.Context, hash common.Hash) (*ETxResult, error) {
+	var result *ETxResult
+	if err := ec.c.CallContext(ctx, &result, "quai_getETxStatus", hash); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, quai.NotFound
+	}
+	return result, nil
+}
+
+// SendMinedBlock sends a mined block back to the node. Only zone blocks carry
+// transactions, so they are numbered in the zone context.
 func (ec *Client) SendMinedBlock(ctx context.Context, block *types.Block, inclTx bool, fullTx bool) error {
-	data, err := RPCMarshalBlock(block, inclTx, fullTx)
+	data, err := RPCMarshalBlock(block, inclTx, fullTx, params.ZONE)
 	if err != nil {
 		return err
 	}
-	return ec.c.CallContext(ctx, nil, "quai_sendMinedBlock", data)
+	return ec.c.CallContext(ctx, nil, "domsub_sendMinedBlock", data)
 }
 
 func (ec *Client) GetAncestorByLocation(ctx context.Context, hash common.Hash, location []byte) (*types.Header, error) {
@@ -267,6 +364,37 @@ func (ec *Client) CheckPCCRC(ctx context.Context, header *types.Header, order in
 	return PCCRCTermini, nil
 }
 
+// GetZoneSupplies returns the supply records of the heads of every zone under
+// the node.
+func (ec *Client) GetZoneSupplies(ctx context.Context) ([]*types.Supply, error) {
+	var supplies []*types.Supply
+	if err := ec.c.CallContext(ctx, &supplies, "quai_getZoneSupplies"); err != nil {
+		return nil, err
+	}
+	return supplies, nil
+}
+
+// SubordinateUrls returns the urls of the subordinate chains the node is linked
+// to, indexed by region or zone. It is only served on the authenticated
+// endpoint, so the client must be dialed with DialWithAuth.
+func (ec *Client) SubordinateUrls(ctx context.Context) ([]string, error) {
+	var urls []string
+	if err := ec.c.CallContext(ctx, &urls, "domsub_subordinateUrls"); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// ReorgHistory returns the count most recent rollups of the chain journaled by
+// the node, newest first.
+func (ec *Client) ReorgHistory(ctx context.Context, count uint64) ([]*types.ReorgRecord, error) {
+	var records []*types.ReorgRecord
+	if err := ec.c.CallContext(ctx, &records, "quai_getReorgHistory", hexutil.Uint64(count)); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
 func (ec *Client) getExternalBlock(ctx context.Context, method string, args ...interface{}) (*types.ExternalBlock, error) {
 	var raw json.RawMessage
 	err := ec.c.CallContext(ctx, &raw, method, args...)
@@ -344,8 +472,8 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 
 // RPCMarshalBlock converts the given block to the RPC output which depends on fullTx. If inclTx is true transactions are
 // returned. When fullTx is true the returned block contains full transaction details, otherwise it will only contain
-// transaction hashes.
-func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool) (map[string]interface{}, error) {
+// transaction hashes. Full transactions are numbered in the given context.
+func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool, context int) (map[string]interface{}, error) {
 	fields := RPCMarshalHeader(block.Header())
 	fields["size"] = hexutil.Uint64(block.Size())
 
@@ -355,7 +483,7 @@ func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool) (map[string]i
 		}
 		if fullTx {
 			formatTx = func(tx *types.Transaction) (interface{}, error) {
-				return newRPCTransactionFromBlockHash(block, tx.Hash()), nil
+				return newRPCTransactionFromBlockHash(block, tx.Hash(), context), nil
 			}
 		}
 		txs := block.Transactions()
@@ -535,12 +663,12 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 }
 
 // newRPCTransactionFromBlockIndex returns a transaction that will serialize to the RPC representation.
-func newRPCTransactionFromBlockIndex(b *types.Block, index uint64) *RPCTransaction {
+func newRPCTransactionFromBlockIndex(b *types.Block, index uint64, context int) *RPCTransaction {
 	txs := b.Transactions()
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	return newRPCTransaction(txs[index], b.Hash(), b.NumberU64(), index, b.BaseFee())
+	return newRPCTransaction(txs[index], b.Hash(), b.NumberU64(context), index, b.BaseFee(context))
 }
 
 // newRPCRawTransactionFromBlockIndex returns the bytes of a transaction given a block and a transaction index.
@@ -554,10 +682,10 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.By
 }
 
 // newRPCTransactionFromBlockHash returns a transaction that will serialize to the RPC representation.
-func newRPCTransactionFromBlockHash(b *types.Block, hash common.Hash) *RPCTransaction {
+func newRPCTransactionFromBlockHash(b *types.Block, hash common.Hash, context int) *RPCTransaction {
 	for idx, tx := range b.Transactions() {
 		if tx.Hash() == hash {
-			return newRPCTransactionFromBlockIndex(b, uint64(idx))
+			return newRPCTransactionFromBlockIndex(b, uint64(idx), context)
 		}
 	}
 	return nil
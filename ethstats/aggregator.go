@@ -0,0 +1,392 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// Aggregator implements the server side of the stats protocol, collecting the
+// reports of the nodes of the whole hierarchy and grouping them by the chain
+// they follow. Nodes report on /api, the grouped health of the hierarchy is
+// served as JSON on any other path.
+type Aggregator struct {
+	secret   string // Password the reporting nodes have to authenticate with
+	upgrader websocket.Upgrader
+
+	nodes map[string]*aggregatedNode // Latest reports of the nodes by id
+	lock  sync.RWMutex
+}
+
+// aggregatedNode is the latest state reported by a single node.
+type aggregatedNode struct {
+	info     nodeInfo
+	sessions int // Number of live connections reporting under the node id
+
+	latency string
+	block   *reportedBlock
+	pending int
+	stats   *nodeStats
+	updated time.Time
+}
+
+// reportedBlock is the part of the block stats the aggregator keeps track of.
+type reportedBlock struct {
+	Number     *big.Int    `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	Order      int         `json:"order"`
+	TotalDiffs []string    `json:"totalDifficulties"`
+	ExtBlocks  int         `json:"externalBlocks"`
+	ETxs       etxStats    `json:"etxs"`
+}
+
+// NewAggregator creates a stats aggregator accepting the nodes reporting with
+// the given secret.
+func NewAggregator(secret string) *Aggregator {
+	return &Aggregator{
+		secret: secret,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		nodes: make(map[string]*aggregatedNode),
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api" {
+		a.serveNode(w, r)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(a.Hierarchy()); err != nil {
+		log.Debug("Failed to write hierarchy health", "err", err)
+	}
+}
+
+// serveNode upgrades the connection of a reporting node and records its
+// reports until it disconnects.
+func (a *Aggregator) serveNode(w http.ResponseWriter, r *http.Request) {
+	conn, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Debug("Failed to upgrade stats connection", "err", err)
+		return
+	}
+	defer conn.Close()
+
+	var id string
+	defer func() {
+		if id != "" {
+			a.disconnect(id)
+		}
+	}()
+	for {
+		var msg map[string][]json.RawMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			log.Debug("Stats node disconnected", "id", id, "err", err)
+			return
+		}
+		emit := msg["emit"]
+		if len(emit) == 0 {
+			log.Debug("Stats node sent non-broadcast", "id", id)
+			return
+		}
+		var command string
+		if err := json.Unmarshal(emit[0], &command); err != nil {
+			log.Debug("Invalid stats node message type", "id", id, "err", err)
+			return
+		}
+		switch {
+		case command == "hello":
+			var auth authMsg
+			if len(emit) != 2 || json.Unmarshal(emit[1], &auth) != nil {
+				log.Debug("Invalid stats node login", "remote", r.RemoteAddr)
+				return
+			}
+			if auth.Secret != a.secret || auth.ID == "" || id != "" {
+				log.Warn("Unauthorized stats node", "id", auth.ID, "remote", r.RemoteAddr)
+				return
+			}
+			id = auth.ID
+			a.connect(id, auth.Info)
+			if err := conn.WriteJSON(map[string][]interface{}{"emit": {"ready"}}); err != nil {
+				return
+			}
+			log.Info("Stats node connected", "id", id, "context", auth.Info.Context, "location", auth.Info.Location)
+
+		case id == "":
+			log.Warn("Stats node reported before login", "remote", r.RemoteAddr)
+			return
+
+		case command == "node-ping" && len(emit) == 2:
+			if err := conn.WriteJSON(map[string][]interface{}{"emit": {"node-pong", emit[1]}}); err != nil {
+				return
+			}
+
+		case len(emit) == 2:
+			a.update(id, command, emit[1])
+		}
+	}
+}
+
+// connect registers a new session of a node.
+func (a *Aggregator) connect(id string, info nodeInfo) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	node := a.nodes[id]
+	if node == nil {
+		node = new(aggregatedNode)
+		a.nodes[id] = node
+	}
+	node.info = info
+	node.sessions++
+	node.updated = time.Now()
+}
+
+// disconnect drops a session of a node. The node is kept around as inactive
+// so its last reports remain visible.
+func (a *Aggregator) disconnect(id string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if node := a.nodes[id]; node != nil && node.sessions > 0 {
+		node.sessions--
+	}
+}
+
+// update records a report of a node.
+func (a *Aggregator) update(id string, command string, payload json.RawMessage) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	node := a.nodes[id]
+	if node == nil {
+		return
+	}
+	var err error
+	switch command {
+	case "block":
+		var report struct {
+			Block *reportedBlock `json:"block"`
+		}
+		if err = json.Unmarshal(payload, &report); err == nil && report.Block != nil {
+			node.block = report.Block
+		}
+	case "pending":
+		var report struct {
+			Stats *pendStats `json:"stats"`
+		}
+		if err = json.Unmarshal(payload, &report); err == nil && report.Stats != nil {
+			node.pending = report.Stats.Pending
+		}
+	case "stats":
+		var report struct {
+			Stats *nodeStats `json:"stats"`
+		}
+		if err = json.Unmarshal(payload, &report); err == nil && report.Stats != nil {
+			node.stats = report.Stats
+		}
+	case "latency":
+		var report struct {
+			Latency string `json:"latency"`
+		}
+		if err = json.Unmarshal(payload, &report); err == nil {
+			node.latency = report.Latency
+		}
+	default:
+		// Block history is only useful to dashboards tracking a single chain
+		return
+	}
+	if err != nil {
+		log.Debug("Invalid stats node report", "id", id, "type", command, "err", err)
+		return
+	}
+	node.updated = time.Now()
+}
+
+// NodeHealth is the latest state reported by a node.
+type NodeHealth struct {
+	ID       string        `json:"id"`
+	Client   string        `json:"client"`
+	Location hexutil.Bytes `json:"location"`
+	Context  int           `json:"context"`
+	Active   bool          `json:"active"`
+	Syncing  bool          `json:"syncing"`
+	Mining   bool          `json:"mining"`
+	Peers    int           `json:"peers"`
+	Pending  int           `json:"pending"`
+	Latency  string        `json:"latency"`
+	Updated  time.Time     `json:"updated"`
+
+	Number          uint64         `json:"number"`
+	Hash            common.Hash    `json:"hash"`
+	Order           int            `json:"order"`
+	TotalDiffs      []string       `json:"totalDifficulties"`
+	ExternalBlocks  int            `json:"externalBlocks"`
+	OutboundETxs    int            `json:"outboundETxs"`
+	InboundETxs     int            `json:"inboundETxs"`
+	ExtBlockCache   *extBlockStats `json:"externalBlockCache,omitempty"`
+	ExtBlockHitRate float64        `json:"externalBlockHitRate"`
+}
+
+// ChainHealth summarizes the nodes following a single chain of the hierarchy.
+type ChainHealth struct {
+	Context  int           `json:"context"`
+	Location hexutil.Bytes `json:"location"`
+	Head     uint64        `json:"head"`   // Highest block number reported
+	Active   int           `json:"active"` // Number of nodes currently reporting
+	Nodes    []*NodeHealth `json:"nodes"`
+}
+
+// RegionHealth summarizes a region and the zones under it.
+type RegionHealth struct {
+	Region *ChainHealth   `json:"region"`
+	Zones  []*ChainHealth `json:"zones"`
+}
+
+// HierarchyHealth is the state of the whole hierarchy as reported by its nodes.
+// Nodes which didn't report a valid location are listed as unlocated.
+type HierarchyHealth struct {
+	Prime     *ChainHealth    `json:"prime"`
+	Regions   []*RegionHealth `json:"regions"`
+	Unlocated []*NodeHealth   `json:"unlocated"`
+}
+
+// Hierarchy groups the latest reports of the nodes by Prime, Region and Zone.
+func (a *Aggregator) Hierarchy() *HierarchyHealth {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	ids := make([]string, 0, len(a.nodes))
+	for id := range a.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var (
+		health = &HierarchyHealth{
+			Prime:     &ChainHealth{Context: params.PRIME, Location: hexutil.Bytes{}, Nodes: []*NodeHealth{}},
+			Regions:   []*RegionHealth{},
+			Unlocated: []*NodeHealth{},
+		}
+		regions = make(map[byte]*RegionHealth)
+		zones   = make(map[[2]byte]*ChainHealth)
+	)
+	region := func(index byte) *RegionHealth {
+		if regions[index] == nil {
+			regions[index] = &RegionHealth{
+				Region: &ChainHealth{Context: params.REGION, Location: hexutil.Bytes{index}, Nodes: []*NodeHealth{}},
+				Zones:  []*ChainHealth{},
+			}
+			health.Regions = append(health.Regions, regions[index])
+		}
+		return regions[index]
+	}
+	for _, id := range ids {
+		node := a.nodes[id].health(id)
+
+		var chain *ChainHealth
+		switch {
+		case node.Context == params.PRIME:
+			chain = health.Prime
+		case node.Context == params.REGION && len(node.Location) > 0:
+			chain = region(node.Location[0]).Region
+		case node.Context == params.ZONE && len(node.Location) > 1:
+			key := [2]byte{node.Location[0], node.Location[1]}
+			if zones[key] == nil {
+				zones[key] = &ChainHealth{Context: params.ZONE, Location: hexutil.Bytes{key[0], key[1]}, Nodes: []*NodeHealth{}}
+				parent := region(key[0])
+				parent.Zones = append(parent.Zones, zones[key])
+			}
+			chain = zones[key]
+		default:
+			health.Unlocated = append(health.Unlocated, node)
+			continue
+		}
+		chain.add(node)
+	}
+	sort.Slice(health.Regions, func(i, j int) bool {
+		return health.Regions[i].Region.Location[0] < health.Regions[j].Region.Location[0]
+	})
+	for _, region := range health.Regions {
+		zones := region.Zones
+		sort.Slice(zones, func(i, j int) bool { return zones[i].Location[1] < zones[j].Location[1] })
+	}
+	return health
+}
+
+// add appends a node to the chain, accounting its head if it's reporting.
+func (c *ChainHealth) add(node *NodeHealth) {
+	c.Nodes = append(c.Nodes, node)
+	if !node.Active {
+		return
+	}
+	c.Active++
+	if node.Number > c.Head {
+		c.Head = node.Number
+	}
+}
+
+// health assembles the reported state of a node.
+func (n *aggregatedNode) health(id string) *NodeHealth {
+	health := &NodeHealth{
+		ID:       id,
+		Client:   n.info.Node,
+		Location: n.info.Location,
+		Context:  n.info.Context,
+		Active:   n.sessions > 0,
+		Pending:  n.pending,
+		Latency:  n.latency,
+		Updated:  n.updated,
+		Order:    -1,
+	}
+	if n.stats != nil {
+		health.Syncing = n.stats.Syncing
+		health.Mining = n.stats.Mining
+		health.Peers = n.stats.Peers
+		if cache := n.stats.ExtBlocks; cache != nil {
+			health.ExtBlockCache = cache
+			if lookups := cache.Hits + cache.Misses; lookups > 0 {
+				health.ExtBlockHitRate = float64(cache.Hits) / float64(lookups)
+			}
+		}
+	}
+	if block := n.block; block != nil {
+		if block.Number != nil {
+			health.Number = block.Number.Uint64()
+		}
+		health.Hash = block.Hash
+		health.Order = block.Order
+		health.TotalDiffs = block.TotalDiffs
+		health.ExternalBlocks = block.ExtBlocks
+		health.OutboundETxs = block.ETxs.Outbound
+		health.InboundETxs = block.ETxs.Inbound
+	}
+	return health
+}
@@ -0,0 +1,143 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethstats
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// dialAggregator connects a reporting node to the aggregator and logs it in.
+func dialAggregator(t *testing.T, url string, id string, secret string, info nodeInfo) (*connWrapper, error) {
+	t.Helper()
+
+	c, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/api", nil)
+	if err != nil {
+		t.Fatalf("failed to dial aggregator: %v", err)
+	}
+	conn := newConnectionWrapper(c)
+	login := map[string][]interface{}{
+		"emit": {"hello", &authMsg{ID: id, Info: info, Secret: secret}},
+	}
+	if err := conn.WriteJSON(login); err != nil {
+		t.Fatalf("failed to send login: %v", err)
+	}
+	var ack map[string][]string
+	if err := conn.ReadJSON(&ack); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if len(ack["emit"]) != 1 || ack["emit"][0] != "ready" {
+		t.Fatalf("unexpected login ack: %v", ack)
+	}
+	return conn, nil
+}
+
+// reportHead sends a block report and waits for it to be processed.
+func reportHead(t *testing.T, conn *connWrapper, id string, number int64, order int) {
+	t.Helper()
+
+	block := map[string][]interface{}{
+		"emit": {"block", map[string]interface{}{
+			"id": id,
+			"block": &blockStats{
+				Number:     big.NewInt(number),
+				Hash:       common.BigToHash(big.NewInt(number)),
+				Order:      order,
+				TotalDiffs: []string{"1", "2", "3"},
+				ETxs:       etxStats{Outbound: 2, Inbound: 1},
+			},
+		}},
+	}
+	if err := conn.WriteJSON(block); err != nil {
+		t.Fatalf("failed to report block: %v", err)
+	}
+	// Replies are sent in order, so the pong guarantees the block was recorded
+	ping := map[string][]interface{}{
+		"emit": {"node-ping", map[string]string{"id": id}},
+	}
+	if err := conn.WriteJSON(ping); err != nil {
+		t.Fatalf("failed to send ping: %v", err)
+	}
+	var pong map[string][]interface{}
+	if err := conn.ReadJSON(&pong); err != nil || len(pong["emit"]) != 2 || pong["emit"][0] != "node-pong" {
+		t.Fatalf("unexpected pong: %v, err: %v", pong, err)
+	}
+}
+
+// Tests that the aggregator groups the reporting nodes by the chain they follow.
+func TestAggregatorHierarchy(t *testing.T) {
+	aggregator := NewAggregator("secret")
+	server := httptest.NewServer(aggregator)
+	defer server.Close()
+
+	if _, err := dialAggregator(t, server.URL, "intruder", "wrong", nodeInfo{}); err == nil {
+		t.Fatal("login with a wrong secret succeeded")
+	}
+	nodes := []struct {
+		id       string
+		context  int
+		location []byte
+		number   int64
+	}{
+		{"prime", params.PRIME, []byte{0, 0}, 10},
+		{"region-2", params.REGION, []byte{2, 0}, 20},
+		{"zone-2-1", params.ZONE, []byte{2, 1}, 30},
+		{"zone-1-3", params.ZONE, []byte{1, 3}, 40},
+		{"zone-1-1", params.ZONE, []byte{1, 1}, 50},
+		{"zone-1-1-backup", params.ZONE, []byte{1, 1}, 49},
+	}
+	for _, node := range nodes {
+		conn, err := dialAggregator(t, server.URL, node.id, "secret", nodeInfo{Context: node.context, Location: node.location})
+		if err != nil {
+			t.Fatalf("node %s: failed to login: %v", node.id, err)
+		}
+		defer conn.Close()
+		reportHead(t, conn, node.id, node.number, node.context)
+	}
+	health := aggregator.Hierarchy()
+	if len(health.Prime.Nodes) != 1 || health.Prime.Head != 10 {
+		t.Fatalf("prime health mismatch: have %d nodes at %d, want 1 at 10", len(health.Prime.Nodes), health.Prime.Head)
+	}
+	if len(health.Regions) != 2 {
+		t.Fatalf("region count mismatch: have %d, want 2", len(health.Regions))
+	}
+	// Region 1 only has zone nodes reporting, region 2 has both
+	first, second := health.Regions[0], health.Regions[1]
+	if first.Region.Location[0] != 1 || len(first.Region.Nodes) != 0 || len(first.Zones) != 2 {
+		t.Fatalf("region 1 mismatch: location %x, %d nodes, %d zones", first.Region.Location, len(first.Region.Nodes), len(first.Zones))
+	}
+	if first.Zones[0].Location[1] != 1 || first.Zones[0].Head != 50 || first.Zones[0].Active != 2 {
+		t.Fatalf("zone 1-1 mismatch: location %x, head %d, active %d", first.Zones[0].Location, first.Zones[0].Head, first.Zones[0].Active)
+	}
+	if first.Zones[1].Location[1] != 3 || first.Zones[1].Head != 40 {
+		t.Fatalf("zone 1-3 mismatch: location %x, head %d", first.Zones[1].Location, first.Zones[1].Head)
+	}
+	if second.Region.Location[0] != 2 || second.Region.Head != 20 || len(second.Zones) != 1 {
+		t.Fatalf("region 2 mismatch: location %x, head %d, %d zones", second.Region.Location, second.Region.Head, len(second.Zones))
+	}
+	node := second.Zones[0].Nodes[0]
+	if node.OutboundETxs != 2 || node.InboundETxs != 1 || node.Order != params.ZONE || len(node.TotalDiffs) != 3 {
+		t.Fatalf("zone 2-1 node mismatch: %+v", node)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/gorilla/websocket"
 	ethereum "github.com/spruce-solutions/go-quai"
 	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
 	"github.com/spruce-solutions/go-quai/common/mclock"
 	"github.com/spruce-solutions/go-quai/consensus"
 	"github.com/spruce-solutions/go-quai/core"
@@ -44,6 +45,7 @@ import (
 	"github.com/spruce-solutions/go-quai/miner"
 	"github.com/spruce-solutions/go-quai/node"
 	"github.com/spruce-solutions/go-quai/p2p"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rpc"
 )
 
@@ -68,6 +70,7 @@ type backend interface {
 	GetTd(ctx context.Context, hash common.Hash) []*big.Int
 	Stats() (pending int, queued int)
 	SyncProgress() ethereum.SyncProgress
+	ChainConfig() *params.ChainConfig
 }
 
 // fullNodeBackend encompasses the functionality necessary for a full node
@@ -78,6 +81,8 @@ type fullNodeBackend interface {
 	BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error)
 	CurrentBlock() *types.Block
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	GetCoincidentExternalBlocks(hash common.Hash) []*types.ExternalBlock
+	ExternalBlockCacheStats() core.ExternalBlockCacheStats
 }
 
 // Service implements an Ethereum netstats reporting daemon that pushes local
@@ -451,6 +456,9 @@ type nodeInfo struct {
 	OsVer    string `json:"os_v"`
 	Client   string `json:"client"`
 	History  bool   `json:"canUpdateHistory"`
+
+	Location hexutil.Bytes `json:"location"`
+	Context  int           `json:"context"`
 }
 
 // authMsg is the authentication infos needed to login to a monitoring server.
@@ -470,6 +478,7 @@ func (s *Service) login(conn *connWrapper) error {
 		protocols = append(protocols, fmt.Sprintf("%s/%d", proto.Name, proto.Version))
 	}
 	var network string
+	config := s.backend.ChainConfig()
 	if info := infos.Protocols["quai"]; info != nil {
 		network = fmt.Sprintf("%d", info.(*ethproto.NodeInfo).Network)
 	} else {
@@ -488,6 +497,8 @@ func (s *Service) login(conn *connWrapper) error {
 			OsVer:    runtime.GOARCH,
 			Client:   "0.1.1",
 			History:  true,
+			Location: config.Location,
+			Context:  config.Context,
 		},
 		Secret: s.pass,
 	}
@@ -576,6 +587,14 @@ type blockStats struct {
 	TxHash     common.Hash    `json:"transactionsRoot"`
 	Root       common.Hash    `json:"stateRoot"`
 	Uncles     uncleStats     `json:"uncles"`
+
+	Location   hexutil.Bytes `json:"location"`
+	Context    int           `json:"context"`
+	Order      int           `json:"order"`
+	Numbers    []*big.Int    `json:"numbers"`
+	TotalDiffs []string      `json:"totalDifficulties"`
+	ExtBlocks  int           `json:"externalBlocks"`
+	ETxs       etxStats      `json:"etxs"`
 }
 
 // txStats is the information to report about individual transactions.
@@ -583,6 +602,44 @@ type txStats struct {
 	Hash common.Hash `json:"hash"`
 }
 
+// etxStats is the information to report about the external transactions of
+// individual blocks.
+type etxStats struct {
+	Outbound int `json:"outbound"` // ETxs sent by the block to other chains
+	Inbound  int `json:"inbound"`  // ETxs to this chain in the linked external blocks
+}
+
+// countETxs counts the ETxs sent by a block and those destined to its chain in
+// the external blocks it links.
+func countETxs(config *params.ChainConfig, block *types.Block, linked []*types.ExternalBlock) etxStats {
+	var stats etxStats
+
+	idRange := config.ChainIDRange()
+	if len(idRange) != 2 {
+		return stats
+	}
+	local := func(addr *common.Address) bool {
+		prefix := int(addr[0])
+		return prefix >= idRange[0] && prefix <= idRange[1]
+	}
+	for _, tx := range block.Transactions() {
+		if to := tx.To(); to != nil && !local(to) {
+			stats.Outbound++
+		}
+	}
+	for _, external := range linked {
+		if external.Context().Int64() != int64(params.ZONE) {
+			continue
+		}
+		for _, tx := range external.Transactions() {
+			if to := tx.To(); to != nil && local(to) {
+				stats.Inbound++
+			}
+		}
+	}
+	return stats
+}
+
 // uncleStats is a custom wrapper around an uncle array to force serializing
 // empty arrays instead of returning null for them.
 type uncleStats []*types.Header
@@ -618,9 +675,12 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 	// Gather the block infos from the local blockchain
 	var (
 		header *types.Header
-		td     *big.Int
+		tds    []*big.Int
 		txs    []txStats
 		uncles []*types.Header
+		linked []*types.ExternalBlock
+		etxs   etxStats
+		config = s.backend.ChainConfig()
 	)
 
 	// check if backend is a full node
@@ -630,13 +690,16 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 			block = fullBackend.CurrentBlock()
 		}
 		header = block.Header()
-		td = fullBackend.GetTd(context.Background(), header.Hash())[types.QuaiNetworkContext]
+		tds = fullBackend.GetTd(context.Background(), header.Hash())
 
 		txs = make([]txStats, len(block.Transactions()))
 		for i, tx := range block.Transactions() {
 			txs[i].Hash = tx.Hash()
 		}
 		uncles = block.Uncles()
+
+		linked = fullBackend.GetCoincidentExternalBlocks(header.Hash())
+		etxs = countETxs(config, block, linked)
 	} else {
 		// Light nodes would need on-demand lookups for transactions/uncles, skip
 		if block != nil {
@@ -644,27 +707,48 @@ func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
 		} else {
 			header = s.backend.CurrentHeader()
 		}
-		td = s.backend.GetTd(context.Background(), header.Hash())[types.QuaiNetworkContext]
+		tds = s.backend.GetTd(context.Background(), header.Hash())
 		txs = []txStats{}
 	}
-
+	// The total difficulties are missing if the block isn't stored (yet)
+	totalDiffs := make([]string, len(tds))
+	for i, td := range tds {
+		if td != nil {
+			totalDiffs[i] = td.String()
+		}
+	}
+	var totalDiff string
+	if config.Context < len(totalDiffs) {
+		totalDiff = totalDiffs[config.Context]
+	}
 	// Assemble and return the block stats
 	author, _ := s.engine.Author(header)
+	order, err := s.engine.GetDifficultyOrder(header)
+	if err != nil {
+		order = -1
+	}
 
 	return &blockStats{
-		Number:     header.Number[types.QuaiNetworkContext],
+		Number:     header.Number[config.Context],
 		Hash:       header.Hash(),
-		ParentHash: header.ParentHash[types.QuaiNetworkContext],
+		ParentHash: header.ParentHash[config.Context],
 		Timestamp:  new(big.Int).SetUint64(header.Time),
 		Miner:      author,
-		GasUsed:    header.GasUsed[types.QuaiNetworkContext],
-		GasLimit:   header.GasLimit[types.QuaiNetworkContext],
-		Diff:       header.Difficulty[types.QuaiNetworkContext],
-		TotalDiff:  td.String(),
+		GasUsed:    header.GasUsed[config.Context],
+		GasLimit:   header.GasLimit[config.Context],
+		Diff:       header.Difficulty[config.Context],
+		TotalDiff:  totalDiff,
 		Txs:        txs,
-		TxHash:     header.TxHash[types.QuaiNetworkContext],
-		Root:       header.Root[types.QuaiNetworkContext],
+		TxHash:     header.TxHash[config.Context],
+		Root:       header.Root[config.Context],
 		Uncles:     uncles,
+		Location:   header.Location,
+		Context:    config.Context,
+		Order:      order,
+		Numbers:    header.Number,
+		TotalDiffs: totalDiffs,
+		ExtBlocks:  len(linked),
+		ETxs:       etxs,
 	}
 }
 
@@ -678,7 +762,7 @@ func (s *Service) reportHistory(conn *connWrapper, list []uint64) error {
 		indexes = append(indexes, list...)
 	} else {
 		// No indexes requested, send back the top ones
-		head := s.backend.CurrentHeader().Number[types.QuaiNetworkContext].Int64()
+		head := s.backend.CurrentHeader().Number[s.backend.ChainConfig().Context].Int64()
 		start := head - historyUpdateRange + 1
 		if start < 0 {
 			start = 0
@@ -759,6 +843,18 @@ type nodeStats struct {
 	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
+
+	ExtBlocks *extBlockStats `json:"externalBlocks,omitempty"`
+}
+
+// extBlockStats is the information to report about the external block cache.
+type extBlockStats struct {
+	Entries uint64 `json:"entries"`
+	Bytes   uint64 `json:"bytes"`
+	Queued  int    `json:"queued"`
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Pruned  int64  `json:"pruned"`
 }
 
 // reportStats retrieves various stats about the node at the networking and
@@ -770,6 +866,8 @@ func (s *Service) reportStats(conn *connWrapper) error {
 		hashrate int
 		syncing  bool
 		gasprice int
+		extstats *extBlockStats
+		config   = s.backend.ChainConfig()
 	)
 	// check if backend is a full node
 	fullBackend, ok := s.backend.(fullNodeBackend)
@@ -778,16 +876,25 @@ func (s *Service) reportStats(conn *connWrapper) error {
 		hashrate = int(fullBackend.Miner().Hashrate())
 
 		sync := fullBackend.SyncProgress()
-		syncing = fullBackend.CurrentHeader().Number[types.QuaiNetworkContext].Uint64() >= sync.HighestBlock
+		syncing = fullBackend.CurrentHeader().Number[config.Context].Uint64() >= sync.HighestBlock
 
 		price, _ := fullBackend.SuggestGasTipCap(context.Background())
 		gasprice = int(price.Uint64())
-		if basefee := fullBackend.CurrentHeader().BaseFee; basefee != nil {
-			gasprice += int(basefee[types.QuaiNetworkContext].Uint64())
+		if basefee := fullBackend.CurrentHeader().BaseFee; config.Context < len(basefee) && basefee[config.Context] != nil {
+			gasprice += int(basefee[config.Context].Uint64())
+		}
+		cache := fullBackend.ExternalBlockCacheStats()
+		extstats = &extBlockStats{
+			Entries: cache.Entries,
+			Bytes:   cache.Bytes,
+			Queued:  cache.Queued,
+			Hits:    cache.Hits,
+			Misses:  cache.Misses,
+			Pruned:  cache.Pruned,
 		}
 	} else {
 		sync := s.backend.SyncProgress()
-		syncing = s.backend.CurrentHeader().Number[types.QuaiNetworkContext].Uint64() >= sync.HighestBlock
+		syncing = s.backend.CurrentHeader().Number[config.Context].Uint64() >= sync.HighestBlock
 	}
 	// Assemble the node stats and send it to the server
 	log.Trace("Sending node details to ethstats")
@@ -802,6 +909,8 @@ func (s *Service) reportStats(conn *connWrapper) error {
 			GasPrice: gasprice,
 			Syncing:  syncing,
 			Uptime:   100,
+
+			ExtBlocks: extstats,
 		},
 	}
 	report := map[string][]interface{}{
@@ -1,8 +1,15 @@
 package ethstats
 
 import (
+	"context"
+	"math/big"
 	"strconv"
 	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
 )
 
 func TestParseEthstatsURL(t *testing.T) {
@@ -65,3 +72,55 @@ func TestParseEthstatsURL(t *testing.T) {
 	}
 
 }
+
+// testBackend is a light node backend serving a single head, whose total
+// difficulties may not be known.
+type testBackend struct {
+	backend
+
+	config *params.ChainConfig
+	head   *types.Header
+	tds    []*big.Int
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig { return b.config }
+func (b *testBackend) CurrentHeader() *types.Header     { return b.head }
+
+func (b *testBackend) GetTd(ctx context.Context, hash common.Hash) []*big.Int {
+	return b.tds
+}
+
+// Tests that block stats are reported in the context of the chain, and that
+// blocks without a known total difficulty are reported without one.
+func TestAssembleBlockStats(t *testing.T) {
+	config, err := params.MainnetOntology.ChainConfig(params.MainnetPrimeChainConfig, []byte{1, 1})
+	if err != nil {
+		t.Fatalf("failed to derive chain config: %v", err)
+	}
+	head := types.NewEmptyHeader()
+	for i := range head.Number {
+		head.Number[i] = big.NewInt(int64(10 * (i + 1)))
+		head.Difficulty[i] = big.NewInt(int64(100 * (i + 1)))
+		head.GasLimit[i] = uint64(1000 * (i + 1))
+	}
+	for _, tt := range []struct {
+		tds  []*big.Int
+		want string
+	}{
+		{nil, ""},
+		{[]*big.Int{big.NewInt(1), big.NewInt(2), nil}, ""},
+		{[]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}, "3"},
+	} {
+		s := &Service{
+			backend: &testBackend{config: config, head: head, tds: tt.tds},
+			engine:  blake3.NewContextFaker(config.Context),
+		}
+		stats := s.assembleBlockStats(nil)
+		if stats.Number.Int64() != 30 || stats.Diff.Int64() != 300 || stats.GasLimit != 3000 {
+			t.Errorf("tds %v: stats not in the zone context: number %v, difficulty %v, gas limit %d", tt.tds, stats.Number, stats.Diff, stats.GasLimit)
+		}
+		if stats.TotalDiff != tt.want {
+			t.Errorf("tds %v: total difficulty mismatch: have %q, want %q", tt.tds, stats.TotalDiff, tt.want)
+		}
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/eth/filters"
 	"github.com/spruce-solutions/go-quai/internal/ethapi"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/rpc"
 )
 
@@ -253,7 +254,7 @@ func (t *Transaction) EffectiveGasPrice(ctx context.Context) (*hexutil.Big, erro
 	if header.BaseFee == nil {
 		return (*hexutil.Big)(tx.GasPrice()), nil
 	}
-	return (*hexutil.Big)(math.BigMin(new(big.Int).Add(tx.GasTipCap(), header.BaseFee[types.QuaiNetworkContext]), tx.GasFeeCap())), nil
+	return (*hexutil.Big)(math.BigMin(new(big.Int).Add(tx.GasTipCap(), header.BaseFee[t.backend.ChainConfig().Context]), tx.GasFeeCap())), nil
 }
 
 func (t *Transaction) MaxFeePerGas(ctx context.Context) (*hexutil.Big, error) {
@@ -475,6 +476,47 @@ func (t *Transaction) V(ctx context.Context) (hexutil.Big, error) {
 	return hexutil.Big(*v), nil
 }
 
+// IsExternal reports whether the transaction crosses chains, either as an
+// ETx applied on this chain or as one sent to another chain.
+func (t *Transaction) IsExternal(ctx context.Context) (bool, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return false, err
+	}
+	if tx.Type() == types.ExternalTxType {
+		return true, nil
+	}
+	to := tx.To()
+	if to == nil {
+		return false, nil
+	}
+	idRange := t.backend.ChainConfig().ChainIDRange()
+	if len(idRange) != 2 {
+		return false, nil
+	}
+	prefix := int(to[0])
+	return prefix < idRange[0] || prefix > idRange[1], nil
+}
+
+// DestinationZone returns the location of the zone an external transaction
+// is destined for, or nil for transactions staying on this chain.
+func (t *Transaction) DestinationZone(ctx context.Context) (*hexutil.Bytes, error) {
+	external, err := t.IsExternal(ctx)
+	if err != nil || !external {
+		return nil, err
+	}
+	ontology := t.backend.ChainConfig().OntologyAt(t.backend.CurrentHeader().Number[params.PRIME])
+	if ontology == nil || t.tx.To() == nil {
+		return nil, nil
+	}
+	location := ontology.AddressLocation(*t.tx.To())
+	if location == nil {
+		return nil, nil
+	}
+	ret := hexutil.Bytes(location)
+	return &ret, nil
+}
+
 type BlockType int
 
 // Block represents an Ethereum block.
@@ -555,7 +597,7 @@ func (b *Block) Number(ctx context.Context) (Long, error) {
 		return 0, err
 	}
 
-	return Long(header.Number[types.QuaiNetworkContext].Uint64()), nil
+	return Long(header.Number[b.backend.ChainConfig().Context].Uint64()), nil
 }
 
 func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
@@ -574,7 +616,7 @@ func (b *Block) GasLimit(ctx context.Context) (Long, error) {
 	if err != nil {
 		return 0, err
 	}
-	return Long(header.GasLimit[types.QuaiNetworkContext]), nil
+	return Long(header.GasLimit[b.backend.ChainConfig().Context]), nil
 }
 
 func (b *Block) GasUsed(ctx context.Context) (Long, error) {
@@ -582,7 +624,7 @@ func (b *Block) GasUsed(ctx context.Context) (Long, error) {
 	if err != nil {
 		return 0, err
 	}
-	return Long(header.GasUsed[types.QuaiNetworkContext]), nil
+	return Long(header.GasUsed[b.backend.ChainConfig().Context]), nil
 }
 
 func (b *Block) BaseFeePerGas(ctx context.Context) (*hexutil.Big, error) {
@@ -593,7 +635,7 @@ func (b *Block) BaseFeePerGas(ctx context.Context) (*hexutil.Big, error) {
 	if header.BaseFee == nil {
 		return nil, nil
 	}
-	return (*hexutil.Big)(header.BaseFee[types.QuaiNetworkContext]), nil
+	return (*hexutil.Big)(header.BaseFee[b.backend.ChainConfig().Context]), nil
 }
 
 func (b *Block) Parent(ctx context.Context) (*Block, error) {
@@ -603,12 +645,12 @@ func (b *Block) Parent(ctx context.Context) (*Block, error) {
 			return nil, err
 		}
 	}
-	if b.header != nil && b.header.Number[types.QuaiNetworkContext].Uint64() > 0 {
-		num := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(b.header.Number[types.QuaiNetworkContext].Uint64() - 1))
+	if b.header != nil && b.header.Number[b.backend.ChainConfig().Context].Uint64() > 0 {
+		num := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(b.header.Number[b.backend.ChainConfig().Context].Uint64() - 1))
 		return &Block{
 			backend:      b.backend,
 			numberOrHash: &num,
-			hash:         b.header.ParentHash[types.QuaiNetworkContext],
+			hash:         b.header.ParentHash[b.backend.ChainConfig().Context],
 		}, nil
 	}
 	return nil, nil
@@ -619,7 +661,7 @@ func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
 	if err != nil {
 		return hexutil.Big{}, err
 	}
-	return hexutil.Big(*header.Difficulty[types.QuaiNetworkContext]), nil
+	return hexutil.Big(*header.Difficulty[b.backend.ChainConfig().Context]), nil
 }
 
 func (b *Block) Timestamp(ctx context.Context) (hexutil.Uint64, error) {
@@ -638,36 +680,36 @@ func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
 	return header.Nonce[:], nil
 }
 
-func (b *Block) TransactionsRoot(ctx context.Context) ([]common.Hash, error) {
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
-		return []common.Hash{common.Hash{}, common.Hash{}, common.Hash{}}, err
+		return common.Hash{}, err
 	}
-	return header.TxHash, nil
+	return header.TxHash[b.backend.ChainConfig().Context], nil
 }
 
-func (b *Block) StateRoot(ctx context.Context) ([]common.Hash, error) {
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
-		return []common.Hash{common.Hash{}, common.Hash{}, common.Hash{}}, err
+		return common.Hash{}, err
 	}
-	return header.Root, nil
+	return header.Root[b.backend.ChainConfig().Context], nil
 }
 
-func (b *Block) ReceiptsRoot(ctx context.Context) ([]common.Hash, error) {
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
-		return []common.Hash{common.Hash{}, common.Hash{}, common.Hash{}}, err
+		return common.Hash{}, err
 	}
-	return header.ReceiptHash, nil
+	return header.ReceiptHash[b.backend.ChainConfig().Context], nil
 }
 
-func (b *Block) OmmerHash(ctx context.Context) ([]common.Hash, error) {
+func (b *Block) OmmerHash(ctx context.Context) (common.Hash, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
-		return []common.Hash{common.Hash{}, common.Hash{}, common.Hash{}}, err
+		return common.Hash{}, err
 	}
-	return header.UncleHash, nil
+	return header.UncleHash[b.backend.ChainConfig().Context], nil
 }
 
 func (b *Block) OmmerCount(ctx context.Context) (*int32, error) {
@@ -696,20 +738,20 @@ func (b *Block) Ommers(ctx context.Context) (*[]*Block, error) {
 	return &ret, nil
 }
 
-func (b *Block) ExtraData(ctx context.Context) ([][]byte, error) {
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
-		return [][]byte{hexutil.Bytes{}, hexutil.Bytes{}, hexutil.Bytes{}}, err
+		return hexutil.Bytes{}, err
 	}
-	return header.Extra, nil
+	return header.Extra[b.backend.ChainConfig().Context], nil
 }
 
-func (b *Block) LogsBloom(ctx context.Context) ([]hexutil.Bytes, error) {
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
 	header, err := b.resolveHeader(ctx)
 	if err != nil {
-		return []hexutil.Bytes{hexutil.Bytes{}, hexutil.Bytes{}, hexutil.Bytes{}}, err
+		return hexutil.Bytes{}, err
 	}
-	return []hexutil.Bytes{header.Bloom[0].Bytes(), header.Bloom[1].Bytes(), header.Bloom[2].Bytes()}, nil
+	return header.Bloom[b.backend.ChainConfig().Context].Bytes(), nil
 }
 
 func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
@@ -722,7 +764,7 @@ func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
 		h = header.Hash()
 	}
 	tempTd := b.backend.GetTd(ctx, h)
-	td := tempTd[types.QuaiNetworkContext]
+	td := tempTd[b.backend.ChainConfig().Context]
 	if td == nil {
 		return hexutil.Big{}, fmt.Errorf("total difficulty not found %x", b.hash)
 	}
@@ -760,7 +802,7 @@ func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, erro
 	}
 	return &Account{
 		backend:       b.backend,
-		address:       header.Coinbase[types.QuaiNetworkContext],
+		address:       header.Coinbase[b.backend.ChainConfig().Context],
 		blockNrOrHash: args.NumberOrLatest(),
 	}, nil
 }
@@ -829,6 +871,216 @@ func (b *Block) OmmerAt(ctx context.Context, args struct{ Index int32 }) (*Block
 	}, nil
 }
 
+func (b *Block) Location(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return header.Location, nil
+}
+
+// Order returns the highest context whose difficulty the block satisfies, or
+// nil if it doesn't satisfy any.
+func (b *Block) Order(ctx context.Context) (*int32, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := b.backend.Engine().GetDifficultyOrder(header)
+	if err != nil {
+		return nil, nil
+	}
+	ret := int32(order)
+	return &ret, nil
+}
+
+func (b *Block) Contexts(ctx context.Context) ([]*BlockContext, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return headerContexts(header), nil
+}
+
+// ExternalBlocks returns the external blocks linked by this block, optionally
+// restricted to a single context.
+func (b *Block) ExternalBlocks(ctx context.Context, args struct{ Context *int32 }) ([]*ExternalBlock, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocks := b.backend.GetCoincidentExternalBlocks(hash)
+	ret := make([]*ExternalBlock, 0, len(blocks))
+	for _, block := range blocks {
+		context := int(block.Context().Int64())
+		if args.Context != nil && int(*args.Context) != context {
+			continue
+		}
+		ret = append(ret, &ExternalBlock{
+			backend: b.backend,
+			header:  block.Header(),
+			context: context,
+			block:   block,
+		})
+	}
+	return ret, nil
+}
+
+// CoincidentDominant returns the block as seen by the dominant chain of its
+// order, or nil if the block isn't coincident with a dominant chain. The body
+// is only available if the dominant block is known to the node.
+func (b *Block) CoincidentDominant(ctx context.Context) (*ExternalBlock, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := b.backend.Engine().GetDifficultyOrder(header)
+	if err != nil || order >= b.backend.ChainConfig().Context {
+		return nil, nil
+	}
+	block, _ := b.backend.GetExternalBlockByHashAndContext(header.Hash(), order)
+	return &ExternalBlock{
+		backend: b.backend,
+		header:  header,
+		context: order,
+		block:   block,
+	}, nil
+}
+
+// BlockContext represents the fields of a block header belonging to a single
+// context of the hierarchy.
+type BlockContext struct {
+	header  *types.Header
+	context int
+}
+
+// headerContexts splits a header into its per-context fields.
+func headerContexts(header *types.Header) []*BlockContext {
+	ret := make([]*BlockContext, 0, types.ContextDepth)
+	for i := 0; i < types.ContextDepth; i++ {
+		ret = append(ret, &BlockContext{header: header, context: i})
+	}
+	return ret
+}
+
+func (c *BlockContext) Context() int32 {
+	return int32(c.context)
+}
+
+func (c *BlockContext) Number() Long {
+	return Long(c.header.Number[c.context].Uint64())
+}
+
+func (c *BlockContext) ParentHash() common.Hash {
+	return c.header.ParentHash[c.context]
+}
+
+func (c *BlockContext) OmmerHash() common.Hash {
+	return c.header.UncleHash[c.context]
+}
+
+func (c *BlockContext) Miner() common.Address {
+	return c.header.Coinbase[c.context]
+}
+
+func (c *BlockContext) StateRoot() common.Hash {
+	return c.header.Root[c.context]
+}
+
+func (c *BlockContext) TransactionsRoot() common.Hash {
+	return c.header.TxHash[c.context]
+}
+
+func (c *BlockContext) ReceiptsRoot() common.Hash {
+	return c.header.ReceiptHash[c.context]
+}
+
+func (c *BlockContext) LogsBloom() hexutil.Bytes {
+	return c.header.Bloom[c.context].Bytes()
+}
+
+func (c *BlockContext) Difficulty() hexutil.Big {
+	if difficulty := c.header.Difficulty[c.context]; difficulty != nil {
+		return hexutil.Big(*difficulty)
+	}
+	return hexutil.Big{}
+}
+
+func (c *BlockContext) NetworkDifficulty() *hexutil.Big {
+	if len(c.header.NetworkDifficulty) <= c.context {
+		return nil
+	}
+	return (*hexutil.Big)(c.header.NetworkDifficulty[c.context])
+}
+
+func (c *BlockContext) GasLimit() Long {
+	return Long(c.header.GasLimit[c.context])
+}
+
+func (c *BlockContext) GasUsed() Long {
+	return Long(c.header.GasUsed[c.context])
+}
+
+func (c *BlockContext) ExtraData() hexutil.Bytes {
+	return c.header.Extra[c.context]
+}
+
+func (c *BlockContext) BaseFeePerGas() *hexutil.Big {
+	if len(c.header.BaseFee) <= c.context {
+		return nil
+	}
+	return (*hexutil.Big)(c.header.BaseFee[c.context])
+}
+
+// ExternalBlock represents a block of another chain of the hierarchy.
+// backend, header and context are mandatory; block is only set if the body
+// is known to the node.
+type ExternalBlock struct {
+	backend ethapi.Backend
+	header  *types.Header
+	context int
+	block   *types.ExternalBlock
+}
+
+func (e *ExternalBlock) Hash() common.Hash {
+	return e.header.Hash()
+}
+
+func (e *ExternalBlock) Context() int32 {
+	return int32(e.context)
+}
+
+func (e *ExternalBlock) Location() hexutil.Bytes {
+	return e.header.Location
+}
+
+func (e *ExternalBlock) Number() Long {
+	return Long(e.header.Number[e.context].Uint64())
+}
+
+func (e *ExternalBlock) Timestamp() hexutil.Uint64 {
+	return hexutil.Uint64(e.header.Time)
+}
+
+func (e *ExternalBlock) Contexts() []*BlockContext {
+	return headerContexts(e.header)
+}
+
+func (e *ExternalBlock) Transactions() *[]*Transaction {
+	if e.block == nil {
+		return nil
+	}
+	ret := make([]*Transaction, 0, len(e.block.Transactions()))
+	for _, tx := range e.block.Transactions() {
+		ret = append(ret, &Transaction{
+			backend: e.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+		})
+	}
+	return &ret
+}
+
 // BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
 // a block.
 type BlockFilterCriteria struct {
@@ -1096,7 +1348,7 @@ func (r *Resolver) Blocks(ctx context.Context, args struct {
 	if args.To != nil {
 		to = rpc.BlockNumber(*args.To)
 	} else {
-		to = rpc.BlockNumber(r.backend.CurrentBlock().Number().Int64())
+		to = rpc.BlockNumber(r.backend.CurrentBlock().Number(r.backend.ChainConfig().Context).Int64())
 	}
 	if to < from {
 		return []*Block{}, nil
@@ -1189,7 +1441,7 @@ func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
 		return hexutil.Big{}, err
 	}
 	if head := r.backend.CurrentHeader(); head.BaseFee != nil {
-		tipcap.Add(tipcap, head.BaseFee[types.QuaiNetworkContext])
+		tipcap.Add(tipcap, head.BaseFee[r.backend.ChainConfig().Context])
 	}
 	return (hexutil.Big)(*tipcap), nil
 }
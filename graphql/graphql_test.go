@@ -17,6 +17,8 @@
 package graphql
 
 import (
+	"crypto/ecdsa"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -26,8 +28,10 @@ import (
 	"time"
 
 	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
 	"github.com/spruce-solutions/go-quai/consensus/blake3"
 	"github.com/spruce-solutions/go-quai/core"
+	"github.com/spruce-solutions/go-quai/core/rawdb"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/core/vm"
 	"github.com/spruce-solutions/go-quai/crypto"
@@ -57,8 +61,78 @@ func TestBuildSchema(t *testing.T) {
 	}
 }
 
+// Tests that the hierarchy fields of a block resolve in the context of the
+// chain, along with the external blocks it links and their transactions.
+func TestGraphQLHierarchyFields(t *testing.T) {
+	defer func(context int) { types.QuaiNetworkContext = context }(types.QuaiNetworkContext)
+
+	stack := createNode(t, false, false)
+	defer stack.Close()
+	config, extBlock := createGQLServiceWithExternalBlocks(t, stack)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	var (
+		idRange  = config.ChainIDRange()
+		internal = extBlock.Transactions()[0]
+		external = extBlock.Transactions()[1]
+		zone     = hexutil.Bytes(config.NetworkOntology().AddressLocation(*external.To()))
+	)
+	if len(idRange) != 2 || zone.String() == "0x" {
+		t.Fatalf("zone chain has no address range")
+	}
+	for i, tt := range []struct {
+		body string
+		want string
+		code int
+	}{
+		{
+			body: `{"query": "{block(number:0){number location contexts{context number gasLimit}}}"}`,
+			want: `{"data":{"block":{"number":0,"location":"0x","contexts":[{"context":0,"number":0,"gasLimit":500000},{"context":1,"number":0,"gasLimit":500000},{"context":2,"number":0,"gasLimit":500000}]}}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:0){externalBlocks{hash context location number}}}"}`,
+			want: fmt.Sprintf(`{"data":{"block":{"externalBlocks":[{"hash":"%s","context":1,"location":"0x01","number":7}]}}}`, extBlock.Hash().Hex()),
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:0){externalBlocks(context:0){hash}}}"}`,
+			want: `{"data":{"block":{"externalBlocks":[]}}}`,
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:0){externalBlocks(context:1){transactions{hash isExternal destinationZone}}}}"}`,
+			want: fmt.Sprintf(`{"data":{"block":{"externalBlocks":[{"transactions":[{"hash":"%s","isExternal":false,"destinationZone":null},{"hash":"%s","isExternal":true,"destinationZone":"%s"}]}]}}}`, internal.Hash().Hex(), external.Hash().Hex(), zone),
+			code: 200,
+		},
+		{
+			body: `{"query": "{block(number:0){mixHash}}"}`,
+			want: `{"errors":[{"message":"Cannot query field \"mixHash\" on type \"Block\".","locations":[{"line":1,"column":18}]}]}`,
+			code: 400,
+		},
+	} {
+		resp, err := http.Post(fmt.Sprintf("%s/graphql", stack.HTTPEndpoint()), "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("could not post: %v", err)
+		}
+		bodyBytes, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("could not read from response body: %v", err)
+		}
+		if have := string(bodyBytes); have != tt.want {
+			t.Errorf("testcase %d %s,\nhave:\n%v\nwant:\n%v", i, tt.body, have, tt.want)
+		}
+		if tt.code != resp.StatusCode {
+			t.Errorf("testcase %d %s,\nwrong statuscode, have: %v, want: %v", i, tt.body, resp.StatusCode, tt.code)
+		}
+	}
+}
+
 // Tests that a graphQL request is successfully handled when graphql is enabled on the specified endpoint
 func TestGraphQLBlockSerialization(t *testing.T) {
+	defer func(context int) { types.QuaiNetworkContext = context }(types.QuaiNetworkContext)
+
 	stack := createNode(t, true, false)
 	defer stack.Close()
 	// start node
@@ -78,12 +152,12 @@ func TestGraphQLBlockSerialization(t *testing.T) {
 		},
 		{ // Should return info about latest block
 			body: `{"query": "{block{number,gasUsed,gasLimit}}","variables": null}`,
-			want: `{"data":{"block":{"number":10,"gasUsed":0,"gasLimit":11500000}}}`,
+			want: `{"data":{"block":{"number":10,"gasUsed":0,"gasLimit":100000}}}`,
 			code: 200,
 		},
 		{
 			body: `{"query": "{block(number:0){number,gasUsed,gasLimit}}","variables": null}`,
-			want: `{"data":{"block":{"number":0,"gasUsed":0,"gasLimit":11500000}}}`,
+			want: `{"data":{"block":{"number":0,"gasUsed":0,"gasLimit":100000}}}`,
 			code: 200,
 		},
 		{
@@ -98,7 +172,7 @@ func TestGraphQLBlockSerialization(t *testing.T) {
 		},
 		{
 			body: `{"query": "{block(number:\"0\"){number,gasUsed,gasLimit}}","variables": null}`,
-			want: `{"data":{"block":{"number":0,"gasUsed":0,"gasLimit":11500000}}}`,
+			want: `{"data":{"block":{"number":0,"gasUsed":0,"gasLimit":100000}}}`,
 			code: 200,
 		},
 		{
@@ -162,8 +236,15 @@ func TestGraphQLBlockSerialization(t *testing.T) {
 }
 
 func TestGraphQLBlockSerializationEIP2718(t *testing.T) {
-	stack := createNode(t, true, true)
+	defer func(context int) { types.QuaiNetworkContext = context }(types.QuaiNetworkContext)
+
+	stack := createNode(t, false, false)
 	defer stack.Close()
+	txs, sender := createGQLServiceWithTransactions(t, stack)
+	var (
+		from = strings.ToLower(sender.Hex())
+		dad  = strings.ToLower(txs[0].To().Hex())
+	)
 	// start node
 	if err := stack.Start(); err != nil {
 		t.Fatalf("could not start node: %v", err)
@@ -176,7 +257,7 @@ func TestGraphQLBlockSerializationEIP2718(t *testing.T) {
 	}{
 		{
 			body: `{"query": "{block {number transactions { from { address } to { address } value hash type accessList { address storageKeys } index}}}"}`,
-			want: `{"data":{"block":{"number":1,"transactions":[{"from":{"address":"0x71562b71999873db5b286df957af199ec94617f7"},"to":{"address":"0x0000000000000000000000000000000000000dad"},"value":"0x64","hash":"0xd864c9d7d37fade6b70164740540c06dd58bb9c3f6b46101908d6339db6a6a7b","type":0,"accessList":[],"index":0},{"from":{"address":"0x71562b71999873db5b286df957af199ec94617f7"},"to":{"address":"0x0000000000000000000000000000000000000dad"},"value":"0x32","hash":"0x19b35f8187b4e15fb59a9af469dca5dfa3cd363c11d372058c12f6482477b474","type":1,"accessList":[{"address":"0x0000000000000000000000000000000000000dad","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000000"]}],"index":1}]}}}`,
+			want: fmt.Sprintf(`{"data":{"block":{"number":1,"transactions":[{"from":{"address":"%[1]s"},"to":{"address":"%[2]s"},"value":"0x64","hash":"%[3]s","type":0,"accessList":[],"index":0},{"from":{"address":"%[1]s"},"to":{"address":"%[2]s"},"value":"0x32","hash":"%[4]s","type":1,"accessList":[{"address":"%[2]s","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000000"]}],"index":1}]}}}`, from, dad, txs[0].Hash().Hex(), txs[1].Hash().Hex()),
 			code: 200,
 		},
 	} {
@@ -234,14 +315,92 @@ func createNode(t *testing.T, gqlEnabled bool, txEnabled bool) *node.Node {
 	return stack
 }
 
+// zoneTestConfig returns the config of the first zone of the mainnet ontology,
+// and sets the context of the process to it.
+func zoneTestConfig(t *testing.T) *params.ChainConfig {
+	config, err := params.MainnetOntology.ChainConfig(params.MainnetPrimeChainConfig, []byte{1, 1})
+	if err != nil {
+		t.Fatalf("could not derive zone config: %v", err)
+	}
+	types.QuaiNetworkContext = config.Context
+	return config
+}
+
+// zoneTestGenesis returns a genesis of the zone chain with the given allocation,
+// at the gas limit of the generated blocks.
+func zoneTestGenesis(config *params.ChainConfig, alloc core.GenesisAlloc) *core.Genesis {
+	genesis := core.MainnetZoneGenesisBlock(config)
+	genesis.GasLimit = []uint64{params.MinGasLimit, params.MinGasLimit, params.MinGasLimit}
+	genesis.BaseFee = []*big.Int{big.NewInt(params.InitialBaseFee), big.NewInt(params.InitialBaseFee), big.NewInt(params.InitialBaseFee)}
+	if alloc != nil {
+		genesis.Alloc = alloc
+	}
+	return genesis
+}
+
+// setGenesisHashes points the genesis hashes of the config of the backend,
+// which are those of the mainnet genesis, at the genesis of the test chain.
+func setGenesisHashes(backend *eth.Ethereum) {
+	genesis := backend.BlockChain().Genesis().Hash()
+	backend.BlockChain().Config().GenesisHashes = []common.Hash{genesis, genesis, genesis}
+}
+
+// zoneTestKey returns a deterministic key whose address is in the range of the
+// zone chain.
+func zoneTestKey(t *testing.T, config *params.ChainConfig) (*ecdsa.PrivateKey, common.Address) {
+	idRange := config.ChainIDRange()
+	for i := int64(1); i < 1<<16; i++ {
+		key, err := crypto.ToECDSA(common.LeftPadBytes(big.NewInt(i).Bytes(), 32))
+		if err != nil {
+			t.Fatalf("could not create key: %v", err)
+		}
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		if int(address[0]) >= idRange[0] && int(address[0]) <= idRange[1] {
+			return key, address
+		}
+	}
+	t.Fatalf("no key in the range of the zone")
+	return nil, common.Address{}
+}
+
+// generateSealedChain generates n blocks on top of the head of the backend and
+// seals each of them at the difficulty of the zone before building the next,
+// so that they are accepted by the fake proof of work.
+func generateSealedChain(t *testing.T, backend *eth.Ethereum, n int, gen func(int, *core.BlockGen)) []*types.Block {
+	var (
+		config = backend.BlockChain().Config()
+		engine = blake3.NewContextFaker(config.Context)
+		parent = backend.BlockChain().CurrentBlock()
+		blocks = make([]*types.Block, n)
+	)
+	for i := range blocks {
+		chain, _ := core.GenerateChain(config, parent, engine, backend.ChainDb(), 1, func(_ int, b *core.BlockGen) {
+			if gen != nil {
+				gen(i, b)
+			}
+		})
+		header := chain[0].Header()
+		for nonce := uint64(0); ; nonce++ {
+			binary.BigEndian.PutUint64(header.Nonce[:], nonce)
+			if order, err := engine.GetDifficultyOrder(header); err == nil && order == config.Context {
+				break
+			}
+			if nonce == 1<<26 {
+				t.Fatalf("could not seal block %d", i)
+			}
+		}
+		blocks[i] = chain[0].WithSeal(header)
+		parent = blocks[i]
+	}
+	return blocks
+}
+
 func createGQLService(t *testing.T, stack *node.Node) {
+	config := zoneTestConfig(t)
+
 	// create backend
 	ethConf := &ethconfig.Config{
-		Genesis: &core.Genesis{
-			Config:     params.AllEthashProtocolChanges,
-			GasLimit:   11500000,
-			Difficulty: big.NewInt(1048576),
-		},
+		Genesis: zoneTestGenesis(config, nil),
 		Blake3: blake3.Config{
 			Fakepow: true,
 		},
@@ -252,14 +411,15 @@ func createGQLService(t *testing.T, stack *node.Node) {
 		TrieDirtyCache:          5,
 		TrieTimeout:             60 * time.Minute,
 		SnapshotCache:           5,
+		ExternalBlockCache:      5,
 	}
 	ethBackend, err := eth.New(stack, ethConf)
 	if err != nil {
 		t.Fatalf("could not create eth backend: %v", err)
 	}
+	setGenesisHashes(ethBackend)
 	// Create some blocks and import them
-	chain, _ := core.GenerateChain(params.AllEthashProtocolChanges, ethBackend.BlockChain().Genesis(),
-		blake3.NewFaker(), ethBackend.ChainDb(), 10, func(i int, gen *core.BlockGen) {})
+	chain := generateSealedChain(t, ethBackend, 10, nil)
 	_, err = ethBackend.BlockChain().InsertChain(chain)
 	if err != nil {
 		t.Fatalf("could not create import blocks: %v", err)
@@ -271,34 +431,32 @@ func createGQLService(t *testing.T, stack *node.Node) {
 	}
 }
 
-func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
+// createGQLServiceWithTransactions creates a zone chain with a block carrying
+// a legacy and an access list transaction, and returns them with their sender.
+func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) ([]*types.Transaction, common.Address) {
+	config := zoneTestConfig(t)
+
 	// create backend
-	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
-	address := crypto.PubkeyToAddress(key.PublicKey)
+	key, address := zoneTestKey(t, config)
 	funds := big.NewInt(1000000000000000)
-	dad := common.HexToAddress("0x0000000000000000000000000000000000000dad")
+	idRange := config.ChainIDRange()
+	dad := common.Address{0: byte(idRange[0]), 18: 0x0d, 19: 0xad}
 
 	ethConf := &ethconfig.Config{
-		Genesis: &core.Genesis{
-			Config:     params.AllEthashProtocolChanges,
-			GasLimit:   11500000,
-			Difficulty: big.NewInt(1048576),
-			Alloc: core.GenesisAlloc{
-				address: {Balance: funds},
-				// The address 0xdad sloads 0x00 and 0x01
-				dad: {
-					Code: []byte{
-						byte(vm.PC),
-						byte(vm.PC),
-						byte(vm.SLOAD),
-						byte(vm.SLOAD),
-					},
-					Nonce:   0,
-					Balance: big.NewInt(0),
+		Genesis: zoneTestGenesis(config, core.GenesisAlloc{
+			address: {Balance: funds},
+			// The address 0xdad sloads 0x00 and 0x01
+			dad: {
+				Code: []byte{
+					byte(vm.PC),
+					byte(vm.PC),
+					byte(vm.SLOAD),
+					byte(vm.SLOAD),
 				},
+				Nonce:   0,
+				Balance: big.NewInt(0),
 			},
-			BaseFee: big.NewInt(params.InitialBaseFee),
-		},
+		}),
 		Blake3: blake3.Config{
 			Fakepow: true,
 		},
@@ -309,13 +467,15 @@ func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
 		TrieDirtyCache:          5,
 		TrieTimeout:             60 * time.Minute,
 		SnapshotCache:           5,
+		ExternalBlockCache:      5,
 	}
 
 	ethBackend, err := eth.New(stack, ethConf)
 	if err != nil {
 		t.Fatalf("could not create eth backend: %v", err)
 	}
-	signer := types.LatestSigner(ethConf.Genesis.Config)
+	setGenesisHashes(ethBackend)
+	signer := types.LatestSigner(config)
 
 	legacyTx, _ := types.SignNewTx(key, signer, &types.LegacyTx{
 		Nonce:    uint64(0),
@@ -325,7 +485,7 @@ func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
 		GasPrice: big.NewInt(params.InitialBaseFee),
 	})
 	envelopTx, _ := types.SignNewTx(key, signer, &types.AccessListTx{
-		ChainID:  ethConf.Genesis.Config.ChainID,
+		ChainID:  config.ChainID,
 		Nonce:    uint64(1),
 		To:       &dad,
 		Gas:      30000,
@@ -338,12 +498,11 @@ func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
 	})
 
 	// Create some blocks and import them
-	chain, _ := core.GenerateChain(params.AllEthashProtocolChanges, ethBackend.BlockChain().Genesis(),
-		blake3.NewFaker(), ethBackend.ChainDb(), 1, func(i int, b *core.BlockGen) {
-			b.SetCoinbase(common.Address{1})
-			b.AddTx(legacyTx)
-			b.AddTx(envelopTx)
-		})
+	chain := generateSealedChain(t, ethBackend, 1, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{1})
+		b.AddTx(legacyTx)
+		b.AddTx(envelopTx)
+	})
 
 	_, err = ethBackend.BlockChain().InsertChain(chain)
 	if err != nil {
@@ -354,4 +513,53 @@ func createGQLServiceWithTransactions(t *testing.T, stack *node.Node) {
 	if err != nil {
 		t.Fatalf("could not create graphql service: %v", err)
 	}
+	return []*types.Transaction{legacyTx, envelopTx}, address
+}
+
+// createGQLServiceWithExternalBlocks creates a zone chain at its genesis, with
+// a region block linked by the genesis carrying a transaction within the zone
+// and one sent to another zone.
+func createGQLServiceWithExternalBlocks(t *testing.T, stack *node.Node) (*params.ChainConfig, *types.ExternalBlock) {
+	config := zoneTestConfig(t)
+
+	ethConf := &ethconfig.Config{
+		Genesis: core.MainnetZoneGenesisBlock(config),
+		Blake3: blake3.Config{
+			Fakepow: true,
+		},
+		NetworkId:               1337,
+		TrieCleanCache:          5,
+		TrieCleanCacheJournal:   "triecache",
+		TrieCleanCacheRejournal: 60 * time.Minute,
+		TrieDirtyCache:          5,
+		TrieTimeout:             60 * time.Minute,
+		SnapshotCache:           5,
+		ExternalBlockCache:      5,
+	}
+	ethBackend, err := eth.New(stack, ethConf)
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	idRange := config.ChainIDRange()
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{To: &common.Address{byte(idRange[0])}, Value: big.NewInt(1), Gas: params.TxGas}),
+		types.NewTx(&types.LegacyTx{To: &common.Address{byte(idRange[1] + 1)}, Value: big.NewInt(2), Gas: params.TxGas}),
+	}
+	header := types.NewEmptyHeader()
+	for i := range header.Number {
+		header.Number[i] = big.NewInt(7)
+	}
+	header.Location = []byte{1}
+	extBlock := types.NewExternalBlockWithHeader(header).WithBody(txs, nil, nil, big.NewInt(int64(params.REGION)))
+
+	genesis := ethBackend.BlockChain().Genesis()
+	rawdb.WriteExternalBlock(ethBackend.ChainDb(), extBlock)
+	rawdb.WriteExternalBlockIndex(ethBackend.ChainDb(), extBlock.Hash(), extBlock.Context().Uint64(), 0, genesis.Hash())
+
+	// create gql service
+	err = New(stack, ethBackend.APIBackend, []string{}, []string{})
+	if err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	return config, extBlock
 }
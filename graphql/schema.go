@@ -138,6 +138,66 @@ const schema string = `
         #Envelope transaction support
         type: Int
         accessList: [AccessTuple!]
+        # IsExternal is true for transactions crossing chains, either ETxs
+        # applied on this chain or transactions sent to another chain.
+        isExternal: Boolean!
+        # DestinationZone is the location of the zone an external transaction
+        # is destined for. This is null for transactions staying on this chain.
+        destinationZone: Bytes
+    }
+
+    # BlockContext holds the fields of a block header belonging to a single
+    # context of the hierarchy: 0 for prime, 1 for region and 2 for zone.
+    type BlockContext {
+        # Context is the index of the context these fields belong to.
+        context: Int!
+        # Number is the number of the block in this context.
+        number: Long!
+        # ParentHash is the hash of the parent block in this context.
+        parentHash: Bytes32!
+        # OmmerHash is the keccak256 hash of the ommers in this context.
+        ommerHash: Bytes32!
+        # Miner is the address rewarded in this context.
+        miner: Address!
+        # StateRoot is the root of the state trie in this context.
+        stateRoot: Bytes32!
+        # TransactionsRoot is the root of the transaction trie in this context.
+        transactionsRoot: Bytes32!
+        # ReceiptsRoot is the root of the receipt trie in this context.
+        receiptsRoot: Bytes32!
+        # LogsBloom is the bloom filter of the logs in this context.
+        logsBloom: Bytes!
+        # Difficulty is the difficulty of the block in this context.
+        difficulty: BigInt!
+        # NetworkDifficulty is the network difficulty in this context.
+        networkDifficulty: BigInt
+        # GasLimit is the gas limit in this context.
+        gasLimit: Long!
+        # GasUsed is the gas used in this context.
+        gasUsed: Long!
+        # ExtraData is the extra data supplied by the miner in this context.
+        extraData: Bytes!
+        # BaseFeePerGas is the base fee in this context.
+        baseFeePerGas: BigInt
+    }
+
+    # ExternalBlock is a block of another chain of the hierarchy.
+    type ExternalBlock {
+        # Hash is the block hash of this block.
+        hash: Bytes32!
+        # Context is the context of the chain the block belongs to.
+        context: Int!
+        # Location is the location of the chain the block originated from.
+        location: Bytes!
+        # Number is the number of this block in its context.
+        number: Long!
+        # Timestamp is the unix timestamp at which this block was mined.
+        timestamp: Long!
+        # Contexts is the list of the header fields of every context.
+        contexts: [BlockContext!]!
+        # Transactions is the list of transactions of this block. This will be
+        # null if the body of the block isn't known to the node.
+        transactions: [Transaction!]
     }
 
     # BlockFilterCriteria encapsulates log filter criteria for a filter applied
@@ -194,8 +254,6 @@ const schema string = `
         # LogsBloom is a bloom filter that can be used to check if a block may
         # contain log entries matching a filter.
         logsBloom: Bytes!
-        # MixHash is the hash that was used as an input to the PoW process.
-        mixHash: Bytes32!
         # Difficulty is a measure of the difficulty of mining this block.
         difficulty: BigInt!
         # TotalDifficulty is the sum of all difficulty values up to and including
@@ -231,6 +289,20 @@ const schema string = `
         # EstimateGas estimates the amount of gas that will be required for
         # successful execution of a transaction at the current block's state.
         estimateGas(data: CallData!): Long!
+        # Location is the location of the chain the block originated from.
+        location: Bytes!
+        # Order is the highest context whose difficulty the block satisfies,
+        # or null if it doesn't satisfy any.
+        order: Int
+        # Contexts is the list of the header fields of every context.
+        contexts: [BlockContext!]!
+        # ExternalBlocks returns the external blocks linked by this block,
+        # restricted to a single context if one is supplied.
+        externalBlocks(context: Int): [ExternalBlock!]!
+        # CoincidentDominant is this block as seen by the dominant chain of its
+        # order. This will be null if the block isn't coincident with a
+        # dominant chain.
+        coincidentDominant: ExternalBlock
     }
 
     # CallData represents the data associated with a local contract call.
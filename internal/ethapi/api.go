@@ -65,7 +65,7 @@ func (s *PublicEthereumAPI) GasPrice(ctx context.Context) (*hexutil.Big, error)
 		return nil, err
 	}
 	if head := s.b.CurrentHeader(); head.BaseFee != nil {
-		tipcap.Add(tipcap, head.BaseFee[types.QuaiNetworkContext])
+		tipcap.Add(tipcap, head.BaseFee[s.b.ChainConfig().Context])
 	}
 	return (*hexutil.Big)(tipcap), err
 }
@@ -197,6 +197,41 @@ func (s *PublicTxPoolAPI) ContentFrom(addr common.Address) map[string]map[string
 	return content
 }
 
+// EtxContent returns the inbound external transactions waiting to be applied
+// and the refunds owed to local senders for the ETxs which expired or failed in
+// their destination zone, keyed by hash.
+func (s *PublicTxPoolAPI) EtxContent() map[string]map[string]map[string]interface{} {
+	content := map[string]map[string]map[string]interface{}{
+		"pending": make(map[string]map[string]interface{}),
+		"refunds": make(map[string]map[string]interface{}),
+	}
+	pending, refunds := s.b.ETxPoolContent()
+	curHeader := s.b.CurrentHeader()
+	for _, etx := range pending {
+		fields := map[string]interface{}{
+			"transaction":    newRPCPendingTransaction(etx.Tx, curHeader, s.b),
+			"sender":         etx.Sender,
+			"externalBlock":  etx.ExternalBlock,
+			"originLocation": hexutil.Bytes(etx.OriginLocation),
+			"arrival":        hexutil.Uint64(etx.Arrival),
+			"attempts":       hexutil.Uint64(etx.Attempts),
+		}
+		if etx.LastError != "" {
+			fields["lastError"] = etx.LastError
+		}
+		content["pending"][etx.Tx.Hash().Hex()] = fields
+	}
+	for _, refund := range refunds {
+		content["refunds"][refund.Hash.Hex()] = map[string]interface{}{
+			"sender":              refund.Sender,
+			"value":               (*hexutil.Big)(refund.Value),
+			"destinationBlock":    refund.DestinationBlock,
+			"destinationLocation": hexutil.Bytes(refund.DestinationLocation),
+		}
+	}
+	return content
+}
+
 // Status returns the number of pending and queued transaction in the pool.
 func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	pending, queue := s.b.Stats()
@@ -342,12 +377,23 @@ func (s *PrivateAccountAPI) DeriveAccount(url string, path string, pin *bool) (a
 }
 
 // NewAccount will create a new account and returns the address for the new account.
-func (s *PrivateAccountAPI) NewAccount(password string) (common.Address, error) {
+// The address belongs to the zone at the given location, or to the chain of the
+// node if no location is given.
+func (s *PrivateAccountAPI) NewAccount(password string, location *hexutil.Bytes) (common.Address, error) {
 	ks, err := fetchKeystore(s.am)
 	if err != nil {
 		return common.Address{}, err
 	}
 	id := s.b.ChainConfig().ChainIDRange()
+	if location != nil {
+		ontology := s.b.ChainConfig().NetworkOntology()
+		if ontology == nil {
+			return common.Address{}, errors.New("network has no ontology")
+		}
+		if id, err = ontology.LocationPrefixRange(*location); err != nil {
+			return common.Address{}, err
+		}
+	}
 	acc, err := ks.NewAccount(password, id)
 	if err == nil {
 		log.Info("Your new key was generated", "address", acc.Address)
@@ -609,7 +655,7 @@ func NewPublicBlockChainAPI(b Backend) *PublicBlockChainAPI {
 // ChainId is the EIP-155 replay-protection chain id for the current ethereum chain config.
 func (api *PublicBlockChainAPI) ChainId() (*hexutil.Big, error) {
 	// if current block is at or past the EIP-155 replay-protection fork block, return chainID from config
-	if config := api.b.ChainConfig(); config.IsEIP155(api.b.CurrentBlock().Number()) {
+	if config := api.b.ChainConfig(); config.IsEIP155(api.b.CurrentBlock().Number(config.Context)) {
 		return (*hexutil.Big)(config.ChainID), nil
 	}
 	return nil, fmt.Errorf("chain not synced beyond EIP-155 replay-protection fork block")
@@ -618,7 +664,7 @@ func (api *PublicBlockChainAPI) ChainId() (*hexutil.Big, error) {
 // BlockNumber returns the block number of the chain head.
 func (s *PublicBlockChainAPI) BlockNumber() hexutil.Uint64 {
 	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
-	return hexutil.Uint64(header.Number[types.QuaiNetworkContext].Uint64())
+	return hexutil.Uint64(header.Number[s.b.ChainConfig().Context].Uint64())
 }
 
 // GetBalance returns the amount of wei for the given address in the state of the
@@ -797,7 +843,7 @@ func (s *PublicBlockChainAPI) GetUncleByBlockHashAndIndex(ctx context.Context, b
 	if block != nil {
 		uncles := block.Uncles()
 		if index >= hexutil.Uint(len(uncles)) {
-			log.Debug("Requested uncle not found", "number", block.Number(), "hash", blockHash, "index", index)
+			log.Debug("Requested uncle not found", "number", block.Number(s.b.ChainConfig().Context), "hash", blockHash, "index", index)
 			return nil, nil
 		}
 		block = types.NewBlockWithHeader(uncles[index])
@@ -807,7 +853,7 @@ func (s *PublicBlockChainAPI) GetUncleByBlockHashAndIndex(ctx context.Context, b
 	if pendBlock != nil && pendBlock.Hash() == blockHash {
 		uncles := pendBlock.Uncles()
 		if index >= hexutil.Uint(len(uncles)) {
-			log.Debug("Requested uncle not found in pending block", "number", block.Number(), "hash", blockHash, "index", index)
+			log.Debug("Requested uncle not found in pending block", "number", block.Number(s.b.ChainConfig().Context), "hash", blockHash, "index", index)
 			return nil, nil
 		}
 		block = types.NewBlockWithHeader(uncles[index])
@@ -931,7 +977,7 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 	defer cancel()
 
 	// Get a new instance of the EVM.
-	msg, err := args.ToMessage(globalGasCap, header.BaseFee[types.QuaiNetworkContext])
+	msg, err := args.ToMessage(globalGasCap, header.BaseFee[b.ChainConfig().Context])
 	if err != nil {
 		return nil, err
 	}
@@ -1034,7 +1080,7 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 		if block == nil {
 			return 0, errors.New("block not found")
 		}
-		hi = block.GasLimit()
+		hi = block.GasLimit(b.ChainConfig().Context)
 	}
 	// Normalize the max fee per gas the call is willing to spend.
 	var feeCap *big.Int
@@ -1202,9 +1248,9 @@ func FormatLogs(logs []vm.StructLog) []StructLogRes {
 	return formatted
 }
 
-// RPCMarshalHeader converts the given header to the RPC output .
-func RPCMarshalEthHeader(head *types.Header) map[string]interface{} {
-	context := types.QuaiNetworkContext
+// RPCMarshalHeader converts the given header to the RPC output of the given
+// context.
+func RPCMarshalEthHeader(head *types.Header, context int) map[string]interface{} {
 	bloom := types.Bloom{}
 	if len(head.Bloom) > len(params.FullerOntology) {
 		bloom = head.Bloom[context]
@@ -1238,8 +1284,8 @@ func RPCMarshalEthHeader(head *types.Header) map[string]interface{} {
 	return result
 }
 
-func RPCMarshalEthBlock(block *types.Block, inclTx bool, fullTx bool) (map[string]interface{}, error) {
-	fields := RPCMarshalEthHeader(block.Header())
+func RPCMarshalEthBlock(block *types.Block, inclTx bool, fullTx bool, context int) (map[string]interface{}, error) {
+	fields := RPCMarshalEthHeader(block.Header(), context)
 	fields["size"] = hexutil.Uint64(block.Size())
 
 	if inclTx {
@@ -1248,7 +1294,7 @@ func RPCMarshalEthBlock(block *types.Block, inclTx bool, fullTx bool) (map[strin
 		}
 		if fullTx {
 			formatTx = func(tx *types.Transaction) (interface{}, error) {
-				return newRPCTransactionFromBlockHash(block, tx.Hash()), nil
+				return newRPCTransactionFromBlockHash(block, tx.Hash(), context), nil
 			}
 		}
 		txs := block.Transactions()
@@ -1307,18 +1353,18 @@ func RPCMarshalReceipt(receipt *types.Receipt) (map[string]interface{}, error) {
 // rpcMarshalHeader uses the generalized output filler, then adds the total difficulty field, which requires
 // a `PublicBlockchainAPI`.
 func (s *PublicBlockChainAPI) rpcMarshalEthHeader(ctx context.Context, header *types.Header) map[string]interface{} {
-	fields := RPCMarshalEthHeader(header)
-	fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, header.Hash())[types.QuaiNetworkContext])
+	fields := RPCMarshalEthHeader(header, s.b.ChainConfig().Context)
+	fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, header.Hash())[s.b.ChainConfig().Context])
 	return fields
 }
 
 func (s *PublicBlockChainAPI) rpcMarshalEthBlock(ctx context.Context, b *types.Block, inclTx bool, fullTx bool) (map[string]interface{}, error) {
-	fields, err := RPCMarshalEthBlock(b, inclTx, fullTx)
+	fields, err := RPCMarshalEthBlock(b, inclTx, fullTx, s.b.ChainConfig().Context)
 	if err != nil {
 		return nil, err
 	}
 	if inclTx {
-		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[types.QuaiNetworkContext])
+		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[s.b.ChainConfig().Context])
 	}
 	return fields, err
 }
@@ -1326,12 +1372,12 @@ func (s *PublicBlockChainAPI) rpcMarshalEthBlock(ctx context.Context, b *types.B
 // rpcMarshalBlock uses the generalized output filler, then adds the total difficulty field, which requires
 // a `PublicBlockchainAPI`.
 func (s *PublicBlockChainAPI) rpcMarshalBlockWithReceipts(ctx context.Context, b *types.Block, receipts types.Receipts, inclTx bool, fullTx bool) (map[string]interface{}, error) {
-	fields, err := RPCMarshalEthBlock(b, inclTx, fullTx)
+	fields, err := RPCMarshalEthBlock(b, inclTx, fullTx, s.b.ChainConfig().Context)
 	if err != nil {
 		return nil, err
 	}
 	if inclTx {
-		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[types.QuaiNetworkContext])
+		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[s.b.ChainConfig().Context])
 	}
 	fieldReceipts := make([]interface{}, len(receipts))
 	for i, receipt := range receipts {
@@ -1431,12 +1477,12 @@ func newRPCPendingTransaction(tx *types.Transaction, current *types.Header, back
 }
 
 // newRPCTransactionFromBlockIndex returns a transaction that will serialize to the RPC representation.
-func newRPCTransactionFromBlockIndex(b *types.Block, index uint64) *RPCTransaction {
+func newRPCTransactionFromBlockIndex(b *types.Block, index uint64, context int) *RPCTransaction {
 	txs := b.Transactions()
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	return newRPCTransaction(txs[index], b.Hash(), b.NumberU64(), index, b.BaseFee())
+	return newRPCTransaction(txs[index], b.Hash(), b.NumberU64(context), index, b.BaseFee(context))
 }
 
 // newRPCRawTransactionFromBlockIndex returns the bytes of a transaction given a block and a transaction index.
@@ -1450,10 +1496,10 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.By
 }
 
 // newRPCTransactionFromBlockHash returns a transaction that will serialize to the RPC representation.
-func newRPCTransactionFromBlockHash(b *types.Block, hash common.Hash) *RPCTransaction {
+func newRPCTransactionFromBlockHash(b *types.Block, hash common.Hash, context int) *RPCTransaction {
 	for idx, tx := range b.Transactions() {
 		if tx.Hash() == hash {
-			return newRPCTransactionFromBlockIndex(b, uint64(idx))
+			return newRPCTransactionFromBlockIndex(b, uint64(idx), context)
 		}
 	}
 	return nil
@@ -1510,7 +1556,7 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 		to = crypto.CreateAddress(args.from(), uint64(*args.Nonce))
 	}
 	// Retrieve the precompiles since they don't need to be added to the access list
-	precompiles := vm.ActivePrecompiles(b.ChainConfig().Rules(header.Number[types.QuaiNetworkContext]))
+	precompiles := vm.ActivePrecompiles(b.ChainConfig().Rules(header.Number[b.ChainConfig().Context]))
 
 	// Create an initial tracer
 	prevTracer := vm.NewAccessListTracer(nil, args.from(), to, precompiles)
@@ -1535,7 +1581,7 @@ func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrH
 		statedb := db.Copy()
 		// Set the accesslist to the last al
 		args.AccessList = &accessList
-		msg, err := args.ToMessage(b.RPCGasCap(), header.BaseFee[types.QuaiNetworkContext])
+		msg, err := args.ToMessage(b.RPCGasCap(), header.BaseFee[b.ChainConfig().Context])
 		if err != nil {
 			return nil, 0, nil, err
 		}
@@ -1594,7 +1640,7 @@ func (s *PublicTransactionPoolAPI) GetBlockTransactionCountByHash(ctx context.Co
 // GetTransactionByBlockNumberAndIndex returns the transaction for the given block number and index.
 func (s *PublicTransactionPoolAPI) GetTransactionByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) *RPCTransaction {
 	if block, _ := s.b.BlockByNumber(ctx, blockNr); block != nil {
-		return newRPCTransactionFromBlockIndex(block, uint64(index))
+		return newRPCTransactionFromBlockIndex(block, uint64(index), s.b.ChainConfig().Context)
 	}
 	return nil
 }
@@ -1602,7 +1648,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionByBlockNumberAndIndex(ctx conte
 // GetTransactionByBlockHashAndIndex returns the transaction for the given block hash and index.
 func (s *PublicTransactionPoolAPI) GetTransactionByBlockHashAndIndex(ctx context.Context, blockHash common.Hash, index hexutil.Uint) *RPCTransaction {
 	if block, _ := s.b.BlockByHash(ctx, blockHash); block != nil {
-		return newRPCTransactionFromBlockIndex(block, uint64(index))
+		return newRPCTransactionFromBlockIndex(block, uint64(index), s.b.ChainConfig().Context)
 	}
 	return nil
 }
@@ -1654,7 +1700,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, has
 		if err != nil {
 			return nil, err
 		}
-		return newRPCTransaction(tx, blockHash, blockNumber, index, header.BaseFee[types.QuaiNetworkContext]), nil
+		return newRPCTransaction(tx, blockHash, blockNumber, index, header.BaseFee[s.b.ChainConfig().Context]), nil
 	}
 	// No finalized transaction, try to retrieve it from the pool
 	if tx := s.b.GetPoolTransaction(hash); tx != nil {
@@ -1724,7 +1770,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		if err != nil {
 			return nil, err
 		}
-		gasPrice := new(big.Int).Add(header.BaseFee[types.QuaiNetworkContext], tx.EffectiveGasTipValue(header.BaseFee[types.QuaiNetworkContext]))
+		gasPrice := new(big.Int).Add(header.BaseFee[s.b.ChainConfig().Context], tx.EffectiveGasTipValue(header.BaseFee[s.b.ChainConfig().Context]))
 		fields["effectiveGasPrice"] = hexutil.Uint64(gasPrice.Uint64())
 	}
 	// Assign receipt status or post state.
@@ -1771,7 +1817,7 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 		return common.Hash{}, err
 	}
 	// Print a log with full tx details for manual investigations and interventions
-	signer := types.MakeSigner(b.ChainConfig(), b.CurrentBlock().Number())
+	signer := types.MakeSigner(b.ChainConfig(), b.CurrentBlock().Number(b.ChainConfig().Context))
 	from, err := types.Sender(signer, tx)
 	if err != nil {
 		return common.Hash{}, err
@@ -74,6 +74,7 @@ type Backend interface {
 	PendingBlockAndReceipts() (*types.Block, types.Receipts)
 	AddExternalBlock(block *types.ExternalBlock) error
 	GetExternalBlockByHashAndContext(hash common.Hash, context int) (*types.ExternalBlock, error)
+	GetCoincidentExternalBlocks(hash common.Hash) []*types.ExternalBlock
 	GetAncestorByLocation(hash common.Hash, location []byte) (*types.Header, error)
 	GetSubordinateSet(stopHash common.Hash, location []byte) ([]common.Hash, error)
 	GetTerminusAtOrder(header *types.Header, order int) (common.Hash, error)
@@ -82,6 +83,13 @@ type Backend interface {
 	HLCRReorg(block *types.Block) (bool, error)
 	PCRC(header *types.Header, order int) (types.PCRCTermini, error)
 	PCCRC(header *types.Header, order int) (types.PCRCTermini, error)
+	GetETxStatus(hash common.Hash) *types.ETxStatus
+	GetReorgHistory(count uint64) []*types.ReorgRecord
+	FaultProofs() []*types.FaultProof
+	AddFaultProof(proof *types.FaultProof) error
+	GetSupply(hash common.Hash) *types.Supply
+	ZoneSupplies(ctx context.Context) ([]*types.Supply, error)
+	NetworkSupply(ctx context.Context) (*core.NetworkSupply, error)
 	EventMux() *event.TypeMux
 	CalculateBaseFee(header *types.Header) *big.Int
 	GetUncleFromWorker(uncleHash common.Hash) (*types.Block, error)
@@ -95,6 +103,7 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions)
+	ETxPoolContent() ([]*types.PendingETx, []*types.ETxRefund)
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 
 	// Filter API
@@ -107,6 +116,8 @@ type Backend interface {
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeReOrgEvent(ch chan<- core.ReOrgRollup) event.Subscription
 	SubscribeMissingExternalBlockEvent(ch chan<- core.MissingExternalBlock) event.Subscription
+	SubscribeETxStatusEvent(ch chan<- core.ETxStatusEvent) event.Subscription
+	SubscribeReorgJournalEvent(ch chan<- core.ReorgJournalEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
 	Engine() consensus.Engine
@@ -135,6 +146,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicBlockChainQuaiAPI(apiBackend),
 			Public:    true,
+		}, {
+			Namespace:     "domsub",
+			Version:       "1.0",
+			Service:       NewPrivateDomSubAPI(apiBackend),
+			Authenticated: true,
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
@@ -0,0 +1,164 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/core"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/log"
+)
+
+// PrivateDomSubAPI provides the methods the dominant and subordinate chains use
+// to drive the chain, inserting blocks and rolling it back. It is only served on
+// the authenticated endpoint.
+type PrivateDomSubAPI struct {
+	b Backend
+}
+
+// NewPrivateDomSubAPI creates the API used by the dominant and subordinate
+// chains.
+func NewPrivateDomSubAPI(b Backend) *PrivateDomSubAPI {
+	return &PrivateDomSubAPI{b}
+}
+
+// SendMinedBlock will run checks on the block and add to canonical chain if valid.
+func (s *PrivateDomSubAPI) SendMinedBlock(ctx context.Context, raw json.RawMessage) error {
+	// Decode header and transactions.
+	var head *types.Header
+	var body rpcBlock
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return err
+	}
+
+	// Load uncles because they are not included in the block response.
+	txs := make([]*types.Transaction, len(body.Transactions))
+	for i, tx := range body.Transactions {
+		txs[i] = tx.tx
+	}
+
+	uncles := make([]*types.Header, len(body.Uncles))
+	for i, uncle := range body.Uncles {
+		uncles[i] = uncle
+	}
+
+	block := types.NewBlockWithHeader(head).WithBody(txs, uncles)
+	log.Info("Retrieved mined block", "number", head.Number, "location", head.Location)
+	s.b.InsertBlock(ctx, block)
+	// Broadcast the block and announce chain insertion event
+	if block.Header() != nil {
+		s.b.EventMux().Post(core.NewMinedBlockEvent{Block: block})
+	}
+
+	return nil
+}
+
+type rpcReorgData struct {
+	Header     *types.Header   `json:"header"`
+	NewHeaders []*types.Header `json:"newHeaders"`
+	OldHeaders []*types.Header `json:"oldHeaders"`
+}
+
+// ReOrgRollBack will send the reorg data to perform reorg rollback
+func (s *PrivateDomSubAPI) SendReOrgData(ctx context.Context, raw json.RawMessage) error {
+	// Decode reOrgHeader and body.
+	var reorgData rpcReorgData
+	if err := json.Unmarshal(raw, &reorgData); err != nil {
+		return err
+	}
+
+	s.b.ReOrgRollBack(reorgData.Header, reorgData.NewHeaders, reorgData.OldHeaders)
+	return nil
+}
+
+type rpcExternalBlock struct {
+	Hash         common.Hash      `json:"hash"`
+	Transactions []rpcTransaction `json:"transactions"`
+	Uncles       []*types.Header  `json:"uncles"`
+	Receipts     []*types.Receipt `json:"receipts"`
+	Context      *big.Int         `json:"context"`
+}
+
+// SendExternalBlock will run checks on the block and add to canonical chain if valid.
+func (s *PrivateDomSubAPI) SendExternalBlock(ctx context.Context, raw json.RawMessage) error {
+	// Decode header and transactions.
+	var head *types.Header
+	var body rpcExternalBlock
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return err
+	}
+
+	// Load transactions
+	txs := make([]*types.Transaction, len(body.Transactions))
+	for i, tx := range body.Transactions {
+		txs[i] = tx.tx
+	}
+
+	uncles := make([]*types.Header, len(body.Uncles))
+	for i, uncle := range body.Uncles {
+		uncles[i] = uncle
+	}
+
+	receipts := make([]*types.Receipt, len(body.Receipts))
+	for i, receipt := range body.Receipts {
+		receipts[i] = receipt
+	}
+
+	block := types.NewExternalBlockWithHeader(head).WithBody(txs, uncles, receipts, body.Context)
+
+	s.b.AddExternalBlock(block)
+
+	return nil
+}
+
+// HLCRReorg checks whether the block wins the HLCR fork choice and rolls the
+// chain back to it if it does.
+func (s *PrivateDomSubAPI) HLCRReorg(ctx context.Context, raw json.RawMessage) (bool, error) {
+	// Decode header and transactions.
+	var head *types.Header
+	var body rpcBlock
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return false, err
+	}
+
+	// Load uncles because they are not included in the block response.
+	txs := make([]*types.Transaction, len(body.Transactions))
+	for i, tx := range body.Transactions {
+		txs[i] = tx.tx
+	}
+
+	uncles := make([]*types.Header, len(body.Uncles))
+	for i, uncle := range body.Uncles {
+		uncles[i] = uncle
+	}
+
+	block := types.NewBlockWithHeader(head).WithBody(txs, uncles)
+	return s.b.HLCRReorg(block)
+}
@@ -26,10 +26,13 @@ import (
 
 	"github.com/spruce-solutions/go-quai/common"
 	"github.com/spruce-solutions/go-quai/common/hexutil"
+	"github.com/spruce-solutions/go-quai/consensus/misc"
 	"github.com/spruce-solutions/go-quai/core"
 	"github.com/spruce-solutions/go-quai/core/types"
 	"github.com/spruce-solutions/go-quai/crypto"
 	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
+	"github.com/spruce-solutions/go-quai/rlp"
 	"github.com/spruce-solutions/go-quai/rpc"
 )
 
@@ -51,7 +54,7 @@ func (s *PublicQuaiAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
 		return nil, err
 	}
 	if head := s.b.CurrentHeader(); head.BaseFee != nil {
-		tipcap.Add(tipcap, head.BaseFee[types.QuaiNetworkContext])
+		tipcap.Add(tipcap, head.BaseFee[s.b.ChainConfig().Context])
 	}
 	return (*hexutil.Big)(tipcap), err
 }
@@ -130,7 +133,7 @@ func NewPublicBlockChainQuaiAPI(b Backend) *PublicBlockChainQuaiAPI {
 // ChainId is the EIP-155 replay-protection chain id for the current Quai chain config.
 func (api *PublicBlockChainQuaiAPI) ChainId() (*hexutil.Big, error) {
 	// if current block is at or past the EIP-155 replay-protection fork block, return chainID from config
-	if config := api.b.ChainConfig(); config.IsEIP155(api.b.CurrentBlock().Number()) {
+	if config := api.b.ChainConfig(); config.IsEIP155(api.b.CurrentBlock().Number(config.Context)) {
 		return (*hexutil.Big)(config.ChainID), nil
 	}
 	return nil, fmt.Errorf("chain not synced beyond EIP-155 replay-protection fork block")
@@ -139,7 +142,7 @@ func (api *PublicBlockChainQuaiAPI) ChainId() (*hexutil.Big, error) {
 // BlockNumber returns the block number of the chain head.
 func (s *PublicBlockChainQuaiAPI) BlockNumber() hexutil.Uint64 {
 	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
-	return hexutil.Uint64(header.Number[types.QuaiNetworkContext].Uint64())
+	return hexutil.Uint64(header.Number[s.b.ChainConfig().Context].Uint64())
 }
 
 // GetBalance returns the amount of wei for the given address in the state of the
@@ -306,7 +309,7 @@ func (s *PublicBlockChainQuaiAPI) GetUncleByBlockHashAndIndex(ctx context.Contex
 	if block != nil {
 		uncles := block.Uncles()
 		if index >= hexutil.Uint(len(uncles)) {
-			log.Debug("Requested uncle not found", "number", block.Number(), "hash", blockHash, "index", index)
+			log.Debug("Requested uncle not found", "number", block.Number(s.b.ChainConfig().Context), "hash", blockHash, "index", index)
 			return nil, nil
 		}
 		block = types.NewBlockWithHeader(uncles[index])
@@ -316,7 +319,7 @@ func (s *PublicBlockChainQuaiAPI) GetUncleByBlockHashAndIndex(ctx context.Contex
 	if pendBlock != nil && pendBlock.Hash() == blockHash {
 		uncles := pendBlock.Uncles()
 		if index >= hexutil.Uint(len(uncles)) {
-			log.Debug("Requested uncle not found in pending block", "number", block.Number(), "hash", blockHash, "index", index)
+			log.Debug("Requested uncle not found in pending block", "number", block.Number(s.b.ChainConfig().Context), "hash", blockHash, "index", index)
 			return nil, nil
 		}
 		block = types.NewBlockWithHeader(uncles[index])
@@ -424,9 +427,9 @@ func RPCMarshalHeader(head *types.Header) map[string]interface{} {
 }
 
 // RPCMarshalBlock converts the given block to the RPC output which depends on fullTx. If inclTx is true transactions are
-// returned. When fullTx is true the returned block contains full transaction details, otherwise it will only contain
-// transaction hashes.
-func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool) (map[string]interface{}, error) {
+// returned. When fullTx is true the returned block contains full transaction details, numbered in the given context,
+// otherwise it will only contain transaction hashes.
+func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool, context int) (map[string]interface{}, error) {
 	fields := RPCMarshalHeader(block.Header())
 	fields["size"] = hexutil.Uint64(block.Size())
 
@@ -436,7 +439,7 @@ func RPCMarshalBlock(block *types.Block, inclTx bool, fullTx bool) (map[string]i
 		}
 		if fullTx {
 			formatTx = func(tx *types.Transaction) (interface{}, error) {
-				return newRPCTransactionFromBlockHash(block, tx.Hash()), nil
+				return newRPCTransactionFromBlockHash(block, tx.Hash(), context), nil
 			}
 		}
 		txs := block.Transactions()
@@ -463,7 +466,7 @@ func RPCMarshalExternalBlock(block *types.Block, receipts []*types.Receipt, cont
 	fields["size"] = hexutil.Uint64(block.Size())
 
 	formatTx := func(tx *types.Transaction) (interface{}, error) {
-		return newRPCTransactionFromBlockHash(block, tx.Hash()), nil
+		return newRPCTransactionFromBlockHash(block, tx.Hash(), int(context.Int64())), nil
 	}
 
 	txs := block.Transactions()
@@ -529,19 +532,19 @@ func RPCMarshalHash(hash common.Hash) (map[string]interface{}, error) {
 // a `PublicBlockchainQuaiAPI`.
 func (s *PublicBlockChainQuaiAPI) rpcMarshalHeader(ctx context.Context, header *types.Header) map[string]interface{} {
 	fields := RPCMarshalHeader(header)
-	fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, header.Hash())[types.QuaiNetworkContext])
+	fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, header.Hash())[s.b.ChainConfig().Context])
 	return fields
 }
 
 // rpcMarshalBlock uses the generalized output filler, then adds the total difficulty field, which requires
 // a `PublicBlockchainAPI`.
 func (s *PublicBlockChainQuaiAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inclTx bool, fullTx bool) (map[string]interface{}, error) {
-	fields, err := RPCMarshalBlock(b, inclTx, fullTx)
+	fields, err := RPCMarshalBlock(b, inclTx, fullTx, s.b.ChainConfig().Context)
 	if err != nil {
 		return nil, err
 	}
 	if inclTx {
-		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[types.QuaiNetworkContext])
+		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[s.b.ChainConfig().Context])
 	}
 	return fields, err
 }
@@ -549,12 +552,12 @@ func (s *PublicBlockChainQuaiAPI) rpcMarshalBlock(ctx context.Context, b *types.
 // rpcMarshalBlock uses the generalized output filler, then adds the total difficulty field, which requires
 // a `PublicBlockchainAPI`.
 func (s *PublicBlockChainQuaiAPI) rpcMarshalBlockWithReceipts(ctx context.Context, b *types.Block, receipts types.Receipts, inclTx bool, fullTx bool) (map[string]interface{}, error) {
-	fields, err := RPCMarshalBlock(b, inclTx, fullTx)
+	fields, err := RPCMarshalBlock(b, inclTx, fullTx, s.b.ChainConfig().Context)
 	if err != nil {
 		return nil, err
 	}
 	if inclTx {
-		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[types.QuaiNetworkContext])
+		fields["totalDifficulty"] = (*hexutil.Big)(s.b.GetTd(ctx, b.Hash())[s.b.ChainConfig().Context])
 	}
 	fieldReceipts := make([]interface{}, len(receipts))
 	for i, receipt := range receipts {
@@ -582,101 +585,6 @@ func (s *PublicBlockChainQuaiAPI) CreateAccessList(ctx context.Context, args Tra
 	return result, nil
 }
 
-// SendMinedBlock will run checks on the block and add to canonical chain if valid.
-func (s *PublicBlockChainQuaiAPI) SendMinedBlock(ctx context.Context, raw json.RawMessage) error {
-	// Decode header and transactions.
-	var head *types.Header
-	var body rpcBlock
-	if err := json.Unmarshal(raw, &head); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(raw, &body); err != nil {
-		return err
-	}
-
-	// Load uncles because they are not included in the block response.
-	txs := make([]*types.Transaction, len(body.Transactions))
-	for i, tx := range body.Transactions {
-		txs[i] = tx.tx
-	}
-
-	uncles := make([]*types.Header, len(body.Uncles))
-	for i, uncle := range body.Uncles {
-		uncles[i] = uncle
-	}
-
-	block := types.NewBlockWithHeader(head).WithBody(txs, uncles)
-	log.Info("Retrieved mined block", "number", head.Number, "location", head.Location)
-	s.b.InsertBlock(ctx, block)
-	// Broadcast the block and announce chain insertion event
-	if block.Header() != nil {
-		s.b.EventMux().Post(core.NewMinedBlockEvent{Block: block})
-	}
-
-	return nil
-}
-
-type rpcReorgData struct {
-	Header     *types.Header   `json:"header"`
-	NewHeaders []*types.Header `json:"newHeaders"`
-	OldHeaders []*types.Header `json:"oldHeaders"`
-}
-
-// ReOrgRollBack will send the reorg data to perform reorg rollback
-func (s *PublicBlockChainQuaiAPI) SendReOrgData(ctx context.Context, raw json.RawMessage) error {
-	// Decode reOrgHeader and body.
-	var reorgData rpcReorgData
-	if err := json.Unmarshal(raw, &reorgData); err != nil {
-		return err
-	}
-
-	s.b.ReOrgRollBack(reorgData.Header, reorgData.NewHeaders, reorgData.OldHeaders)
-	return nil
-}
-
-type rpcExternalBlock struct {
-	Hash         common.Hash      `json:"hash"`
-	Transactions []rpcTransaction `json:"transactions"`
-	Uncles       []*types.Header  `json:"uncles"`
-	Receipts     []*types.Receipt `json:"receipts"`
-	Context      *big.Int         `json:"context"`
-}
-
-// SendExternalBlock will run checks on the block and add to canonical chain if valid.
-func (s *PublicBlockChainQuaiAPI) SendExternalBlock(ctx context.Context, raw json.RawMessage) error {
-	// Decode header and transactions.
-	var head *types.Header
-	var body rpcExternalBlock
-	if err := json.Unmarshal(raw, &head); err != nil {
-		return err
-	}
-	if err := json.Unmarshal(raw, &body); err != nil {
-		return err
-	}
-
-	// Load transactions
-	txs := make([]*types.Transaction, len(body.Transactions))
-	for i, tx := range body.Transactions {
-		txs[i] = tx.tx
-	}
-
-	uncles := make([]*types.Header, len(body.Uncles))
-	for i, uncle := range body.Uncles {
-		uncles[i] = uncle
-	}
-
-	receipts := make([]*types.Receipt, len(body.Receipts))
-	for i, receipt := range body.Receipts {
-		receipts[i] = receipt
-	}
-
-	block := types.NewExternalBlockWithHeader(head).WithBody(txs, uncles, receipts, body.Context)
-
-	s.b.AddExternalBlock(block)
-
-	return nil
-}
-
 type HeaderHashWithContext struct {
 	Hash    common.Hash
 	Context int
@@ -739,33 +647,6 @@ func (s *PublicBlockChainQuaiAPI) GetBlockStatus(ctx context.Context, raw json.R
 	return s.b.GetBlockStatus(head)
 }
 
-func (s *PublicBlockChainQuaiAPI) HLCRReorg(ctx context.Context, raw json.RawMessage) (bool, error) {
-	// Decode header and transactions.
-	var head *types.Header
-	var body rpcBlock
-	if err := json.Unmarshal(raw, &head); err != nil {
-		return false, err
-	}
-	if err := json.Unmarshal(raw, &body); err != nil {
-		return false, err
-	}
-
-	// Load uncles because they are not included in the block response.
-	txs := make([]*types.Transaction, len(body.Transactions))
-	for i, tx := range body.Transactions {
-		txs[i] = tx.tx
-	}
-
-	uncles := make([]*types.Header, len(body.Uncles))
-	for i, uncle := range body.Uncles {
-		uncles[i] = uncle
-	}
-
-	block := types.NewBlockWithHeader(head).WithBody(txs, uncles)
-	fmt.Println("Header", block.Hash())
-	return s.b.HLCRReorg(block)
-}
-
 // GetSubordinateSet returns the valid mined blocks from a dominant chain to the subordinate
 func (s *PublicBlockChainQuaiAPI) GetSubordinateSet(ctx context.Context, raw json.RawMessage) ([]common.Hash, error) {
 	var hashWithLocation HashWithLocation
@@ -804,3 +685,218 @@ func (s *PublicBlockChainQuaiAPI) CheckPCCRC(ctx context.Context, raw json.RawMe
 	fmt.Println("Header Number:", headerWithOrder.Header.Number, "Order:", headerWithOrder.Order, "Hash:", headerWithOrder.Header.Hash())
 	return s.b.PCCRC(headerWithOrder.Header, headerWithOrder.Order)
 }
+
+// GetReorgHistory returns the most recent rollups of the chain from the reorg
+// journal, newest first. Without a count the last entry is returned.
+func (s *PublicBlockChainQuaiAPI) GetReorgHistory(count *hexutil.Uint64) []*types.ReorgRecord {
+	limit := uint64(1)
+	if count != nil {
+		limit = uint64(*count)
+	}
+	return s.b.GetReorgHistory(limit)
+}
+
+// GetFaultProofs returns all fault proofs recorded by the node, either built
+// locally when a twisted or colliding block was rejected or received from peers.
+func (s *PublicBlockChainQuaiAPI) GetFaultProofs() []*types.FaultProof {
+	return s.b.FaultProofs()
+}
+
+// SubmitFaultProof verifies an RLP encoded fault proof and, if it proves a
+// fault, stores it and propagates it to the network. It returns the hash of
+// the proof.
+func (s *PublicBlockChainQuaiAPI) SubmitFaultProof(input hexutil.Bytes) (common.Hash, error) {
+	proof := new(types.FaultProof)
+	if err := rlp.DecodeBytes(input, proof); err != nil {
+		return common.Hash{}, err
+	}
+	if err := s.b.AddFaultProof(proof); err != nil {
+		return common.Hash{}, err
+	}
+	return proof.Hash(), nil
+}
+
+// maxIssuanceBlocks is the maximum number of blocks quai_getIssuance totals in
+// a single request.
+const maxIssuanceBlocks = 10000
+
+// issuanceResult is the supply issued by a range of canonical blocks.
+type issuanceResult struct {
+	From     hexutil.Uint64 `json:"from"`
+	To       hexutil.Uint64 `json:"to"`
+	Total    *hexutil.Big   `json:"total"`
+	Miners   *hexutil.Big   `json:"miners"`
+	Uncles   *hexutil.Big   `json:"uncles"`
+	Treasury *hexutil.Big   `json:"treasury"`
+	Orders   []*hexutil.Big `json:"orders"` // Block rewards paid for the blocks of each difficulty order
+}
+
+// GetIssuance totals the supply issued by the canonical blocks of the chain
+// from and to the given block numbers inclusive, split by recipient and by the
+// difficulty order of the rewarded blocks. Every block pays the reward of its
+// parent.
+func (s *PublicBlockChainQuaiAPI) GetIssuance(ctx context.Context, from rpc.BlockNumber, to rpc.BlockNumber) (*issuanceResult, error) {
+	config := s.b.ChainConfig()
+	first, err := s.b.HeaderByNumber(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	last, err := s.b.HeaderByNumber(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	if first == nil || last == nil {
+		return nil, errors.New("block not found")
+	}
+	start, end := first.Number[config.Context].Uint64(), last.Number[config.Context].Uint64()
+	if start > end {
+		return nil, fmt.Errorf("invalid range: from %d after to %d", start, end)
+	}
+	if end-start >= maxIssuanceBlocks {
+		return nil, fmt.Errorf("range of %d blocks exceeds the limit of %d", end-start+1, maxIssuanceBlocks)
+	}
+	var (
+		miners   = new(big.Int)
+		uncles   = new(big.Int)
+		treasury = new(big.Int)
+		orders   = make([]*big.Int, types.ContextDepth)
+	)
+	for i := range orders {
+		orders[i] = new(big.Int)
+	}
+	// The genesis block issues no reward, its allocation is not emitted supply
+	if start == 0 {
+		start = 1
+	}
+	for number := start; number <= end; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		parent, err := s.b.HeaderByHash(ctx, block.ParentHash(config.Context))
+		if err != nil {
+			return nil, err
+		}
+		order := misc.RewardedOrder(config, parent, s.b.Engine().GetDifficultyOrder)
+		rewards := misc.BlockRewards(config, block.Header(), parent, order, block.Uncles())
+		miners.Add(miners, rewards.Miner)
+		miners.Add(miners, rewards.Parent)
+		for _, reward := range rewards.Uncles {
+			uncles.Add(uncles, reward)
+		}
+		treasury.Add(treasury, rewards.Treasury)
+
+		if rewards.Order >= 0 {
+			orders[rewards.Order].Add(orders[rewards.Order], rewards.Parent)
+			orders[rewards.Order].Add(orders[rewards.Order], rewards.Treasury)
+		}
+	}
+	result := &issuanceResult{
+		From:     hexutil.Uint64(first.Number[config.Context].Uint64()),
+		To:       hexutil.Uint64(end),
+		Total:    (*hexutil.Big)(new(big.Int).Add(new(big.Int).Add(miners, uncles), treasury)),
+		Miners:   (*hexutil.Big)(miners),
+		Uncles:   (*hexutil.Big)(uncles),
+		Treasury: (*hexutil.Big)(treasury),
+		Orders:   make([]*hexutil.Big, len(orders)),
+	}
+	for i, issued := range orders {
+		result.Orders[i] = (*hexutil.Big)(issued)
+	}
+	return result, nil
+}
+
+// GetSupply returns the supply record of a zone block: the rewards it issued,
+// the base fees it burned and the value of the ETxs leaving and entering the
+// zone, along with the totals since tracking started.
+func (s *PublicBlockChainQuaiAPI) GetSupply(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Supply, error) {
+	header, err := s.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errors.New("block not found")
+	}
+	return s.b.GetSupply(header.Hash()), nil
+}
+
+// GetZoneSupplies returns the supply records of the heads of every zone under
+// the node, it is used by dominant chains to aggregate the network supply.
+func (s *PublicBlockChainQuaiAPI) GetZoneSupplies(ctx context.Context) ([]*types.Supply, error) {
+	return s.b.ZoneSupplies(ctx)
+}
+
+// GetNetworkSupply aggregates the supply of every zone under the node and
+// reconciles the ETx flows between them, listing the mismatches found. On a
+// prime node it covers the whole network.
+func (s *PublicBlockChainQuaiAPI) GetNetworkSupply(ctx context.Context) (map[string]interface{}, error) {
+	supply, err := s.b.NetworkSupply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"zones":       supply.Zones,
+		"base":        (*hexutil.Big)(supply.Base),
+		"issued":      (*hexutil.Big)(supply.Issued),
+		"burned":      (*hexutil.Big)(supply.Burned),
+		"circulating": (*hexutil.Big)(supply.Circulating),
+		"inFlight":    (*hexutil.Big)(supply.InFlight),
+		"mismatches":  supply.Mismatches,
+	}, nil
+}
+
+// GetETxStatus returns the lifecycle status of the external transaction with
+// the given hash from its origin block to its destination block, including its
+// receipt once it has been applied and the value refunded to its sender if it
+// failed.
+func (s *PublicBlockChainQuaiAPI) GetETxStatus(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	status := s.b.GetETxStatus(hash)
+	if status == nil {
+		return nil, nil
+	}
+	fields := map[string]interface{}{
+		"hash":             status.Hash,
+		"stage":            status.Stage.String(),
+		"originBlock":      status.OriginBlock,
+		"originLocation":   hexutil.Bytes(status.OriginLocation),
+		"referenceContext": hexutil.Uint64(status.ReferenceContext),
+		"destinationBlock": status.DestinationBlock,
+	}
+	if status.Error != "" {
+		fields["error"] = status.Error
+	}
+	if status.Stage == types.ETxFailed {
+		_, refunds := s.b.ETxPoolContent()
+		for _, refund := range refunds {
+			if refund.Hash == hash {
+				fields["refund"] = (*hexutil.Big)(refund.Value)
+				break
+			}
+		}
+	}
+	if status.DestinationBlock != (common.Hash{}) {
+		receipts, err := s.b.GetReceipts(ctx, status.DestinationBlock)
+		if err != nil {
+			return nil, err
+		}
+		// The origin zone only knows the destination block as an external block.
+		if receipts == nil {
+			if extBlock, err := s.b.GetExternalBlockByHashAndContext(status.DestinationBlock, params.ZONE); err == nil {
+				receipts = extBlock.Receipts()
+			}
+		}
+		for _, receipt := range receipts {
+			if receipt.TxHash == hash {
+				fields["receipt"] = receipt
+				break
+			}
+		}
+	}
+	return fields, nil
+}
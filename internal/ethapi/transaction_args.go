@@ -80,11 +80,12 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
 	}
 	// After london, default to 1559 unless gasPrice is set
 	head := b.CurrentHeader()
+	context := b.ChainConfig().Context
 	// If user specifies both maxPriorityfee and maxFee, then we do not
 	// need to consult the chain for defaults. It's definitely a London tx.
 	if args.MaxPriorityFeePerGas == nil || args.MaxFeePerGas == nil {
 		// In this clause, user left some fields unspecified.
-		if b.ChainConfig().IsLondon(head.Number[types.QuaiNetworkContext]) && args.GasPrice == nil {
+		if b.ChainConfig().IsLondon(head.Number[context]) && args.GasPrice == nil {
 			if args.MaxPriorityFeePerGas == nil {
 				tip, err := b.SuggestGasTipCap(ctx)
 				if err != nil {
@@ -95,7 +96,7 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
 			if args.MaxFeePerGas == nil {
 				gasFeeCap := new(big.Int).Add(
 					(*big.Int)(args.MaxPriorityFeePerGas),
-					new(big.Int).Mul(head.BaseFee[types.QuaiNetworkContext], big.NewInt(2)),
+					new(big.Int).Mul(head.BaseFee[context], big.NewInt(2)),
 				)
 				args.MaxFeePerGas = (*hexutil.Big)(gasFeeCap)
 			}
@@ -111,11 +112,11 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
 				if err != nil {
 					return err
 				}
-				if b.ChainConfig().IsLondon(head.Number[types.QuaiNetworkContext]) {
+				if b.ChainConfig().IsLondon(head.Number[context]) {
 					// The legacy tx gas price suggestion should not add 2x base fee
 					// because all fees are consumed, so it would result in a spiral
 					// upwards.
-					price.Add(price, head.BaseFee[types.QuaiNetworkContext])
+					price.Add(price, head.BaseFee[context])
 				}
 				args.GasPrice = (*hexutil.Big)(price)
 			}
@@ -443,6 +443,12 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'verifyPCRC',
+			call: 'debug_verifyPCRC',
+			params: 2,
+			inputFormatter: [null, null],
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -667,6 +673,11 @@ const PersonalJs = `
 web3._extend({
 	property: 'personal',
 	methods: [
+		new web3._extend.Method({
+			name: 'newAccount',
+			call: 'personal_newAccount',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'importRawKey',
 			call: 'personal_importRawKey',
@@ -746,6 +757,10 @@ web3._extend({
 			name: 'inspect',
 			getter: 'txpool_inspect'
 		}),
+		new web3._extend.Property({
+			name: 'etxContent',
+			getter: 'txpool_etxContent'
+		}),
 		new web3._extend.Property({
 			name: 'status',
 			getter: 'txpool_status',
@@ -124,6 +124,10 @@ func (b *LesApiBackend) GetExternalBlockByHashAndContext(hash common.Hash, conte
 	return nil, errors.New("light client does not support external block caching")
 }
 
+func (b *LesApiBackend) GetCoincidentExternalBlocks(hash common.Hash) []*types.ExternalBlock {
+	return nil
+}
+
 func (b *LesApiBackend) GetAncestorByLocation(hash common.Hash, location []byte) (*types.Header, error) {
 	return nil, errors.New("light client does not support getting ancestor by location")
 }
@@ -132,6 +136,34 @@ func (b *LesApiBackend) PCRC(header *types.Header, order int) (types.PCRCTermini
 	return types.PCRCTermini{}, errors.New("light client does not support running PCRC")
 }
 
+func (b *LesApiBackend) GetETxStatus(hash common.Hash) *types.ETxStatus {
+	return nil
+}
+
+func (b *LesApiBackend) GetReorgHistory(count uint64) []*types.ReorgRecord {
+	return nil
+}
+
+func (b *LesApiBackend) FaultProofs() []*types.FaultProof {
+	return nil
+}
+
+func (b *LesApiBackend) AddFaultProof(proof *types.FaultProof) error {
+	return errors.New("light client does not support fault proofs")
+}
+
+func (b *LesApiBackend) GetSupply(hash common.Hash) *types.Supply {
+	return nil
+}
+
+func (b *LesApiBackend) ZoneSupplies(ctx context.Context) ([]*types.Supply, error) {
+	return nil, errors.New("light client does not track supply")
+}
+
+func (b *LesApiBackend) NetworkSupply(ctx context.Context) (*core.NetworkSupply, error) {
+	return nil, errors.New("light client does not track supply")
+}
+
 func (b *LesApiBackend) PCCRC(header *types.Header, order int) (types.PCRCTermini, error) {
 	return types.PCRCTermini{}, errors.New("light client does not support running PCCRC")
 }
@@ -152,7 +184,7 @@ func (b *LesApiBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash r
 		if block == nil {
 			return nil, errors.New("header found, but block body is missing")
 		}
-		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(block.NumberU64()) != hash {
+		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(block.NumberU64(b.eth.blockchain.Config().Context)) != hash {
 			return nil, errors.New("hash is not currently canonical")
 		}
 		return block, nil
@@ -218,7 +250,7 @@ func (b *LesApiBackend) GetEVM(ctx context.Context, msg core.Message, state *sta
 		vmConfig = new(vm.Config)
 	}
 	txContext := core.NewEVMTxContext(msg)
-	context := core.NewEVMBlockContext(header, b.eth.blockchain, nil)
+	context := core.NewEVMBlockContext(header, b.eth.blockchain, nil, b.eth.blockchain.Config().Context)
 	return vm.NewEVM(context, txContext, state, b.eth.chainConfig, *vmConfig), state.Error, nil
 }
 
@@ -258,6 +290,10 @@ func (b *LesApiBackend) TxPoolContentFrom(addr common.Address) (types.Transactio
 	return b.eth.txPool.ContentFrom(addr)
 }
 
+func (b *LesApiBackend) ETxPoolContent() ([]*types.PendingETx, []*types.ETxRefund) {
+	return nil, nil
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subscription {
 	return b.eth.txPool.SubscribeNewTxsEvent(ch)
 }
@@ -306,6 +342,20 @@ func (b *LesApiBackend) SubscribeMissingExternalBlockEvent(ch chan<- core.Missin
 	})
 }
 
+func (b *LesApiBackend) SubscribeETxStatusEvent(ch chan<- core.ETxStatusEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+func (b *LesApiBackend) SubscribeReorgJournalEvent(ch chan<- core.ReorgJournalEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.eth.blockchain.SubscribeRemovedLogsEvent(ch)
 }
@@ -256,9 +256,9 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 	mode := d.getMode()
 	switch {
 	case d.blockchain != nil && mode == FullSync:
-		current = d.blockchain.CurrentBlock().NumberU64()
+		current = d.blockchain.CurrentBlock().NumberU64(types.QuaiNetworkContext)
 	case d.blockchain != nil && mode == FastSync:
-		current = d.blockchain.CurrentFastBlock().NumberU64()
+		current = d.blockchain.CurrentFastBlock().NumberU64(types.QuaiNetworkContext)
 	case d.lightchain != nil:
 		current = d.lightchain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
 	default:
@@ -769,9 +769,9 @@ func (d *Downloader) findAncestor(p *peerConnection, remoteHeader *types.Header)
 	mode := d.getMode()
 	switch mode {
 	case FullSync:
-		localHeight = d.blockchain.CurrentBlock().NumberU64()
+		localHeight = d.blockchain.CurrentBlock().NumberU64(types.QuaiNetworkContext)
 	case FastSync:
-		localHeight = d.blockchain.CurrentFastBlock().NumberU64()
+		localHeight = d.blockchain.CurrentFastBlock().NumberU64(types.QuaiNetworkContext)
 	default:
 		localHeight = d.lightchain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
 	}
@@ -1147,8 +1147,8 @@ func (d *Downloader) fetchHeaders(p *peerConnection, from uint64) error {
 					if mode == LightSync {
 						head = d.lightchain.CurrentHeader().Number[types.QuaiNetworkContext].Uint64()
 					} else {
-						head = d.blockchain.CurrentFastBlock().NumberU64()
-						if full := d.blockchain.CurrentBlock().NumberU64(); head < full {
+						head = d.blockchain.CurrentFastBlock().NumberU64(types.QuaiNetworkContext)
+						if full := d.blockchain.CurrentBlock().NumberU64(types.QuaiNetworkContext); head < full {
 							head = full
 						}
 					}
@@ -1524,8 +1524,8 @@ func (d *Downloader) processHeaders(origin uint64, td *big.Int) error {
 		if rollback > 0 {
 			lastHeader, lastFastBlock, lastBlock := d.lightchain.CurrentHeader().Number, common.Big0, common.Big0
 			if mode != LightSync {
-				lastFastBlock = d.blockchain.CurrentFastBlock().Number()
-				lastBlock = d.blockchain.CurrentBlock().Number()
+				lastFastBlock = d.blockchain.CurrentFastBlock().Number(types.QuaiNetworkContext)
+				lastBlock = d.blockchain.CurrentBlock().Number(types.QuaiNetworkContext)
 			}
 			if err := d.lightchain.SetHead(rollback - 1); err != nil { // -1 to target the parent of the first uncertain block
 				// We're already unwinding the stack, only print the error to make it more visible
@@ -1533,8 +1533,8 @@ func (d *Downloader) processHeaders(origin uint64, td *big.Int) error {
 			}
 			curFastBlock, curBlock := common.Big0, common.Big0
 			if mode != LightSync {
-				curFastBlock = d.blockchain.CurrentFastBlock().Number()
-				curBlock = d.blockchain.CurrentBlock().Number()
+				curFastBlock = d.blockchain.CurrentFastBlock().Number(types.QuaiNetworkContext)
+				curBlock = d.blockchain.CurrentBlock().Number(types.QuaiNetworkContext)
 			}
 			log.Warn("Rolled back chain segment",
 				"header", fmt.Sprintf("%d->%d", lastHeader, d.lightchain.CurrentHeader().Number),
@@ -1575,7 +1575,7 @@ func (d *Downloader) processHeaders(origin uint64, td *big.Int) error {
 				// R: Nothing to give
 				if mode != LightSync {
 					head := d.blockchain.CurrentBlock()
-					if !gotHeaders && td.Cmp(d.blockchain.GetTd(head.Hash(), head.NumberU64())[types.QuaiNetworkContext]) > 0 {
+					if !gotHeaders && td.Cmp(d.blockchain.GetTd(head.Hash(), head.NumberU64(types.QuaiNetworkContext))[types.QuaiNetworkContext]) > 0 {
 						return errStallingPeer
 					}
 				}
@@ -1920,7 +1920,7 @@ func (d *Downloader) commitFastSyncData(results []*fetchResult, stateSync *state
 
 func (d *Downloader) commitPivotBlock(result *fetchResult) error {
 	block := types.NewBlockWithHeader(result.Header).WithBody(result.Transactions, result.Uncles)
-	log.Debug("Committing fast sync pivot as new head", "number", block.Number(), "hash", block.Hash())
+	log.Debug("Committing fast sync pivot as new head", "number", block.Number(types.QuaiNetworkContext), "hash", block.Hash())
 
 	// Commit the pivot block as the new head, will require full sync from here on
 	if _, err := d.blockchain.InsertReceiptChain([]*types.Block{block}, []types.Receipts{result.Receipts}, d.ancientLimit); err != nil {
@@ -78,16 +78,16 @@ func newTester() *downloadTester {
 		ownHeaders:  map[common.Hash]*types.Header{testGenesis.Hash(): testGenesis.Header()},
 		ownBlocks:   map[common.Hash]*types.Block{testGenesis.Hash(): testGenesis},
 		ownReceipts: map[common.Hash]types.Receipts{testGenesis.Hash(): nil},
-		ownChainTd:  map[common.Hash]*big.Int{testGenesis.Hash(): testGenesis.Difficulty()},
+		ownChainTd:  map[common.Hash]*big.Int{testGenesis.Hash(): testGenesis.Difficulty(types.QuaiNetworkContext)},
 
 		// Initialize ancient store with test genesis block
 		ancientHeaders:  map[common.Hash]*types.Header{testGenesis.Hash(): testGenesis.Header()},
 		ancientBlocks:   map[common.Hash]*types.Block{testGenesis.Hash(): testGenesis},
 		ancientReceipts: map[common.Hash]types.Receipts{testGenesis.Hash(): nil},
-		ancientChainTd:  map[common.Hash]*big.Int{testGenesis.Hash(): testGenesis.Difficulty()},
+		ancientChainTd:  map[common.Hash]*big.Int{testGenesis.Hash(): testGenesis.Difficulty(types.QuaiNetworkContext)},
 	}
 	tester.stateDb = rawdb.NewMemoryDatabase()
-	tester.stateDb.Put(testGenesis.Root().Bytes(), []byte{0x00})
+	tester.stateDb.Put(testGenesis.Root(types.QuaiNetworkContext).Bytes(), []byte{0x00})
 
 	tester.downloader = New(0, tester.stateDb, trie.NewSyncBloom(1, tester.stateDb), new(event.TypeMux), tester, nil, tester.dropPeer)
 	return tester
@@ -195,13 +195,13 @@ func (dl *downloadTester) CurrentBlock() *types.Block {
 
 	for i := len(dl.ownHashes) - 1; i >= 0; i-- {
 		if block := dl.ancientBlocks[dl.ownHashes[i]]; block != nil {
-			if _, err := dl.stateDb.Get(block.Root().Bytes()); err == nil {
+			if _, err := dl.stateDb.Get(block.Root(types.QuaiNetworkContext).Bytes()); err == nil {
 				return block
 			}
 			return block
 		}
 		if block := dl.ownBlocks[dl.ownHashes[i]]; block != nil {
-			if _, err := dl.stateDb.Get(block.Root().Bytes()); err == nil {
+			if _, err := dl.stateDb.Get(block.Root(types.QuaiNetworkContext).Bytes()); err == nil {
 				return block
 			}
 		}
@@ -229,7 +229,7 @@ func (dl *downloadTester) CurrentFastBlock() *types.Block {
 func (dl *downloadTester) FastSyncCommitHead(hash common.Hash) error {
 	// For now only check that the state trie is correct
 	if block := dl.GetBlockByHash(hash); block != nil {
-		_, err := trie.NewSecure(block.Root(), trie.NewDatabase(dl.stateDb))
+		_, err := trie.NewSecure(block.Root(types.QuaiNetworkContext), trie.NewDatabase(dl.stateDb))
 		return err
 	}
 	return fmt.Errorf("non existent block: %x", hash[:4])
@@ -294,10 +294,10 @@ func (dl *downloadTester) InsertChain(blocks types.Blocks) (i int, err error) {
 	dl.lock.Lock()
 	defer dl.lock.Unlock()
 	for i, block := range blocks {
-		if parent, ok := dl.ownBlocks[block.ParentHash()]; !ok {
+		if parent, ok := dl.ownBlocks[block.ParentHash(types.QuaiNetworkContext)]; !ok {
 			return i, fmt.Errorf("InsertChain: unknown parent at position %d / %d", i, len(blocks))
-		} else if _, err := dl.stateDb.Get(parent.Root().Bytes()); err != nil {
-			return i, fmt.Errorf("InsertChain: unknown parent state %x: %v", parent.Root(), err)
+		} else if _, err := dl.stateDb.Get(parent.Root(types.QuaiNetworkContext).Bytes()); err != nil {
+			return i, fmt.Errorf("InsertChain: unknown parent state %x: %v", parent.Root(types.QuaiNetworkContext), err)
 		}
 		if hdr := dl.getHeaderByHash(block.Hash()); hdr == nil {
 			dl.ownHashes = append(dl.ownHashes, block.Hash())
@@ -305,9 +305,9 @@ func (dl *downloadTester) InsertChain(blocks types.Blocks) (i int, err error) {
 		}
 		dl.ownBlocks[block.Hash()] = block
 		dl.ownReceipts[block.Hash()] = make(types.Receipts, 0)
-		dl.stateDb.Put(block.Root().Bytes(), []byte{0x00})
-		td := dl.getTd(block.ParentHash())
-		dl.ownChainTd[block.Hash()] = new(big.Int).Add(td, block.Difficulty())
+		dl.stateDb.Put(block.Root(types.QuaiNetworkContext).Bytes(), []byte{0x00})
+		td := dl.getTd(block.ParentHash(types.QuaiNetworkContext))
+		dl.ownChainTd[block.Hash()] = new(big.Int).Add(td, block.Difficulty(types.QuaiNetworkContext))
 	}
 	return len(blocks), nil
 }
@@ -321,18 +321,18 @@ func (dl *downloadTester) InsertReceiptChain(blocks types.Blocks, receipts []typ
 		if _, ok := dl.ownHeaders[blocks[i].Hash()]; !ok {
 			return i, errors.New("unknown owner")
 		}
-		if _, ok := dl.ancientBlocks[blocks[i].ParentHash()]; !ok {
-			if _, ok := dl.ownBlocks[blocks[i].ParentHash()]; !ok {
+		if _, ok := dl.ancientBlocks[blocks[i].ParentHash(types.QuaiNetworkContext)]; !ok {
+			if _, ok := dl.ownBlocks[blocks[i].ParentHash(types.QuaiNetworkContext)]; !ok {
 				return i, errors.New("InsertReceiptChain: unknown parent")
 			}
 		}
-		if blocks[i].NumberU64() <= ancientLimit {
+		if blocks[i].NumberU64(types.QuaiNetworkContext) <= ancientLimit {
 			dl.ancientBlocks[blocks[i].Hash()] = blocks[i]
 			dl.ancientReceipts[blocks[i].Hash()] = receipts[i]
 
 			// Migrate from active db to ancient db
 			dl.ancientHeaders[blocks[i].Hash()] = blocks[i].Header()
-			dl.ancientChainTd[blocks[i].Hash()] = new(big.Int).Add(dl.ancientChainTd[blocks[i].ParentHash()], blocks[i].Difficulty())
+			dl.ancientChainTd[blocks[i].Hash()] = new(big.Int).Add(dl.ancientChainTd[blocks[i].ParentHash(types.QuaiNetworkContext)], blocks[i].Difficulty(types.QuaiNetworkContext))
 			delete(dl.ownHeaders, blocks[i].Hash())
 			delete(dl.ownChainTd, blocks[i].Hash())
 		} else {
@@ -1016,7 +1016,7 @@ func testInvalidHeaderRollback(t *testing.T, protocol uint, mode SyncMode) {
 		t.Errorf("rollback head mismatch: have %v, want at most %v", head, 2*fsHeaderSafetyNet+MaxHeaderFetch)
 	}
 	if mode == FastSync {
-		if head := tester.CurrentBlock().NumberU64(); head != 0 {
+		if head := tester.CurrentBlock().NumberU64(types.QuaiNetworkContext); head != 0 {
 			t.Errorf("fast sync pivot block #%d not rolled back", head)
 		}
 	}
@@ -1039,7 +1039,7 @@ func testInvalidHeaderRollback(t *testing.T, protocol uint, mode SyncMode) {
 		t.Errorf("rollback head mismatch: have %v, want at most %v", head, 2*fsHeaderSafetyNet+MaxHeaderFetch)
 	}
 	if mode == FastSync {
-		if head := tester.CurrentBlock().NumberU64(); head != 0 {
+		if head := tester.CurrentBlock().NumberU64(types.QuaiNetworkContext); head != 0 {
 			t.Errorf("fast sync pivot block #%d not rolled back", head)
 		}
 	}
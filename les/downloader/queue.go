@@ -74,10 +74,10 @@ func newFetchResult(header *types.Header, fastSync bool) *fetchResult {
 	item := &fetchResult{
 		Header: header,
 	}
-	if !header.EmptyBody() {
+	if !header.EmptyBody(types.QuaiNetworkContext) {
 		item.pending |= (1 << bodyType)
 	}
-	if fastSync && !header.EmptyReceipts() {
+	if fastSync && !header.EmptyReceipts(types.QuaiNetworkContext) {
 		item.pending |= (1 << receiptType)
 	}
 	return item
@@ -318,7 +318,7 @@ func (q *queue) Schedule(headers []*types.Header, from uint64) []*types.Header {
 			q.blockTaskQueue.Push(header, -int64(header.Number[types.QuaiNetworkContext].Uint64()))
 		}
 		// Queue for receipt retrieval
-		if q.mode == FastSync && !header.EmptyReceipts() {
+		if q.mode == FastSync && !header.EmptyReceipts(types.QuaiNetworkContext) {
 			if _, ok := q.receiptTaskPool[hash]; ok {
 				log.Warn("Header already scheduled for receipt fetch", "number", header.Number, "hash", hash)
 			} else {
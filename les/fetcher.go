@@ -440,7 +440,7 @@ func (f *lightFetcher) mainloop() {
 			// Rewind all untrusted headers for ulc mode.
 			if ulc {
 				head := f.chain.CurrentHeader()
-				ancestor := rawdb.FindCommonAncestor(f.chaindb, origin, head)
+				ancestor := rawdb.FindCommonAncestor(f.chaindb, origin, head, types.QuaiNetworkContext)
 				var untrusted []common.Hash
 				for head.Number[types.QuaiNetworkContext].Cmp(ancestor.Number[types.QuaiNetworkContext]) > 0 {
 					hash, number := head.Hash(), head.Number[types.QuaiNetworkContext].Uint64()
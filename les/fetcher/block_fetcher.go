@@ -143,7 +143,7 @@ func (inject *blockOrHeaderInject) number() uint64 {
 	if inject.header != nil {
 		return inject.header.Number[types.QuaiNetworkContext].Uint64()
 	}
-	return inject.block.NumberU64()
+	return inject.block.NumberU64(types.QuaiNetworkContext)
 }
 
 // number returns the block hash of the injected object.
@@ -715,7 +715,7 @@ func (f *BlockFetcher) enqueue(peer string, header *types.Header, block *types.B
 	if header != nil {
 		hash, number = header.Hash(), header.Number[types.QuaiNetworkContext].Uint64()
 	} else {
-		hash, number = block.Hash(), block.NumberU64()
+		hash, number = block.Hash(), block.NumberU64(types.QuaiNetworkContext)
 	}
 	// Ensure the peer isn't DOSing us
 	count := f.queues[peer] + 1
@@ -790,14 +790,14 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block) {
 	hash := block.Hash()
 
 	// Run the import on a new thread
-	log.Debug("Importing propagated block", "peer", peer, "number", block.Number(), "hash", hash)
+	log.Debug("Importing propagated block", "peer", peer, "number", block.Number(types.QuaiNetworkContext), "hash", hash)
 	go func() {
 		defer func() { f.done <- hash }()
 
 		// If the parent's unknown, abort insertion
-		parent := f.getBlock(block.ParentHash())
+		parent := f.getBlock(block.ParentHash(types.QuaiNetworkContext))
 		if parent == nil {
-			log.Debug("Unknown parent of propagated block", "peer", peer, "number", block.Number(), "hash", hash, "parent", block.ParentHash())
+			log.Debug("Unknown parent of propagated block", "peer", peer, "number", block.Number(types.QuaiNetworkContext), "hash", hash, "parent", block.ParentHash(types.QuaiNetworkContext))
 			return
 		}
 		// Quickly validate the header and propagate the block if it passes
@@ -812,13 +812,13 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block) {
 
 		default:
 			// Something went very wrong, drop the peer
-			log.Debug("Propagated block verification failed", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
+			log.Debug("Propagated block verification failed", "peer", peer, "number", block.Number(types.QuaiNetworkContext), "hash", hash, "err", err)
 			f.dropPeer(peer)
 			return
 		}
 		// Run the actual import and log any issues
 		if _, err := f.insertChain(types.Blocks{block}); err != nil {
-			log.Debug("Propagated block import failed", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
+			log.Debug("Propagated block import failed", "peer", peer, "number", block.Number(types.QuaiNetworkContext), "hash", hash, "err", err)
 			return
 		}
 		// If import succeeded, broadcast the block
@@ -134,7 +134,7 @@ func (f *fetcherTester) chainHeight() uint64 {
 	if f.fetcher.light {
 		return f.headers[f.hashes[len(f.hashes)-1]].Number[0].Uint64() //Using first value of Number array
 	}
-	return f.blocks[f.hashes[len(f.hashes)-1]].NumberU64()
+	return f.blocks[f.hashes[len(f.hashes)-1]].NumberU64(types.QuaiNetworkContext)
 }
 
 // insertChain injects a new headers into the simulated chain.
@@ -165,11 +165,11 @@ func (f *fetcherTester) insertChain(blocks types.Blocks) (int, error) {
 
 	for i, block := range blocks {
 		// Make sure the parent in known
-		if _, ok := f.blocks[block.ParentHash()]; !ok {
+		if _, ok := f.blocks[block.ParentHash(types.QuaiNetworkContext)]; !ok {
 			return i, errors.New("unknown parent")
 		}
 		// Discard any new blocks if the same height already exists
-		if block.NumberU64() <= f.blocks[f.hashes[len(f.hashes)-1]].NumberU64() {
+		if block.NumberU64(types.QuaiNetworkContext) <= f.blocks[f.hashes[len(f.hashes)-1]].NumberU64(types.QuaiNetworkContext) {
 			return i, nil
 		}
 		// Otherwise build our current chain
@@ -669,14 +669,14 @@ func testDistantAnnouncementDiscarding(t *testing.T, light bool) {
 	tester.fetcher.fetchingHook = func(hashes []common.Hash) { fetching <- struct{}{} }
 
 	// Ensure that a block with a lower number than the threshold is discarded
-	tester.fetcher.Notify("lower", hashes[low], blocks[hashes[low]].NumberU64(), time.Now().Add(-arriveTimeout), headerFetcher, bodyFetcher)
+	tester.fetcher.Notify("lower", hashes[low], blocks[hashes[low]].NumberU64(types.QuaiNetworkContext), time.Now().Add(-arriveTimeout), headerFetcher, bodyFetcher)
 	select {
 	case <-time.After(50 * time.Millisecond):
 	case <-fetching:
 		t.Fatalf("fetcher requested stale header")
 	}
 	// Ensure that a block with a higher number than the threshold is discarded
-	tester.fetcher.Notify("higher", hashes[high], blocks[hashes[high]].NumberU64(), time.Now().Add(-arriveTimeout), headerFetcher, bodyFetcher)
+	tester.fetcher.Notify("higher", hashes[high], blocks[hashes[high]].NumberU64(types.QuaiNetworkContext), time.Now().Add(-arriveTimeout), headerFetcher, bodyFetcher)
 	select {
 	case <-time.After(50 * time.Millisecond):
 	case <-fetching:
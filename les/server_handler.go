@@ -116,7 +116,7 @@ func (h *serverHandler) handle(p *clientPeer) error {
 		hash   = head.Hash()
 		number = head.Number[types.QuaiNetworkContext].Uint64()
 		td     = h.blockchain.GetTd(hash, number)
-		forkID = forkid.NewID(h.blockchain.Config(), h.blockchain.Genesis().Hash(), h.blockchain.CurrentBlock().NumberU64())
+		forkID = forkid.NewID(h.blockchain.Config(), h.blockchain.Genesis().Hash(), h.blockchain.CurrentBlock().NumberU64(h.blockchain.Config().Context))
 	)
 	if err := p.Handshake(td[types.QuaiNetworkContext], hash, number, h.blockchain.Genesis().Hash(), forkID, h.forkFilter, h.server); err != nil {
 		p.Log().Debug("Light Ethereum handshake failed", "err", err)
@@ -421,7 +421,7 @@ func (h *serverHandler) broadcastLoop() {
 			}
 			var reorg uint64
 			if lastHead != nil {
-				reorg = lastHead.Number[types.QuaiNetworkContext].Uint64() - rawdb.FindCommonAncestor(h.chainDb, header, lastHead).Number[types.QuaiNetworkContext].Uint64()
+				reorg = lastHead.Number[types.QuaiNetworkContext].Uint64() - rawdb.FindCommonAncestor(h.chainDb, header, lastHead, types.QuaiNetworkContext).Number[types.QuaiNetworkContext].Uint64()
 			}
 			lastHead = header
 			log.Debug("Announcing block to peers", "number", number, "hash", hash, "td", td, "reorg", reorg)
@@ -78,6 +78,11 @@ type LightChain struct {
 // available in the database. It initialises the default Ethereum header
 // validator.
 func NewLightChain(odr OdrBackend, config *params.ChainConfig, engine consensus.Engine, checkpoint *params.TrustedCheckpoint) (*LightChain, error) {
+	if config.Ontology != nil || len(config.OntologyForks) > 0 {
+		if err := params.RegisterOntology(config.NetworkOntology()); err != nil {
+			return nil, err
+		}
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
@@ -201,8 +206,8 @@ func (lc *LightChain) ResetWithGenesisBlock(genesis *types.Block) {
 
 	// Prepare the genesis block and reinitialise the chain
 	batch := lc.chainDb.NewBatch()
-	rawdb.WriteTd(batch, genesis.Hash(), genesis.NumberU64(), genesis.Header().Difficulty)
-	rawdb.WriteBlock(batch, genesis)
+	rawdb.WriteTd(batch, genesis.Hash(), genesis.NumberU64(lc.Config().Context), genesis.Header().Difficulty)
+	rawdb.WriteBlock(batch, genesis, types.QuaiNetworkContext)
 	rawdb.WriteHeadHeaderHash(batch, genesis.Hash())
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed to reset genesis block", "err", err)
@@ -147,7 +147,7 @@ type ChtRequest struct {
 // StoreResult stores the retrieved data in local database
 func (req *ChtRequest) StoreResult(db ethdb.Database) {
 	hash, num := req.Header.Hash(), req.Header.Number[types.QuaiNetworkContext].Uint64()
-	rawdb.WriteHeader(db, req.Header)
+	rawdb.WriteHeader(db, req.Header, types.QuaiNetworkContext)
 	rawdb.WriteTd(db, hash, num, req.Td)
 	rawdb.WriteCanonicalHash(db, hash, num)
 }
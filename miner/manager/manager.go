@@ -0,0 +1,412 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package manager mines a slice of the Quai hierarchy. It merges the pending
+// headers of a Prime, Region and Zone chain into a single header, mines it
+// locally or serves it to external miners, and delivers every solution to each
+// context whose difficulty it satisfies.
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	quai "github.com/spruce-solutions/go-quai"
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/metrics"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+const (
+	// pendingChanSize is the size of the channels receiving pending headers.
+	pendingChanSize = 16
+
+	// maxWork is the number of most recent combined headers solutions are
+	// accepted for.
+	maxWork = 16
+
+	// requestTimeout bounds every request sent to a chain.
+	requestTimeout = 10 * time.Second
+
+	// resubscribeDelay is the time waited before subscribing again to a chain
+	// whose subscription failed.
+	resubscribeDelay = 3 * time.Second
+)
+
+var (
+	errStaleWork   = errors.New("work not found")
+	errInvalidSeal = errors.New("header does not satisfy any difficulty")
+
+	workMeter    = metrics.NewRegisteredMeter("manager/work", nil)
+	blockMeters  = []metrics.Meter{metrics.NewRegisteredMeter("manager/blocks/prime", nil), metrics.NewRegisteredMeter("manager/blocks/region", nil), metrics.NewRegisteredMeter("manager/blocks/zone", nil)}
+	deliverMeter = metrics.NewRegisteredMeter("manager/deliver/failed", nil)
+)
+
+// Chain is the connection of the manager to one chain of the slice.
+type Chain interface {
+	// SubscribePendingBlock subscribes to the headers the miner of the chain
+	// starts working on.
+	SubscribePendingBlock(ctx context.Context, ch chan<- *types.Header) (quai.Subscription, error)
+
+	// PendingBlock returns the block the miner of the chain is working on.
+	PendingBlock(ctx context.Context) (*types.Block, error)
+
+	// SendMinedBlock delivers a sealed block to the chain.
+	SendMinedBlock(ctx context.Context, block *types.Block, inclTx bool, fullTx bool) error
+}
+
+// work is a combined header together with the pending blocks of every context
+// it was merged from.
+type work struct {
+	header *types.Header
+	blocks []*types.Block // Pending blocks indexed by context
+}
+
+// Manager merges the pending work of the Prime, Region and Zone chain of a
+// slice and routes the solutions back.
+type Manager struct {
+	chains []Chain // Chains of the slice indexed by context
+	engine *blake3.Blake3
+	local  bool // Whether the header is mined by the engine
+
+	mu      sync.Mutex
+	pending []*types.Block        // Latest pending block of every context
+	works   map[common.Hash]*work // Recent work by seal hash
+	hashes  []common.Hash         // Seal hashes of the recent work in creation order
+	current *work                 // Most recent work
+
+	workFeed event.Feed
+	scope    event.SubscriptionScope
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a manager for the chains of a slice, indexed by context. If local
+// is set, the combined header is mined by the engine, otherwise it is only
+// served to the subscribers of the work feed.
+func New(chains []Chain, engine *blake3.Blake3, local bool) (*Manager, error) {
+	if len(chains) != types.ContextDepth {
+		return nil, fmt.Errorf("manager needs %d chains, have %d", types.ContextDepth, len(chains))
+	}
+	return &Manager{
+		chains:  chains,
+		engine:  engine,
+		local:   local,
+		pending: make([]*types.Block, types.ContextDepth),
+		works:   make(map[common.Hash]*work),
+		quit:    make(chan struct{}),
+	}, nil
+}
+
+// Start follows the pending blocks of every chain and starts mining if local
+// mining is enabled.
+func (m *Manager) Start() {
+	for index, chain := range m.chains {
+		m.wg.Add(1)
+		go m.follow(index, chain)
+	}
+	if m.local {
+		m.wg.Add(1)
+		go m.sealLoop()
+	}
+}
+
+// Stop terminates the manager, waiting for its loops to exit.
+func (m *Manager) Stop() {
+	close(m.quit)
+	m.scope.Close()
+	m.wg.Wait()
+}
+
+// SubscribePendingHeader subscribes to the combined headers of new work. It
+// implements stratum.Backend.
+func (m *Manager) SubscribePendingHeader(ch chan<- *types.Header) event.Subscription {
+	return m.scope.Track(m.workFeed.Subscribe(ch))
+}
+
+// PendingBlock returns a block of the combined header with the given seal hash,
+// if the work is still recent. It implements stratum.Backend.
+func (m *Manager) PendingBlock(sealHash common.Hash) *types.Block {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if w := m.works[sealHash]; w != nil {
+		return types.NewBlockWithHeader(w.header)
+	}
+	return nil
+}
+
+// SubmitBlock delivers a block sealed by an external miner. It implements
+// stratum.Backend.
+func (m *Manager) SubmitBlock(block *types.Block, order int) error {
+	return m.Submit(block.Header(), order)
+}
+
+// follow tracks the pending blocks of the chain of a context, subscribing again
+// whenever the subscription fails.
+func (m *Manager) follow(index int, chain Chain) {
+	defer m.wg.Done()
+
+	logger := log.New("context", index)
+	headers := make(chan *types.Header, pendingChanSize)
+	for {
+		sub, err := chain.SubscribePendingBlock(context.Background(), headers)
+		if err != nil {
+			logger.Warn("Failed to subscribe to pending blocks", "err", err)
+		} else {
+			err = m.followSub(index, chain, sub, headers)
+			sub.Unsubscribe()
+			if err == nil {
+				return
+			}
+			logger.Warn("Pending block subscription failed", "err", err)
+		}
+		select {
+		case <-time.After(resubscribeDelay):
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// followSub updates the pending block of a context on every header of the
+// subscription, until it fails or the manager stops.
+func (m *Manager) followSub(index int, chain Chain, sub quai.Subscription, headers chan *types.Header) error {
+	for {
+		select {
+		case <-headers:
+			ctx, cancel := newContext()
+			block, err := chain.PendingBlock(ctx)
+			cancel()
+			if err != nil {
+				log.Warn("Failed to retrieve pending block", "context", index, "err", err)
+				continue
+			}
+			m.update(index, block)
+
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return err
+		case <-m.quit:
+			return nil
+		}
+	}
+}
+
+// update records the pending block of a context and, once every context has
+// one, announces the work combining them.
+func (m *Manager) update(index int, block *types.Block) {
+	m.mu.Lock()
+	m.pending[index] = block
+	for _, pending := range m.pending {
+		if pending == nil {
+			m.mu.Unlock()
+			return
+		}
+	}
+	w := &work{
+		header: CombineHeaders(blockHeaders(m.pending)),
+		blocks: append([]*types.Block{}, m.pending...),
+	}
+	sealHash := m.workHash(w.header)
+	if _, ok := m.works[sealHash]; ok {
+		m.mu.Unlock()
+		return
+	}
+	m.works[sealHash] = w
+	m.hashes = append(m.hashes, sealHash)
+	if len(m.hashes) > maxWork {
+		delete(m.works, m.hashes[0])
+		m.hashes = m.hashes[1:]
+	}
+	m.current = w
+	m.mu.Unlock()
+
+	workMeter.Mark(1)
+	log.Debug("New combined work", "sealhash", sealHash, "number", w.header.Number)
+	m.workFeed.Send(w.header)
+}
+
+// sealLoop mines the most recent work with the engine, restarting on every
+// new work.
+func (m *Manager) sealLoop() {
+	defer m.wg.Done()
+
+	var (
+		headers = make(chan *types.Header, pendingChanSize)
+		results = make(chan *types.HeaderBundle)
+		stop    chan struct{}
+	)
+	sub := m.SubscribePendingHeader(headers)
+	defer sub.Unsubscribe()
+
+	interrupt := func() {
+		if stop != nil {
+			close(stop)
+			stop = nil
+		}
+	}
+	defer interrupt()
+
+	for {
+		select {
+		case header := <-headers:
+			interrupt()
+			stop = make(chan struct{})
+			if err := m.engine.SealHeader(header, results, stop); err != nil {
+				log.Error("Failed to start sealing", "err", err)
+			}
+		case result := <-results:
+			if err := m.Submit(result.Header, result.Context); err != nil {
+				log.Warn("Failed to deliver mined block", "err", err)
+			}
+		case <-sub.Err():
+			return
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// workHash returns the seal hash of the work a header was sealed from, which
+// is the seal hash of the header without its nonce.
+func (m *Manager) workHash(header *types.Header) common.Hash {
+	header = types.CopyHeader(header)
+	header.Nonce = types.BlockNonce{}
+	return m.engine.SealHash(header)
+}
+
+// Submit delivers a sealed combined header to every context whose difficulty
+// it satisfies, from the given order down to the zone. Every context receives
+// the header with the body of its own pending block.
+func (m *Manager) Submit(header *types.Header, order int) error {
+	if order < 0 || order >= types.ContextDepth {
+		return errInvalidSeal
+	}
+	m.mu.Lock()
+	w := m.works[m.workHash(header)]
+	m.mu.Unlock()
+	if w == nil {
+		return errStaleWork
+	}
+	blockMeters[order].Mark(1)
+	log.Info("Mined block", "hash", header.Hash(), "order", order, "number", header.Number)
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, types.ContextDepth)
+	)
+	for index := order; index < types.ContextDepth; index++ {
+		pending := w.blocks[index]
+		block := types.NewBlockWithHeader(header).WithBody(pending.Transactions(), pending.Uncles())
+
+		wg.Add(1)
+		go func(index int, block *types.Block) {
+			defer wg.Done()
+			ctx, cancel := newContext()
+			defer cancel()
+			errs[index] = m.chains[index].SendMinedBlock(ctx, block, true, true)
+		}(index, block)
+	}
+	wg.Wait()
+
+	for index, err := range errs {
+		if err != nil {
+			deliverMeter.Mark(1)
+			return fmt.Errorf("failed to deliver block to context %d: %v", index, err)
+		}
+	}
+	return nil
+}
+
+// CombineHeaders merges the pending headers of the Prime, Region and Zone
+// chain of a slice into a single header. Every header contributes the fields
+// of its own context, the location is taken from the zone and the time is the
+// latest of the three so it follows the parent of every context.
+func CombineHeaders(headers []*types.Header) *types.Header {
+	combined := &types.Header{
+		ParentHash:        make([]common.Hash, types.ContextDepth),
+		UncleHash:         make([]common.Hash, types.ContextDepth),
+		Coinbase:          make([]common.Address, types.ContextDepth),
+		Root:              make([]common.Hash, types.ContextDepth),
+		TxHash:            make([]common.Hash, types.ContextDepth),
+		ReceiptHash:       make([]common.Hash, types.ContextDepth),
+		Bloom:             make([]types.Bloom, types.ContextDepth),
+		Difficulty:        make([]*big.Int, types.ContextDepth),
+		NetworkDifficulty: make([]*big.Int, types.ContextDepth),
+		Number:            make([]*big.Int, types.ContextDepth),
+		GasLimit:          make([]uint64, types.ContextDepth),
+		GasUsed:           make([]uint64, types.ContextDepth),
+		Extra:             make([][]byte, types.ContextDepth),
+		BaseFee:           make([]*big.Int, types.ContextDepth),
+		Location:          common.CopyBytes(headers[params.ZONE].Location),
+	}
+	for index, header := range headers {
+		combined.ParentHash[index] = header.ParentHash[index]
+		combined.UncleHash[index] = header.UncleHash[index]
+		combined.Coinbase[index] = header.Coinbase[index]
+		combined.Root[index] = header.Root[index]
+		combined.TxHash[index] = header.TxHash[index]
+		combined.ReceiptHash[index] = header.ReceiptHash[index]
+		combined.Bloom[index] = header.Bloom[index]
+		combined.Difficulty[index] = copyBig(header.Difficulty[index])
+		combined.NetworkDifficulty[index] = copyBig(header.NetworkDifficulty[index])
+		combined.Number[index] = copyBig(header.Number[index])
+		combined.GasLimit[index] = header.GasLimit[index]
+		combined.GasUsed[index] = header.GasUsed[index]
+		combined.Extra[index] = common.CopyBytes(header.Extra[index])
+		if index < len(header.BaseFee) {
+			combined.BaseFee[index] = copyBig(header.BaseFee[index])
+		}
+		if header.Time > combined.Time {
+			combined.Time = header.Time
+		}
+	}
+	return combined
+}
+
+// blockHeaders returns the headers of the blocks.
+func blockHeaders(blocks []*types.Block) []*types.Header {
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	return headers
+}
+
+// copyBig returns a copy of b, or nil if b is nil.
+func copyBig(b *big.Int) *big.Int {
+	if b == nil {
+		return nil
+	}
+	return new(big.Int).Set(b)
+}
+
+// newContext returns the context of a request sent to a chain.
+func newContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	quai "github.com/spruce-solutions/go-quai"
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// contextHeader creates a pending header of a context, filling every context
+// field with values derived from it.
+func contextHeader(context int, time uint64) *types.Header {
+	header := &types.Header{Time: time, Location: []byte{byte(context), byte(context)}}
+	for i := 0; i < types.ContextDepth; i++ {
+		header.ParentHash = append(header.ParentHash, common.Hash{byte(context), byte(i)})
+		header.UncleHash = append(header.UncleHash, types.EmptyUncleHash[i])
+		header.Coinbase = append(header.Coinbase, common.Address{byte(context)})
+		header.Root = append(header.Root, common.Hash{byte(context)})
+		header.TxHash = append(header.TxHash, types.EmptyRootHash[i])
+		header.ReceiptHash = append(header.ReceiptHash, types.EmptyRootHash[i])
+		header.Bloom = append(header.Bloom, types.Bloom{})
+		header.Difficulty = append(header.Difficulty, big.NewInt(int64(100*context+i)))
+		header.NetworkDifficulty = append(header.NetworkDifficulty, big.NewInt(int64(100*context+i)))
+		header.Number = append(header.Number, big.NewInt(int64(10*context+i)))
+		header.GasLimit = append(header.GasLimit, uint64(context))
+		header.GasUsed = append(header.GasUsed, uint64(context))
+		header.Extra = append(header.Extra, []byte{byte(context)})
+		header.BaseFee = append(header.BaseFee, big.NewInt(int64(context)))
+	}
+	return header
+}
+
+func TestCombineHeaders(t *testing.T) {
+	headers := []*types.Header{contextHeader(0, 10), contextHeader(1, 30), contextHeader(2, 20)}
+	combined := CombineHeaders(headers)
+
+	for context := 0; context < types.ContextDepth; context++ {
+		if have, want := combined.ParentHash[context], headers[context].ParentHash[context]; have != want {
+			t.Errorf("context %d: parent hash mismatch: have %x, want %x", context, have, want)
+		}
+		if have, want := combined.Number[context], headers[context].Number[context]; have.Cmp(want) != 0 {
+			t.Errorf("context %d: number mismatch: have %v, want %v", context, have, want)
+		}
+		if have, want := combined.Difficulty[context], headers[context].Difficulty[context]; have.Cmp(want) != 0 {
+			t.Errorf("context %d: difficulty mismatch: have %v, want %v", context, have, want)
+		}
+		if have, want := combined.Coinbase[context], headers[context].Coinbase[context]; have != want {
+			t.Errorf("context %d: coinbase mismatch: have %x, want %x", context, have, want)
+		}
+	}
+	if combined.Time != 30 {
+		t.Errorf("time mismatch: have %d, want %d", combined.Time, 30)
+	}
+	if have, want := combined.Location, headers[2].Location; string(have) != string(want) {
+		t.Errorf("location mismatch: have %x, want %x", have, want)
+	}
+	// The combined header must not share state with the pending headers.
+	headers[2].Number[2].SetUint64(0)
+	if combined.Number[2].Uint64() != 22 {
+		t.Errorf("combined header shares the numbers of the pending headers")
+	}
+}
+
+// testChain is a chain of a context recording the blocks mined for it.
+type testChain struct {
+	mu     sync.Mutex
+	blocks []*types.Block
+}
+
+func (c *testChain) SubscribePendingBlock(ctx context.Context, ch chan<- *types.Header) (quai.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testChain) PendingBlock(ctx context.Context) (*types.Block, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testChain) SendMinedBlock(ctx context.Context, block *types.Block, inclTx bool, fullTx bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blocks = append(c.blocks, block)
+	return nil
+}
+
+func (c *testChain) mined() []*types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*types.Block{}, c.blocks...)
+}
+
+// Tests that a sealed combined header is found among the recent work and
+// delivered to every context it satisfies, both when sealed locally and when
+// submitted by an external miner.
+func TestSubmitSealedHeader(t *testing.T) {
+	engine, err := blake3.New(blake3.Config{MiningThreads: 1}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	chains := []*testChain{new(testChain), new(testChain), new(testChain)}
+	m, err := New([]Chain{chains[0], chains[1], chains[2]}, engine, false)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	// Any nonce satisfies the difficulty of every context
+	for context := 0; context < types.ContextDepth; context++ {
+		header := contextHeader(context, 10)
+		for i := range header.Difficulty {
+			header.Difficulty[i] = big.NewInt(1)
+		}
+		m.update(context, types.NewBlockWithHeader(header))
+	}
+	m.mu.Lock()
+	work := m.current
+	m.mu.Unlock()
+	if work == nil {
+		t.Fatalf("no work combined from the pending blocks")
+	}
+	results := make(chan *types.HeaderBundle, 1)
+	if err := engine.SealHeader(types.CopyHeader(work.header), results, make(chan struct{})); err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	var result *types.HeaderBundle
+	select {
+	case result = <-results:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("sealing timed out")
+	}
+	if err := m.Submit(result.Header, result.Context); err != nil {
+		t.Fatalf("failed to submit sealed header: %v", err)
+	}
+	// External miners submit a block of the pending block of the work
+	external := types.CopyHeader(work.header)
+	external.Nonce = types.EncodeNonce(result.Header.Nonce.Uint64() + 1)
+	if err := m.SubmitBlock(m.PendingBlock(engine.SealHash(work.header)).WithSeal(external), params.PRIME); err != nil {
+		t.Fatalf("failed to submit external block: %v", err)
+	}
+	for context, chain := range chains {
+		blocks := chain.mined()
+		if len(blocks) != 2 {
+			t.Fatalf("context %d: mined block count mismatch: have %d, want 2", context, len(blocks))
+		}
+		for i, header := range []*types.Header{result.Header, external} {
+			if blocks[i].Hash() != header.Hash() {
+				t.Errorf("context %d: block %d hash mismatch: have %x, want %x", context, i, blocks[i].Hash(), header.Hash())
+			}
+		}
+	}
+	// Unknown work is rejected
+	stale := types.CopyHeader(work.header)
+	stale.Time++
+	if err := m.Submit(stale, params.PRIME); err != errStaleWork {
+		t.Errorf("stale work error mismatch: have %v, want %v", err, errStaleWork)
+	}
+}
@@ -52,6 +52,10 @@ type Config struct {
 	GasPrice   *big.Int       // Minimum gas price for mining a transaction
 	Recommit   time.Duration  // The time interval for miner to re-create mining work.
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
+
+	Stratum           string `toml:",omitempty"` // Stratum v1 listening address, disabled if empty
+	StratumV2         string `toml:",omitempty"` // Stratum v2 listening address, disabled if empty
+	StratumDifficulty uint64 `toml:",omitempty"` // Initial share difficulty of stratum connections
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -239,6 +243,12 @@ func (miner *Miner) SubscribePendingBlock(ch chan<- *types.Header) event.Subscri
 	return miner.worker.pendingBlockFeed.Subscribe(ch)
 }
 
+// PendingWork returns the sealing block with the given seal hash, if it is
+// still pending.
+func (miner *Miner) PendingWork(sealHash common.Hash) *types.Block {
+	return miner.worker.pendingWork(sealHash)
+}
+
 // Method to retrieve uncles from the worker in case not found in normal DB.
 func (miner *Miner) GetUncle(hash common.Hash) *types.Block {
 	if uncle, exist := miner.worker.localUncles[hash]; exist {
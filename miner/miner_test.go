@@ -78,8 +78,8 @@ func (bc *testBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block
 func (bc *testBlockChain) GetGasUsedInChain(block *types.Block, length int) int64 {
 	gasUsed := 0
 	for i := 0; block != nil && i < length; i++ {
-		gasUsed += int(block.GasUsed())
-		block = bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		gasUsed += int(block.GasUsed(types.QuaiNetworkContext))
+		block = bc.GetBlock(block.ParentHash(types.QuaiNetworkContext), block.NumberU64(types.QuaiNetworkContext)-1)
 	}
 	return int64(gasUsed)
 }
@@ -92,7 +92,7 @@ func (bc *testBlockChain) GetUnclesInChain(block *types.Block, length int) []*ty
 	uncles := []*types.Header{}
 	for i := 0; block != nil && i < length; i++ {
 		uncles = append(uncles, block.Uncles()...)
-		block = bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		block = bc.GetBlock(block.ParentHash(types.QuaiNetworkContext), block.NumberU64(types.QuaiNetworkContext)-1)
 	}
 	return uncles
 }
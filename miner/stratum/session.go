@@ -0,0 +1,155 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stratum
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/log"
+)
+
+const (
+	// maxRetargetStep bounds the factor by which a single adjustment may
+	// change the share difficulty of a connection.
+	maxRetargetStep = 4
+)
+
+// codec is the wire protocol of a connection.
+type codec interface {
+	// serve reads and handles requests until the connection fails.
+	serve() error
+
+	// notify sends a new job, clean telling whether older jobs are worthless.
+	notify(j *job, clean bool) error
+
+	// setDifficulty announces a new share difficulty.
+	setDifficulty(difficulty uint64) error
+}
+
+// session is the state of a miner connection shared by both protocols.
+type session struct {
+	server     *Server
+	conn       net.Conn
+	codec      codec
+	extranonce []byte
+
+	mu         sync.Mutex
+	subscribed bool
+	worker     string
+	difficulty uint64    // Current share difficulty
+	shares     uint64    // Shares accepted since the last retarget
+	retargeted time.Time // Time of the last retarget
+}
+
+func (sess *session) subscribe() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.subscribed = true
+}
+
+func (sess *session) isSubscribed() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	return sess.subscribed
+}
+
+func (sess *session) authorize(worker string) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.worker = worker
+	log.Debug("Stratum worker authorized", "remote", sess.conn.RemoteAddr(), "worker", worker)
+}
+
+func (sess *session) isAuthorized() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	return sess.worker != ""
+}
+
+func (sess *session) workerName() string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	return sess.worker
+}
+
+func (sess *session) shareDifficulty() uint64 {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	return sess.difficulty
+}
+
+// accepted records an accepted share and retargets the share difficulty if
+// the adjustment interval elapsed.
+func (sess *session) accepted(now time.Time) {
+	sess.mu.Lock()
+	sess.shares++
+	sess.mu.Unlock()
+
+	sess.retarget(now)
+}
+
+// retarget adjusts the share difficulty so that the connection submits a share
+// every configured share time, announcing any change to the miner.
+func (sess *session) retarget(now time.Time) {
+	sess.mu.Lock()
+	elapsed := now.Sub(sess.retargeted)
+	if elapsed < sess.server.config.RetargetTime {
+		sess.mu.Unlock()
+		return
+	}
+	old := sess.difficulty
+
+	// Without shares, the best estimate is one share right now
+	shares := sess.shares
+	if shares == 0 {
+		shares = 1
+	}
+	expected := float64(elapsed) / float64(sess.server.config.ShareTime)
+	factor := float64(shares) / expected
+	if factor > maxRetargetStep {
+		factor = maxRetargetStep
+	}
+	if factor < 1.0/maxRetargetStep {
+		factor = 1.0 / maxRetargetStep
+	}
+	difficulty := uint64(float64(old) * factor)
+	if difficulty < 1 {
+		difficulty = 1
+	}
+	sess.difficulty = difficulty
+	sess.shares = 0
+	sess.retargeted = now
+	subscribed := sess.subscribed
+	sess.mu.Unlock()
+
+	if difficulty == old || !subscribed {
+		return
+	}
+	log.Trace("Retargeted stratum share difficulty", "remote", sess.conn.RemoteAddr(), "old", old, "new", difficulty)
+	if err := sess.codec.setDifficulty(difficulty); err != nil {
+		log.Debug("Failed to set stratum difficulty", "remote", sess.conn.RemoteAddr(), "err", err)
+		sess.conn.Close()
+	}
+}
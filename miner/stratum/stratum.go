@@ -0,0 +1,441 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stratum implements a Stratum mining server for blake3 work. Jobs are
+// built from the combined Prime, Region and Zone header produced by the local
+// worker, so a single connection mines every context at once.
+package stratum
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/metrics"
+)
+
+const (
+	// extranonceSize is the number of leading nonce bytes assigned to every
+	// connection, the remaining bytes are searched by the miner.
+	extranonceSize = 2
+
+	// nonceSize is the size of the blake3 header nonce.
+	nonceSize = 8
+
+	// maxJobs is the number of most recent jobs shares are accepted for.
+	maxJobs = 16
+
+	// maxJobShares is the number of shares accepted for a single job, bounding
+	// the memory spent on detecting duplicates.
+	maxJobShares = 16384
+)
+
+var (
+	errStaleJob       = errors.New("job not found")
+	errDuplicateShare = errors.New("duplicate share")
+	errLowDifficulty  = errors.New("low difficulty share")
+	errBadExtranonce  = errors.New("nonce does not match extranonce")
+	errUnauthorized   = errors.New("unauthorized worker")
+
+	big2e256   = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0)) // 2^256
+	maxUint256 = new(big.Int).Sub(big2e256, big.NewInt(1))                       // 2^256-1
+
+	acceptedShareMeter = metrics.NewRegisteredMeter("stratum/shares/accepted", nil)
+	rejectedShareMeter = metrics.NewRegisteredMeter("stratum/shares/rejected", nil)
+	blockMeter         = metrics.NewRegisteredMeter("stratum/blocks", nil)
+	sessionGauge       = metrics.NewRegisteredGauge("stratum/sessions", nil)
+)
+
+// Config are the configuration parameters of the Stratum server.
+type Config struct {
+	ListenAddr   string        // Listening address of the Stratum v1 server (empty = disabled)
+	ListenAddrV2 string        // Listening address of the Stratum v2 server (empty = disabled)
+	Difficulty   uint64        // Initial share difficulty of new connections
+	ShareTime    time.Duration // Desired time between shares of a connection
+	RetargetTime time.Duration // Interval between share difficulty adjustments
+}
+
+// DefaultConfig contains the default configurations for the Stratum server.
+var DefaultConfig = Config{
+	Difficulty:   1 << 16,
+	ShareTime:    10 * time.Second,
+	RetargetTime: 90 * time.Second,
+}
+
+// sanitize checks the provided user configurations and changes anything that's
+// unreasonable or unworkable.
+func (config *Config) sanitize() Config {
+	conf := *config
+	if conf.Difficulty == 0 {
+		log.Warn("Sanitizing invalid stratum difficulty", "provided", conf.Difficulty, "updated", DefaultConfig.Difficulty)
+		conf.Difficulty = DefaultConfig.Difficulty
+	}
+	if conf.ShareTime <= 0 {
+		log.Warn("Sanitizing invalid stratum share time", "provided", conf.ShareTime, "updated", DefaultConfig.ShareTime)
+		conf.ShareTime = DefaultConfig.ShareTime
+	}
+	if conf.RetargetTime < conf.ShareTime {
+		log.Warn("Sanitizing invalid stratum retarget time", "provided", conf.RetargetTime, "updated", 9*conf.ShareTime)
+		conf.RetargetTime = 9 * conf.ShareTime
+	}
+	return conf
+}
+
+// Backend wraps the node services the Stratum server mines for.
+type Backend interface {
+	// SubscribePendingHeader delivers every new header produced by the worker.
+	SubscribePendingHeader(ch chan<- *types.Header) event.Subscription
+
+	// PendingBlock returns the sealing block of the header with the given
+	// seal hash, if it is still pending.
+	PendingBlock(sealHash common.Hash) *types.Block
+
+	// SubmitBlock imports a sealed block and forwards it to the dominant
+	// chains of the context order it satisfies.
+	SubmitBlock(block *types.Block, order int) error
+}
+
+// job is a unit of work served to the miners.
+type job struct {
+	id       uint32
+	header   *types.Header
+	sealHash common.Hash
+	prefix   []byte // Seal data preceding the nonce
+
+	submitted map[types.BlockNonce]struct{} // Shares received, at most maxJobShares
+}
+
+// Server serves blake3 work to Stratum miners.
+type Server struct {
+	config  Config
+	engine  consensus.Engine
+	backend Backend
+
+	mu         sync.Mutex
+	jobs       map[uint32]*job
+	jobIDs     []uint32 // Job ids in creation order
+	current    *job
+	nextJob    uint32
+	extranonce uint16
+	sessions   map[*session]struct{}
+
+	listeners []net.Listener
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New creates a Stratum server mining the headers produced by the backend.
+func New(config Config, engine consensus.Engine, backend Backend) *Server {
+	return &Server{
+		config:   (&config).sanitize(),
+		engine:   engine,
+		backend:  backend,
+		jobs:     make(map[uint32]*job),
+		sessions: make(map[*session]struct{}),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, opening the configured listeners.
+func (s *Server) Start() error {
+	for _, l := range []struct {
+		addr  string
+		codec func(*session) codec
+	}{
+		{s.config.ListenAddr, newV1Codec},
+		{s.config.ListenAddrV2, newV2Codec},
+	} {
+		if l.addr == "" {
+			continue
+		}
+		listener, err := net.Listen("tcp", l.addr)
+		if err != nil {
+			s.Stop()
+			return err
+		}
+		s.listeners = append(s.listeners, listener)
+		log.Info("Stratum server started", "addr", listener.Addr())
+
+		s.wg.Add(1)
+		go s.accept(listener, l.codec)
+	}
+	s.wg.Add(1)
+	go s.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the listeners and every connection.
+func (s *Server) Stop() error {
+	select {
+	case <-s.quit:
+		return nil
+	default:
+		close(s.quit)
+	}
+	for _, listener := range s.listeners {
+		listener.Close()
+	}
+	s.mu.Lock()
+	for sess := range s.sessions {
+		sess.conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// Addrs returns the addresses the server listens on.
+func (s *Server) Addrs() []net.Addr {
+	addrs := make([]net.Addr, len(s.listeners))
+	for i, listener := range s.listeners {
+		addrs[i] = listener.Addr()
+	}
+	return addrs
+}
+
+// loop turns every new pending header into a job and broadcasts it.
+func (s *Server) loop() {
+	defer s.wg.Done()
+
+	headers := make(chan *types.Header, 16)
+	sub := s.backend.SubscribePendingHeader(headers)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case header := <-headers:
+			j, clean := s.newJob(header)
+			s.broadcast(j, clean)
+
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// newJob creates a job for the header, reporting whether it builds on a new
+// parent, making the previous jobs worthless.
+func (s *Server) newJob(header *types.Header) (*job, bool) {
+	header = types.CopyHeader(header)
+	header.Nonce = types.BlockNonce{}
+	data := blake3.SealData(header)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextJob++
+	j := &job{
+		id:        s.nextJob,
+		header:    header,
+		sealHash:  s.engine.SealHash(header),
+		prefix:    data[:len(data)-nonceSize],
+		submitted: make(map[types.BlockNonce]struct{}),
+	}
+	clean := s.current == nil || !sameParents(s.current.header, header)
+	s.current = j
+	s.jobs[j.id] = j
+	s.jobIDs = append(s.jobIDs, j.id)
+	if len(s.jobIDs) > maxJobs {
+		delete(s.jobs, s.jobIDs[0])
+		s.jobIDs = s.jobIDs[1:]
+	}
+	return j, clean
+}
+
+// sameParents reports whether two headers build on the same parent in every
+// context.
+func sameParents(a, b *types.Header) bool {
+	if len(a.ParentHash) != len(b.ParentHash) {
+		return false
+	}
+	for i := range a.ParentHash {
+		if a.ParentHash[i] != b.ParentHash[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// currentJob returns the most recent job, if any.
+func (s *Server) currentJob() *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.current
+}
+
+// broadcast sends a job to every subscribed connection.
+func (s *Server) broadcast(j *job, clean bool) {
+	s.mu.Lock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	for _, sess := range sessions {
+		if !sess.isSubscribed() {
+			continue
+		}
+		sess.retarget(time.Now())
+		if err := sess.codec.notify(j, clean); err != nil {
+			log.Debug("Failed to notify stratum job", "remote", sess.conn.RemoteAddr(), "err", err)
+			sess.conn.Close()
+		}
+	}
+}
+
+// accept serves the connections of a listener with the given protocol.
+func (s *Server) accept(listener net.Listener, newCodec func(*session) codec) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				log.Warn("Stratum listener failed", "addr", listener.Addr(), "err", err)
+			}
+			return
+		}
+		sess := s.newSession(conn)
+		sess.codec = newCodec(sess)
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.dropSession(sess)
+
+			if err := sess.codec.serve(); err != nil {
+				log.Debug("Stratum connection closed", "remote", conn.RemoteAddr(), "err", err)
+			}
+		}()
+	}
+}
+
+// newSession registers a connection, assigning it a unique extranonce.
+func (s *Server) newSession(conn net.Conn) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.extranonce++
+	sess := &session{
+		server:     s,
+		conn:       conn,
+		extranonce: []byte{byte(s.extranonce >> 8), byte(s.extranonce)},
+		difficulty: s.config.Difficulty,
+		retargeted: time.Now(),
+	}
+	s.sessions[sess] = struct{}{}
+	sessionGauge.Update(int64(len(s.sessions)))
+	return sess
+}
+
+// dropSession unregisters a closed connection.
+func (s *Server) dropSession(sess *session) {
+	sess.conn.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, sess)
+	sessionGauge.Update(int64(len(s.sessions)))
+}
+
+// submit validates a share of a connection. It returns the context order the
+// share satisfies, or -1 if it doesn't satisfy any context difficulty. Shares
+// satisfying a context are sealed into a block and submitted to the backend.
+func (s *Server) submit(sess *session, jobID uint32, nonce types.BlockNonce) (int, error) {
+	order, err := s.verify(sess, jobID, nonce)
+	if err != nil {
+		rejectedShareMeter.Mark(1)
+		return -1, err
+	}
+	acceptedShareMeter.Mark(1)
+	sess.accepted(time.Now())
+	return order, nil
+}
+
+func (s *Server) verify(sess *session, jobID uint32, nonce types.BlockNonce) (int, error) {
+	if !sess.isAuthorized() {
+		return -1, errUnauthorized
+	}
+	for i, b := range sess.extranonce {
+		if nonce[i] != b {
+			return -1, errBadExtranonce
+		}
+	}
+	s.mu.Lock()
+	j, ok := s.jobs[jobID]
+	if !ok {
+		s.mu.Unlock()
+		return -1, errStaleJob
+	}
+	if _, ok := j.submitted[nonce]; ok {
+		s.mu.Unlock()
+		return -1, errDuplicateShare
+	}
+	if len(j.submitted) >= maxJobShares {
+		s.mu.Unlock()
+		return -1, errStaleJob
+	}
+	j.submitted[nonce] = struct{}{}
+	s.mu.Unlock()
+
+	header := types.CopyHeader(j.header)
+	header.Nonce = nonce
+
+	hash := new(big.Int).SetBytes(s.engine.SealHash(header).Bytes())
+	if hash.Cmp(shareTarget(sess.shareDifficulty())) > 0 {
+		return -1, errLowDifficulty
+	}
+	order, err := s.engine.GetDifficultyOrder(header)
+	if err != nil {
+		// Valid share, but not a block of any context
+		return -1, nil
+	}
+	block := s.backend.PendingBlock(j.sealHash)
+	if block == nil {
+		log.Warn("Stratum block found for expired work", "sealhash", j.sealHash, "order", order)
+		return order, nil
+	}
+	sealed := block.WithSeal(header)
+	blockMeter.Mark(1)
+	log.Info("Stratum block found", "hash", sealed.Hash(), "order", order, "worker", sess.workerName())
+	if err := s.backend.SubmitBlock(sealed, order); err != nil {
+		log.Warn("Failed to submit stratum block", "hash", sealed.Hash(), "order", order, "err", err)
+	}
+	return order, nil
+}
+
+// shareTarget converts a share difficulty into the hash target shares must meet.
+func shareTarget(difficulty uint64) *big.Int {
+	if difficulty <= 1 {
+		return new(big.Int).Set(maxUint256)
+	}
+	return new(big.Int).Div(big2e256, new(big.Int).SetUint64(difficulty))
+}
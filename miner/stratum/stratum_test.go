@@ -0,0 +1,305 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stratum
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/consensus/blake3"
+	"github.com/spruce-solutions/go-quai/core/types"
+	"github.com/spruce-solutions/go-quai/event"
+	"github.com/spruce-solutions/go-quai/params"
+)
+
+// testBackend serves a single pending header and records submitted blocks.
+type testBackend struct {
+	feed event.Feed
+
+	mu     sync.Mutex
+	blocks []*types.Block
+	orders []int
+}
+
+func (b *testBackend) SubscribePendingHeader(ch chan<- *types.Header) event.Subscription {
+	return b.feed.Subscribe(ch)
+}
+
+func (b *testBackend) PendingBlock(sealHash common.Hash) *types.Block {
+	return types.NewBlockWithHeader(testHeader())
+}
+
+func (b *testBackend) SubmitBlock(block *types.Block, order int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.blocks = append(b.blocks, block)
+	b.orders = append(b.orders, order)
+	return nil
+}
+
+func (b *testBackend) submitted() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]int{}, b.orders...)
+}
+
+// testHeader returns a header any share satisfies as a zone block, but that
+// is practically impossible to satisfy as a region or prime block.
+func testHeader() *types.Header {
+	header := types.NewEmptyHeader()
+	header.Difficulty[params.PRIME] = new(big.Int).Lsh(big.NewInt(1), 100)
+	header.Difficulty[params.REGION] = new(big.Int).Lsh(big.NewInt(1), 100)
+	header.Difficulty[params.ZONE] = big.NewInt(1)
+	header.Location = []byte{1, 1}
+	return header
+}
+
+func newTestServer(t *testing.T) (*Server, *testBackend) {
+	engine, err := blake3.New(blake3.Config{}, nil, false)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	backend := new(testBackend)
+	server := New(Config{
+		ListenAddr:   "127.0.0.1:0",
+		ListenAddrV2: "127.0.0.1:0",
+		Difficulty:   1,
+		ShareTime:    time.Hour,
+		RetargetTime: time.Hour,
+	}, engine, backend)
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	// Wait for the job loop to subscribe before publishing work
+	for backend.feed.Send(testHeader()) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for server.currentJob() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return server, backend
+}
+
+func TestStratumV1(t *testing.T) {
+	server, backend := newTestServer(t)
+	defer server.Stop()
+
+	conn, err := net.Dial("tcp", server.Addrs()[0].String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	call := func(method string, params ...interface{}) {
+		req, _ := json.Marshal(map[string]interface{}{"id": 1, "method": method, "params": params})
+		if _, err := conn.Write(append(req, '\n')); err != nil {
+			t.Fatalf("failed to send %s: %v", method, err)
+		}
+	}
+	read := func() map[string]interface{} {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(line, &msg); err != nil {
+			t.Fatalf("invalid message %s: %v", line, err)
+		}
+		return msg
+	}
+	call("mining.subscribe")
+	if result, ok := read()["result"].([]interface{}); !ok || result[1] != "0001" {
+		t.Fatalf("unexpected subscribe result: %v", result)
+	}
+	if msg := read(); msg["method"] != "mining.set_difficulty" {
+		t.Fatalf("expected difficulty, got %v", msg)
+	}
+	notify := read()
+	if notify["method"] != "mining.notify" {
+		t.Fatalf("expected job, got %v", notify)
+	}
+	jobID := notify["params"].([]interface{})[0].(string)
+
+	// Shares are rejected until the worker is authorized
+	call("mining.submit", "worker", jobID, "000000000001")
+	if msg := read(); msg["result"] != false {
+		t.Fatalf("unauthorized share accepted: %v", msg)
+	}
+	call("mining.authorize", "worker", "")
+	if msg := read(); msg["result"] != true {
+		t.Fatalf("authorization failed: %v", msg)
+	}
+	call("mining.submit", "worker", jobID, "000000000002")
+	if msg := read(); msg["result"] != true {
+		t.Fatalf("share rejected: %v", msg)
+	}
+	share := read()
+	if share["method"] != "quai.share" || share["params"].([]interface{})[2] != float64(params.ZONE) {
+		t.Fatalf("unexpected share order: %v", share)
+	}
+	call("mining.submit", "worker", jobID, "000000000002")
+	if msg := read(); msg["result"] != false || msg["error"].([]interface{})[0] != float64(v1ErrDuplicate) {
+		t.Fatalf("duplicate share accepted: %v", msg)
+	}
+	call("mining.submit", "worker", "ffff", "000000000003")
+	if msg := read(); msg["result"] != false || msg["error"].([]interface{})[0] != float64(v1ErrStaleJob) {
+		t.Fatalf("stale share accepted: %v", msg)
+	}
+	if orders := backend.submitted(); len(orders) != 1 || orders[0] != params.ZONE {
+		t.Fatalf("unexpected submitted blocks: %v", orders)
+	}
+}
+
+func TestStratumV2(t *testing.T) {
+	server, backend := newTestServer(t)
+	defer server.Stop()
+
+	conn, err := net.Dial("tcp", server.Addrs()[1].String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	send := func(msgType uint8, payload v2Writer) {
+		frame := v2Writer{}
+		frame.u16(0)
+		frame.u8(msgType)
+		frame = append(frame, byte(len(payload)), byte(len(payload)>>8), byte(len(payload)>>16))
+		if _, err := conn.Write(append(frame, payload...)); err != nil {
+			t.Fatalf("failed to send message %#x: %v", msgType, err)
+		}
+	}
+	read := func() (uint16, uint8, *v2Reader) {
+		header := make([]byte, v2HeaderSize)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		payload := make([]byte, int(header[3])|int(header[4])<<8|int(header[5])<<16)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+		return binary.LittleEndian.Uint16(header) &^ v2ChannelMsgBit, header[2], &v2Reader{buf: payload}
+	}
+	var setup v2Writer
+	setup.u8(v2MiningProtocol)
+	setup.u16(2)
+	setup.u16(2)
+	send(v2SetupConnection, setup)
+	if _, msgType, _ := read(); msgType != v2SetupConnectionSuccess {
+		t.Fatalf("setup failed: message %#x", msgType)
+	}
+	var open v2Writer
+	open.u32(7)
+	open.str0255("worker")
+	send(v2OpenStandardMiningChannel, open)
+	_, msgType, r := read()
+	if msgType != v2OpenStandardMiningChannelOK || r.u32() != 7 || r.u32() != v2ChannelID {
+		t.Fatalf("failed to open channel: message %#x", msgType)
+	}
+	r.next(32)
+	prefix := r.next(int(r.u8()))
+
+	_, msgType, r = read()
+	if msgType != v2NewMiningJob {
+		t.Fatalf("expected job, got message %#x", msgType)
+	}
+	r.u32()
+	jobID := r.u32()
+
+	var submit v2Writer
+	submit.u32(v2ChannelID)
+	submit.u32(1)
+	submit.u32(jobID)
+	submit.u64(uint64(prefix[0])<<56 | uint64(prefix[1])<<48 | 42)
+	send(v2SubmitSharesStandard, submit)
+	if _, msgType, _ := read(); msgType != v2SubmitSharesSuccess {
+		t.Fatalf("share rejected: message %#x", msgType)
+	}
+	extension, msgType, r := read()
+	if extension != v2QuaiExtension || msgType != v2QuaiShareOrder {
+		t.Fatalf("expected share order, got extension %#x message %#x", extension, msgType)
+	}
+	r.u32()
+	r.u32()
+	if order := int(int8(r.u8())); order != params.ZONE {
+		t.Fatalf("unexpected share order: have %d, want %d", order, params.ZONE)
+	}
+	// A nonce outside of the extranonce prefix is rejected
+	submit = v2Writer{}
+	submit.u32(v2ChannelID)
+	submit.u32(2)
+	submit.u32(jobID)
+	submit.u64(uint64(prefix[0]+1)<<56 | 42)
+	send(v2SubmitSharesStandard, submit)
+	if _, msgType, _ := read(); msgType != v2SubmitSharesError {
+		t.Fatalf("invalid share accepted: message %#x", msgType)
+	}
+	if orders := backend.submitted(); len(orders) != 1 {
+		t.Fatalf("unexpected submitted blocks: %v", orders)
+	}
+}
+
+// Tests that the shares remembered for duplicate detection are bounded per job.
+func TestStratumJobShareLimit(t *testing.T) {
+	server, _ := newTestServer(t)
+	defer server.Stop()
+
+	local, remote := net.Pipe()
+	defer remote.Close()
+	sess := server.newSession(local)
+	defer server.dropSession(sess)
+	sess.authorize("worker")
+
+	j := server.currentJob()
+	nonce := func(n uint32) types.BlockNonce {
+		var nonce types.BlockNonce
+		copy(nonce[:], sess.extranonce)
+		binary.BigEndian.PutUint32(nonce[4:], n)
+		return nonce
+	}
+	server.mu.Lock()
+	for n := uint32(0); len(j.submitted) < maxJobShares-1; n++ {
+		j.submitted[nonce(n)] = struct{}{}
+	}
+	server.mu.Unlock()
+
+	if _, err := server.submit(sess, j.id, nonce(maxJobShares)); err != nil {
+		t.Fatalf("share rejected: %v", err)
+	}
+	if _, err := server.submit(sess, j.id, nonce(maxJobShares+1)); err != errStaleJob {
+		t.Fatalf("share over the limit: have %v, want %v", err, errStaleJob)
+	}
+	if _, err := server.submit(sess, j.id, nonce(0)); err != errDuplicateShare {
+		t.Fatalf("duplicate share: have %v, want %v", err, errDuplicateShare)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(j.submitted) != maxJobShares {
+		t.Fatalf("remembered share count mismatch: have %d, want %d", len(j.submitted), maxJobShares)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stratum
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/spruce-solutions/go-quai/core/types"
+)
+
+// Stratum v1 error codes.
+const (
+	v1ErrOther         = 20
+	v1ErrStaleJob      = 21
+	v1ErrDuplicate     = 22
+	v1ErrLowDifficulty = 23
+	v1ErrUnauthorized  = 24
+)
+
+// maxV1LineSize is the maximum size of a Stratum v1 request.
+const maxV1LineSize = 64 * 1024
+
+// v1Request is a Stratum v1 JSON-RPC request.
+type v1Request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// v1Response is a Stratum v1 JSON-RPC response.
+type v1Response struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+// v1Notification is a Stratum v1 JSON-RPC notification.
+type v1Notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// v1Codec implements the line delimited JSON-RPC Stratum v1 protocol.
+//
+// Jobs are sent as mining.notify [job id, seal prefix, clean jobs], the seal
+// prefix being the hex encoded blake3 preimage preceding the 8 byte nonce. The
+// nonce is the extranonce1 assigned by mining.subscribe followed by the
+// extranonce2 submitted with mining.submit [worker, job id, extranonce2].
+// Every accepted share is followed by a quai.share [job id, extranonce2, order]
+// notification reporting the context order the share satisfies, -1 if none.
+type v1Codec struct {
+	sess *session
+
+	writeMu sync.Mutex
+	enc     *json.Encoder
+}
+
+func newV1Codec(sess *session) codec {
+	return &v1Codec{sess: sess, enc: json.NewEncoder(sess.conn)}
+}
+
+func (c *v1Codec) write(msg interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.enc.Encode(msg)
+}
+
+func (c *v1Codec) serve() error {
+	scanner := bufio.NewScanner(c.sess.conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxV1LineSize)
+	for scanner.Scan() {
+		var req v1Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			return fmt.Errorf("invalid stratum request: %v", err)
+		}
+		if err := c.handle(&req); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers a single request.
+func (c *v1Codec) handle(req *v1Request) error {
+	switch req.Method {
+	case "mining.subscribe":
+		subID := hex.EncodeToString(c.sess.extranonce)
+		result := []interface{}{
+			[][]string{{"mining.set_difficulty", subID}, {"mining.notify", subID}},
+			hex.EncodeToString(c.sess.extranonce),
+			nonceSize - extranonceSize,
+		}
+		if err := c.write(&v1Response{ID: req.ID, Result: result}); err != nil {
+			return err
+		}
+		c.sess.subscribe()
+		if err := c.setDifficulty(c.sess.shareDifficulty()); err != nil {
+			return err
+		}
+		if j := c.sess.server.currentJob(); j != nil {
+			return c.notify(j, true)
+		}
+		return nil
+
+	case "mining.authorize":
+		var worker string
+		if len(req.Params) == 0 || json.Unmarshal(req.Params[0], &worker) != nil || worker == "" {
+			return c.write(&v1Response{ID: req.ID, Result: false, Error: v1Error(v1ErrUnauthorized, errUnauthorized)})
+		}
+		c.sess.authorize(worker)
+		return c.write(&v1Response{ID: req.ID, Result: true})
+
+	case "mining.extranonce.subscribe":
+		// The extranonce of a connection never changes
+		return c.write(&v1Response{ID: req.ID, Result: true})
+
+	case "mining.submit":
+		var jobID, extranonce2 string
+		if len(req.Params) < 3 || json.Unmarshal(req.Params[1], &jobID) != nil || json.Unmarshal(req.Params[2], &extranonce2) != nil {
+			return c.write(&v1Response{ID: req.ID, Result: false, Error: v1Error(v1ErrOther, fmt.Errorf("invalid submit parameters"))})
+		}
+		id, err := strconv.ParseUint(jobID, 16, 32)
+		if err != nil {
+			return c.write(&v1Response{ID: req.ID, Result: false, Error: v1Error(v1ErrStaleJob, errStaleJob)})
+		}
+		suffix, err := hex.DecodeString(extranonce2)
+		if err != nil || len(suffix) != nonceSize-extranonceSize {
+			return c.write(&v1Response{ID: req.ID, Result: false, Error: v1Error(v1ErrOther, fmt.Errorf("invalid extranonce2"))})
+		}
+		var nonce types.BlockNonce
+		copy(nonce[:], c.sess.extranonce)
+		copy(nonce[extranonceSize:], suffix)
+
+		order, err := c.sess.server.submit(c.sess, uint32(id), nonce)
+		if err != nil {
+			return c.write(&v1Response{ID: req.ID, Result: false, Error: v1Error(v1SubmitErrorCode(err), err)})
+		}
+		if err := c.write(&v1Response{ID: req.ID, Result: true}); err != nil {
+			return err
+		}
+		return c.write(&v1Notification{Method: "quai.share", Params: []interface{}{jobID, extranonce2, order}})
+
+	default:
+		return c.write(&v1Response{ID: req.ID, Error: v1Error(v1ErrOther, fmt.Errorf("unsupported method %q", req.Method))})
+	}
+}
+
+func (c *v1Codec) notify(j *job, clean bool) error {
+	params := []interface{}{strconv.FormatUint(uint64(j.id), 16), hex.EncodeToString(j.prefix), clean}
+	return c.write(&v1Notification{Method: "mining.notify", Params: params})
+}
+
+func (c *v1Codec) setDifficulty(difficulty uint64) error {
+	return c.write(&v1Notification{Method: "mining.set_difficulty", Params: []interface{}{difficulty}})
+}
+
+// v1Error encodes a Stratum v1 error.
+func v1Error(code int, err error) []interface{} {
+	return []interface{}{code, err.Error(), nil}
+}
+
+// v1SubmitErrorCode maps a share rejection onto its Stratum v1 error code.
+func v1SubmitErrorCode(err error) int {
+	switch err {
+	case errStaleJob:
+		return v1ErrStaleJob
+	case errDuplicateShare:
+		return v1ErrDuplicate
+	case errLowDifficulty:
+		return v1ErrLowDifficulty
+	case errUnauthorized:
+		return v1ErrUnauthorized
+	default:
+		return v1ErrOther
+	}
+}
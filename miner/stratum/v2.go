@@ -0,0 +1,342 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stratum
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/spruce-solutions/go-quai/core/types"
+)
+
+// Stratum v2 message types of the mining protocol.
+const (
+	v2SetupConnection             = 0x00
+	v2SetupConnectionSuccess      = 0x01
+	v2SetupConnectionError        = 0x02
+	v2OpenStandardMiningChannel   = 0x10
+	v2OpenStandardMiningChannelOK = 0x11
+	v2OpenMiningChannelError      = 0x12
+	v2NewMiningJob                = 0x15
+	v2SubmitSharesStandard        = 0x1a
+	v2SubmitSharesSuccess         = 0x1c
+	v2SubmitSharesError           = 0x1d
+	v2SetTarget                   = 0x21
+
+	// v2QuaiShareOrder reports the context order of an accepted share.
+	v2QuaiShareOrder = 0x00
+)
+
+const (
+	v2MiningProtocol = 0x00    // Protocol id of the mining protocol
+	v2HeaderSize     = 6       // Size of the frame header
+	v2MaxPayloadSize = 1 << 16 // Maximum accepted payload size
+	v2ChannelID      = 1       // Every connection has a single standard channel
+
+	v2ChannelMsgBit uint16 = 0x8000 // Extension type flag of channel messages
+	v2QuaiExtension uint16 = 0x5100 // Extension type of the Quai messages
+)
+
+var errV2Protocol = errors.New("unsupported stratum v2 protocol")
+
+// v2Codec implements the binary framing and the standard channel messages of
+// the Stratum v2 mining protocol, without the Noise encryption layer.
+//
+// As blake3 headers are not Bitcoin headers, NewMiningJob carries the seal
+// prefix (the blake3 preimage preceding the nonce) in place of the version
+// and merkle root, and SubmitSharesStandard carries the whole 8 byte nonce in
+// place of the nonce, ntime and version fields. The upper bytes of the nonce
+// must match the extranonce prefix assigned when opening the channel. Every
+// accepted share is followed by a Quai extension message reporting the context
+// order it satisfies.
+type v2Codec struct {
+	sess *session
+
+	writeMu sync.Mutex
+	open    bool
+}
+
+func newV2Codec(sess *session) codec {
+	return &v2Codec{sess: sess}
+}
+
+// v2Writer serializes the fields of a Stratum v2 message.
+type v2Writer []byte
+
+func (w *v2Writer) u8(v uint8) { *w = append(*w, v) }
+
+func (w *v2Writer) u16(v uint16) {
+	var b [2]byte
+	binary.LittleEndian.PutUint16(b[:], v)
+	*w = append(*w, b[:]...)
+}
+
+func (w *v2Writer) u32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	*w = append(*w, b[:]...)
+}
+
+func (w *v2Writer) u64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	*w = append(*w, b[:]...)
+}
+
+func (w *v2Writer) str0255(s string) {
+	w.u8(uint8(len(s)))
+	*w = append(*w, s...)
+}
+
+func (w *v2Writer) b032(b []byte) {
+	w.u8(uint8(len(b)))
+	*w = append(*w, b...)
+}
+
+func (w *v2Writer) b064k(b []byte) {
+	w.u16(uint16(len(b)))
+	*w = append(*w, b...)
+}
+
+// u256 appends a 256 bit little endian integer from its big endian bytes.
+func (w *v2Writer) u256(b []byte) {
+	var le [32]byte
+	for i := 0; i < len(b) && i < 32; i++ {
+		le[i] = b[len(b)-1-i]
+	}
+	*w = append(*w, le[:]...)
+}
+
+// v2Reader deserializes the fields of a Stratum v2 message.
+type v2Reader struct {
+	buf []byte
+	err error
+}
+
+func (r *v2Reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *v2Reader) u8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *v2Reader) u16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *v2Reader) u32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *v2Reader) u64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *v2Reader) str0255() string {
+	return string(r.next(int(r.u8())))
+}
+
+func (c *v2Codec) write(extension uint16, msgType uint8, payload []byte) error {
+	frame := make(v2Writer, 0, v2HeaderSize+len(payload))
+	frame.u16(extension)
+	frame.u8(msgType)
+	frame = append(frame, byte(len(payload)), byte(len(payload)>>8), byte(len(payload)>>16))
+	frame = append(frame, payload...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err := c.sess.conn.Write(frame)
+	return err
+}
+
+func (c *v2Codec) serve() error {
+	header := make([]byte, v2HeaderSize)
+	for {
+		if _, err := io.ReadFull(c.sess.conn, header); err != nil {
+			return err
+		}
+		extension := binary.LittleEndian.Uint16(header) &^ v2ChannelMsgBit
+		msgType := header[2]
+		length := int(header[3]) | int(header[4])<<8 | int(header[5])<<16
+		if length > v2MaxPayloadSize {
+			return fmt.Errorf("stratum v2 message too large: %d bytes", length)
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.sess.conn, payload); err != nil {
+			return err
+		}
+		if extension != 0 {
+			// Unknown extensions must be ignored
+			continue
+		}
+		if err := c.handle(msgType, &v2Reader{buf: payload}); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers a single message.
+func (c *v2Codec) handle(msgType uint8, r *v2Reader) error {
+	switch msgType {
+	case v2SetupConnection:
+		protocol := r.u8()
+		minVersion, maxVersion := r.u16(), r.u16()
+		if r.err != nil {
+			return r.err
+		}
+		if protocol != v2MiningProtocol || minVersion > 2 || maxVersion < 2 {
+			var w v2Writer
+			w.u32(0)
+			w.str0255("unsupported-protocol")
+			c.write(0, v2SetupConnectionError, w)
+			return errV2Protocol
+		}
+		var w v2Writer
+		w.u16(2)
+		w.u32(0)
+		return c.write(0, v2SetupConnectionSuccess, w)
+
+	case v2OpenStandardMiningChannel:
+		requestID := r.u32()
+		user := r.str0255()
+		if r.err != nil {
+			return r.err
+		}
+		if user == "" {
+			var w v2Writer
+			w.u32(requestID)
+			w.str0255("unknown-user")
+			return c.write(0, v2OpenMiningChannelError, w)
+		}
+		c.sess.authorize(user)
+
+		var w v2Writer
+		w.u32(requestID)
+		w.u32(v2ChannelID)
+		w.u256(shareTarget(c.sess.shareDifficulty()).Bytes())
+		w.b032(c.sess.extranonce)
+		w.u32(0)
+		if err := c.write(0, v2OpenStandardMiningChannelOK, w); err != nil {
+			return err
+		}
+		c.open = true
+		c.sess.subscribe()
+		if j := c.sess.server.currentJob(); j != nil {
+			return c.notify(j, true)
+		}
+		return nil
+
+	case v2SubmitSharesStandard:
+		channelID := r.u32()
+		sequence := r.u32()
+		jobID := r.u32()
+		nonce := types.EncodeNonce(r.u64())
+		if r.err != nil {
+			return r.err
+		}
+		if !c.open || channelID != v2ChannelID {
+			return c.submitError(channelID, sequence, "invalid-channel-id")
+		}
+		order, err := c.sess.server.submit(c.sess, jobID, nonce)
+		if err != nil {
+			return c.submitError(channelID, sequence, v2SubmitErrorCode(err))
+		}
+		var w v2Writer
+		w.u32(channelID)
+		w.u32(sequence)
+		w.u32(1)
+		w.u64(c.sess.shareDifficulty())
+		if err := c.write(v2ChannelMsgBit, v2SubmitSharesSuccess, w); err != nil {
+			return err
+		}
+		var ext v2Writer
+		ext.u32(channelID)
+		ext.u32(sequence)
+		ext.u8(uint8(int8(order)))
+		return c.write(v2QuaiExtension|v2ChannelMsgBit, v2QuaiShareOrder, ext)
+
+	default:
+		// Messages not needed by standard channels are ignored
+		return nil
+	}
+}
+
+func (c *v2Codec) submitError(channelID, sequence uint32, code string) error {
+	var w v2Writer
+	w.u32(channelID)
+	w.u32(sequence)
+	w.str0255(code)
+	return c.write(v2ChannelMsgBit, v2SubmitSharesError, w)
+}
+
+func (c *v2Codec) notify(j *job, clean bool) error {
+	var w v2Writer
+	w.u32(v2ChannelID)
+	w.u32(j.id)
+	w.u8(0) // Never a future job
+	w.b064k(j.prefix)
+	return c.write(v2ChannelMsgBit, v2NewMiningJob, w)
+}
+
+func (c *v2Codec) setDifficulty(difficulty uint64) error {
+	var w v2Writer
+	w.u32(v2ChannelID)
+	w.u256(shareTarget(difficulty).Bytes())
+	return c.write(v2ChannelMsgBit, v2SetTarget, w)
+}
+
+// v2SubmitErrorCode maps a share rejection onto its Stratum v2 error code.
+func v2SubmitErrorCode(err error) string {
+	switch err {
+	case errStaleJob:
+		return "stale-share"
+	case errDuplicateShare:
+		return "duplicate-share"
+	case errLowDifficulty:
+		return "difficulty-too-low"
+	case errUnauthorized:
+		return "invalid-channel-id"
+	default:
+		return "invalid-share"
+	}
+}
@@ -341,6 +341,18 @@ func (w *worker) pendingBlock() *types.Block {
 	return w.snapshotBlock
 }
 
+// pendingWork returns the sealing block with the given seal hash, if it is
+// still pending.
+func (w *worker) pendingWork(sealHash common.Hash) *types.Block {
+	w.pendingMu.RLock()
+	defer w.pendingMu.RUnlock()
+
+	if task, exist := w.pendingTasks[sealHash]; exist {
+		return task.block
+	}
+	return nil
+}
+
 // pendingBlockAndReceipts returns pending block and corresponding receipts.
 func (w *worker) pendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	// return a snapshot to avoid contention on currentMu mutex
@@ -426,7 +438,7 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 	clearPending := func(number uint64) {
 		w.pendingMu.Lock()
 		for h, t := range w.pendingTasks {
-			if t.block.NumberU64()+staleThreshold <= number {
+			if t.block.NumberU64(w.chainConfig.Context)+staleThreshold <= number {
 				delete(w.pendingTasks, h)
 			}
 		}
@@ -436,12 +448,12 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 	for {
 		select {
 		case <-w.startCh:
-			clearPending(w.chain.CurrentBlock().NumberU64())
+			clearPending(w.chain.CurrentBlock().NumberU64(w.chainConfig.Context))
 			timestamp = time.Now().Unix()
 			commit(false, commitInterruptNewHead)
 
 		case head := <-w.chainHeadCh:
-			clearPending(head.Block.NumberU64())
+			clearPending(head.Block.NumberU64(w.chainConfig.Context))
 			timestamp = time.Now().Unix()
 			commit(false, commitInterruptNewHead)
 
@@ -551,12 +563,12 @@ func (w *worker) mainLoop() {
 		case <-cleanTicker.C:
 			chainHead := w.chain.CurrentBlock()
 			for hash, uncle := range w.localUncles {
-				if uncle.NumberU64()+staleThreshold <= chainHead.NumberU64() {
+				if uncle.NumberU64(w.chainConfig.Context)+staleThreshold <= chainHead.NumberU64(w.chainConfig.Context) {
 					delete(w.localUncles, hash)
 				}
 			}
 			for hash, uncle := range w.remoteUncles {
-				if uncle.NumberU64()+staleThreshold <= chainHead.NumberU64() {
+				if uncle.NumberU64(w.chainConfig.Context)+staleThreshold <= chainHead.NumberU64(w.chainConfig.Context) {
 					delete(w.remoteUncles, hash)
 				}
 			}
@@ -577,7 +589,7 @@ func (w *worker) mainLoop() {
 					acc, _ := types.Sender(w.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.current.header.BaseFee[types.QuaiNetworkContext])
+				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs, w.current.header.BaseFee[w.chainConfig.Context])
 				tcount := w.current.tcount
 				w.commitTransactions(w.current, txset, nil)
 
@@ -670,7 +682,7 @@ func (w *worker) resultLoop() {
 				continue
 			}
 			// Short circuit when receiving duplicate result caused by resubmitting.
-			if w.chain.HasBlock(block.Hash(), block.NumberU64()) {
+			if w.chain.HasBlock(block.Hash(), block.NumberU64(w.chainConfig.Context)) {
 				continue
 			}
 			var (
@@ -681,7 +693,7 @@ func (w *worker) resultLoop() {
 			task, exist := w.pendingTasks[sealhash]
 			w.pendingMu.RUnlock()
 			if !exist {
-				log.Error("Block found but no relative pending task", "number", block.Number(), "sealhash", sealhash, "hash", hash)
+				log.Error("Block found but no relative pending task", "number", block.Number(w.chainConfig.Context), "sealhash", sealhash, "hash", hash)
 				continue
 			}
 			// Different block could share same sealhash, deep copy here to prevent write-write conflict.
@@ -696,7 +708,7 @@ func (w *worker) resultLoop() {
 
 				// add block location fields
 				receipt.BlockHash = hash
-				receipt.BlockNumber = block.Number()
+				receipt.BlockNumber = block.Number(w.chainConfig.Context)
 				receipt.TransactionIndex = uint(i)
 
 				// Update the block hash in all logs since it is now available and not when the
@@ -711,14 +723,14 @@ func (w *worker) resultLoop() {
 				logs = append(logs, receipt.Logs...)
 			}
 
-			log.Info("Successfully sealed new block", "number", block.Number(), "sealhash", sealhash, "hash", hash,
+			log.Info("Successfully sealed new block", "number", block.Number(w.chainConfig.Context), "sealhash", sealhash, "hash", hash,
 				"elapsed", common.PrettyDuration(time.Since(task.createdAt)))
 
 			// Broadcast the block and announce chain insertion event
 			w.mux.Post(core.NewMinedBlockEvent{Block: block})
 
 			// Insert the block into the set of pending ones to resultLoop for confirmations
-			w.unconfirmed.Insert(block.NumberU64(), block.Hash())
+			w.unconfirmed.Insert(block.NumberU64(w.chainConfig.Context), block.Hash())
 
 		case <-w.exitCh:
 			return
@@ -730,7 +742,7 @@ func (w *worker) resultLoop() {
 func (w *worker) makeEnv(parent *types.Block, header *types.Header, coinbase common.Address) (*environment, error) {
 	// Retrieve the parent state to execute on top and start a prefetcher for
 	// the miner to speed block sealing up a bit.
-	state, err := w.chain.StateAt(parent.Root())
+	state, err := w.chain.StateAt(parent.Root(w.chainConfig.Context))
 	if err != nil {
 		// Note since the sealing block can be created upon the arbitrary parent
 		// block, but the state of parent block may already be pruned, so the necessary
@@ -739,7 +751,7 @@ func (w *worker) makeEnv(parent *types.Block, header *types.Header, coinbase com
 		// The maximum acceptable reorg depth can be limited by the finalised block
 		// somehow. TODO(rjl493456442) fix the hard-coded number here later.
 		state, err = w.eth.StateAtBlock(parent, 1024, nil, false, false)
-		log.Warn("Recovered mining state", "root", parent.Root(), "err", err)
+		log.Warn("Recovered mining state", "root", parent.Root(w.chainConfig.Context), "err", err)
 	}
 	if err != nil {
 		return nil, err
@@ -748,7 +760,7 @@ func (w *worker) makeEnv(parent *types.Block, header *types.Header, coinbase com
 
 	// Note the passed coinbase may be different with header.Coinbase.
 	env := &environment{
-		signer:          types.MakeSigner(w.chainConfig, header.Number[types.QuaiNetworkContext]),
+		signer:          types.MakeSigner(w.chainConfig, header.Number[w.chainConfig.Context]),
 		state:           state,
 		coinbase:        coinbase,
 		ancestors:       mapset.NewSet(),
@@ -776,10 +788,10 @@ func (w *worker) commitUncle(env *environment, uncle *types.Header) error {
 	if _, exist := env.uncles[hash]; exist {
 		return errors.New("uncle not unique")
 	}
-	if env.header.ParentHash[types.QuaiNetworkContext] == uncle.ParentHash[types.QuaiNetworkContext] {
+	if env.header.ParentHash[w.chainConfig.Context] == uncle.ParentHash[w.chainConfig.Context] {
 		return errors.New("uncle is sibling")
 	}
-	if !env.ancestors.Contains(uncle.ParentHash[types.QuaiNetworkContext]) {
+	if !env.ancestors.Contains(uncle.ParentHash[w.chainConfig.Context]) {
 		return errors.New("uncle's parent unknown")
 	}
 	if env.family.Contains(hash) {
@@ -808,7 +820,7 @@ func (w *worker) updateSnapshot(env *environment) {
 func (w *worker) commitTransaction(env *environment, tx *types.Transaction) ([]*types.Log, error) {
 	if tx != nil {
 		snap := env.state.Snapshot()
-		receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed[types.QuaiNetworkContext], *w.chain.GetVMConfig())
+		receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, tx, &env.header.GasUsed[w.chainConfig.Context], *w.chain.GetVMConfig())
 		if err != nil {
 			env.state.RevertToSnapshot(snap)
 			return nil, err
@@ -826,7 +838,7 @@ func (w *worker) commitExternalTransaction(env *environment, tx *types.Transacti
 
 		snap := env.state.Snapshot()
 
-		receipt, err := core.ApplyExternalTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, externalBlock, tx, &env.header.GasUsed[types.QuaiNetworkContext], *w.chain.GetVMConfig())
+		receipt, err := core.ApplyExternalTransaction(w.chainConfig, w.chain, &env.coinbase, env.gasPool, env.state, env.header, externalBlock, tx, &env.header.GasUsed[w.chainConfig.Context], *w.chain.GetVMConfig())
 		if err != nil {
 			env.state.RevertToSnapshot(snap)
 			return nil, err
@@ -842,7 +854,7 @@ func (w *worker) commitExternalTransaction(env *environment, tx *types.Transacti
 func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByPriceAndNonce, interrupt *int32) bool {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
-		env.gasPool = new(core.GasPool).AddGas(gasLimit[types.QuaiNetworkContext])
+		env.gasPool = new(core.GasPool).AddGas(gasLimit[w.chainConfig.Context])
 	}
 	var coalescedLogs []*types.Log
 
@@ -856,7 +868,7 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 		if interrupt != nil && atomic.LoadInt32(interrupt) != commitInterruptNone {
 			// Notify resubmit loop to increase resubmitting interval due to too frequent commits.
 			if atomic.LoadInt32(interrupt) == commitInterruptResubmit {
-				ratio := float64(gasLimit[types.QuaiNetworkContext]-env.gasPool.Gas()) / float64(gasLimit[types.QuaiNetworkContext])
+				ratio := float64(gasLimit[w.chainConfig.Context]-env.gasPool.Gas()) / float64(gasLimit[w.chainConfig.Context])
 				if ratio < 0.1 {
 					ratio = 0.1
 				}
@@ -884,7 +896,7 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 		from, _ := types.Sender(env.signer, tx)
 		// Check whether the tx is replay protected. If we're not in the EIP155 hf
 		// phase, start ignoring the sender until we do.
-		if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number[types.QuaiNetworkContext]) {
+		if tx.Protected() && !w.chainConfig.IsEIP155(env.header.Number[w.chainConfig.Context]) {
 			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", w.chainConfig.EIP155Block)
 
 			txs.Pop()
@@ -985,7 +997,7 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		timestamp = parent.Time() + 1
 	}
 	// Construct the sealing block header, set the extra field if it's allowed
-	num := parent.Number()
+	num := parent.Number(w.chainConfig.Context)
 	header := &types.Header{
 		ParentHash:        make([]common.Hash, 3),
 		Number:            make([]*big.Int, 3),
@@ -1003,16 +1015,16 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		Bloom:             make([]types.Bloom, 3),
 		Location:          w.chainConfig.Location,
 	}
-	header.ParentHash[types.QuaiNetworkContext] = parent.Hash()
-	header.Number[types.QuaiNetworkContext] = big.NewInt(int64(num.Uint64()) + 1)
-	header.Extra[types.QuaiNetworkContext] = w.extra
-	header.BaseFee[types.QuaiNetworkContext] = misc.CalcBaseFee(w.chainConfig, parent.Header(), w.chain.GetHeaderByNumber, w.chain.GetUnclesInChain, w.chain.GetGasUsedInChain)
+	header.ParentHash[w.chainConfig.Context] = parent.Hash()
+	header.Number[w.chainConfig.Context] = big.NewInt(int64(num.Uint64()) + 1)
+	header.Extra[w.chainConfig.Context] = w.extra
+	header.BaseFee[w.chainConfig.Context] = misc.CalcBaseFee(w.chainConfig, parent.Header(), w.chain.GetHeaderByNumber, w.chain.GetUnclesInChain, w.chain.GetGasUsedInChain)
 	if w.isRunning() {
 		if w.coinbase == (common.Address{}) {
 			log.Error("Refusing to mine without etherbase")
 			return nil, errors.New("refusing to mine without etherbase")
 		}
-		header.Coinbase[types.QuaiNetworkContext] = w.coinbase
+		header.Coinbase[w.chainConfig.Context] = w.coinbase
 	}
 
 	// Run the consensus preparation with the default or customized consensus engine.
@@ -1046,6 +1058,10 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 	return env, nil
 }
 
+// fillExternalTransactions pays the refunds owed by the external blocks of the
+// sealing block and applies the ETxs to this zone, first the ones carried by
+// the external blocks and then the ones pending in the ETx pool since an earlier
+// block. ETxs which fail to apply are left out and stay pending for a retry.
 func (w *worker) fillExternalTransactions(interrupt *int32, env *environment) {
 	// Gather external blocks and apply transactions
 	externalBlocks, extBlockErr := w.engine.GetExternalBlocks(w.chain, env.header, false)
@@ -1055,13 +1071,42 @@ func (w *worker) fillExternalTransactions(interrupt *int32, env *environment) {
 		return
 	}
 
+	// ETxs carrying calldata execute a call, which is paid from the block gas
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit[w.chainConfig.Context])
+	}
 	externalGasUsed := uint64(0)
+	candidates := make([]*types.Transaction, 0)
 	for _, externalBlock := range externalBlocks {
 		externalBlock.Receipts().DeriveFields(w.chainConfig, externalBlock.Hash(), externalBlock.Header().Number[externalBlock.Context().Int64()].Uint64(), externalBlock.Transactions())
 		externalGasUsed += uint64(externalBlock.Header().GasUsed[externalBlock.Context().Uint64()])
-		for _, tx := range externalBlock.Transactions() {
-			w.commitExternalTransaction(env, tx, externalBlock)
+		candidates = append(candidates, externalBlock.Transactions()...)
+
+		// Pay back the ETxs sent from this zone which the external block failed
+		for _, refund := range core.ApplyETxRefunds(w.chainConfig, env.state, env.header, externalBlock) {
+			log.Debug("Refunding external transaction", "hash", refund.Hash, "sender", refund.Sender, "value", refund.Value)
+		}
+	}
+	pending, _ := w.chain.ETxPool().Content()
+	for _, etx := range pending {
+		candidates = append(candidates, etx.Tx)
+	}
+	committed := make(map[common.Hash]bool)
+	for _, tx := range candidates {
+		if committed[tx.Hash()] {
+			continue
+		}
+		externalBlock, err := w.chain.ETxSource(tx, env.header, externalBlocks)
+		if err != nil {
+			continue
+		}
+		env.state.Prepare(tx.Hash(), env.tcount)
+		if _, err := w.commitExternalTransaction(env, tx, externalBlock); err != nil {
+			log.Debug("Leaving out external transaction", "hash", tx.Hash(), "err", err)
+			continue
 		}
+		committed[tx.Hash()] = true
+		env.tcount++
 	}
 	env.externalGasUsed = externalGasUsed
 	env.externalBlockLength = len(externalBlocks)
@@ -1085,13 +1130,13 @@ func (w *worker) fillTransactions(interrupt *int32, env *environment) {
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, localTxs, env.header.BaseFee[types.QuaiNetworkContext])
+		txs := types.NewTransactionsByPriceAndNonce(env.signer, localTxs, env.header.BaseFee[w.chainConfig.Context])
 		if w.commitTransactions(env, txs, interrupt) {
 			return
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(env.signer, remoteTxs, env.header.BaseFee[types.QuaiNetworkContext])
+		txs := types.NewTransactionsByPriceAndNonce(env.signer, remoteTxs, env.header.BaseFee[w.chainConfig.Context])
 		if w.commitTransactions(env, txs, interrupt) {
 			return
 		}
@@ -1105,13 +1150,13 @@ func (w *worker) adjustGasLimit(interrupt *int32, env *environment) {
 	// Find the parent block for sealing task
 	parent := w.chain.CurrentBlock()
 
-	gasUsed := (parent.GasUsed() + env.externalGasUsed) / uint64(env.externalBlockLength+1)
+	gasUsed := (parent.GasUsed(w.chainConfig.Context) + env.externalGasUsed) / uint64(env.externalBlockLength+1)
 
 	// Get the amount of uncles for the past 1000 blocks
-	prevBlock := w.chain.GetBlockByHash(env.header.ParentHash[types.QuaiNetworkContext])
+	prevBlock := w.chain.GetBlockByHash(env.header.ParentHash[w.chainConfig.Context])
 	uncleCount := len(w.chain.GetUnclesInChain(prevBlock, 1000))
 
-	env.header.GasLimit[types.QuaiNetworkContext] = core.CalcGasLimit(parent.GasLimit(), gasUsed, uncleCount)
+	env.header.GasLimit[w.chainConfig.Context] = core.CalcGasLimit(parent.GasLimit(w.chainConfig.Context), gasUsed, uncleCount, w.chainConfig.Context)
 }
 
 // generateWork generates a sealing block based on the given parameters.
@@ -1186,10 +1231,10 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 		}
 		select {
 		case w.taskCh <- &task{receipts: env.receipts, state: env.state, block: block, createdAt: time.Now()}:
-			w.unconfirmed.Shift(block.NumberU64() - 1)
-			log.Info("Commit new sealing work", "number", block.Number(), "sealhash", w.engine.SealHash(block.Header()),
+			w.unconfirmed.Shift(block.NumberU64(w.chainConfig.Context) - 1)
+			log.Info("Commit new sealing work", "number", block.Number(w.chainConfig.Context), "sealhash", w.engine.SealHash(block.Header()),
 				"uncles", len(env.uncles), "txs", env.tcount,
-				"gas", block.GasUsed(), "fees", totalFees(block, env.receipts),
+				"gas", block.GasUsed(w.chainConfig.Context), "fees", totalFees(block, env.receipts, w.chainConfig.Context),
 				"elapsed", common.PrettyDuration(time.Since(start)))
 
 		case <-w.exitCh:
@@ -1248,10 +1293,10 @@ func (w *worker) postSideBlock(event core.ChainSideEvent) {
 }
 
 // totalFees computes total consumed miner fees in ETH. Block transactions and receipts have to have the same order.
-func totalFees(block *types.Block, receipts []*types.Receipt) *big.Float {
+func totalFees(block *types.Block, receipts []*types.Receipt, context int) *big.Float {
 	feesWei := new(big.Int)
 	for i, tx := range block.Transactions() {
-		minerFee, _ := tx.EffectiveGasTip(block.BaseFee())
+		minerFee, _ := tx.EffectiveGasTip(block.BaseFee(context))
 		feesWei.Add(feesWei, new(big.Int).Mul(new(big.Int).SetUint64(receipts[i].GasUsed), minerFee))
 	}
 	return new(big.Float).Quo(new(big.Float).SetInt(feesWei), new(big.Float).SetInt(big.NewInt(params.Ether)))
@@ -151,7 +151,7 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 	}
 	parent := genesis
 	if n > 0 {
-		parent = chain.GetBlockByHash(chain.CurrentBlock().ParentHash())
+		parent = chain.GetBlockByHash(chain.CurrentBlock().ParentHash(types.QuaiNetworkContext))
 	}
 	blocks, _ := core.GenerateChain(chainConfig, parent, engine, db, 1, func(i int, gen *core.BlockGen) {
 		gen.SetCoinbase(testUserAddress)
@@ -175,10 +175,10 @@ func (b *testWorkerBackend) StateAtBlock(block *types.Block, reexec uint64, base
 func (b *testWorkerBackend) newRandomUncle() *types.Block {
 	var parent *types.Block
 	cur := b.chain.CurrentBlock()
-	if cur.NumberU64() == 0 {
+	if cur.NumberU64(types.QuaiNetworkContext) == 0 {
 		parent = b.chain.Genesis()
 	} else {
-		parent = b.chain.GetBlockByHash(b.chain.CurrentBlock().ParentHash())
+		parent = b.chain.GetBlockByHash(b.chain.CurrentBlock().ParentHash(types.QuaiNetworkContext))
 	}
 	blocks, _ := core.GenerateChain(b.chain.Config(), parent, b.chain.Engine(), b.db, 1, func(i int, gen *core.BlockGen) {
 		var addr = make([]byte, common.AddressLength)
@@ -262,7 +262,7 @@ func testGenerateBlockAndImport(t *testing.T, isClique bool) {
 		case ev := <-sub.Chan():
 			block := ev.Data.(core.NewMinedBlockEvent).Block
 			if _, err := chain.InsertChain([]*types.Block{block}); err != nil {
-				t.Fatalf("failed to insert new mined block %d: %v", block.NumberU64(), err)
+				t.Fatalf("failed to insert new mined block %d: %v", block.NumberU64(types.QuaiNetworkContext), err)
 			}
 		case <-time.After(3 * time.Second): // Worker needs 1s to include new changes.
 			t.Fatalf("timeout")
@@ -302,7 +302,7 @@ func testEmptyWork(t *testing.T, chainConfig *params.ChainConfig, engine consens
 		}
 	}
 	w.newTaskHook = func(task *task) {
-		if task.block.NumberU64() == 1 {
+		if task.block.NumberU64(types.QuaiNetworkContext) == 1 {
 			checkEqual(t, task, taskIndex)
 			taskIndex += 1
 			taskCh <- struct{}{}
@@ -333,7 +333,7 @@ func TestStreamUncleBlock(t *testing.T) {
 
 	taskIndex := 0
 	w.newTaskHook = func(task *task) {
-		if task.block.NumberU64() == 2 {
+		if task.block.NumberU64(types.QuaiNetworkContext) == 2 {
 			// The first task is an empty task, the second
 			// one has 1 pending tx, the third one has 1 tx
 			// and 1 uncle.
@@ -391,7 +391,7 @@ func testRegenerateMiningBlock(t *testing.T, chainConfig *params.ChainConfig, en
 
 	taskIndex := 0
 	w.newTaskHook = func(task *task) {
-		if task.block.NumberU64() == 1 {
+		if task.block.NumberU64(types.QuaiNetworkContext) == 1 {
 			// The first task is an empty task, the second
 			// one has 1 pending tx, the third one has 2 txs
 			if taskIndex == 2 {
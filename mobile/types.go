@@ -203,19 +203,19 @@ func (b *Block) String() string {
 	return encodeOrError(b)
 }
 
-func (b *Block) GetParentHash() *Hash   { return &Hash{b.block.ParentHash()} }
-func (b *Block) GetUncleHash() *Hash    { return &Hash{b.block.UncleHash()} }
-func (b *Block) GetCoinbase() *Address  { return &Address{b.block.Coinbase()} }
-func (b *Block) GetRoot() *Hash         { return &Hash{b.block.Root()} }
-func (b *Block) GetTxHash() *Hash       { return &Hash{b.block.TxHash()} }
-func (b *Block) GetReceiptHash() *Hash  { return &Hash{b.block.ReceiptHash()} }
-func (b *Block) GetBloom() *Bloom       { return &Bloom{b.block.Bloom()} }
-func (b *Block) GetDifficulty() *BigInt { return &BigInt{b.block.Difficulty()} }
-func (b *Block) GetNumber() int64       { return b.block.Number().Int64() }
-func (b *Block) GetGasLimit() int64     { return int64(b.block.GasLimit()) }
-func (b *Block) GetGasUsed() int64      { return int64(b.block.GasUsed()) }
+func (b *Block) GetParentHash() *Hash   { return &Hash{b.block.ParentHash(0)} }
+func (b *Block) GetUncleHash() *Hash    { return &Hash{b.block.UncleHash(0)} }
+func (b *Block) GetCoinbase() *Address  { return &Address{b.block.Coinbase(0)} }
+func (b *Block) GetRoot() *Hash         { return &Hash{b.block.Root(0)} }
+func (b *Block) GetTxHash() *Hash       { return &Hash{b.block.TxHash(0)} }
+func (b *Block) GetReceiptHash() *Hash  { return &Hash{b.block.ReceiptHash(0)} }
+func (b *Block) GetBloom() *Bloom       { return &Bloom{b.block.Bloom(0)} }
+func (b *Block) GetDifficulty() *BigInt { return &BigInt{b.block.Difficulty(0)} }
+func (b *Block) GetNumber() int64       { return b.block.Number(0).Int64() }
+func (b *Block) GetGasLimit() int64     { return int64(b.block.GasLimit(0)) }
+func (b *Block) GetGasUsed() int64      { return int64(b.block.GasUsed(0)) }
 func (b *Block) GetTime() int64         { return int64(b.block.Time()) }
-func (b *Block) GetExtra() []byte       { return b.block.Extra() }
+func (b *Block) GetExtra() []byte       { return b.block.Extra(0) }
 
 //func (b *Block) GetMixDigest() *Hash            { return &Hash{b.block.MixDigest()} }
 func (b *Block) GetNonce() int64                { return int64(b.block.Nonce()) }
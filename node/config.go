@@ -18,6 +18,7 @@ package node
 
 import (
 	"crypto/ecdsa"
+	crand "crypto/rand"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -27,6 +28,7 @@ import (
 	"sync"
 
 	"github.com/spruce-solutions/go-quai/common"
+	"github.com/spruce-solutions/go-quai/common/hexutil"
 	"github.com/spruce-solutions/go-quai/crypto"
 	"github.com/spruce-solutions/go-quai/log"
 	"github.com/spruce-solutions/go-quai/p2p"
@@ -40,6 +42,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirJWTSecret       = "jwtsecret"          // Path within the datadir to the secret of the authenticated RPC endpoint
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -167,6 +170,26 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// AuthAddr is the host interface on which to start the authenticated RPC
+	// server. It serves the APIs used by the dominant and subordinate chains
+	// over both HTTP and websocket. If this field is empty, no authenticated
+	// endpoint will be started.
+	AuthAddr string `toml:",omitempty"`
+
+	// AuthPort is the TCP port number on which to start the authenticated RPC
+	// server.
+	AuthPort int `toml:",omitempty"`
+
+	// AuthVirtualHosts is the list of virtual hostnames which are allowed on
+	// incoming requests to the authenticated RPC server.
+	AuthVirtualHosts []string `toml:",omitempty"`
+
+	// JWTSecret is the path to the hex encoded secret the authenticated RPC server
+	// verifies tokens with and the links to other chains sign them with. Every
+	// node of a hierarchy must share the same secret. If empty, a secret is
+	// generated in the data directory.
+	JWTSecret string `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -381,6 +404,60 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	return key
 }
 
+// AuthSecret returns the secret of the authenticated RPC endpoint. It is read
+// from the configured file, or generated and stored in it if the file doesn't
+// exist yet.
+func (c *Config) AuthSecret() ([]byte, error) {
+	file := c.JWTSecret
+	if file == "" {
+		// Generate an ephemeral secret if no datadir is being used.
+		if c.DataDir == "" {
+			return generateAuthSecret()
+		}
+		file = c.ResolvePath(datadirJWTSecret)
+	}
+	if secret, err := ReadAuthSecret(file); !os.IsNotExist(err) {
+		return secret, err
+	}
+	// No secret found, generate and store a new one.
+	secret, err := generateAuthSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(file, []byte(hexutil.Encode(secret)), 0600); err != nil {
+		return nil, err
+	}
+	log.Info("Generated JWT secret", "path", file)
+	return secret, nil
+}
+
+// ReadAuthSecret reads the hex encoded secret of the authenticated RPC endpoint
+// from the file.
+func ReadAuthSecret(file string) ([]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	secret := common.FromHex(strings.TrimSpace(string(data)))
+	if len(secret) != rpc.JWTSecretLength {
+		return nil, fmt.Errorf("invalid JWT secret in %s: need %d bytes, have %d", file, rpc.JWTSecretLength, len(secret))
+	}
+	return secret, nil
+}
+
+// generateAuthSecret returns a new random secret for the authenticated RPC
+// endpoint.
+func generateAuthSecret() ([]byte, error) {
+	secret := make([]byte, rpc.JWTSecretLength)
+	if _, err := crand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
 // StaticNodes returns a list of node enode URLs configured as static nodes.
 func (c *Config) StaticNodes() []*enode.Node {
 	return c.parsePersistentNodes(&c.staticNodesWarning, c.ResolvePath(datadirStaticNodes))
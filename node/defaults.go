@@ -34,8 +34,15 @@ const (
 	DefaultWSPort      = 8546        // Default TCP port for the websocket RPC server
 	DefaultGraphQLHost = "localhost" // Default host interface for the GraphQL server
 	DefaultGraphQLPort = 8547        // Default TCP port for the GraphQL server
+	DefaultAuthHost    = "localhost" // Default host interface for the authenticated RPC server
+	DefaultAuthPort    = 8551        // Default TCP port for the authenticated RPC server
 )
 
+// DefaultAuthModules are the API modules served on the authenticated endpoint
+// next to the authenticated ones, the dominant and subordinate chains rely on
+// them as well.
+var DefaultAuthModules = []string{"eth", "quai", "net", "web3"}
+
 // DefaultConfig contains reasonable default settings.
 var DefaultConfig = Config{
 	DataDir:             DefaultDataDir(),
@@ -45,6 +52,9 @@ var DefaultConfig = Config{
 	HTTPTimeouts:        rpc.DefaultHTTPTimeouts,
 	WSPort:              DefaultWSPort,
 	WSModules:           []string{"net", "web3"},
+	AuthAddr:            DefaultAuthHost,
+	AuthPort:            DefaultAuthPort,
+	AuthVirtualHosts:    []string{"localhost"},
 	GraphQLVirtualHosts: []string{"localhost"},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
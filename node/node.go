@@ -55,6 +55,8 @@ type Node struct {
 	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
 	http          *httpServer //
 	ws            *httpServer //
+	httpAuth      *httpServer // Authenticated RPC server serving both HTTP and websocket
+	authSecret    []byte      // Secret tokens of the authenticated RPC server are signed with
 	ipc           *ipcServer  // Stores information about the ipc http server
 	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
 
@@ -119,6 +121,9 @@ func New(conf *Config) (*Node, error) {
 	}
 	node.keyDir = keyDir
 	node.keyDirTemp = isEphem
+	if node.authSecret, err = conf.AuthSecret(); err != nil {
+		return nil, err
+	}
 	// Creates an empty AccountManager with no backends. Callers (e.g. cmd/geth)
 	// are required to add the backends later on.
 	node.accman = accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: conf.InsecureUnlockAllowed})
@@ -148,6 +153,7 @@ func New(conf *Config) (*Node, error) {
 	// Configure RPC servers.
 	node.http = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
+	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
 
 	return node, nil
@@ -349,6 +355,7 @@ func (n *Node) startRPC() error {
 			return err
 		}
 	}
+	openAPIs, allAPIs := n.getAPIs()
 
 	// Configure HTTP.
 	if n.config.HTTPHost != "" {
@@ -361,7 +368,7 @@ func (n *Node) startRPC() error {
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
 			return err
 		}
-		if err := n.http.enableRPC(n.rpcAPIs, config); err != nil {
+		if err := n.http.enableRPC(openAPIs, config); err != nil {
 			return err
 		}
 	}
@@ -377,7 +384,35 @@ func (n *Node) startRPC() error {
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
 		}
-		if err := server.enableWS(n.rpcAPIs, config); err != nil {
+		if err := server.enableWS(openAPIs, config); err != nil {
+			return err
+		}
+	}
+
+	// Configure the authenticated endpoint.
+	if n.config.AuthAddr != "" {
+		modules := append([]string{}, DefaultAuthModules...)
+		for _, api := range allAPIs {
+			if api.Authenticated {
+				modules = append(modules, api.Namespace)
+			}
+		}
+		if err := n.httpAuth.setListenAddr(n.config.AuthAddr, n.config.AuthPort); err != nil {
+			return err
+		}
+		httpCfg := httpConfig{
+			Modules:   modules,
+			Vhosts:    n.config.AuthVirtualHosts,
+			jwtSecret: n.authSecret,
+		}
+		if err := n.httpAuth.enableRPC(allAPIs, httpCfg); err != nil {
+			return err
+		}
+		wsCfg := wsConfig{
+			Modules:   modules,
+			jwtSecret: n.authSecret,
+		}
+		if err := n.httpAuth.enableWS(allAPIs, wsCfg); err != nil {
 			return err
 		}
 	}
@@ -385,7 +420,21 @@ func (n *Node) startRPC() error {
 	if err := n.http.start(); err != nil {
 		return err
 	}
-	return n.ws.start()
+	if err := n.ws.start(); err != nil {
+		return err
+	}
+	return n.httpAuth.start()
+}
+
+// getAPIs returns the APIs served on the public endpoints, which exclude the
+// authenticated ones, and all APIs of the node.
+func (n *Node) getAPIs() (open, all []rpc.API) {
+	for _, api := range n.rpcAPIs {
+		if !api.Authenticated {
+			open = append(open, api)
+		}
+	}
+	return open, n.rpcAPIs
 }
 
 func (n *Node) wsServerForPort(port int) *httpServer {
@@ -398,6 +447,7 @@ func (n *Node) wsServerForPort(port int) *httpServer {
 func (n *Node) stopRPC() {
 	n.http.stop()
 	n.ws.stop()
+	n.httpAuth.stop()
 	n.ipc.stop()
 	n.stopInProc()
 }
@@ -545,6 +595,18 @@ func (n *Node) WSEndpoint() string {
 	return "ws://" + n.ws.listenAddr() + n.ws.wsConfig.prefix
 }
 
+// AuthEndpoint returns the current authenticated JSON-RPC endpoint, serving
+// both HTTP and websocket.
+func (n *Node) AuthEndpoint() string {
+	return n.httpAuth.listenAddr()
+}
+
+// AuthSecret returns the secret tokens of the authenticated endpoint are signed
+// with. Links to the dominant and subordinate chains authenticate with it.
+func (n *Node) AuthSecret() []byte {
+	return n.authSecret
+}
+
 // EventMux retrieves the event multiplexer used by all the network services in
 // the current protocol stack.
 func (n *Node) EventMux() *event.TypeMux {
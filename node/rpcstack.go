@@ -40,13 +40,15 @@ type httpConfig struct {
 	CorsAllowedOrigins []string
 	Vhosts             []string
 	prefix             string // path prefix on which to mount http handler
+	jwtSecret          []byte // optional JWT secret requests must be authenticated with
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins []string
-	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Origins   []string
+	Modules   []string
+	prefix    string // path prefix on which to mount ws handler
+	jwtSecret []byte // optional JWT secret handshakes must be authenticated with
 }
 
 type rpcHandler struct {
@@ -284,8 +286,12 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 		return err
 	}
 	h.httpConfig = config
+	handler := NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts)
+	if config.jwtSecret != nil {
+		handler = newJWTHandler(config.jwtSecret, handler)
+	}
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts),
+		Handler: handler,
 		server:  srv,
 	})
 	return nil
@@ -316,8 +322,12 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 		return err
 	}
 	h.wsConfig = config
+	handler := srv.WebsocketHandler(config.Origins)
+	if config.jwtSecret != nil {
+		handler = newJWTHandler(config.jwtSecret, handler)
+	}
 	h.wsHandler.Store(&rpcHandler{
-		Handler: srv.WebsocketHandler(config.Origins),
+		Handler: handler,
 		server:  srv,
 	})
 	return nil
@@ -383,6 +393,26 @@ func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	return c.Handler(srv)
 }
 
+// jwtHandler is a handler which only passes on requests carrying a bearer token
+// signed with the shared secret.
+type jwtHandler struct {
+	secret []byte
+	next   http.Handler
+}
+
+func newJWTHandler(secret []byte, next http.Handler) http.Handler {
+	return &jwtHandler{secret: secret, next: next}
+}
+
+// ServeHTTP serves JSON-RPC requests over HTTP, implements http.Handler
+func (h *jwtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := rpc.VerifyJWTRequest(h.secret, r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
 // virtualHostHandler is a handler which validates the Host-header of incoming requests.
 // Using virtual hosts can help prevent DNS rebinding attacks, where a 'random' domain name points to
 // the service ip address (but without CORS headers). By verifying the targeted virtual host, we can
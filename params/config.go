@@ -53,10 +53,6 @@ var CheckpointOracles = map[common.Hash]*CheckpointOracleConfig{
 	MainnetPrimeGenesisHash: MainnetCheckpointOracle,
 	RopstenGenesisHash:      RopstenCheckpointOracle,
 }
-var mainnetValidChains = []*big.Int{big.NewInt(9000), big.NewInt(9100), big.NewInt(9101), big.NewInt(9102), big.NewInt(9103), big.NewInt(9200), big.NewInt(9201), big.NewInt(9202), big.NewInt(9203), big.NewInt(9300), big.NewInt(9301), big.NewInt(9302), big.NewInt(9303)}
-
-var testnetValidChains = []*big.Int{big.NewInt(12000), big.NewInt(12100), big.NewInt(12101), big.NewInt(12102), big.NewInt(12103), big.NewInt(12200), big.NewInt(12201), big.NewInt(12202), big.NewInt(12203), big.NewInt(12300), big.NewInt(12301), big.NewInt(12302), big.NewInt(12303)}
-
 var (
 	// MainnetPrimeChainConfig is the chain parameters to run a node on the main network.
 	MainnetPrimeChainConfig = &ChainConfig{
@@ -701,7 +697,7 @@ var (
 		GenesisHashes:       nil,
 		FullerMapContext:    big.NewInt(0)}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), 0, []byte{0, 0}, big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, big.NewInt(0)}
+	TestChainConfig = &ChainConfig{big.NewInt(1), 0, []byte{0, 0}, big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, big.NewInt(0), nil, nil, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -790,7 +786,35 @@ type ChainConfig struct {
 	GenesisHashes []common.Hash
 
 	// Quai Network Ontology
-	FullerMapContext *big.Int // Block number effective for Fuller Map Context ontology
+	FullerMapContext *big.Int  // Block number effective for Fuller Map Context ontology
+	Ontology         *Ontology `json:"ontology,omitempty"` // Shape of the network, nil for the registered one of the chain ID
+
+	// Scheduled expansions of the ontology, ordered by prime block number
+	OntologyForks []*OntologyFork `json:"ontologyForks,omitempty"`
+
+	// Difficulty adjustment algorithm of each context, indexed by context. A
+	// missing or empty entry selects the frontier algorithm.
+	DifficultyAlgorithms []string `json:"difficultyAlgorithms,omitempty"`
+
+	// Block reward schedule, nil for the default rewards without halving
+	EmissionBlock *big.Int  `json:"emissionBlock,omitempty"` // Emission schedule switch block (nil = no fork, 0 = already on the schedule)
+	Emission      *Emission `json:"emission,omitempty"`
+}
+
+// Difficulty adjustment algorithms selectable per context.
+const (
+	FrontierDifficulty = "frontier" // Frontier rules, including the difficulty bomb
+	NoBombDifficulty   = "nobomb"   // Frontier rules without the difficulty bomb
+	EMADifficulty      = "ema"      // Exponential moving average of the block times
+)
+
+// DifficultyAlgorithm returns the name of the difficulty adjustment algorithm
+// used by the given context.
+func (c *ChainConfig) DifficultyAlgorithm(context int) string {
+	if context < 0 || context >= len(c.DifficultyAlgorithms) || c.DifficultyAlgorithms[context] == "" {
+		return FrontierDifficulty
+	}
+	return c.DifficultyAlgorithms[context]
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -904,6 +928,12 @@ func (c *ChainConfig) IsCatalyst(num *big.Int) bool {
 	return isForked(c.CatalystBlock, num)
 }
 
+// IsEmission returns whether num is either equal to the emission schedule fork
+// block or greater.
+func (c *ChainConfig) IsEmission(num *big.Int) bool {
+	return isForked(c.EmissionBlock, num)
+}
+
 // IsFuller returns whether num is either equal to the Merge fork block or greater.
 func (c *ChainConfig) IsFuller(num *big.Int) bool {
 	return isForked(c.FullerMapContext, num)
@@ -968,6 +998,47 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	if c.Ontology != nil {
+		if err := c.Ontology.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(c.OntologyForks) > 0 {
+		ontology := c.NetworkOntology()
+		if ontology == nil {
+			return errOntologyMissing
+		}
+		last := c.FullerMapContext
+		for i, fork := range c.OntologyForks {
+			if fork.Block == nil || (last != nil && fork.Block.Cmp(last) <= 0) {
+				return fmt.Errorf("unsupported ontology fork ordering: fork %d at %v", i, fork.Block)
+			}
+			last = fork.Block
+		}
+		if len(c.ontologySchedule()) != len(c.OntologyForks)+1 {
+			return fmt.Errorf("invalid ontology fork: %v", errOntologyShrink)
+		}
+	}
+	if c.Ontology != nil || len(c.OntologyForks) > 0 {
+		if c.ChainID != nil && !c.NetworkOntology().Contains(c.ChainID) {
+			return fmt.Errorf("chain ID %v outside of the configured ontology", c.ChainID)
+		}
+	}
+	for context := range c.DifficultyAlgorithms {
+		switch algorithm := c.DifficultyAlgorithm(context); algorithm {
+		case FrontierDifficulty, NoBombDifficulty, EMADifficulty:
+		default:
+			return fmt.Errorf("unsupported difficulty algorithm %q for context %d", algorithm, context)
+		}
+	}
+	if (c.Emission == nil) != (c.EmissionBlock == nil) {
+		return fmt.Errorf("invalid emission schedule: %w", errEmissionFork)
+	}
+	if c.Emission != nil {
+		if err := c.Emission.validate(c); err != nil {
+			return fmt.Errorf("invalid emission schedule: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -1015,6 +1086,18 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.FullerMapContext, newcfg.FullerMapContext, head) {
 		return newCompatError("Fuller ontology block", c.FullerMapContext, newcfg.FullerMapContext)
 	}
+	if isForkIncompatible(c.EmissionBlock, newcfg.EmissionBlock, head) {
+		return newCompatError("Emission fork block", c.EmissionBlock, newcfg.EmissionBlock)
+	}
+	// The difficulty algorithms have no fork block, every block past genesis
+	// was verified with the stored ones.
+	if isForked(common.Big1, head) {
+		for context := PRIME; context <= ZONE; context++ {
+			if c.DifficultyAlgorithm(context) != newcfg.DifficultyAlgorithm(context) {
+				return newCompatError("difficulty algorithm", common.Big0, common.Big0)
+			}
+		}
+	}
 	return nil
 }
 
@@ -1109,132 +1192,152 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	}
 }
 
-// bytePrefixList expands the space with proper size for chain IDs to be indexed
-var (
-	mainnetBytePrefixList = make([][]int, 20000)
-	testnetBytePrefixList = make([][]int, 20000)
-)
-
-func init() {
-	mainnetBytePrefixList[9000] = []int{0, 9}
-	mainnetBytePrefixList[9100] = []int{10, 19}
-	mainnetBytePrefixList[9101] = []int{20, 29}
-	mainnetBytePrefixList[9102] = []int{30, 39}
-	mainnetBytePrefixList[9103] = []int{40, 49}
-	mainnetBytePrefixList[9200] = []int{50, 59}
-	mainnetBytePrefixList[9201] = []int{60, 69}
-	mainnetBytePrefixList[9202] = []int{70, 79}
-	mainnetBytePrefixList[9203] = []int{80, 89}
-	mainnetBytePrefixList[9300] = []int{90, 99}
-	mainnetBytePrefixList[9301] = []int{100, 109}
-	mainnetBytePrefixList[9302] = []int{110, 119}
-	mainnetBytePrefixList[9303] = []int{120, 129}
-
-	testnetBytePrefixList[12000] = []int{0, 9}
-	testnetBytePrefixList[12100] = []int{10, 19}
-	testnetBytePrefixList[12101] = []int{20, 29}
-	testnetBytePrefixList[12102] = []int{30, 39}
-	testnetBytePrefixList[12103] = []int{40, 49}
-	testnetBytePrefixList[12200] = []int{50, 59}
-	testnetBytePrefixList[12201] = []int{60, 69}
-	testnetBytePrefixList[12202] = []int{70, 79}
-	testnetBytePrefixList[12203] = []int{80, 89}
-	testnetBytePrefixList[12300] = []int{90, 99}
-	testnetBytePrefixList[12301] = []int{100, 109}
-	testnetBytePrefixList[12302] = []int{110, 119}
-	testnetBytePrefixList[12303] = []int{120, 129}
+// NetworkOntology returns the ontology of the network after all scheduled
+// expansions. As expansions keep the address prefixes of existing chains, it
+// allocates the prefixes of every chain the network ever has.
+func (c *ChainConfig) NetworkOntology() *Ontology {
+	schedule := c.ontologySchedule()
+	if len(schedule) == 0 {
+		return nil
+	}
+	return schedule[len(schedule)-1]
 }
 
-// ChainIDRange returns the byte lookup based off a configs chainID
-func (c *ChainConfig) ChainIDRange() []int {
-	for _, inSet := range mainnetValidChains {
-		if c.ChainID.Cmp(inSet) == 0 {
-			lookup := mainnetBytePrefixList[c.ChainID.Int64()]
-			return lookup
+// OntologyAt returns the ontology effective at the given prime block number.
+func (c *ChainConfig) OntologyAt(primeNumber *big.Int) *Ontology {
+	schedule := c.ontologySchedule()
+	if len(schedule) == 0 {
+		return nil
+	}
+	ontology := schedule[0]
+	for i, fork := range c.OntologyForks {
+		if i+1 < len(schedule) && isForked(fork.Block, primeNumber) {
+			ontology = schedule[i+1]
 		}
 	}
+	return ontology
+}
 
-	for _, inSet := range testnetValidChains {
-		if c.ChainID.Cmp(inSet) == 0 {
-			lookup := testnetBytePrefixList[c.ChainID.Int64()]
-			return lookup
+// OntologyForkBlock returns the prime block number of the ontology fork which
+// creates the chain, or nil if the chain is part of the initial ontology.
+func (c *ChainConfig) OntologyForkBlock() *big.Int {
+	schedule := c.ontologySchedule()
+	if len(schedule) == 0 || schedule[0].Contains(c.ChainID) {
+		return nil
+	}
+	for i, fork := range c.OntologyForks {
+		if i+1 < len(schedule) && schedule[i+1].Contains(c.ChainID) {
+			return fork.Block
 		}
 	}
 	return nil
 }
 
-// LookupChainIDRange returns the byte lookup based off a configs chainID
-func LookupChainIDRange(index *big.Int) []int {
-	for _, inSet := range mainnetValidChains {
-		if index.Cmp(inSet) == 0 {
-			lookup := mainnetBytePrefixList[index.Int64()]
-			return lookup
-		}
+// ForkedChainConfig derives the configuration of the chain at the given location
+// from the configuration of another chain of the network. Unlike the configs
+// derived by Ontology.ChainConfig, the location may belong to a chain created
+// by a scheduled ontology fork.
+func (c *ChainConfig) ForkedChainConfig(location []byte) (*ChainConfig, error) {
+	schedule := c.ontologySchedule()
+	if len(schedule) == 0 {
+		return nil, errOntologyMissing
+	}
+	config, err := schedule[len(schedule)-1].ChainConfig(c, location)
+	if err != nil {
+		return nil, err
 	}
+	// Expansions are replayed from the initial ontology of the network
+	config.Ontology = schedule[0]
+	return config, nil
+}
 
-	for _, inSet := range testnetValidChains {
-		if index.Cmp(inSet) == 0 {
-			lookup := testnetBytePrefixList[index.Int64()]
-			return lookup
+// ontologySchedule returns the initial ontology of the network followed by the
+// ontology of every scheduled expansion.
+func (c *ChainConfig) ontologySchedule() []*Ontology {
+	// Expansions start from the configured or built-in ontology, the registered
+	// one may already be expanded. Chains created by an expansion of a built-in
+	// network therefore need to configure its initial ontology.
+	ontology := c.Ontology
+	if ontology == nil && len(c.OntologyForks) > 0 {
+		ontology = builtinOntology(c.ChainID)
+	} else if ontology == nil {
+		ontology = LookupOntology(c.ChainID)
+	}
+	if ontology == nil {
+		return nil
+	}
+	schedule := []*Ontology{ontology}
+	for _, fork := range c.OntologyForks {
+		expanded, err := ontology.Expand(fork.Regions, fork.Zones)
+		if err != nil {
+			break
 		}
+		schedule = append(schedule, expanded)
+		ontology = expanded
 	}
-	return nil
+	return schedule
 }
 
-// ValidChainID takes in a chain ID and checks against the valid list
-func ValidChainID(id *big.Int, chainId *big.Int) bool {
-	for _, inSet := range mainnetValidChains {
-		if chainId.Cmp(inSet) == 0 {
-			for _, valid := range mainnetValidChains {
-				if id.Cmp(valid) == 0 {
-					return true
-				}
-			}
-		}
+// OntologyShape returns the number of regions and zones per region of the
+// network, defaulting to the Fuller ontology.
+func (c *ChainConfig) OntologyShape() []int {
+	if o := c.NetworkOntology(); o != nil {
+		return o.Shape()
 	}
+	return FullerOntology
+}
 
-	for _, inSet := range testnetValidChains {
-		if chainId.Cmp(inSet) == 0 {
-			for _, valid := range testnetValidChains {
-				if id.Cmp(valid) == 0 {
-					return true
-				}
-			}
-		}
+// ChainIDRange returns the byte lookup based off a configs chainID
+func (c *ChainConfig) ChainIDRange() []int {
+	if o := c.NetworkOntology(); o != nil {
+		return o.PrefixRange(c.ChainID)
 	}
-	return false
+	return nil
 }
 
-// CheckETxChainID takes in a chain ID and checks against the valid list
-func CheckETxChainID(ourID *big.Int, txID *big.Int) bool {
-	for _, inSet := range mainnetValidChains {
-		if ourID.Cmp(inSet) == 0 {
-			for _, valid := range mainnetValidChains {
-				if txID.Cmp(valid) == 0 {
-					return true
-				}
-			}
-		}
+// LookupChainIDRange returns the byte lookup based off a configs chainID
+func LookupChainIDRange(index *big.Int) []int {
+	if o := LookupOntology(index); o != nil {
+		return o.PrefixRange(index)
 	}
+	return nil
+}
 
-	for _, inSet := range testnetValidChains {
-		if ourID.Cmp(inSet) == 0 {
-			for _, valid := range testnetValidChains {
-				if txID.Cmp(valid) == 0 {
-					return true
-				}
-			}
-		}
+// ValidChainID reports whether a transaction with the chain ID id may be
+// handled by the chain with the given chain ID, as it is the chain itself or a
+// chain of the registered ontology of its network. Without a block number the
+// ontology is the one after every scheduled expansion.
+func ValidChainID(id *big.Int, chainId *big.Int) bool {
+	if id.Cmp(chainId) == 0 {
+		return true
 	}
-	return false
+	o := LookupOntology(chainId)
+	return o != nil && o.Contains(id)
 }
 
-// CurrentOntology is used to retrieve the MapContext of a given block.
+// ValidChainID reports whether the chain ID belongs to a chain of the network at
+// the given prime block number.
+func (c *ChainConfig) ValidChainID(id *big.Int, primeNumber *big.Int) bool {
+	o := c.OntologyAt(primeNumber)
+	return o != nil && o.Contains(id)
+}
+
+// CheckETxChainID reports whether an ETx with the given chain ID comes from a
+// chain of the network at the given prime block number.
+func (c *ChainConfig) CheckETxChainID(txID *big.Int, primeNumber *big.Int) bool {
+	return c.ValidChainID(txID, primeNumber)
+}
+
+// CurrentOntology is used to retrieve the MapContext of a given block, taking
+// scheduled ontology forks at the prime number of the block into account.
 func (c *ChainConfig) CurrentOntology(number []*big.Int) ([]int, error) {
 	forkNumber := number[0]
 
 	switch {
-	case forkNumber.Cmp(c.FullerMapContext) >= 0: // Fuller = 0
+	case c.IsFuller(forkNumber): // Fuller = 0
+		if o := c.OntologyAt(forkNumber); o != nil {
+			return o.Shape(), nil
+		}
 		return FullerOntology, nil
 	default:
 		return nil, errors.New("invalid block number passed to ontology")
@@ -87,6 +87,29 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     30,
 			},
 		},
+		{
+			stored:  &ChainConfig{},
+			new:     &ChainConfig{DifficultyAlgorithms: []string{"", "", EMADifficulty}},
+			head:    0,
+			wantErr: nil,
+		},
+		{
+			stored:  &ChainConfig{},
+			new:     &ChainConfig{DifficultyAlgorithms: []string{FrontierDifficulty}},
+			head:    10,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{DifficultyAlgorithms: []string{"", "", NoBombDifficulty}},
+			new:    &ChainConfig{DifficultyAlgorithms: []string{"", "", EMADifficulty}},
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "difficulty algorithm",
+				StoredConfig: big.NewInt(0),
+				NewConfig:    big.NewInt(0),
+				RewindTo:     0,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -96,3 +119,28 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestDifficultyAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithms []string
+		context    int
+		want       string
+		wantErr    bool
+	}{
+		{nil, ZONE, FrontierDifficulty, false},
+		{[]string{"", NoBombDifficulty}, PRIME, FrontierDifficulty, false},
+		{[]string{"", NoBombDifficulty}, REGION, NoBombDifficulty, false},
+		{[]string{"", NoBombDifficulty}, ZONE, FrontierDifficulty, false},
+		{[]string{EMADifficulty, EMADifficulty, EMADifficulty}, ZONE, EMADifficulty, false},
+		{[]string{"", "", "bogus"}, ZONE, "bogus", true},
+	}
+	for i, test := range tests {
+		config := &ChainConfig{DifficultyAlgorithms: test.algorithms}
+		if have := config.DifficultyAlgorithm(test.context); have != test.want {
+			t.Errorf("test %d: algorithm mismatch: have %q, want %q", i, have, test.want)
+		}
+		if err := config.CheckConfigForkOrder(); (err != nil) != test.wantErr {
+			t.Errorf("test %d: error mismatch: have %v, want error %v", i, err, test.wantErr)
+		}
+	}
+}
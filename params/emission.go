@@ -0,0 +1,110 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/spruce-solutions/go-quai/common"
+)
+
+// MaxTreasuryShare is the share of a block reward, in basis points, that
+// corresponds to the whole reward.
+const MaxTreasuryShare = 10000
+
+// Emission describes the block reward schedule of a network, in effect from the
+// emission fork block of the chain config on. A block is paid the reward of the
+// difficulty order it satisfies. Rewards halve every interval of blocks of the
+// paying chain counted from the fork. Rewards are indexed by order, the other
+// entries by context. A missing or nil reward keeps the default reward of the
+// order. The treasury of a context must be an account of the chain paying it.
+type Emission struct {
+	Rewards          []*big.Int       `json:"rewards,omitempty"`          // Base reward of a block of each order
+	HalvingIntervals []uint64         `json:"halvingIntervals,omitempty"` // Number of blocks between halvings of each context, 0 for never
+	Treasuries       []common.Address `json:"treasuries,omitempty"`       // Account receiving the treasury share in each context
+	TreasuryShare    uint64           `json:"treasuryShare,omitempty"`    // Share of each block reward paid to the treasury, in basis points
+}
+
+var (
+	errEmissionContexts = errors.New("emission schedule has more entries than contexts")
+	errEmissionReward   = errors.New("emission schedule has a negative reward")
+	errTreasuryShare    = errors.New("treasury share exceeds the block reward")
+	errTreasuryMissing  = errors.New("treasury share has no treasury account")
+	errTreasuryRange    = errors.New("treasury account outside of the chain prefix range")
+	errEmissionFork     = errors.New("emission schedule and emission block must be set together")
+)
+
+// validate checks that the emission schedule is well formed, and that the
+// treasury of the context of the config belongs to the chain.
+func (e *Emission) validate(config *ChainConfig) error {
+	contexts := ZONE + 1
+	if len(e.Rewards) > contexts || len(e.HalvingIntervals) > contexts || len(e.Treasuries) > contexts {
+		return errEmissionContexts
+	}
+	for _, reward := range e.Rewards {
+		if reward != nil && reward.Sign() < 0 {
+			return errEmissionReward
+		}
+	}
+	if e.TreasuryShare > MaxTreasuryShare {
+		return errTreasuryShare
+	}
+	if e.TreasuryShare > 0 {
+		for context := 0; context < contexts; context++ {
+			if context >= len(e.Treasuries) || e.Treasuries[context] == (common.Address{}) {
+				return fmt.Errorf("%w in context %d", errTreasuryMissing, context)
+			}
+		}
+		if prefixes := config.ChainIDRange(); prefixes != nil {
+			treasury := e.Treasuries[config.Context]
+			if prefix := int(treasury[0]); prefix < prefixes[0] || prefix > prefixes[1] {
+				return fmt.Errorf("%w: %x in context %d", errTreasuryRange, treasury, config.Context)
+			}
+		}
+	}
+	return nil
+}
+
+// EmissionReward returns the configured base reward of a block of the given
+// difficulty order, or nil if the order pays the default reward.
+func (c *ChainConfig) EmissionReward(order int) *big.Int {
+	if c.Emission == nil || order < 0 || order >= len(c.Emission.Rewards) {
+		return nil
+	}
+	return c.Emission.Rewards[order]
+}
+
+// HalvingInterval returns the number of blocks of the given context between
+// two halvings of its block reward, 0 if the reward never halves.
+func (c *ChainConfig) HalvingInterval(context int) uint64 {
+	if c.Emission == nil || context < 0 || context >= len(c.Emission.HalvingIntervals) {
+		return 0
+	}
+	return c.Emission.HalvingIntervals[context]
+}
+
+// Treasury returns the treasury account of the given context and the share of
+// the block rewards paid to it in basis points. The share is 0 if the network
+// has no treasury.
+func (c *ChainConfig) Treasury(context int) (common.Address, uint64) {
+	if c.Emission == nil || context < 0 || context >= len(c.Emission.Treasuries) {
+		return common.Address{}, 0
+	}
+	return c.Emission.Treasuries[context], c.Emission.TreasuryShare
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+)
+
+func TestEmissionValidation(t *testing.T) {
+	treasuries := []common.Address{{0x01}, {0x02}, {0x03}}
+	tests := []struct {
+		emission *Emission
+		err      error
+	}{
+		{&Emission{}, nil},
+		{&Emission{Rewards: []*big.Int{nil, big.NewInt(1)}, HalvingIntervals: []uint64{0, 0, 1000}}, nil},
+		{&Emission{Rewards: []*big.Int{big.NewInt(-1)}}, errEmissionReward},
+		{&Emission{Rewards: make([]*big.Int, 4)}, errEmissionContexts},
+		{&Emission{HalvingIntervals: make([]uint64, 4)}, errEmissionContexts},
+		{&Emission{Treasuries: treasuries, TreasuryShare: 500}, nil},
+		{&Emission{Treasuries: treasuries, TreasuryShare: MaxTreasuryShare + 1}, errTreasuryShare},
+		{&Emission{Treasuries: treasuries[:2], TreasuryShare: 500}, errTreasuryMissing},
+		{&Emission{Treasuries: []common.Address{{0x01}, {}, {0x03}}, TreasuryShare: 500}, errTreasuryMissing},
+	}
+	for i, test := range tests {
+		config := &ChainConfig{EmissionBlock: big.NewInt(0), Emission: test.emission}
+		if err := config.CheckConfigForkOrder(); !errors.Is(err, test.err) {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.err)
+		}
+	}
+	// The schedule and its fork block go together
+	if err := (&ChainConfig{Emission: &Emission{}}).CheckConfigForkOrder(); !errors.Is(err, errEmissionFork) {
+		t.Errorf("schedule without fork: error mismatch: have %v, want %v", err, errEmissionFork)
+	}
+	if err := (&ChainConfig{EmissionBlock: big.NewInt(0)}).CheckConfigForkOrder(); !errors.Is(err, errEmissionFork) {
+		t.Errorf("fork without schedule: error mismatch: have %v, want %v", err, errEmissionFork)
+	}
+}
+
+func TestEmissionTreasuryRange(t *testing.T) {
+	location := []byte{1, 2}
+	prefixes, err := MainnetOntology.LocationPrefixRange(location)
+	if err != nil {
+		t.Fatalf("failed to look up prefix range: %v", err)
+	}
+	for _, test := range []struct {
+		prefix int
+		err    error
+	}{
+		{prefixes[0], nil},
+		{prefixes[1], nil},
+		{prefixes[0] - 1, errTreasuryRange},
+		{prefixes[1] + 1, errTreasuryRange},
+	} {
+		template := *MainnetPrimeChainConfig
+		template.EmissionBlock = big.NewInt(0)
+		template.Emission = &Emission{
+			Treasuries:    []common.Address{{0x01}, {0x02}, {byte(test.prefix)}},
+			TreasuryShare: 500,
+		}
+		config, err := MainnetOntology.ChainConfig(&template, location)
+		if err != nil {
+			t.Fatalf("failed to derive chain config: %v", err)
+		}
+		if err := config.CheckConfigForkOrder(); !errors.Is(err, test.err) {
+			t.Errorf("treasury prefix %#x: error mismatch: have %v, want %v", test.prefix, err, test.err)
+		}
+	}
+}
+
+func TestEmissionAccessors(t *testing.T) {
+	config := &ChainConfig{}
+	if reward := config.EmissionReward(ZONE); reward != nil {
+		t.Errorf("unexpected reward without emission schedule: %v", reward)
+	}
+	if _, share := config.Treasury(ZONE); share != 0 {
+		t.Errorf("unexpected treasury share without emission schedule: %d", share)
+	}
+	if config.IsEmission(big.NewInt(100)) {
+		t.Errorf("emission schedule active without fork")
+	}
+	config.EmissionBlock = big.NewInt(100)
+	if config.IsEmission(big.NewInt(99)) || !config.IsEmission(big.NewInt(100)) {
+		t.Errorf("emission schedule not active from block 100")
+	}
+	config.Emission = &Emission{
+		Rewards:          []*big.Int{big.NewInt(3), nil, big.NewInt(1)},
+		HalvingIntervals: []uint64{0, 10},
+		Treasuries:       []common.Address{{0x01}, {0x02}, {0x03}},
+		TreasuryShare:    250,
+	}
+	if reward := config.EmissionReward(PRIME); reward.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("prime reward mismatch: have %v, want 3", reward)
+	}
+	if reward := config.EmissionReward(REGION); reward != nil {
+		t.Errorf("region reward mismatch: have %v, want default", reward)
+	}
+	if interval := config.HalvingInterval(REGION); interval != 10 {
+		t.Errorf("region halving interval mismatch: have %d, want 10", interval)
+	}
+	if interval := config.HalvingInterval(ZONE); interval != 0 {
+		t.Errorf("zone halving interval mismatch: have %d, want 0", interval)
+	}
+	if treasury, share := config.Treasury(ZONE); treasury != (common.Address{0x03}) || share != 250 {
+		t.Errorf("zone treasury mismatch: have %x/%d, want %x/250", treasury, share, common.Address{0x03})
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/spruce-solutions/go-quai/common"
+)
+
+const (
+	// DefaultPrefixWidth is the number of address prefixes allocated to every
+	// chain of an ontology not configuring it.
+	DefaultPrefixWidth = 10
+
+	// chainIDRegionStep separates the chain IDs of consecutive regions.
+	chainIDRegionStep = 100
+)
+
+// Ontology describes the shape of a Quai network, a prime chain with a number
+// of regions having the same number of zones each, and the chain IDs and
+// address prefixes allocated to its chains.
+//
+// The prime chain has the base chain ID, region r has the base chain ID plus
+// 100*r and zone z of region r has the chain ID of its region plus z. Address
+// prefixes, the first byte of an address, are allocated in ranges of the
+// prefix width to the chains in allocation order. Without an explicit
+// allocation that is the prime chain, then every region followed by its zones.
+type Ontology struct {
+	Regions     int      `json:"regions"`               // Number of regions under prime
+	Zones       int      `json:"zones"`                 // Number of zones under every region
+	BaseChainID uint64   `json:"baseChainId"`           // Chain ID of the prime chain
+	PrefixWidth int      `json:"prefixWidth,omitempty"` // Number of address prefixes per chain
+	Allocation  []uint64 `json:"allocation,omitempty"`  // Chain IDs in address prefix order
+}
+
+// OntologyFork schedules the expansion of the network to a larger ontology,
+// effective from a prime block number.
+type OntologyFork struct {
+	Block   *big.Int `json:"block"`   // Prime block number of the first block of the new ontology
+	Regions int      `json:"regions"` // Number of regions from the fork on
+	Zones   int      `json:"zones"`   // Number of zones under every region from the fork on
+}
+
+var (
+	// MainnetOntology is the ontology of the main network.
+	MainnetOntology = &Ontology{Regions: 3, Zones: 3, BaseChainID: 9000}
+
+	// TestnetOntology is the ontology of the test network.
+	TestnetOntology = &Ontology{Regions: 3, Zones: 3, BaseChainID: 12000}
+)
+
+var (
+	errOntologyMissing  = errors.New("network has no ontology")
+	errOntologyShape    = errors.New("ontology needs at least one region and zone")
+	errOntologyTooLarge = errors.New("ontology has too many regions or zones")
+	errOntologyPrefixes = errors.New("ontology address prefixes exceed a byte")
+	errOntologyAlloc    = errors.New("ontology allocation does not list every chain once")
+	errOntologyShrink   = errors.New("ontology fork cannot remove regions or zones")
+)
+
+var (
+	ontologiesLock sync.RWMutex
+	ontologies     = []*Ontology{MainnetOntology, TestnetOntology}
+)
+
+// RegisterOntology makes the chain IDs of an ontology known to the package
+// level chain ID lookups, which cannot be given a chain configuration. An
+// ontology overlapping a registered one replaces it.
+func RegisterOntology(o *Ontology) error {
+	if o == nil {
+		return errOntologyMissing
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	ontologiesLock.Lock()
+	defer ontologiesLock.Unlock()
+
+	registered := []*Ontology{o}
+	for _, other := range ontologies {
+		if !o.overlaps(other) {
+			registered = append(registered, other)
+		}
+	}
+	ontologies = registered
+	return nil
+}
+
+// LookupOntology returns the registered ontology containing the given chain ID.
+func LookupOntology(chainID *big.Int) *Ontology {
+	if chainID == nil {
+		return nil
+	}
+	ontologiesLock.RLock()
+	defer ontologiesLock.RUnlock()
+
+	for _, o := range ontologies {
+		if o.Contains(chainID) {
+			return o
+		}
+	}
+	return nil
+}
+
+// builtinOntology returns the built-in ontology containing the given chain ID.
+func builtinOntology(chainID *big.Int) *Ontology {
+	for _, o := range []*Ontology{MainnetOntology, TestnetOntology} {
+		if o.Contains(chainID) {
+			return o
+		}
+	}
+	return nil
+}
+
+// Validate checks that the ontology is well formed.
+func (o *Ontology) Validate() error {
+	if o.Regions < 1 || o.Zones < 1 {
+		return errOntologyShape
+	}
+	if o.Regions >= chainIDRegionStep || o.Zones >= chainIDRegionStep {
+		return errOntologyTooLarge
+	}
+	if o.prefixWidth() < 1 || o.Chains()*o.prefixWidth() > 256 {
+		return errOntologyPrefixes
+	}
+	if o.Allocation != nil {
+		if len(o.Allocation) != o.Chains() {
+			return errOntologyAlloc
+		}
+		seen := make(map[uint64]bool)
+		for _, id := range o.Allocation {
+			if seen[id] || !o.Contains(new(big.Int).SetUint64(id)) {
+				return errOntologyAlloc
+			}
+			seen[id] = true
+		}
+	}
+	return nil
+}
+
+// Shape returns the number of regions and zones per region.
+func (o *Ontology) Shape() []int {
+	return []int{o.Regions, o.Zones}
+}
+
+// Chains returns the number of chains in the network.
+func (o *Ontology) Chains() int {
+	return 1 + o.Regions*(o.Zones+1)
+}
+
+func (o *Ontology) prefixWidth() int {
+	if o.PrefixWidth == 0 {
+		return DefaultPrefixWidth
+	}
+	return o.PrefixWidth
+}
+
+// ChainID returns the chain ID of the chain at the given location, {0, 0}
+// being prime and {r, 0} region r.
+func (o *Ontology) ChainID(location []byte) (*big.Int, error) {
+	if len(location) != 2 || int(location[0]) > o.Regions || int(location[1]) > o.Zones || (location[0] == 0 && location[1] != 0) {
+		return nil, fmt.Errorf("location %v outside of the %dx%d ontology", location, o.Regions, o.Zones)
+	}
+	id := o.BaseChainID + uint64(location[0])*chainIDRegionStep + uint64(location[1])
+	return new(big.Int).SetUint64(id), nil
+}
+
+// Location returns the location of the chain with the given chain ID.
+func (o *Ontology) Location(chainID *big.Int) ([]byte, bool) {
+	if chainID == nil || !chainID.IsUint64() || chainID.Uint64() < o.BaseChainID {
+		return nil, false
+	}
+	offset := chainID.Uint64() - o.BaseChainID
+	region, zone := offset/chainIDRegionStep, offset%chainIDRegionStep
+	if region > uint64(o.Regions) || zone > uint64(o.Zones) || (region == 0 && zone != 0) {
+		return nil, false
+	}
+	return []byte{byte(region), byte(zone)}, true
+}
+
+// Contains reports whether the chain ID belongs to a chain of the ontology.
+func (o *Ontology) Contains(chainID *big.Int) bool {
+	_, ok := o.Location(chainID)
+	return ok
+}
+
+// ChainIDs returns the chain IDs of all chains in address prefix order.
+func (o *Ontology) ChainIDs() []*big.Int {
+	ids := make([]*big.Int, 0, o.Chains())
+	for region := 0; region <= o.Regions; region++ {
+		for zone := 0; zone <= o.Zones; zone++ {
+			if region == 0 && zone != 0 {
+				break
+			}
+			id, _ := o.ChainID([]byte{byte(region), byte(zone)})
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// PrefixRange returns the first and last address prefix of the chain with the
+// given chain ID, or nil if the chain is not part of the ontology.
+func (o *Ontology) PrefixRange(chainID *big.Int) []int {
+	location, ok := o.Location(chainID)
+	if !ok {
+		return nil
+	}
+	index := 0
+	if o.Allocation != nil {
+		for index = range o.Allocation {
+			if o.Allocation[index] == chainID.Uint64() {
+				break
+			}
+		}
+	} else if location[0] > 0 {
+		index = 1 + int(location[0]-1)*(o.Zones+1) + int(location[1])
+	}
+	first := index * o.prefixWidth()
+	return []int{first, first + o.prefixWidth() - 1}
+}
+
+// LocationPrefixRange returns the first and last address prefix of the chain
+// at the given location.
+func (o *Ontology) LocationPrefixRange(location []byte) ([]int, error) {
+	chainID, err := o.ChainID(location)
+	if err != nil {
+		return nil, err
+	}
+	return o.PrefixRange(chainID), nil
+}
+
+// AddressLocation returns the location of the chain owning the address, or
+// nil if its prefix isn't allocated to any chain.
+func (o *Ontology) AddressLocation(addr common.Address) []byte {
+	index := int(addr[0]) / o.prefixWidth()
+	allocation := o.allocation()
+	if index >= len(allocation) {
+		return nil
+	}
+	location, _ := o.Location(new(big.Int).SetUint64(allocation[index]))
+	return location
+}
+
+// ZoneLocations returns the locations of every zone, ordered by region.
+func (o *Ontology) ZoneLocations() [][]byte {
+	locations := make([][]byte, 0, o.Regions*o.Zones)
+	for region := 1; region <= o.Regions; region++ {
+		for zone := 1; zone <= o.Zones; zone++ {
+			locations = append(locations, []byte{byte(region), byte(zone)})
+		}
+	}
+	return locations
+}
+
+// Expand returns the ontology grown to the given number of regions and zones.
+// The chains of the ontology keep their address prefixes, the new chains are
+// allocated the prefixes following them in chain ID order.
+func (o *Ontology) Expand(regions, zones int) (*Ontology, error) {
+	if regions < o.Regions || zones < o.Zones {
+		return nil, errOntologyShrink
+	}
+	expanded := &Ontology{
+		Regions:     regions,
+		Zones:       zones,
+		BaseChainID: o.BaseChainID,
+		PrefixWidth: o.PrefixWidth,
+	}
+	allocated := make(map[uint64]bool)
+	for _, id := range o.ChainIDs() {
+		allocated[id.Uint64()] = true
+	}
+	expanded.Allocation = make([]uint64, 0, expanded.Chains())
+	expanded.Allocation = append(expanded.Allocation, o.allocation()...)
+	for _, id := range expanded.ChainIDs() {
+		if !allocated[id.Uint64()] {
+			expanded.Allocation = append(expanded.Allocation, id.Uint64())
+		}
+	}
+	if err := expanded.Validate(); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// allocation returns the chain IDs in address prefix order.
+func (o *Ontology) allocation() []uint64 {
+	if o.Allocation != nil {
+		return o.Allocation
+	}
+	ids := o.ChainIDs()
+	allocation := make([]uint64, len(ids))
+	for i, id := range ids {
+		allocation[i] = id.Uint64()
+	}
+	return allocation
+}
+
+// overlaps reports whether two ontologies share a chain ID.
+func (o *Ontology) overlaps(other *Ontology) bool {
+	last := func(o *Ontology) uint64 {
+		return o.BaseChainID + uint64(o.Regions)*chainIDRegionStep + uint64(o.Zones)
+	}
+	return o.BaseChainID <= last(other) && other.BaseChainID <= last(o)
+}
+
+// ChainConfig derives the configuration of the chain at the given location
+// from a template, setting its chain ID, context, location and ontology.
+func (o *Ontology) ChainConfig(template *ChainConfig, location []byte) (*ChainConfig, error) {
+	chainID, err := o.ChainID(location)
+	if err != nil {
+		return nil, err
+	}
+	config := *template
+	config.ChainID = chainID
+	config.Location = common.CopyBytes(location)
+	config.Ontology = o
+	switch {
+	case location[0] == 0:
+		config.Context = PRIME
+	case location[1] == 0:
+		config.Context = REGION
+	default:
+		config.Context = ZONE
+	}
+	return &config, nil
+}
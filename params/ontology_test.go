@@ -0,0 +1,202 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/spruce-solutions/go-quai/common"
+)
+
+func TestOntologyPrefixRanges(t *testing.T) {
+	// The mainnet ontology must keep the historical Fuller allocation
+	fuller := map[int64][]int{
+		9000: {0, 9}, 9100: {10, 19}, 9101: {20, 29}, 9102: {30, 39}, 9103: {40, 49},
+		9200: {50, 59}, 9201: {60, 69}, 9202: {70, 79}, 9203: {80, 89},
+		9300: {90, 99}, 9301: {100, 109}, 9302: {110, 119}, 9303: {120, 129},
+	}
+	ids := MainnetOntology.ChainIDs()
+	if len(ids) != len(fuller) {
+		t.Fatalf("chain count mismatch: have %d, want %d", len(ids), len(fuller))
+	}
+	for _, id := range ids {
+		if have, want := LookupChainIDRange(id), fuller[id.Int64()]; !reflect.DeepEqual(have, want) {
+			t.Errorf("chain %v: prefix range mismatch: have %v, want %v", id, have, want)
+		}
+	}
+	for _, id := range []int64{8999, 9004, 9010, 9400, 9001, 12304} {
+		if r := LookupChainIDRange(big.NewInt(id)); r != nil {
+			t.Errorf("chain %d: unexpected prefix range %v", id, r)
+		}
+	}
+}
+
+func TestOntologyShapes(t *testing.T) {
+	tests := []struct {
+		ontology *Ontology
+		chains   int
+		last     []int // Prefix range of the last zone
+		err      error
+	}{
+		{&Ontology{Regions: 1, Zones: 2, BaseChainID: 1000}, 4, []int{30, 39}, nil},
+		{&Ontology{Regions: 4, Zones: 4, BaseChainID: 2000}, 21, []int{200, 209}, nil},
+		{&Ontology{Regions: 4, Zones: 4, BaseChainID: 2000, PrefixWidth: 12}, 21, []int{240, 251}, nil},
+		{&Ontology{Regions: 5, Zones: 5, BaseChainID: 3000}, 31, nil, errOntologyPrefixes},
+		{&Ontology{Regions: 0, Zones: 3, BaseChainID: 3000}, 1, nil, errOntologyShape},
+	}
+	for i, tt := range tests {
+		if err := tt.ontology.Validate(); err != tt.err {
+			t.Errorf("test %d: validation error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if tt.err != nil {
+			continue
+		}
+		ids := tt.ontology.ChainIDs()
+		if len(ids) != tt.chains {
+			t.Errorf("test %d: chain count mismatch: have %d, want %d", i, len(ids), tt.chains)
+		}
+		last := ids[len(ids)-1]
+		if r := tt.ontology.PrefixRange(last); !reflect.DeepEqual(r, tt.last) {
+			t.Errorf("test %d: last prefix range mismatch: have %v, want %v", i, r, tt.last)
+		}
+		location, ok := tt.ontology.Location(last)
+		if !ok || int(location[0]) != tt.ontology.Regions || int(location[1]) != tt.ontology.Zones {
+			t.Errorf("test %d: last location mismatch: have %v", i, location)
+		}
+	}
+}
+
+func TestRegisterOntology(t *testing.T) {
+	private := &Ontology{Regions: 1, Zones: 2, BaseChainID: 5000}
+	zone, _ := private.ChainID([]byte{1, 2})
+	if ValidChainID(zone, big.NewInt(5000)) {
+		t.Fatalf("unregistered chain accepted")
+	}
+	if err := RegisterOntology(private); err != nil {
+		t.Fatalf("failed to register ontology: %v", err)
+	}
+	if !ValidChainID(zone, big.NewInt(5000)) || !ValidChainID(zone, big.NewInt(5100)) {
+		t.Errorf("registered chain rejected")
+	}
+	if ValidChainID(big.NewInt(9101), big.NewInt(5100)) {
+		t.Errorf("chain of another network accepted")
+	}
+	if ValidChainID(big.NewInt(5103), big.NewInt(5100)) {
+		t.Errorf("chain outside of the ontology accepted")
+	}
+	config := &ChainConfig{ChainID: big.NewInt(5101), Ontology: private}
+	if r := config.ChainIDRange(); !reflect.DeepEqual(r, []int{20, 29}) {
+		t.Errorf("prefix range mismatch: have %v", r)
+	}
+	if shape := config.OntologyShape(); !reflect.DeepEqual(shape, []int{1, 2}) {
+		t.Errorf("shape mismatch: have %v", shape)
+	}
+}
+
+func TestOntologyFork(t *testing.T) {
+	base := &Ontology{Regions: 3, Zones: 3, BaseChainID: 7000}
+	config := *TestChainConfig
+	config.ChainID = big.NewInt(7101)
+	config.Ontology = base
+	config.OntologyForks = []*OntologyFork{{Block: big.NewInt(100), Regions: 4, Zones: 4}}
+
+	if err := config.CheckConfigForkOrder(); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+	// The shape switches at the fork block
+	for number, want := range map[int64][]int{0: {3, 3}, 99: {3, 3}, 100: {4, 4}, 1000: {4, 4}} {
+		shape, err := config.CurrentOntology([]*big.Int{big.NewInt(number)})
+		if err != nil || !reflect.DeepEqual(shape, want) {
+			t.Errorf("block %d: shape mismatch: have %v (%v), want %v", number, shape, err, want)
+		}
+	}
+	// Chains created by the fork are only part of the network from its block on
+	for number, want := range map[int64]bool{99: false, 100: true} {
+		if valid := config.ValidChainID(big.NewInt(7104), big.NewInt(number)); valid != want {
+			t.Errorf("block %d: forked chain validity mismatch: have %v, want %v", number, valid, want)
+		}
+		if valid := config.CheckETxChainID(big.NewInt(7104), big.NewInt(number)); valid != want {
+			t.Errorf("block %d: forked etx chain validity mismatch: have %v, want %v", number, valid, want)
+		}
+	}
+	if !config.ValidChainID(big.NewInt(7103), common.Big0) {
+		t.Errorf("initial chain rejected")
+	}
+	if number := config.OntologyForkBlock(); number != nil {
+		t.Errorf("initial chain created by fork %v", number)
+	}
+	forked, err := config.ForkedChainConfig([]byte{1, 4})
+	if err != nil {
+		t.Fatalf("failed to derive forked chain config: %v", err)
+	}
+	if forked.ChainID.Uint64() != 7104 || forked.Context != ZONE || forked.Ontology != base {
+		t.Errorf("forked chain config mismatch: have chain %v in context %d", forked.ChainID, forked.Context)
+	}
+	if number := forked.OntologyForkBlock(); number == nil || number.Int64() != 100 {
+		t.Errorf("forked chain fork block mismatch: have %v, want %d", number, 100)
+	}
+	// Existing chains keep their prefixes, new chains are allocated after them
+	expanded := config.NetworkOntology()
+	for _, id := range base.ChainIDs() {
+		if have, want := expanded.PrefixRange(id), base.PrefixRange(id); !reflect.DeepEqual(have, want) {
+			t.Errorf("chain %v: prefix moved from %v to %v", id, want, have)
+		}
+	}
+	for id, want := range map[int64][]int{7104: {130, 139}, 7204: {140, 149}, 7304: {150, 159}, 7400: {160, 169}, 7404: {200, 209}} {
+		if have := expanded.PrefixRange(big.NewInt(id)); !reflect.DeepEqual(have, want) {
+			t.Errorf("chain %d: prefix range mismatch: have %v, want %v", id, have, want)
+		}
+	}
+	// Forks cannot shrink the ontology or precede earlier ones
+	config.OntologyForks = append(config.OntologyForks, &OntologyFork{Block: big.NewInt(200), Regions: 3, Zones: 5})
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Errorf("shrinking fork accepted")
+	}
+	config.OntologyForks[1] = &OntologyFork{Block: big.NewInt(50), Regions: 4, Zones: 5}
+	if err := config.CheckConfigForkOrder(); err == nil {
+		t.Errorf("misordered fork accepted")
+	}
+}
+
+func TestOntologyAddressLocation(t *testing.T) {
+	o := &Ontology{Regions: 3, Zones: 3, BaseChainID: 9000}
+	for _, location := range append(o.ZoneLocations(), []byte{0, 0}, []byte{2, 0}) {
+		r, err := o.LocationPrefixRange(location)
+		if err != nil {
+			t.Fatalf("location %v: failed to get prefix range: %v", location, err)
+		}
+		for _, prefix := range []int{r[0], r[1]} {
+			if have := o.AddressLocation(common.Address{byte(prefix)}); !reflect.DeepEqual(have, location) {
+				t.Errorf("prefix %d: location mismatch: have %v, want %v", prefix, have, location)
+			}
+		}
+	}
+	if location := o.AddressLocation(common.Address{130}); location != nil {
+		t.Errorf("unallocated prefix has location %v", location)
+	}
+	if _, err := o.LocationPrefixRange([]byte{4, 1}); err == nil {
+		t.Errorf("location outside of the ontology accepted")
+	}
+	// Expanded ontologies locate the chains by their allocation
+	expanded, _ := o.Expand(4, 4)
+	if have := expanded.AddressLocation(common.Address{130}); !reflect.DeepEqual(have, []byte{1, 4}) {
+		t.Errorf("expanded location mismatch: have %v, want [1 4]", have)
+	}
+}
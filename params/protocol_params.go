@@ -163,6 +163,8 @@ const (
 
 	ExternalBlockLookupLimit int   = 75 // Amount of iterations to lookup external block
 	ExternalBlockLookupDelay int64 = 25 // Delay time (ms) for external block lookup during polling
+
+	ETxLifetime uint64 = 256 // Number of prime blocks an ETx may wait for its destination zone before it expires
 )
 
 // Gas discount table for BLS12-381 G1 and G2 multi exponentiation operations
@@ -173,6 +175,7 @@ var (
 	GenesisDifficulty      = []*big.Int{big.NewInt(531072), big.NewInt(431072), big.NewInt(131072)} // Difficulty of the Genesis block.
 	MinimumDifficulty      = []*big.Int{big.NewInt(531072), big.NewInt(431072), big.NewInt(131072)} // The minimum that the difficulty may ever be.
 	DurationLimits         = []*big.Int{big.NewInt(900), big.NewInt(300), big.NewInt(10)}           // The decision boundary on the blocktime duration used to determine whether difficulty should go up or not.
+	DifficultyEMAWindow    = []*big.Int{big.NewInt(60), big.NewInt(60), big.NewInt(60)}             // Number of blocks averaged by the exponential moving average difficulty algorithm.
 	TargetUncles           = []int{10, 30, 100}                                                     // The bound divisor of the gas limit, used in update calculations.
 )
 
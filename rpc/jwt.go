@@ -0,0 +1,174 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// JWTSecretLength is the length of the shared secret authenticated endpoints
+// sign their tokens with.
+const JWTSecretLength = 32
+
+// jwtExpiryTimeout is the maximum difference between the issuance time of a
+// token and the local clock for the token to be accepted.
+const jwtExpiryTimeout = 60 * time.Second
+
+var (
+	errJWTMissing   = errors.New("missing token")
+	errJWTMalformed = errors.New("malformed token")
+	errJWTAlgorithm = errors.New("unsupported token algorithm")
+	errJWTSignature = errors.New("invalid token signature")
+	errJWTStale     = errors.New("stale token")
+)
+
+// jwtHeader is the encoded header of every token, only HMAC-SHA256 signatures
+// are supported.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// jwtClaims are the claims carried by a token.
+type jwtClaims struct {
+	IssuedAt int64 `json:"iat"`
+}
+
+// NewJWTToken creates a token issued at the given time and signed with the
+// secret.
+func NewJWTToken(secret []byte, now time.Time) (string, error) {
+	claims, err := json.Marshal(jwtClaims{IssuedAt: now.Unix()})
+	if err != nil {
+		return "", err
+	}
+	signed := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(jwtSignature(secret, signed)), nil
+}
+
+// VerifyJWTToken checks that the token is signed with the secret and was
+// issued close enough to the given time.
+func VerifyJWTToken(secret []byte, token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errJWTMalformed
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return errJWTMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return errJWTMalformed
+	}
+	if header.Alg != "HS256" {
+		return errJWTAlgorithm
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errJWTMalformed
+	}
+	if !hmac.Equal(signature, jwtSignature(secret, parts[0]+"."+parts[1])) {
+		return errJWTSignature
+	}
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return errJWTMalformed
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return errJWTMalformed
+	}
+	if diff := now.Sub(time.Unix(claims.IssuedAt, 0)); diff > jwtExpiryTimeout || diff < -jwtExpiryTimeout {
+		return errJWTStale
+	}
+	return nil
+}
+
+// VerifyJWTRequest checks the bearer token in the authorization header of the
+// request.
+func VerifyJWTRequest(secret []byte, r *http.Request) error {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return errJWTMissing
+	}
+	return VerifyJWTToken(secret, strings.TrimPrefix(auth, "Bearer "), time.Now())
+}
+
+// jwtSignature returns the HMAC-SHA256 signature of the signed part of a token.
+func jwtSignature(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+// HTTPAuth adds authentication to the headers of an outgoing HTTP request or
+// websocket handshake.
+type HTTPAuth func(header http.Header) error
+
+// NewJWTAuth creates an HTTPAuth sending a freshly issued bearer token signed
+// with the secret.
+func NewJWTAuth(secret []byte) HTTPAuth {
+	return func(header http.Header) error {
+		token, err := NewJWTToken(secret, time.Now())
+		if err != nil {
+			return err
+		}
+		header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// authTransport authenticates every request sent through the base transport.
+type authTransport struct {
+	auth HTTPAuth
+	base http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := t.auth(req.Header); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// DialWithAuth creates a new RPC client for an authenticated endpoint, just like
+// DialContext. Every HTTP request and websocket handshake is authenticated with
+// auth. Only HTTP and websocket endpoints are supported.
+func DialWithAuth(ctx context.Context, rawurl string, auth HTTPAuth) (*Client, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return DialHTTPWithClient(rawurl, &http.Client{Transport: &authTransport{auth: auth, base: http.DefaultTransport}})
+	case "ws", "wss":
+		return dialWebsocket(ctx, rawurl, "", newWebsocketDialer(), auth)
+	default:
+		return nil, fmt.Errorf("no authenticated transport for URL scheme %q", u.Scheme)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWTToken(t *testing.T) {
+	secret := make([]byte, JWTSecretLength)
+	secret[0] = 1
+	now := time.Unix(1000000, 0)
+
+	token, err := NewJWTToken(secret, now)
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	other, err := NewJWTToken(secret, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	// Claims of one token signed with the signature of another
+	forged := token[:strings.LastIndex(token, ".")] + other[strings.LastIndex(other, "."):]
+	tests := []struct {
+		secret []byte
+		token  string
+		now    time.Time
+		err    error
+	}{
+		{secret, token, now, nil},
+		{secret, token, now.Add(jwtExpiryTimeout), nil},
+		{secret, token, now.Add(-jwtExpiryTimeout), nil},
+		{secret, token, now.Add(jwtExpiryTimeout + time.Second), errJWTStale},
+		{secret, token, now.Add(-jwtExpiryTimeout - time.Second), errJWTStale},
+		{make([]byte, JWTSecretLength), token, now, errJWTSignature},
+		{secret, forged, now, errJWTSignature},
+		{secret, strings.Replace(token, ".", "", 1), now, errJWTMalformed},
+		{secret, "", now, errJWTMalformed},
+	}
+	for i, tt := range tests {
+		if err := VerifyJWTToken(tt.secret, tt.token, tt.now); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestDialWithAuth(t *testing.T) {
+	secret := make([]byte, JWTSecretLength)
+	secret[0] = 1
+
+	server := newTestServer()
+	defer server.Stop()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := VerifyJWTRequest(secret, r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Upgrade") != "" {
+			server.WebsocketHandler([]string{"*"}).ServeHTTP(w, r)
+			return
+		}
+		server.ServeHTTP(w, r)
+	})
+	httpsrv := httptest.NewServer(handler)
+	defer httpsrv.Close()
+	wsURL := "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+
+	for _, url := range []string{httpsrv.URL, wsURL} {
+		// Calls signed with the shared secret must succeed.
+		client, err := DialWithAuth(context.Background(), url, NewJWTAuth(secret))
+		if err != nil {
+			t.Fatalf("%s: failed to dial: %v", url, err)
+		}
+		var result echoResult
+		if err := client.Call(&result, "test_echo", "hello", 10, &echoArgs{"world"}); err != nil {
+			t.Errorf("%s: authenticated call failed: %v", url, err)
+		}
+		client.Close()
+
+		// Calls signed with another secret must be rejected.
+		client, err = DialWithAuth(context.Background(), url, NewJWTAuth(make([]byte, JWTSecretLength)))
+		if err == nil {
+			if err := client.Call(&result, "test_echo", "hello", 10, &echoArgs{"world"}); err == nil {
+				t.Errorf("%s: call with wrong secret succeeded", url)
+			}
+			client.Close()
+		}
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/spruce-solutions/go-quai/common/hexutil"
 	"github.com/spruce-solutions/go-quai/internal/ethapi"
 	"github.com/spruce-solutions/go-quai/log"
+	"github.com/spruce-solutions/go-quai/params"
 	"github.com/spruce-solutions/go-quai/signer/core/apitypes"
 	"github.com/spruce-solutions/go-quai/signer/storage"
 )
@@ -43,7 +44,7 @@ const (
 	// ExternalAPIVersion -- see extapi_changelog.md
 	ExternalAPIVersion = "6.1.0"
 	// InternalAPIVersion -- see intapi_changelog.md
-	InternalAPIVersion = "7.0.1"
+	InternalAPIVersion = "7.1.0"
 )
 
 // ExternalAPI defines the external API through which signing requests are made.
@@ -116,6 +117,9 @@ type SignerAPI struct {
 	validator   Validator
 	rejectMode  bool
 	credentials storage.Storage
+
+	location []byte // Zone new accounts are generated for, any if nil
+	idRange  []int  // Address prefixes of the zone new accounts are generated for
 }
 
 // Metadata about a request
@@ -244,7 +248,8 @@ type (
 		Approved bool `json:"approved"`
 	}
 	NewAccountRequest struct {
-		Meta Metadata `json:"meta"`
+		Meta     Metadata      `json:"meta"`
+		Location hexutil.Bytes `json:"location,omitempty"`
 	}
 	NewAccountResponse struct {
 		Approved bool `json:"approved"`
@@ -252,6 +257,7 @@ type (
 	ListRequest struct {
 		Accounts []accounts.Account `json:"accounts"`
 		Meta     Metadata           `json:"meta"`
+		Ontology *params.Ontology   `json:"-"` // Shape of the signer's network, locating the accounts
 	}
 	ListResponse struct {
 		Accounts []accounts.Account `json:"accounts"`
@@ -283,12 +289,33 @@ func NewSignerAPI(am *accounts.Manager, chainID int64, noUSB bool, ui UIClientAP
 	if advancedMode {
 		log.Info("Clef is in advanced mode: will warn instead of reject")
 	}
-	signer := &SignerAPI{big.NewInt(chainID), am, ui, validator, !advancedMode, credentials}
+	signer := &SignerAPI{chainID: big.NewInt(chainID), am: am, UI: ui, validator: validator, rejectMode: !advancedMode, credentials: credentials}
 	if !noUSB {
 		signer.startUSBListener()
 	}
 	return signer
 }
+
+// SetAccountLocation makes new accounts be generated for the zone at the given
+// location, which is looked up in the ontology of the signer's chain, or the
+// main network ontology if the chain is unknown.
+func (api *SignerAPI) SetAccountLocation(location []byte) error {
+	idRange, err := api.ontology().LocationPrefixRange(location)
+	if err != nil {
+		return err
+	}
+	api.location, api.idRange = common.CopyBytes(location), idRange
+	return nil
+}
+
+// ontology returns the ontology of the signer's chain, or the main network
+// ontology if the chain is unknown.
+func (api *SignerAPI) ontology() *params.Ontology {
+	if ontology := params.LookupOntology(api.chainID); ontology != nil {
+		return ontology
+	}
+	return params.MainnetOntology
+}
 func (api *SignerAPI) openTrezor(url accounts.URL) {
 	resp, err := api.UI.OnInputRequired(UserInputRequest{
 		Prompt: "Pin required to open Trezor wallet\n" +
@@ -392,7 +419,7 @@ func (api *SignerAPI) List(ctx context.Context) ([]common.Address, error) {
 	for _, wallet := range api.am.Wallets() {
 		accs = append(accs, wallet.Accounts()...)
 	}
-	result, err := api.UI.ApproveListing(&ListRequest{Accounts: accs, Meta: MetadataFromContext(ctx)})
+	result, err := api.UI.ApproveListing(&ListRequest{Accounts: accs, Meta: MetadataFromContext(ctx), Ontology: api.ontology()})
 	if err != nil {
 		return nil, err
 	}
@@ -413,7 +440,7 @@ func (api *SignerAPI) New(ctx context.Context) (common.Address, error) {
 	if be := api.am.Backends(keystore.KeyStoreType); len(be) == 0 {
 		return common.Address{}, errors.New("password based accounts not supported")
 	}
-	if resp, err := api.UI.ApproveNewAccount(&NewAccountRequest{MetadataFromContext(ctx)}); err != nil {
+	if resp, err := api.UI.ApproveNewAccount(&NewAccountRequest{Meta: MetadataFromContext(ctx), Location: api.location}); err != nil {
 		return common.Address{}, err
 	} else if !resp.Approved {
 		return common.Address{}, ErrRequestDenied
@@ -442,8 +469,8 @@ func (api *SignerAPI) newAccount() (common.Address, error) {
 			api.UI.ShowError(fmt.Sprintf("Account creation attempt #%d failed due to password requirements: %v", i+1, pwErr))
 		} else {
 			// No error
-			acc, err := be[0].(*keystore.KeyStore).NewAccount(resp.Text, nil)
-			log.Info("Your new key was generated", "address", acc.Address)
+			acc, err := be[0].(*keystore.KeyStore).NewAccount(resp.Text, api.idRange)
+			log.Info("Your new key was generated", "address", acc.Address, "location", acc.Location(api.ontology()))
 			log.Warn("Please backup your key file!", "path", acc.URL.Path)
 			log.Warn("Please remember your password!")
 			return acc.Address, err
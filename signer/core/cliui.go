@@ -198,6 +198,9 @@ func (ui *CommandlineUI) ApproveListing(request *ListRequest) (ListResponse, err
 	for _, account := range request.Accounts {
 		fmt.Printf("  [x] %v\n", account.Address.Hex())
 		fmt.Printf("    URL: %v\n", account.URL)
+		if location := account.Location(request.Ontology); location != nil {
+			fmt.Printf("    Location: %d,%d\n", location[0], location[1])
+		}
 	}
 	fmt.Printf("-------------------------------------------\n")
 	showMetadata(request.Meta)
@@ -217,6 +220,9 @@ func (ui *CommandlineUI) ApproveNewAccount(request *NewAccountRequest) (NewAccou
 	fmt.Printf("A request has been made to create a new account. \n")
 	fmt.Printf("Approving this operation means that a new account is created,\n")
 	fmt.Printf("and the address is returned to the external caller\n\n")
+	if len(request.Location) == 2 {
+		fmt.Printf("The account is created for zone %d,%d\n\n", request.Location[0], request.Location[1])
+	}
 	showMetadata(request.Meta)
 	if !ui.confirm() {
 		return NewAccountResponse{false}, nil
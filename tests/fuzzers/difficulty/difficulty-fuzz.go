@@ -84,8 +84,6 @@ func Fuzz(data []byte) int {
 
 var minDifficulty = big.NewInt(0x2000)
 
-type calculator func(time uint64, parent *types.Header) *big.Int
-
 type calculatorwithContext func(time uint64, parent *types.Header, context int) *big.Int
 
 func (f *fuzzer) fuzz() int {
@@ -130,14 +128,14 @@ func (f *fuzzer) fuzz() int {
 
 	for i, pair := range []struct {
 		bigFn  calculatorwithContext
-		u256Fn calculator
+		u256Fn calculatorwithContext
 	}{
 		{blake3.FrontierDifficultyCalulator, blake3.CalcDifficultyFrontierU256},
 		/*{blake3.HomesteadDifficultyCalulator, blake3.CalcDifficultyHomesteadU256},
 		{blake3.DynamicDifficultyCalculator(bombDelay), blake3.MakeDifficultyCalculatorU256(bombDelay)},*/
 	} {
 		want := pair.bigFn(time, header, 0)
-		have := pair.u256Fn(time, header)
+		have := pair.u256Fn(time, header, 0)
 		if want.Cmp(have) != 0 {
 			panic(fmt.Sprintf("pair %d: want %x have %x\nparent.Number: %x\np.Time: %x\nc.Time: %x\nBombdelay: %v\n", i, want, have,
 				header.Number, header.Time, time, bombDelay))